@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/callen/bird-song-explorer/internal/api"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// mixOptions picks the mix and what goes under the voice
+type mixOptions struct {
+	kind  string
+	sound string
+	song  string
+	music string
+	out   string
+}
+
+func newAudioCommand(cfg *config.Config) *cobra.Command {
+	audio := &cobra.Command{Use: "audio", Short: "Mix audio the way card tracks are mixed"}
+
+	var options mixOptions
+	mix := &cobra.Command{
+		Use:         "mix <voice.mp3> [clip.mp3...]",
+		Short:       "Mix narration with nature sounds, bird song, or music",
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: withServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAudioMix(cfg, args[0], args[1:], options)
+		},
+	}
+	mix.Flags().StringVar(&options.kind, "kind", "intro", "Mix to make: intro, night-intro, outro, or concat")
+	mix.Flags().StringVar(&options.sound, "sound", "forest", "Nature sound under an intro (morning_birds, forest, meadow, night, ...)")
+	mix.Flags().StringVar(&options.song, "song", "", "Bird song MP3 under an outro")
+	mix.Flags().StringVar(&options.music, "music", "", "Background music type under an outro, instead of a bird song")
+	mix.Flags().StringVar(&options.out, "out", "mix.mp3", "Output file")
+
+	audio.AddCommand(mix)
+	return audio
+}
+
+// runAudioMix mixes local clips with the same mixers the card's tracks use
+func runAudioMix(cfg *config.Config, voicePath string, clipPaths []string, options mixOptions) error {
+	voice, err := os.ReadFile(voicePath)
+	if err != nil {
+		return err
+	}
+
+	services.BootstrapFFmpeg(api.FFmpegOptions(cfg))
+
+	var mixed []byte
+	switch options.kind {
+	case "intro":
+		mixed, err = services.NewIntroMixer().MixIntroWithNatureSounds(voice, options.sound)
+	case "night-intro":
+		mixed, err = services.NewIntroMixer().MixNightIntro(voice)
+	case "outro":
+		mixer := services.NewAudioMixer(nil)
+		switch {
+		case options.music != "":
+			mixed, err = mixer.MixOutroWithMusic(voice, options.music)
+		case options.song != "":
+			var birdSong []byte
+			if birdSong, err = os.ReadFile(options.song); err != nil {
+				return err
+			}
+			mixed, err = mixer.MixOutroWithNatureSounds(voice, birdSong)
+		default:
+			return errors.New("an outro mix needs --song or --music")
+		}
+	case "concat":
+		clips := [][]byte{voice}
+		for _, path := range clipPaths {
+			clip, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			clips = append(clips, clip)
+		}
+		mixed, err = services.NewAudioProcessor().Concat(clips...)
+	default:
+		return fmt.Errorf("unknown mix %q", options.kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(options.out, mixed, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (%d bytes)\n", options.out, len(mixed))
+	return nil
+}
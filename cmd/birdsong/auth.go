@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/spf13/cobra"
+)
+
+func newAuthCommand(cfg *config.Config) *cobra.Command {
+	auth := &cobra.Command{Use: "auth", Short: "Log in to Yoto and save the tokens"}
+
+	var port int
+	var timeout time.Duration
+	browser := &cobra.Command{
+		Use:   "browser",
+		Short: "Log in to Yoto in a browser and save the tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuthBrowser(cfg, port, timeout)
+		},
+	}
+	browser.Flags().IntVar(&port, "port", 8081, "Local port for the login callback; the redirect URI must be allowed for YOTO_CLIENT_ID")
+	browser.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "How long to wait for the login")
+
+	device := &cobra.Command{
+		Use:   "device",
+		Short: "Log in to Yoto with a code entered on another device and save the tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuthDevice(cfg)
+		},
+	}
+
+	auth.AddCommand(browser, device)
+	return auth
+}
+
+// runAuthBrowser logs in with the authorization code flow: the user approves in a browser, which
+// redirects back to a callback served on localhost
+func runAuthBrowser(cfg *config.Config, port int, timeout time.Duration) error {
+	if cfg.YotoClientID == "" {
+		return errors.New("YOTO_CLIENT_ID is required")
+	}
+	client, err := newYotoClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	verifier := randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+	state := randomToken()
+	redirectURI := fmt.Sprintf("http://localhost:%d/callback", port)
+
+	codes := make(chan string, 1)
+	failures := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("error") != "":
+			failures <- fmt.Errorf("login denied: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("state") != state:
+			failures <- errors.New("login callback state didn't match; try again")
+		default:
+			codes <- query.Get("code")
+		}
+		fmt.Fprintln(w, "You can close this window and return to your terminal.")
+	})
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen for the login callback: %w", err)
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Shutdown(context.Background())
+
+	fmt.Printf("Open this URL to log in to Yoto:\n\n  %s\n\n", client.AuthorizeURL(redirectURI, state, base64.RawURLEncoding.EncodeToString(challenge[:])))
+
+	select {
+	case code := <-codes:
+		tokens, err := client.ExchangeCode(code, redirectURI, verifier)
+		if err != nil {
+			return err
+		}
+		printTokens(tokens)
+		return nil
+	case err := <-failures:
+		return err
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for the login")
+	}
+}
+
+// runAuthDevice logs in with the device code flow, for machines without a browser
+func runAuthDevice(cfg *config.Config) error {
+	if cfg.YotoClientID == "" {
+		return errors.New("YOTO_CLIENT_ID is required")
+	}
+	client, err := newYotoClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := client.StartDeviceLogin()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("On any device, open %s and enter the code %s", authorization.VerificationURI, authorization.UserCode)
+	if authorization.VerificationURIComplete != "" {
+		fmt.Printf("\n(or open %s)", authorization.VerificationURIComplete)
+	}
+	fmt.Println("\n\nWaiting for approval...")
+
+	tokens, err := client.AwaitDeviceLogin(authorization)
+	if err != nil {
+		return err
+	}
+	printTokens(tokens)
+	return nil
+}
+
+// printTokens confirms the login and shows the tokens for deployments configured from the environment
+func printTokens(tokens *yoto.TokenResponse) {
+	fmt.Println("✅ Logged in; the tokens are saved to the token store")
+	fmt.Printf("\nYOTO_ACCESS_TOKEN=%s\nYOTO_REFRESH_TOKEN=%s\n", tokens.AccessToken, tokens.RefreshToken)
+}
+
+// randomToken returns a random URL-safe string for PKCE verifiers and OAuth state
+func randomToken() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
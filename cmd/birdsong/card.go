@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/callen/bird-song-explorer/internal/api"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/preview"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// rebuildOptions picks the location and device a past date is rebuilt for
+type rebuildOptions struct {
+	hour      int
+	latitude  string
+	longitude string
+	city      string
+	device    string
+}
+
+func newCardCommand(cfg *config.Config) *cobra.Command {
+	card := &cobra.Command{Use: "card", Short: "Publish, preview, and rebuild card updates"}
+
+	var updateCard string
+	update := &cobra.Command{
+		Use:         "update",
+		Short:       "Publish today's bird to a card, as the scheduler does",
+		Args:        cobra.NoArgs,
+		Annotations: withServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCardUpdate(cfg, updateCard)
+		},
+	}
+	update.Flags().StringVar(&updateCard, "card", cfg.YotoCardID, "Card to update")
+
+	var options preview.Options
+	previewCmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Build today's update for a card into a local folder without publishing it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCardPreview(cfg, options)
+		},
+	}
+	previewCmd.Flags().StringVar(&options.CardID, "card", "", "Card to build (default: the default card)")
+	previewCmd.Flags().StringVar(&options.OutDir, "out", "dry_run_preview", "Folder for the preview bundle")
+	previewCmd.Flags().BoolVar(&options.Offline, "offline", false, "Refuse requests to anything but the preview's fakes (eBird, Xeno-canto, and the recordings bucket included)")
+
+	var rebuildCard string
+	var rebuild rebuildOptions
+	rebuildCmd := &cobra.Command{
+		Use:         "rebuild <YYYY-MM-DD>",
+		Short:       "Regenerate a card's tracks for a past date, for debugging",
+		Args:        cobra.ExactArgs(1),
+		Annotations: withServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCardRebuild(cfg, rebuildCard, args[0], rebuild)
+		},
+	}
+	rebuildCmd.Flags().StringVar(&rebuildCard, "card", cfg.YotoCardID, "Card to rebuild")
+	rebuildCmd.Flags().IntVar(&rebuild.hour, "hour", 9, "Local hour of the day to rebuild")
+	rebuildCmd.Flags().StringVar(&rebuild.latitude, "lat", "", "Latitude to rebuild for (default: the card's default location)")
+	rebuildCmd.Flags().StringVar(&rebuild.longitude, "lng", "", "Longitude to rebuild for")
+	rebuildCmd.Flags().StringVar(&rebuild.city, "city", "", "City name for the location")
+	rebuildCmd.Flags().StringVar(&rebuild.device, "device", "", "Device whose stored location and options to rebuild for")
+
+	card.AddCommand(update, previewCmd, rebuildCmd)
+	return card
+}
+
+// runCardUpdate publishes today's bird through the server's daily update handler
+func runCardUpdate(cfg *config.Config, cardID string) error {
+	if cardID == "" {
+		return errors.New("no card to update; set YOTO_CARD_ID or pass --card")
+	}
+	// Stream URLs on the card point at the deployed service, not this process
+	if cfg.ServiceURL == "" {
+		if cfg.BaseURL == "" {
+			return errors.New("SERVICE_URL or BASE_URL must name the deployed service the card streams from")
+		}
+		cfg.ServiceURL = cfg.BaseURL
+	}
+
+	services.BootstrapFFmpeg(api.FFmpegOptions(cfg))
+	router := api.NewRouter(cfg, api.NewHandler(cfg))
+
+	req := httptest.NewRequest("POST", "/api/v1/daily-update?card="+url.QueryEscape(cardID), nil)
+	// Loopback address skips IP geolocation
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set("X-Scheduler-Token", cfg.SchedulerToken)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	fmt.Println(recorder.Body.String())
+	if recorder.Code != http.StatusOK {
+		return fmt.Errorf("update failed with status %d", recorder.Code)
+	}
+	return nil
+}
+
+// runCardPreview builds today's update into a local folder, the same as the server's --dry-run
+func runCardPreview(cfg *config.Config, options preview.Options) error {
+	services.BootstrapFFmpeg(api.FFmpegOptions(cfg))
+	result, err := preview.Run(cfg, options)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Preview for %s on card %s written to %s (%d tracks, %d narrated scripts)\n",
+		result.Bird, result.CardID, result.Dir, result.Tracks, result.Scripts)
+	return nil
+}
+
+// runCardRebuild regenerates a past date's tracks through the server's rebuild endpoint
+func runCardRebuild(cfg *config.Config, cardID, date string, options rebuildOptions) error {
+	if cardID == "" {
+		return errors.New("no card to rebuild; set YOTO_CARD_ID or pass --card")
+	}
+
+	query := url.Values{"date": {date}, "hour": {fmt.Sprint(options.hour)}}
+	if options.latitude != "" && options.longitude != "" {
+		query.Set("lat", options.latitude)
+		query.Set("lng", options.longitude)
+		query.Set("city", options.city)
+	}
+	if options.device != "" {
+		query.Set("device", options.device)
+	}
+
+	services.BootstrapFFmpeg(api.FFmpegOptions(cfg))
+	router := api.NewRouter(cfg, api.NewHandler(cfg))
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/cards/"+url.PathEscape(cardID)+"/rebuild?"+query.Encode(), nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set("X-Scheduler-Token", cfg.SchedulerToken)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	fmt.Println(recorder.Body.String())
+	if recorder.Code != http.StatusOK {
+		return fmt.Errorf("rebuild failed with status %d", recorder.Code)
+	}
+	return nil
+}
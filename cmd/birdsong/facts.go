@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/spf13/cobra"
+)
+
+// factsOptions reproduces the phrasing and regional facts of one card's day
+type factsOptions struct {
+	generator string
+	locale    string
+	cardID    string
+	day       string
+	latitude  float64
+	longitude float64
+	asJSON    bool
+}
+
+func newFactsCommand(cfg *config.Config) *cobra.Command {
+	facts := &cobra.Command{Use: "facts", Short: "Generate bird facts"}
+
+	var options factsOptions
+	generate := &cobra.Command{
+		Use:         "generate <bird>",
+		Short:       "Generate the guide script for a bird with each sentence's source",
+		Example:     "  birdsong facts generate American Robin",
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: withServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFactsGenerate(cfg, strings.Join(args, " "), options)
+		},
+	}
+	generate.Flags().StringVar(&options.generator, "generator", cfg.FactGenerator, "Fact generator ("+strings.Join(services.FactGeneratorNames(), ", ")+")")
+	generate.Flags().StringVar(&options.locale, "locale", cfg.ContentLocale, "Narration language")
+	generate.Flags().StringVar(&options.cardID, "card", "", "Card whose phrasing to reproduce")
+	generate.Flags().StringVar(&options.day, "day", time.Now().UTC().Format("2006-01-02"), "Day whose phrasing to reproduce (YYYY-MM-DD)")
+	generate.Flags().Float64Var(&options.latitude, "lat", 0, "Listener latitude for regional facts")
+	generate.Flags().Float64Var(&options.longitude, "lng", 0, "Listener longitude for regional facts")
+	generate.Flags().BoolVar(&options.asJSON, "json", false, "Print the transcript as JSON")
+
+	facts.AddCommand(generate)
+	return facts
+}
+
+// runFactsGenerate prints a bird's guide script the way the admin preview generates it
+func runFactsGenerate(cfg *config.Config, birdName string, options factsOptions) error {
+	bird := &models.Bird{CommonName: birdName}
+	if metadata, err := services.NewBirdStorage("").GetBirdMetadata(birdName); err == nil {
+		bird.ScientificName = metadata.ScientificName
+		bird.Family = metadata.Family
+	}
+
+	generator := services.NewFactGeneratorForLocale(options.generator, cfg.EBirdAPIKey, options.locale, randx.Daily(options.day, options.cardID))
+	transcript := generator.GenerateFactTranscript(context.Background(), bird, options.latitude, options.longitude)
+
+	if options.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(transcript)
+	}
+
+	fmt.Printf("%s (%s generator)\n\n", transcript.BirdName, transcript.Generator)
+	for _, sentence := range transcript.Sentences {
+		fmt.Printf("[%s] %s\n", sentence.Source, sentence.Text)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/spf13/cobra"
+)
+
+func newIconsCommand(cfg *config.Config) *cobra.Command {
+	icons := &cobra.Command{Use: "icons", Short: "Manage the cards' display icons"}
+	icons.AddCommand(&cobra.Command{
+		Use:         "search <bird>",
+		Short:       "Find and upload a bird's display icon",
+		Example:     "  birdsong icons search American Robin",
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: withServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runIconsSearch(cfg, strings.Join(args, " "))
+		},
+	})
+	return icons
+}
+
+// runIconsSearch finds a bird's icon the way card updates do, uploading it on first use
+func runIconsSearch(cfg *config.Config, birdName string) error {
+	client, err := newYotoClient(cfg)
+	if err != nil {
+		return err
+	}
+	icon, err := yoto.NewIconSearcher(client).SearchBirdIcon(birdName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", birdName, icon)
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/callen/bird-song-explorer/internal/api"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/spf13/cobra"
+)
+
+// withServices marks a command that builds services, so the shared settings are applied before it
+// runs. Login and the preview, which isolates its own state, leave them alone.
+var withServices = map[string]string{"services": "configure"}
+
+// The birdsong CLI runs the service's own code paths from the command line, with settings read
+// from the same environment as the server. Run from the repository root, since assets are read
+// from ./assets and state from ./data.
+func main() {
+	if err := newRootCommand(config.Load()).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the command tree; flag defaults come from cfg, which is validated before
+// any command runs
+func newRootCommand(cfg *config.Config) *cobra.Command {
+	root := &cobra.Command{
+		Use:          "birdsong",
+		Short:        "Run the bird song service's code paths from the command line",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			if _, ok := cmd.Annotations["services"]; ok {
+				api.ConfigureServices(cfg)
+			}
+			return nil
+		},
+	}
+	root.AddCommand(
+		newAuthCommand(cfg),
+		newCardCommand(cfg),
+		newFactsCommand(cfg),
+		newAudioCommand(cfg),
+		newIconsCommand(cfg),
+		newSongsCommand(cfg),
+	)
+	return root
+}
+
+// newYotoClient builds a Yoto client the way the server does: environment tokens, replaced by any
+// newer tokens in the configured token store (a file when none is configured)
+func newYotoClient(cfg *config.Config) (*yoto.Client, error) {
+	client := yoto.NewClient(cfg.YotoClientID, "", cfg.YotoAPIBaseURL)
+	if cfg.YotoAccessToken != "" {
+		client.SetConfiguredTokens(cfg.YotoAccessToken, cfg.YotoRefreshToken)
+	}
+	if cfg.AutoUpdateSecrets {
+		client.SetSecretsProject(cfg.GCPSecretsProject)
+	}
+
+	kind := cfg.YotoTokenStore
+	if kind == "" {
+		kind = "file"
+	}
+	tokenStore, err := yoto.NewTokenStore(kind, cfg.YotoTokenFile, cfg.GCPSecretsProject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s token store: %w", kind, err)
+	}
+	client.SetTokenStore(tokenStore)
+	return client, nil
+}
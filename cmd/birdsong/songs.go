@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/spf13/cobra"
+)
+
+func newSongsCommand(cfg *config.Config) *cobra.Command {
+	songs := &cobra.Command{Use: "songs", Short: "Maintain the bird song catalog"}
+
+	var verify bool
+	check := &cobra.Command{
+		Use:         "check",
+		Short:       "Check species without a song for new recordings",
+		Args:        cobra.NoArgs,
+		Annotations: withServices,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSongsCheck(cfg, verify)
+		},
+	}
+	check.Flags().BoolVar(&verify, "verify", cfg.VerifyBirdSongs, "Verify each recording with BirdNET (BIRDNET_API_URL), else just reject noisy clips")
+
+	songs.AddCommand(check)
+	return songs
+}
+
+// runSongsCheck looks for recordings of the species filed as having no usable song, listing the
+// ones that can be moved back into the catalog
+func runSongsCheck(cfg *config.Config, verify bool) error {
+	// Check birds in the unavailable directory
+	catalog := services.NewTTSCatalog(cfg.PrerecordedTTSDir)
+	unavailableDir := filepath.Join(catalog.Root(), services.UnavailableSongDir)
+
+	entries, err := catalog.List(false)
+	if err != nil {
+		return fmt.Errorf("failed to read TTS catalog: %w", err)
+	}
+
+	var birds []services.CatalogSpecies
+	for _, entry := range entries {
+		if entry.SongUnavailable {
+			birds = append(birds, entry)
+		}
+	}
+	if len(birds) == 0 {
+		fmt.Println("No birds found in bird-song-unavailable directory")
+		return nil
+	}
+
+	var birdsWithSongs []string
+	var birdsWithoutSongs []string
+
+	selector := services.NewRecordingSelector(cfg.XenoCantoAPIKey, cfg.EBirdAPIKey)
+	taxonomy := ebird.SharedTaxonomy(cfg.EBirdAPIKey)
+	if err := taxonomy.Load(); err != nil {
+		return fmt.Errorf("failed to load eBird taxonomy (is EBIRD_API_KEY set?): %w", err)
+	}
+	if verify {
+		// BirdNET checks the species when BIRDNET_API_URL is set; otherwise noisy clips are rejected
+		selector.SetVerifier(services.NewSongVerifier(cfg.BirdNetAPIURL))
+	}
+
+	fmt.Println("Checking birds in unavailable directory against xeno-canto and Macaulay Library...")
+	fmt.Println("=" + strings.Repeat("=", 60))
+
+	for _, bird := range birds {
+		birdName := bird.Directory
+		cleanBirdName := bird.Species
+		birdPath := filepath.Join(unavailableDir, birdName)
+
+		// Resolve the directory name ("black-capped-chickadee") through the eBird taxonomy
+		species, exists := taxonomy.ByCommonName(cleanBirdName)
+		if !exists {
+			fmt.Printf("❌ %s - Not found in the eBird taxonomy\n", birdName)
+			birdsWithoutSongs = append(birdsWithoutSongs, birdPath)
+			continue
+		}
+		scientificName := species.ScientificName
+
+		// Try xeno-canto, falling back to the Macaulay Library
+		fmt.Printf("Checking %s (%s)... ", birdName, scientificName)
+
+		recording, err := selector.FindRecording(context.Background(), scientificName)
+		if err != nil {
+			fmt.Printf("❌ No usable recording\n")
+			fmt.Printf("   Error: %v\n", err)
+			birdsWithoutSongs = append(birdsWithoutSongs, birdPath)
+		} else {
+			fmt.Printf("✅ Found %s recording %s\n", recording.Source, recording.ID)
+			fmt.Printf("   URL: %s\n", recording.URL)
+			fmt.Printf("   Type: %s, Length: %ds\n", recording.Type, recording.DurationSeconds)
+			birdsWithSongs = append(birdsWithSongs, birdPath)
+		}
+	}
+
+	// Print summary
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Printf("SUMMARY: %d birds with songs, %d birds without songs\n",
+		len(birdsWithSongs), len(birdsWithoutSongs))
+
+	if len(birdsWithoutSongs) > 0 {
+		fmt.Println("\nBirds WITHOUT available songs:")
+		for _, bird := range birdsWithoutSongs {
+			fmt.Printf("  - %s\n", filepath.Base(bird))
+		}
+	}
+
+	if len(birdsWithSongs) > 0 {
+		fmt.Println("\nBirds WITH available songs (can be moved back to audio):")
+		for _, bird := range birdsWithSongs {
+			fmt.Printf("  - %s\n", filepath.Base(bird))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+)
+
+func main() {
+	root := flag.String("root", "", "Pre-recorded TTS directory (default PRERECORDED_TTS_DIR or prerecorded_tts)")
+	durations := flag.Bool("durations", false, "Probe file durations with ffprobe")
+	asJSON := flag.Bool("json", false, "Print JSON instead of a table")
+	flag.Usage = func() {
+		fmt.Println("Usage: catalog [flags] <list|missing|show <species>>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		command = "list"
+	}
+
+	catalog := services.NewTTSCatalog(*root)
+
+	switch command {
+	case "list", "missing":
+		entries, err := catalog.List(*durations)
+		if err != nil {
+			log.Fatalf("Failed to read catalog: %v", err)
+		}
+		if command == "missing" {
+			var incomplete []services.CatalogSpecies
+			for _, entry := range entries {
+				if len(entry.Missing) > 0 {
+					incomplete = append(incomplete, entry)
+				}
+			}
+			entries = incomplete
+		}
+		if *asJSON {
+			printJSON(entries)
+			return
+		}
+		printTable(entries, command == "missing")
+
+	case "show":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		entry, err := catalog.Get(strings.Join(flag.Args()[1:], " "), *durations)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *asJSON {
+			printJSON(entry)
+			return
+		}
+		printSpecies(entry)
+
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Fatalf("Failed to encode catalog: %v", err)
+	}
+}
+
+func printTable(entries []services.CatalogSpecies, showMissing bool) {
+	if len(entries) == 0 {
+		fmt.Println("No species found")
+		return
+	}
+
+	fmt.Printf("%-36s %-8s %-24s %-6s %s\n", "SPECIES", "SONG", "VOICES", "FILES", "LAST UPDATED")
+	for _, entry := range entries {
+		song := "yes"
+		if entry.SongUnavailable {
+			song = "no"
+		}
+		updated := "-"
+		if !entry.LastUpdated.IsZero() {
+			updated = entry.LastUpdated.Format("2006-01-02")
+		}
+		fmt.Printf("%-36s %-8s %-24s %-6d %s\n", entry.Directory, song, strings.Join(entry.Voices, ","), len(entry.Assets), updated)
+		if showMissing {
+			fmt.Printf("    missing: %s\n", strings.Join(entry.Missing, ", "))
+		}
+	}
+	fmt.Printf("\n%d species\n", len(entries))
+}
+
+func printSpecies(entry *services.CatalogSpecies) {
+	fmt.Printf("Species:      %s\n", entry.Species)
+	if entry.Region != "" {
+		fmt.Printf("Region:       %s\n", entry.Region)
+	}
+	fmt.Printf("Song:         %v\n", !entry.SongUnavailable)
+	fmt.Printf("Voices:       %s\n", strings.Join(entry.Voices, ", "))
+	fmt.Printf("Last updated: %s\n\n", entry.LastUpdated.Format("2006-01-02 15:04"))
+
+	for _, asset := range entry.Assets {
+		duration := ""
+		if asset.DurationSeconds > 0 {
+			duration = fmt.Sprintf("%.1fs", asset.DurationSeconds)
+		}
+		fmt.Printf("  %-40s %8s  %s\n", asset.File, duration, asset.ModifiedAt.Format("2006-01-02"))
+	}
+
+	if len(entry.Missing) > 0 {
+		fmt.Printf("\nMissing: %s\n", strings.Join(entry.Missing, ", "))
+	}
+}
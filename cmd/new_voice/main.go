@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/api"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+)
+
+// Renders shorter than this are almost always truncated or empty responses
+const minAssetSeconds = 1.5
+
+func main() {
+	voiceID := flag.String("voice-id", "", "ElevenLabs voice ID (defaults to the LOCALE_VOICES entry for -locale)")
+	voiceName := flag.String("name", "", "Narrator name used in asset filenames (e.g. Amelia)")
+	modelID := flag.String("model", services.DefaultElevenLabsModel, "ElevenLabs model ID")
+	locale := flag.String("locale", "en", "Narration language for the intro and outro scripts (en, fr, de, es)")
+	outrosPerType := flag.Int("outros-per-type", 3, "Outro recordings to generate for each outro type")
+	ambience := flag.String("ambience", "morning_birds,forest,meadow,night", "Comma-separated nature sounds to mix under each intro")
+	force := flag.Bool("force", false, "Regenerate files that already exist")
+	flag.Parse()
+
+	cfg := config.Load()
+	api.ConfigureServices(cfg)
+
+	*locale = services.NormalizeLocale(*locale)
+	if *voiceID == "" {
+		*voiceID = services.NewVoiceManager(cfg.LocaleVoices, "").VoiceForLocale(*locale)
+	}
+
+	if *voiceID == "" || *voiceName == "" {
+		fmt.Println("Usage: new_voice -voice-id <elevenlabs id> -name <NarratorName> [-locale fr]")
+		os.Exit(1)
+	}
+	if strings.ContainsAny(*voiceName, " _/") {
+		log.Fatalf("Voice name %q must be a single word; it is matched by the outro_<type>_*_<name>.mp3 pattern", *voiceName)
+	}
+
+	if cfg.ElevenLabsAPIKey == "" {
+		log.Fatal("ELEVENLABS_API_KEY is not set")
+	}
+
+	caps := services.BootstrapFFmpeg(api.FFmpegOptions(cfg))
+	elevenLabs := services.NewElevenLabsTTS(cfg.ElevenLabsAPIKey, *modelID)
+	elevenLabs.SetCache(services.NewTTSCacheFor(cfg.TTSCacheBackend, cfg.TTSCacheBucket, cfg.TTSCacheDir))
+	tts := &ttsClient{
+		voiceID: *voiceID,
+		tts:     elevenLabs,
+	}
+
+	introDir := filepath.Dir(services.IntroManifestPath)
+	outroDir := filepath.Dir(services.OutroManifestPath)
+
+	fmt.Printf("🎙️  Generating %s assets for %s (%s)\n", *locale, *voiceName, *voiceID)
+
+	assetLocale := *locale
+	if assetLocale == services.DefaultLocale {
+		assetLocale = ""
+	}
+
+	// Intros
+	introAssets := &services.VoiceAssets{VoiceID: *voiceID, Locale: assetLocale, GeneratedAt: time.Now().UTC()}
+	mixer := services.NewIntroMixer()
+	for i, script := range services.NewIntroManagerForLocale(*locale, nil).Intros() {
+		file := fmt.Sprintf("intro_%02d_%s.mp3", i+1, *voiceName)
+		audio, err := tts.render(filepath.Join(introDir, file), script, *force)
+		if err != nil {
+			log.Fatalf("Failed to generate %s: %v", file, err)
+		}
+		introAssets.Files = append(introAssets.Files, services.VoiceAssetFile{File: file, Kind: "intro"})
+
+		if !caps.Mixing {
+			continue
+		}
+		for _, sound := range strings.Split(*ambience, ",") {
+			sound = strings.TrimSpace(sound)
+			if sound == "" {
+				continue
+			}
+			mixedFile := filepath.Join("with_nature", fmt.Sprintf("intro_%02d_%s_%s.mp3", i+1, *voiceName, sound))
+			if err := writeMixedIntro(mixer, filepath.Join(introDir, mixedFile), audio, sound, *force); err != nil {
+				log.Fatalf("Failed to mix %s: %v", mixedFile, err)
+			}
+			introAssets.Files = append(introAssets.Files, services.VoiceAssetFile{File: mixedFile, Kind: "intro", Ambience: sound})
+		}
+	}
+	if !caps.Mixing {
+		fmt.Println("⚠️  ffmpeg mixing unavailable - skipped nature-mixed intro variants")
+	}
+
+	// Outros
+	outroAssets := &services.VoiceAssets{VoiceID: *voiceID, Locale: assetLocale, GeneratedAt: time.Now().UTC()}
+	scripts := services.NewOutroManagerForLocale(*locale, nil).StaticOutroScripts(*outrosPerType)
+	for _, outroType := range services.StaticOutroTypes {
+		for i, script := range scripts[outroType] {
+			file := fmt.Sprintf("outro_%s_%02d_%s.mp3", outroType, i+1, *voiceName)
+			if _, err := tts.render(filepath.Join(outroDir, file), script, *force); err != nil {
+				log.Fatalf("Failed to generate %s: %v", file, err)
+			}
+			outroAssets.Files = append(outroAssets.Files, services.VoiceAssetFile{File: file, Kind: outroType})
+		}
+	}
+
+	// Validate with the audio probe before recording the voice as available
+	failed := false
+	for _, entry := range []struct {
+		path   string
+		assets *services.VoiceAssets
+	}{
+		{services.IntroManifestPath, introAssets},
+		{services.OutroManifestPath, outroAssets},
+	} {
+		manifest, err := services.LoadVoiceManifest(entry.path)
+		if err != nil {
+			log.Fatalf("Failed to load manifest: %v", err)
+		}
+		manifest.SetVoice(*voiceName, entry.assets)
+
+		problems := manifest.Validate(*voiceName, minAssetSeconds)
+		for _, problem := range problems {
+			fmt.Printf("❌ %s\n", problem)
+		}
+		if len(problems) > 0 {
+			failed = true
+			continue
+		}
+
+		if err := manifest.Save(); err != nil {
+			log.Fatalf("Failed to save %s: %v", entry.path, err)
+		}
+		fmt.Printf("✅ Updated %s (%d files)\n", entry.path, len(entry.assets.Files))
+	}
+
+	if !caps.Probe {
+		fmt.Println("⚠️  ffprobe unavailable - durations were not verified")
+	}
+	if failed {
+		fmt.Println("❌ Validation failed; fix or regenerate with -force before deploying")
+		os.Exit(1)
+	}
+
+	fmt.Printf("🎉 %s is ready to narrate\n", *voiceName)
+}
+
+// ttsClient renders scripts with the ElevenLabs text-to-speech API
+type ttsClient struct {
+	voiceID string
+	tts     *services.ElevenLabsTTS
+}
+
+// render writes the speech for text to path and returns the audio, reusing an existing file unless force is set
+func (t *ttsClient) render(path string, text string, force bool) ([]byte, error) {
+	if !force {
+		if data, err := os.ReadFile(path); err == nil {
+			fmt.Printf("   Using existing %s\n", filepath.Base(path))
+			return data, nil
+		}
+	}
+
+	audio, cached, err := t.tts.Render(context.Background(), text, t.voiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, audio, 0644); err != nil {
+		return nil, err
+	}
+
+	if cached {
+		fmt.Printf("   Restored %s from TTS cache (%d bytes)\n", filepath.Base(path), len(audio))
+	} else {
+		fmt.Printf("   Generated %s (%d bytes)\n", filepath.Base(path), len(audio))
+	}
+	return audio, nil
+}
+
+// writeMixedIntro mixes an intro with a nature sound and saves it, skipping existing files unless force is set
+func writeMixedIntro(mixer *services.IntroMixer, path string, introData []byte, sound string, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+
+	mixed, err := mixer.MixIntroWithNatureSounds(introData, sound)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, mixed, 0644)
+}
@@ -1,26 +1,52 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
 
 	"github.com/callen/bird-song-explorer/internal/api"
 	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/logging"
+	"github.com/callen/bird-song-explorer/internal/preview"
+	"github.com/callen/bird-song-explorer/internal/services"
 )
 
 func main() {
+	dryRun := flag.Bool("dry-run", false, "Build the card's update without calling Yoto or ElevenLabs and write a preview bundle instead of serving")
+	outDir := flag.String("out", "dry_run_preview", "Folder for the dry run's preview bundle")
+	cardID := flag.String("card", "", "Card to build in the dry run (default: the default card)")
+	offline := flag.Bool("offline", false, "Refuse the dry run's requests to anything but its fakes (eBird, Xeno-canto, and the recordings bucket included)")
+	flag.Parse()
+
 	cfg := config.Load()
+	logging.Setup(cfg.LogFormat, cfg.YotoCardID, cfg.GCPProjectID)
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	for _, warning := range cfg.Warnings() {
+		log.Printf("[CONFIG] %s", warning)
+	}
 
-	router := api.SetupRouter(cfg)
+	// Verify ffmpeg before serving so the audio engine knows which operations are available
+	services.BootstrapFFmpeg(api.FFmpegOptions(cfg))
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	if *dryRun {
+		result, err := preview.Run(cfg, preview.Options{OutDir: *outDir, CardID: *cardID, Offline: *offline})
+		if err != nil {
+			log.Fatalf("Dry run failed: %v", err)
+		}
+		fmt.Printf("Dry run preview for %s on card %s written to %s (%d tracks, %d narrated scripts)\n",
+			result.Bird, result.CardID, result.Dir, result.Tracks, result.Scripts)
+		return
 	}
 
-	log.Printf("Starting Bird Song Explorer server on port %s", port)
-	if err := http.ListenAndServe(":"+port, router); err != nil {
+	api.ConfigureServices(cfg)
+	router := api.SetupRouter(cfg)
+
+	log.Printf("Starting Bird Song Explorer server on port %s", cfg.Port)
+	if err := http.ListenAndServe(":"+cfg.Port, router); err != nil {
 		log.Fatal("Server failed to start:", err)
 	}
 }
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/api"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/preview"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeTransport answers every outbound request in-process so the soak run never touches the network
+type fakeTransport struct {
+	requests atomic.Int64 // Card jobs and background refreshes call out from their own goroutines
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+
+	body := "{}"
+	switch {
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/content/"):
+		body = `{"card":{"cardId":"soak","title":"Bird Song Explorer","metadata":{}}}`
+	case req.Method == "POST" && req.URL.Path == "/content":
+		body = `{"cardId":"soak","status":"ok"}`
+	case strings.Contains(req.URL.Path, "/media/displayIcons/user/me/upload"):
+		body = `{"displayIcon":{"mediaId":"soak-icon","new":true}}`
+	}
+
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// simClock is the simulated time the handlers read, advanced through each day's plays
+type simClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *simClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *simClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *simClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type sample struct {
+	Day        int
+	Goroutines int
+	HeapMB     float64
+	TempFiles  int
+	Stats      map[string]interface{}
+}
+
+func main() {
+	days := flag.Int("days", 30, "Number of simulated days")
+	playsPerDay := flag.Int("plays", 50, "Simulated card plays per day (each play streams all four tracks)")
+	maxGoroutineGrowth := flag.Int("max-goroutine-growth", 20, "Fail if goroutines grow by more than this")
+	maxHeapGrowthMB := flag.Float64("max-heap-growth-mb", 50, "Fail if heap grows by more than this many MB")
+	maxTempFileGrowth := flag.Int("max-temp-file-growth", 10, "Fail if temp audio files grow by more than this")
+	// Plays are spread over the simulated day, so sessions older than their expiry are swept
+	maxSessions := flag.Int("max-sessions", 500, "Fail if live streaming sessions exceed this")
+	verbose := flag.Bool("verbose", false, "Show pipeline logs")
+	flag.Parse()
+
+	report := os.Stdout
+	if !*verbose {
+		log.SetOutput(io.Discard)
+		if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+			os.Stdout = devNull
+		}
+	}
+
+	transport := &fakeTransport{}
+	http.DefaultTransport = transport
+
+	gin.SetMode(gin.ReleaseMode)
+	gin.DefaultWriter = io.Discard
+
+	// The pipeline keeps its state under ./data, so run in a scratch directory to keep it out of the
+	// working tree, with the assets it reads linked in
+	workDir, err := os.MkdirTemp("", "soak")
+	if err != nil {
+		fmt.Fprintf(report, "❌ failed to create a work dir: %v\n", err)
+		os.Exit(1)
+	}
+	if assets, err := filepath.Abs("assets"); err == nil {
+		os.Symlink(assets, filepath.Join(workDir, "assets"))
+	}
+	if err := os.Chdir(workDir); err != nil {
+		fmt.Fprintf(report, "❌ failed to enter the work dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Start from the defaults, so timeouts and limits are the ones production runs with. Loading
+	// in the work dir skips the checkout's .env; state and credentials are then pointed at fakes.
+	cfg := config.Load()
+	preview.IsolateState(cfg, filepath.Join(workDir, "data"))
+	cfg.Environment = "soak"
+	cfg.YotoClientID = "soak-client"
+	cfg.YotoAccessToken = "soak-access"
+	cfg.YotoRefreshToken = "soak-refresh"
+	cfg.YotoCardID = "soak"
+	cfg.YotoAPIBaseURL = "https://api.yotoplay.com"
+	cfg.Cards = config.NewCardRegistry(nil, cfg.YotoCardID)
+	cfg.SchedulerToken = "soak-token"
+	cfg.CronSecret = cfg.SchedulerToken
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(report, "❌ invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	api.ConfigureServices(cfg)
+
+	// Days start at 13:00 UTC, after the 12:00 switch to the new day's bird
+	clock := &simClock{}
+	firstDay := time.Date(2026, time.January, 5, 13, 0, 0, 0, time.UTC)
+	clock.Set(firstDay)
+	api.SetClock(clock.Now)
+	playInterval := 24 * time.Hour / time.Duration(*playsPerDay+1)
+
+	handler := api.NewHandler(cfg)
+	router := api.NewRouter(cfg, handler)
+
+	var samples []sample
+	baseline := takeSample(0, handler)
+	samples = append(samples, baseline)
+
+	failedUpdates := 0
+	start := time.Now()
+	for day := 1; day <= *days; day++ {
+		clock.Set(firstDay.AddDate(0, 0, day-1))
+		if status := doRequest(router, "POST", "/api/v1/daily-update", map[string]string{"X-Scheduler-Token": cfg.SchedulerToken}); status != http.StatusOK {
+			failedUpdates++
+		}
+
+		for play := 0; play < *playsPerDay; play++ {
+			clock.Advance(playInterval)
+			session := fmt.Sprintf("soak_%d_%d", day, play)
+			for _, track := range []string{"intro", "announcement", "description", "outro"} {
+				doRequest(router, "GET", fmt.Sprintf("/api/v1/stream/%s?session=%s", track, session), nil)
+			}
+		}
+
+		samples = append(samples, takeSample(day, handler))
+	}
+
+	os.RemoveAll(workDir)
+
+	final := samples[len(samples)-1]
+	fmt.Fprintf(report, "Soak run: %d days x %d plays in %s (%d outbound requests faked)\n",
+		*days, *playsPerDay, time.Since(start).Round(time.Millisecond), transport.requests.Load())
+	fmt.Fprintf(report, "%5s %11s %9s %10s %s\n", "day", "goroutines", "heap_mb", "temp_files", "stats")
+	for _, s := range samples {
+		fmt.Fprintf(report, "%5d %11d %9.2f %10d %v\n", s.Day, s.Goroutines, s.HeapMB, s.TempFiles, s.Stats)
+	}
+
+	var failures []string
+	if growth := final.Goroutines - baseline.Goroutines; growth > *maxGoroutineGrowth {
+		failures = append(failures, fmt.Sprintf("goroutines grew by %d (limit %d)", growth, *maxGoroutineGrowth))
+	}
+	if growth := final.HeapMB - baseline.HeapMB; growth > *maxHeapGrowthMB {
+		failures = append(failures, fmt.Sprintf("heap grew by %.2f MB (limit %.2f)", growth, *maxHeapGrowthMB))
+	}
+	if growth := final.TempFiles - baseline.TempFiles; growth > *maxTempFileGrowth {
+		failures = append(failures, fmt.Sprintf("temp files grew by %d (limit %d)", growth, *maxTempFileGrowth))
+	}
+	if sessions, ok := final.Stats["streaming_sessions"].(int); ok && sessions > *maxSessions {
+		failures = append(failures, fmt.Sprintf("%d live sessions (limit %d)", sessions, *maxSessions))
+	}
+	// Every update runs against fakes that always succeed, so anything failed or left queued is a bug
+	if failedUpdates > 0 {
+		failures = append(failures, fmt.Sprintf("%d of %d daily updates failed", failedUpdates, *days))
+	}
+	if jobs, ok := final.Stats["card_jobs"].(map[string]interface{}); ok {
+		if pending, _ := jobs["pending"].(int); pending > 0 {
+			failures = append(failures, fmt.Sprintf("%d card jobs still queued (%v retrying)", pending, jobs["retrying"]))
+		}
+	}
+	if webhooks, ok := final.Stats["webhook_queue"].(map[string]interface{}); ok {
+		if pending, _ := webhooks["pending"].(int); pending > 0 {
+			failures = append(failures, fmt.Sprintf("%d webhooks still queued", pending))
+		}
+	}
+
+	if len(failures) > 0 {
+		for _, f := range failures {
+			fmt.Fprintf(report, "❌ %s\n", f)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(report, "✅ No leaks detected")
+}
+
+// doRequest calls the router in-process and returns the response status
+func doRequest(router http.Handler, method, path string, headers map[string]string) int {
+	req := httptest.NewRequest(method, path, bytes.NewReader(nil))
+	// Loopback address skips IP geolocation
+	req.RemoteAddr = "127.0.0.1:12345"
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func takeSample(day int, handler *api.Handler) sample {
+	// Give cleanup goroutines a chance to finish before measuring
+	time.Sleep(50 * time.Millisecond)
+	runtime.GC()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return sample{
+		Day:        day,
+		Goroutines: runtime.NumGoroutine(),
+		HeapMB:     float64(mem.HeapAlloc) / 1024 / 1024,
+		TempFiles:  countTempAudioFiles(),
+		Stats:      handler.Stats(),
+	}
+}
+
+// countTempAudioFiles counts mixer scratch files left in the temp directory
+func countTempAudioFiles() int {
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), "*.mp3"))
+	if err != nil {
+		return 0
+	}
+	return len(matches)
+}
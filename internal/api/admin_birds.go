@@ -0,0 +1,177 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type pinRequest struct {
+	BirdName string `json:"bird_name" binding:"required"`
+	Date     string `json:"date"` // YYYY-MM-DD; defaults to tomorrow (UTC)
+}
+
+type blockRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListCards returns each registered card with its region, today's bird, and tomorrow's pin
+func (h *Handler) ListCards(c *gin.Context) {
+	now := clock().UTC()
+	today := now.Format("2006-01-02")
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+
+	cards := make([]gin.H, 0, len(h.config.Cards.Cards()))
+	for _, card := range h.config.Cards.Cards() {
+		region := cardRegion(card)
+		entry := gin.H{
+			"card":   card.CardID,
+			"title":  card.DisplayTitle(),
+			"region": region,
+			"date":   today,
+		}
+		if birdName, exists := h.dailyBird(region, today); exists {
+			entry["bird"] = birdName
+		}
+		if pinned, ok := h.overrides.PinnedBird(region, tomorrow); ok {
+			entry["pinned_tomorrow"] = pinned
+		}
+		cards = append(cards, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cards": cards})
+}
+
+// RefreshCard republishes a card with its region's bird for today, like the daily update for one card
+func (h *Handler) RefreshCard(c *gin.Context) {
+	card, exists := h.config.Cards.Get(c.Param("card"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown card"})
+		return
+	}
+
+	log.Printf("[ADMIN] Forcing refresh of card %s", card.CardID)
+	response, err := h.updateCardForDay(c.Request.Context(), card, h.cardDefaultTime(card), h.webhookBaseURL(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListPins returns every pinned bird
+func (h *Handler) ListPins(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"pins": h.overrides.Pins()})
+}
+
+// PinBird pins the bird a region plays on a date, tomorrow by default. Days whose bird has
+// already been recorded can't be changed.
+func (h *Handler) PinBird(c *gin.Context) {
+	region := strings.ToLower(c.Param("region"))
+	if region == services.RegionAuto || !h.availableBirds.IsKnownRegion(region) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown region"})
+		return
+	}
+
+	var request pinRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bird_name is required"})
+		return
+	}
+
+	now := clock().UTC()
+	date := request.Date
+	if date == "" {
+		date = now.AddDate(0, 0, 1).Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	if date < now.Format("2006-01-02") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot pin a past date"})
+		return
+	}
+	if recorded, exists := h.dailyBird(region, date); exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bird already selected for this date", "bird": recorded})
+		return
+	}
+
+	bird := h.availableBirds.GetBirdByName(request.BirdName)
+	if bird == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bird has no prerecorded content"})
+		return
+	}
+
+	pin, err := h.overrides.Pin(region, date, bird.CommonName)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to save pin: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save pin"})
+		return
+	}
+	c.JSON(http.StatusOK, pin)
+}
+
+// UnpinBird removes a region's pin for a date
+func (h *Handler) UnpinBird(c *gin.Context) {
+	removed, err := h.overrides.Unpin(strings.ToLower(c.Param("region")), c.Param("date"))
+	if err != nil {
+		log.Printf("[ADMIN] Failed to remove pin: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove pin"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pin for that region and date"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetBlocklist returns the species the rotation skips
+func (h *Handler) GetBlocklist(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"blocklist": h.overrides.Blocklist()})
+}
+
+// BlockSpecies adds a species to the blocklist
+func (h *Handler) BlockSpecies(c *gin.Context) {
+	var request blockRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	bird := h.availableBirds.GetBirdByName(c.Param("species"))
+	if bird == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown species"})
+		return
+	}
+
+	entry, err := h.overrides.Block(bird.CommonName, request.Reason)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to save blocklist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save blocklist"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// UnblockSpecies removes a species from the blocklist
+func (h *Handler) UnblockSpecies(c *gin.Context) {
+	removed, err := h.overrides.Unblock(c.Param("species"))
+	if err != nil {
+		log.Printf("[ADMIN] Failed to save blocklist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save blocklist"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Species is not blocklisted"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdminToken rejects admin requests without the scheduler token when one is configured
+func (h *Handler) requireAdminToken(c *gin.Context) {
+	expectedToken := h.config.SchedulerToken
+	if expectedToken != "" && c.GetHeader("X-Scheduler-Token") != expectedToken {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid scheduler token"})
+		return
+	}
+	c.Next()
+}
+
+// PreviewTranscript generates the guide script for a bird and returns each sentence with its source,
+// alongside the stored transcript if there is one. Posted to /admin/transcript, the new transcript
+// is stored too.
+// The card and day parameters pick the seed, so a card's build for a day can be reproduced.
+func (h *Handler) PreviewTranscript(c *gin.Context) {
+	birdName := c.Query("bird")
+	if birdName == "" {
+		birdName = h.currentDailyBird()
+	}
+	if birdName == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No bird selected yet"})
+		return
+	}
+
+	generatorType := c.DefaultQuery("generator", h.config.FactGenerator)
+	latitude, _ := strconv.ParseFloat(c.Query("lat"), 64)
+	longitude, _ := strconv.ParseFloat(c.Query("lng"), 64)
+
+	bird := &models.Bird{CommonName: birdName}
+	if metadata, err := h.birdStorage.GetBirdMetadata(birdName); err == nil {
+		bird.ScientificName = metadata.ScientificName
+		bird.Family = metadata.Family
+	}
+
+	locale := c.DefaultQuery("locale", h.config.ContentLocale)
+	// The same card and day (YYYY-MM-DD, today by default) always preview the same phrasing
+	day := c.DefaultQuery("day", clock().UTC().Format("2006-01-02"))
+	generator := services.NewFactGeneratorForLocale(generatorType, h.config.EBirdAPIKey, locale, randx.Daily(day, c.Query("card")))
+	transcript := generator.GenerateFactTranscript(c.Request.Context(), bird, latitude, longitude)
+
+	response := gin.H{
+		"bird":       birdName,
+		"locale":     services.NormalizeLocale(locale),
+		"transcript": transcript,
+	}
+	if stored, err := h.birdStorage.GetTranscript(birdName); err == nil {
+		response["stored_transcript"] = stored
+	}
+
+	// Themed intro and outro scripts, for rendering the theme's narration
+	now := clock().UTC()
+	if theme, ok := h.themes.ThemeOn(now); ok {
+		response["theme"] = gin.H{
+			"key":   theme.Key,
+			"name":  theme.Name,
+			"intro": theme.IntroText(now, birdName),
+			"outro": theme.OutroText(now, birdName),
+		}
+	}
+
+	if c.Request.Method == http.MethodPost {
+		if err := h.birdStorage.SaveTranscript(transcript); err != nil {
+			log.Printf("[ADMIN] Failed to save transcript for %s: %v", birdName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save transcript"})
+			return
+		}
+		response["saved"] = true
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCatalog lists the pre-recorded TTS catalog. Use ?species= for one entry, ?missing=true to
+// only return species with missing assets, and ?durations=true to probe file durations.
+func (h *Handler) GetCatalog(c *gin.Context) {
+	withDurations := c.Query("durations") == "true"
+
+	if species := c.Query("species"); species != "" {
+		entry, err := h.ttsCatalog.Get(species, withDurations)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+		return
+	}
+
+	entries, err := h.ttsCatalog.List(withDurations)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to read TTS catalog: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read catalog"})
+		return
+	}
+
+	if c.Query("missing") == "true" {
+		incomplete := []services.CatalogSpecies{}
+		for _, entry := range entries {
+			if len(entry.Missing) > 0 {
+				incomplete = append(incomplete, entry)
+			}
+		}
+		entries = incomplete
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"root":    h.ttsCatalog.Root(),
+		"count":   len(entries),
+		"species": entries,
+	})
+}
+
+// GetConfig returns the running configuration with secrets masked, any problems Validate finds,
+// and the warnings logged at startup
+func (h *Handler) GetConfig(c *gin.Context) {
+	response := gin.H{
+		"settings": h.config.Redacted(),
+		"warnings": h.config.Warnings(),
+		"valid":    true,
+	}
+	if err := h.config.Validate(); err != nil {
+		response["valid"] = false
+		response["error"] = err.Error()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTTSQuota returns ElevenLabs character usage and what's left of the daily and monthly budgets
+func (h *Handler) GetTTSQuota(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ttsQuota.Status())
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamBirdHero plays the "Be a Bird Hero!" chapter explaining why the session's bird is rare and
+// one way children can help. Birds that aren't threatened get the silent skip clip.
+func (h *Handler) StreamBirdHero(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Query("session")
+	session := h.getOrCreateSession(c, sessionID)
+
+	birdName := session.BirdName
+	if birdName == "" {
+		selectedBird, err := h.getDailyBirdWithFallback(c, "bird_hero")
+		if err != nil {
+			slog.WarnContext(ctx, "[STREAMING] bird_hero: No bird for session", "error", err)
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		birdName = selectedBird
+		session.BirdName = birdName
+		putSession(session)
+	}
+
+	voiceID := h.narratorVoice(session.VoiceID, services.VoiceRoleBirdHero, locationLocalTime(session.Location))
+
+	story, err := h.birdHero.GenerateStory(ctx, birdName, voiceID)
+	if err != nil {
+		slog.InfoContext(ctx, "[STREAMING] bird_hero: No story, skipping", "bird", birdName, "error", err)
+		streamSkip(c)
+		return
+	}
+
+	slog.InfoContext(ctx, "[STREAMING] bird_hero: Playing story", "bird", birdName, "status", story.Status)
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "audio/mpeg", story.Audio)
+}
+
+// birdHeroAudio renders the bird hero story, falling back to the silent skip clip for birds that
+// aren't threatened or when it can't be made
+func (h *Handler) birdHeroAudio(ctx context.Context, birdName string, voiceID string, localNow time.Time) (*services.StreamAudio, error) {
+	voiceID = h.narratorVoice(voiceID, services.VoiceRoleBirdHero, localNow)
+	story, err := h.birdHero.GenerateStory(ctx, birdName, voiceID)
+	if err == nil {
+		return services.NewStreamAudio(story.Audio), nil
+	}
+	slog.InfoContext(ctx, "[STREAMING] bird_hero: No story, skipping", "bird", birdName, "error", err)
+	return skipClip, nil
+}
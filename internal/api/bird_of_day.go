@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/internal/store"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
+)
+
+var birdSelections = metrics.NewCounterVec("bird_explorer_bird_selections_total",
+	"Daily birds newly selected, by bird-of-day region", "region")
+
+// dailyGlobalBird returns the day's global bird from the in-memory cache, then the persistent store,
+// so a restarted instance keeps serving the bird that was already selected
+func (h *Handler) dailyGlobalBird(date string) (string, bool) {
+	return h.dailyBird(store.RegionGlobal, date)
+}
+
+// recordDailyGlobalBird stores the day's global bird unless one is already recorded,
+// returning the bird every instance should use
+func (h *Handler) recordDailyGlobalBird(date string, birdName string) string {
+	return h.recordDailyBird(store.RegionGlobal, date, birdName)
+}
+
+// dailyBird returns the day's bird for a region. Only the global bird is kept in the update cache;
+// regional cards read the store directly.
+func (h *Handler) dailyBird(region string, date string) (string, bool) {
+	if region == store.RegionGlobal {
+		if birdName, exists := h.updateCache.GetDailyGlobalBird(date); exists && birdName != "" {
+			return birdName, true
+		}
+	}
+
+	record, err := h.birdOfDay.Get(region, date)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[BIRD_STORE] Failed to read %s bird for %s: %v", region, date, err)
+		}
+		return "", false
+	}
+
+	if region == store.RegionGlobal {
+		h.updateCache.SetDailyGlobalBird(date, record.BirdName)
+	}
+	return record.BirdName, true
+}
+
+// recordDailyBird stores the day's bird for a region unless one is already recorded
+func (h *Handler) recordDailyBird(region string, date string, birdName string) string {
+	record, err := h.birdOfDay.Record(store.BirdOfDay{
+		Region:   region,
+		Date:     date,
+		BirdName: birdName,
+	})
+	if err != nil {
+		log.Printf("[BIRD_STORE] Failed to record %s for %s (%s): %v", birdName, date, region, err)
+	} else if record.BirdName != birdName {
+		log.Printf("[BIRD_STORE] %s already selected for %s (%s), keeping it instead of %s", record.BirdName, date, region, birdName)
+		birdName = record.BirdName
+	} else {
+		birdSelections.Inc(region)
+	}
+
+	if region == store.RegionGlobal {
+		h.updateCache.SetDailyGlobalBird(date, birdName)
+	}
+	return birdName
+}
+
+// cardRegion is the bird-of-day store region a card draws from
+func cardRegion(card config.CardProfile) string {
+	if card.IsGlobal() {
+		return store.RegionGlobal
+	}
+	return card.Region
+}
+
+// autoRegion is the region pack for the country the device was last located in, or the global
+// pool when its country has no pack
+func (h *Handler) autoRegion(deviceID string) string {
+	if record, ok := h.deviceRegistry.Get(deviceID); ok && record.Location != nil {
+		if pack := services.RegionPackForCountry(record.Location.Country); pack != nil {
+			return pack.ID
+		}
+	}
+	return store.RegionGlobal
+}
+
+// rotationBirdForCard picks the bird for day's calendar date from the card's species pool,
+// before holiday theming. An admin pin for the date wins, and blocklisted species give up
+// their day to the next bird in the rotation.
+func (h *Handler) rotationBirdForCard(card config.CardProfile, day time.Time) *models.Bird {
+	if pinned, ok := h.overrides.PinnedBird(cardRegion(card), day.Format("2006-01-02")); ok {
+		if bird := h.availableBirds.GetBirdByName(pinned); bird != nil {
+			log.Printf("[OVERRIDES] Using pinned %s for %s", bird.CommonName, cardRegion(card))
+			return bird
+		}
+	}
+
+	for offset := 0; offset < len(h.availableBirds.GetAllAvailableBirds()); offset++ {
+		bird := h.poolBirdForCard(card, day.AddDate(0, 0, offset))
+		if bird == nil || !h.overrides.IsBlocked(bird.CommonName) {
+			return bird
+		}
+		log.Printf("[OVERRIDES] Skipping blocklisted %s", bird.CommonName)
+	}
+
+	log.Printf("[OVERRIDES] Every bird in the %s pool is blocklisted, ignoring the blocklist", cardRegion(card))
+	return h.poolBirdForCard(card, day)
+}
+
+// specialGuestForCard returns the card's special guest for day's calendar date, or nil when it
+// isn't the region's guest day, an admin pinned the day's bird, or the guest is blocklisted
+func (h *Handler) specialGuestForCard(card config.CardProfile, day time.Time) *models.Bird {
+	if _, pinned := h.overrides.PinnedBird(cardRegion(card), day.Format("2006-01-02")); pinned {
+		return nil
+	}
+	guest := h.availableBirds.GetSpecialGuestOn(cardRegion(card), day)
+	if guest == nil || h.overrides.IsBlocked(guest.CommonName) {
+		return nil
+	}
+	return guest
+}
+
+// upcomingSpecies returns the scientific names of the birds cards are likely to need over the
+// next RecordingWarmDays: each card's rotation bird and, for cards with a quiz and a default
+// location, the quiz's mystery bird
+func (h *Handler) upcomingSpecies(ctx context.Context, now time.Time) []string {
+	var names []string
+	for _, card := range h.config.Cards.Cards() {
+		includeQuiz := h.config.EnableBirdQuiz
+		if card.IncludeQuiz != nil {
+			includeQuiz = *card.IncludeQuiz
+		}
+		location, hasLocation := h.defaultLocations.Resolve(card.CardID)
+
+		for day := 0; day < services.RecordingWarmDays; day++ {
+			bird := h.rotationBirdForCard(card, now.AddDate(0, 0, day))
+			if guest := h.specialGuestForCard(card, now.AddDate(0, 0, day)); guest != nil {
+				bird = guest
+			}
+			if bird == nil {
+				continue
+			}
+			names = append(names, bird.ScientificName)
+
+			if includeQuiz && hasLocation {
+				if mystery, err := h.quizGenerator.SelectMysteryBird(ctx, bird.CommonName, location.Latitude, location.Longitude); err == nil {
+					names = append(names, mystery.ScientificName)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// poolBirdForCard returns the card's species pool bird for day's calendar date
+func (h *Handler) poolBirdForCard(card config.CardProfile, day time.Time) *models.Bird {
+	if card.IsGlobal() {
+		return h.availableBirds.GetCyclingBirdOn(day)
+	}
+	return h.availableBirds.GetBirdForContinentOn(card.Region, day)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/gin-gonic/gin"
+)
+
+// birdOfWeekEnabled reports whether the card keeps one bird all week, with a themed fact chapter
+// that changes every day
+func (h *Handler) birdOfWeekEnabled(card config.CardProfile) bool {
+	if card.BirdOfWeek != nil {
+		return *card.BirdOfWeek
+	}
+	return h.config.EnableBirdOfWeek
+}
+
+// weeklyBirdForCard returns the card's bird for localNow's week, choosing the rotation bird for
+// the week's Monday on first use. The choice is the card's own, so other cards in the region keep
+// their daily birds.
+func (h *Handler) weeklyBirdForCard(card config.CardProfile, localNow time.Time) (*models.Bird, error) {
+	weekStart := services.WeekStart(localNow)
+	if name, ok := h.weeklySchedule.BirdForWeek(card.CardID, weekStart); ok {
+		if bird := h.availableBirds.GetBirdByName(name); bird != nil {
+			return bird, nil
+		}
+	}
+
+	monday, err := time.Parse("2006-01-02", weekStart)
+	if err != nil {
+		return nil, err
+	}
+	bird := h.rotationBirdForCard(card, monday)
+	if bird == nil {
+		return nil, fmt.Errorf("no bird available for region %s", cardRegion(card))
+	}
+	h.weeklySchedule.StartWeek(card.CardID, weekStart, bird.CommonName)
+	slog.Info("[BIRD_OF_WEEK] Starting a new week", "card_id", card.CardID, "week_start", weekStart, "bird", bird.CommonName)
+	return bird, nil
+}
+
+// publishWeeklyCard updates a bird-of-the-week card. The first update of the week publishes the
+// whole card; later days only swap the fact chapter, falling back to a full update when the card
+// has lost it.
+func (h *Handler) publishWeeklyCard(ctx context.Context, contentManager *yoto.ContentManager, job services.CardJob, sessionID string) error {
+	weekStart := services.WeekStart(h.jobDay(job))
+	if h.weeklySchedule.WeekPublished(job.CardID, weekStart) {
+		err := contentManager.UpdateStreamingChapter(job.CardID, yoto.SegmentWeeklyFact, job.BaseURL, sessionID)
+		if !errors.Is(err, yoto.ErrChapterNotFound) {
+			if err == nil {
+				h.weeklySchedule.MarkPublished(job.CardID, weekStart, job.Day)
+			}
+			return err
+		}
+		slog.WarnContext(ctx, "[BIRD_OF_WEEK] Card has no fact chapter, publishing the whole card", "card_id", job.CardID, "error", err)
+	}
+
+	if err := contentManager.UpdateCardWithStreamingTracks(job.CardID, job.BirdName, job.BaseURL, sessionID); err != nil {
+		return err
+	}
+	h.weeklySchedule.MarkPublished(job.CardID, weekStart, job.Day)
+	return nil
+}
+
+// jobDay is the local day a card job is for, or today when it can't be parsed
+func (h *Handler) jobDay(job services.CardJob) time.Time {
+	if day, err := time.Parse("2006-01-02", job.Day); err == nil {
+		return day
+	}
+	return clock().UTC()
+}
+
+// StreamWeeklyFact plays the bird of the week's themed fact for the listener's day
+func (h *Handler) StreamWeeklyFact(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Query("session")
+	session := h.getOrCreateSession(c, sessionID)
+
+	birdName := session.BirdName
+	if birdName == "" {
+		selectedBird, err := h.getDailyBirdWithFallback(c, "weekly_fact")
+		if err != nil {
+			slog.WarnContext(ctx, "[STREAMING] weekly_fact: No bird for session", "error", err)
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		birdName = selectedBird
+		session.BirdName = birdName
+		putSession(session)
+	}
+
+	localNow := locationLocalTime(session.Location)
+	voiceID := h.narratorVoice(session.VoiceID, services.VoiceRoleWeekly, localNow)
+
+	fact, err := h.weeklyFacts.GenerateFact(ctx, birdName, localNow, voiceID)
+	if err != nil {
+		slog.WarnContext(ctx, "[STREAMING] weekly_fact: Failed to generate fact, skipping", "bird", birdName, "error", err)
+		streamSkip(c)
+		return
+	}
+
+	slog.InfoContext(ctx, "[STREAMING] weekly_fact: Playing fact", "bird", birdName, "theme", fact.Theme)
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "audio/mpeg", fact.Audio)
+}
+
+// weeklyFactAudio renders the day's themed fact, falling back to the silent skip clip when it
+// can't be made
+func (h *Handler) weeklyFactAudio(ctx context.Context, birdName string, voiceID string, localNow time.Time) (*services.StreamAudio, error) {
+	voiceID = h.narratorVoice(voiceID, services.VoiceRoleWeekly, localNow)
+	fact, err := h.weeklyFacts.GenerateFact(ctx, birdName, localNow, voiceID)
+	if err == nil {
+		return services.NewStreamAudio(fact.Audio), nil
+	}
+	slog.WarnContext(ctx, "[STREAMING] weekly_fact: Failed to generate fact, skipping", "bird", birdName, "error", err)
+	return skipClip, nil
+}
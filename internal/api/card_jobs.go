@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/logging"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/gin-gonic/gin"
+)
+
+// streamingWeeklyFactTitle titles the weekly fact chapter on streaming cards
+const streamingWeeklyFactTitle = "Bird of the Week"
+
+// runCardJob queues a card update and runs it now, cancelling its API calls if ctx is done. When it
+// fails, or is left waiting on a Yoto transcode, the job stays queued and the card job consumer
+// resumes it from its checkpoints.
+func (h *Handler) runCardJob(ctx context.Context, job services.CardJob) error {
+	if job.RequestID == "" {
+		job.RequestID = logging.RequestID(ctx)
+	}
+	job.ID = services.CardJobID(job.CardID, job.Day, job.Trigger, job.DeviceID, job.Mode)
+
+	queued, err := h.cardJobs.Enqueue(job)
+	if err != nil {
+		// The update can still go ahead, it just won't be resumed if it fails
+		slog.WarnContext(ctx, "[CARD_JOBS] Failed to queue card update, running it unqueued", "card_id", job.CardID, "error", err)
+		return h.processCardJob(ctx, job)
+	}
+	err = h.cardJobs.Run(queued.ID, func(job services.CardJob) error {
+		return h.processCardJob(ctx, job)
+	})
+	if errors.Is(err, services.ErrCardJobWaiting) {
+		// The consumer finishes the update once Yoto catches up, so the caller needn't wait or retry
+		slog.InfoContext(ctx, "[CARD_JOBS] Card update queued until its transcodes finish", "card_id", job.CardID, "bird", job.BirdName)
+		return nil
+	}
+	return err
+}
+
+// resumeCardJob is the card job consumer, resuming a queued job with nothing waiting on it
+func (h *Handler) resumeCardJob(job services.CardJob) error {
+	return h.processCardJob(context.Background(), job)
+}
+
+// processCardJob publishes a job's bird to its card, skipping the steps a previous attempt
+// checkpointed. The lookups and the publish each run under their own deadline.
+func (h *Handler) processCardJob(ctx context.Context, job services.CardJob) error {
+	requestID := job.RequestID
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	ctx = logging.WithRequestID(ctx, requestID)
+
+	card, registered := h.config.Cards.Get(job.CardID)
+	if !registered {
+		slog.WarnContext(ctx, "[CARD_JOBS] Card is no longer registered, dropping update", "card_id", job.CardID)
+		return nil
+	}
+	if job.Region != "" {
+		card.Region = job.Region
+	}
+
+	if job.Attempts > 0 {
+		slog.InfoContext(ctx, "[CARD_JOBS] Resuming card update", "card_id", job.CardID, "bird", job.BirdName,
+			"attempt", job.Attempts+1, "checkpoints", len(job.Checkpoints))
+		h.pipelineEvents.Publish(services.EventJobStarted, job.CardID, job.BirdName, "Resuming card update")
+	}
+
+	policy := h.dependencies.Policy()
+	if policy.Active() {
+		slog.WarnContext(ctx, "[CARD_JOBS] Dependencies degraded, using fallbacks", "card_id", job.CardID, "reasons", policy.Reasons)
+	}
+
+	checkpoints := h.cardJobs.Checkpoints(job.ID)
+	contentManager := h.newContentManager(card)
+	contentManager.SetCheckpointer(checkpoints)
+	contentManager.SetProgressReporter(func(step string, message string) {
+		switch step {
+		case yoto.ProgressAudioReady:
+			h.pipelineEvents.Publish(services.EventTTSDone, job.CardID, job.BirdName, message)
+		case yoto.ProgressUploaded:
+			h.pipelineEvents.Publish(services.EventUploadDone, job.CardID, job.BirdName, message)
+		}
+	})
+	if day, err := time.Parse("2006-01-02", job.Day); err == nil {
+		contentManager.SetRandomizer(randx.Daily(job.Day, job.CardID))
+		if theme, ok := h.themes.ThemeOn(day); ok {
+			contentManager.SetThemeIcon(theme.IconPath())
+		}
+	}
+	streaming := h.streamingCardEnabled(card)
+	weekly := h.birdOfWeekEnabled(card) && job.Mode != services.ContentModeNight && !streaming
+	if weekly {
+		contentManager.SetWeeklyFact(services.WeeklyFactThemeOn(h.jobDay(job)).Title)
+	} else if streaming && h.birdOfWeekEnabled(card) {
+		// The chapter keeps one title; its stream plays each day's theme
+		contentManager.SetWeeklyFact(streamingWeeklyFactTitle)
+	}
+
+	lookupCtx, cancelLookup := context.WithTimeout(ctx, time.Duration(h.config.CardLookupTimeoutSeconds)*time.Second)
+	defer cancelLookup()
+	if cover := h.cardCover(lookupCtx, card, job); cover != "" {
+		contentManager.SetCoverImage(cover)
+	}
+	if h.birdHeroEnabled(card) && !policy.SkipsChapter(services.ChapterBirdHero) {
+		if status, threatened := h.birdHero.ThreatenedStatus(lookupCtx, job.BirdName); threatened {
+			slog.InfoContext(ctx, "[CARD_JOBS] Threatened bird, adding the bird hero chapter", "bird", job.BirdName, "status", status)
+			contentManager.SetConservationStatus(status)
+		}
+	}
+	if job.DeviceID != "" {
+		options := yoto.ListenerOptions{}
+		if profile, exists := h.deviceProfiles.Get(job.DeviceID); exists {
+			options = listenerOptions(profile)
+		}
+		options.DeviceID = job.DeviceID
+		contentManager.SetListenerOptions(options)
+	}
+	// The song and narration are fetched and rendered alongside the update. When it fails they're
+	// waited for and checkpointed, so the retry doesn't pay for them again.
+	song := checkpoints.Step(yoto.StepSongFetched)
+	tts := checkpoints.Step(yoto.StepTTSRendered)
+	published := false
+	defer func() {
+		if published {
+			return
+		}
+		for _, step := range []*services.CardJobStep{song, tts} {
+			if err := step.Finish(); err != nil {
+				slog.WarnContext(ctx, "[CARD_JOBS] Failed to checkpoint step", "card_id", job.CardID, "error", err)
+			}
+		}
+	}()
+	h.fetchBirdSong(ctx, song, job)
+	// The card's fact generator arm gets its own rendered guide
+	h.renderGuideVariant(ctx, tts, card, job)
+	h.renderFamilyPrimer(ctx, tts, job)
+	h.renderThemeNarration(ctx, tts, job)
+	h.renderBilingualNarration(ctx, tts, job)
+	// Streaming cards switch to the night variant by the device's local time on every play
+	contentManager.SetNightMode(job.Mode == services.ContentModeNight && !streaming)
+	cancelLookup()
+
+	publishCtx, cancelPublish := context.WithTimeout(ctx, time.Duration(h.config.CardPublishTimeoutSeconds)*time.Second)
+	defer cancelPublish()
+	contentManager.SetContext(publishCtx)
+
+	// Create session BEFORE updating card to ensure icon and bird name match
+	sessionID := h.CreateSessionForBird(job.CardID, job.BirdName)
+	slog.InfoContext(ctx, "[CARD_JOBS] Created session", "card_id", job.CardID, "session", sessionID, "bird", job.BirdName)
+
+	updateStart := time.Now()
+	var err error
+	if weekly {
+		err = h.publishWeeklyCard(publishCtx, contentManager, job, sessionID)
+	} else {
+		err = contentManager.UpdateCardWithStreamingTracks(job.CardID, job.BirdName, job.BaseURL, sessionID)
+	}
+	if errors.Is(err, yoto.ErrTranscodePending) {
+		// Not a failure: the queue checks on the transcode again later and resumes from the checkpoints
+		slog.InfoContext(ctx, "[CARD_JOBS] Waiting for Yoto to finish transcoding", "card_id", job.CardID, "bird", job.BirdName, "error", err)
+		return &services.CardJobError{Stage: services.CardStageTranscode, Err: fmt.Errorf("%w: %v", services.ErrCardJobWaiting, err)}
+	}
+	observeCardUpdate(job.Trigger, updateStart, err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.WarnContext(ctx, "[CARD_JOBS] Card update ran past its deadline", "card_id", job.CardID, "bird", job.BirdName,
+			"timeout_seconds", h.config.CardPublishTimeoutSeconds)
+	}
+	if errors.Is(err, yoto.ErrRateLimited) {
+		// Not a failed attempt either: the queue waits as long as Yoto asked before trying again
+		wait := yoto.RetryAfter(err)
+		slog.WarnContext(ctx, "[CARD_JOBS] Rate limited by Yoto", "card_id", job.CardID, "bird", job.BirdName, "retry_after", wait)
+		return &services.CardJobError{Stage: cardUpdateStage(err), Err: fmt.Errorf("%w: %v", services.ErrCardJobWaiting, err), RetryAfter: wait}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "[CARD_JOBS] Failed to update card", "card_id", job.CardID, "bird", job.BirdName, "trigger", job.Trigger, "error", err)
+		h.publishUpdateFailure(job.CardID, job.BirdName, err)
+		switch {
+		case errors.Is(err, yoto.ErrUnauthorized):
+			// Yoto rejected the token before its recorded expiry, so the retry refreshes it first
+			h.yotoClient.ExpireAccessToken()
+		case errors.Is(err, yoto.ErrCardNotFound), errors.Is(err, yoto.ErrValidation):
+			return &services.CardJobError{Stage: cardUpdateStage(err), Err: fmt.Errorf("%w: %w", services.ErrCardJobAbort, err)}
+		}
+		return &services.CardJobError{Stage: cardUpdateStage(err), Err: err}
+	}
+	published = true
+	h.pipelineEvents.Publish(services.EventPublished, job.CardID, job.BirdName, "Card updated")
+
+	if job.Trigger == services.CardJobWebhook {
+		chapters, contentHash := contentManager.PublishedContent()
+		h.updateCache.MarkPublished(job.CardID, job.Day, updateCacheContext(job.Mode), job.BirdName, publishedTracks(chapters), contentHash)
+	}
+	slog.InfoContext(ctx, "[CARD_JOBS] Updated card", "card_id", job.CardID, "bird", job.BirdName,
+		"trigger", job.Trigger, "duration", time.Since(updateStart).Round(time.Millisecond))
+	return nil
+}
+
+// fetchBirdSong caches the job bird's recording in the background, so the quiz, guide call, and
+// listen-and-count streams read it from the asset store instead of waiting on xeno-canto
+func (h *Handler) fetchBirdSong(ctx context.Context, step *services.CardJobStep, job services.CardJob) {
+	bird := h.availableBirds.GetBirdByName(job.BirdName)
+	if h.recordingWarmer == nil || bird == nil || bird.ScientificName == "" {
+		return
+	}
+	step.Go(func() error {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), narrationRenderTimeout)
+		defer cancel()
+
+		if _, failed := h.recordingWarmer.Warm(ctx, []string{bird.ScientificName}); failed > 0 {
+			return fmt.Errorf("failed to cache the recording of %s", bird.ScientificName)
+		}
+		return nil
+	})
+}
+
+// publishedTracks lists the tracks of published chapters for the update cache
+func publishedTracks(chapters []yoto.StreamingChapter) []services.CachedTrack {
+	var tracks []services.CachedTrack
+	for _, chapter := range chapters {
+		for _, track := range chapter.Tracks {
+			title := track.Title
+			if title == "" {
+				title = chapter.Title
+			}
+			tracks = append(tracks, services.CachedTrack{Chapter: chapter.Key, Title: title, URL: track.TrackURL})
+		}
+	}
+	return tracks
+}
+
+// ListCardJobs returns the card updates waiting to be retried
+func (h *Handler) ListCardJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  h.cardJobs.Pending(),
+		"stats": h.cardJobs.Stats(),
+	})
+}
+
+// cardCover picks the cover image for an update: the card's pinned cover, then the bird's photo
+// (except on streaming cards, which don't show the bird), then the rotating artwork. It returns "" to keep the card's existing cover.
+func (h *Handler) cardCover(ctx context.Context, card config.CardProfile, job services.CardJob) string {
+	if card.CoverImage != "" {
+		return card.CoverImage
+	}
+	if h.birdCoverEnabled(card) && !h.streamingCardEnabled(card) {
+		photo, err := h.photoFetcher.PhotoForBirdInRegion(ctx, job.BirdName, card.Region)
+		if err == nil {
+			return photo.LargeURL
+		}
+		slog.WarnContext(ctx, "[CARD_JOBS] No photo for cover", "bird", job.BirdName, "error", err)
+	}
+	if h.coverRotationEnabled(card) {
+		if cover, ok := h.covers.CoverOn(h.jobDay(job), h.southernCard(card)); ok {
+			slog.InfoContext(ctx, "[CARD_JOBS] Using rotating cover", "card_id", card.CardID, "cover", cover.Key)
+			return cover.URL
+		}
+	}
+	return ""
+}
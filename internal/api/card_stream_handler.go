@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// cardStreamTracks are the tracks StreamCardTrack serves
+var cardStreamTracks = map[string]bool{
+	"intro":        true,
+	"announcement": true,
+	"description":  true,
+	"outro":        true,
+	"primer":       true,
+	"quiz":         true,
+	"hotspots":     true,
+	"bird_hero":    true,
+	"weekly_fact":  true,
+	"listen_count": true,
+}
+
+// StreamCardTrack serves one of a card's tracks (intro, announcement, description, outro, primer,
+// quiz, hotspots, bird_hero, weekly_fact, or listen_count) for the requesting device's current local day. The bird is resolved on every request,
+// so the card's track URLs never change and the audio is served directly with range support. After
+// bedtime, cards with night mode play the night bird with the night intro and outro.
+func (h *Handler) StreamCardTrack(c *gin.Context) {
+	card, exists := h.config.Cards.Get(c.Param("card"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown card"})
+		return
+	}
+
+	track := c.Param("track")
+	if !cardStreamTracks[track] {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown track"})
+		return
+	}
+	deviceID := deviceIDFromRequest(c)
+
+	// The intro starts every play, so it refreshes the device's location
+	location := h.deviceLocation(c, card, track == "intro")
+	localNow := cardLocalTime(card, location)
+	localDate := localNow.Format("2006-01-02")
+
+	mode := h.contentMode(card, localNow)
+	if c.Query("mode") == services.ContentModeNight {
+		mode = services.ContentModeNight
+	}
+	night := mode == services.ContentModeNight
+
+	bird, err := h.birdForMode(card, localNow, mode)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "[STREAMING] No bird for card track", "card_id", card.CardID, "track", track, "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Bird content not ready yet. Please try again in a few minutes."})
+		return
+	}
+
+	playKey := fmt.Sprintf("%s_%s_%s", card.CardID, localDate, deviceID)
+	switch track {
+	case "intro":
+		h.deviceRegistry.Touch(deviceID)
+		h.pipelineEvents.Publish(services.EventCardPlayed, card.CardID, bird.CommonName, "")
+	case "description":
+		generator := factsPreference(c)
+		if generator == "" {
+			generator, _ = h.experimentGuideGenerator(card, localDate)
+		}
+		h.factExperiment.RecordGuideStarted(playKey, generator)
+	case "outro":
+		h.factExperiment.RecordCompleted(playKey)
+	}
+
+	audio, err := h.cardTrackAudio(c, card, track, bird.CommonName, location, localNow, night)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "[STREAMING] Failed to load card track audio", "card_id", card.CardID, "track", track, "bird", bird.CommonName, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Audio unavailable"})
+		return
+	}
+
+	serveStreamAudio(c, track, audio, localNow)
+}
+
+// cardTrackAudio makes one of a card's tracks for the bird on localNow's day. The request's query
+// carries the listener's options (voice, facts, nature, greeting).
+func (h *Handler) cardTrackAudio(c *gin.Context, card config.CardProfile, track string, birdName string, location *models.Location, localNow time.Time, night bool) (*services.StreamAudio, error) {
+	ctx := c.Request.Context()
+	voiceID := c.Query("voice")
+
+	switch track {
+	case "intro":
+		if night {
+			return h.streamCache.Fetch(ctx, h.introURL(c, birdName, night, localNow))
+		}
+		return h.introAudio(c, h.introURL(c, birdName, night, localNow), location, localNow)
+	case "announcement":
+		return h.streamCache.Fetch(ctx, h.announcementURL(birdName))
+	case "description":
+		if h.guideCallsEnabled(card) {
+			return h.guideAudio(c, card, birdName, location, localNow)
+		}
+		return h.streamCache.Fetch(ctx, h.descriptionURL(c, birdName, h.guideVariant(c, card, localNow.Format("2006-01-02"))))
+	case "outro":
+		var audio *services.StreamAudio
+		var err error
+		if !night && h.outroRotationEnabled(card) {
+			audio, err = h.rotatingOutroAudio(ctx, card.CardID, birdName, voiceID, localNow)
+		} else {
+			audio, err = h.streamCache.Fetch(ctx, outroURL(birdName, night))
+		}
+		if err == nil && !night && h.listenCountEnabled(card) {
+			audio = h.withCountingAnswer(ctx, birdName, voiceID, localNow, audio)
+		}
+		return audio, err
+	case "primer":
+		if primerURL, ok := h.primerURL(deviceIDFromRequest(c), birdName); ok {
+			return h.streamCache.Fetch(ctx, primerURL)
+		}
+		return skipClip, nil
+	case "quiz":
+		return h.quizAudio(ctx, birdName, location, voiceID, localNow)
+	case "hotspots":
+		return h.hotspotAudio(ctx, birdName, location, voiceID, localNow)
+	case "bird_hero":
+		return h.birdHeroAudio(ctx, birdName, voiceID, localNow)
+	case "weekly_fact":
+		return h.weeklyFactAudio(ctx, birdName, voiceID, localNow)
+	case "listen_count":
+		return h.countingAudio(ctx, birdName, voiceID, localNow)
+	}
+	return nil, fmt.Errorf("unknown track %q", track)
+}
+
+// factsPreference is the guide a device profile asked for (?facts=), or "" for the card's own
+func factsPreference(c *gin.Context) string {
+	if preferred := c.Query("facts"); services.IsFactGenerator(preferred) {
+		return preferred
+	}
+	return ""
+}
+
+// quizAudio renders the quiz round, falling back to the silent skip clip when it can't be made
+func (h *Handler) quizAudio(ctx context.Context, birdName string, location *models.Location, voiceID string, localNow time.Time) (*services.StreamAudio, error) {
+	if location != nil {
+		voiceID = h.narratorVoice(voiceID, services.VoiceRoleQuiz, localNow)
+		quiz, err := h.quizGenerator.GenerateQuiz(ctx, birdName, location.Latitude, location.Longitude, voiceID)
+		if err == nil {
+			return services.NewStreamAudio(quiz.Audio), nil
+		}
+		slog.WarnContext(ctx, "[STREAMING] quiz: Failed to generate quiz, skipping", "bird", birdName, "error", err)
+	}
+	return skipClip, nil
+}
+
+// deviceLocation returns the requesting device's location. With refresh set the IP is looked up
+// and folded into the device's smoothed location; otherwise the stored location is used. Devices
+// without one fall back to the card's default location, or nil in "no location" mode.
+func (h *Handler) deviceLocation(c *gin.Context, card config.CardProfile, refresh bool) *models.Location {
+	deviceID := deviceIDFromRequest(c)
+
+	if refresh {
+		if location, err := h.locationService.GetLocationFromIP(c.ClientIP()); err == nil && location != nil {
+			return h.deviceRegistry.SmoothLocation(deviceID, location)
+		}
+	}
+	if record, ok := h.deviceRegistry.Get(deviceID); ok && record.Location != nil {
+		return record.Location
+	}
+	if fallback, ok := h.defaultLocations.Resolve(card.CardID); ok {
+		return fallback
+	}
+	return nil
+}
+
+// cardLocalTime is the current time where the device is, falling back to the card's configured
+// timezone and then UTC
+func cardLocalTime(card config.CardProfile, location *models.Location) time.Time {
+	if location == nil && card.Timezone != "" {
+		if tz, err := time.LoadLocation(card.Timezone); err == nil {
+			return clock().In(tz)
+		}
+	}
+	return locationLocalTime(location)
+}
+
+// cardDefaultTime is the current time at the card's default location, falling back to its
+// configured timezone and then UTC, for work not tied to one device's play
+func (h *Handler) cardDefaultTime(card config.CardProfile) time.Time {
+	if location, ok := h.defaultLocations.Resolve(card.CardID); ok {
+		return cardLocalTime(card, location)
+	}
+	return cardLocalTime(card, nil)
+}
+
+// locationLocalTime is the current time at the location, or UTC without one
+func locationLocalTime(location *models.Location) time.Time {
+	if location != nil {
+		return clock().In(GetTimezoneFromLocation(location.Latitude, location.Longitude))
+	}
+	return clock().UTC()
+}
+
+// outroURL is the bird's outro, or its goodnight outro in night mode once that has been rendered
+func outroURL(birdName string, night bool) string {
+	if night {
+		if nightURL := narrationURL(birdName, "outro_night"); narrationVariantExists(nightURL) {
+			return nightURL
+		}
+	}
+	return narrationURL(birdName, "outro")
+}
+
+// narrationURL is the stored narration clip for a bird
+func narrationURL(birdName string, clip string) string {
+	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	return fmt.Sprintf("%s/%s/narration/%s.mp3", narrationBaseURL, birdDir, clip)
+}
+
+// serveStreamAudio writes the audio with range and conditional request support. Players may
+// cache it until the device's local midnight, when the bird changes.
+func serveStreamAudio(c *gin.Context, track string, audio *services.StreamAudio, localNow time.Time) {
+	year, month, day := localNow.Date()
+	midnight := time.Date(year, month, day+1, 0, 0, 0, 0, localNow.Location())
+	maxAge := int(midnight.Sub(localNow).Seconds())
+
+	c.Header("Content-Type", "audio/mpeg")
+	c.Header("ETag", audio.ETag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+	http.ServeContent(c.Writer, c.Request, track+".mp3", audio.FetchedAt, bytes.NewReader(audio.Data))
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+)
+
+// ConfigureServices applies the settings the services share process-wide: moderation rules,
+// pronunciations, guide length and reading grade, assets, geocoding, and where icon mappings and
+// the eBird taxonomy are cached. Call it once at startup, before building a handler.
+func ConfigureServices(cfg *config.Config) {
+	services.ConfigureModeration(cfg.ModerationRulesPath, cfg.PerspectiveAPIKey, cfg.PerspectiveThreshold)
+	services.ConfigurePronunciations(cfg.PronunciationsPath)
+	services.ConfigureGuideLength(cfg.GuideTargetSeconds)
+	services.ConfigureReadingGrade(cfg.GuideReadingGrade)
+	services.ConfigureDawnChorus(cfg.EnableDawnChorus)
+	services.ConfigureAssetStore(cfg.AssetStoreBackend, cfg.AssetStoreBucket, cfg.AssetStorePrefix, cfg.AssetDir)
+	services.ConfigureGeocoder(cfg.Geocoder, cfg.OpenCageAPIKey, cfg.NominatimURL)
+	yoto.ConfigureIconMappings(cfg.IconMappingsPath)
+	ebird.ConfigureTaxonomy(cfg.EBirdTaxonomyPath)
+}
+
+// FFmpegOptions returns where services.BootstrapFFmpeg gets the pinned static ffmpeg build
+func FFmpegOptions(cfg *config.Config) services.FFmpegOptions {
+	return services.FFmpegOptions{
+		InstallDir:    cfg.FFmpegInstallDir,
+		StaticURL:     cfg.FFmpegStaticURL,
+		FFmpegSHA256:  cfg.FFmpegStaticSHA256,
+		FFprobeSHA256: cfg.FFprobeStaticSHA256,
+		AutoDownload:  cfg.FFmpegAutoDownload,
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CronDailyUpdate is called frequently (e.g. every 15 minutes) by an external cron. Each card is
+// updated once for every timezone its listeners are in, as that timezone's local midnight passes,
+// rather than once a day on the server's clock.
+func (h *Handler) CronDailyUpdate(c *gin.Context) {
+	if h.config.CronSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "CRON_SECRET not configured"})
+		return
+	}
+	if !validCronSecret(c, h.config.CronSecret) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid cron secret"})
+		return
+	}
+
+	baseURL := h.config.ServiceURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s", c.Request.Host)
+		if h.config.Environment == "development" {
+			baseURL = fmt.Sprintf("http://%s", c.Request.Host)
+		}
+	}
+
+	now := clock().UTC()
+	due := h.rollout.Due(h.rolloutTargets(), now)
+
+	status := http.StatusOK
+	results := make([]gin.H, 0, len(due))
+	for _, target := range due {
+		result := gin.H{
+			"card":       target.CardID,
+			"timezone":   target.Timezone,
+			"local_date": target.LocalDate,
+		}
+
+		card, exists := h.config.Cards.Get(target.CardID)
+		if !exists {
+			continue
+		}
+
+		// Another of the card's timezones already moved it to this date's bird
+		if h.rollout.CardUpdatedOn(target.CardID, target.LocalDate) {
+			h.rollout.MarkUpdated(target.CardID, target.Timezone, target.LocalDate)
+			result["status"] = "already_current"
+			results = append(results, result)
+			continue
+		}
+
+		log.Printf("[CRON] Midnight passed in %s (%s), updating card %s", target.Timezone, target.LocalDate, target.CardID)
+		response, err := h.updateCardForDay(c.Request.Context(), card, target.LocalTime, baseURL)
+		if err != nil {
+			// Left unmarked so the next cron run retries it
+			status = http.StatusInternalServerError
+			result["status"] = "failed"
+			result["error"] = response["error"]
+			results = append(results, result)
+			continue
+		}
+
+		h.rollout.MarkUpdated(target.CardID, target.Timezone, target.LocalDate)
+		result["status"] = "updated"
+		result["bird"] = response["bird"]
+		results = append(results, result)
+	}
+
+	c.JSON(status, gin.H{
+		"success":   status == http.StatusOK,
+		"updates":   results,
+		"timestamp": now.Format(time.RFC3339),
+	})
+}
+
+// rolloutTargets lists every card with the timezones it's played in: the card's configured
+// timezone, else the one for its default location, plus the timezones of registered devices for
+// the default card (devices aren't tied to a card, and most deployments have just one). Device
+// timezones come from their locations and from the players' own settings synced from Yoto.
+func (h *Handler) rolloutTargets() []services.RolloutTarget {
+	var targets []services.RolloutTarget
+	defaultCardID := h.config.Cards.Default().CardID
+
+	for _, card := range h.config.Cards.Cards() {
+		zones := make(map[string]bool)
+		if card.Timezone != "" {
+			zones[card.Timezone] = true
+		} else if location, ok := h.defaultLocations.Resolve(card.CardID); ok {
+			zones[GetTimezoneFromLocation(location.Latitude, location.Longitude).String()] = true
+		}
+
+		if card.CardID == defaultCardID {
+			for _, location := range h.deviceRegistry.Locations() {
+				zones[GetTimezoneFromLocation(location.Latitude, location.Longitude).String()] = true
+			}
+			for _, zone := range h.deviceRegistry.Timezones() {
+				zones[zone] = true
+			}
+		}
+
+		if len(zones) == 0 {
+			zones["UTC"] = true
+		}
+		for zone := range zones {
+			targets = append(targets, services.RolloutTarget{CardID: card.CardID, Timezone: zone})
+		}
+	}
+
+	return targets
+}
+
+// validCronSecret accepts the secret as an X-Cron-Secret header or a bearer token
+func validCronSecret(c *gin.Context, secret string) bool {
+	provided := c.GetHeader("X-Cron-Secret")
+	if provided == "" {
+		provided = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) == 1
+}
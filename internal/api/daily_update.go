@@ -1,15 +1,33 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
 	"github.com/gin-gonic/gin"
 )
 
+var cardUpdateDuration = metrics.NewHistogramVec("bird_explorer_card_update_duration_seconds",
+	"Time to generate, upload, and publish a card's tracks, by trigger and result", nil, "trigger", "result")
+
+// observeCardUpdate records how long a card update took and whether it succeeded
+func observeCardUpdate(trigger string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	cardUpdateDuration.ObserveSince(start, trigger, result)
+}
+
 // DailyUpdateHandler handles the scheduled daily update of the Yoto card
 func (h *Handler) DailyUpdateHandler(c *gin.Context) {
 	// Prevent recursive calls
@@ -37,21 +55,11 @@ func (h *Handler) DailyUpdateHandler(c *gin.Context) {
 		log.Printf("DailyUpdateHandler: Successfully reached httpbin.org")
 	}
 
-	// Always select bird from available prerecorded birds (streaming mode only)
-	bird := h.availableBirds.GetCyclingBird()
-	now := time.Now().UTC()
-	daysSinceEpoch := now.Unix() / (24 * 60 * 60)
-	log.Printf("DailyUpdateHandler: Selected bird: %s (UTC: %s, days since epoch: %d, index: %d)",
-		bird.CommonName, now.Format("2006-01-02 15:04:05"), daysSinceEpoch, daysSinceEpoch%4)
-
-	// Store this as the daily global bird for fallback use
-	localDate := time.Now().UTC().Format("2006-01-02")
-	h.updateCache.SetDailyGlobalBird(localDate, bird.CommonName)
-	log.Printf("DailyUpdateHandler: Stored %s as global bird for %s", bird.CommonName, localDate)
+	now := clock().UTC()
 
 	// Get a generic intro (no bird name mentioned)
 	// Use the configured service URL or fall back to host
-	baseURL := os.Getenv("SERVICE_URL")
+	baseURL := h.config.ServiceURL
 	if baseURL == "" {
 		baseURL = fmt.Sprintf("https://%s", c.Request.Host)
 		if h.config.Environment == "development" {
@@ -59,32 +67,203 @@ func (h *Handler) DailyUpdateHandler(c *gin.Context) {
 		}
 	}
 
-	contentManager := h.yotoClient.NewContentManager()
-
-	cardID := h.config.YotoCardID
-	if cardID == "" {
+	if len(h.config.Cards.Cards()) == 0 {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "YOTO_CARD_ID not configured"})
 		return
 	}
 
-	// Create session BEFORE updating card to ensure icon and bird name match
-	sessionID := h.CreateSessionForBird(cardID, bird.CommonName)
-	log.Printf("[DAILY_UPDATE] Created session %s for bird: %s", sessionID, bird.CommonName)
+	// ?card= updates one card immediately; otherwise every card scheduled for this hour is updated
+	var cards []config.CardProfile
+	if cardID := c.Query("card"); cardID != "" {
+		card, exists := h.config.Cards.Get(cardID)
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Unknown card %s", cardID)})
+			return
+		}
+		cards = []config.CardProfile{card}
+	} else {
+		cards = h.config.Cards.DueAt(now.Hour())
+	}
+
+	if len(cards) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"success":   true,
+			"message":   fmt.Sprintf("No cards scheduled for %02d:00 UTC", now.Hour()),
+			"cards":     []gin.H{},
+			"timestamp": clock().Format(time.RFC3339),
+		})
+		return
+	}
+
+	// A single card keeps the original response shape
+	if len(cards) == 1 {
+		response, err := h.updateCardForDay(c.Request.Context(), cards[0], h.cardDefaultTime(cards[0]), baseURL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	status := http.StatusOK
+	results := make([]gin.H, 0, len(cards))
+	for _, card := range cards {
+		response, err := h.updateCardForDay(c.Request.Context(), card, h.cardDefaultTime(card), baseURL)
+		if err != nil {
+			status = http.StatusInternalServerError
+		}
+		results = append(results, response)
+	}
+
+	c.JSON(status, gin.H{
+		"success":   status == http.StatusOK,
+		"cards":     results,
+		"timestamp": clock().Format(time.RFC3339),
+	})
+}
+
+// updateCardForDay selects the day's bird for a card's region and publishes it to the card.
+// The returned response describes the update, or the failure when err is set.
+func (h *Handler) updateCardForDay(ctx context.Context, card config.CardProfile, now time.Time, baseURL string) (gin.H, error) {
+	cardID := card.CardID
+	localDate := now.Format("2006-01-02")
+
+	bird, err := h.selectDailyBird(card, now)
+	if err != nil {
+		return gin.H{"error": err.Error(), "card": cardID}, err
+	}
+
+	// Split households get their own location sections; species, song, and core facts stay shared
+	if h.householdEnricher.Enabled() {
+		go h.renderHouseholdVariants(context.WithoutCancel(ctx), bird, now)
+	}
 
-	err = contentManager.UpdateCardWithStreamingTracks(cardID, bird.CommonName, baseURL, sessionID)
+	h.pipelineEvents.Publish(services.EventJobStarted, cardID, bird.CommonName, "Daily update started")
 
+	factGenerator := card.FactGenerator
+	if factGenerator == "" {
+		factGenerator = h.factExperiment.GeneratorForCard(cardID)
+	}
+	h.factExperiment.RecordAssignment(cardID, localDate, factGenerator)
+
+	err = h.runCardJob(ctx, services.CardJob{
+		CardID:   cardID,
+		Day:      localDate,
+		Trigger:  services.CardJobScheduled,
+		BirdName: bird.CommonName,
+		BaseURL:  baseURL,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		return gin.H{
 			"error": fmt.Sprintf("Failed to update Yoto card: %v", err),
+			"card":  cardID,
 			"bird":  bird.CommonName,
-		})
-		return
+		}, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success":   true,
-		"message":   fmt.Sprintf("Successfully set daily bird as %s (generic facts)", bird.CommonName),
-		"bird":      bird.CommonName,
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	response := gin.H{
+		"success":        true,
+		"message":        fmt.Sprintf("Successfully set daily bird as %s (generic facts)", bird.CommonName),
+		"card":           cardID,
+		"bird":           bird.CommonName,
+		"fact_generator": factGenerator,
+		"timestamp":      clock().Format(time.RFC3339),
+	}
+	if theme, ok := h.themes.ThemeOn(now); ok {
+		response["theme"] = theme.Name
+		if theme.IsHoliday() {
+			response["holiday"] = theme.Name
+			response["greeting"] = theme.IntroText(now, bird.CommonName)
+		}
+	}
+	return response, nil
+}
+
+// selectDailyBird returns the bird a card's region plays on now's calendar date, selecting and
+// recording it on the first request of the day
+func (h *Handler) selectDailyBird(card config.CardProfile, now time.Time) (*models.Bird, error) {
+	region := cardRegion(card)
+	localDate := now.Format("2006-01-02")
+
+	// Bird-of-the-week cards keep their own bird all week
+	if h.birdOfWeekEnabled(card) {
+		return h.weeklyBirdForCard(card, now)
+	}
+
+	// A bird already recorded for today (before a restart or by another instance) is kept
+	var bird *models.Bird
+	if storedName, exists := h.dailyBird(region, localDate); exists {
+		if bird = h.availableBirds.GetBirdByName(storedName); bird != nil {
+			log.Printf("DailyUpdateHandler: Using %s already recorded for %s (%s)", bird.CommonName, localDate, region)
+		}
+	}
+
+	if bird == nil {
+		var err error
+		if bird, err = h.chooseDailyBird(card, now); err != nil {
+			return nil, err
+		}
+
+		// Store this as the region's daily bird; if another instance recorded one first, use theirs
+		if recorded := h.recordDailyBird(region, localDate, bird.CommonName); recorded != bird.CommonName {
+			if existing := h.availableBirds.GetBirdByName(recorded); existing != nil {
+				bird = existing
+			}
+		}
+		log.Printf("DailyUpdateHandler: Stored %s as %s bird for %s", bird.CommonName, region, localDate)
+	}
+
+	return bird, nil
+}
+
+// chooseDailyBird selects the bird for a card's region on now's calendar date without recording
+// it: the rotation bird, or a holiday's, special guest, or seasonal theme's bird in its place
+func (h *Handler) chooseDailyBird(card config.CardProfile, now time.Time) (*models.Bird, error) {
+	region := cardRegion(card)
+	localDate := now.Format("2006-01-02")
+	theme, themed := h.themes.ThemeOn(now)
+
+	// Always select bird from available prerecorded birds (streaming mode only)
+	bird := h.rotationBirdForCard(card, now)
+	if bird == nil {
+		return nil, fmt.Errorf("no bird available for region %s", region)
+	}
+	daysSinceEpoch := now.Unix() / (24 * 60 * 60)
+	log.Printf("DailyUpdateHandler: Selected bird: %s for %s (local: %s, days since epoch: %d)",
+		bird.CommonName, region, now.Format("2006-01-02 15:04:05"), daysSinceEpoch)
+
+	// Holidays, then special guests, then seasonal themes, bias selection toward themed species
+	// when one is available, unless an admin pinned the day's bird
+	if _, pinned := h.overrides.PinnedBird(region, localDate); pinned {
+		return bird, nil
+	}
+	if themed && theme.IsHoliday() {
+		if themedBird := h.availableBirds.GetBirdForTheme(theme); themedBird != nil && !h.overrides.IsBlocked(themedBird.CommonName) {
+			log.Printf("DailyUpdateHandler: %s - using themed bird %s instead of %s", theme.Name, themedBird.CommonName, bird.CommonName)
+			return themedBird, nil
+		}
+	}
+	if guest := h.specialGuestForCard(card, now); guest != nil {
+		log.Printf("DailyUpdateHandler: Featuring special guest %s, rare in %s lately, instead of %s", guest.CommonName, region, bird.CommonName)
+		return guest, nil
+	}
+	if themed && !theme.IsHoliday() && !theme.MatchesSpecies(bird.CommonName) {
+		if themedBird := h.availableBirds.GetBirdForTheme(theme); themedBird != nil && !h.overrides.IsBlocked(themedBird.CommonName) {
+			log.Printf("DailyUpdateHandler: %s - using themed bird %s instead of %s", theme.Name, themedBird.CommonName, bird.CommonName)
+			return themedBird, nil
+		}
+	}
+	return bird, nil
+}
+
+// publishUpdateFailure reports a failed card update, alerting separately when the card was reverted
+func (h *Handler) publishUpdateFailure(cardID string, birdName string, err error) {
+	var verifyErr *yoto.CardVerificationError
+	if errors.As(err, &verifyErr) && verifyErr.Reverted {
+		log.Printf("[ALERT] Card %s was reverted after a failed update: %s", cardID, verifyErr.Mismatch)
+		h.pipelineEvents.Publish(services.EventCardReverted, cardID, birdName, err.Error())
+		return
+	}
+	h.pipelineEvents.Publish(services.EventPipelineFail, cardID, birdName, err.Error())
 }
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentDailyBird returns today's global bird, falling back to the cycling bird
+func (h *Handler) currentDailyBird() string {
+	today := clock().UTC().Format("2006-01-02")
+	if birdName, exists := h.dailyGlobalBird(today); exists && birdName != "" {
+		return birdName
+	}
+
+	if bird := h.availableBirds.GetCyclingBird(); bird != nil {
+		return bird.CommonName
+	}
+	return ""
+}
+
+// GetTrivia returns multiple-choice questions about a bird for parents to quiz kids after listening
+func (h *Handler) GetTrivia(c *gin.Context) {
+	birdName := c.Query("bird")
+	if birdName == "" {
+		birdName = h.currentDailyBird()
+	}
+	if birdName == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No bird selected yet"})
+		return
+	}
+
+	questions, err := h.triviaGenerator.GenerateForBird(birdName, clock().UTC())
+	if err != nil {
+		log.Printf("[DASHBOARD] Failed to generate trivia for %s: %v", birdName, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No fact sheet available for this bird",
+			"bird":  birdName,
+		})
+		return
+	}
+
+	response := gin.H{
+		"bird":      birdName,
+		"questions": questions,
+	}
+	if h.localizedNames.Enabled() {
+		response["localized_name"] = h.localizedBirdName(c.Request.Context(), birdName)
+		response["locale"] = h.localizedNames.Locale()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
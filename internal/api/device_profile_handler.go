@@ -0,0 +1,73 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/gin-gonic/gin"
+)
+
+// listenerOptions converts a device profile into the preferences carried by the card's track URLs
+func listenerOptions(profile services.DeviceProfile) yoto.ListenerOptions {
+	return yoto.ListenerOptions{
+		VoiceID:       profile.VoiceID,
+		FactGenerator: profile.FactGenerator(),
+		NatureIntros:  profile.NatureIntros,
+		DynamicIntro:  profile.DynamicIntro,
+	}
+}
+
+// ListDeviceProfiles returns every stored device profile
+func (h *Handler) ListDeviceProfiles(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"profiles": h.deviceProfiles.All()})
+}
+
+// GetDeviceProfile returns the profile for one device
+func (h *Handler) GetDeviceProfile(c *gin.Context) {
+	profile, exists := h.deviceProfiles.Get(c.Param("device"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No profile for device"})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// PutDeviceProfile creates or replaces a device's profile
+func (h *Handler) PutDeviceProfile(c *gin.Context) {
+	var profile services.DeviceProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile"})
+		return
+	}
+	profile.DeviceID = c.Param("device")
+
+	if profile.Region != "" && !h.availableBirds.IsKnownRegion(profile.Region) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown region"})
+		return
+	}
+
+	saved, err := h.deviceProfiles.Put(profile)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to save profile for %s: %v", profile.DeviceID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteDeviceProfile removes a device's profile so it falls back to the card defaults
+func (h *Handler) DeleteDeviceProfile(c *gin.Context) {
+	deleted, err := h.deviceProfiles.Delete(c.Param("device"))
+	if err != nil {
+		log.Printf("[ADMIN] Failed to delete profile for %s: %v", c.Param("device"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No profile for device"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
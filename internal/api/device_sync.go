@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// startDeviceSync syncs the account's players into the device registry now and then on every
+// interval, so the scheduler knows every player's timezone before it has played the card
+func (h *Handler) startDeviceSync(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := h.syncDevices(context.Background()); err != nil {
+				log.Printf("[DEVICE_SYNC] %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// syncDevices lists the account's players and their timezones from the Yoto API and records
+// them in the device registry, returning how many players the registry hadn't seen before
+func (h *Handler) syncDevices(ctx context.Context) (int, error) {
+	devices, err := h.yotoClient.ListDevices(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list Yoto devices: %w", err)
+	}
+
+	synced := make([]services.SyncedDevice, 0, len(devices))
+	for _, device := range devices {
+		entry := services.SyncedDevice{
+			DeviceID: device.DeviceID,
+			Name:     device.Name,
+			Family:   device.DeviceFamily,
+			Online:   device.Online,
+		}
+		// A player's timezone lives in its config, which offline players still return
+		if deviceConfig, err := h.yotoClient.GetDeviceConfig(ctx, device.DeviceID); err != nil {
+			log.Printf("[DEVICE_SYNC] No config for device %s: %v", device.DeviceID, err)
+		} else if zone := deviceConfig.Device.Config.GeoTimezone; zone != "" {
+			if _, err := time.LoadLocation(zone); err == nil {
+				entry.Timezone = zone
+			} else {
+				log.Printf("[DEVICE_SYNC] Ignoring unknown timezone %q for device %s", zone, device.DeviceID)
+			}
+		}
+		synced = append(synced, entry)
+	}
+
+	added := h.deviceRegistry.Sync(synced)
+	log.Printf("[DEVICE_SYNC] Synced %d devices from Yoto (%d new)", len(synced), added)
+	return added, nil
+}
+
+// ListRegisteredDevices returns every device in the registry with its synced details and
+// listening history
+func (h *Handler) ListRegisteredDevices(c *gin.Context) {
+	devices := h.deviceRegistry.All()
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(devices),
+		"devices":   devices,
+		"timezones": h.deviceRegistry.Timezones(),
+	})
+}
+
+// SyncDevices syncs the registry from the Yoto API now instead of waiting for the next interval
+func (h *Handler) SyncDevices(c *gin.Context) {
+	added, err := h.syncDevices(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"added":   added,
+		"devices": h.deviceRegistry.Count(),
+	})
+}
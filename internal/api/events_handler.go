@@ -0,0 +1,44 @@
+package api
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAlive keeps idle connections open through Cloud Run and proxies
+const sseKeepAlive = 15 * time.Second
+
+// StreamDashboardEvents streams pipeline events to the dashboard as server-sent events.
+// Recent events are replayed on connect so a freshly opened dashboard shows the current run.
+func (h *Handler) StreamDashboardEvents(c *gin.Context) {
+	events, backlog := h.pipelineEvents.Subscribe()
+	defer h.pipelineEvents.Unsubscribe(events)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, event := range backlog {
+		c.SSEvent(event.Type, event)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event := <-events:
+			c.SSEvent(event.Type, event)
+			return true
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().UTC().Format(time.RFC3339))
+			return true
+		}
+	})
+}
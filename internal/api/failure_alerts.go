@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+)
+
+// newFailureAlerts sets up alerting for abandoned card updates with the configured notifiers
+func newFailureAlerts(cfg *config.Config) *services.FailureAlerts {
+	var notifiers []services.FailureNotifier
+	if cfg.AlertSlackWebhookURL != "" {
+		notifiers = append(notifiers, services.NewSlackNotifier(cfg.AlertSlackWebhookURL))
+	}
+	if cfg.AlertEmailTo != "" && cfg.SMTPHost != "" {
+		var to []string
+		for _, address := range strings.Split(cfg.AlertEmailTo, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				to = append(to, address)
+			}
+		}
+		notifiers = append(notifiers, services.NewEmailNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.AlertEmailFrom, to))
+	}
+	return services.NewFailureAlerts(time.Duration(cfg.AlertWindowSeconds)*time.Second,
+		time.Duration(cfg.AlertCooldownMinutes)*time.Minute, notifiers...)
+}
+
+// reportAbandonedCardJob alerts on a card update the job queue gave up on. While a dependency is
+// down, failures are put down to it, so an outage that fails every card is a single alert.
+func (h *Handler) reportAbandonedCardJob(job services.CardJob) {
+	var down []string
+	for _, status := range h.dependencies.Statuses() {
+		if status.Status == services.HealthDown {
+			down = append(down, status.Name)
+		}
+	}
+
+	stage := job.LastStage
+	if stage == "" {
+		stage = services.CardStageUpdate
+	}
+	h.failureAlerts.Report(services.CardFailure{
+		CardID:   job.CardID,
+		BirdName: job.BirdName,
+		Day:      job.Day,
+		Trigger:  job.Trigger,
+		Stage:    stage,
+		Error:    job.LastError,
+		Attempts: job.Attempts,
+		Cause:    services.FailureCause(stage, job.LastError, job.CardID, down),
+	})
+}
+
+// cardUpdateStage is the stage a failed card publish stopped at
+func cardUpdateStage(err error) string {
+	var verifyErr *yoto.CardVerificationError
+	if errors.As(err, &verifyErr) {
+		return services.CardStageVerify
+	}
+	if errors.Is(err, yoto.ErrTranscodeTimeout) {
+		return services.CardStageTranscode
+	}
+	return services.CardStagePublish
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/gin-gonic/gin"
+)
+
+// guideCallsEnabled reports whether the card's Explorer's Guide is narrated live with the bird's
+// call spliced in
+func (h *Handler) guideCallsEnabled(card config.CardProfile) bool {
+	if card.GuideCalls != nil {
+		return *card.GuideCalls
+	}
+	return h.config.EnableGuideCalls
+}
+
+// weatherEnabled reports whether the card's guide opens with the weather at the listener's location
+func (h *Handler) weatherEnabled(card config.CardProfile) bool {
+	if card.Weather != nil {
+		return *card.Weather
+	}
+	return h.config.EnableWeather
+}
+
+// guideFactGenerator builds the card's guide generator for date, seeded with the day so every
+// play that day reads the same facts. Cards with weather enabled open with the current weather;
+// its lines are English, so other content languages go without.
+func (h *Handler) guideFactGenerator(c *gin.Context, card config.CardProfile, date string) services.FactGenerator {
+	generator := services.NewFactGeneratorForLocale(h.guideGenerator(c, card, date), h.config.EBirdAPIKey,
+		h.config.ContentLocale, randx.Daily(date, card.CardID))
+	if h.weatherEnabled(card) && services.NormalizeLocale(h.config.ContentLocale) == services.DefaultLocale {
+		return services.WithWeather(generator, h.weather)
+	}
+	return generator
+}
+
+// guideGenerator is the fact generator the card's guide uses on date: the device profile's
+// preference, the card's own, or the card's experiment bucket
+func (h *Handler) guideGenerator(c *gin.Context, card config.CardProfile, date string) string {
+	if generator := factsPreference(c); generator != "" {
+		return generator
+	}
+	if card.FactGenerator != "" {
+		return card.FactGenerator
+	}
+	return h.factExperiment.AssignmentFor(card.CardID, date)
+}
+
+// guideAudio narrates the Explorer's Guide for the listener's location with a snippet of the
+// bird's call where the script cues it. The script uses the day's seed, so every play that day
+// hears the same facts; the pre-rendered description plays when it can't be narrated.
+func (h *Handler) guideAudio(c *gin.Context, card config.CardProfile, birdName string, location *models.Location, localNow time.Time) (*services.StreamAudio, error) {
+	ctx := c.Request.Context()
+	date := localNow.Format("2006-01-02")
+
+	if bird := h.availableBirds.GetBirdByName(birdName); bird != nil {
+		var latitude, longitude float64
+		if location != nil {
+			latitude, longitude = location.Latitude, location.Longitude
+		}
+		script := h.guideFactGenerator(c, card, date).GenerateFactScript(ctx, bird, latitude, longitude)
+
+		voiceID := h.narratorVoice(c.Query("voice"), services.VoiceRoleGuide, localNow)
+		audio, err := h.guideCalls.Render(ctx, birdName, script, voiceID)
+		if err == nil {
+			return services.NewStreamAudio(audio), nil
+		}
+		slog.WarnContext(ctx, "[STREAMING] description: Failed to narrate guide, using pre-rendered narration", "bird", birdName, "error", err)
+	}
+	return h.streamCache.Fetch(ctx, h.descriptionURL(c, birdName, h.guideVariant(c, card, date)))
+}
@@ -1,13 +1,29 @@
 package api
 
 import (
+	"context"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/callen/bird-song-explorer/internal/config"
 	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/internal/store"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+	"github.com/callen/bird-song-explorer/pkg/randx"
 	"github.com/callen/bird-song-explorer/pkg/yoto"
 )
 
+// clock is where the handlers read the current time from
+var clock = time.Now
+
+// SetClock replaces the handlers' clock, so simulated runs can step through days. Call it before
+// serving requests.
+func SetClock(now func() time.Time) {
+	clock = now
+}
+
 type Handler struct {
 	config                  *config.Config
 	locationService         *services.LocationService
@@ -16,20 +32,76 @@ type Handler struct {
 	yotoClient              *yoto.Client
 	updateCache             *services.UpdateCache
 	availableBirds          *services.AvailableBirdsService
+	triviaGenerator         *services.TriviaGenerator
+	defaultLocations        *services.DefaultLocationResolver
+	deviceRegistry          *services.DeviceRegistry
+	primerService           *services.PrimerService
+	householdEnricher       *services.HouseholdEnricher
+	updateQueue             *services.UpdateQueue
+	birdStorage             *services.BirdStorage
+	localizedNames          *services.LocalizedNameService
+	pipelineEvents          *services.PipelineEvents
+	factExperiment          *services.FactExperiment
+	themes                  *services.ThemeManager
+	covers                  *services.CoverManager
+	songVisualizer          *services.SongVisualizer
+	photoFetcher            *services.PhotoFetcher
+	birdIconGenerator       *services.BirdIconGenerator
+	ttsQuota                *services.QuotaManager
+	audioNormalizer         *services.AudioNormalizer
+	ttsCatalog              *services.TTSCatalog
+	webhookQueue            *services.WebhookQueue
+	webhookEvents           *services.WebhookDispatcher
+	failureAlerts           *services.FailureAlerts
+	cardJobs                *services.CardJobQueue
+	birdOfDay               store.BirdOfDayStore
+	playEvents              store.PlayEventStore
+	rollout                 *services.RolloutScheduler
+	quizGenerator           *services.QuizGenerator
+	hotspotGuide            *services.HotspotGuide
+	birdHero                *services.BirdHeroGuide
+	voices                  *services.VoiceManager
+	deviceProfiles          *services.DeviceProfileStore
+	overrides               *services.BirdOverrides
+	streamCache             *services.StreamCache
+	userTime                *services.UserTimeHelper
+	dependencies            *services.DependencyMonitor
+	weeklySchedule          *services.WeeklySchedule
+	weeklyFacts             *services.WeeklyFactGuide
+	countingGenerator       *services.CountingGenerator
+	guideCalls              *services.GuideCallSplicer
+	weather                 services.WeatherService
+	outroContent            *services.OutroContentService
+	introComposer           *services.IntroComposer
+	stitcher                *services.AudioStitcher
+	narration               *services.NarrationRenderer
+	recordingWarmer         *services.RecordingWarmer // nil unless ENABLE_RECORDING_WARMER is set
 }
 
 func NewHandler(cfg *config.Config) *Handler {
+	if cfg.Cards == nil {
+		cfg.Cards = config.NewCardRegistry(nil, cfg.YotoCardID)
+	}
+
 	yotoClient := yoto.NewClient(
 		cfg.YotoClientID,
 		"", // No client secret needed for public client
 		cfg.YotoAPIBaseURL,
 	)
 
-	// Set the access and refresh tokens if available
-	if cfg.YotoAccessToken != "" && cfg.YotoRefreshToken != "" {
-		// The expiresIn is not stored, so we'll use a default of 24 hours
-		// The client will check token expiry and refresh as needed
-		yotoClient.SetTokens(cfg.YotoAccessToken, cfg.YotoRefreshToken, 86400)
+	// Set the access and refresh tokens if available; the client refreshes them as they expire
+	if cfg.YotoAccessToken != "" {
+		yotoClient.SetConfiguredTokens(cfg.YotoAccessToken, cfg.YotoRefreshToken)
+	}
+	if cfg.AutoUpdateSecrets {
+		yotoClient.SetSecretsProject(cfg.GCPSecretsProject)
+	}
+
+	// Stored tokens replace the env tokens once they've been rotated
+	if tokenStore, err := yoto.NewTokenStore(cfg.YotoTokenStore, cfg.YotoTokenFile, cfg.GCPSecretsProject); err != nil {
+		log.Printf("Failed to initialize %s token store: %v, using environment tokens only", cfg.YotoTokenStore, err)
+	} else if tokenStore != nil {
+		yotoClient.SetTokenStore(tokenStore)
 	}
 
 	// Initialize timezone lookup service
@@ -38,13 +110,314 @@ func NewHandler(cfg *config.Config) *Handler {
 		log.Printf("Failed to initialize timezone lookup service: %v, will use fallback", err)
 	}
 
-	return &Handler{
+	// A configured database is required: the file store is per instance and would split state
+	birdOfDay, err := store.Open(cfg.BirdStoreDriver, cfg.BirdStoreDSN, cfg.BirdStorePath)
+	if err != nil {
+		log.Fatalf("Failed to open %s bird-of-day store: %v", cfg.BirdStoreDriver, err)
+	}
+	// A database-backed bird store holds play events, queued webhooks, and the fact experiment too
+	playEvents, ok := birdOfDay.(store.PlayEventStore)
+	if !ok {
+		playEvents = store.NewFilePlayStore(cfg.PlayEventsPath)
+	}
+	webhookBacklog, ok := birdOfDay.(store.WebhookQueueStore)
+	if !ok {
+		webhookBacklog = store.NewFileWebhookQueue(cfg.WebhookQueuePath)
+	}
+	experiments, ok := birdOfDay.(store.ExperimentStore)
+	if !ok {
+		experiments = store.NewFileExperimentStore(cfg.FactExperimentPath)
+	}
+
+	birdStorage := services.NewBirdStorage("")
+	birdStorage.SetTranscriptDir(cfg.TranscriptDir)
+	deviceRegistry := services.NewDeviceRegistry(cfg.DeviceRegistryPath)
+	deviceRegistry.SetLocationSmoothing(cfg.LocationSmoothingWindowDays, cfg.LocationSmoothingSwitchDays)
+
+	photoFetcher := services.NewPhotoFetcher(cfg.PhotoLicenses)
+
+	// Region packs only feature birds their country's eBird checklist lists, and rate their birds
+	// by how often the country reports them to pick special guests
+	availableBirds := services.NewAvailableBirdsService()
+	if cfg.EBirdAPIKey != "" {
+		availableBirds.SetRegionChecklists(services.NewRegionChecklists(cfg.EBirdAPIKey))
+		if cfg.EnableSpecialGuests {
+			availableBirds.SetRegionFrequencies(services.SharedRegionFrequencies(cfg.EBirdAPIKey))
+		}
+	}
+
+	// Every ElevenLabs render shares one budget
+	ttsQuota := services.NewQuotaManager(cfg.ElevenLabsQuotaPath, cfg.ElevenLabsDailyCharBudget, cfg.ElevenLabsMonthlyCharBudget, cfg.ElevenLabsMaxConcurrent)
+	tts := services.NewElevenLabsTTS(cfg.ElevenLabsAPIKey, "")
+	tts.SetQuotaManager(ttsQuota)
+	tts.SetCache(services.NewTTSCacheFor(cfg.TTSCacheBackend, cfg.TTSCacheBucket, cfg.TTSCacheDir))
+
+	userTime := services.NewUserTimeHelper()
+	userTime.SetBedtime(cfg.BedtimeHour, cfg.WakeHour)
+
+	voices := services.NewVoiceManager(cfg.LocaleVoices, cfg.NarratorVoiceID)
+	voices.SetVoiceCasts(cfg.VoiceCasts)
+
+	// The quiz, guide calls, counting activity, and warmer share one selector and its eBird client
+	recordings := services.NewRecordingSelector(cfg.XenoCantoAPIKey, cfg.EBirdAPIKey)
+
+	handler := &Handler{
 		config:                  cfg,
-		locationService:         services.NewLocationService(),
+		locationService:         services.NewLocationService(cfg.GeoLite2Path),
 		timezoneLocationService: services.NewTimezoneLocationService(),
 		timezoneLookup:          timezoneLookup,
 		yotoClient:              yotoClient,
 		updateCache:             services.NewUpdateCache(),
-		availableBirds:          services.NewAvailableBirdsService(),
+		availableBirds:          availableBirds,
+		triviaGenerator:         services.NewTriviaGenerator(birdStorage),
+		defaultLocations:        services.NewDefaultLocationResolver(cfg.DefaultLocation, cfg.CardDefaultLocations),
+		deviceRegistry:          deviceRegistry,
+		primerService:           services.NewPrimerService(deviceRegistry, birdStorage),
+		householdEnricher:       services.NewHouseholdEnricher(cfg.EBirdAPIKey, cfg.HouseholdDevices),
+		updateQueue:             services.NewUpdateQueue(cfg.MaxConcurrentUpdates, time.Duration(cfg.WebhookRetryAfterSeconds)*time.Second),
+		birdStorage:             birdStorage,
+		localizedNames:          services.NewLocalizedNameService(cfg.BilingualLocale),
+		pipelineEvents:          services.NewPipelineEvents(),
+		factExperiment:          services.NewFactExperiment(cfg.FactGenerator, cfg.FactExperimentPercent, "fact-generator-v1", experiments),
+		themes:                  services.NewThemeManager(cfg.ThemesPath, cfg.HolidayLocale, cfg.HolidayCalendarPath),
+		covers:                  services.NewCoverManager(cfg.CoverArtworkPath),
+		songVisualizer:          services.NewSongVisualizer(birdStorage),
+		photoFetcher:            photoFetcher,
+		birdIconGenerator:       services.NewBirdIconGenerator(photoFetcher),
+		audioNormalizer:         services.NewAudioNormalizer(float64(cfg.LoudnessTargetLUFS)),
+		ttsCatalog:              services.NewTTSCatalog(cfg.PrerecordedTTSDir),
+		webhookQueue:            services.NewWebhookQueue(webhookBacklog, time.Duration(cfg.WebhookRetryAfterSeconds)*time.Second),
+		webhookEvents:           services.NewWebhookDispatcher(),
+		failureAlerts:           newFailureAlerts(cfg),
+		cardJobs:                services.NewCardJobQueue(cfg.CardJobsPath, time.Duration(cfg.WebhookRetryAfterSeconds)*time.Second),
+		birdOfDay:               birdOfDay,
+		playEvents:              playEvents,
+		rollout:                 services.NewRolloutScheduler(cfg.RolloutStatePath),
+		quizGenerator:           services.NewQuizGenerator(cfg.EBirdAPIKey, recordings, tts),
+		hotspotGuide:            services.NewHotspotGuide(cfg.EBirdAPIKey, tts),
+		birdHero:                services.NewBirdHeroGuide(tts),
+		ttsQuota:                ttsQuota,
+		voices:                  voices,
+		deviceProfiles:          services.NewDeviceProfileStore(cfg.DeviceProfilesPath),
+		overrides:               services.NewBirdOverrides(cfg.BirdOverridesPath),
+		streamCache:             services.NewStreamCache(cfg.StreamCacheMB),
+		userTime:                userTime,
+		dependencies:            services.NewDependencyMonitor(),
+		weeklySchedule:          services.NewWeeklySchedule(cfg.WeeklySchedulePath),
+		weeklyFacts:             services.NewWeeklyFactGuide(birdStorage, tts),
+		countingGenerator:       services.NewCountingGenerator(recordings, tts),
+		guideCalls:              services.NewGuideCallSplicer(recordings, tts),
+		weather:                 services.NewWeatherService(cfg.WeatherProvider, cfg.OpenMeteoURL),
+		outroContent:            services.NewOutroContentService(cfg.OutroHistoryPath, tts),
+		introComposer:           services.NewIntroComposer(tts),
+		stitcher:                services.NewAudioStitcher(),
+		narration:               services.NewNarrationRenderer(tts, services.NewNarrationStore(cfg.NarrationDir)),
+	}
+
+	// Webhook card refreshes wait for the TTS budget to reset rather than run on fallbacks
+	handler.updateQueue.SetBudgetCheck(ttsQuota.HasBudget)
+	handler.registerHealthChecks()
+	handler.registerWebhookHandlers()
+	handler.webhookQueue.Start(handler.processWebhookEntry)
+	handler.cardJobs.OnAbandon(handler.reportAbandonedCardJob)
+	handler.cardJobs.Start(handler.resumeCardJob)
+
+	// Cache next week's recordings overnight so card updates don't wait on xeno-canto
+	if cfg.EnableRecordingWarmer {
+		warmer := services.NewRecordingWarmer(services.SharedAssetStore(), recordings, handler.audioNormalizer)
+		if cfg.EnableSongTrimming {
+			warmer.SetTrimmer(services.NewSongTrimmer(float64(cfg.SongClipMinSeconds), float64(cfg.SongClipMaxSeconds)))
+		}
+		handler.quizGenerator.SetRecordingCache(warmer)
+		handler.countingGenerator.SetRecordingCache(warmer)
+		handler.guideCalls.SetRecordingCache(warmer)
+		handler.recordingWarmer = warmer
+		warmer.Start(cfg.RecordingWarmHour, handler.upcomingSpecies)
+	}
+
+	// Re-check stale species icon mappings against yotoicons.com once a day
+	yoto.NewIconSearcher(yotoClient).StartRefresh(24 * time.Hour)
+
+	// Learn the account's players and their timezones from Yoto rather than waiting for plays
+	if cfg.DeviceSyncMinutes > 0 {
+		handler.startDeviceSync(time.Duration(cfg.DeviceSyncMinutes) * time.Minute)
+	}
+
+	// Load the species taxonomy in the background so the first name lookup doesn't wait on the download
+	if cfg.EBirdAPIKey != "" {
+		go func() {
+			if err := ebird.SharedTaxonomy(cfg.EBirdAPIKey).Load(); err != nil {
+				log.Printf("[EBIRD] %v", err)
+			}
+		}()
+	}
+	return handler
+}
+
+// Stats returns in-memory state sizes for leak monitoring
+func (h *Handler) Stats() map[string]interface{} {
+	stats := h.updateCache.GetStats()
+	stats["streaming_sessions"] = SessionCount()
+	stats["update_queue"] = h.updateQueue.Stats()
+	stats["webhook_queue"] = h.webhookQueue.Stats()
+	stats["card_jobs"] = h.cardJobs.Stats()
+	stats["tts_quota"] = h.ttsQuota.Status()
+	stats["rollout"] = h.rollout.Stats()
+	stats["event_subscribers"] = h.pipelineEvents.SubscriberCount()
+	stats["fact_experiment"] = h.factExperiment.Stats()
+	stats["stream_cache"] = h.streamCache.Stats()
+	stats["http_breakers"] = httpx.Breakers()
+	return stats
+}
+
+// birdCoverEnabled reports whether updates to the card replace its cover with the bird's photo
+func (h *Handler) birdCoverEnabled(card config.CardProfile) bool {
+	if card.BirdCover != nil {
+		return *card.BirdCover
+	}
+	return h.config.EnableBirdCover
+}
+
+// coverRotationEnabled reports whether updates to the card set its cover from the artwork rotation
+func (h *Handler) coverRotationEnabled(card config.CardProfile) bool {
+	if card.CoverRotation != nil {
+		return *card.CoverRotation && h.covers.Enabled()
+	}
+	return h.covers.Enabled()
+}
+
+// southernCard reports whether the card's seasons are the southern hemisphere's, from its default
+// location, else its species pool
+func (h *Handler) southernCard(card config.CardProfile) bool {
+	if location, ok := h.defaultLocations.Resolve(card.CardID); ok {
+		return location.Latitude < 0
+	}
+	if pack := services.RegionPackByID(card.Region); pack != nil {
+		return pack.Continent == "oceania" || pack.Continent == "south_america"
+	}
+	return false
+}
+
+// streamingCardEnabled reports whether the card's tracks are all generated per request, so its
+// content is only posted when its layout changes
+func (h *Handler) streamingCardEnabled(card config.CardProfile) bool {
+	if card.Streaming != nil {
+		return *card.Streaming
+	}
+	return h.config.EnableStreamingCards
+}
+
+// birdHeroEnabled reports whether the card gets the bird hero chapter for threatened birds
+func (h *Handler) birdHeroEnabled(card config.CardProfile) bool {
+	if card.BirdHero != nil {
+		return *card.BirdHero
+	}
+	return h.config.EnableBirdHero
+}
+
+// singleTrackEnabled reports whether the card plays as one stitched track instead of chapters
+func (h *Handler) singleTrackEnabled(card config.CardProfile) bool {
+	if card.SingleTrack != nil {
+		return *card.SingleTrack
+	}
+	return h.config.EnableSingleTrack
+}
+
+// narratorVoice returns the listener's chosen voice, otherwise the voice cast for the track role
+// on the listener's local day
+func (h *Handler) narratorVoice(voiceID string, role string, localNow time.Time) string {
+	if voiceID != "" {
+		return voiceID
+	}
+	return h.voices.VoiceForRole(h.config.ContentLocale, role, localNow)
+}
+
+// newContentManager creates a Yoto content manager with deployment-level card options applied,
+// overridden by the card's own profile
+func (h *Handler) newContentManager(card config.CardProfile) *yoto.ContentManager {
+	contentManager := h.yotoClient.NewContentManager()
+	contentManager.SetCardTitle(card.Title)
+	contentManager.SetRandomizer(randx.Daily(clock().UTC().Format("2006-01-02"), card.CardID))
+	contentManager.SetTranscodeWait(time.Duration(h.config.YotoTranscodePollMillis)*time.Millisecond,
+		time.Duration(h.config.YotoTranscodeMaxWaitSeconds)*time.Second)
+	contentManager.SetAsyncTranscode(h.config.YotoTranscodeAsync)
+	includePrimer := h.config.EnableFamilyPrimer
+	if card.IncludePrimer != nil {
+		includePrimer = *card.IncludePrimer
+	}
+	contentManager.SetIncludePrimer(includePrimer)
+	policy := h.dependencies.Policy()
+	includeQuiz := h.config.EnableBirdQuiz
+	if card.IncludeQuiz != nil {
+		includeQuiz = *card.IncludeQuiz
+	}
+	contentManager.SetIncludeQuiz(includeQuiz && !policy.SkipsChapter(services.ChapterQuiz))
+	includeHotspots := h.config.EnableHotspotChapter
+	if card.IncludeHotspots != nil {
+		includeHotspots = *card.IncludeHotspots
+	}
+	contentManager.SetIncludeHotspots(includeHotspots && !policy.SkipsChapter(services.ChapterHotspots))
+	contentManager.SetIncludeListenCount(h.listenCountEnabled(card) && !policy.SkipsChapter(services.ChapterCounting))
+	if segments := policy.FilterChapters(h.cardTemplateSegments(card)); len(segments) > 0 {
+		if err := contentManager.SetCardTemplate(yoto.CardTemplate{Segments: segments}); err != nil {
+			log.Printf("[CARD_TEMPLATE] Ignoring chapter layout for card %s, using the standard layout: %v", card.CardID, err)
+		}
+	}
+	contentManager.SetDynamicStreams(h.config.EnableDynamicStreams)
+	contentManager.SetStreamingCard(h.streamingCardEnabled(card))
+	contentManager.SetSingleTrack(h.singleTrackEnabled(card))
+	contentManager.SetTrackStitcher(h.stitcher.Stitch)
+	contentManager.SetTitleFormatter(yoto.NewTitleFormatter(h.config.TitleEnglishVariant))
+	if h.config.EnableSongVisualizer {
+		contentManager.SetGuideIconProvider(h.songVisualizer.IconForBird)
+	}
+	if h.config.EnableGeneratedIcons {
+		contentManager.SetBirdIconProvider(h.birdIconGenerator.IconForBird)
+	}
+	if h.config.EnableAudioNormalization && !policy.SkipNormalization {
+		contentManager.SetAudioNormalizer(h.audioNormalizer.Normalize)
+	}
+	contentManager.SetPlaybackOptions(h.playbackOptions(card))
+	return contentManager
+}
+
+// playbackOptions returns the card's playback behavior: the deployment's autoadvance and resume
+// settings, with the card's own autoadvance taking precedence
+func (h *Handler) playbackOptions(card config.CardProfile) *yoto.PlaybackOptions {
+	options := &yoto.PlaybackOptions{Config: yoto.ContentConfig{
+		AutoAdvance:   h.config.YotoAutoAdvance,
+		ResumeTimeout: h.config.YotoResumeTimeoutSeconds,
+	}}
+	switch card.AutoAdvance {
+	case "":
+	case yoto.AutoAdvanceNext, yoto.AutoAdvanceNone, yoto.AutoAdvanceRepeat:
+		options.Config.AutoAdvance = card.AutoAdvance
+	default:
+		log.Printf("[CARD_PLAYBACK] Ignoring unknown autoadvance %q for card %s", card.AutoAdvance, card.CardID)
+	}
+	return options
+}
+
+// cardTemplateSegments returns the card's chapter layout, else the deployment's, else nil for the
+// standard layout
+func (h *Handler) cardTemplateSegments(card config.CardProfile) []string {
+	if len(card.Chapters) > 0 {
+		return card.Chapters
+	}
+	var segments []string
+	for _, segment := range strings.Split(h.config.CardTemplate, ",") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// localizedBirdName returns the bird's common name in the bilingual mode language
+func (h *Handler) localizedBirdName(ctx context.Context, birdName string) string {
+	scientificName := ""
+	if metadata, err := h.birdStorage.GetBirdMetadata(birdName); err == nil {
+		scientificName = metadata.ScientificName
 	}
+	return h.localizedNames.CommonName(ctx, birdName, scientificName)
 }
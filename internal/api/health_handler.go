@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Hosts whose circuit breakers back the dependency checks
+const (
+	elevenLabsHost = "api.elevenlabs.io"
+	eBirdHost      = "api.ebird.org"
+	xenoCantoHost  = "xeno-canto.org"
+)
+
+// registerHealthChecks registers a check for each dependency card updates rely on
+func (h *Handler) registerHealthChecks() {
+	h.dependencies.Register(h.yotoAuthHealth)
+	h.dependencies.Register(h.elevenLabsHealth)
+	h.dependencies.Register(func() services.DependencyStatus {
+		if h.config.EBirdAPIKey == "" {
+			return services.DependencyStatus{Name: services.DependencyEBird, Status: services.HealthDisabled, Detail: "EBIRD_API_KEY is not set"}
+		}
+		return services.HostHealth(services.DependencyEBird, eBirdHost, false)
+	})
+	h.dependencies.Register(func() services.DependencyStatus {
+		return services.HostHealth(services.DependencyXenoCanto, xenoCantoHost, false)
+	})
+	h.dependencies.Register(ffmpegHealth)
+	h.dependencies.Register(func() services.DependencyStatus {
+		// Every track streams from the asset store, so nothing plays while it's down
+		return services.HostHealth(services.DependencyAssetStore, hostOf(narrationBaseURL), true)
+	})
+}
+
+// yotoAuthHealth reports whether the Yoto client holds usable credentials
+func (h *Handler) yotoAuthHealth() services.DependencyStatus {
+	status := services.HostHealth(services.DependencyYotoAuth, hostOf(h.config.YotoAPIBaseURL), false)
+	auth := h.yotoClient.AuthStatus()
+	switch {
+	case !auth.HasAccessToken && !auth.HasRefreshToken:
+		status.Status = services.HealthDown
+		status.Detail = "no Yoto tokens, complete the OAuth flow"
+	case auth.LastError != "":
+		status.Status = services.HealthDown
+		status.Detail = auth.LastError
+	case status.Status == services.HealthOK && auth.HasAccessToken && time.Now().After(auth.ExpiresAt):
+		status.Detail = "access token expired, it will be refreshed on the next call"
+	}
+	return status
+}
+
+// elevenLabsHealth reports the text-to-speech API's breaker and remaining character quota
+func (h *Handler) elevenLabsHealth() services.DependencyStatus {
+	if h.config.ElevenLabsAPIKey == "" {
+		return services.DependencyStatus{Name: services.DependencyElevenLabs, Status: services.HealthDisabled, Detail: "ELEVENLABS_API_KEY is not set"}
+	}
+	status := services.HostHealth(services.DependencyElevenLabs, elevenLabsHost, false)
+	if status.Status == services.HealthDown {
+		return status
+	}
+	quota := h.ttsQuota.Status()
+	if quota.DailyRemaining == 0 || quota.MonthlyRemaining == 0 {
+		status.Status = services.HealthDown
+		status.Detail = fmt.Sprintf("character quota used up until %s", quota.ResetsAt.Format(time.RFC3339))
+	}
+	return status
+}
+
+// ffmpegHealth reports whether ffmpeg is installed with the filters normalization needs
+func ffmpegHealth() services.DependencyStatus {
+	status := services.DependencyStatus{Name: services.DependencyFFmpeg, Status: services.HealthOK}
+	caps := services.GetFFmpegCapabilities()
+	switch {
+	case !caps.Available:
+		status.Status = services.HealthDown
+		status.Detail = "ffmpeg is not installed"
+	case !caps.Loudnorm || !caps.MP3Encode:
+		status.Status = services.HealthDegraded
+		status.Detail = "ffmpeg lacks loudnorm or an MP3 encoder"
+	}
+	return status
+}
+
+// hostOf returns a URL's host, which is what circuit breakers are keyed by
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// Healthz reports each dependency's status and the fallbacks currently active. It always
+// answers 200 so a degraded instance isn't restarted; use Readyz to take it out of rotation.
+func (h *Handler) Healthz(c *gin.Context) {
+	statuses := h.dependencies.Statuses()
+	c.JSON(http.StatusOK, gin.H{
+		"ready":        h.dependencies.Ready(statuses),
+		"dependencies": statuses,
+		"degradation":  services.PolicyFor(statuses),
+	})
+}
+
+// Readyz answers 503 while a critical dependency is down
+func (h *Handler) Readyz(c *gin.Context) {
+	statuses := h.dependencies.Statuses()
+	code := http.StatusOK
+	ready := h.dependencies.Ready(statuses)
+	if !ready {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"ready":        ready,
+		"dependencies": statuses,
+	})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamHotspots plays the "Where can you see it?" chapter naming nearby parks where families could
+// see the session's bird. Without a location, or when no park-like hotspot is found, the chapter
+// plays the silent skip clip.
+func (h *Handler) StreamHotspots(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Query("session")
+	session := h.getOrCreateSession(c, sessionID)
+
+	birdName := session.BirdName
+	if birdName == "" {
+		selectedBird, err := h.getDailyBirdWithFallback(c, "hotspots")
+		if err != nil {
+			slog.WarnContext(ctx, "[STREAMING] hotspots: No bird for session", "error", err)
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		birdName = selectedBird
+		session.BirdName = birdName
+		putSession(session)
+	}
+
+	if session.Location == nil {
+		slog.InfoContext(ctx, "[STREAMING] hotspots: No location for session, skipping", "session", session.SessionID)
+		streamSkip(c)
+		return
+	}
+
+	voiceID := h.narratorVoice(session.VoiceID, services.VoiceRoleHotspots, locationLocalTime(session.Location))
+
+	tour, err := h.hotspotGuide.GenerateTour(ctx, birdName, session.Location.Latitude, session.Location.Longitude, voiceID)
+	if err != nil {
+		slog.WarnContext(ctx, "[STREAMING] hotspots: Failed to generate tour, skipping", "bird", birdName, "error", err)
+		streamSkip(c)
+		return
+	}
+
+	slog.InfoContext(ctx, "[STREAMING] hotspots: Playing tour", "bird", birdName, "places", len(tour.Places))
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "audio/mpeg", tour.Audio)
+}
+
+// hotspotAudio renders the hotspot tour, falling back to the silent skip clip when it can't be made
+func (h *Handler) hotspotAudio(ctx context.Context, birdName string, location *models.Location, voiceID string, localNow time.Time) (*services.StreamAudio, error) {
+	if location != nil {
+		voiceID = h.narratorVoice(voiceID, services.VoiceRoleHotspots, localNow)
+		tour, err := h.hotspotGuide.GenerateTour(ctx, birdName, location.Latitude, location.Longitude, voiceID)
+		if err == nil {
+			return services.NewStreamAudio(tour.Audio), nil
+		}
+		slog.WarnContext(ctx, "[STREAMING] hotspots: Failed to generate tour, skipping", "bird", birdName, "error", err)
+	}
+	return skipClip, nil
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const narrationBaseURL = "https://storage.googleapis.com/bird-song-explorer-audio/birds"
+
+// variantExists caches whether a narration variant has been rendered to storage, by URL
+var variantExists sync.Map
+
+// variantMissTTL is how long a variant found missing is served the fallback before storage is
+// checked again, so intros probing several variants don't pay a HEAD request per miss per play
+const variantMissTTL = 10 * time.Minute
+
+// variantLookup is a cached storage check; misses expire after variantMissTTL
+type variantLookup struct {
+	exists    bool
+	checkedAt time.Time
+}
+
+// GetHouseholdSections returns the location-specific guide sections generated for each
+// household device, so the narration pipeline can render one description variant per home. The
+// sections name devices and where they are, so the route is behind the admin token.
+func (h *Handler) GetHouseholdSections(c *gin.Context) {
+	if !h.householdEnricher.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Split household mode is not configured"})
+		return
+	}
+
+	date, sections := h.householdEnricher.Sections()
+	c.JSON(http.StatusOK, gin.H{
+		"date":     date,
+		"sections": sections,
+	})
+}
+
+// renderHouseholdVariants generates each household's location sections for the day's bird and
+// renders its description variant, which descriptionURL serves to the household's devices once
+// it is uploaded
+func (h *Handler) renderHouseholdVariants(ctx context.Context, bird *models.Bird, localNow time.Time) {
+	sections := h.householdEnricher.EnrichForBird(ctx, bird, localNow.Format("2006-01-02"))
+	voiceID := h.narratorVoice("", services.VoiceRoleGuide, localNow)
+	for _, deviceSections := range sections {
+		h.renderNarrationVariant(ctx, nil, bird.CommonName, "description_"+deviceSections.Variant+".mp3", deviceSections.Script, voiceID)
+	}
+}
+
+// descriptionURL picks the description narration for the requesting device. Devices in a
+// split household get their location-specific variant once it has been rendered; everyone
+// else, and households whose variant isn't ready, get the shared description, or the rendered
+// guide for the device's preferred generator ("basic", "enhanced", ...) when it asked for one.
+func (h *Handler) descriptionURL(c *gin.Context, birdName string, generator string) string {
+	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	sharedURL := fmt.Sprintf("%s/%s/narration/description.mp3", narrationBaseURL, birdDir)
+	if generator != "" {
+		generatorURL := fmt.Sprintf("%s/%s/narration/description_%s.mp3", narrationBaseURL, birdDir, generator)
+		if narrationVariantExists(generatorURL) {
+			sharedURL = generatorURL
+		}
+	}
+
+	if !h.householdEnricher.Enabled() {
+		return sharedURL
+	}
+
+	deviceID := deviceIDFromRequest(c)
+	sections, ok := h.householdEnricher.SectionsForDevice(deviceID, birdName)
+	if !ok {
+		return sharedURL
+	}
+
+	variantURL := fmt.Sprintf("%s/%s/narration/description_%s.mp3", narrationBaseURL, birdDir, sections.Variant)
+	if !narrationVariantExists(variantURL) {
+		log.Printf("[STREAMING] description: Variant %s not rendered yet for %s, using shared description", sections.Variant, deviceID)
+		return sharedURL
+	}
+
+	log.Printf("[STREAMING] description: Serving %s variant to %s", sections.Variant, deviceID)
+	return variantURL
+}
+
+// rememberNarrationVariant records a variant that was just rendered, so it is served without a check
+func rememberNarrationVariant(url string) {
+	variantExists.Store(url, variantLookup{exists: true})
+}
+
+// narrationVariantExists checks storage for a rendered variant, remembering hits for good and
+// misses, including failed checks, for variantMissTTL
+func narrationVariantExists(url string) bool {
+	if cached, ok := variantExists.Load(url); ok {
+		lookup := cached.(variantLookup)
+		if lookup.exists || time.Since(lookup.checkedAt) < variantMissTTL {
+			return lookup.exists
+		}
+	}
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	exists := false
+	if resp, err := client.Head(url); err == nil {
+		resp.Body.Close()
+		exists = resp.StatusCode == http.StatusOK
+	}
+
+	variantExists.Store(url, variantLookup{exists: exists, checkedAt: time.Now()})
+	return exists
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// introAudio fetches the intro and, for devices whose profile asks for a dynamic intro
+// (?greeting=true), opens it with a greeting for the listener's time of day and town, over nature
+// sounds for their biome or time of day. Greetings are English-only, so other content languages
+// and failed renders get the plain intro.
+func (h *Handler) introAudio(c *gin.Context, introURL string, location *models.Location, localNow time.Time) (*services.StreamAudio, error) {
+	intro, err := h.streamCache.Fetch(c.Request.Context(), introURL)
+	if err != nil || c.Query("greeting") != "true" || services.NormalizeLocale(h.config.ContentLocale) != services.DefaultLocale {
+		return intro, err
+	}
+
+	city := ""
+	var latitude, longitude float64
+	if location != nil {
+		city, latitude, longitude = location.City, location.Latitude, location.Longitude
+	}
+	ambience := ""
+	if c.Query("nature") != "false" {
+		ambience = services.NatureSoundForPlace(latitude, longitude, localNow)
+	}
+	voiceID := h.narratorVoice(c.Query("voice"), services.VoiceRoleIntro, localNow)
+
+	composed, err := h.introComposer.Compose(c.Request.Context(), intro.Data, introURL, localNow, city, voiceID, ambience)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "[STREAMING] intro: Greeting unavailable, using the plain intro", "error", err)
+		return intro, nil
+	}
+	return services.NewStreamAudio(composed), nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// listenCountEnabled reports whether the card gets the "Listen and count!" activity chapter
+func (h *Handler) listenCountEnabled(card config.CardProfile) bool {
+	if card.ListenAndCount != nil {
+		return *card.ListenAndCount
+	}
+	return h.config.EnableListenAndCount
+}
+
+// StreamListenCount plays the "Listen and count!" activity for the session's bird. The answer is
+// saved for the outro; birds without a countable recording get the silent skip clip.
+func (h *Handler) StreamListenCount(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Query("session")
+	session := h.getOrCreateSession(c, sessionID)
+
+	birdName := session.BirdName
+	if birdName == "" {
+		selectedBird, err := h.getDailyBirdWithFallback(c, "listen_count")
+		if err != nil {
+			slog.WarnContext(ctx, "[STREAMING] listen_count: No bird for session", "error", err)
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		birdName = selectedBird
+		session.BirdName = birdName
+		putSession(session)
+	}
+
+	localNow := locationLocalTime(session.Location)
+	voiceID := h.narratorVoice(session.VoiceID, services.VoiceRoleCounting, localNow)
+
+	activity, err := h.countingGenerator.GenerateActivity(ctx, birdName, localNow, voiceID)
+	if err != nil {
+		slog.InfoContext(ctx, "[STREAMING] listen_count: No activity, skipping", "bird", birdName, "error", err)
+		streamSkip(c)
+		return
+	}
+
+	slog.InfoContext(ctx, "[STREAMING] listen_count: Playing activity", "bird", birdName, "count", activity.Count)
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "audio/mpeg", activity.Audio)
+}
+
+// countingAudio renders the listen-and-count activity, falling back to the silent skip clip when
+// it can't be made
+func (h *Handler) countingAudio(ctx context.Context, birdName string, voiceID string, localNow time.Time) (*services.StreamAudio, error) {
+	voiceID = h.narratorVoice(voiceID, services.VoiceRoleCounting, localNow)
+	activity, err := h.countingGenerator.GenerateActivity(ctx, birdName, localNow, voiceID)
+	if err == nil {
+		return services.NewStreamAudio(activity.Audio), nil
+	}
+	slog.InfoContext(ctx, "[STREAMING] listen_count: No activity, skipping", "bird", birdName, "error", err)
+	return skipClip, nil
+}
+
+// withCountingAnswer returns the outro with the listen-and-count answer read first, in the
+// activity's voice. Without an activity for the day the outro plays alone.
+func (h *Handler) withCountingAnswer(ctx context.Context, birdName string, voiceID string, localNow time.Time, outro *services.StreamAudio) *services.StreamAudio {
+	voiceID = h.narratorVoice(voiceID, services.VoiceRoleCounting, localNow)
+	return services.NewStreamAudio(h.countingGenerator.PrependAnswer(ctx, birdName, localNow, voiceID, outro.Data))
+}
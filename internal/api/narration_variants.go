@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/gin-gonic/gin"
+)
+
+// narrationRenderTimeout bounds rendering one narration variant in the background
+const narrationRenderTimeout = 3 * time.Minute
+
+// renderNarrationVariant narrates script to one of a bird's narration files in the background,
+// so the card update doesn't wait on ElevenLabs. The streaming endpoints pick the variant up as
+// soon as it is uploaded; until then they play the shared narration.
+func (h *Handler) renderNarrationVariant(ctx context.Context, step *services.CardJobStep, birdName string, file string, script string, voiceID string) {
+	h.renderNarration(ctx, step, services.NarrationName(birdName, file), script, voiceID)
+}
+
+// renderNarration narrates script to name, relative to the birds/ folder, in the background as
+// part of a card job's TTS step, or untracked when step is nil
+func (h *Handler) renderNarration(ctx context.Context, step *services.CardJobStep, name string, script string, voiceID string) {
+	step.Go(func() error {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), narrationRenderTimeout)
+		defer cancel()
+
+		rendered, err := h.narration.Render(ctx, name, script, voiceID)
+		if err != nil {
+			slog.WarnContext(ctx, "[NARRATION] Failed to render variant", "name", name, "error", err)
+			return err
+		}
+		if rendered {
+			rememberNarrationVariant(narrationBaseURL + "/" + name)
+		}
+		return nil
+	})
+}
+
+// renderThemeNarration renders the intro and outro of the holiday or seasonal theme running on the
+// job's local date, which the streaming endpoints swap in once they are uploaded
+func (h *Handler) renderThemeNarration(ctx context.Context, step *services.CardJobStep, job services.CardJob) {
+	day := h.jobDay(job)
+	theme, ok := h.themes.ThemeOn(day)
+	if !ok {
+		return
+	}
+
+	birdDir := strings.ToLower(strings.ReplaceAll(job.BirdName, " ", "_"))
+	if intro := theme.IntroText(day, job.BirdName); intro != "" {
+		h.renderNarration(ctx, step, theme.IntroName(day, birdDir), intro, h.narratorVoice("", services.VoiceRoleIntro, day))
+	}
+	if outro := theme.OutroText(day, job.BirdName); outro != "" {
+		h.renderNarration(ctx, step, theme.OutroName(day, birdDir), outro, h.narratorVoice("", services.VoiceRoleOutro, day))
+	}
+}
+
+// bilingualClip names a bird's bilingual intro or announcement ("intro_bilingual_es") for the
+// configured second language
+func (h *Handler) bilingualClip(clip string) string {
+	return clip + "_bilingual_" + services.NormalizeLocale(h.localizedNames.Locale())
+}
+
+// renderBilingualNarration renders the intro and announcement naming the job's bird in both
+// languages, which the streaming endpoints swap in once they are uploaded
+func (h *Handler) renderBilingualNarration(ctx context.Context, step *services.CardJobStep, job services.CardJob) {
+	if !h.localizedNames.Enabled() {
+		return
+	}
+	narration, ok := services.BilingualNarrationFor(h.localizedNames.Locale(), job.BirdName, h.localizedBirdName(ctx, job.BirdName))
+	if !ok {
+		return
+	}
+
+	voiceID := h.narratorVoice("", services.VoiceRoleIntro, h.jobDay(job))
+	h.renderNarrationVariant(ctx, step, job.BirdName, h.bilingualClip("intro")+".mp3", narration.Intro, voiceID)
+	h.renderNarrationVariant(ctx, step, job.BirdName, h.bilingualClip("announcement")+".mp3", narration.Announcement, voiceID)
+}
+
+// experimentGuideGenerator returns the generator whose guide the card plays on date and whether
+// it has its own rendered guide: cards pinned to a generator and cards in the fact generator
+// experiment do, everyone else plays the shared description
+func (h *Handler) experimentGuideGenerator(card config.CardProfile, date string) (string, bool) {
+	if card.FactGenerator != "" {
+		return card.FactGenerator, true
+	}
+	return h.factExperiment.AssignmentFor(card.CardID, date), h.factExperiment.Enabled()
+}
+
+// guideVariant is the generator whose rendered guide the listener gets, or "" for the shared
+// description: the device profile's preference, else the card's pinned generator or experiment arm
+func (h *Handler) guideVariant(c *gin.Context, card config.CardProfile, date string) string {
+	if generator := factsPreference(c); generator != "" {
+		return generator
+	}
+	if generator, variant := h.experimentGuideGenerator(card, date); variant {
+		return generator
+	}
+	return ""
+}
+
+// renderGuideVariant writes the card's guide for the job's bird in the background and stores its
+// transcript, so what the card says can be traced back to its sources. Cards whose experiment arm
+// has its own guide also get description_{generator}.mp3 rendered, so the arm is what their
+// listeners hear; cards narrating the guide live need no variant.
+func (h *Handler) renderGuideVariant(ctx context.Context, step *services.CardJobStep, card config.CardProfile, job services.CardJob) {
+	bird := h.availableBirds.GetBirdByName(job.BirdName)
+	if bird == nil {
+		return
+	}
+	generator, variant := h.experimentGuideGenerator(card, job.Day)
+
+	var latitude, longitude float64
+	if location, ok := h.defaultLocations.Resolve(card.CardID); ok {
+		latitude, longitude = location.Latitude, location.Longitude
+	}
+
+	step.Go(func() error {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), narrationRenderTimeout)
+		defer cancel()
+
+		transcript := services.NewFactGeneratorForLocale(generator, h.config.EBirdAPIKey, h.config.ContentLocale,
+			randx.Daily(job.Day, bird.CommonName)).GenerateFactTranscript(ctx, bird, latitude, longitude)
+		if err := h.birdStorage.SaveTranscript(transcript); err != nil {
+			slog.WarnContext(ctx, "[NARRATION] Failed to save guide transcript", "bird", bird.CommonName, "error", err)
+		}
+
+		if variant && !h.guideCallsEnabled(card) {
+			voiceID := h.narratorVoice("", services.VoiceRoleGuide, h.jobDay(job))
+			h.renderNarrationVariant(ctx, step, bird.CommonName, "description_"+generator+".mp3", transcript.Script, voiceID)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+)
+
+// nightRegionSuffix keeps a region's night bird apart from its day bird in the bird-of-day store
+const nightRegionSuffix = "_night"
+
+// nightModeEnabled reports whether the card switches to the calmer night variant after bedtime
+func (h *Handler) nightModeEnabled(card config.CardProfile) bool {
+	if card.NightMode != nil {
+		return *card.NightMode
+	}
+	return h.config.EnableNightMode
+}
+
+// contentMode returns services.ContentModeNight when the card has night mode and it's after
+// bedtime where the listener is, otherwise services.ContentModeDay
+func (h *Handler) contentMode(card config.CardProfile, localNow time.Time) string {
+	if !h.nightModeEnabled(card) {
+		return services.ContentModeDay
+	}
+	return h.userTime.ContentModeAt(localNow)
+}
+
+// nightBirdForCard returns the night bird for the card's region on localNow's date, recording an
+// owl/nightjar-type pick on first use so every device hears the same one. Without an available
+// nocturnal species the day bird is used.
+func (h *Handler) nightBirdForCard(card config.CardProfile, localNow time.Time) (*models.Bird, error) {
+	region := cardRegion(card) + nightRegionSuffix
+	localDate := localNow.Format("2006-01-02")
+
+	if storedName, exists := h.dailyBird(region, localDate); exists {
+		if bird := h.availableBirds.GetBirdByName(storedName); bird != nil {
+			return bird, nil
+		}
+	}
+
+	bird := h.availableBirds.GetNocturnalBird()
+	if bird == nil || h.overrides.IsBlocked(bird.CommonName) {
+		log.Printf("[NIGHT_MODE] No nocturnal bird available for %s, using the day bird", cardRegion(card))
+		return h.selectDailyBird(card, localNow)
+	}
+
+	recorded := h.recordDailyBird(region, localDate, bird.CommonName)
+	if existing := h.availableBirds.GetBirdByName(recorded); existing != nil {
+		bird = existing
+	}
+	log.Printf("[NIGHT_MODE] Using %s as the %s night bird for %s", bird.CommonName, cardRegion(card), localDate)
+	return bird, nil
+}
+
+// birdForMode returns the card's bird for the content mode
+func (h *Handler) birdForMode(card config.CardProfile, localNow time.Time, mode string) (*models.Bird, error) {
+	if mode == services.ContentModeNight {
+		return h.nightBirdForCard(card, localNow)
+	}
+	return h.selectDailyBird(card, localNow)
+}
+
+// webhookDeviceTime is the current time where a webhook's device is, from its stored location,
+// else the card's default location, else the card's timezone
+func (h *Handler) webhookDeviceTime(card config.CardProfile, deviceID string) time.Time {
+	if record, ok := h.deviceRegistry.Get(deviceID); ok && record.Location != nil {
+		return cardLocalTime(card, record.Location)
+	}
+	if fallback, ok := h.defaultLocations.Resolve(card.CardID); ok {
+		return cardLocalTime(card, fallback)
+	}
+	return cardLocalTime(card, nil)
+}
+
+// updateCacheContext is the update cache context for a webhook refresh, so a card refreshed by
+// day is refreshed again for its first play after bedtime
+func updateCacheContext(mode string) string {
+	if mode == services.ContentModeNight {
+		return "webhook_night"
+	}
+	return "webhook"
+}
@@ -7,10 +7,8 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
-	"github.com/callen/bird-song-explorer/pkg/gcp"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,7 +21,7 @@ type OAuthTokenResponse struct {
 
 // HandleTokenRefresh manually triggers a token refresh for testing
 func (h *Handler) HandleTokenRefresh(c *gin.Context) {
-	refreshToken := os.Getenv("YOTO_REFRESH_TOKEN")
+	refreshToken := h.config.YotoRefreshToken
 	if refreshToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No refresh token available"})
 		return
@@ -38,11 +36,11 @@ func (h *Handler) HandleTokenRefresh(c *gin.Context) {
 
 	h.yotoClient.SetTokens(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn)
 
-	// Persist tokens to Secret Manager so new instances get the fresh tokens
-	if err := gcp.UpdateYotoTokens(tokens.AccessToken, tokens.RefreshToken); err != nil {
-		log.Printf("[TOKEN_REFRESH] Warning: Failed to update tokens in Secret Manager: %v", err)
+	// Persist tokens so new instances get the fresh tokens
+	if err := h.yotoClient.PersistTokens(); err != nil {
+		log.Printf("[TOKEN_REFRESH] Warning: Failed to persist tokens: %v", err)
 	} else {
-		log.Printf("[TOKEN_REFRESH] ✅ Successfully persisted tokens to Secret Manager")
+		log.Printf("[TOKEN_REFRESH] ✅ Successfully persisted tokens")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+)
+
+// outroRotationEnabled reports whether the card's outro is narrated live from the rotating banks
+func (h *Handler) outroRotationEnabled(card config.CardProfile) bool {
+	if card.OutroRotation != nil {
+		return *card.OutroRotation
+	}
+	return h.config.EnableOutroRotation
+}
+
+// rotatingOutroAudio narrates the card's outro for the day from the rotation, falling back to the
+// bird's pre-recorded outro when it can't be rendered
+func (h *Handler) rotatingOutroAudio(ctx context.Context, cardID string, birdName string, voiceID string, localNow time.Time) (*services.StreamAudio, error) {
+	voiceID = h.narratorVoice(voiceID, services.VoiceRoleOutro, localNow)
+	outro, err := h.outroContent.GenerateOutro(ctx, cardID, birdName, localNow, voiceID)
+	if err == nil {
+		return services.NewStreamAudio(outro.Audio), nil
+	}
+	slog.WarnContext(ctx, "[STREAMING] outro: Rotation unavailable, using the recorded outro", "card_id", cardID, "bird", birdName, "error", err)
+	return h.streamCache.Fetch(ctx, outroURL(birdName, false))
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Play report window, in days
+const (
+	defaultPlayReportDays = 30
+	maxPlayReportDays     = 180
+)
+
+// birdPlays is how often a bird's card was started and on how many players
+type birdPlays struct {
+	Bird    string `json:"bird"`
+	Plays   int    `json:"plays"`
+	Devices int    `json:"devices"`
+}
+
+// trackListening is how long listeners stayed on a track before stopping
+type trackListening struct {
+	Chapter              string  `json:"chapter"`
+	Track                string  `json:"track"`
+	Stops                int     `json:"stops"`
+	AverageListenSeconds float64 `json:"average_listen_seconds"`
+}
+
+// chapterDropOff is where playback stopped, as a share of all stops
+type chapterDropOff struct {
+	Chapter  string  `json:"chapter"`
+	Starts   int     `json:"starts"`
+	Stops    int     `json:"stops"`
+	StopRate float64 `json:"stop_rate"`
+}
+
+// playReport summarizes what kids actually listened to, to guide content length
+type playReport struct {
+	Since    time.Time        `json:"since"`
+	Plays    int              `json:"plays"`
+	Stops    int              `json:"stops"`
+	Devices  int              `json:"devices"`
+	TopBirds []birdPlays      `json:"top_birds"`
+	Tracks   []trackListening `json:"tracks"`
+	Chapters []chapterDropOff `json:"chapters"`
+}
+
+// recordPlayEvent stores card.played and card.stopped webhook events for the listening report
+func (h *Handler) recordPlayEvent(ctx context.Context, event services.WebhookEvent, entry services.WebhookQueueEntry) error {
+	play := store.PlayEvent{
+		EventID:  event.Envelope().EventID,
+		CardID:   entry.CardID,
+		DeviceID: entry.DeviceID,
+	}
+	// The queue key stands in for a missing event ID, so retried entries aren't counted twice
+	if play.EventID == "" {
+		play.EventID = entry.Key
+	}
+	switch e := event.(type) {
+	case *services.CardPlayedEvent:
+		play.Type = store.PlayStarted
+		play.Chapter, play.Track = e.ChapterKey, e.TrackKey
+	case *services.CardStoppedEvent:
+		play.Type = store.PlayStopped
+		play.Chapter, play.Track = e.ChapterKey, e.TrackKey
+		play.ListenSeconds = e.Position
+	default:
+		return nil
+	}
+	if !entry.ReceivedAt.IsZero() {
+		play.OccurredAt = entry.ReceivedAt
+	}
+	play.BirdName = h.playedBird(entry.CardID, entry.Day)
+
+	if err := h.playEvents.RecordPlay(play); err != nil {
+		slog.ErrorContext(ctx, "[PLAYS] Failed to record play event", "card_id", play.CardID, "type", play.Type, "error", err)
+		return err
+	}
+	return nil
+}
+
+// playedBird returns the bird a card was playing on a day, if it's known
+func (h *Handler) playedBird(cardID string, day string) string {
+	if birdName := h.updateCache.GetBirdName(cardID, day, "webhook"); birdName != "" {
+		return birdName
+	}
+	card, exists := h.config.Cards.Get(cardID)
+	if !exists {
+		return ""
+	}
+	birdName, _ := h.dailyBird(cardRegion(card), day)
+	return birdName
+}
+
+// GetPlayReport summarizes recent playback: the most-played birds, average listen length per
+// track, and which chapters listeners stop in. ?days= sets the window (default 30) and ?limit=
+// the number of birds (default 10).
+func (h *Handler) GetPlayReport(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultPlayReportDays)))
+	if err != nil || days < 1 || days > maxPlayReportDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 180"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+		return
+	}
+
+	since := clock().UTC().AddDate(0, 0, -days)
+	events, err := h.playEvents.PlaysSince(since)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "[PLAYS] Failed to load play events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load play events"})
+		return
+	}
+
+	report := buildPlayReport(events, limit)
+	report.Since = since
+	c.JSON(http.StatusOK, report)
+}
+
+// buildPlayReport aggregates play events, keeping the limit most-played birds
+func buildPlayReport(events []store.PlayEvent, limit int) playReport {
+	type trackKey struct{ chapter, track string }
+
+	report := playReport{
+		TopBirds: []birdPlays{},
+		Tracks:   []trackListening{},
+		Chapters: []chapterDropOff{},
+	}
+	devices := make(map[string]bool)
+	birds := make(map[string]*birdPlays)
+	birdDevices := make(map[string]map[string]bool)
+	tracks := make(map[trackKey]*trackListening)
+	chapters := make(map[string]*chapterDropOff)
+
+	chapter := func(key string) *chapterDropOff {
+		if chapters[key] == nil {
+			chapters[key] = &chapterDropOff{Chapter: key}
+		}
+		return chapters[key]
+	}
+
+	for _, event := range events {
+		if event.DeviceID != "" {
+			devices[event.DeviceID] = true
+		}
+
+		switch event.Type {
+		case store.PlayStarted:
+			report.Plays++
+			if event.Chapter != "" {
+				chapter(event.Chapter).Starts++
+			}
+			if event.BirdName == "" {
+				continue
+			}
+			if birds[event.BirdName] == nil {
+				birds[event.BirdName] = &birdPlays{Bird: event.BirdName}
+				birdDevices[event.BirdName] = make(map[string]bool)
+			}
+			birds[event.BirdName].Plays++
+			if event.DeviceID != "" {
+				birdDevices[event.BirdName][event.DeviceID] = true
+			}
+
+		case store.PlayStopped:
+			report.Stops++
+			if event.Chapter == "" {
+				continue
+			}
+			chapter(event.Chapter).Stops++
+
+			key := trackKey{event.Chapter, event.Track}
+			if tracks[key] == nil {
+				tracks[key] = &trackListening{Chapter: event.Chapter, Track: event.Track}
+			}
+			// Summed here, divided below
+			tracks[key].Stops++
+			tracks[key].AverageListenSeconds += event.ListenSeconds
+		}
+	}
+	report.Devices = len(devices)
+
+	for name, bird := range birds {
+		bird.Devices = len(birdDevices[name])
+		report.TopBirds = append(report.TopBirds, *bird)
+	}
+	sort.Slice(report.TopBirds, func(i, j int) bool {
+		if report.TopBirds[i].Plays != report.TopBirds[j].Plays {
+			return report.TopBirds[i].Plays > report.TopBirds[j].Plays
+		}
+		return report.TopBirds[i].Bird < report.TopBirds[j].Bird
+	})
+	if len(report.TopBirds) > limit {
+		report.TopBirds = report.TopBirds[:limit]
+	}
+
+	for _, track := range tracks {
+		track.AverageListenSeconds /= float64(track.Stops)
+		report.Tracks = append(report.Tracks, *track)
+	}
+	sort.Slice(report.Tracks, func(i, j int) bool {
+		if report.Tracks[i].Chapter != report.Tracks[j].Chapter {
+			return report.Tracks[i].Chapter < report.Tracks[j].Chapter
+		}
+		return report.Tracks[i].Track < report.Tracks[j].Track
+	})
+
+	for _, dropOff := range chapters {
+		if report.Stops > 0 {
+			dropOff.StopRate = float64(dropOff.Stops) / float64(report.Stops)
+		}
+		report.Chapters = append(report.Chapters, *dropOff)
+	}
+	sort.Slice(report.Chapters, func(i, j int) bool {
+		return report.Chapters[i].Chapter < report.Chapters[j].Chapter
+	})
+	return report
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// primerBaseURL is where rendered family primers are played from, birds/_primers/{key}.mp3
+const primerBaseURL = narrationBaseURL + "/_primers"
+
+// skipClip is the half second of silence played for a chapter with nothing to say, so the player
+// moves straight on to the next one
+var skipClip = services.NewStreamAudio(services.SilentMP3(0.5))
+
+// streamSkip answers a chapter request with the silent skip clip
+func streamSkip(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "audio/mpeg", skipClip.Data)
+}
+
+// deviceIDFromRequest identifies the player making a streaming request: the device its track URLs
+// were generated for, or the card they belong to, which stays the same when the home's IP changes.
+// The client IP is the last resort, for URLs that carry neither.
+func deviceIDFromRequest(c *gin.Context) string {
+	if deviceID := c.Query("device"); deviceID != "" {
+		return deviceID
+	}
+	if deviceID := c.GetHeader("X-Yoto-Device-Id"); deviceID != "" {
+		return deviceID
+	}
+	if cardID := c.Param("card"); cardID != "" {
+		return "card_" + cardID
+	}
+	if cardID := c.Query("card"); cardID != "" {
+		return "card_" + cardID
+	}
+	return "ip_" + c.ClientIP()
+}
+
+// StreamPrimer plays the family "sound signature" primer for devices in their first week.
+// Established listeners get a short silent clip so the chapter is effectively skipped.
+func (h *Handler) StreamPrimer(c *gin.Context) {
+	sessionID := c.Query("session")
+	session := h.getOrCreateSession(c, sessionID)
+
+	birdName := session.BirdName
+	if birdName == "" {
+		selectedBird, err := h.getDailyBirdWithFallback(c, "primer")
+		if err != nil {
+			log.Printf("[STREAMING] primer: %v", err)
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		birdName = selectedBird
+		session.BirdName = birdName
+		putSession(session)
+	}
+
+	deviceID := deviceIDFromRequest(c)
+	primerURL, ok := h.primerURL(deviceID, birdName)
+	if !ok {
+		streamSkip(c)
+		return
+	}
+
+	log.Printf("[STREAMING] primer: Playing %s for new listener %s", primerURL, deviceID)
+	c.Redirect(http.StatusFound, primerURL)
+}
+
+// primerURL returns the rendered family primer for a device still in its first week. Primers the
+// card job hasn't rendered yet are skipped.
+func (h *Handler) primerURL(deviceID string, birdName string) (string, bool) {
+	primer, ok := h.primerService.PrimerForDevice(deviceID, birdName)
+	if !ok {
+		return "", false
+	}
+	primerURL := primerBaseURL + "/" + primer.Key + ".mp3"
+	return primerURL, narrationVariantExists(primerURL)
+}
+
+// renderFamilyPrimer renders the primer for the job bird's family in the background, so new
+// listeners hear it from the first play
+func (h *Handler) renderFamilyPrimer(ctx context.Context, step *services.CardJobStep, job services.CardJob) {
+	metadata, err := h.birdStorage.GetBirdMetadata(job.BirdName)
+	if err != nil {
+		return
+	}
+	primer, ok := services.GetFamilyPrimer(metadata.Family)
+	if !ok {
+		return
+	}
+
+	voiceID := h.narratorVoice("", services.VoiceRoleGuide, h.jobDay(job))
+	slog.DebugContext(ctx, "[PRIMER] Rendering family primer", "family", primer.Family, "key", primer.Key)
+	h.renderNarration(ctx, step, "_primers/"+primer.Key+".mp3", primer.Text, voiceID)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamQuiz plays the "Can you guess the bird?" round for a second bird seen near the listener.
+// Without a location, or when the quiz can't be rendered, the chapter plays the silent skip clip.
+func (h *Handler) StreamQuiz(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Query("session")
+	session := h.getOrCreateSession(c, sessionID)
+
+	birdName := session.BirdName
+	if birdName == "" {
+		selectedBird, err := h.getDailyBirdWithFallback(c, "quiz")
+		if err != nil {
+			slog.WarnContext(ctx, "[STREAMING] quiz: No bird for session", "error", err)
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		birdName = selectedBird
+		session.BirdName = birdName
+		putSession(session)
+	}
+
+	if session.Location == nil {
+		slog.InfoContext(ctx, "[STREAMING] quiz: No location for session, skipping quiz", "session", session.SessionID)
+		streamSkip(c)
+		return
+	}
+
+	voiceID := h.narratorVoice(session.VoiceID, services.VoiceRoleQuiz, locationLocalTime(session.Location))
+
+	quiz, err := h.quizGenerator.GenerateQuiz(ctx, birdName, session.Location.Latitude, session.Location.Longitude, voiceID)
+	if err != nil {
+		slog.WarnContext(ctx, "[STREAMING] quiz: Failed to generate quiz, skipping", "bird", birdName, "error", err)
+		streamSkip(c)
+		return
+	}
+
+	slog.InfoContext(ctx, "[STREAMING] quiz: Playing mystery bird", "bird", birdName, "mystery_bird", quiz.MysteryBird)
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "audio/mpeg", quiz.Audio)
+}
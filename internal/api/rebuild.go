@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Where a rebuild's bird came from
+const (
+	rebuildFromHistory  = "history"         // The bird recorded for the region and date
+	rebuildFromSchedule = "weekly_schedule" // The bird-of-the-week card's recorded bird for the week
+	rebuildReplayed     = "replayed"        // Nothing was recorded, so the selection was run again
+)
+
+// rebuildTrack is one regenerated track in a rebuild's response
+type rebuildTrack struct {
+	Track string `json:"track"`
+	File  string `json:"file,omitempty"`
+	Bytes int    `json:"bytes,omitempty"`
+	ETag  string `json:"etag,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// RebuildCard regenerates a card's content for a past date (?date=YYYY-MM-DD), to reproduce what
+// listeners heard that day. The bird comes from the recorded history, or the selection is replayed
+// when none was recorded; the guide script uses the same seed as that day's build. Every track is
+// regenerated into the asset store under REBUILD_PREFIX/{card}/{date}, so the files outlive the
+// instance that built them, and the response gives their locations. Nothing is published or
+// recorded.
+//
+// The listener's location isn't kept per day, so the rebuild uses ?lat=&lng= (and ?city=), the
+// device's stored location (?device=), or the card's default location. ?hour= sets the local hour
+// (9 by default; an hour after bedtime rebuilds the night variant), and the listener options the
+// streams take (voice, facts, nature, greeting) are passed through.
+func (h *Handler) RebuildCard(c *gin.Context) {
+	ctx := c.Request.Context()
+	card, exists := h.config.Cards.Get(c.Param("card"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown card"})
+		return
+	}
+
+	day, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	hour := 9
+	if value := c.Query("hour"); value != "" {
+		if hour, err = strconv.Atoi(value); err != nil || hour < 0 || hour > 23 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hour must be from 0 to 23"})
+			return
+		}
+	}
+
+	location, locationSource := h.rebuildLocation(c, card)
+	localNow := cardLocalTime(card, location)
+	localNow = time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, localNow.Location())
+	if localNow.After(clock()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must not be in the future"})
+		return
+	}
+	date := localNow.Format("2006-01-02")
+
+	mode := h.contentMode(card, localNow)
+	bird, source, err := h.replayBird(card, localNow, mode)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	night := mode == services.ContentModeNight
+	slog.InfoContext(ctx, "[REBUILD] Rebuilding card", "card_id", card.CardID, "date", date, "bird", bird.CommonName,
+		"source", source, "mode", mode)
+
+	assets := services.SharedAssetStore()
+	dir := path.Join(h.config.RebuildPrefix, card.CardID, date)
+
+	// The guide script the day's build would have generated
+	generator := h.guideGenerator(c, card, date)
+	var latitude, longitude float64
+	if location != nil {
+		latitude, longitude = location.Latitude, location.Longitude
+	}
+	transcript := h.guideFactGenerator(c, card, date).GenerateFactTranscript(ctx, bird, latitude, longitude)
+	if data, err := json.MarshalIndent(transcript, "", "  "); err == nil {
+		if err := assets.WriteFile(path.Join(dir, "transcript.json"), data); err != nil {
+			slog.WarnContext(ctx, "[REBUILD] Failed to write transcript", "dir", dir, "error", err)
+		}
+	}
+
+	segments := h.rebuildSegments(c, card, bird.CommonName, mode)
+	tracks := make([]rebuildTrack, 0, len(segments))
+	for i, segment := range segments {
+		result := rebuildTrack{Track: segment}
+		audio, err := h.cardTrackAudio(c, card, segment, bird.CommonName, location, localNow, night)
+		if err != nil {
+			slog.WarnContext(ctx, "[REBUILD] Failed to rebuild track", "card_id", card.CardID, "track", segment, "error", err)
+			result.Error = err.Error()
+			tracks = append(tracks, result)
+			continue
+		}
+
+		file := path.Join(dir, fmt.Sprintf("%02d_%s.mp3", i+1, segment))
+		if err := assets.WriteFile(file, audio.Data); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.File, result.Bytes, result.ETag = assets.Location(file), len(audio.Data), audio.ETag
+		}
+		tracks = append(tracks, result)
+	}
+
+	response := gin.H{
+		"card":           card.CardID,
+		"date":           date,
+		"local_time":     localNow.Format(time.RFC3339),
+		"mode":           mode,
+		"bird":           bird.CommonName,
+		"bird_source":    source,
+		"location_from":  locationSource,
+		"fact_generator": generator,
+		"transcript":     transcript,
+		"tracks":         tracks,
+		"output":         assets.Location(dir),
+	}
+	if location != nil {
+		response["location"] = location
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// rebuildLocation picks the location a rebuild generates for, and says where it came from
+func (h *Handler) rebuildLocation(c *gin.Context, card config.CardProfile) (*models.Location, string) {
+	latitude, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	longitude, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr == nil && lngErr == nil {
+		return &models.Location{Latitude: latitude, Longitude: longitude, City: c.Query("city")}, "request"
+	}
+	if record, ok := h.deviceRegistry.Get(deviceIDFromRequest(c)); ok && record.Location != nil {
+		return record.Location, "device"
+	}
+	if fallback, ok := h.defaultLocations.Resolve(card.CardID); ok {
+		return fallback, "card_default"
+	}
+	return nil, "none"
+}
+
+// replayBird returns the card's bird on localNow's date without recording anything: the recorded
+// bird when there is one, otherwise the bird the selection picks for that date
+func (h *Handler) replayBird(card config.CardProfile, localNow time.Time, mode string) (*models.Bird, string, error) {
+	region := cardRegion(card)
+	date := localNow.Format("2006-01-02")
+	if mode == services.ContentModeNight {
+		region += nightRegionSuffix
+	}
+
+	if h.birdOfWeekEnabled(card) && mode != services.ContentModeNight {
+		weekStart := services.WeekStart(localNow)
+		if name, ok := h.weeklySchedule.BirdForWeek(card.CardID, weekStart); ok {
+			if bird := h.availableBirds.GetBirdByName(name); bird != nil {
+				return bird, rebuildFromSchedule, nil
+			}
+		}
+		monday, err := time.Parse("2006-01-02", weekStart)
+		if err != nil {
+			return nil, "", err
+		}
+		if bird := h.rotationBirdForCard(card, monday); bird != nil {
+			return bird, rebuildReplayed, nil
+		}
+		return nil, "", fmt.Errorf("no bird available for region %s", cardRegion(card))
+	}
+
+	if name, ok := h.dailyBird(region, date); ok {
+		if bird := h.availableBirds.GetBirdByName(name); bird != nil {
+			return bird, rebuildFromHistory, nil
+		}
+	}
+	if mode == services.ContentModeNight {
+		if bird := h.availableBirds.GetNocturnalBird(); bird != nil && !h.overrides.IsBlocked(bird.CommonName) {
+			return bird, rebuildReplayed, nil
+		}
+		return h.replayBird(card, localNow, services.ContentModeDay)
+	}
+
+	bird, err := h.chooseDailyBird(card, localNow)
+	if err != nil {
+		return nil, "", err
+	}
+	return bird, rebuildReplayed, nil
+}
+
+// rebuildSegments lists the tracks the card plays, laid out as its update would lay them out
+func (h *Handler) rebuildSegments(c *gin.Context, card config.CardProfile, birdName string, mode string) []string {
+	contentManager := h.newContentManager(card)
+	if h.birdOfWeekEnabled(card) && mode != services.ContentModeNight {
+		contentManager.SetWeeklyFact(streamingWeeklyFactTitle)
+	}
+	if h.birdHeroEnabled(card) {
+		if status, threatened := h.birdHero.ThreatenedStatus(c.Request.Context(), birdName); threatened {
+			contentManager.SetConservationStatus(status)
+		}
+	}
+	return contentManager.Segments()
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// requestID tags each request with a correlation ID, taken from the caller's X-Request-ID when
+// it sends one, so every log entry for the request (and any work it queues) can be found together
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logging.RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = logging.NewRequestID()
+		}
+		c.Header(logging.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// structuredRequestLogger replaces gin's console access log with one JSON entry per request,
+// tagged with the Cloud Run trace so request logs group in Logs Explorer
+func structuredRequestLogger(cardID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		severity := "INFO"
+		if c.Writer.Status() >= 500 {
+			severity = "ERROR"
+		} else if c.Writer.Status() >= 400 {
+			severity = "WARNING"
+		}
+
+		logging.Write(logging.Entry{
+			Severity:  severity,
+			Message:   fmt.Sprintf("%s %s %d", c.Request.Method, c.Request.URL.Path, c.Writer.Status()),
+			Component: "HTTP",
+			CardID:    cardID,
+			RequestID: logging.RequestID(c.Request.Context()),
+			Trace:     logging.TraceName(c.GetHeader("X-Cloud-Trace-Context")),
+			HTTPRequest: &logging.HTTPRequest{
+				RequestMethod: c.Request.Method,
+				RequestURL:    c.Request.URL.String(),
+				Status:        c.Writer.Status(),
+				Latency:       fmt.Sprintf("%.3fs", time.Since(start).Seconds()),
+				RemoteIP:      c.ClientIP(),
+				UserAgent:     c.Request.UserAgent(),
+			},
+		})
+	}
+}
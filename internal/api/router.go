@@ -4,29 +4,98 @@ import (
 	"net/http"
 
 	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/logging"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRouter(cfg *config.Config) *gin.Engine {
+	return NewRouter(cfg, NewHandler(cfg))
+}
+
+// NewRouter wires routes to an existing handler (used by tools that need access to handler state)
+func NewRouter(cfg *config.Config, handler *Handler) *gin.Engine {
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
-	handler := NewHandler(cfg)
+	var router *gin.Engine
+	if logging.Enabled() {
+		router = gin.New()
+		router.Use(requestID(), structuredRequestLogger(cfg.YotoCardID), gin.Recovery())
+	} else {
+		router = gin.Default()
+		router.Use(requestID())
+	}
 
 	router.GET("/health", healthCheck)
+	router.GET("/healthz", handler.Healthz)
+	router.GET("/readyz", handler.Readyz)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Parent companion page for the bird a card is playing today
+	router.GET("/today/:card", handler.TodayPage)
 
 	v1 := router.Group("/api/v1")
 	{
-		v1.POST("/daily-update", handler.DailyUpdateHandler) // Scheduler trigger for global bird
+		v1.POST("/daily-update", handler.DailyUpdateHandler)   // Scheduler trigger for global bird
+		v1.POST("/cron/daily-update", handler.CronDailyUpdate) // Frequent cron trigger, updates cards at each listener's midnight
 		v1.POST("/yoto/token/refresh", handler.HandleTokenRefresh)
+		v1.POST("/yoto/webhook", handler.HandleYotoWebhook)
 
 		// Streaming endpoints for dynamic content
 		v1.GET("/stream/intro", handler.StreamIntro)
 		v1.GET("/stream/announcement", handler.StreamBirdAnnouncement)
+		v1.GET("/stream/primer", handler.StreamPrimer)
+		v1.GET("/stream/quiz", handler.StreamQuiz)
+		v1.GET("/stream/hotspots", handler.StreamHotspots)
+		v1.GET("/stream/bird_hero", handler.StreamBirdHero)
+		v1.GET("/stream/weekly_fact", handler.StreamWeeklyFact)
+		v1.GET("/stream/listen_count", handler.StreamListenCount)
 		v1.GET("/stream/description", handler.StreamDescription)
 		v1.GET("/stream/outro", handler.StreamOutro)
+
+		// Card-scoped streams resolve the bird per request, so track URLs stay the same every day
+		v1.GET("/stream/:card/:track", handler.StreamCardTrack)
+
+		// Script tooling
+		v1.POST("/scripts/estimate", handler.EstimateScript)
+
+		// Parent dashboard data (not played as audio)
+		dashboard := v1.Group("/dashboard")
+		{
+			dashboard.GET("/trivia", handler.GetTrivia)
+			dashboard.GET("/events", handler.StreamDashboardEvents)
+		}
+
+		// Admin tooling, guarded by the scheduler token
+		admin := v1.Group("/admin", handler.requireAdminToken)
+		{
+			admin.GET("/preview", handler.PreviewTranscript)
+			admin.POST("/transcript", handler.PreviewTranscript)
+			admin.GET("/catalog", handler.GetCatalog)
+			admin.GET("/devices", handler.ListDeviceProfiles)
+			admin.GET("/devices/registry", handler.ListRegisteredDevices)
+			admin.GET("/households", handler.GetHouseholdSections)
+			admin.POST("/devices/sync", handler.SyncDevices)
+			admin.GET("/devices/:device/profile", handler.GetDeviceProfile)
+			admin.PUT("/devices/:device/profile", handler.PutDeviceProfile)
+			admin.DELETE("/devices/:device/profile", handler.DeleteDeviceProfile)
+			admin.GET("/cards", handler.ListCards)
+			admin.POST("/cards/:card/refresh", handler.RefreshCard)
+			admin.POST("/cards/:card/rebuild", handler.RebuildCard)
+			admin.GET("/jobs", handler.ListCardJobs)
+			admin.GET("/quota", handler.GetTTSQuota)
+			admin.GET("/config", handler.GetConfig)
+			admin.GET("/plays", handler.GetPlayReport)
+			admin.GET("/pins", handler.ListPins)
+			admin.PUT("/pins/:region", handler.PinBird)
+			admin.DELETE("/pins/:region/:date", handler.UnpinBird)
+			admin.GET("/blocklist", handler.GetBlocklist)
+			admin.PUT("/blocklist/:species", handler.BlockSpecies)
+			admin.DELETE("/blocklist/:species", handler.UnblockSpecies)
+		}
 	}
 
 	return router
@@ -36,5 +105,6 @@ func healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
 		"service": "bird-song-explorer",
+		"ffmpeg":  services.GetFFmpegCapabilities(),
 	})
 }
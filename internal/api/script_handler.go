@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxEstimateScriptLength keeps the estimate endpoint from being used to hash arbitrary uploads
+const maxEstimateScriptLength = 50000
+
+type estimateScriptRequest struct {
+	Script string `json:"script"`
+}
+
+// EstimateScript returns word count, reading time per voice speed, readability grade,
+// and section breakdown for a narration script
+func (h *Handler) EstimateScript(c *gin.Context) {
+	var req estimateScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must be JSON with a \"script\" field"})
+		return
+	}
+
+	if strings.TrimSpace(req.Script) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "script is required"})
+		return
+	}
+	if len(req.Script) > maxEstimateScriptLength {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "script is too long"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.EstimateScript(req.Script))
+}
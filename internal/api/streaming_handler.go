@@ -5,14 +5,18 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/callen/bird-song-explorer/internal/config"
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
 type StreamingSession struct {
 	SessionID      string
+	CardID         string // Card the session was created for; empty for sessions started by a stream request
 	Location       *models.Location
 	BirdName       string
 	ScientificName string
@@ -21,28 +25,65 @@ type StreamingSession struct {
 	CreatedAt      time.Time
 }
 
-var sessionStore = make(map[string]*StreamingSession)
+var (
+	sessionStore = make(map[string]*StreamingSession)
+	sessionMu    sync.Mutex
+)
 
 func cleanupSessions() {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
 	for id, session := range sessionStore {
-		if time.Since(session.CreatedAt) > 15*time.Minute {
+		if clock().Sub(session.CreatedAt) > 15*time.Minute {
 			delete(sessionStore, id)
 		}
 	}
 }
 
+func getSession(sessionID string) (*StreamingSession, bool) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	session, exists := sessionStore[sessionID]
+	return session, exists
+}
+
+func putSession(session *StreamingSession) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	sessionStore[session.SessionID] = session
+}
+
+func deleteSession(sessionID string) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	delete(sessionStore, sessionID)
+}
+
+// SessionCount returns the number of live streaming sessions
+func SessionCount() int {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	return len(sessionStore)
+}
+
 // CreateSessionForBird creates a new session for a specific bird
 // This ensures the icon and narration match when the card is played
 func (h *Handler) CreateSessionForBird(cardID string, birdName string) string {
-	sessionID := fmt.Sprintf("%s_%d", cardID, time.Now().Unix())
+	sessionID := fmt.Sprintf("%s_%d", cardID, clock().Unix())
 
 	session := &StreamingSession{
 		SessionID: sessionID,
+		CardID:    cardID,
 		BirdName:  birdName,
-		CreatedAt: time.Now(),
+		CreatedAt: clock(),
 	}
 
-	sessionStore[sessionID] = session
+	putSession(session)
 	log.Printf("[SESSION] Created session %s for bird: %s", sessionID, birdName)
 
 	go cleanupSessions()
@@ -50,18 +91,27 @@ func (h *Handler) CreateSessionForBird(cardID string, birdName string) string {
 	return sessionID
 }
 
+// sessionCardID returns the card a session belongs to, or the default card when the
+// session wasn't created by a card update
+func (h *Handler) sessionCardID(session *StreamingSession) string {
+	if session.CardID != "" {
+		return session.CardID
+	}
+	return h.config.Cards.Default().CardID
+}
+
 // getOrCreateSession gets an existing session or creates a new one
 // Uses the session ID from query parameter to maintain state across tracks
 func (h *Handler) getOrCreateSession(c *gin.Context, sessionID string) *StreamingSession {
 	clientIP := c.ClientIP()
 
 	if sessionID != "" {
-		if existingSession, exists := sessionStore[sessionID]; exists {
-			if time.Since(existingSession.CreatedAt) > 15*time.Minute {
-				log.Printf("[STREAMING] Session %s expired (age: %v), creating new one", sessionID, time.Since(existingSession.CreatedAt))
-				delete(sessionStore, sessionID)
+		if existingSession, exists := getSession(sessionID); exists {
+			if clock().Sub(existingSession.CreatedAt) > 15*time.Minute {
+				log.Printf("[STREAMING] Session %s expired (age: %v), creating new one", sessionID, clock().Sub(existingSession.CreatedAt))
+				deleteSession(sessionID)
 			} else {
-				log.Printf("[STREAMING] Using existing session %s for bird: %s (age: %v)", sessionID, existingSession.BirdName, time.Since(existingSession.CreatedAt))
+				log.Printf("[STREAMING] Using existing session %s for bird: %s (age: %v)", sessionID, existingSession.BirdName, clock().Sub(existingSession.CreatedAt))
 				return existingSession
 			}
 		}
@@ -69,20 +119,25 @@ func (h *Handler) getOrCreateSession(c *gin.Context, sessionID string) *Streamin
 
 	sessionKey := sessionID
 	if sessionKey == "" {
-		sessionKey = fmt.Sprintf("ip_%s_%d", clientIP, time.Now().Unix())
+		sessionKey = fmt.Sprintf("ip_%s_%d", clientIP, clock().Unix())
 	}
 
 	newSession := &StreamingSession{
 		SessionID: sessionKey,
-		CreatedAt: time.Now(),
+		CreatedAt: clock(),
 	}
 
 	location, err := h.locationService.GetLocationFromIP(clientIP)
 	if err == nil && location != nil {
-		newSession.Location = location
+		newSession.Location = h.deviceRegistry.SmoothLocation(deviceIDFromRequest(c), location)
+	} else if fallback, ok := h.defaultLocations.Resolve(h.config.YotoCardID); ok {
+		log.Printf("[STREAMING] IP lookup failed for %s, using configured default location (%.2f, %.2f)", clientIP, fallback.Latitude, fallback.Longitude)
+		newSession.Location = fallback
+	} else {
+		log.Printf("[STREAMING] IP lookup failed for %s and no default location configured, using no-location mode", clientIP)
 	}
 
-	sessionStore[newSession.SessionID] = newSession
+	putSession(newSession)
 	go cleanupSessions()
 	return newSession
 }
@@ -90,7 +145,7 @@ func (h *Handler) getOrCreateSession(c *gin.Context, sessionID string) *Streamin
 // getDailyBirdWithFallback gets the bird from cache with timezone awareness
 // If cache fails, falls back to GetCyclingBird() and updates the card
 func (h *Handler) getDailyBirdWithFallback(c *gin.Context, context string) (string, error) {
-	now := time.Now().UTC()
+	now := clock().UTC()
 	var lookupDate string
 
 	// Timezone-aware cache lookup
@@ -106,7 +161,7 @@ func (h *Handler) getDailyBirdWithFallback(c *gin.Context, context string) (stri
 	}
 
 	// Try primary lookup
-	cachedBirdName, exists := h.updateCache.GetDailyGlobalBird(lookupDate)
+	cachedBirdName, exists := h.dailyGlobalBird(lookupDate)
 	if exists && cachedBirdName != "" {
 		log.Printf("[STREAMING] %s: ✅ Using cached daily bird: %s (date: %s)", context, cachedBirdName, lookupDate)
 		return cachedBirdName, nil
@@ -114,27 +169,28 @@ func (h *Handler) getDailyBirdWithFallback(c *gin.Context, context string) (stri
 
 	// Try yesterday as backup (in case cache failed)
 	yesterday := now.AddDate(0, 0, -1)
-	cachedBirdName, exists = h.updateCache.GetDailyGlobalBird(yesterday.Format("2006-01-02"))
+	cachedBirdName, exists = h.dailyGlobalBird(yesterday.Format("2006-01-02"))
 	if exists && cachedBirdName != "" {
 		log.Printf("[STREAMING] %s: ⚠️  Primary cache miss, using yesterday's bird: %s", context, cachedBirdName)
 		return cachedBirdName, nil
 	}
 
 	// Fallback: Get cycling bird AND update card with new icon
-	log.Printf("[STREAMING] %s: ❌ Cache failed, falling back to GetCyclingBird()", context)
-	bird := h.availableBirds.GetCyclingBird()
+	continent := h.fallbackContinent()
+	log.Printf("[STREAMING] %s: ❌ Cache failed, falling back to %s species pool", context, continent)
+	bird := h.availableBirds.GetBirdForContinent(continent)
 	if bird == nil {
 		return "", fmt.Errorf("no bird available")
 	}
 
 	// Update the card with the fallback bird's icon
-	cardID := h.config.YotoCardID
-	if cardID != "" {
+	card := h.config.Cards.Default()
+	if cardID := card.CardID; cardID != "" {
 		baseURL := fmt.Sprintf("https://%s", c.Request.Host)
 		sessionID := fmt.Sprintf("%s_%d", cardID, now.Unix())
 
 		log.Printf("[STREAMING] %s: 🔄 Updating card with fallback bird: %s", context, bird.CommonName)
-		contentManager := h.yotoClient.NewContentManager()
+		contentManager := h.newContentManager(card)
 		err := contentManager.UpdateCardWithStreamingTracks(cardID, bird.CommonName, baseURL, sessionID)
 		if err != nil {
 			log.Printf("[STREAMING] %s: ⚠️  Failed to update card: %v", context, err)
@@ -146,6 +202,16 @@ func (h *Handler) getDailyBirdWithFallback(c *gin.Context, context string) (stri
 	return bird.CommonName, nil
 }
 
+// fallbackContinent picks the species pool for cache misses from the configured default location.
+// With no defaults configured ("no location" mode) the global pool is used.
+func (h *Handler) fallbackContinent() string {
+	location, ok := h.defaultLocations.Resolve(h.config.YotoCardID)
+	if !ok {
+		return "global"
+	}
+	return services.ContinentForTimezone(GetTimezoneFromLocation(location.Latitude, location.Longitude).String())
+}
+
 func (h *Handler) StreamIntro(c *gin.Context) {
 	birdName := c.Query("bird")
 	sessionID := c.Query("session")
@@ -166,14 +232,74 @@ func (h *Handler) StreamIntro(c *gin.Context) {
 		session.BirdName = selectedBird
 	}
 
-	birdDir := strings.ToLower(strings.ReplaceAll(session.BirdName, " ", "_"))
-	gcsURL := fmt.Sprintf("https://storage.googleapis.com/bird-song-explorer-audio/birds/%s/narration/intro.mp3", birdDir)
+	// The intro starts every play, so it's where first-seen tracking happens
+	h.deviceRegistry.Touch(deviceIDFromRequest(c))
+	h.pipelineEvents.Publish(services.EventCardPlayed, h.sessionCardID(session), session.BirdName, "")
 
-	sessionStore[session.SessionID] = session
+	if voiceID := c.Query("voice"); voiceID != "" {
+		session.VoiceID = voiceID
+	}
+
+	night := c.Query("mode") == services.ContentModeNight
+	gcsURL := h.introURL(c, session.BirdName, night, locationLocalTime(session.Location))
+
+	putSession(session)
 	c.Header("X-Session-ID", session.SessionID)
+
+	// A greeting is spliced in here, so the intro is served rather than redirected to
+	if c.Query("greeting") == "true" && !night {
+		location, _ := h.locationService.GetLocationFromIP(c.ClientIP())
+		localNow := locationLocalTime(location)
+		audio, err := h.introAudio(c, gcsURL, location, localNow)
+		if err != nil {
+			log.Printf("[STREAMING] intro: %v", err)
+			c.Redirect(http.StatusFound, gcsURL)
+			return
+		}
+		serveStreamAudio(c, "intro", audio, localNow)
+		return
+	}
 	c.Redirect(http.StatusFound, gcsURL)
 }
 
+// introURL picks the intro narration: the calmer night intro in night mode, a holiday's or seasonal
+// theme's intro once it has been rendered, the voice-only intro for devices that turned off nature
+// sounds, otherwise the standard intro. The theme is the one running on now's date.
+func (h *Handler) introURL(c *gin.Context, birdName string, night bool, now time.Time) string {
+	// The night intro's softer ambience wins over any theme, once it has been rendered
+	if night {
+		if nightURL := narrationURL(birdName, "intro_night"); narrationVariantExists(nightURL) {
+			return nightURL
+		}
+	}
+
+	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	gcsURL := fmt.Sprintf("%s/%s/narration/intro.mp3", narrationBaseURL, birdDir)
+
+	// Devices that turned off nature-mixed intros get the voice-only render once it exists
+	if c.Query("nature") == "false" {
+		plainURL := fmt.Sprintf("%s/%s/narration/intro_plain.mp3", narrationBaseURL, birdDir)
+		if narrationVariantExists(plainURL) {
+			gcsURL = plainURL
+		}
+	}
+
+	// Bilingual mode introduces the bird by both its names once that intro has been rendered
+	if h.localizedNames.Enabled() {
+		if bilingualURL := narrationURL(birdName, h.bilingualClip("intro")); narrationVariantExists(bilingualURL) {
+			gcsURL = bilingualURL
+		}
+	}
+
+	// Holidays and seasonal themes swap in a themed intro once its audio has been rendered
+	if theme, ok := h.themes.ThemeOn(now); ok && narrationVariantExists(theme.IntroURL(now, birdDir)) {
+		log.Printf("[STREAMING] intro: Using %s themed intro", theme.Name)
+		gcsURL = theme.IntroURL(now, birdDir)
+	}
+
+	return gcsURL
+}
+
 func (h *Handler) StreamBirdAnnouncement(c *gin.Context) {
 	sessionID := c.Query("session")
 	session := h.getOrCreateSession(c, sessionID)
@@ -189,13 +315,21 @@ func (h *Handler) StreamBirdAnnouncement(c *gin.Context) {
 		}
 		birdName = selectedBird
 		session.BirdName = birdName
-		sessionStore[session.SessionID] = session
+		putSession(session)
 	}
 
-	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
-	gcsURL := fmt.Sprintf("https://storage.googleapis.com/bird-song-explorer-audio/birds/%s/narration/announcement.mp3", birdDir)
+	c.Redirect(http.StatusFound, h.announcementURL(birdName))
+}
 
-	c.Redirect(http.StatusFound, gcsURL)
+// announcementURL picks the announcement narration: in bilingual mode the one naming the bird in
+// both languages once it has been rendered, otherwise the standard announcement
+func (h *Handler) announcementURL(birdName string) string {
+	if h.localizedNames.Enabled() {
+		if bilingualURL := narrationURL(birdName, h.bilingualClip("announcement")); narrationVariantExists(bilingualURL) {
+			return bilingualURL
+		}
+	}
+	return narrationURL(birdName, "announcement")
 }
 
 func (h *Handler) StreamDescription(c *gin.Context) {
@@ -213,13 +347,25 @@ func (h *Handler) StreamDescription(c *gin.Context) {
 		}
 		birdName = selectedBird
 		session.BirdName = birdName
-		sessionStore[session.SessionID] = session
+		putSession(session)
 	}
 
-	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
-	gcsURL := fmt.Sprintf("https://storage.googleapis.com/bird-song-explorer-audio/birds/%s/narration/description.mp3", birdDir)
+	// A device profile's guide preference overrides the card's experiment arm, and the arm's
+	// rendered guide plays once the card job has rendered it
+	cardID := h.sessionCardID(session)
+	card, registered := h.config.Cards.Get(cardID)
+	if !registered {
+		card = config.CardProfile{CardID: cardID}
+	}
+	date := clock().UTC().Format("2006-01-02")
+	variant := h.guideVariant(c, card, date)
+	generator := variant
+	if generator == "" {
+		generator, _ = h.experimentGuideGenerator(card, date)
+	}
+	h.factExperiment.RecordGuideStarted(experimentSessionKey(c, session), generator)
 
-	c.Redirect(http.StatusFound, gcsURL)
+	c.Redirect(http.StatusFound, h.descriptionURL(c, birdName, variant))
 }
 
 func (h *Handler) StreamOutro(c *gin.Context) {
@@ -237,11 +383,55 @@ func (h *Handler) StreamOutro(c *gin.Context) {
 		}
 		birdName = selectedBird
 		session.BirdName = birdName
-		sessionStore[session.SessionID] = session
+		putSession(session)
+	}
+
+	h.factExperiment.RecordCompleted(experimentSessionKey(c, session))
+
+	// Night mode plays the goodnight outro instead of any theme's
+	if c.Query("mode") == services.ContentModeNight {
+		c.Redirect(http.StatusFound, outroURL(birdName, true))
+		return
 	}
 
 	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
 	gcsURL := fmt.Sprintf("https://storage.googleapis.com/bird-song-explorer-audio/birds/%s/narration/outro.mp3", birdDir)
 
+	// Seasonal themes swap in a themed outro once its audio has been rendered
+	now := clock().UTC()
+	if theme, ok := h.themes.ThemeOn(now); ok && narrationVariantExists(theme.OutroURL(now, birdDir)) {
+		log.Printf("[STREAMING] outro: Using %s themed outro", theme.Name)
+		gcsURL = theme.OutroURL(now, birdDir)
+	}
+
+	// Rotating outros are narrated live, with the listen-and-count answer read first when enabled
+	if h.config.EnableOutroRotation {
+		localNow := locationLocalTime(session.Location)
+		if outro, err := h.rotatingOutroAudio(c.Request.Context(), h.sessionCardID(session), birdName, session.VoiceID, localNow); err == nil {
+			if h.config.EnableListenAndCount {
+				outro = h.withCountingAnswer(c.Request.Context(), birdName, session.VoiceID, localNow, outro)
+			}
+			c.Header("Cache-Control", "no-cache")
+			c.Data(http.StatusOK, "audio/mpeg", outro.Data)
+			return
+		}
+	}
+
+	// The listen-and-count answer is read before the outro, so the audio is served rather than redirected
+	if h.config.EnableListenAndCount {
+		if outro, err := h.streamCache.Fetch(c.Request.Context(), gcsURL); err == nil {
+			outro = h.withCountingAnswer(c.Request.Context(), birdName, session.VoiceID, locationLocalTime(session.Location), outro)
+			c.Header("Cache-Control", "no-cache")
+			c.Data(http.StatusOK, "audio/mpeg", outro.Data)
+			return
+		}
+	}
+
 	c.Redirect(http.StatusFound, gcsURL)
 }
+
+// experimentSessionKey identifies one listen-through; the card session is shared by every
+// player, so the device is included to count each listener separately
+func experimentSessionKey(c *gin.Context, session *StreamingSession) string {
+	return session.SessionID + "_" + deviceIDFromRequest(c)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/today.html
+var pageTemplates embed.FS
+
+var todayTemplate = template.Must(template.ParseFS(pageTemplates, "templates/today.html"))
+
+// sentencesPerParagraph groups the facts script into short paragraphs for reading
+const sentencesPerParagraph = 3
+
+// todayPage is what the parent page shows about the card's bird
+type todayPage struct {
+	Date     string
+	Bird     *models.Bird
+	SongURL  string
+	Script   []string
+	EBirdURL string
+}
+
+// TodayPage serves a small page for parents showing the bird a card is playing today: its
+// photo, the song, the script the Explorer's Guide read, and links to learn more
+func (h *Handler) TodayPage(c *gin.Context) {
+	ctx := c.Request.Context()
+	card, exists := h.config.Cards.Get(c.Param("card"))
+	if !exists {
+		c.String(http.StatusNotFound, "Unknown card")
+		return
+	}
+
+	localNow := cardLocalTime(card, h.deviceLocation(c, card, false))
+	bird, err := h.selectDailyBird(card, localNow)
+	if err != nil {
+		slog.WarnContext(ctx, "[TODAY] No bird for card", "card_id", card.CardID, "error", err)
+		c.String(http.StatusServiceUnavailable, "Today's bird hasn't been chosen yet. Please check back in a few minutes.")
+		return
+	}
+
+	page := todayPage{
+		Date:    localNow.Format("Monday, January 2"),
+		Bird:    h.photoFetcher.WithPhoto(ctx, bird),
+		SongURL: narrationURL(bird.CommonName, "announcement"),
+		Script:  h.todayScript(ctx, card, bird, localNow),
+	}
+	if species, ok := ebird.SharedTaxonomy("").ByCommonName(bird.CommonName); ok {
+		page.EBirdURL = "https://ebird.org/species/" + species.SpeciesCode
+	}
+
+	var body strings.Builder
+	if err := todayTemplate.Execute(&body, page); err != nil {
+		slog.ErrorContext(ctx, "[TODAY] Failed to render page", "card_id", card.CardID, "bird", bird.CommonName, "error", err)
+		c.String(http.StatusInternalServerError, "Failed to render page")
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(body.String()))
+}
+
+// todayScript returns the bird's stored guide script as paragraphs, generating the script with the
+// card's seed for the day when none has been stored
+func (h *Handler) todayScript(ctx context.Context, card config.CardProfile, bird *models.Bird, localNow time.Time) []string {
+	transcript, err := h.birdStorage.GetTranscript(bird.CommonName)
+	if err != nil {
+		rng := randx.Daily(localNow.Format("2006-01-02"), card.CardID)
+		generator := services.NewFactGeneratorForLocale(h.config.FactGenerator, h.config.EBirdAPIKey, h.config.ContentLocale, rng)
+		transcript = generator.GenerateFactTranscript(ctx, bird, bird.Latitude, bird.Longitude)
+	}
+	if transcript == nil {
+		return nil
+	}
+
+	var paragraphs []string
+	for i := 0; i < len(transcript.Sentences); i += sentencesPerParagraph {
+		end := min(i+sentencesPerParagraph, len(transcript.Sentences))
+		sentences := make([]string, 0, end-i)
+		for _, sentence := range transcript.Sentences[i:end] {
+			sentences = append(sentences, sentence.Text)
+		}
+		paragraphs = append(paragraphs, strings.Join(sentences, " "))
+	}
+	if len(paragraphs) == 0 && transcript.Script != "" {
+		paragraphs = []string{transcript.Script}
+	}
+	return paragraphs
+}
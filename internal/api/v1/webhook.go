@@ -0,0 +1,69 @@
+// Package v1 defines the versioned JSON bodies the API returns, so the parent page and downstream
+// tooling can rely on their shape. Fields are only ever added within a version.
+package v1
+
+// Version is the schema version every response in this package carries
+const Version = "v1"
+
+// Webhook response statuses
+const (
+	WebhookQueued    = "queued"    // The event was queued; the card refreshes shortly
+	WebhookDuplicate = "duplicate" // The event was already queued
+	WebhookCached    = "cached"    // The card was already refreshed today, so nothing was queued
+	WebhookDeferred  = "deferred"  // The event was queued but updates are saturated; the card keeps its content until Retry-After
+	WebhookIgnored   = "ignored"   // Nothing handles the event type
+	WebhookError     = "error"
+)
+
+// WebhookResponse is the body of every Yoto webhook response. Bird, Tracks, and ContentHash are
+// set once the day's bird is known for the card, and Tracks and ContentHash once it has been
+// published.
+type WebhookResponse struct {
+	Version     string     `json:"version"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	EventType   string     `json:"event_type,omitempty"`
+	CardID      string     `json:"card_id,omitempty"`
+	DeviceID    string     `json:"device_id,omitempty"`
+	Bird        *Bird      `json:"bird,omitempty"`
+	Tracks      []Track    `json:"tracks,omitempty"`
+	LocalTime   *LocalTime `json:"local_time,omitempty"`
+	NatureSound string     `json:"nature_sound,omitempty"` // Ambience under the intro ("morning_birds", "night", ...)
+	Voice       string     `json:"voice,omitempty"`        // ElevenLabs voice ID narrating the guide
+	ContentHash string     `json:"content_hash,omitempty"` // SHA-256 of the content posted to the card
+}
+
+// Bird is the bird a card plays
+type Bird struct {
+	CommonName     string `json:"common_name"`
+	ScientificName string `json:"scientific_name,omitempty"`
+	Family         string `json:"family,omitempty"`
+	ImageURL       string `json:"image_url,omitempty"`
+	WikipediaURL   string `json:"wikipedia_url,omitempty"`
+}
+
+// Track is one track of the content published to a card
+type Track struct {
+	Chapter string `json:"chapter"`
+	Title   string `json:"title,omitempty"`
+	URL     string `json:"url"`
+}
+
+// LocalTime is the listener's time of day, which picks the content mode, greeting, and ambience
+type LocalTime struct {
+	Time        string `json:"time"` // RFC 3339 with the listener's offset
+	Timezone    string `json:"timezone"`
+	Hour        int    `json:"hour"`
+	TimePeriod  string `json:"time_period"`  // "early_morning", "afternoon", "night", ...
+	ContentMode string `json:"content_mode"` // "day" or "night"
+}
+
+// NewWebhookResponse creates a response with the given status
+func NewWebhookResponse(status string) *WebhookResponse {
+	return &WebhookResponse{Version: Version, Status: status}
+}
+
+// NewWebhookError creates an error response
+func NewWebhookError(message string) *WebhookResponse {
+	return &WebhookResponse{Version: Version, Status: WebhookError, Error: message}
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/callen/bird-song-explorer/internal/api/v1"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/logging"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/internal/store"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	webhookRequests = metrics.NewCounterVec("bird_explorer_webhook_requests_total",
+		"Yoto webhook deliveries by response status", "status")
+	webhookJobs = metrics.NewCounterVec("bird_explorer_webhook_jobs_total",
+		"Queued webhook events processed, by result (ok, busy, deferred, aborted, or error)", "result")
+)
+
+// errUpdateQueueBusy tells the webhook consumer to retry an entry later
+var errUpdateQueueBusy = errors.New("update queue saturated")
+
+// errCardUpdatesDeferred tells the webhook consumer to retry an entry once Yoto is usable again
+var errCardUpdatesDeferred = errors.New("card updates deferred while Yoto is unavailable")
+
+// registerWebhookHandlers routes webhook event types to the features that use them
+func (h *Handler) registerWebhookHandlers() {
+	h.webhookEvents.On(services.WebhookCardPlayed, h.handleCardPlayed)
+	h.webhookEvents.On(services.WebhookCardPlayed, h.recordPlayEvent)
+	h.webhookEvents.On(services.WebhookCardStopped, h.recordPlayEvent)
+}
+
+// HandleYotoWebhook decodes the event, stores it in the durable webhook queue, and acknowledges
+// it straight away. The consumer worker routes it to the handlers registered for its type, so
+// bursts of Yoto retries during cold starts are neither lost nor handled twice. Event types
+// nothing handles are acknowledged without queueing.
+func (h *Handler) HandleYotoWebhook(c *gin.Context) {
+	defer func() { webhookRequests.Inc(strconv.Itoa(c.Writer.Status())) }()
+	ctx := c.Request.Context()
+
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.NewWebhookError("Invalid webhook payload"))
+		return
+	}
+	decoded, err := services.DecodeWebhookEvent(payload)
+	if err != nil {
+		slog.WarnContext(ctx, "[WEBHOOK] Rejected payload", "error", err)
+		c.JSON(http.StatusBadRequest, v1.NewWebhookError("Invalid webhook payload"))
+		return
+	}
+	event := decoded.Envelope()
+
+	handlerCount := h.webhookEvents.HandlerCount(event.EventType)
+	if handlerCount == 0 {
+		slog.DebugContext(ctx, "[WEBHOOK] No handler for event type", "event_type", event.EventType)
+		c.JSON(http.StatusOK, h.webhookResponse(v1.WebhookIgnored, event, config.CardProfile{}, ""))
+		return
+	}
+
+	// Card events without a card ID are for the default card; other events need not name one
+	cardID := event.CardID
+	cardEvent := isCardWebhookEvent(decoded)
+	if cardID == "" && cardEvent {
+		cardID = h.config.Cards.Default().CardID
+		if cardID == "" {
+			c.JSON(http.StatusBadRequest, v1.NewWebhookError("cardId is required"))
+			return
+		}
+	}
+	var card config.CardProfile
+	if cardID != "" {
+		registered, exists := h.config.Cards.Get(cardID)
+		if !exists {
+			slog.WarnContext(ctx, "[WEBHOOK] Ignoring event for unregistered card", "card_id", cardID)
+			c.JSON(http.StatusNotFound, v1.NewWebhookError("Unknown card"))
+			return
+		}
+		card = registered
+	}
+
+	date := clock().UTC().Format("2006-01-02")
+	// When the card refresh is the only thing listening, a card already refreshed today (in this
+	// content mode) has nothing left to do
+	if _, played := decoded.(*services.CardPlayedEvent); played && handlerCount == 1 {
+		cacheContext := updateCacheContext(h.contentMode(card, h.webhookDeviceTime(card, event.DeviceID)))
+		if h.updateCache.HasBeenUpdated(cardID, date, cacheContext) {
+			c.JSON(http.StatusOK, h.webhookResponse(v1.WebhookCached, event, card, date))
+			return
+		}
+	}
+
+	// Without an event ID, deliveries for the same card, device and day are treated as one event
+	eventID := event.EventID
+	if eventID == "" {
+		eventID = fmt.Sprintf("%s:%s", event.EventType, cardID)
+	}
+
+	queued, err := h.webhookQueue.Enqueue(services.WebhookQueueEntry{
+		Key:       services.WebhookDedupeKey(eventID, event.DeviceID, date),
+		EventID:   event.EventID,
+		EventType: event.EventType,
+		CardID:    cardID,
+		DeviceID:  event.DeviceID,
+		Day:       date,
+		BaseURL:   h.webhookBaseURL(c),
+		RequestID: logging.RequestID(ctx),
+		Payload:   payload,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "[WEBHOOK] Failed to queue event", "card_id", cardID, "error", err)
+		c.Header("Retry-After", strconv.Itoa(h.config.WebhookRetryAfterSeconds))
+		c.JSON(http.StatusServiceUnavailable, v1.NewWebhookError("Failed to queue event"))
+		return
+	}
+
+	if !queued {
+		c.JSON(http.StatusOK, h.webhookResponse(v1.WebhookDuplicate, event, card, date))
+		return
+	}
+	// With the update queue or TTS budget saturated the card plays its current content for now,
+	// and the queued event is retried once there's room
+	if h.updateQueue.Saturated() {
+		slog.InfoContext(ctx, "[WEBHOOK] Queued event behind saturated updates", "card_id", cardID, "device_id", event.DeviceID)
+		c.Header("Retry-After", strconv.Itoa(int(h.updateQueue.RetryAfter().Seconds())))
+		c.JSON(http.StatusAccepted, h.webhookResponse(v1.WebhookDeferred, event, card, date))
+		return
+	}
+	slog.InfoContext(ctx, "[WEBHOOK] Queued event", "card_id", cardID, "device_id", event.DeviceID, "event_type", event.EventType)
+	c.JSON(http.StatusAccepted, h.webhookResponse(v1.WebhookQueued, event, card, date))
+}
+
+// isCardWebhookEvent reports whether the event is about a card, and so belongs to one
+func isCardWebhookEvent(event services.WebhookEvent) bool {
+	switch event.(type) {
+	case *services.CardPlayedEvent, *services.CardStoppedEvent:
+		return true
+	}
+	return false
+}
+
+// processWebhookEntry is the webhook queue consumer. It decodes the stored payload and dispatches
+// it, logging under the request ID of the delivery that queued it; any handler error leaves the
+// entry for a retry.
+func (h *Handler) processWebhookEntry(entry services.WebhookQueueEntry) error {
+	requestID := entry.RequestID
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	ctx := logging.WithRequestID(context.Background(), requestID)
+
+	// Entries queued before payloads were stored only carry the envelope fields
+	var event services.WebhookEvent = &services.CardPlayedEvent{WebhookEnvelope: services.WebhookEnvelope{
+		EventID:   entry.EventID,
+		EventType: entry.EventType,
+		CardID:    entry.CardID,
+		DeviceID:  entry.DeviceID,
+	}}
+	if len(entry.Payload) > 0 {
+		decoded, err := services.DecodeWebhookEvent(entry.Payload)
+		if err != nil {
+			slog.ErrorContext(ctx, "[WEBHOOK] Dropping undecodable queued event", "key", entry.Key, "error", err)
+			return nil
+		}
+		event = decoded
+	}
+	return h.webhookEvents.Dispatch(ctx, event, entry)
+}
+
+// handleCardPlayed runs the card refresh in an update queue slot and waits for the result, so a
+// failure, a saturated queue, or a spent TTS budget leaves the entry for a retry
+func (h *Handler) handleCardPlayed(ctx context.Context, event services.WebhookEvent, entry services.WebhookQueueEntry) error {
+	if policy := h.dependencies.Policy(); policy.DeferCardUpdates {
+		slog.WarnContext(ctx, "[WEBHOOK] Deferring card update", "card_id", entry.CardID, "reasons", policy.Reasons)
+		h.pipelineEvents.Publish(services.EventJobDeferred, entry.CardID, "", "Yoto unavailable, webhook event will be retried")
+		webhookJobs.Inc("deferred")
+		return errCardUpdatesDeferred
+	}
+
+	result := make(chan error, 1)
+	job := func() {
+		result <- h.refreshCardFromWebhook(ctx, entry.CardID, entry.DeviceID, entry.Day, entry.BaseURL)
+	}
+
+	if !h.updateQueue.TryRun(entry.Key, job) {
+		h.pipelineEvents.Publish(services.EventJobDeferred, entry.CardID, "", "Update queue busy or TTS budget spent, webhook event will be retried")
+		webhookJobs.Inc("busy")
+		return errUpdateQueueBusy
+	}
+
+	err := <-result
+	if errors.Is(err, services.ErrCardJobAbort) {
+		// Retrying the event can't fix a missing card or a request Yoto rejects, so it's dropped
+		slog.WarnContext(ctx, "[WEBHOOK] Dropping event, card update can't succeed", "card_id", entry.CardID, "error", err)
+		webhookJobs.Inc("aborted")
+		return nil
+	}
+	if err != nil {
+		webhookJobs.Inc("error")
+	} else {
+		webhookJobs.Inc("ok")
+	}
+	return err
+}
+
+// refreshCardFromWebhook updates the card with today's bird and records it in the update cache.
+// The playing device's profile, if it has one, picks the region, guide, voice, and intro style.
+// After bedtime where the device is, cards with night mode get the night variant and night bird.
+func (h *Handler) refreshCardFromWebhook(ctx context.Context, cardID string, deviceID string, date string, baseURL string) error {
+	// Cards removed from the registry after the event was queued are dropped
+	card, registered := h.config.Cards.Get(cardID)
+	if !registered {
+		slog.WarnContext(ctx, "[WEBHOOK] Card is no longer registered, skipping update", "card_id", cardID)
+		return nil
+	}
+
+	// Streaming cards generate every track on request, so there's nothing to refresh
+	if h.streamingCardEnabled(card) {
+		return nil
+	}
+
+	localNow := h.webhookDeviceTime(card, deviceID)
+	mode := h.contentMode(card, localNow)
+	if h.updateCache.HasBeenUpdated(cardID, date, updateCacheContext(mode)) {
+		return nil
+	}
+
+	profile, hasProfile := h.deviceProfiles.Get(deviceID)
+	if hasProfile && profile.Region != "" {
+		slog.InfoContext(ctx, "[WEBHOOK] Device prefers a regional species pool", "device_id", deviceID, "region", profile.Region)
+		card.Region = profile.Region
+	}
+	if card.Region == services.RegionAuto {
+		card.Region = h.autoRegion(deviceID)
+		slog.InfoContext(ctx, "[WEBHOOK] Using the listener's region pack", "device_id", deviceID, "region", card.Region)
+	}
+
+	// Every device hears the region's recorded bird; if the scheduler hasn't run yet, the first
+	// webhook of the day records the rotation bird for everyone else
+	region := cardRegion(card)
+	birdName, exists := h.dailyBird(region, date)
+	if mode == services.ContentModeNight {
+		nightBird, err := h.nightBirdForCard(card, localNow)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "[WEBHOOK] After bedtime, using night mode", "card_id", cardID, "device_id", deviceID, "bird", nightBird.CommonName)
+		birdName, exists = nightBird.CommonName, true
+	} else if h.birdOfWeekEnabled(card) {
+		weeklyBird, err := h.weeklyBirdForCard(card, localNow)
+		if err != nil {
+			return err
+		}
+		birdName, exists = weeklyBird.CommonName, true
+	}
+	if !exists {
+		bird := h.rotationBirdForCard(card, clock().UTC())
+		if guest := h.specialGuestForCard(card, clock().UTC()); guest != nil {
+			slog.InfoContext(ctx, "[WEBHOOK] Featuring a special guest", "card_id", cardID, "bird", guest.CommonName)
+			bird = guest
+		}
+		if bird == nil {
+			return fmt.Errorf("no bird available for %s", cardID)
+		}
+		birdName = h.recordDailyBird(region, date, bird.CommonName)
+	}
+
+	h.pipelineEvents.Publish(services.EventJobStarted, cardID, birdName, "Webhook update started")
+
+	if hasProfile {
+		if options := listenerOptions(profile); options.FactGenerator != "" {
+			h.factExperiment.RecordAssignment(cardID, date, options.FactGenerator)
+		}
+	}
+
+	job := services.CardJob{
+		CardID:   cardID,
+		Day:      date,
+		Trigger:  services.CardJobWebhook,
+		BirdName: birdName,
+		DeviceID: deviceID,
+		BaseURL:  baseURL,
+		Mode:     mode,
+	}
+	if card.Region != store.RegionGlobal {
+		job.Region = card.Region
+	}
+	return h.runCardJob(ctx, job)
+}
+
+func (h *Handler) webhookBaseURL(c *gin.Context) string {
+	if baseURL := h.config.ServiceURL; baseURL != "" {
+		return baseURL
+	}
+	if h.config.Environment == "development" {
+		return fmt.Sprintf("http://%s", c.Request.Host)
+	}
+	return fmt.Sprintf("https://%s", c.Request.Host)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/api/v1"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+)
+
+// webhookResponse describes what the event means for the card: the listener's local time and the
+// voice and ambience their intro uses, plus the bird, tracks, and content hash once the card has
+// been refreshed today
+func (h *Handler) webhookResponse(status string, event services.WebhookEnvelope, card config.CardProfile, date string) *v1.WebhookResponse {
+	response := v1.NewWebhookResponse(status)
+	response.EventType = event.EventType
+	response.CardID = card.CardID
+	response.DeviceID = event.DeviceID
+	if card.CardID == "" {
+		return response
+	}
+
+	localNow := h.webhookDeviceTime(card, event.DeviceID)
+	mode := h.contentMode(card, localNow)
+	timezone := localNow.Location().String()
+	response.LocalTime = &v1.LocalTime{
+		Time:        localNow.Format(time.RFC3339),
+		Timezone:    timezone,
+		Hour:        localNow.Hour(),
+		TimePeriod:  services.TimePeriod(localNow.Hour()),
+		ContentMode: mode,
+	}
+
+	profile, _ := h.deviceProfiles.Get(event.DeviceID)
+	options := listenerOptions(profile)
+	response.Voice = h.narratorVoice(options.VoiceID, services.VoiceRoleIntro, localNow)
+	switch {
+	case mode == services.ContentModeNight:
+		response.NatureSound = "night"
+	case options.NatureIntros == nil || *options.NatureIntros:
+		response.NatureSound = services.NewUserTimeHelper().GetNatureSoundForUserTime(timezone)
+	}
+
+	entry, updated := h.updateCache.GetEntry(card.CardID, date, updateCacheContext(mode))
+	if !updated {
+		return response
+	}
+	response.Bird = &v1.Bird{CommonName: entry.BirdName}
+	if bird := h.availableBirds.GetBirdByName(entry.BirdName); bird != nil {
+		response.Bird.ScientificName = bird.ScientificName
+		response.Bird.Family = bird.Family
+		response.Bird.ImageURL = bird.PhotoURL
+		response.Bird.WikipediaURL = bird.WikipediaURL
+	}
+	for _, track := range entry.Tracks {
+		response.Tracks = append(response.Tracks, v1.Track{Chapter: track.Chapter, Title: track.Title, URL: track.URL})
+	}
+	response.ContentHash = entry.ContentHash
+	return response
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+)
+
+// DefaultCardTitle is the playlist title used when a card profile doesn't set one
+const DefaultCardTitle = "Bird Song Explorer"
+
+// CardProfile describes one MYO card managed by the service
+type CardProfile struct {
+	CardID string `json:"card_id"`
+	Title  string `json:"title,omitempty"`  // Playlist title, e.g. "Bird Song Explorer - Europe"
+	Region string `json:"region,omitempty"` // Species pool ("north_america", "uk", "japan", ...); empty or "global" for the shared daily bird, "auto" for the listener's country
+
+	// IANA timezone for the cron rollout; empty derives it from the card's default location
+	Timezone string `json:"timezone,omitempty"`
+
+	// Hour (UTC) the scheduler's daily update applies to this card; nil updates on every scheduler run
+	UpdateHourUTC *int `json:"update_hour_utc,omitempty"`
+
+	// Content profile; unset fields fall back to the deployment-wide settings
+	FactGenerator   string `json:"fact_generator,omitempty"`
+	IncludePrimer   *bool  `json:"include_primer,omitempty"`
+	IncludeQuiz     *bool  `json:"include_quiz,omitempty"`
+	IncludeHotspots *bool  `json:"include_hotspots,omitempty"`
+	BirdCover       *bool  `json:"bird_cover,omitempty"`
+	CoverRotation   *bool  `json:"cover_rotation,omitempty"`
+	NightMode       *bool  `json:"night_mode,omitempty"`
+	BirdHero        *bool  `json:"bird_hero,omitempty"`
+	BirdOfWeek      *bool  `json:"bird_of_week,omitempty"`
+	ListenAndCount  *bool  `json:"listen_and_count,omitempty"`
+	SingleTrack     *bool  `json:"single_track,omitempty"`
+	OutroRotation   *bool  `json:"outro_rotation,omitempty"`
+	Streaming       *bool  `json:"streaming,omitempty"`
+	GuideCalls      *bool  `json:"guide_calls,omitempty"`
+	Weather         *bool  `json:"weather,omitempty"`
+	AutoAdvance     string `json:"autoadvance,omitempty"` // "next", "none", or "repeat"
+
+	// Static cover image URL; set, every update uses it instead of bird photos and rotating artwork
+	CoverImage string `json:"cover_image,omitempty"`
+
+	// Ordered chapter segments (intro, announcement, primer, description, weekly_fact, listen_count, quiz, hotspots, bird_hero, outro);
+	// set, it replaces the standard layout and the include options
+	Chapters []string `json:"chapters,omitempty"`
+}
+
+// IsGlobal reports whether the card plays the shared global daily bird. "auto" cards do too until
+// a play resolves the listener's region pack.
+func (p CardProfile) IsGlobal() bool {
+	return p.Region == "" || p.Region == "global" || p.Region == "auto"
+}
+
+// DisplayTitle returns the card's playlist title
+func (p CardProfile) DisplayTitle() string {
+	if p.Title == "" {
+		return DefaultCardTitle
+	}
+	return p.Title
+}
+
+// DueAt reports whether the daily update should run for this card at the given UTC hour
+func (p CardProfile) DueAt(hour int) bool {
+	return p.UpdateHourUTC == nil || *p.UpdateHourUTC == hour
+}
+
+// CardRegistry holds every card the service manages. The first card is the default, used
+// where a request doesn't identify its card.
+type CardRegistry struct {
+	cards []CardProfile
+	byID  map[string]int
+}
+
+// NewCardRegistry creates a registry from profiles, or a single global card for defaultCardID
+// when there are none
+func NewCardRegistry(cards []CardProfile, defaultCardID string) *CardRegistry {
+	if len(cards) == 0 && defaultCardID != "" {
+		cards = []CardProfile{{CardID: defaultCardID}}
+	}
+
+	registry := &CardRegistry{byID: make(map[string]int)}
+	for _, card := range cards {
+		if card.CardID == "" {
+			continue
+		}
+		if _, exists := registry.byID[card.CardID]; exists {
+			log.Printf("[CARD_REGISTRY] Duplicate card %s ignored", card.CardID)
+			continue
+		}
+		registry.byID[card.CardID] = len(registry.cards)
+		registry.cards = append(registry.cards, card)
+	}
+	return registry
+}
+
+// LoadCardRegistry reads card profiles from a JSON array at path, falling back to YOTO_CARD_ID
+func LoadCardRegistry(path string, defaultCardID string) *CardRegistry {
+	if path == "" {
+		return NewCardRegistry(nil, defaultCardID)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("[CARD_REGISTRY] Failed to read %s, using YOTO_CARD_ID only: %v", path, err)
+		return NewCardRegistry(nil, defaultCardID)
+	}
+
+	var cards []CardProfile
+	if err := json.Unmarshal(data, &cards); err != nil {
+		log.Printf("[CARD_REGISTRY] Failed to parse %s, using YOTO_CARD_ID only: %v", path, err)
+		return NewCardRegistry(nil, defaultCardID)
+	}
+
+	registry := NewCardRegistry(cards, defaultCardID)
+	log.Printf("[CARD_REGISTRY] Loaded %d cards from %s", len(registry.cards), path)
+	return registry
+}
+
+// Cards returns every registered card
+func (r *CardRegistry) Cards() []CardProfile {
+	return r.cards
+}
+
+// Get returns the profile for a card
+func (r *CardRegistry) Get(cardID string) (CardProfile, bool) {
+	index, exists := r.byID[cardID]
+	if !exists {
+		return CardProfile{}, false
+	}
+	return r.cards[index], true
+}
+
+// Default returns the first registered card, or an empty profile when none are configured
+func (r *CardRegistry) Default() CardProfile {
+	if len(r.cards) == 0 {
+		return CardProfile{}
+	}
+	return r.cards[0]
+}
+
+// DueAt returns the cards whose daily update runs at the given UTC hour
+func (r *CardRegistry) DueAt(hour int) []CardProfile {
+	var due []CardProfile
+	for _, card := range r.cards {
+		if card.DueAt(hour) {
+			due = append(due, card)
+		}
+	}
+	return due
+}
@@ -2,53 +2,360 @@ package config
 
 import (
 	"log"
-	"os"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port               string
-	Environment        string
-	BaseURL            string
-	DatabaseURL        string
-	YotoClientID       string
-	YotoAccessToken    string
-	YotoRefreshToken   string
-	YotoCardID         string
-	YotoAPIBaseURL     string
-	EBirdAPIKey        string
-	XenoCantoAPIKey    string
-	SchedulerToken     string
+	Port               string `env:"PORT" default:"8080"`
+	Environment        string `env:"ENV" default:"development"`
+	BaseURL            string `env:"BASE_URL" required:"production"`
+	DatabaseURL        string `env:"DATABASE_URL" secret:"true"`
+	YotoClientID       string `env:"YOTO_CLIENT_ID" required:"production"`
+	YotoAccessToken    string `env:"YOTO_ACCESS_TOKEN" secret:"true"`
+	YotoRefreshToken   string `env:"YOTO_REFRESH_TOKEN" secret:"true"`
+	YotoCardID         string `env:"YOTO_CARD_ID"`
+	YotoAPIBaseURL     string `env:"YOTO_API_BASE_URL" default:"https://api.yotoplay.com"`
+	EBirdAPIKey        string `env:"EBIRD_API_KEY" secret:"true"`
+	XenoCantoAPIKey    string `env:"XENOCANTO_API_KEY" secret:"true"`
+	SchedulerToken     string `env:"SCHEDULER_TOKEN" required:"production" secret:"true"`
+	CronSecret         string `env:"CRON_SECRET" secret:"true"` // Shared secret for the per-timezone cron endpoint (defaults to SCHEDULER_TOKEN)
 	CacheTTLHours      int
 	BirdOfDayResetHour int
+
+	// Fallback coordinates when location lookups fail ("lat,lon" and "cardID=lat,lon;...")
+	// Leave both empty for "no location" mode, which picks from a continent-level pool
+	DefaultLocation      string `env:"DEFAULT_LOCATION"`
+	CardDefaultLocations string `env:"CARD_DEFAULT_LOCATIONS"`
+
+	// Local MaxMind GeoLite2-City .mmdb for IP lookups, reloaded on SIGHUP; the remote lookup API
+	// is the fallback, and the only provider when this is empty
+	GeoLite2Path string `env:"GEOLITE2_DB_PATH"`
+
+	// Adds a family "sound signature" primer chapter before the guide for new listeners
+	EnableFamilyPrimer bool `env:"ENABLE_FAMILY_PRIMER"`
+
+	// Adds a "Can you guess the bird?" chapter after the guide, narrated with ElevenLabs
+	EnableBirdQuiz   bool   `env:"ENABLE_BIRD_QUIZ"`
+	ElevenLabsAPIKey string `env:"ELEVENLABS_API_KEY" secret:"true"`
+	NarratorVoiceID  string `env:"ELEVENLABS_VOICE_ID"`
+
+	// Ends guides with when tomorrow's dawn chorus starts at the listener's location
+	EnableDawnChorus bool `env:"ENABLE_DAWN_CHORUS"`
+
+	// How long the Explorer's Guide is planned to read for; lower-priority sections are left out to fit
+	GuideTargetSeconds float64 `env:"GUIDE_TARGET_SECONDS" default:"120"`
+
+	// Script moderation: a JSON list of rules added to the built-in ones, and the Perspective API
+	// key and score (0-1) over which it flags a sentence, for checking what the rules let through
+	ModerationRulesPath  string  `env:"MODERATION_RULES_PATH"`
+	PerspectiveAPIKey    string  `env:"PERSPECTIVE_API_KEY" secret:"true"`
+	PerspectiveThreshold float64 `env:"PERSPECTIVE_THRESHOLD" default:"0.7"`
+
+	// ElevenLabs character budgets (0 for unlimited) and how many renders may run at once
+	ElevenLabsDailyCharBudget   int `env:"ELEVENLABS_DAILY_CHAR_BUDGET" default:"0"`
+	ElevenLabsMonthlyCharBudget int `env:"ELEVENLABS_MONTHLY_CHAR_BUDGET" default:"0"`
+	ElevenLabsMaxConcurrent     int `env:"ELEVENLABS_MAX_CONCURRENT" default:"2"`
+
+	// Adds a "Where can you see it?" chapter naming nearby parks and refuges from eBird hotspots
+	EnableHotspotChapter bool `env:"ENABLE_HOTSPOT_CHAPTER"`
+
+	// Adds a "Be a Bird Hero!" chapter for birds iNaturalist lists as vulnerable, endangered, or
+	// critically endangered
+	EnableBirdHero bool `env:"ENABLE_BIRD_HERO" default:"true"`
+
+	// Keep one bird on cards for a whole week, adding a fact chapter with a different theme each
+	// day (Monday song, Tuesday nesting, ...) that is the only chapter updated after Monday
+	EnableBirdOfWeek bool `env:"ENABLE_BIRD_OF_WEEK"`
+
+	// About once a week, region pack cards feature a "special guest": a bird from their pool that
+	// the pack's eBird region has rarely reported lately, with the guide saying why it's special
+	EnableSpecialGuests bool `env:"ENABLE_SPECIAL_GUESTS" default:"true"`
+
+	// Adds a "Listen and count!" chapter after the guide asking children to count the bird's songs
+	// in a clip, with the answer read at the start of the outro
+	EnableListenAndCount bool `env:"ENABLE_LISTEN_AND_COUNT"`
+
+	// Narrate the Explorer's Guide live instead of playing its pre-rendered narration, splicing
+	// five seconds of the bird's recording in where the script asks children to listen to its call
+	EnableGuideCalls bool `env:"ENABLE_GUIDE_CALLS"`
+
+	// Open guides narrated live (see EnableGuideCalls) and rebuilt with a line about the weather
+	// at the listener's location, e.g. a rainy day being a great one to listen from the window
+	EnableWeather bool `env:"ENABLE_WEATHER"`
+
+	// Where the weather comes from: "open-meteo" (no key needed) or "none", and an Open-Meteo
+	// server to use instead of the public one
+	WeatherProvider string `env:"WEATHER_PROVIDER" default:"open-meteo"`
+	OpenMeteoURL    string `env:"OPEN_METEO_URL"`
+
+	// Publish cards as one continuous track: the chapters are stitched together with crossfades
+	// and uploaded as a single chapter instead of streaming one chapter each
+	EnableSingleTrack bool `env:"ENABLE_SINGLE_TRACK"`
+
+	// Narrate outros live from the joke, teaser, wisdom, challenge, and fun fact banks, rotating
+	// each card through them so it doesn't hear an outro again until it has heard the rest
+	EnableOutroRotation bool `env:"ENABLE_OUTRO_ROTATION"`
+
+	// Pre-cache the best recording of each bird likely over the next week, daily at this UTC hour
+	EnableRecordingWarmer bool `env:"ENABLE_RECORDING_WARMER"`
+	RecordingWarmHour     int  `env:"RECORDING_WARM_HOUR" default:"3"`
+
+	// Minutes between syncs of the account's players from the Yoto API into the device registry
+	// (0 disables the sync, leaving devices to be learned from plays)
+	DeviceSyncMinutes int `env:"DEVICE_SYNC_MINUTES" default:"60"`
+
+	// Point card tracks at /stream/{cardID}/{track}, which picks the bird for each device's local day
+	EnableDynamicStreams bool `env:"ENABLE_DYNAMIC_STREAMS"`
+
+	// Streaming cards are published once with a layout that doesn't change with the bird: every
+	// track is generated on request by the card-scoped endpoints, so daily updates only post
+	// content when the layout changed, and plays don't refresh the card
+	EnableStreamingCards bool `env:"ENABLE_STREAMING_CARDS"`
+
+	// Card playback behavior: what the player does when a chapter ends ("next", "none", or "repeat";
+	// empty leaves the player default) and how many seconds it remembers where a listener stopped
+	// (0 leaves the player default)
+	YotoAutoAdvance          string `env:"YOTO_AUTOADVANCE"`
+	YotoResumeTimeoutSeconds int    `env:"YOTO_RESUME_TIMEOUT_SECONDS"`
+
+	// English spelling variant for card titles: "us", "uk", or empty to keep API spellings
+	TitleEnglishVariant string `env:"TITLE_ENGLISH_VARIANT"`
+
+	// Split households sharing one card: "deviceID=lat,lon;deviceID2=lat,lon"
+	HouseholdDevices string `env:"HOUSEHOLD_DEVICES"`
+
+	// Webhook back-pressure: concurrent card updates allowed and the base retry delay for queued webhook events
+	MaxConcurrentUpdates     int `env:"MAX_CONCURRENT_UPDATES" default:"2"`
+	WebhookRetryAfterSeconds int `env:"WEBHOOK_RETRY_AFTER_SECONDS" default:"30"`
+
+	// Where accepted webhooks wait for processing when no bird store driver is set. The file is per
+	// instance; on Cloud Run configure the SQL store so queued webhooks survive scale-down.
+	WebhookQueuePath string `env:"WEBHOOK_QUEUE_PATH" default:"data/webhook_queue.json"`
+
+	// Deadlines for the stages of a card update: looking up the bird's cover photo and conservation
+	// status, then uploading and publishing the card's content. A stage that overruns is cancelled
+	// and the job is left queued for a retry.
+	CardLookupTimeoutSeconds  int `env:"CARD_LOOKUP_TIMEOUT_SECONDS" default:"20"`
+	CardPublishTimeoutSeconds int `env:"CARD_PUBLISH_TIMEOUT_SECONDS" default:"180"`
+
+	// Yoto transcode polling for uploaded audio. In async mode a slow transcode leaves the card job
+	// queued and it resumes once Yoto finishes, rather than holding the request open.
+	YotoTranscodePollMillis     int  `env:"YOTO_TRANSCODE_POLL_MS" default:"500"`
+	YotoTranscodeMaxWaitSeconds int  `env:"YOTO_TRANSCODE_MAX_WAIT_SECONDS" default:"15"`
+	YotoTranscodeAsync          bool `env:"YOTO_TRANSCODE_ASYNC"`
+
+	// Narration language for generated scripts, intros, and outros ("en", "fr", "de", "es") and the
+	// ElevenLabs voice for each locale ("fr=voiceID;de=voiceID")
+	ContentLocale string `env:"CONTENT_LOCALE" default:"en"`
+	LocaleVoices  string `env:"LOCALE_VOICES"`
+
+	// Voices for individual track roles, rotating daily ("quiz=voiceA,voiceB;fr:hotspots=voiceC")
+	VoiceCasts string `env:"VOICE_CASTS"`
+
+	// Second language for bilingual mode ("es", "fr", ...); empty disables it
+	BilingualLocale string `env:"BILINGUAL_LOCALE"`
+
+	// Fact generator for the guide ("basic", "enhanced", or "location") and the share of cards
+	// bucketed into "enhanced" for the generator experiment (0 disables the experiment)
+	FactGenerator         string `env:"BIRD_FACT_GENERATOR" default:"basic"`
+	FactExperimentPercent int    `env:"FACT_EXPERIMENT_ENHANCED_PERCENT" default:"0"`
+
+	// Where the experiment's assignments and plays are kept when no bird store driver is set (the
+	// SQL store holds them otherwise)
+	FactExperimentPath string `env:"FACT_EXPERIMENT_PATH" default:"data/fact_experiment.json"`
+
+	// Holiday calendar locale ("en-US", "en-GB", or "none") and optional JSON calendar override;
+	// holidays run as one-day themes
+	HolidayLocale       string `env:"HOLIDAY_LOCALE" default:"en-US"`
+	HolidayCalendarPath string `env:"HOLIDAY_CALENDAR_PATH"`
+
+	// Seasonal theme packs: a JSON list of themes, "" for the built-in packs, or "none"
+	ThemesPath string `env:"THEMES_PATH"`
+
+	// Animated song-bar icon for the Explorer's Guide track
+	EnableSongVisualizer bool `env:"ENABLE_SONG_VISUALIZER" default:"true"`
+
+	// Pixel-art icon generated from a photo for species without an icon asset
+	EnableGeneratedIcons bool `env:"ENABLE_GENERATED_ICONS" default:"true"`
+
+	// Replace the card cover with a photo of the day's bird; PhotoLicenses lists the iNaturalist
+	// licenses a photo may have ("cc0,cc-by", empty for the built-in list)
+	EnableBirdCover bool   `env:"ENABLE_BIRD_COVER"`
+	PhotoLicenses   string `env:"PHOTO_LICENSES"`
+
+	// Rotating card covers: a JSON list of artwork URLs for months or seasons, empty to keep covers
+	CoverArtworkPath string `env:"COVER_ARTWORK_PATH"`
+
+	// Calmer night variant (softer intro, goodnight outro, nocturnal bird) between BedtimeHour and
+	// WakeHour in the listener's local time
+	EnableNightMode bool `env:"ENABLE_NIGHT_MODE"`
+	BedtimeHour     int  `env:"BEDTIME_HOUR" default:"19"`
+	WakeHour        int  `env:"WAKE_HOUR" default:"6"`
+
+	// Chapter layout for every card as comma-separated segments ("intro,announcement,description,outro");
+	// empty uses the standard layout with the include options above
+	CardTemplate string `env:"CARD_TEMPLATE"`
+
+	// Cut the noisy lead-in and tail off cached field recordings and keep between the minimum and
+	// maximum seconds around the bird's loudest activity
+	EnableSongTrimming bool `env:"ENABLE_SONG_TRIMMING" default:"true"`
+	SongClipMinSeconds int  `env:"SONG_CLIP_MIN_SECONDS" default:"45"`
+	SongClipMaxSeconds int  `env:"SONG_CLIP_MAX_SECONDS" default:"90"`
+
+	// Loudness-normalize uploaded tracks (ffmpeg loudnorm) to this integrated loudness in LUFS
+	EnableAudioNormalization bool `env:"ENABLE_AUDIO_NORMALIZATION" default:"true"`
+	LoudnessTargetLUFS       int  `env:"LOUDNESS_TARGET_LUFS" default:"-23"`
+
+	// Where rotated Yoto tokens are persisted: "file", "secret-manager", "memory", or empty for env vars only
+	YotoTokenStore string `env:"YOTO_TOKEN_STORE"`
+	YotoTokenFile  string `env:"YOTO_TOKEN_FILE" default:"data/yoto_tokens.json"`
+
+	// Bird-of-day store: a database/sql driver name and DSN (defaults to DATABASE_URL, with the
+	// "pgx" Postgres driver), or a JSON file when neither is set
+	BirdStoreDriver string `env:"BIRD_STORE_DRIVER"`
+	BirdStoreDSN    string `env:"BIRD_STORE_DSN" secret:"true"`
+	BirdStorePath   string `env:"BIRD_STORE_PATH" default:"data/bird_of_day.json"`
+
+	// Abandoned card updates are alerted to a Slack incoming webhook and/or by email. Failures with
+	// the same cause within the window are one alert, and a cause isn't alerted on again until the
+	// cooldown has passed.
+	AlertSlackWebhookURL string `env:"ALERT_SLACK_WEBHOOK_URL" secret:"true"`
+	AlertEmailTo         string `env:"ALERT_EMAIL_TO"` // Comma-separated recipients
+	AlertEmailFrom       string `env:"ALERT_EMAIL_FROM"`
+	SMTPHost             string `env:"SMTP_HOST"`
+	SMTPPort             int    `env:"SMTP_PORT" default:"587"`
+	SMTPUsername         string `env:"SMTP_USERNAME"`
+	SMTPPassword         string `env:"SMTP_PASSWORD" secret:"true"`
+	AlertWindowSeconds   int    `env:"ALERT_WINDOW_SECONDS" default:"300"`
+	AlertCooldownMinutes int    `env:"ALERT_COOLDOWN_MINUTES" default:"60"`
+
+	// Asset store prefix POST /admin/cards/{id}/rebuild writes a past date's regenerated tracks under
+	RebuildPrefix string `env:"REBUILD_PREFIX" default:"rebuilds"`
+
+	// Where playback events are kept when no bird store driver is set (the SQL store holds them otherwise)
+	PlayEventsPath string `env:"PLAY_EVENTS_PATH" default:"data/play_events.json"`
+
+	// Public URL of this service for the track and webhook URLs it hands out; empty uses the
+	// request's host
+	ServiceURL string `env:"SERVICE_URL"`
+
+	// Where per-instance state is kept
+	CardJobsPath        string `env:"CARD_JOBS_PATH" default:"data/card_jobs.json"`
+	DeviceRegistryPath  string `env:"DEVICE_REGISTRY_PATH" default:"data/device_registry.json"`
+	DeviceProfilesPath  string `env:"DEVICE_PROFILES_PATH" default:"data/device_profiles.json"`
+	ElevenLabsQuotaPath string `env:"ELEVENLABS_QUOTA_PATH" default:"data/elevenlabs_quota.json"`
+	RolloutStatePath    string `env:"ROLLOUT_STATE_PATH" default:"data/rollout_state.json"`
+	WeeklySchedulePath  string `env:"WEEKLY_SCHEDULE_PATH" default:"data/weekly_schedule.json"`
+	OutroHistoryPath    string `env:"OUTRO_HISTORY_PATH" default:"data/outro_history.json"`
+	BirdOverridesPath   string `env:"BIRD_OVERRIDES_PATH" default:"data/bird_overrides.json"`
+	IconMappingsPath    string `env:"ICON_MAPPINGS_PATH" default:"data/icon_mappings.json"`
+	EBirdTaxonomyPath   string `env:"EBIRD_TAXONOMY_PATH" default:"data/ebird_taxonomy.json"`
+
+	// Script transcripts are kept alongside each bird's narration unless TranscriptDir is set
+	TranscriptDir string `env:"TRANSCRIPT_DIR"`
+
+	// Pre-rendered narration, and a JSON list of pronunciations added to the built-in ones
+	PrerecordedTTSDir  string `env:"PRERECORDED_TTS_DIR" default:"prerecorded_tts"`
+	PronunciationsPath string `env:"PRONUNCIATIONS_PATH"`
+
+	// Days of location evidence kept per device, and how many of them a new region must lead
+	// before the device's bird pool switches to it
+	LocationSmoothingWindowDays int `env:"LOCATION_SMOOTHING_WINDOW_DAYS" default:"7"`
+	LocationSmoothingSwitchDays int `env:"LOCATION_SMOOTHING_SWITCH_DAYS" default:"3"`
+
+	// Sound effects and ambience: "gcs" reads them from AssetStorePrefix in AssetStoreBucket ("/"
+	// for the bucket root), anything else from files under AssetDir
+	AssetStoreBackend string `env:"ASSET_STORE_BACKEND" default:"local"`
+	AssetStoreBucket  string `env:"ASSET_STORE_BUCKET" default:"bird-song-explorer-audio"`
+	AssetStorePrefix  string `env:"ASSET_STORE_PREFIX" default:"assets/"`
+	AssetDir          string `env:"ASSET_DIR" default:"assets"`
+
+	// Rendered ElevenLabs audio, keyed by text and voice: "gcs" keeps it in TTSCacheBucket, "disk"
+	// in files under TTSCacheDir
+	TTSCacheBackend string `env:"TTS_CACHE_BACKEND" default:"disk"`
+	TTSCacheBucket  string `env:"TTS_CACHE_BUCKET" default:"bird-song-explorer-audio"`
+	TTSCacheDir     string `env:"TTS_CACHE_DIR" default:"data/tts_cache"`
+
+	// Narration variants are rendered to the narration bucket, or to files under NarrationDir when
+	// it is set (for local runs)
+	NarrationDir string `env:"NARRATION_DIR"`
+
+	// Streamed tracks kept in memory for repeat plays
+	StreamCacheMB int `env:"STREAM_CACHE_MB" default:"64"`
+
+	// Grade level Explorer's Guide facts are simplified to
+	GuideReadingGrade float64 `env:"GUIDE_READING_GRADE" default:"6"`
+
+	// Play pre-recorded outros rather than narrating them live
+	UseStaticOutros bool `env:"USE_STATIC_OUTROS" default:"true"`
+
+	// Reverse geocoding for location intros: "nominatim" (OpenStreetMap, or the server at
+	// NominatimURL), "opencage" (needs OpenCageAPIKey), or "none"
+	Geocoder       string `env:"GEOCODER" default:"nominatim"`
+	OpenCageAPIKey string `env:"OPENCAGE_API_KEY" secret:"true"`
+	NominatimURL   string `env:"NOMINATIM_URL"`
+
+	// Pinned static ffmpeg build, downloaded into FFmpegInstallDir (default under the temp dir)
+	// when the image has no ffmpeg and FFmpegAutoDownload is on
+	FFmpegInstallDir    string `env:"FFMPEG_INSTALL_DIR"`
+	FFmpegStaticURL     string `env:"FFMPEG_STATIC_URL"`
+	FFmpegStaticSHA256  string `env:"FFMPEG_STATIC_SHA256"`
+	FFprobeStaticSHA256 string `env:"FFPROBE_STATIC_SHA256"`
+	FFmpegAutoDownload  bool   `env:"FFMPEG_AUTO_DOWNLOAD"`
+
+	// Check cached recordings really are the bird (birdsong songs -verify) with the BirdNET server
+	// at BirdNetAPIURL, or with a spectral check for birdsong when it's empty
+	BirdNetAPIURL   string `env:"BIRDNET_API_URL"`
+	VerifyBirdSongs bool   `env:"VERIFY_BIRD_SONGS"`
+
+	// Secret Manager project for YOTO_TOKEN_STORE=secret-manager; with AutoUpdateSecrets, rotated
+	// tokens are also written there when no token store is set
+	GCPSecretsProject string `env:"GCP_PROJECT"`
+	AutoUpdateSecrets bool   `env:"AUTO_UPDATE_SECRETS"`
+
+	// Cards managed by this deployment, loaded from CARD_REGISTRY_PATH (a JSON array of card
+	// profiles); without it YOTO_CARD_ID is the only card
+	Cards *CardRegistry
+
+	// "json" for one-line structured logs (Cloud Logging); anything else keeps console output
+	LogFormat    string `env:"LOG_FORMAT" default:"text"`
+	GCPProjectID string `env:"GOOGLE_CLOUD_PROJECT"`
+
+	// Problems found while reading the environment, reported by Validate
+	loadErrors []string
 }
 
+// Load reads the configuration from the environment (and .env). Problems such as unparseable
+// numbers are collected rather than fatal; call Validate before serving.
 func Load() *Config {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	return &Config{
-		Port:               getEnv("PORT", "8080"),
-		Environment:        getEnv("ENV", "development"),
-		BaseURL:            getEnv("BASE_URL", ""),
-		DatabaseURL:        getEnv("DATABASE_URL", ""),
-		YotoClientID:       getEnv("YOTO_CLIENT_ID", ""),
-		YotoAccessToken:    getEnv("YOTO_ACCESS_TOKEN", ""),
-		YotoRefreshToken:   getEnv("YOTO_REFRESH_TOKEN", ""),
-		YotoCardID:         getEnv("YOTO_CARD_ID", ""),
-		YotoAPIBaseURL:     getEnv("YOTO_API_BASE_URL", "https://api.yotoplay.com"),
-		EBirdAPIKey:        getEnv("EBIRD_API_KEY", ""),
-		XenoCantoAPIKey:    getEnv("XENOCANTO_API_KEY", ""),
-		SchedulerToken:     getEnv("SCHEDULER_TOKEN", ""),
+	cfg := &Config{
 		CacheTTLHours:      24,
 		BirdOfDayResetHour: 6,
 	}
+	cfg.loadErrors = loadEnv(cfg)
+
+	// Settings that default to another setting
+	if cfg.CronSecret == "" {
+		cfg.CronSecret = cfg.SchedulerToken
+	}
+	if cfg.BirdStoreDSN == "" {
+		cfg.BirdStoreDSN = cfg.DatabaseURL
+	}
+	if cfg.BirdStoreDriver == "" && cfg.BirdStoreDSN != "" {
+		cfg.BirdStoreDriver = "pgx"
+	}
+
+	cfg.Cards = LoadCardRegistry(getEnv("CARD_REGISTRY_PATH", ""), cfg.YotoCardID)
+	return cfg
 }
 
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value, _ := lookupEnv(key); value != "" {
 		return value
 	}
 	return defaultValue
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Config fields are read from the environment variable in their env tag, falling back to the
+// default tag. A required tag of "true" or "production" (ENV=production only) makes Validate
+// fail when the setting is empty, and secret fields are masked by Redacted.
+
+// redactedValue replaces secrets that are set in Redacted
+const redactedValue = "[redacted]"
+
+// lookupEnv returns the setting from the environment or, when KEY is unset and KEY_FILE names a
+// file, that file's contents (for mounted secrets). The error is for an unreadable secret file.
+func lookupEnv(key string) (string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE=%s: can't read the secret file (%v); mount the file or set %s directly", key, path, err, key)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// loadEnv fills every tagged field of cfg and returns the settings it couldn't parse
+func loadEnv(cfg *Config) []string {
+	var problems []string
+	value := reflect.ValueOf(cfg).Elem()
+	fields := value.Type()
+
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		raw, err := lookupEnv(key)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		if raw == "" {
+			raw = field.Tag.Get("default")
+		}
+		if raw == "" {
+			continue
+		}
+
+		target := value.Field(i)
+		switch target.Kind() {
+		case reflect.String:
+			target.SetString(raw)
+		case reflect.Int:
+			parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s=%q: must be a whole number", key, raw))
+				parsed, _ = strconv.Atoi(field.Tag.Get("default"))
+			}
+			target.SetInt(int64(parsed))
+		case reflect.Float64:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s=%q: must be a number", key, raw))
+				parsed, _ = strconv.ParseFloat(field.Tag.Get("default"), 64)
+			}
+			target.SetFloat(parsed)
+		case reflect.Bool:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s=%q: must be true or false", key, raw))
+				parsed = field.Tag.Get("default") == "true"
+			}
+			target.SetBool(parsed)
+		}
+	}
+
+	return problems
+}
+
+// Validate reports every configuration problem at once, each naming the setting to fix: values
+// that didn't parse, required settings left empty, and values out of range.
+func (c *Config) Validate() error {
+	problems := append([]string(nil), c.loadErrors...)
+
+	value := reflect.ValueOf(c).Elem()
+	fields := value.Type()
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		required := field.Tag.Get("required")
+		if required == "" || (required == "production" && c.Environment != "production") {
+			continue
+		}
+		if value.Field(i).IsZero() {
+			when := ""
+			if required == "production" {
+				when = " when ENV=production"
+			}
+			key := field.Tag.Get("env")
+			problems = append(problems, fmt.Sprintf("%s is required%s; set it or mount it with %s_FILE", key, when, key))
+		}
+	}
+
+	checkHour := func(key string, hour int) {
+		if hour < 0 || hour > 23 {
+			problems = append(problems, fmt.Sprintf("%s=%d: must be an hour from 0 to 23", key, hour))
+		}
+	}
+	checkHour("BEDTIME_HOUR", c.BedtimeHour)
+	checkHour("WAKE_HOUR", c.WakeHour)
+	checkHour("RECORDING_WARM_HOUR", c.RecordingWarmHour)
+
+	if c.SongClipMinSeconds <= 0 || c.SongClipMaxSeconds < c.SongClipMinSeconds {
+		problems = append(problems, fmt.Sprintf("SONG_CLIP_MIN_SECONDS=%d, SONG_CLIP_MAX_SECONDS=%d: must be positive, with the minimum no more than the maximum", c.SongClipMinSeconds, c.SongClipMaxSeconds))
+	}
+	if c.FactExperimentPercent < 0 || c.FactExperimentPercent > 100 {
+		problems = append(problems, fmt.Sprintf("FACT_EXPERIMENT_ENHANCED_PERCENT=%d: must be a percentage from 0 to 100", c.FactExperimentPercent))
+	}
+	switch c.FactGenerator {
+	case "basic", "enhanced", "location":
+	default:
+		problems = append(problems, fmt.Sprintf("BIRD_FACT_GENERATOR=%q: must be \"basic\", \"enhanced\", or \"location\"", c.FactGenerator))
+	}
+	switch c.TitleEnglishVariant {
+	case "", "us", "uk":
+	default:
+		problems = append(problems, fmt.Sprintf("TITLE_ENGLISH_VARIANT=%q: must be \"us\", \"uk\", or empty", c.TitleEnglishVariant))
+	}
+	switch c.YotoAutoAdvance {
+	case "", "next", "none", "repeat":
+	default:
+		problems = append(problems, fmt.Sprintf("YOTO_AUTOADVANCE=%q: must be \"next\", \"none\", \"repeat\", or empty", c.YotoAutoAdvance))
+	}
+	if c.YotoResumeTimeoutSeconds < 0 {
+		problems = append(problems, fmt.Sprintf("YOTO_RESUME_TIMEOUT_SECONDS=%d: must not be negative", c.YotoResumeTimeoutSeconds))
+	}
+	switch c.YotoTokenStore {
+	case "", "file", "secret-manager", "memory":
+	default:
+		problems = append(problems, fmt.Sprintf("YOTO_TOKEN_STORE=%q: must be \"file\", \"secret-manager\", \"memory\", or empty", c.YotoTokenStore))
+	}
+	if c.DeviceSyncMinutes < 0 {
+		problems = append(problems, fmt.Sprintf("DEVICE_SYNC_MINUTES=%d: must be 0 (off) or more", c.DeviceSyncMinutes))
+	}
+	if c.MaxConcurrentUpdates < 1 {
+		problems = append(problems, fmt.Sprintf("MAX_CONCURRENT_UPDATES=%d: must be at least 1", c.MaxConcurrentUpdates))
+	}
+	if c.CardLookupTimeoutSeconds < 1 || c.CardPublishTimeoutSeconds < 1 {
+		problems = append(problems, fmt.Sprintf("CARD_LOOKUP_TIMEOUT_SECONDS=%d, CARD_PUBLISH_TIMEOUT_SECONDS=%d: must be at least 1", c.CardLookupTimeoutSeconds, c.CardPublishTimeoutSeconds))
+	}
+	if c.AlertEmailTo != "" && (c.SMTPHost == "" || c.AlertEmailFrom == "") {
+		problems = append(problems, "ALERT_EMAIL_TO is set: SMTP_HOST and ALERT_EMAIL_FROM are required to send alert emails")
+	}
+	if c.AlertWindowSeconds < 1 || c.AlertCooldownMinutes < 0 {
+		problems = append(problems, fmt.Sprintf("ALERT_WINDOW_SECONDS=%d, ALERT_COOLDOWN_MINUTES=%d: the window must be at least 1 and the cooldown 0 or more", c.AlertWindowSeconds, c.AlertCooldownMinutes))
+	}
+	if c.ElevenLabsMaxConcurrent < 1 {
+		problems = append(problems, fmt.Sprintf("ELEVENLABS_MAX_CONCURRENT=%d: must be at least 1", c.ElevenLabsMaxConcurrent))
+	}
+	switch c.WeatherProvider {
+	case "open-meteo", "none":
+	default:
+		problems = append(problems, fmt.Sprintf("WEATHER_PROVIDER=%q: must be \"open-meteo\" or \"none\"", c.WeatherProvider))
+	}
+	if c.GuideTargetSeconds <= 0 {
+		problems = append(problems, fmt.Sprintf("GUIDE_TARGET_SECONDS=%g: must be more than 0", c.GuideTargetSeconds))
+	}
+	if c.PerspectiveThreshold <= 0 || c.PerspectiveThreshold > 1 {
+		problems = append(problems, fmt.Sprintf("PERSPECTIVE_THRESHOLD=%g: must be above 0 and at most 1", c.PerspectiveThreshold))
+	}
+	if c.BirdStoreDriver != "" && c.BirdStoreDSN == "" {
+		problems = append(problems, fmt.Sprintf("BIRD_STORE_DRIVER=%s needs BIRD_STORE_DSN or DATABASE_URL", c.BirdStoreDriver))
+	}
+	switch c.AssetStoreBackend {
+	case "local", "gcs":
+	default:
+		problems = append(problems, fmt.Sprintf("ASSET_STORE_BACKEND=%q: must be \"local\" or \"gcs\"", c.AssetStoreBackend))
+	}
+	switch c.TTSCacheBackend {
+	case "disk", "gcs":
+	default:
+		problems = append(problems, fmt.Sprintf("TTS_CACHE_BACKEND=%q: must be \"disk\" or \"gcs\"", c.TTSCacheBackend))
+	}
+	switch c.Geocoder {
+	case "nominatim", "opencage", "none":
+	default:
+		problems = append(problems, fmt.Sprintf("GEOCODER=%q: must be \"nominatim\", \"opencage\", or \"none\"", c.Geocoder))
+	}
+	if c.StreamCacheMB < 1 {
+		problems = append(problems, fmt.Sprintf("STREAM_CACHE_MB=%d: must be at least 1", c.StreamCacheMB))
+	}
+	if c.GuideReadingGrade <= 0 {
+		problems = append(problems, fmt.Sprintf("GUIDE_READING_GRADE=%g: must be more than 0", c.GuideReadingGrade))
+	}
+	if c.LocationSmoothingWindowDays < 1 || c.LocationSmoothingSwitchDays < 1 {
+		problems = append(problems, fmt.Sprintf("LOCATION_SMOOTHING_WINDOW_DAYS=%d, LOCATION_SMOOTHING_SWITCH_DAYS=%d: must be at least 1", c.LocationSmoothingWindowDays, c.LocationSmoothingSwitchDays))
+	}
+	if c.GCPSecretsProject == "" && (c.YotoTokenStore == "secret-manager" || c.AutoUpdateSecrets) {
+		problems = append(problems, "YOTO_TOKEN_STORE=secret-manager and AUTO_UPDATE_SECRETS need GCP_PROJECT")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+}
+
+// Warnings lists settings that won't stop the server but leave features degraded, such as
+// narrated chapters enabled without an ElevenLabs key
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if c.ElevenLabsAPIKey == "" {
+		narrated := []struct {
+			key     string
+			enabled bool
+		}{
+			{"ENABLE_BIRD_QUIZ", c.EnableBirdQuiz},
+			{"ENABLE_HOTSPOT_CHAPTER", c.EnableHotspotChapter},
+			{"ENABLE_BIRD_HERO", c.EnableBirdHero},
+			{"ENABLE_BIRD_OF_WEEK", c.EnableBirdOfWeek},
+			{"ENABLE_LISTEN_AND_COUNT", c.EnableListenAndCount},
+			{"ENABLE_GUIDE_CALLS", c.EnableGuideCalls},
+			{"ENABLE_OUTRO_ROTATION", c.EnableOutroRotation},
+		}
+		for _, feature := range narrated {
+			if feature.enabled {
+				warnings = append(warnings, fmt.Sprintf("%s is on but ELEVENLABS_API_KEY is empty; its chapter will be skipped", feature.key))
+			}
+		}
+	}
+	if c.EnableWeather && !c.EnableGuideCalls {
+		warnings = append(warnings, "ENABLE_WEATHER is on but ENABLE_GUIDE_CALLS is off; pre-rendered guides won't mention the weather")
+	}
+	if c.EBirdAPIKey == "" {
+		warnings = append(warnings, "EBIRD_API_KEY is empty; birds are picked without regional sightings")
+	}
+	if c.Environment == "production" && c.BirdStoreDriver == "" {
+		warnings = append(warnings, "DATABASE_URL is empty; queued webhooks, daily birds, and plays are kept on this instance's disk and lost when it is replaced")
+	}
+	if c.Geocoder == "opencage" && c.OpenCageAPIKey == "" {
+		warnings = append(warnings, "GEOCODER=opencage but OPENCAGE_API_KEY is empty; locations are geocoded with Nominatim")
+	}
+	if c.Cards == nil || len(c.Cards.Cards()) == 0 {
+		warnings = append(warnings, "neither YOTO_CARD_ID nor CARD_REGISTRY_PATH names a card; no cards will be updated")
+	}
+	return warnings
+}
+
+// Redacted returns every environment setting by variable name, with secrets that are set masked,
+// for inspecting a running deployment
+func (c *Config) Redacted() map[string]interface{} {
+	settings := make(map[string]interface{})
+	value := reflect.ValueOf(c).Elem()
+	fields := value.Type()
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		if field.Tag.Get("secret") == "true" && !value.Field(i).IsZero() {
+			settings[key] = redactedValue
+			continue
+		}
+		settings[key] = value.Field(i).Interface()
+	}
+	return settings
+}
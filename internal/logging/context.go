@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// RequestIDHeader carries a caller's correlation ID in, and ours back out
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// NewRequestID returns a random correlation ID
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// WithRequestID returns a context whose log entries carry the correlation ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the context's correlation ID, or "" when it has none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID from the context and the "[TAG]" component from the
+// message to every record, so slog entries line up with the rest of the codebase's logs
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if match := componentPattern.FindStringSubmatch(record.Message); match != nil && match[1] != "" {
+		record.AddAttrs(slog.String("component", strings.TrimSpace(match[1])))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// setDefaultLogger makes handler the slog default. slog.SetDefault also reroutes the log
+// package through slog, so the log package's own output and flags are put back afterwards.
+func setDefaultLogger(handler slog.Handler) {
+	flags, output := log.Flags(), log.Writer()
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	log.SetFlags(flags)
+	log.SetOutput(output)
+}
+
+// consoleHandler writes readable key=value lines for local development
+func consoleHandler() slog.Handler {
+	return slog.NewTextHandler(os.Stdout, nil)
+}
+
+// jsonHandler writes entries with the field names Cloud Logging expects
+func jsonHandler() slog.Handler {
+	return slog.NewJSONHandler(entryWriter{}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return attr
+			}
+			switch attr.Key {
+			case slog.LevelKey:
+				return slog.String("severity", severityForLevel(attr.Value.Any().(slog.Level)))
+			case slog.MessageKey:
+				attr.Key = "message"
+				attr.Value = slog.StringValue(strings.TrimSpace(emojiPattern.ReplaceAllString(attr.Value.String(), "")))
+			}
+			return attr
+		},
+	})
+}
+
+func severityForLevel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level < slog.LevelInfo:
+		return "DEBUG"
+	}
+	return "INFO"
+}
+
+// entryWriter writes to the real stdout under the same lock as Write, so slog entries never
+// interleave with captured console lines
+type entryWriter struct{}
+
+func (entryWriter) Write(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return out.Write(p)
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one structured log line in the format Cloud Logging parses from stdout
+type Entry struct {
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Component string `json:"component,omitempty"`
+	CardID    string `json:"card_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Trace     string `json:"logging.googleapis.com/trace,omitempty"`
+	Time      string `json:"time"`
+
+	HTTPRequest *HTTPRequest `json:"httpRequest,omitempty"`
+}
+
+// HTTPRequest is the Cloud Logging request payload for access logs
+type HTTPRequest struct {
+	RequestMethod string `json:"requestMethod"`
+	RequestURL    string `json:"requestUrl"`
+	Status        int    `json:"status"`
+	Latency       string `json:"latency"`
+	RemoteIP      string `json:"remoteIp,omitempty"`
+	UserAgent     string `json:"userAgent,omitempty"`
+}
+
+var (
+	// componentPattern matches the "[TAG]" and "Name:" prefixes used throughout the codebase
+	componentPattern = regexp.MustCompile(`^\s*(?:\[([A-Za-z_ ]+)\]|([A-Z][A-Za-z]+):\s)`)
+
+	// emojiPattern strips pictographs and variation selectors so messages stay searchable
+	emojiPattern = regexp.MustCompile(`[\x{1F000}-\x{1FAFF}\x{2600}-\x{27BF}\x{2B00}-\x{2BFF}\x{FE0F}]\s*`)
+
+	mu      sync.Mutex
+	out     io.Writer = os.Stdout
+	enabled bool
+	cardID  string
+	project string
+)
+
+// Enabled reports whether JSON logging is active
+func Enabled() bool {
+	return enabled
+}
+
+// Setup switches the standard logger and slog to one-line JSON entries on stdout when format is
+// "json". Entries are written before the log call returns, so a log.Fatal message is never lost.
+// cardID is attached to entries that mention it and projectID is used
+// to build trace names. Any other format leaves console output unchanged and sends slog
+// to stdout as key=value lines.
+func Setup(format string, configuredCardID string, projectID string) {
+	if strings.ToLower(format) != "json" {
+		setDefaultLogger(consoleHandler())
+		return
+	}
+
+	mu.Lock()
+	out = os.Stdout
+	enabled = true
+	cardID = configuredCardID
+	project = projectID
+	mu.Unlock()
+
+	log.SetFlags(0)
+	log.SetOutput(&lineWriter{severity: "INFO"})
+
+	setDefaultLogger(jsonHandler())
+}
+
+// Write emits a single structured entry
+func Write(entry Entry) {
+	if entry.Time == "" {
+		entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	out.Write(append(data, '\n'))
+}
+
+// TraceName converts an X-Cloud-Trace-Context header into a Cloud Logging trace name
+func TraceName(header string) string {
+	if header == "" || project == "" {
+		return ""
+	}
+	traceID := strings.SplitN(header, "/", 2)[0]
+	return "projects/" + project + "/traces/" + traceID
+}
+
+// lineWriter turns each message from the standard logger into an entry as it is written. The
+// logger makes one Write per call, so a multi-line message stays one entry.
+type lineWriter struct {
+	severity string
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if entry, ok := parseLine(strings.TrimRight(string(p), "\n"), w.severity); ok {
+		Write(entry)
+	}
+	return len(p), nil
+}
+
+// parseLine builds an entry from a console line, inferring severity and component
+func parseLine(line string, defaultSeverity string) (Entry, bool) {
+	message := strings.TrimSpace(emojiPattern.ReplaceAllString(line, ""))
+	if message == "" || strings.Trim(message, "=-") == "" {
+		return Entry{}, false
+	}
+
+	entry := Entry{
+		Severity: severityFor(line, defaultSeverity),
+		Message:  message,
+	}
+
+	if match := componentPattern.FindStringSubmatch(message); match != nil {
+		component := match[1]
+		if component == "" {
+			component = match[2]
+		}
+		entry.Component = strings.TrimSpace(component)
+	}
+
+	if cardID != "" && strings.Contains(line, cardID) {
+		entry.CardID = cardID
+	}
+
+	return entry, true
+}
+
+func severityFor(line string, defaultSeverity string) string {
+	lower := strings.ToLower(line)
+	switch {
+	case strings.Contains(line, "❌"), strings.Contains(lower, "error"), strings.Contains(lower, "failed"), strings.Contains(lower, "panic"):
+		return "ERROR"
+	case strings.Contains(line, "⚠"), strings.Contains(lower, "warning"):
+		return "WARNING"
+	default:
+		return defaultSeverity
+	}
+}
@@ -17,6 +17,8 @@ type Bird struct {
 	Facts            []string  `json:"facts"`
 	Description      string    `json:"description"`
 	WikipediaURL     string    `json:"wikipedia_url"`
+	PhotoURL         string    `json:"photo_url,omitempty"`
+	PhotoAttribution string    `json:"photo_attribution,omitempty"`
 	Latitude         float64   `json:"latitude,omitempty"`
 	Longitude        float64   `json:"longitude,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
@@ -0,0 +1,376 @@
+// Package preview builds a card's daily update without publishing it: Yoto is faked and
+// ElevenLabs is answered with silence, and the result is written to a local folder
+package preview
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/api"
+	"github.com/callen/bird-song-explorer/internal/config"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/pkg/gcp"
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/callen/bird-song-explorer/pkg/yoto/yototest"
+)
+
+const (
+	elevenLabsHost = "api.elevenlabs.io"
+	// Narration pace used to size the silent stand-in for each script
+	dryRunWordsPerSecond = 2.5
+)
+
+// Options configures a dry run
+type Options struct {
+	OutDir  string // Preview bundle folder
+	CardID  string // Card to build; empty builds the default card
+	Offline bool   // Refuse requests to anything but the fakes, leaving out what eBird and others would add
+}
+
+// narratedScript is a script the pipeline sent to ElevenLabs during a dry run
+type narratedScript struct {
+	VoiceID string
+	Text    string
+}
+
+// narrationRecorder stands in for ElevenLabs: it records each script and answers with silence of
+// about the narration's length, passing every other request through (offline, only requests to
+// this machine, where the fakes run)
+type narrationRecorder struct {
+	next    http.RoundTripper
+	offline bool
+
+	mu      sync.Mutex
+	scripts []narratedScript
+}
+
+func (r *narrationRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Hostname() != elevenLabsHost {
+		if r.offline && !isLoopback(req.URL.Hostname()) {
+			return nil, fmt.Errorf("dry run is offline: refusing %s", req.URL.Host)
+		}
+		return r.next.RoundTrip(req)
+	}
+
+	var body struct {
+		Text string `json:"text"`
+	}
+	if req.Body != nil {
+		json.NewDecoder(req.Body).Decode(&body)
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	r.scripts = append(r.scripts, narratedScript{VoiceID: path.Base(req.URL.Path), Text: body.Text})
+	r.mu.Unlock()
+
+	seconds := max(float64(len(strings.Fields(body.Text)))/dryRunWordsPerSecond, 1)
+	audio := services.SilentMP3(seconds)
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"audio/mpeg"}},
+		Body:          io.NopCloser(bytes.NewReader(audio)),
+		ContentLength: int64(len(audio)),
+		Request:       req,
+	}, nil
+}
+
+// isLoopback reports whether host is this machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Scripts returns the scripts narrated so far, in order
+func (r *narrationRecorder) Scripts() []narratedScript {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]narratedScript(nil), r.scripts...)
+}
+
+// Result describes a preview bundle
+type Result struct {
+	CardID  string
+	Bird    string
+	Dir     string
+	Tracks  int // MP3s written
+	Scripts int // Scripts sent for narration
+}
+
+// Run builds a card's daily update end to end (bird selection, scripts, and audio mixing)
+// against a fake Yoto API and a silent stand-in for ElevenLabs, then writes a preview bundle to
+// the output folder: scripts.txt, each track as an MP3, and content.json as it would be POSTed.
+// Other sources (eBird, Xeno-canto, the recordings bucket) are still read unless the run is
+// offline, so the preview matches what the card would get. State the run writes, narration and
+// the TTS cache included, goes to a scratch directory, leaving the deployment's bird of the day,
+// histories, and caches untouched, and the run fails rather than create a Google Cloud client.
+// Run configures the services itself, so call it instead of api.ConfigureServices.
+func Run(cfg *config.Config, options Options) (*Result, error) {
+	if cfg.Cards == nil {
+		cfg.Cards = config.NewCardRegistry(nil, cfg.YotoCardID)
+	}
+	card, ok := cfg.Cards.Default(), len(cfg.Cards.Cards()) > 0
+	if options.CardID != "" {
+		card, ok = cfg.Cards.Get(options.CardID)
+	}
+	if !ok {
+		return nil, fmt.Errorf("no card to preview; set YOTO_CARD_ID or pass --card")
+	}
+
+	if err := os.MkdirAll(options.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", options.OutDir, err)
+	}
+	scratch, err := os.MkdirTemp("", "dry_run")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+	IsolateState(cfg, scratch)
+	api.ConfigureServices(cfg)
+
+	// Credentials would reach the production buckets and secrets, so no client may be created
+	var cloudMu sync.Mutex
+	var cloudClients []string
+	gcp.SetClientGuard(func(purpose string) error {
+		cloudMu.Lock()
+		defer cloudMu.Unlock()
+		if !slices.Contains(cloudClients, purpose) {
+			cloudClients = append(cloudClients, purpose)
+		}
+		return fmt.Errorf("dry run: refusing to create a Google Cloud client for %s", purpose)
+	})
+	defer gcp.SetClientGuard(nil)
+
+	yotoAPI := yototest.NewServer()
+	defer yotoAPI.Close()
+	yotoAPI.AddCard(yoto.Card{CardID: card.CardID, Title: config.DefaultCardTitle})
+	cfg.YotoAPIBaseURL = yotoAPI.URL
+	cfg.YotoAccessToken, cfg.YotoRefreshToken = yotoAPI.Tokens()
+	if cfg.ElevenLabsAPIKey == "" {
+		// Never sent anywhere; a key keeps the narrated chapters in the preview
+		cfg.ElevenLabsAPIKey = "dry-run"
+	}
+
+	narration := &narrationRecorder{next: http.DefaultTransport, offline: options.Offline}
+	http.DefaultTransport = narration
+	defer func() { http.DefaultTransport = narration.next }()
+
+	handler := api.NewHandler(cfg)
+	site := httptest.NewServer(api.NewRouter(cfg, handler))
+	defer site.Close()
+	// Stream and stitched track URLs point back at the in-process server
+	cfg.ServiceURL = site.URL
+
+	day := time.Now().UTC().Format("2006-01-02")
+	var update struct {
+		Bird string `json:"bird"`
+	}
+	if err := dryRunRequest(cfg, "POST", site.URL+"/api/v1/daily-update?card="+url.QueryEscape(card.CardID), &update); err != nil {
+		return nil, fmt.Errorf("daily update failed: %w", err)
+	}
+	log.Printf("[DRY_RUN] Built %s for card %s", update.Bird, card.CardID)
+
+	posts := yotoAPI.RequestsTo("POST", "/content")
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("the update didn't POST any content to Yoto")
+	}
+	posted := posts[len(posts)-1].Body
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, posted, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to format content.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(options.OutDir, "content.json"), indented.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write content.json: %w", err)
+	}
+
+	tracks, err := writeTracks(yotoAPI, posted, options.OutDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var preview struct {
+		Transcript *services.ScriptTranscript `json:"transcript"`
+	}
+	query := url.Values{"bird": {update.Bird}, "card": {card.CardID}, "day": {day}}
+	if err := dryRunRequest(cfg, "GET", site.URL+"/api/v1/admin/preview?"+query.Encode(), &preview); err != nil {
+		log.Printf("[DRY_RUN] No guide transcript for %s: %v", update.Bird, err)
+	}
+	if err := writeScripts(options.OutDir, card.CardID, update.Bird, day, preview.Transcript, narration.Scripts()); err != nil {
+		return nil, err
+	}
+
+	cloudMu.Lock()
+	defer cloudMu.Unlock()
+	if len(cloudClients) > 0 {
+		return nil, fmt.Errorf("the update tried to use Google Cloud (%s); point those settings at local storage", strings.Join(cloudClients, ", "))
+	}
+
+	return &Result{
+		CardID:  card.CardID,
+		Bird:    update.Bird,
+		Dir:     options.OutDir,
+		Tracks:  tracks,
+		Scripts: len(narration.Scripts()),
+	}, nil
+}
+
+// IsolateState points every file the pipeline writes at the scratch directory and keeps it off
+// the buckets and the Yoto token store. The bird of the day is copied in, so the preview uses the
+// bird the card already has today.
+func IsolateState(cfg *config.Config, scratch string) {
+	for path, name := range map[*string]string{
+		&cfg.CardJobsPath:        "card_jobs.json",
+		&cfg.DeviceRegistryPath:  "device_registry.json",
+		&cfg.DeviceProfilesPath:  "device_profiles.json",
+		&cfg.ElevenLabsQuotaPath: "elevenlabs_quota.json",
+		&cfg.IconMappingsPath:    "icon_mappings.json",
+		&cfg.OutroHistoryPath:    "outro_history.json",
+		&cfg.RolloutStatePath:    "rollout_state.json",
+		&cfg.WebhookQueuePath:    "webhook_queue.json",
+		&cfg.WeeklySchedulePath:  "weekly_schedule.json",
+		&cfg.FactExperimentPath:  "fact_experiment.json",
+	} {
+		*path = filepath.Join(scratch, name)
+	}
+	// An empty TTS cache sends every script through the recorder, and its silent renders and the
+	// narration made from them mustn't reach the shared cache or the narration bucket
+	cfg.TTSCacheBackend = "disk"
+	cfg.TTSCacheDir = filepath.Join(scratch, "tts_cache")
+	cfg.NarrationDir = filepath.Join(scratch, "narration")
+	// Assets are read from the checkout, since the asset bucket needs credentials
+	cfg.AssetStoreBackend = "local"
+	// Card jobs store their guide transcripts, which mustn't replace the real ones
+	cfg.TranscriptDir = filepath.Join(scratch, "transcripts")
+
+	birdStorePath := filepath.Join(scratch, "bird_of_day.json")
+	if cfg.BirdStoreDriver == "" {
+		if data, err := os.ReadFile(cfg.BirdStorePath); err == nil {
+			os.WriteFile(birdStorePath, data, 0644)
+		}
+	}
+	cfg.BirdStoreDriver = ""
+	cfg.BirdStorePath = birdStorePath
+	cfg.PlayEventsPath = filepath.Join(scratch, "play_events.json")
+	cfg.YotoTokenStore = "memory"
+	cfg.YotoTokenFile = filepath.Join(scratch, "yoto_tokens.json")
+	cfg.EnableRecordingWarmer = false
+	cfg.DeviceSyncMinutes = 0
+}
+
+// dryRunRequest calls the in-process server with the scheduler token and decodes the JSON reply
+func dryRunRequest(cfg *config.Config, method string, requestURL string, out interface{}) error {
+	req, err := http.NewRequest(method, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Scheduler-Token", cfg.SchedulerToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %d: %s", method, req.URL.Path, resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+var unsafeFilename = regexp.MustCompile(`[^a-z0-9]+`)
+
+// writeTracks saves every track in the posted content as an MP3, numbered in play order. Uploaded
+// tracks come from the fake Yoto API; streamed tracks are fetched from their URL.
+func writeTracks(yotoAPI *yototest.Server, posted []byte, outDir string) (int, error) {
+	var body struct {
+		Content struct {
+			Chapters []yoto.Chapter `json:"chapters"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(posted, &body); err != nil {
+		return 0, fmt.Errorf("failed to parse the posted content: %w", err)
+	}
+
+	uploads := make(map[string][]byte)
+	for _, upload := range yotoAPI.Uploads() {
+		sum := sha256.Sum256(upload.Data)
+		uploads[hex.EncodeToString(sum[:])] = upload.Data
+	}
+
+	written := 0
+	for _, chapter := range body.Content.Chapters {
+		for _, track := range chapter.Tracks {
+			var audio []byte
+			if sha, ok := strings.CutPrefix(track.TrackURL, "yoto:#"); ok {
+				audio, ok = uploads[sha]
+				if !ok {
+					log.Printf("[DRY_RUN] No upload for track %q (%s)", track.Title, track.TrackURL)
+					continue
+				}
+			} else {
+				resp, err := http.Get(track.TrackURL)
+				if err != nil {
+					log.Printf("[DRY_RUN] Failed to fetch track %q: %v", track.Title, err)
+					continue
+				}
+				audio, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil || resp.StatusCode != http.StatusOK {
+					log.Printf("[DRY_RUN] Failed to fetch track %q: status %d, %v", track.Title, resp.StatusCode, err)
+					continue
+				}
+			}
+
+			written++
+			name := strings.Trim(unsafeFilename.ReplaceAllString(strings.ToLower(track.Title), "_"), "_")
+			filename := filepath.Join(outDir, fmt.Sprintf("%02d_%s.mp3", written, name))
+			if err := os.WriteFile(filename, audio, 0644); err != nil {
+				return written, fmt.Errorf("failed to write %s: %w", filename, err)
+			}
+		}
+	}
+	return written, nil
+}
+
+// writeScripts writes the guide transcript and every narrated script to scripts.txt
+func writeScripts(outDir string, cardID string, birdName string, day string, transcript *services.ScriptTranscript, scripts []narratedScript) error {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Bird Song Explorer dry run: %s on card %s, %s\n", birdName, cardID, day)
+
+	if transcript != nil {
+		fmt.Fprintf(&out, "\n== Guide (%s generator) ==\n%s\n", transcript.Generator, transcript.Script)
+	}
+	for i, script := range scripts {
+		fmt.Fprintf(&out, "\n== Narration %d (voice %s) ==\n%s\n", i+1, script.VoiceID, script.Text)
+	}
+
+	if err := os.WriteFile(filepath.Join(outDir, "scripts.txt"), []byte(out.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write scripts.txt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package preview
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/callen/bird-song-explorer/internal/config"
+)
+
+// hostRecorder sits under the dry run's transport and records every request that gets past it,
+// answering only requests to this machine
+type hostRecorder struct {
+	next http.RoundTripper
+
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (r *hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.hosts = append(r.hosts, req.URL.Hostname())
+	r.mu.Unlock()
+	if !isLoopback(req.URL.Hostname()) {
+		return nil, errors.New("test network: no route to " + req.URL.Host)
+	}
+	return r.next.RoundTrip(req)
+}
+
+func TestOfflineDryRunOnlyContactsItsFakes(t *testing.T) {
+	// Card updates read ./assets and ./birds from the repository root
+	t.Chdir("../..")
+	recorder := &hostRecorder{next: http.DefaultTransport}
+	http.DefaultTransport = recorder
+	t.Cleanup(func() { http.DefaultTransport = recorder.next })
+
+	cfg := config.Load()
+	cfg.YotoCardID = "card1"
+	cfg.Cards = config.NewCardRegistry(nil, cfg.YotoCardID)
+	cfg.SchedulerToken = "scheduler-token"
+	// As deployed: the shared TTS cache and narration live in the production bucket
+	cfg.TTSCacheBackend = "gcs"
+	cfg.NarrationDir = ""
+
+	outDir := t.TempDir()
+	result, err := Run(cfg, Options{OutDir: outDir, CardID: "card1", Offline: true})
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if result.Scripts == 0 {
+		t.Error("nothing was narrated, so the narration store and TTS cache weren't exercised")
+	}
+	for _, name := range []string{"content.json", "scripts.txt"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("preview bundle is missing %s: %v", name, err)
+		}
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for _, host := range recorder.hosts {
+		if !isLoopback(host) {
+			t.Errorf("dry run contacted %s; only the fakes on this machine may be", host)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+	"github.com/callen/bird-song-explorer/pkg/gcp"
+)
+
+// ukuleleJingleAsset closes every outro
+const ukuleleJingleAsset = "sound_effects/chimes/ukulele_short.mp3"
+
+// assetCacheMaxAge is how long a downloaded asset is reused before checking the bucket again
+const assetCacheMaxAge = time.Hour
+
+// AssetStore reads the audio assets (pre-recorded intros and outros, music, sound effects)
+// by slash-separated names relative to the asset root, e.g. "final_outros/outro_joke_1_Amelia.mp3".
+// Missing assets return an error wrapping fs.ErrNotExist.
+type AssetStore interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	Exists(name string) bool
+	// Glob returns the asset names matching a path.Match pattern, sorted
+	Glob(pattern string) ([]string, error)
+	// LocalPath returns a file on disk holding the asset, for passing to ffmpeg
+	LocalPath(name string) (string, error)
+	// Location names where the asset is kept, for telling an operator where to find it
+	Location(name string) string
+}
+
+var (
+	sharedAssetStore     AssetStore
+	sharedAssetStoreOnce sync.Once
+)
+
+// ConfigureAssetStore sets up the process-wide asset store: objects under prefix in bucket when
+// backend is "gcs" (a prefix of "/" is the bucket root), otherwise files under dir. Call it at
+// startup, before anything reads an asset; later calls have no effect.
+func ConfigureAssetStore(backend, bucket, prefix, dir string) {
+	sharedAssetStoreOnce.Do(func() {
+		if backend == "gcs" {
+			sharedAssetStore = NewGCSAssetStore(bucket, strings.TrimPrefix(prefix, "/"))
+			return
+		}
+		if dir == "" {
+			dir = "assets"
+		}
+		sharedAssetStore = NewLocalAssetStore(dir)
+	})
+}
+
+// SharedAssetStore returns the process-wide asset store, files under assets when
+// ConfigureAssetStore wasn't called
+func SharedAssetStore() AssetStore {
+	ConfigureAssetStore("local", "", "", "")
+	return sharedAssetStore
+}
+
+// LocalAssetStore reads assets from a directory in the container
+type LocalAssetStore struct {
+	root string
+}
+
+// NewLocalAssetStore creates a store rooted at dir
+func NewLocalAssetStore(dir string) *LocalAssetStore {
+	return &LocalAssetStore{root: dir}
+}
+
+func (ls *LocalAssetStore) path(name string) string {
+	return filepath.Join(ls.root, filepath.FromSlash(name))
+}
+
+// ReadFile reads an asset
+func (ls *LocalAssetStore) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(ls.path(name))
+}
+
+// WriteFile writes an asset atomically
+func (ls *LocalAssetStore) WriteFile(name string, data []byte) error {
+	return filex.AtomicWriteFile(ls.path(name), data, 0644)
+}
+
+// Exists reports whether the asset file exists
+func (ls *LocalAssetStore) Exists(name string) bool {
+	_, err := os.Stat(ls.path(name))
+	return err == nil
+}
+
+// Glob lists matching asset files
+func (ls *LocalAssetStore) Glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(ls.path(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		rel, err := filepath.Rel(ls.root, match)
+		if err != nil {
+			continue
+		}
+		names = append(names, filepath.ToSlash(rel))
+	}
+	return names, nil
+}
+
+// LocalPath returns the asset's own path
+func (ls *LocalAssetStore) LocalPath(name string) (string, error) {
+	assetPath := ls.path(name)
+	if _, err := os.Stat(assetPath); err != nil {
+		return "", err
+	}
+	return assetPath, nil
+}
+
+// Location returns the asset's path
+func (ls *LocalAssetStore) Location(name string) string {
+	return ls.path(name)
+}
+
+// GCSAssetStore reads assets from a Cloud Storage bucket, so new recordings can be added without
+// redeploying. Files passed to ffmpeg are downloaded to a local cache.
+type GCSAssetStore struct {
+	bucket   string
+	prefix   string
+	cacheDir string
+
+	once       sync.Once
+	httpClient *http.Client
+	clientErr  error
+}
+
+// NewGCSAssetStore creates a store reading objects under prefix in bucket
+func NewGCSAssetStore(bucket string, prefix string) *GCSAssetStore {
+	return &GCSAssetStore{
+		bucket:   bucket,
+		prefix:   prefix,
+		cacheDir: filepath.Join(os.TempDir(), "asset_cache"),
+	}
+}
+
+// client creates the authenticated HTTP client on first use
+func (gs *GCSAssetStore) client() (*http.Client, error) {
+	gs.once.Do(func() {
+		client, err := gcp.DefaultClient(context.Background(), "GCS bucket "+gs.bucket, "https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			gs.clientErr = fmt.Errorf("failed to create GCS client: %w", err)
+			return
+		}
+		client.Timeout = 60 * time.Second
+		gs.httpClient = client
+	})
+	return gs.httpClient, gs.clientErr
+}
+
+func (gs *GCSAssetStore) objectURL(name string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s%s", gs.bucket, gs.prefix, name)
+}
+
+// Location returns the asset's gs:// URI
+func (gs *GCSAssetStore) Location(name string) string {
+	return fmt.Sprintf("gs://%s/%s%s", gs.bucket, gs.prefix, name)
+}
+
+// ReadFile downloads an asset
+func (gs *GCSAssetStore) ReadFile(name string) ([]byte, error) {
+	client, err := gs.client()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Get(gs.objectURL(name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("asset %s: %w", name, fs.ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GCS returned status %d for %s", resp.StatusCode, name)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// WriteFile uploads an asset
+func (gs *GCSAssetStore) WriteFile(name string, data []byte) error {
+	client, err := gs.client()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", gs.objectURL(name), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "audio/mpeg")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GCS upload returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Exists checks for the object with a HEAD request
+func (gs *GCSAssetStore) Exists(name string) bool {
+	client, err := gs.client()
+	if err != nil {
+		return false
+	}
+
+	resp, err := client.Head(gs.objectURL(name))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// Glob lists the objects under the pattern's literal leading part and filters them with path.Match
+func (gs *GCSAssetStore) Glob(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	client, err := gs.client()
+	if err != nil {
+		return nil, err
+	}
+
+	listPrefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		listPrefix = pattern[:i]
+	}
+
+	var names []string
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("prefix", gs.prefix+listPrefix)
+		query.Set("fields", "items(name),nextPageToken")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		resp, err := client.Get(fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o?%s", gs.bucket, query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("GCS list returned status %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse GCS listing: %w", err)
+		}
+
+		for _, item := range page.Items {
+			name := strings.TrimPrefix(item.Name, gs.prefix)
+			if matched, _ := path.Match(pattern, name); matched {
+				names = append(names, name)
+			}
+		}
+		if page.NextPageToken == "" {
+			return names, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// LocalPath downloads the asset into the cache, reusing a copy younger than assetCacheMaxAge. A
+// stale copy is used when the bucket can't be reached.
+func (gs *GCSAssetStore) LocalPath(name string) (string, error) {
+	cachePath := filepath.Join(gs.cacheDir, filepath.FromSlash(name))
+	info, statErr := os.Stat(cachePath)
+	if statErr == nil && time.Since(info.ModTime()) < assetCacheMaxAge {
+		return cachePath, nil
+	}
+
+	data, err := gs.ReadFile(name)
+	if err != nil {
+		if statErr == nil {
+			log.Printf("[ASSET_STORE] Failed to refresh %s, using cached copy: %v", name, err)
+			return cachePath, nil
+		}
+		return "", err
+	}
+
+	// Concurrent mixes may download the same asset; each writes its own temp file
+	if err := filex.AtomicWriteFile(cachePath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to cache asset: %w", err)
+	}
+	return cachePath, nil
+}
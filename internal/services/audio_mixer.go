@@ -3,37 +3,26 @@ package services
 import (
 	"bytes"
 	"fmt"
-	"math/rand"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/randx"
 )
 
 // AudioMixer handles mixing audio with background music or nature sounds
 type AudioMixer struct {
-	musicPath string
+	assets AssetStore
+	rng    *randx.Randomizer // Picks the background music; nil picks with a time seed
 }
 
-// NewAudioMixer creates a new audio mixer
-func NewAudioMixer() *AudioMixer {
-	// Try different possible paths for the music directory
-	possiblePaths := []string{
-		"/root/assets/music", // Docker container path
-		"./assets/music",     // Local development path
-		"assets/music",       // Relative path
-	}
-
-	musicPath := "./assets/music" // Default
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			musicPath = path
-			break
-		}
-	}
-
+// NewAudioMixer creates a new audio mixer reading music and jingles from the shared asset store
+func NewAudioMixer(rng *randx.Randomizer) *AudioMixer {
 	return &AudioMixer{
-		musicPath: musicPath,
+		assets: SharedAssetStore(),
+		rng:    rng,
 	}
 }
 
@@ -41,36 +30,34 @@ func NewAudioMixer() *AudioMixer {
 func (am *AudioMixer) MixOutroWithMusic(voiceData []byte, musicType string) ([]byte, error) {
 
 	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		fmt.Printf("[AUDIO_MIXER] ffmpeg not found in PATH, returning voice only\n")
+	if !GetFFmpegCapabilities().Mixing {
+		slog.Warn("[AUDIO_MIXER] ffmpeg mixing unavailable, returning voice only")
 		return voiceData, nil
 	}
 
 	// Create temp files for processing
 	tempDir := os.TempDir()
 	voiceFile := filepath.Join(tempDir, fmt.Sprintf("outro_voice_%d.mp3", time.Now().Unix()))
-	musicFile := am.selectBackgroundMusic(musicType)
+	musicAsset := am.selectBackgroundMusic(musicType)
 	outputFile := filepath.Join(tempDir, fmt.Sprintf("outro_mixed_%d.mp3", time.Now().Unix()))
 
 	// Write voice data to temp file
 	if err := os.WriteFile(voiceFile, voiceData, 0644); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Failed to write voice file: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Failed to write voice file", "error", err)
 		return nil, fmt.Errorf("failed to write voice file: %w", err)
 	}
 	defer os.Remove(voiceFile)
 	defer os.Remove(outputFile)
 
 	// Check if music file exists
-	if _, err := os.Stat(musicFile); os.IsNotExist(err) {
-		fmt.Printf("[AUDIO_MIXER] Music file not found: %s, returning voice only\n", musicFile)
-		// List contents of music directory for debugging
-		if entries, err := os.ReadDir(am.musicPath); err == nil {
-			fmt.Printf("[AUDIO_MIXER] Contents of %s:\n", am.musicPath)
-			for _, entry := range entries {
-				fmt.Printf("[AUDIO_MIXER]   - %s\n", entry.Name())
-			}
+	musicFile, err := am.assets.LocalPath(musicAsset)
+	if err != nil {
+		slog.Warn("[AUDIO_MIXER] Music file not found, returning voice only", "asset", musicAsset, "error", err)
+		// List available music for debugging
+		if names, err := am.assets.Glob("music/*"); err == nil {
+			slog.Info("[AUDIO_MIXER] Available music", "files", names)
 		} else {
-			fmt.Printf("[AUDIO_MIXER] Could not read music directory: %v\n", err)
+			slog.Warn("[AUDIO_MIXER] Could not read music directory", "error", err)
 		}
 		return voiceData, nil // Return voice only if no music available
 	}
@@ -79,7 +66,7 @@ func (am *AudioMixer) MixOutroWithMusic(voiceData []byte, musicType string) ([]b
 	// - Music at moderate volume
 	// - Fade in/out for smooth transitions
 	// - Mix both tracks together
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.Command(ffmpegBinary(),
 		"-i", voiceFile, // Input: voice
 		"-i", musicFile, // Input: music
 		"-filter_complex",
@@ -99,7 +86,7 @@ func (am *AudioMixer) MixOutroWithMusic(voiceData []byte, musicType string) ([]b
 
 	if err := cmd.Run(); err != nil {
 		// If ffmpeg fails, return voice only
-		fmt.Printf("[AUDIO_MIXER] ffmpeg mixing failed: %v\nStderr: %s\n", err, stderr.String())
+		slog.Error("[AUDIO_MIXER] ffmpeg mixing failed", "error", err, "stderr", stderr.String())
 		return voiceData, nil
 	}
 
@@ -146,52 +133,43 @@ func (am *AudioMixer) selectBackgroundMusic(musicType string) string {
 
 	// Check if we have seasonal music
 	if tracks, exists := musicTracks[seasonalKey]; exists && len(tracks) > 0 {
-		rand.Seed(time.Now().UnixNano())
-		selected := tracks[rand.Intn(len(tracks))]
-		return filepath.Join(am.musicPath, selected)
+		selected := tracks[am.rng.Intn(len(tracks))]
+		return "music/" + selected
 	}
 
 	// Fall back to cheerful music
 	if tracks, exists := musicTracks["cheerful"]; exists && len(tracks) > 0 {
-		rand.Seed(time.Now().UnixNano())
-		selected := tracks[rand.Intn(len(tracks))]
-		return filepath.Join(am.musicPath, selected)
+		selected := tracks[am.rng.Intn(len(tracks))]
+		return "music/" + selected
 	}
 
 	// Default fallback
-	return filepath.Join(am.musicPath, "outro_music_default.mp3")
+	return "music/outro_music_default.mp3"
 }
 
 // DownloadAndCacheMusic downloads a music file from URL and caches it
 func (am *AudioMixer) DownloadAndCacheMusic(url string, filename string) error {
-	musicDir := am.musicPath
-
-	// Create music directory if it doesn't exist
-	if err := os.MkdirAll(musicDir, 0755); err != nil {
-		return fmt.Errorf("failed to create music directory: %w", err)
-	}
-
-	outputPath := filepath.Join(musicDir, filename)
+	asset := "music/" + filename
 
 	// Check if already cached
-	if _, err := os.Stat(outputPath); err == nil {
-		fmt.Printf("[AUDIO_MIXER] Music already cached: %s\n", filename)
+	if am.assets.Exists(asset) {
+		slog.Info("[AUDIO_MIXER] Music already cached", "file", filename)
 		return nil
 	}
 
 	// Download the file (placeholder - would need actual implementation)
-	fmt.Printf("[AUDIO_MIXER] Would download music from %s to %s\n", url, outputPath)
+	slog.Info("[AUDIO_MIXER] Would download music", "url", url, "asset", asset)
 
 	return nil
 }
 
 // MixOutroWithNatureSounds mixes the outro voice with bird song as background
 func (am *AudioMixer) MixOutroWithNatureSounds(voiceData []byte, birdSongData []byte) ([]byte, error) {
-	fmt.Printf("[AUDIO_MIXER] Starting nature sounds mixing process\n")
+	slog.Info("[AUDIO_MIXER] Starting nature sounds mixing process")
 
 	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		fmt.Printf("[AUDIO_MIXER] ffmpeg not found in PATH, returning voice only\n")
+	if !GetFFmpegCapabilities().Mixing {
+		slog.Warn("[AUDIO_MIXER] ffmpeg mixing unavailable, returning voice only")
 		return voiceData, nil
 	}
 
@@ -201,34 +179,32 @@ func (am *AudioMixer) MixOutroWithNatureSounds(voiceData []byte, birdSongData []
 	birdFile := filepath.Join(tempDir, fmt.Sprintf("bird_song_%d.mp3", time.Now().Unix()))
 	outputFile := filepath.Join(tempDir, fmt.Sprintf("outro_mixed_%d.mp3", time.Now().Unix()))
 
-	fmt.Printf("[AUDIO_MIXER] Voice file: %s\n", voiceFile)
-	fmt.Printf("[AUDIO_MIXER] Bird song file: %s\n", birdFile)
-	fmt.Printf("[AUDIO_MIXER] Output file: %s\n", outputFile)
+	slog.Debug("[AUDIO_MIXER] Mixing files", "voice", voiceFile, "bird_song", birdFile, "output", outputFile)
 
 	// Write voice data to temp file
 	if err := os.WriteFile(voiceFile, voiceData, 0644); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Failed to write voice file: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Failed to write voice file", "error", err)
 		return nil, fmt.Errorf("failed to write voice file: %w", err)
 	}
 	defer os.Remove(voiceFile)
 
 	// Write bird song data to temp file
 	if err := os.WriteFile(birdFile, birdSongData, 0644); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Failed to write bird song file: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Failed to write bird song file", "error", err)
 		os.Remove(voiceFile)
 		return nil, fmt.Errorf("failed to write bird song file: %w", err)
 	}
 	defer os.Remove(birdFile)
 	defer os.Remove(outputFile)
 
-	fmt.Printf("[AUDIO_MIXER] Files written, proceeding with mixing\n")
+	slog.Debug("[AUDIO_MIXER] Files written, proceeding with mixing")
 
 	// Mix audio using ffmpeg with volume normalization:
 	// - Bird song at 15% volume while voice is playing
 	// - Apply loudnorm for consistent levels with other tracks
 	// - Loop bird song if it's shorter than the outro
 	// - Fade in/out for smooth transitions
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.Command(ffmpegBinary(),
 		"-i", voiceFile, // Input: voice
 		"-stream_loop", "-1", // Loop the bird song
 		"-i", birdFile, // Input: bird song
@@ -250,7 +226,7 @@ func (am *AudioMixer) MixOutroWithNatureSounds(voiceData []byte, birdSongData []
 
 	if err := cmd.Run(); err != nil {
 		// If ffmpeg fails, return voice only
-		fmt.Printf("[AUDIO_MIXER] ffmpeg mixing failed: %v\nStderr: %s\n", err, stderr.String())
+		slog.Error("[AUDIO_MIXER] ffmpeg mixing failed", "error", err, "stderr", stderr.String())
 		return voiceData, nil
 	}
 
@@ -260,17 +236,17 @@ func (am *AudioMixer) MixOutroWithNatureSounds(voiceData []byte, birdSongData []
 		return nil, fmt.Errorf("failed to read mixed audio: %w", err)
 	}
 
-	fmt.Printf("[AUDIO_MIXER] Successfully mixed outro with bird song (size: %d bytes)\n", len(mixedData))
+	slog.Info("[AUDIO_MIXER] Mixed outro with bird song", "bytes", len(mixedData))
 	return mixedData, nil
 }
 
 // MixOutroWithAmbienceAndJingle mixes the outro voice with ambience and adds a ukulele jingle
 func (am *AudioMixer) MixOutroWithAmbienceAndJingle(voiceData []byte, ambienceData []byte, ambienceName string) ([]byte, error) {
-	fmt.Printf("[AUDIO_MIXER] Starting outro mixing with %s ambience and ukulele jingle\n", ambienceName)
+	slog.Info("[AUDIO_MIXER] Starting outro mixing with ambience and ukulele jingle", "ambience", ambienceName)
 
 	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		fmt.Printf("[AUDIO_MIXER] ffmpeg not found in PATH, returning voice only\n")
+	if !GetFFmpegCapabilities().Mixing {
+		slog.Warn("[AUDIO_MIXER] ffmpeg mixing unavailable, returning voice only")
 		return voiceData, nil
 	}
 
@@ -280,24 +256,21 @@ func (am *AudioMixer) MixOutroWithAmbienceAndJingle(voiceData []byte, ambienceDa
 	ambienceFile := filepath.Join(tempDir, fmt.Sprintf("outro_ambience_%d.mp3", time.Now().Unix()))
 	outputFile := filepath.Join(tempDir, fmt.Sprintf("outro_mixed_%d.mp3", time.Now().Unix()))
 
-	// Path to ukulele jingle
-	ukuleleFile := "assets/sound_effects/chimes/ukulele_short.mp3"
+	// Path to ukulele jingle, downloaded if the assets live in a bucket
+	ukuleleFile, ukuleleErr := am.assets.LocalPath(ukuleleJingleAsset)
 
-	fmt.Printf("[AUDIO_MIXER] Voice file: %s\n", voiceFile)
-	fmt.Printf("[AUDIO_MIXER] Ambience file: %s\n", ambienceFile)
-	fmt.Printf("[AUDIO_MIXER] Ukulele file: %s\n", ukuleleFile)
-	fmt.Printf("[AUDIO_MIXER] Output file: %s\n", outputFile)
+	slog.Debug("[AUDIO_MIXER] Mixing files", "voice", voiceFile, "ambience", ambienceFile, "ukulele", ukuleleFile, "output", outputFile)
 
 	// Write voice data to temp file
 	if err := os.WriteFile(voiceFile, voiceData, 0644); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Failed to write voice file: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Failed to write voice file", "error", err)
 		return nil, fmt.Errorf("failed to write voice file: %w", err)
 	}
 	defer os.Remove(voiceFile)
 
 	// Write ambience data to temp file
 	if err := os.WriteFile(ambienceFile, ambienceData, 0644); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Failed to write ambience file: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Failed to write ambience file", "error", err)
 		os.Remove(voiceFile)
 		return nil, fmt.Errorf("failed to write ambience file: %w", err)
 	}
@@ -305,20 +278,20 @@ func (am *AudioMixer) MixOutroWithAmbienceAndJingle(voiceData []byte, ambienceDa
 	defer os.Remove(outputFile)
 
 	// Check if ukulele file exists
-	if _, err := os.Stat(ukuleleFile); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Ukulele file not found: %s, mixing without jingle\n", ukuleleFile)
+	if ukuleleErr != nil {
+		slog.Warn("[AUDIO_MIXER] Ukulele file not found, mixing without jingle", "asset", ukuleleJingleAsset, "error", ukuleleErr)
 		// Fall back to mixing without jingle
 		return am.mixOutroWithAmbienceOnly(voiceFile, ambienceFile, outputFile)
 	}
 
-	fmt.Printf("[AUDIO_MIXER] Files written, proceeding with mixing\n")
+	slog.Debug("[AUDIO_MIXER] Files written, proceeding with mixing")
 
 	// Mix audio using ffmpeg with volume normalization:
 	// - Ambience at 15% volume while voice is playing
 	// - Fade out ambience 1-2 seconds faster after voice ends
 	// - Crossfade to ukulele jingle at the end
 	// - Apply loudnorm for consistent levels
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.Command(ffmpegBinary(),
 		"-i", voiceFile, // Input 0: voice
 		"-i", ambienceFile, // Input 1: ambience
 		"-i", ukuleleFile, // Input 2: ukulele jingle
@@ -350,8 +323,7 @@ func (am *AudioMixer) MixOutroWithAmbienceAndJingle(voiceData []byte, ambienceDa
 
 	if err := cmd.Run(); err != nil {
 		// If ffmpeg fails, try simpler mixing
-		fmt.Printf("[AUDIO_MIXER] Complex mixing failed: %v\nStderr: %s\n", err, stderr.String())
-		fmt.Printf("[AUDIO_MIXER] Falling back to simpler mixing\n")
+		slog.Warn("[AUDIO_MIXER] Complex mixing failed, falling back to simpler mixing", "error", err, "stderr", stderr.String())
 		return am.mixOutroWithAmbienceOnly(voiceFile, ambienceFile, outputFile)
 	}
 
@@ -361,13 +333,13 @@ func (am *AudioMixer) MixOutroWithAmbienceAndJingle(voiceData []byte, ambienceDa
 		return nil, fmt.Errorf("failed to read mixed audio: %w", err)
 	}
 
-	fmt.Printf("[AUDIO_MIXER] Successfully mixed outro with %s ambience and ukulele jingle (size: %d bytes)\n", ambienceName, len(mixedData))
+	slog.Info("[AUDIO_MIXER] Mixed outro with ambience and ukulele jingle", "ambience", ambienceName, "bytes", len(mixedData))
 	return mixedData, nil
 }
 
 // mixOutroWithAmbienceOnly is a fallback for when ukulele is not available
 func (am *AudioMixer) mixOutroWithAmbienceOnly(voiceFile, ambienceFile, outputFile string) ([]byte, error) {
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.Command(ffmpegBinary(),
 		"-i", voiceFile, // Input: voice
 		"-i", ambienceFile, // Input: ambience
 		"-filter_complex",
@@ -387,7 +359,7 @@ func (am *AudioMixer) mixOutroWithAmbienceOnly(voiceFile, ambienceFile, outputFi
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Fallback mixing failed: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Fallback mixing failed", "error", err)
 		return nil, fmt.Errorf("fallback mixing failed: %w", err)
 	}
 
@@ -406,7 +378,7 @@ func GenerateSimpleOutroMusic() ([]byte, error) {
 
 	// Generate a simple 25-second tune using sox (if available) or ffmpeg
 	// This creates a simple, cheerful melody using sine waves
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.Command(ffmpegBinary(),
 		"-f", "lavfi",
 		"-i", "sine=frequency=523:duration=0.25,sine=frequency=587:duration=0.25,sine=frequency=659:duration=0.25,sine=frequency=523:duration=0.25",
 		"-filter_complex",
@@ -422,7 +394,7 @@ func GenerateSimpleOutroMusic() ([]byte, error) {
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("[AUDIO_MIXER] Failed to generate simple music: %v\n", err)
+		slog.Error("[AUDIO_MIXER] Failed to generate simple music", "error", err)
 		return nil, fmt.Errorf("failed to generate music: %w", err)
 	}
 
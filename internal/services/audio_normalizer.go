@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EBU R128 loudness targets: integrated loudness, true peak, and loudness range
+const (
+	defaultLoudnessTarget = -23.0
+	loudnessTruePeak      = -1.0
+	loudnessRange         = 7.0
+)
+
+// loudnormMeasurement is the JSON summary loudnorm prints after the analysis pass
+type loudnormMeasurement struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// AudioNormalizer brings recordings and TTS tracks to the same loudness so kids don't have to
+// adjust the volume between tracks
+type AudioNormalizer struct {
+	targetLUFS float64
+}
+
+// NewAudioNormalizer creates a normalizer for the given integrated loudness (0 uses the EBU R128 -23 LUFS)
+func NewAudioNormalizer(targetLUFS float64) *AudioNormalizer {
+	if targetLUFS == 0 {
+		targetLUFS = defaultLoudnessTarget
+	}
+	return &AudioNormalizer{targetLUFS: targetLUFS}
+}
+
+// Normalize runs two-pass loudnorm on MP3 data. Without ffmpeg loudnorm support, or if ffmpeg
+// fails, the original audio is returned with an error so callers can still upload it.
+func (an *AudioNormalizer) Normalize(audioData []byte) ([]byte, error) {
+	caps := GetFFmpegCapabilities()
+	if !caps.Loudnorm || !caps.MP3Encode {
+		return audioData, fmt.Errorf("ffmpeg loudnorm unavailable")
+	}
+
+	tempDir, err := os.MkdirTemp("", "normalize_")
+	if err != nil {
+		return audioData, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFile := filepath.Join(tempDir, "input.mp3")
+	outputFile := filepath.Join(tempDir, "output.mp3")
+	if err := os.WriteFile(inputFile, audioData, 0644); err != nil {
+		return audioData, fmt.Errorf("failed to write input audio: %w", err)
+	}
+
+	measured, err := an.measure(inputFile)
+	if err != nil {
+		return audioData, err
+	}
+
+	// Second pass applies a linear gain from the measured values, preserving dynamics
+	filter := fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		an.targetLUFS, loudnessTruePeak, loudnessRange,
+		measured.InputI, measured.InputTP, measured.InputLRA, measured.InputThresh, measured.TargetOffset)
+
+	cmd := exec.Command(ffmpegBinary(),
+		"-i", inputFile,
+		"-af", filter,
+		"-ar", "44100",
+		"-c:a", "libmp3lame",
+		"-b:a", "192k",
+		"-y",
+		outputFile,
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return audioData, fmt.Errorf("loudnorm failed: %w: %s", err, stderr.String())
+	}
+
+	normalized, err := os.ReadFile(outputFile)
+	if err != nil {
+		return audioData, fmt.Errorf("failed to read normalized audio: %w", err)
+	}
+
+	slog.Info("[AUDIO_NORMALIZER] Normalized loudness", "input_lufs", measured.InputI, "target_lufs", an.targetLUFS)
+	return normalized, nil
+}
+
+// measure runs the loudnorm analysis pass and parses its JSON summary from stderr
+func (an *AudioNormalizer) measure(inputFile string) (*loudnormMeasurement, error) {
+	cmd := exec.Command(ffmpegBinary(),
+		"-i", inputFile,
+		"-af", fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f:print_format=json", an.targetLUFS, loudnessTruePeak, loudnessRange),
+		"-f", "null",
+		"-",
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("loudnorm analysis failed: %w", err)
+	}
+
+	output := stderr.String()
+	start := strings.LastIndex(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("loudnorm analysis printed no measurements")
+	}
+
+	var measured loudnormMeasurement
+	if err := json.Unmarshal([]byte(output[start:end+1]), &measured); err != nil {
+		return nil, fmt.Errorf("failed to parse loudnorm measurements: %w", err)
+	}
+
+	// Silent input measures as -inf, which the second pass can't use
+	if strings.Contains(measured.InputI, "inf") {
+		return nil, fmt.Errorf("input is silent")
+	}
+	return &measured, nil
+}
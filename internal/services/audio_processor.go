@@ -0,0 +1,209 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// AudioProcessor performs the basic edits the mixers need. Durations and offsets are in seconds.
+type AudioProcessor interface {
+	// Name identifies the implementation in logs ("ffmpeg" or "native")
+	Name() string
+	// Concat joins clips end to end
+	Concat(clips ...[]byte) ([]byte, error)
+	// Gain scales the amplitude by factor (2.0 is roughly +6 dB)
+	Gain(audio []byte, factor float64) ([]byte, error)
+	// Fade ramps the volume up over the first fadeIn seconds and down over the last fadeOut seconds
+	Fade(audio []byte, fadeIn, fadeOut float64) ([]byte, error)
+	// Trim keeps duration seconds starting at start
+	Trim(audio []byte, start, duration float64) ([]byte, error)
+	// Duration returns the clip length
+	Duration(audio []byte) (float64, error)
+}
+
+// NewAudioProcessor uses ffmpeg when it can encode MP3 with fades, otherwise the pure-Go processor
+func NewAudioProcessor() AudioProcessor {
+	caps := GetFFmpegCapabilities()
+	if caps.Available && caps.MP3Encode && caps.Fades {
+		return &FFmpegAudioProcessor{}
+	}
+	return &NativeAudioProcessor{}
+}
+
+// AudioSegment is one piece of an assembled track, cut and faded before it's joined
+type AudioSegment struct {
+	Label    string // Names the segment in errors
+	Audio    []byte
+	Start    float64 // Where to cut from; with Duration 0 the segment is used whole
+	Duration float64
+	FadeIn   float64
+	FadeOut  float64
+	Gain     float64 // Amplitude factor; 0 leaves the level alone
+}
+
+// AssembleSegments cuts, fades, and joins segments in order, returning the track and the offset
+// each segment starts at in it, so spliced-in clips can be placed and logged
+func AssembleSegments(processor AudioProcessor, segments []AudioSegment) ([]byte, []float64, error) {
+	if len(segments) == 0 {
+		return nil, nil, fmt.Errorf("no segments to assemble")
+	}
+
+	clips := make([][]byte, 0, len(segments))
+	offsets := make([]float64, 0, len(segments))
+	position := 0.0
+	for _, segment := range segments {
+		clip := segment.Audio
+		var err error
+		if segment.Duration > 0 {
+			if clip, err = processor.Trim(clip, segment.Start, segment.Duration); err != nil {
+				return nil, nil, fmt.Errorf("failed to trim %s (%s): %w", segment.Label, processor.Name(), err)
+			}
+		}
+		if segment.Gain > 0 && segment.Gain != 1 {
+			if clip, err = processor.Gain(clip, segment.Gain); err != nil {
+				return nil, nil, fmt.Errorf("failed to adjust %s (%s): %w", segment.Label, processor.Name(), err)
+			}
+		}
+		if segment.FadeIn > 0 || segment.FadeOut > 0 {
+			if clip, err = processor.Fade(clip, segment.FadeIn, segment.FadeOut); err != nil {
+				return nil, nil, fmt.Errorf("failed to fade %s (%s): %w", segment.Label, processor.Name(), err)
+			}
+		}
+		duration, err := processor.Duration(clip)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to measure %s (%s): %w", segment.Label, processor.Name(), err)
+		}
+
+		clips = append(clips, clip)
+		offsets = append(offsets, position)
+		position += duration
+	}
+
+	track, err := processor.Concat(clips...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to join segments (%s): %w", processor.Name(), err)
+	}
+	return track, offsets, nil
+}
+
+// FFmpegAudioProcessor re-encodes through ffmpeg, which handles any input format and sample-accurate edits
+type FFmpegAudioProcessor struct{}
+
+// Name returns "ffmpeg"
+func (fp *FFmpegAudioProcessor) Name() string {
+	return "ffmpeg"
+}
+
+// Concat joins clips with the concat filter, so clips with different formats can be combined
+func (fp *FFmpegAudioProcessor) Concat(clips ...[]byte) ([]byte, error) {
+	if len(clips) == 0 {
+		return nil, fmt.Errorf("no clips to concatenate")
+	}
+
+	var filter strings.Builder
+	for i := range clips {
+		fmt.Fprintf(&filter, "[%d:a]", i)
+	}
+	fmt.Fprintf(&filter, "concat=n=%d:v=0:a=1[out]", len(clips))
+
+	return runAudioFilter(clips, "-filter_complex", filter.String(), "-map", "[out]")
+}
+
+// Gain applies the volume filter
+func (fp *FFmpegAudioProcessor) Gain(audio []byte, factor float64) ([]byte, error) {
+	return runAudioFilter([][]byte{audio}, "-af", fmt.Sprintf("volume=%.3f", factor))
+}
+
+// Fade applies afade at either end
+func (fp *FFmpegAudioProcessor) Fade(audio []byte, fadeIn, fadeOut float64) ([]byte, error) {
+	var filters []string
+	if fadeIn > 0 {
+		filters = append(filters, fmt.Sprintf("afade=t=in:st=0:d=%.2f", fadeIn))
+	}
+	if fadeOut > 0 {
+		duration, err := fp.Duration(audio)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, fmt.Sprintf("afade=t=out:st=%.2f:d=%.2f", duration-fadeOut, fadeOut))
+	}
+	if len(filters) == 0 {
+		return audio, nil
+	}
+	return runAudioFilter([][]byte{audio}, "-af", strings.Join(filters, ","))
+}
+
+// Trim cuts with atrim
+func (fp *FFmpegAudioProcessor) Trim(audio []byte, start, duration float64) ([]byte, error) {
+	return runAudioFilter([][]byte{audio}, "-af", fmt.Sprintf("atrim=%.2f:%.2f,asetpts=PTS-STARTPTS", start, start+duration))
+}
+
+// Duration uses ffprobe when available, falling back to reading the frames
+func (fp *FFmpegAudioProcessor) Duration(audio []byte) (float64, error) {
+	if GetFFmpegCapabilities().Probe {
+		if path, cleanup, err := writeTempAudio(audio, "probe"); err == nil {
+			defer cleanup()
+			if duration := probeDuration(path); duration > 0 {
+				return duration, nil
+			}
+		}
+	}
+	return (&NativeAudioProcessor{}).Duration(audio)
+}
+
+// runAudioFilter writes the inputs to temp files, runs ffmpeg with the given filter arguments, and
+// returns the MP3 output
+func runAudioFilter(inputs [][]byte, filterArgs ...string) ([]byte, error) {
+	var args []string
+	for i, input := range inputs {
+		path, cleanup, err := writeTempAudio(input, fmt.Sprintf("input%d", i))
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		args = append(args, "-i", path)
+	}
+
+	outputFile, cleanup, err := writeTempAudio(nil, "output")
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	args = append(args, filterArgs...)
+	args = append(args,
+		"-c:a", "libmp3lame",
+		"-b:a", "192k",
+		"-ar", "44100",
+		"-y", outputFile,
+	)
+
+	cmd := exec.Command(ffmpegBinary(), args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, stderr.String())
+	}
+
+	return os.ReadFile(outputFile)
+}
+
+// writeTempAudio saves audio to a uniquely named temp file, returning a cleanup func
+func writeTempAudio(audio []byte, label string) (string, func(), error) {
+	file, err := os.CreateTemp("", "audio_"+label+"_*.mp3")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+
+	_, err = file.Write(audio)
+	file.Close()
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return file.Name(), cleanup, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+const (
+	stitchCrossfadeSeconds = 0.75
+	// Clips shorter than this are the silent skip clip standing in for a left-out chapter
+	stitchMinClipSeconds = 1.0
+)
+
+// AudioStitcher joins a card's tracks into one continuous MP3 for cards that play as a single
+// track. Neighbouring tracks overlap by a short crossfade so there is no gap between them.
+type AudioStitcher struct {
+	processor  AudioProcessor
+	httpClient *http.Client
+	crossfade  float64
+}
+
+// NewAudioStitcher creates a stitcher. Stream requests can wait on narration being rendered, so
+// downloads get a generous timeout.
+func NewAudioStitcher() *AudioStitcher {
+	return &AudioStitcher{
+		processor:  NewAudioProcessor(),
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 3 * time.Minute}),
+		crossfade:  stitchCrossfadeSeconds,
+	}
+}
+
+// Stitch downloads each track in order and joins them
+func (as *AudioStitcher) Stitch(ctx context.Context, trackURLs []string) ([]byte, error) {
+	clips := make([][]byte, 0, len(trackURLs))
+	for _, trackURL := range trackURLs {
+		clip, err := as.download(ctx, trackURL)
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, clip)
+	}
+	return as.Join(clips)
+}
+
+// Join crossfades the clips into one track, leaving out silent skip clips. ffmpeg overlaps
+// neighbouring clips; the native processor fades each clip's edges and joins them end to end.
+func (as *AudioStitcher) Join(clips [][]byte) ([]byte, error) {
+	var kept [][]byte
+	shortest := math.Inf(1)
+	for _, clip := range clips {
+		duration, err := as.processor.Duration(clip)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure track (%s): %w", as.processor.Name(), err)
+		}
+		if duration < stitchMinClipSeconds {
+			continue
+		}
+		kept = append(kept, clip)
+		shortest = math.Min(shortest, duration)
+	}
+
+	switch len(kept) {
+	case 0:
+		return nil, fmt.Errorf("no tracks to stitch")
+	case 1:
+		return kept[0], nil
+	}
+
+	// A crossfade can't be longer than the clips it overlaps
+	crossfade := math.Min(as.crossfade, shortest/4)
+
+	if _, ok := as.processor.(*FFmpegAudioProcessor); ok {
+		var filter strings.Builder
+		previous := "0:a"
+		for i := 1; i < len(kept); i++ {
+			label := fmt.Sprintf("x%d", i)
+			if i == len(kept)-1 {
+				label = "out"
+			}
+			fmt.Fprintf(&filter, "[%s][%d:a]acrossfade=d=%.2f[%s];", previous, i, crossfade, label)
+			previous = label
+		}
+		stitched, err := runAudioFilter(kept, "-filter_complex", strings.TrimSuffix(filter.String(), ";"), "-map", "[out]")
+		if err != nil {
+			return nil, fmt.Errorf("failed to crossfade tracks: %w", err)
+		}
+		slog.Info("[STITCHER] Stitched tracks", "tracks", len(kept), "skipped", len(clips)-len(kept), "crossfade", crossfade, "bytes", len(stitched))
+		return stitched, nil
+	}
+
+	faded := make([][]byte, len(kept))
+	for i, clip := range kept {
+		fadeIn, fadeOut := crossfade/2, crossfade/2
+		if i == 0 {
+			fadeIn = 0
+		}
+		if i == len(kept)-1 {
+			fadeOut = 0
+		}
+		var err error
+		if faded[i], err = as.processor.Fade(clip, fadeIn, fadeOut); err != nil {
+			return nil, fmt.Errorf("failed to fade track (%s): %w", as.processor.Name(), err)
+		}
+	}
+	stitched, err := as.processor.Concat(faded...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join tracks (%s): %w", as.processor.Name(), err)
+	}
+	slog.Info("[STITCHER] Stitched tracks", "tracks", len(kept), "skipped", len(clips)-len(kept), "processor", as.processor.Name(), "bytes", len(stitched))
+	return stitched, nil
+}
+
+// download fetches one track, following the redirects session streams answer with
+func (as *AudioStitcher) download(ctx context.Context, trackURL string) ([]byte, error) {
+	resp, err := httpx.Get(ctx, as.httpClient, trackURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch track %s: %w", trackURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("track %s returned status %d", trackURL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -6,12 +6,9 @@ import (
 	"time"
 
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 type AvailableBird struct {
 	CommonName     string
 	ScientificName string
@@ -20,7 +17,9 @@ type AvailableBird struct {
 }
 
 type AvailableBirdsService struct {
-	birds []AvailableBird
+	birds       []AvailableBird
+	checklists  *RegionChecklists
+	frequencies *RegionFrequencies
 }
 
 func NewAvailableBirdsService() *AvailableBirdsService {
@@ -78,11 +77,58 @@ func (s *AvailableBirdsService) GetAllAvailableBirds() []AvailableBird {
 	return s.birds
 }
 
+// SetRegionChecklists filters region pack pools by the pack's eBird checklist
+func (s *AvailableBirdsService) SetRegionChecklists(checklists *RegionChecklists) {
+	s.checklists = checklists
+}
+
+// SetRegionFrequencies lets region packs feature a special guest, a bird their eBird region has
+// rarely reported lately
+func (s *AvailableBirdsService) SetRegionFrequencies(frequencies *RegionFrequencies) {
+	s.frequencies = frequencies
+}
+
+// GetSpecialGuestOn returns the region's special guest for the calendar date of t: on the region's
+// special guest day, a bird from its pool that its eBird region rates rare. It returns nil on other
+// days, for regions without an eBird region, and when none of the pool is rare.
+func (s *AvailableBirdsService) GetSpecialGuestOn(region string, t time.Time) *models.Bird {
+	pack := RegionPackByID(region)
+	if s.frequencies == nil || pack == nil || pack.EBirdRegion == "" || !IsSpecialGuestDay(region, t) {
+		return nil
+	}
+
+	var guests []AvailableBird
+	for _, bird := range s.GetBirdsByRegion(region) {
+		if rarity, known := s.frequencies.Rarity(pack.EBirdRegion, bird.ScientificName); known && rarity == RarityRare {
+			guests = append(guests, bird)
+		}
+	}
+	if len(guests) == 0 {
+		return nil
+	}
+
+	// Weeks take turns through the guests, so one rare bird doesn't return every week
+	selected := guests[int(weeksSinceEpoch(t))%len(guests)]
+
+	return &models.Bird{
+		CommonName:     selected.CommonName,
+		ScientificName: selected.ScientificName,
+		Region:         selected.Region,
+	}
+}
+
+// GetBirdsByRegion returns the birds tagged with the region. A region pack's pool also takes the
+// pack's candidate species, less any its eBird checklist doesn't list.
 func (s *AvailableBirdsService) GetBirdsByRegion(region string) []AvailableBird {
 	var regionalBirds []AvailableBird
 	regionLower := strings.ToLower(region)
+	pack := RegionPackByID(region)
 
 	for _, bird := range s.birds {
+		if pack != nil && pack.hasCandidate(bird.ScientificName) {
+			regionalBirds = append(regionalBirds, bird)
+			continue
+		}
 		for _, birdRegion := range bird.Regions {
 			if strings.ToLower(birdRegion) == regionLower {
 				regionalBirds = append(regionalBirds, bird)
@@ -91,9 +137,24 @@ func (s *AvailableBirdsService) GetBirdsByRegion(region string) []AvailableBird
 		}
 	}
 
+	if pack != nil && pack.EBirdRegion != "" && s.checklists != nil {
+		var recorded []AvailableBird
+		for _, bird := range regionalBirds {
+			if present, known := s.checklists.Contains(pack.EBirdRegion, bird.ScientificName); present || !known {
+				recorded = append(recorded, bird)
+			}
+		}
+		regionalBirds = recorded
+	}
+
 	return regionalBirds
 }
 
+// IsKnownRegion reports whether region is "global", "auto", a region pack, or tags any bird
+func (s *AvailableBirdsService) IsKnownRegion(region string) bool {
+	return region == "global" || region == RegionAuto || RegionPackByID(region) != nil || len(s.GetBirdsByRegion(region)) > 0
+}
+
 func (s *AvailableBirdsService) GetRandomBird() *models.Bird {
 	if len(s.birds) == 0 {
 		return nil
@@ -111,15 +172,9 @@ func (s *AvailableBirdsService) GetRandomBird() *models.Bird {
 func (s *AvailableBirdsService) GetRandomBirdForLocation(location *models.Location) *models.Bird {
 	var matchingBirds []AvailableBird
 
-	if location != nil && location.Country != "" {
-		regionLower := strings.ToLower(location.Country)
-		for _, bird := range s.birds {
-			for _, region := range bird.Regions {
-				if strings.ToLower(region) == regionLower {
-					matchingBirds = append(matchingBirds, bird)
-					break
-				}
-			}
+	if location != nil {
+		if pack := RegionPackForCountry(location.Country); pack != nil {
+			matchingBirds = s.GetBirdsByRegion(pack.ID)
 		}
 	}
 
@@ -144,20 +199,57 @@ func (s *AvailableBirdsService) GetRandomBirdForLocation(location *models.Locati
 	}
 }
 
+// GetBirdByName returns the available bird with the given common name, or nil
+// GetBirdByName finds an available bird by common name. Names the list doesn't spell the same way
+// ("great-spotted-woodpecker", a scientific name, or an eBird species code) are resolved through
+// the eBird taxonomy.
+func (s *AvailableBirdsService) GetBirdByName(commonName string) *models.Bird {
+	for _, bird := range s.birds {
+		if strings.EqualFold(bird.CommonName, commonName) {
+			return &models.Bird{
+				CommonName:     bird.CommonName,
+				ScientificName: bird.ScientificName,
+				Region:         bird.Region,
+			}
+		}
+	}
+
+	species, ok := ebird.SharedTaxonomy("").Resolve(commonName)
+	if !ok {
+		return nil
+	}
+	for _, bird := range s.birds {
+		if strings.EqualFold(bird.ScientificName, species.ScientificName) || strings.EqualFold(bird.CommonName, species.CommonName) {
+			return &models.Bird{
+				CommonName:     bird.CommonName,
+				ScientificName: bird.ScientificName,
+				Family:         species.Family,
+				Order:          species.Order,
+				Region:         bird.Region,
+			}
+		}
+	}
+	return nil
+}
+
 func (s *AvailableBirdsService) HasAvailableBirds() bool {
 	return len(s.birds) > 0
 }
 
 func (s *AvailableBirdsService) GetCyclingBird() *models.Bird {
+	return s.GetCyclingBirdOn(time.Now().UTC())
+}
+
+// GetCyclingBirdOn returns the rotation bird for the calendar date of t, in t's own location,
+// so a timezone that has already passed midnight gets the next day's bird
+func (s *AvailableBirdsService) GetCyclingBirdOn(t time.Time) *models.Bird {
 	if len(s.birds) == 0 {
 		return nil
 	}
 
-	now := time.Now().UTC()
 	// Calculate seed based on daily intervals since epoch
-	// This ensures the bird changes once per day at midnight UTC
-	daysSinceEpoch := now.Unix() / (24 * 60 * 60)
-	birdIndex := int(daysSinceEpoch) % len(s.birds)
+	// This ensures the bird changes once per day at midnight
+	birdIndex := int(daysSinceEpoch(t)) % len(s.birds)
 
 	selected := s.birds[birdIndex]
 
@@ -167,3 +259,90 @@ func (s *AvailableBirdsService) GetCyclingBird() *models.Bird {
 		Region:         selected.Region,
 	}
 }
+
+// GetBirdForContinent selects today's bird from a continent-level species pool.
+// Used in "no location" mode when neither IP lookup nor configured defaults give coordinates.
+func (s *AvailableBirdsService) GetBirdForContinent(continent string) *models.Bird {
+	return s.GetBirdForContinentOn(continent, time.Now().UTC())
+}
+
+// GetBirdForContinentOn selects the continent pool's bird for the calendar date of t. Region packs
+// with none of their birds available use their continent's pool, and continents no bird is tagged
+// with use their region packs' birds.
+func (s *AvailableBirdsService) GetBirdForContinentOn(continent string, t time.Time) *models.Bird {
+	pool := s.GetBirdsByRegion(continent)
+	if pack := RegionPackByID(continent); len(pool) == 0 && pack != nil && pack.Continent != continent {
+		pool = s.GetBirdsByRegion(pack.Continent)
+	}
+	if len(pool) == 0 {
+		for _, pack := range RegionPacks() {
+			if pack.Continent == continent && pack.ID != continent {
+				pool = append(pool, s.GetBirdsByRegion(pack.ID)...)
+			}
+		}
+	}
+	if len(pool) == 0 {
+		pool = s.GetBirdsByRegion("global")
+	}
+	if len(pool) == 0 {
+		return s.GetCyclingBirdOn(t)
+	}
+
+	selected := pool[int(daysSinceEpoch(t))%len(pool)]
+
+	return &models.Bird{
+		CommonName:     selected.CommonName,
+		ScientificName: selected.ScientificName,
+		Region:         selected.Region,
+	}
+}
+
+// GetBirdForTheme picks a species that fits a holiday or seasonal theme, or nil if none of the available
+// birds match
+func (s *AvailableBirdsService) GetBirdForTheme(theme *Theme) *models.Bird {
+	if theme == nil {
+		return nil
+	}
+	return s.birdMatching(theme.MatchesSpecies)
+}
+
+// nocturnalKeywords pick out the night birds favoured in night mode
+var nocturnalKeywords = []string{"owl", "nightjar", "nighthawk", "poorwill", "whip-poor-will", "potoo", "frogmouth", "night-heron", "kiwi", "nightingale"}
+
+// IsNocturnalSpecies reports whether a bird is one night mode favours
+func IsNocturnalSpecies(commonName string) bool {
+	return matchesKeywords(commonName, nocturnalKeywords)
+}
+
+// GetNocturnalBird returns the day's night bird for night mode, or nil if none is available
+func (s *AvailableBirdsService) GetNocturnalBird() *models.Bird {
+	return s.birdMatching(IsNocturnalSpecies)
+}
+
+// birdMatching picks the day's bird among the available species that match
+func (s *AvailableBirdsService) birdMatching(matches func(commonName string) bool) *models.Bird {
+	var themed []AvailableBird
+	for _, bird := range s.birds {
+		if matches(bird.CommonName) {
+			themed = append(themed, bird)
+		}
+	}
+	if len(themed) == 0 {
+		return nil
+	}
+
+	daysSinceEpoch := time.Now().UTC().Unix() / (24 * 60 * 60)
+	selected := themed[int(daysSinceEpoch)%len(themed)]
+
+	return &models.Bird{
+		CommonName:     selected.CommonName,
+		ScientificName: selected.ScientificName,
+		Region:         selected.Region,
+	}
+}
+
+// daysSinceEpoch counts days to the calendar date of t in its own location
+func daysSinceEpoch(t time.Time) int64 {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+}
@@ -1,54 +1,114 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"math/rand"
 	"strings"
-	"time"
 
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"github.com/callen/bird-song-explorer/pkg/wikipedia"
 )
 
+// basicFallbackFact is used when a bird has no usable description
+const basicFallbackFact = "The %s is an amazing bird!"
+
 // BasicFactGenerator generates simple, TTS-friendly bird facts
-type BasicFactGenerator struct{}
+type BasicFactGenerator struct {
+	text *LocaleTemplates  // Translated templates; nil for English
+	wiki *wikipedia.Client // Wikipedia in the script's language, for non-English scripts
+	rng  *randx.Randomizer // Picks the generic facts; nil picks with a time seed
+}
 
 // NewBasicFactGenerator creates a new basic fact generator
-func NewBasicFactGenerator() *BasicFactGenerator {
-	return &BasicFactGenerator{}
+func NewBasicFactGenerator(rng *randx.Randomizer) *BasicFactGenerator {
+	return &BasicFactGenerator{rng: rng}
+}
+
+// NewBasicFactGeneratorForLocale creates a basic fact generator that writes in the given language,
+// taking the bird's description from that language's Wikipedia
+func NewBasicFactGeneratorForLocale(locale string, rng *randx.Randomizer) *BasicFactGenerator {
+	text, ok := TemplatesForLocale(locale)
+	if !ok {
+		return NewBasicFactGenerator(rng)
+	}
+	return &BasicFactGenerator{
+		text: text,
+		wiki: wikipedia.NewClientForLanguage(NormalizeLocale(locale)),
+		rng:  rng,
+	}
 }
 
 // GetGeneratorType returns the type of this generator
 func (g *BasicFactGenerator) GetGeneratorType() string {
-	return "basic"
+	return FactGeneratorBasic
 }
 
 // GenerateFactScript creates a simple fact script for a bird
-func (g *BasicFactGenerator) GenerateFactScript(bird *models.Bird, latitude, longitude float64) string {
+func (g *BasicFactGenerator) GenerateFactScript(ctx context.Context, bird *models.Bird, latitude, longitude float64) string {
+	return g.GenerateFactTranscript(ctx, bird, latitude, longitude).Script
+}
+
+// GenerateFactTranscript creates a simple fact script for a bird, attributing each sentence to its source
+func (g *BasicFactGenerator) GenerateFactTranscript(ctx context.Context, bird *models.Bird, latitude, longitude float64) *ScriptTranscript {
+	bird, fromTaxonomy := withTaxonomy(ebird.SharedTaxonomy(""), bird)
+	if g.text != nil {
+		return g.generateLocalizedTranscript(ctx, bird)
+	}
+
+	var builder transcriptBuilder
+
 	// Extract scientific name if available
 	scientificName := bird.ScientificName
+	scientificSource := SourceCuratedBank
+	scientificDetail := "available_birds"
+	if fromTaxonomy {
+		scientificSource = SourceEBird
+		scientificDetail = "taxonomy"
+	}
 	if scientificName == "" && bird.Description != "" {
 		scientificName = g.extractScientificName(bird.Description)
+		scientificSource = SourceWikipedia
+		scientificDetail = "summary"
 	}
 
 	// Get a simple fact from the description
 	simpleFact := g.extractSimpleFact(bird.Description, bird.CommonName)
-	
+
 	// Get an additional generic fact
 	additionalFact := g.getGenericBirdFact(bird.CommonName, simpleFact)
 
 	// Build the text
-	var script string
 	if scientificName != "" {
 		// Format with scientific name and enhanced fact
-		script = fmt.Sprintf("The scientific name for the %s is %s. Did you know? %s %s Birds are found all over the world, each one perfectly adapted to its home!",
-			bird.CommonName, scientificName, simpleFact, additionalFact)
+		builder.add(fmt.Sprintf("The scientific name for the %s is %s.", bird.CommonName, scientificName), scientificSource, scientificDetail)
+		builder.add("Did you know?", SourceTemplate, "transition")
 	} else {
 		// Enhanced version without scientific name
-		script = fmt.Sprintf("Let me tell you about the amazing %s! Did you know? %s %s Every bird has its own special story. Listen carefully to learn its unique song!",
-			bird.CommonName, simpleFact, additionalFact)
+		builder.add(fmt.Sprintf("Let me tell you about the amazing %s! Did you know?", bird.CommonName), SourceTemplate, "intro")
+	}
+
+	if simpleFact == fmt.Sprintf(basicFallbackFact, bird.CommonName) {
+		builder.add(simpleFact, SourceTemplate, "fallback_fact")
+	} else {
+		builder.add(simpleFact, SourceWikipedia, "summary")
+	}
+	builder.add(additionalFact, SourceCuratedBank, "generic_bird_facts")
+
+	if dawnChorus := dawnChorusSentence(latitude, longitude); dawnChorus != "" {
+		builder.add(dawnChorus, SourceTemplate, "dawn_chorus")
+	}
+
+	builder.addCallCue(bird.CommonName)
+
+	if scientificName != "" {
+		builder.add("Birds are found all over the world, each one perfectly adapted to its home!", SourceTemplate, "closing")
+	} else {
+		builder.add("Every bird has its own special story. Listen carefully to learn its unique song!", SourceTemplate, "closing")
 	}
 
-	return script
+	return builder.transcript(bird.CommonName, g.GetGeneratorType())
 }
 
 // extractScientificName extracts the scientific name from a description
@@ -70,7 +130,7 @@ func (g *BasicFactGenerator) extractScientificName(description string) string {
 // extractSimpleFact extracts a simple fact from the description
 func (g *BasicFactGenerator) extractSimpleFact(description string, birdName string) string {
 	if description == "" {
-		return fmt.Sprintf("The %s is an amazing bird!", birdName)
+		return fmt.Sprintf(basicFallbackFact, birdName)
 	}
 
 	// Remove scientific name if present
@@ -98,7 +158,7 @@ func (g *BasicFactGenerator) extractSimpleFact(description string, birdName stri
 	if strings.Contains(strings.ToLower(simpleFact), "derived from") ||
 		strings.Contains(strings.ToLower(simpleFact), "greek") ||
 		strings.Contains(strings.ToLower(simpleFact), "latin") {
-		simpleFact = fmt.Sprintf("The %s is an amazing bird!", birdName)
+		simpleFact = fmt.Sprintf(basicFallbackFact, birdName)
 	}
 
 	return simpleFact
@@ -165,6 +225,52 @@ func (g *BasicFactGenerator) getGenericBirdFact(birdName string, existingFact st
 		"Birds existed alongside dinosaurs - they're living dinosaurs themselves!",
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	return defaultFacts[rand.Intn(len(defaultFacts))]
-}
\ No newline at end of file
+	return defaultFacts[g.rng.Intn(len(defaultFacts))]
+}
+
+// generateLocalizedTranscript builds the script from the translated templates and the bird's
+// page on the locale's Wikipedia. The dawn chorus line is English-only and left out.
+func (g *BasicFactGenerator) generateLocalizedTranscript(ctx context.Context, bird *models.Bird) *ScriptTranscript {
+	var builder transcriptBuilder
+
+	// Look up by scientific name first: other wikis rarely have a page under the English name
+	birdName := bird.CommonName
+	description := ""
+	for _, query := range []string{bird.ScientificName, bird.CommonName} {
+		if query == "" {
+			continue
+		}
+		if summary, err := g.wiki.GetBirdSummary(ctx, query); err == nil && summary.Extract != "" {
+			description = summary.Extract
+			if summary.Title != "" && !strings.EqualFold(summary.Title, bird.ScientificName) {
+				birdName = summary.Title
+			}
+			break
+		}
+	}
+
+	if bird.ScientificName != "" {
+		builder.add(fmt.Sprintf(g.text.ScientificName, birdName, bird.ScientificName), SourceCuratedBank, "available_birds")
+		builder.add(g.text.DidYouKnow, SourceTemplate, "transition")
+	} else {
+		builder.add(fmt.Sprintf(g.text.AmazingBirdIntro, birdName), SourceTemplate, "intro")
+	}
+
+	if sentences := splitSentences(description); len(sentences) > 0 {
+		if len(sentences) > 2 {
+			sentences = sentences[:2]
+		}
+		builder.add(strings.Join(sentences, " "), SourceWikipedia, "summary")
+	} else {
+		builder.add(fmt.Sprintf(g.text.FallbackFact, birdName), SourceTemplate, "fallback_fact")
+	}
+	builder.add(g.text.GenericFacts[g.rng.Intn(len(g.text.GenericFacts))], SourceCuratedBank, "generic_bird_facts")
+
+	if bird.ScientificName != "" {
+		builder.add(g.text.ClosingScientific, SourceTemplate, "closing")
+	} else {
+		builder.add(g.text.ClosingPlain, SourceTemplate, "closing")
+	}
+
+	return builder.transcript(bird.CommonName, g.GetGeneratorType())
+}
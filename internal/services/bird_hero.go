@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/inaturalist"
+)
+
+const (
+	// conservationStatusMaxAge is how long a species' status is reused; IUCN assessments change rarely
+	conservationStatusMaxAge = 7 * 24 * time.Hour
+	birdHeroMaxCached        = 50
+)
+
+// threatenedStatusNames are the IUCN statuses that get a bird hero chapter, in kid terms
+var threatenedStatusNames = map[string]string{
+	"VU": "vulnerable",
+	"EN": "endangered",
+	"CR": "critically endangered",
+}
+
+// IsThreatenedStatus reports whether an IUCN status code is vulnerable, endangered, or critically endangered
+func IsThreatenedStatus(status string) bool {
+	_, threatened := threatenedStatusNames[strings.ToUpper(status)]
+	return threatened
+}
+
+// whyRare explains each threatened status for children
+var whyRare = map[string]string{
+	"VU": "There aren't as many of them as there used to be, because the wild places they need for food and nests keep getting smaller.",
+	"EN": "Only a small number are left in the wild. Their homes are disappearing, and they have fewer safe places to raise their chicks.",
+	"CR": "It is one of the rarest birds on Earth, and only a few are left. Scientists and bird helpers are working very hard to keep every one of them safe.",
+}
+
+// heroActions are the concrete things a child can do; each bird always gets the same one
+var heroActions = []string{
+	"Ask a grown-up to help you plant flowers and bushes that grow naturally where you live, so birds have food and places to hide.",
+	"Put stickers on big windows at home, so birds can see the glass and don't bump into it.",
+	"If you have a cat, ask your family to keep it indoors, where it stays safe and birds do too.",
+	"When you're at the beach or the park, pick up litter with a grown-up, so birds don't get tangled or eat it by mistake.",
+	"Stay on the path when you're exploring nature, so nests hidden on the ground stay safe.",
+	"Put out a shallow dish of clean water, and change it every day, so thirsty birds have somewhere to drink and splash.",
+}
+
+// birdHeroScript is the bird hero chapter's narration
+var birdHeroScript = template.Must(template.New("bird_hero").Parse(
+	"Time to be a bird hero! Scientists say the {{.BirdName}} is {{.StatusName}}. {{.WhyRare}} " +
+		"But you can help. {{.Action}} " +
+		"Every little thing you do makes the world a safer place for the {{.BirdName}}. Thank you, bird hero!"))
+
+// BirdHeroStory is the bird hero chapter for a threatened species
+type BirdHeroStory struct {
+	Bird   string `json:"bird"`
+	Status string `json:"status"` // IUCN code: VU, EN, or CR
+	Script string `json:"script"`
+	Audio  []byte `json:"-"`
+}
+
+type cachedConservationStatus struct {
+	status    string
+	failed    bool
+	fetchedAt time.Time
+}
+
+// BirdHeroGuide looks up species' conservation status on iNaturalist and narrates why threatened
+// birds are rare and one thing children can do to help
+type BirdHeroGuide struct {
+	inatClient *inaturalist.Client
+	tts        *ElevenLabsTTS
+
+	mu       sync.Mutex
+	statuses map[string]cachedConservationStatus // lowercased bird name -> IUCN code, "" when unlisted
+	stories  map[string]*BirdHeroStory           // bird and voice -> rendered story
+}
+
+// NewBirdHeroGuide creates a bird hero guide narrating with ElevenLabs
+func NewBirdHeroGuide(tts *ElevenLabsTTS) *BirdHeroGuide {
+	return &BirdHeroGuide{
+		inatClient: inaturalist.NewClient(),
+		tts:        tts,
+		statuses:   make(map[string]cachedConservationStatus),
+		stories:    make(map[string]*BirdHeroStory),
+	}
+}
+
+// ThreatenedStatus returns the bird's IUCN status code when iNaturalist lists it as vulnerable,
+// endangered, or critically endangered. Failed lookups are treated as not threatened and retried
+// after an hour; cancelled ones aren't cached.
+func (bg *BirdHeroGuide) ThreatenedStatus(ctx context.Context, birdName string) (string, bool) {
+	key := strings.ToLower(birdName)
+
+	bg.mu.Lock()
+	cached, ok := bg.statuses[key]
+	bg.mu.Unlock()
+	maxAge := conservationStatusMaxAge
+	if cached.failed {
+		maxAge = time.Hour
+	}
+	if !ok || time.Since(cached.fetchedAt) >= maxAge {
+		cached = cachedConservationStatus{fetchedAt: time.Now()}
+		taxon, err := bg.inatClient.SearchTaxon(ctx, birdName)
+		if ctx.Err() != nil {
+			return "", false
+		}
+		if err != nil {
+			slog.Warn("[BIRD_HERO] Conservation status lookup failed", "bird", birdName, "error", err)
+			cached.failed = true
+		} else if taxon.ConservationStatus != nil {
+			cached.status = strings.ToUpper(taxon.ConservationStatus.Status)
+		}
+
+		bg.mu.Lock()
+		bg.statuses[key] = cached
+		bg.mu.Unlock()
+	}
+
+	return cached.status, IsThreatenedStatus(cached.status)
+}
+
+// GenerateStory returns the bird hero narration for a threatened bird, reusing a rendered story
+// for the same bird and voice
+func (bg *BirdHeroGuide) GenerateStory(ctx context.Context, birdName string, voiceID string) (*BirdHeroStory, error) {
+	status, threatened := bg.ThreatenedStatus(ctx, birdName)
+	if !threatened {
+		return nil, fmt.Errorf("%s is not threatened", birdName)
+	}
+
+	key := strings.ToLower(birdName) + "|" + voiceID
+	bg.mu.Lock()
+	cached, ok := bg.stories[key]
+	bg.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	script, err := BuildBirdHeroScript(birdName, status)
+	if err != nil {
+		return nil, err
+	}
+	story := &BirdHeroStory{Bird: birdName, Status: status, Script: script}
+	if story.Audio, _, err = bg.tts.Render(ctx, story.Script, voiceID); err != nil {
+		return nil, fmt.Errorf("failed to render bird hero script: %w", err)
+	}
+
+	bg.mu.Lock()
+	if len(bg.stories) >= birdHeroMaxCached {
+		bg.stories = make(map[string]*BirdHeroStory)
+	}
+	bg.stories[key] = story
+	bg.mu.Unlock()
+
+	slog.InfoContext(ctx, "[BIRD_HERO] Generated story", "bird", birdName, "status", status, "bytes", len(story.Audio))
+	return story, nil
+}
+
+// BuildBirdHeroScript returns the narration explaining why the bird is rare and one way to help
+func BuildBirdHeroScript(birdName string, status string) (string, error) {
+	status = strings.ToUpper(status)
+	statusName, threatened := threatenedStatusNames[status]
+	if !threatened {
+		return "", fmt.Errorf("status %q is not threatened", status)
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.ToLower(birdName)))
+
+	var buf bytes.Buffer
+	err := birdHeroScript.Execute(&buf, struct {
+		BirdName, StatusName, WhyRare, Action string
+	}{birdName, statusName, whyRare[status], heroActions[hash.Sum32()%uint32(len(heroActions))]})
+	if err != nil {
+		return "", fmt.Errorf("failed to build bird hero script: %w", err)
+	}
+	return buf.String(), nil
+}
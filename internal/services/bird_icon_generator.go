@@ -0,0 +1,232 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Photo decoders
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Generated icon layout: a 16x16 Yoto display icon with a transparent background
+const (
+	generatedIconSize = 16
+	// Pixels this close (squared RGB distance) to the photo's border colour become background
+	iconBackgroundThreshold = 48 * 48 * 3
+	iconMaxPhotoBytes       = 8 << 20
+)
+
+// pixelIconPalette is the PICO-8 palette; its saturated colours stay readable on the tiny display.
+// Index 0 is transparent background.
+var pixelIconPalette = color.Palette{
+	color.RGBA{0x00, 0x00, 0x00, 0x00},
+	color.RGBA{0x00, 0x00, 0x00, 0xff},
+	color.RGBA{0x1d, 0x2b, 0x53, 0xff},
+	color.RGBA{0x7e, 0x25, 0x53, 0xff},
+	color.RGBA{0x00, 0x87, 0x51, 0xff},
+	color.RGBA{0xab, 0x52, 0x36, 0xff},
+	color.RGBA{0x5f, 0x57, 0x4f, 0xff},
+	color.RGBA{0xc2, 0xc3, 0xc7, 0xff},
+	color.RGBA{0xff, 0xf1, 0xe8, 0xff},
+	color.RGBA{0xff, 0x00, 0x4d, 0xff},
+	color.RGBA{0xff, 0xa3, 0x00, 0xff},
+	color.RGBA{0xff, 0xec, 0x27, 0xff},
+	color.RGBA{0x00, 0xe4, 0x36, 0xff},
+	color.RGBA{0x29, 0xad, 0xff, 0xff},
+	color.RGBA{0x83, 0x76, 0x9c, 0xff},
+	color.RGBA{0xff, 0x77, 0xa8, 0xff},
+	color.RGBA{0xff, 0xcc, 0xaa, 0xff},
+}
+
+// BirdIconGenerator renders a 16x16 pixel-art icon from a photo of the bird, so species without
+// a hand-drawn icon still get one of their own instead of the generic bird
+type BirdIconGenerator struct {
+	photos     *PhotoFetcher
+	httpClient *http.Client
+	cacheDir   string
+
+	mu     sync.Mutex
+	failed map[string]time.Time // Birds without a usable photo, retried after a day
+}
+
+// NewBirdIconGenerator creates a generator that renders icons from the photo fetcher's photos
+func NewBirdIconGenerator(photos *PhotoFetcher) *BirdIconGenerator {
+	return &BirdIconGenerator{
+		photos:     photos,
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 15 * time.Second}),
+		cacheDir:   filepath.Join(os.TempDir(), "bird_icons"),
+		failed:     make(map[string]time.Time),
+	}
+}
+
+// IconForBird returns the path to a generated PNG icon for the bird, rendering it on first use.
+// It returns "" when no photo can be found or decoded, so callers keep the generic icon.
+func (g *BirdIconGenerator) IconForBird(ctx context.Context, birdName string) string {
+	dirName := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	outputPath := filepath.Join(g.cacheDir, dirName+"_16x16.png")
+	if _, err := os.Stat(outputPath); err == nil {
+		return outputPath
+	}
+
+	g.mu.Lock()
+	failedAt, failed := g.failed[dirName]
+	g.mu.Unlock()
+	if failed && time.Since(failedAt) < 24*time.Hour {
+		return ""
+	}
+
+	photo, source, err := g.fetchPhoto(ctx, birdName)
+	if ctx.Err() != nil {
+		return ""
+	}
+	if err != nil {
+		slog.Warn("[ICON_GENERATOR] No usable photo, using generic icon", "bird", birdName, "error", err)
+		g.mu.Lock()
+		g.failed[dirName] = time.Now()
+		g.mu.Unlock()
+		return ""
+	}
+
+	var icon bytes.Buffer
+	if err := png.Encode(&icon, PixelIcon(photo)); err != nil {
+		slog.Error("[ICON_GENERATOR] Failed to encode PNG", "bird", birdName, "error", err)
+		return ""
+	}
+	// Written atomically so a concurrent update never uploads a half-written icon
+	if err := filex.AtomicWriteFile(outputPath, icon.Bytes(), 0644); err != nil {
+		slog.Error("[ICON_GENERATOR] Failed to save icon", "path", outputPath, "error", err)
+		return ""
+	}
+
+	slog.Info("[ICON_GENERATOR] Generated pixel icon", "bird", birdName, "source", source)
+	return outputPath
+}
+
+// fetchPhoto downloads the photo the photo fetcher found for the bird
+func (g *BirdIconGenerator) fetchPhoto(ctx context.Context, birdName string) (image.Image, string, error) {
+	photo, err := g.photos.PhotoForBird(ctx, birdName)
+	if err != nil {
+		return nil, "", err
+	}
+	img, err := g.downloadImage(ctx, photo.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	return img, photo.Source, nil
+}
+
+func (g *BirdIconGenerator) downloadImage(ctx context.Context, imageURL string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "BirdSongExplorer/1.0 (https://github.com/callen/bird-song-explorer)")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download photo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("photo download returned status %d", resp.StatusCode)
+	}
+
+	photo, _, err := image.Decode(io.LimitReader(resp.Body, iconMaxPhotoBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
+	}
+	return photo, nil
+}
+
+// PixelIcon turns a photo into a 16x16 pixel-art icon: the centre square is box-averaged down to
+// 16x16, pixels matching the photo's border colour are dropped as background, and the rest is
+// quantized to a small fixed palette
+func PixelIcon(photo image.Image) *image.Paletted {
+	bounds := photo.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Point{
+		X: bounds.Min.X + (bounds.Dx()-side)/2,
+		Y: bounds.Min.Y + (bounds.Dy()-side)/2,
+	})
+
+	var cells [generatedIconSize][generatedIconSize][3]float64
+	for y := 0; y < generatedIconSize; y++ {
+		for x := 0; x < generatedIconSize; x++ {
+			cell := image.Rect(
+				crop.Min.X+x*side/generatedIconSize, crop.Min.Y+y*side/generatedIconSize,
+				crop.Min.X+(x+1)*side/generatedIconSize, crop.Min.Y+(y+1)*side/generatedIconSize,
+			)
+			cells[y][x] = averageColor(photo, cell)
+		}
+	}
+
+	// The border ring is mostly sky, water, or foliage, so its average stands in for the background
+	var background [3]float64
+	var borderCells float64
+	for i := 0; i < generatedIconSize; i++ {
+		for _, c := range [][3]float64{cells[0][i], cells[generatedIconSize-1][i], cells[i][0], cells[i][generatedIconSize-1]} {
+			for ch := range background {
+				background[ch] += c[ch]
+			}
+			borderCells++
+		}
+	}
+	for ch := range background {
+		background[ch] /= borderCells
+	}
+
+	icon := image.NewPaletted(image.Rect(0, 0, generatedIconSize, generatedIconSize), pixelIconPalette)
+	opaque := pixelIconPalette[1:]
+	for y := 0; y < generatedIconSize; y++ {
+		for x := 0; x < generatedIconSize; x++ {
+			c := cells[y][x]
+			if colorDistance(c, background) < iconBackgroundThreshold {
+				continue // Index 0 is transparent
+			}
+			pixel := color.RGBA{uint8(c[0]), uint8(c[1]), uint8(c[2]), 0xff}
+			icon.SetColorIndex(x, y, uint8(opaque.Index(pixel)+1))
+		}
+	}
+	return icon
+}
+
+// averageColor returns the mean 8-bit RGB of the pixels in rect
+func averageColor(img image.Image, rect image.Rectangle) [3]float64 {
+	var sum [3]float64
+	var n float64
+	for y := rect.Min.Y; y < max(rect.Max.Y, rect.Min.Y+1); y++ {
+		for x := rect.Min.X; x < max(rect.Max.X, rect.Min.X+1); x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum[0] += float64(r >> 8)
+			sum[1] += float64(g >> 8)
+			sum[2] += float64(b >> 8)
+			n++
+		}
+	}
+	for ch := range sum {
+		sum[ch] /= n
+	}
+	return sum
+}
+
+func colorDistance(a, b [3]float64) float64 {
+	var d float64
+	for ch := range a {
+		d += (a[ch] - b[ch]) * (a[ch] - b[ch])
+	}
+	return d
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// BirdPin fixes the bird a region plays on a date instead of the rotation bird
+type BirdPin struct {
+	Region   string    `json:"region"`
+	Date     string    `json:"date"` // YYYY-MM-DD
+	BirdName string    `json:"bird_name"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// BlockedSpecies is a species the rotation skips, e.g. one that repeatedly lacks usable recordings
+type BlockedSpecies struct {
+	CommonName string    `json:"common_name"`
+	Reason     string    `json:"reason,omitempty"`
+	BlockedAt  time.Time `json:"blocked_at"`
+}
+
+type birdOverridesState struct {
+	Pins      map[string]*BirdPin        `json:"pins"`      // "region_date" -> pin
+	Blocklist map[string]*BlockedSpecies `json:"blocklist"` // lowercased common name -> entry
+}
+
+// BirdOverrides holds admin pins and the species blocklist, persisted to a JSON file
+type BirdOverrides struct {
+	mu    sync.RWMutex
+	path  string
+	state birdOverridesState
+}
+
+// NewBirdOverrides loads overrides from disk, starting empty if the file doesn't exist
+func NewBirdOverrides(path string) *BirdOverrides {
+	if path == "" {
+		path = "data/bird_overrides.json"
+	}
+
+	overrides := &BirdOverrides{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &overrides.state); err != nil {
+			log.Printf("[OVERRIDES] Failed to parse %s, starting empty: %v", path, err)
+			overrides.state = birdOverridesState{}
+		}
+	}
+	if overrides.state.Pins == nil {
+		overrides.state.Pins = make(map[string]*BirdPin)
+	}
+	if overrides.state.Blocklist == nil {
+		overrides.state.Blocklist = make(map[string]*BlockedSpecies)
+	}
+
+	return overrides
+}
+
+// Pin sets the bird for a region and date, replacing any earlier pin
+func (bo *BirdOverrides) Pin(region, date, birdName string) (BirdPin, error) {
+	pin := BirdPin{
+		Region:   region,
+		Date:     date,
+		BirdName: birdName,
+		PinnedAt: time.Now().UTC(),
+	}
+
+	bo.mu.Lock()
+	bo.state.Pins[region+"_"+date] = &pin
+	bo.mu.Unlock()
+
+	log.Printf("[OVERRIDES] Pinned %s for %s on %s", birdName, region, date)
+	return pin, bo.save()
+}
+
+// Unpin removes a region's pin for a date, reporting whether there was one
+func (bo *BirdOverrides) Unpin(region, date string) (bool, error) {
+	bo.mu.Lock()
+	_, exists := bo.state.Pins[region+"_"+date]
+	delete(bo.state.Pins, region+"_"+date)
+	bo.mu.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	return true, bo.save()
+}
+
+// PinnedBird returns the bird pinned for a region and date
+func (bo *BirdOverrides) PinnedBird(region, date string) (string, bool) {
+	bo.mu.RLock()
+	defer bo.mu.RUnlock()
+
+	pin, exists := bo.state.Pins[region+"_"+date]
+	if !exists {
+		return "", false
+	}
+	return pin.BirdName, true
+}
+
+// Pins returns every pin, ordered by date then region
+func (bo *BirdOverrides) Pins() []BirdPin {
+	bo.mu.RLock()
+	defer bo.mu.RUnlock()
+
+	pins := make([]BirdPin, 0, len(bo.state.Pins))
+	for _, pin := range bo.state.Pins {
+		pins = append(pins, *pin)
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		if pins[i].Date != pins[j].Date {
+			return pins[i].Date < pins[j].Date
+		}
+		return pins[i].Region < pins[j].Region
+	})
+	return pins
+}
+
+// Block adds a species to the blocklist
+func (bo *BirdOverrides) Block(commonName, reason string) (BlockedSpecies, error) {
+	entry := BlockedSpecies{
+		CommonName: commonName,
+		Reason:     reason,
+		BlockedAt:  time.Now().UTC(),
+	}
+
+	bo.mu.Lock()
+	bo.state.Blocklist[strings.ToLower(commonName)] = &entry
+	bo.mu.Unlock()
+
+	log.Printf("[OVERRIDES] Blocked %s: %s", commonName, reason)
+	return entry, bo.save()
+}
+
+// Unblock removes a species from the blocklist, reporting whether it was listed
+func (bo *BirdOverrides) Unblock(commonName string) (bool, error) {
+	key := strings.ToLower(commonName)
+
+	bo.mu.Lock()
+	_, exists := bo.state.Blocklist[key]
+	delete(bo.state.Blocklist, key)
+	bo.mu.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	return true, bo.save()
+}
+
+// IsBlocked reports whether a species is on the blocklist
+func (bo *BirdOverrides) IsBlocked(commonName string) bool {
+	bo.mu.RLock()
+	defer bo.mu.RUnlock()
+
+	_, blocked := bo.state.Blocklist[strings.ToLower(commonName)]
+	return blocked
+}
+
+// Blocklist returns every blocked species, sorted by name
+func (bo *BirdOverrides) Blocklist() []BlockedSpecies {
+	bo.mu.RLock()
+	defer bo.mu.RUnlock()
+
+	blocked := make([]BlockedSpecies, 0, len(bo.state.Blocklist))
+	for _, entry := range bo.state.Blocklist {
+		blocked = append(blocked, *entry)
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].CommonName < blocked[j].CommonName
+	})
+	return blocked
+}
+
+// save writes the overrides to disk atomically
+func (bo *BirdOverrides) save() error {
+	bo.mu.RLock()
+	data, err := json.MarshalIndent(bo.state, "", "  ")
+	bo.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal overrides: %w", err)
+	}
+	return filex.AtomicWriteFile(bo.path, data, 0644)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
+
+// Rarity is how often a species turns up in a region lately
+type Rarity string
+
+const (
+	RarityCommon   Rarity = "common"
+	RarityUncommon Rarity = "uncommon"
+	RarityRare     Rarity = "rare"
+	RarityAbsent   Rarity = "absent" // Not reported on any sampled day
+)
+
+const (
+	// A region's frequencies come from its daily species lists for every other day of the last
+	// four weeks, refreshed daily
+	raritySampleDays    = 14
+	raritySampleSpacing = 2
+	rarityMaxAge        = 24 * time.Hour
+
+	// Reported on at least half the sampled days is common; on fewer than one in six is rare
+	commonFrequency = 0.5
+	rareFrequency   = 1.0 / 6
+)
+
+// SpeciesFrequency is how many of a region's sampled days a species was reported on
+type SpeciesFrequency struct {
+	DaysReported int
+	DaysSampled  int
+}
+
+// Frequency is the share of sampled days the species was reported on
+func (f SpeciesFrequency) Frequency() float64 {
+	if f.DaysSampled == 0 {
+		return 0
+	}
+	return float64(f.DaysReported) / float64(f.DaysSampled)
+}
+
+// Rarity classifies the frequency as common, uncommon, rare, or absent
+func (f SpeciesFrequency) Rarity() Rarity {
+	switch frequency := f.Frequency(); {
+	case f.DaysReported == 0:
+		return RarityAbsent
+	case frequency >= commonFrequency:
+		return RarityCommon
+	case frequency >= rareFrequency:
+		return RarityUncommon
+	default:
+		return RarityRare
+	}
+}
+
+// IsSpecialGuestDay reports whether t's calendar date is the region's special guest day. Each week
+// draws its own day, so guests don't always fall on the same weekday.
+func IsSpecialGuestDay(region string, t time.Time) bool {
+	day := randx.Daily(WeekStart(t), "special_guest:"+region).Intn(7)
+	return (int(t.Weekday())+6)%7 == day
+}
+
+// weeksSinceEpoch counts Monday-to-Sunday weeks to the calendar date of t. The epoch fell on a
+// Thursday, so its week is shifted to start on the Monday before.
+func weeksSinceEpoch(t time.Time) int64 {
+	return (daysSinceEpoch(t) + 3) / 7
+}
+
+// SpecialGuestRegion returns the eBird region a place's birds are rated in, and its name: the
+// country's region pack region when the pack has one, otherwise the country itself (eBird uses
+// ISO country codes). The code is "" when the country is unknown.
+func SpecialGuestRegion(place *Place) (code string, name string) {
+	if place == nil || place.CountryCode == "" {
+		return "", ""
+	}
+	if pack := RegionPackForCountry(place.CountryCode); pack != nil && pack.EBirdRegion != "" && pack.EBirdRegion != place.CountryCode {
+		return pack.EBirdRegion, pack.Name
+	}
+	return place.CountryCode, place.Country
+}
+
+// RegionFrequencies estimates how often species are reported in eBird regions, from the species
+// each region's birders reported on a sample of recent days. Regions are sampled on first use.
+type RegionFrequencies struct {
+	client *ebird.Client
+
+	mu      sync.Mutex
+	regions map[string]regionFrequencies
+}
+
+type regionFrequencies struct {
+	daysReported map[string]int // Keyed on lower-case scientific name
+	daysSampled  int
+	fetchedAt    time.Time
+}
+
+var (
+	sharedFrequenciesMu sync.Mutex
+	sharedFrequencies   *RegionFrequencies
+)
+
+// SharedRegionFrequencies returns the process-wide frequency cache. The first non-empty API key
+// creates it; an empty key returns the cache if one was created, and nil otherwise, so fact
+// scripts only rate birds when special guests are enabled.
+func SharedRegionFrequencies(apiKey string) *RegionFrequencies {
+	sharedFrequenciesMu.Lock()
+	defer sharedFrequenciesMu.Unlock()
+
+	if sharedFrequencies == nil && apiKey != "" {
+		sharedFrequencies = NewRegionFrequencies(apiKey)
+	}
+	return sharedFrequencies
+}
+
+// NewRegionFrequencies creates a frequency cache using the eBird API key
+func NewRegionFrequencies(apiKey string) *RegionFrequencies {
+	return &RegionFrequencies{
+		client:  ebird.NewClient(apiKey),
+		regions: make(map[string]regionFrequencies),
+	}
+}
+
+// Frequency returns how often the species was reported in the region. known is false when too
+// few of the region's sampled days could be fetched.
+func (r *RegionFrequencies) Frequency(regionCode string, scientificName string) (frequency SpeciesFrequency, known bool) {
+	region := r.region(regionCode)
+	if region.daysReported == nil {
+		return SpeciesFrequency{}, false
+	}
+	return SpeciesFrequency{
+		DaysReported: region.daysReported[strings.ToLower(scientificName)],
+		DaysSampled:  region.daysSampled,
+	}, true
+}
+
+// Rarity classifies the species in the region, with known false when its frequency isn't known
+func (r *RegionFrequencies) Rarity(regionCode string, scientificName string) (Rarity, bool) {
+	frequency, known := r.Frequency(regionCode, scientificName)
+	if !known {
+		return "", false
+	}
+	return frequency.Rarity(), true
+}
+
+// region returns the region's cached sample, fetching it when it's missing or stale. A failed
+// sample is retried after an hour.
+func (r *RegionFrequencies) region(regionCode string) regionFrequencies {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	region, cached := r.regions[regionCode]
+	maxAge := rarityMaxAge
+	if cached && region.daysReported == nil {
+		maxAge = time.Hour
+	}
+	if !cached || time.Since(region.fetchedAt) >= maxAge {
+		region = r.sample(regionCode)
+		r.regions[regionCode] = region
+	}
+	return region
+}
+
+// sample counts the days each species was reported on over the sampled days before today
+func (r *RegionFrequencies) sample(regionCode string) regionFrequencies {
+	region := regionFrequencies{fetchedAt: time.Now()}
+	daysReported := make(map[string]int)
+	today := time.Now().UTC()
+
+	for i := 1; i <= raritySampleDays; i++ {
+		day := today.AddDate(0, 0, -i*raritySampleSpacing)
+		// The sample is shared by every caller, so one caller's cancellation shouldn't cut it short
+		observations, err := r.client.GetHistoricObservations(context.Background(), regionCode, day)
+		if err != nil {
+			log.Printf("[RARITY] Failed to fetch %s species for %s: %v", regionCode, day.Format("2006-01-02"), err)
+			continue
+		}
+		region.daysSampled++
+
+		reported := make(map[string]bool, len(observations))
+		for _, observation := range observations {
+			name := strings.ToLower(observation.ScientificName)
+			if name != "" && !reported[name] {
+				reported[name] = true
+				daysReported[name]++
+			}
+		}
+	}
+
+	if region.daysSampled*2 < raritySampleDays {
+		log.Printf("[RARITY] Only %d of %d %s days could be sampled", region.daysSampled, raritySampleDays, regionCode)
+		return region
+	}
+	region.daysReported = daysReported
+	log.Printf("[RARITY] Sampled %d species over %d days in %s", len(daysReported), region.daysSampled, regionCode)
+	return region
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,8 +10,13 @@ import (
 	"time"
 
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
+// regionalCheckClient makes the eBird region lookups
+var regionalCheckClient = httpx.NewClient(httpx.Options{Timeout: 10 * time.Second})
+
 // BirdRegionalChecker checks if a bird has been spotted near a user's location
 type BirdRegionalChecker struct {
 	ebirdAPIKey string
@@ -23,20 +29,20 @@ func NewBirdRegionalChecker(ebirdAPIKey string) *BirdRegionalChecker {
 }
 
 // IsRegionalBird checks if a bird has been spotted within radius km of location in the last days
-func (c *BirdRegionalChecker) IsRegionalBird(birdName string, location *models.Location, radiusKm int, days int) (bool, error) {
+func (c *BirdRegionalChecker) IsRegionalBird(ctx context.Context, birdName string, location *models.Location, radiusKm int, days int) (bool, error) {
 	if location == nil {
 		return false, fmt.Errorf("location is nil")
 	}
 
 	// Get species code for the bird
-	speciesCode, err := c.getSpeciesCode(birdName)
+	speciesCode, err := c.getSpeciesCode(ctx, birdName)
 	if err != nil {
 		log.Printf("[REGIONAL] Failed to get species code for %s: %v", birdName, err)
 		return false, err
 	}
 
 	// Check recent observations near the location
-	hasObservations, err := c.checkRecentObservations(speciesCode, location.Latitude, location.Longitude, radiusKm, days)
+	hasObservations, err := c.checkRecentObservations(ctx, speciesCode, location.Latitude, location.Longitude, radiusKm, days)
 	if err != nil {
 		log.Printf("[REGIONAL] Failed to check observations for %s: %v", birdName, err)
 		return false, err
@@ -46,19 +52,18 @@ func (c *BirdRegionalChecker) IsRegionalBird(birdName string, location *models.L
 }
 
 // getSpeciesCode gets the eBird species code for a common name
-func (c *BirdRegionalChecker) getSpeciesCode(commonName string) (string, error) {
+func (c *BirdRegionalChecker) getSpeciesCode(ctx context.Context, commonName string) (string, error) {
 	// eBird taxonomy search endpoint
 	apiURL := fmt.Sprintf("https://api.ebird.org/v2/ref/taxonomy/ebird?fmt=json&locale=en&q=%s",
 		url.QueryEscape(commonName))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("X-eBirdApiToken", c.ebirdAPIKey)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := regionalCheckClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -82,30 +87,12 @@ func (c *BirdRegionalChecker) getSpeciesCode(commonName string) (string, error)
 }
 
 // checkRecentObservations checks if there are recent observations of the species near the location
-func (c *BirdRegionalChecker) checkRecentObservations(speciesCode string, lat, lng float64, radiusKm, days int) (bool, error) {
-	// eBird recent observations endpoint
-	apiURL := fmt.Sprintf("https://api.ebird.org/v2/data/obs/geo/recent/%s?lat=%.4f&lng=%.4f&dist=%d&back=%d",
-		speciesCode, lat, lng, radiusKm, days)
-
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return false, err
-	}
-	req.Header.Set("X-eBirdApiToken", c.ebirdAPIKey)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+func (c *BirdRegionalChecker) checkRecentObservations(ctx context.Context, speciesCode string, lat, lng float64, radiusKm, days int) (bool, error) {
+	// Shares the eBird client's rate limit and daily cache with the other lookups
+	observations, err := ebird.NewClient(c.ebirdAPIKey).GetRecentSpeciesObservations(ctx, speciesCode, lat, lng, radiusKm, days)
 	if err != nil {
 		return false, err
 	}
-	defer resp.Body.Close()
-
-	// We just need to know if there are any observations
-	var observations []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&observations); err != nil {
-		// If we can't decode, assume no observations
-		return false, nil
-	}
 
 	hasObservations := len(observations) > 0
 	
@@ -1,10 +1,11 @@
 package services
 
 import (
-	"math"
+	"context"
 	"strings"
 
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services/factkit"
 	"github.com/callen/bird-song-explorer/pkg/ebird"
 )
 
@@ -21,14 +22,14 @@ func NewBirdRegionalMatcher(ebirdAPIKey string) *BirdRegionalMatcher {
 }
 
 // IsBirdInRegion checks if a bird has been seen near the given location
-func (brm *BirdRegionalMatcher) IsBirdInRegion(bird *models.Bird, latitude, longitude float64) (bool, *RegionalInfo) {
+func (brm *BirdRegionalMatcher) IsBirdInRegion(ctx context.Context, bird *models.Bird, latitude, longitude float64) (bool, *RegionalInfo) {
 	// If no eBird client, can't check
 	if brm.ebirdClient == nil {
 		return false, nil
 	}
 
 	// Get recent observations within 50km
-	observations, err := brm.ebirdClient.GetRecentObservations(latitude, longitude, 30)
+	observations, err := brm.ebirdClient.GetRecentObservations(ctx, latitude, longitude, 30)
 	if err != nil {
 		// If API fails, return false but don't error
 		return false, nil
@@ -45,7 +46,7 @@ func (brm *BirdRegionalMatcher) IsBirdInRegion(bird *models.Bird, latitude, long
 			sightingCount++
 
 			// Calculate distance
-			dist := calculateDistance(latitude, longitude, obs.Latitude, obs.Longitude)
+			dist := factkit.DistanceMiles(latitude, longitude, obs.Latitude, obs.Longitude)
 			if dist < nearestDistance {
 				nearestDistance = dist
 			}
@@ -134,22 +135,6 @@ type BirdRange struct {
 	Habitat string
 }
 
-// Helper function to calculate distance between two coordinates
-func calculateDistance(lat1, lng1, lat2, lng2 float64) float64 {
-	const earthRadius = 3959.0 // miles
-
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLng := (lng2 - lng1) * math.Pi / 180
-
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
-			math.Sin(dLng/2)*math.Sin(dLng/2)
-
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-
-	return earthRadius * c
-}
-
 // Helper function to guess habitat from bird name
 func getHabitatFromName(name string) string {
 	if strings.Contains(name, "forest") || strings.Contains(name, "wood") {
@@ -37,7 +37,8 @@ type BirdSize struct {
 
 // BirdStorage handles bird data storage and retrieval
 type BirdStorage struct {
-	basePath string
+	basePath      string
+	transcriptDir string // Where script transcripts are kept instead of alongside the narration
 }
 
 // NewBirdStorage creates a new BirdStorage instance
@@ -50,6 +51,11 @@ func NewBirdStorage(basePath string) *BirdStorage {
 	}
 }
 
+// SetTranscriptDir keeps script transcripts under dir rather than alongside each bird's narration
+func (bs *BirdStorage) SetTranscriptDir(dir string) {
+	bs.transcriptDir = dir
+}
+
 // GetBirdMetadata retrieves metadata for a specific bird
 func (bs *BirdStorage) GetBirdMetadata(birdName string) (*BirdMetadata, error) {
 	// Convert bird name to directory format (lowercase, underscores)
@@ -161,3 +167,26 @@ func (bs *BirdStorage) GetBirdsByHabitat(habitat string) ([]string, error) {
 
 	return birds, nil
 }
+
+// ListAllBirdMetadata returns metadata for every bird in the global species directory
+func (bs *BirdStorage) ListAllBirdMetadata() ([]*BirdMetadata, error) {
+	var all []*BirdMetadata
+
+	globalPath := filepath.Join(bs.basePath, "_global_species")
+	entries, err := ioutil.ReadDir(globalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global species directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			metadata, err := bs.GetBirdMetadata(entry.Name())
+			if err != nil {
+				continue
+			}
+			all = append(all, metadata)
+		}
+	}
+
+	return all, nil
+}
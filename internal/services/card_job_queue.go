@@ -0,0 +1,493 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+const (
+	// maxCardJobAttempts is how many times a card update is tried before it's abandoned
+	maxCardJobAttempts = 8
+	// maxCardJobAge drops jobs that couldn't finish within a day; by then a newer bird is due
+	maxCardJobAge = 24 * time.Hour
+)
+
+// Card update triggers
+const (
+	CardJobScheduled = "scheduled"
+	CardJobWebhook   = "webhook"
+)
+
+// ErrCardJobRunning is returned by Run when the job is already being processed
+var ErrCardJobRunning = errors.New("card update already in progress")
+
+// ErrCardJobWaiting is returned by a job's processing when it's waiting on work elsewhere, such
+// as a slow Yoto transcode. The job is checked again after the retry delay without counting as a
+// failed attempt.
+var ErrCardJobWaiting = errors.New("card update waiting")
+
+// ErrCardJobAbort is returned by a job's processing when retrying can't help, such as a card that
+// no longer exists. The job is abandoned at once instead of using up its remaining attempts.
+var ErrCardJobAbort = errors.New("card update can't succeed")
+
+// Card update stages a failure is reported at
+const (
+	CardStageUpdate    = "update"    // Failed somewhere that didn't say which stage
+	CardStagePublish   = "publish"   // Posting the card content to Yoto
+	CardStageVerify    = "verify"    // Reading the card back after posting it
+	CardStageTranscode = "transcode" // Waiting on Yoto to transcode uploaded audio
+)
+
+// CardJobError is a failed card update with the stage it failed at
+type CardJobError struct {
+	Stage      string
+	Err        error
+	RetryAfter time.Duration // Waits at least this long before the next attempt, as a rate limit asked
+}
+
+func (e *CardJobError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CardJobError) Unwrap() error {
+	return e.Err
+}
+
+// cardJobRetryAfter returns the wait a failed card update asked for, or 0
+func cardJobRetryAfter(err error) time.Duration {
+	var jobErr *CardJobError
+	if errors.As(err, &jobErr) {
+		return jobErr.RetryAfter
+	}
+	return 0
+}
+
+// CardJobStage returns the stage a card update failed at
+func CardJobStage(err error) string {
+	var jobErr *CardJobError
+	if errors.As(err, &jobErr) && jobErr.Stage != "" {
+		return jobErr.Stage
+	}
+	return CardStageUpdate
+}
+
+// CardJob is a durable "update card X with bird Y" request. Checkpoints record the steps that
+// already finished (uploaded icons, posted content) so a retry resumes instead of starting over.
+type CardJob struct {
+	ID          string            `json:"id"`
+	CardID      string            `json:"card_id"`
+	Day         string            `json:"day"`
+	Trigger     string            `json:"trigger"`
+	BirdName    string            `json:"bird_name"`
+	Region      string            `json:"region,omitempty"`    // Species pool override from the device's profile
+	DeviceID    string            `json:"device_id,omitempty"` // Device whose webhook queued the job
+	Mode        string            `json:"mode,omitempty"`      // ContentModeNight for the night variant; "" is day
+	BaseURL     string            `json:"base_url"`
+	RequestID   string            `json:"request_id,omitempty"` // Correlation ID of the request that queued the job
+	Checkpoints map[string]string `json:"checkpoints,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Attempts    int               `json:"attempts"`
+	NextAttempt time.Time         `json:"next_attempt"`
+	LastError   string            `json:"last_error,omitempty"`
+	LastStage   string            `json:"last_stage,omitempty"`
+}
+
+// CardJobID identifies a card's update for a day, so repeated triggers share one job. Night
+// variants are separate jobs from the day's update.
+func CardJobID(cardID, day, trigger, deviceID, mode string) string {
+	if mode == ContentModeNight {
+		return fmt.Sprintf("%s|%s|%s|%s|%s", cardID, day, trigger, deviceID, mode)
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", cardID, day, trigger, deviceID)
+}
+
+// CardJobQueue persists card update jobs to a JSON file until they succeed. Jobs are usually run
+// straight away by the request that created them; a failed job stays queued and the background
+// consumer retries it with a growing delay, resuming from its checkpoints.
+type CardJobQueue struct {
+	mu         sync.Mutex
+	path       string
+	jobs       []*CardJob
+	running    map[string]bool
+	retryDelay time.Duration
+	wake       chan struct{}
+	started    bool
+	onAbandon  func(CardJob)
+}
+
+// NewCardJobQueue loads pending jobs from path (data/card_jobs.json by default)
+func NewCardJobQueue(path string, retryDelay time.Duration) *CardJobQueue {
+	if path == "" {
+		path = "data/card_jobs.json"
+	}
+	if retryDelay <= 0 {
+		retryDelay = 30 * time.Second
+	}
+
+	queue := &CardJobQueue{
+		path:       path,
+		running:    make(map[string]bool),
+		retryDelay: retryDelay,
+		wake:       make(chan struct{}, 1),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &queue.jobs); err != nil {
+			log.Printf("[CARD_JOBS] Failed to parse %s, starting empty: %v", path, err)
+			queue.jobs = nil
+		}
+	}
+	if len(queue.jobs) > 0 {
+		log.Printf("[CARD_JOBS] Recovered %d unfinished card updates", len(queue.jobs))
+	}
+
+	return queue
+}
+
+// Enqueue stores a job and returns it. When a job with the same ID is still pending, that job
+// is returned instead, keeping its checkpoints.
+func (q *CardJobQueue) Enqueue(job CardJob) (CardJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if existing := q.find(job.ID); existing != nil {
+		return copyCardJob(existing), nil
+	}
+
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now().UTC()
+	}
+	job.NextAttempt = job.CreatedAt
+	if job.Checkpoints == nil {
+		job.Checkpoints = make(map[string]string)
+	}
+
+	q.jobs = append(q.jobs, &job)
+	if err := q.save(); err != nil {
+		q.jobs = q.jobs[:len(q.jobs)-1]
+		return CardJob{}, err
+	}
+	return copyCardJob(&job), nil
+}
+
+// Run processes a pending job now. A failure leaves the job queued for the consumer to retry.
+func (q *CardJobQueue) Run(id string, process func(CardJob) error) error {
+	q.mu.Lock()
+	job := q.find(id)
+	if job == nil {
+		q.mu.Unlock()
+		return fmt.Errorf("card job %s not found", id)
+	}
+	if q.running[id] {
+		q.mu.Unlock()
+		return ErrCardJobRunning
+	}
+	q.running[id] = true
+	snapshot := copyCardJob(job)
+	q.mu.Unlock()
+
+	err := process(snapshot)
+	q.complete(id, err)
+	return err
+}
+
+// OnAbandon sets a function called with each job the queue gives up on, after its last attempt
+// fails or it grows too old. It runs on its own goroutine.
+func (q *CardJobQueue) OnAbandon(abandoned func(CardJob)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onAbandon = abandoned
+}
+
+// abandon reports a job the queue is dropping; the caller holds q.mu
+func (q *CardJobQueue) abandon(job *CardJob) {
+	if q.onAbandon != nil {
+		go q.onAbandon(copyCardJob(job))
+	}
+}
+
+// Start runs the retry consumer in the background
+func (q *CardJobQueue) Start(process func(CardJob) error) {
+	q.mu.Lock()
+	if q.started {
+		q.mu.Unlock()
+		return
+	}
+	q.started = true
+	q.mu.Unlock()
+
+	go q.consume(process)
+}
+
+// consume retries due jobs, sleeping until the next one is due or a job fails
+func (q *CardJobQueue) consume(process func(CardJob) error) {
+	for {
+		id, wait := q.next()
+		if id == "" {
+			select {
+			case <-q.wake:
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if err := q.Run(id, process); err != nil && !errors.Is(err, ErrCardJobRunning) {
+			log.Printf("[CARD_JOBS] Retry of %s failed: %v", id, err)
+		}
+	}
+}
+
+// next returns the ID of the oldest due job that isn't running, or how long to wait for one.
+// Jobs past their age limit are dropped.
+func (q *CardJobQueue) next() (string, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	kept := q.jobs[:0]
+	for _, job := range q.jobs {
+		if time.Since(job.CreatedAt) > maxCardJobAge && !q.running[job.ID] {
+			log.Printf("[CARD_JOBS] Dropping %s, it's more than %v old (last error: %s)", job.ID, maxCardJobAge, job.LastError)
+			q.abandon(job)
+			continue
+		}
+		kept = append(kept, job)
+	}
+	if len(kept) != len(q.jobs) {
+		q.jobs = kept
+		if err := q.save(); err != nil {
+			log.Printf("[CARD_JOBS] Failed to save jobs: %v", err)
+		}
+	}
+
+	wait := time.Minute
+	sort.SliceStable(q.jobs, func(i, j int) bool {
+		return q.jobs[i].NextAttempt.Before(q.jobs[j].NextAttempt)
+	})
+	for _, job := range q.jobs {
+		if q.running[job.ID] {
+			continue
+		}
+		if until := time.Until(job.NextAttempt); until > 0 {
+			if until < wait {
+				wait = until
+			}
+			return "", wait
+		}
+		return job.ID, 0
+	}
+	return "", wait
+}
+
+// complete removes a finished job, or schedules a retry when it failed
+func (q *CardJobQueue) complete(id string, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.running, id)
+	for i, job := range q.jobs {
+		if job.ID != id {
+			continue
+		}
+
+		if err == nil {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+
+		job.LastError = err.Error()
+		job.LastStage = CardJobStage(err)
+		retryAfter := cardJobRetryAfter(err)
+		if errors.Is(err, ErrCardJobWaiting) {
+			delay := max(q.retryDelay, retryAfter)
+			job.NextAttempt = time.Now().UTC().Add(delay)
+			log.Printf("[CARD_JOBS] %s is waiting, checking again in %v: %v", id, delay, err)
+			break
+		}
+
+		job.Attempts++
+		if errors.Is(err, ErrCardJobAbort) {
+			log.Printf("[CARD_JOBS] Abandoning %s, retrying can't help: %v", id, err)
+			q.abandon(job)
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+		if job.Attempts >= maxCardJobAttempts {
+			log.Printf("[CARD_JOBS] Abandoning %s after %d attempts: %v", id, job.Attempts, err)
+			q.abandon(job)
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+
+		delay := max(q.retryDelay*time.Duration(job.Attempts), retryAfter)
+		job.NextAttempt = time.Now().UTC().Add(delay)
+		log.Printf("[CARD_JOBS] %s failed (attempt %d, %d steps checkpointed), retrying in %v: %v", id, job.Attempts, len(job.Checkpoints), delay, err)
+		break
+	}
+
+	if err := q.save(); err != nil {
+		log.Printf("[CARD_JOBS] Failed to save jobs: %v", err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Checkpoints returns the step checkpoints of a job, for the content manager to resume from
+func (q *CardJobQueue) Checkpoints(id string) *CardJobCheckpoints {
+	return &CardJobCheckpoints{queue: q, id: id}
+}
+
+// Pending returns the unfinished jobs, oldest first
+func (q *CardJobQueue) Pending() []CardJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]CardJob, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, copyCardJob(job))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Stats returns queue depth for monitoring
+func (q *CardJobQueue) Stats() map[string]interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	retrying := 0
+	for _, job := range q.jobs {
+		if job.Attempts > 0 {
+			retrying++
+		}
+	}
+	return map[string]interface{}{
+		"pending":  len(q.jobs),
+		"retrying": retrying,
+		"running":  len(q.running),
+	}
+}
+
+// find returns the pending job with the ID; the caller holds q.mu
+func (q *CardJobQueue) find(id string) *CardJob {
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// save writes the jobs to disk atomically. Callers hold q.mu.
+func (q *CardJobQueue) save() error {
+	return filex.AtomicWriteJSON(q.path, q.jobs)
+}
+
+// copyCardJob returns a copy that doesn't share the checkpoint map
+func copyCardJob(job *CardJob) CardJob {
+	snapshot := *job
+	snapshot.Checkpoints = make(map[string]string, len(job.Checkpoints))
+	for step, value := range job.Checkpoints {
+		snapshot.Checkpoints[step] = value
+	}
+	return snapshot
+}
+
+// CardJobCheckpoints reads and records one job's step checkpoints, persisting each as it's saved
+type CardJobCheckpoints struct {
+	queue *CardJobQueue
+	id    string
+}
+
+// Checkpoint returns a finished step's recorded result
+func (c *CardJobCheckpoints) Checkpoint(step string) (string, bool) {
+	c.queue.mu.Lock()
+	defer c.queue.mu.Unlock()
+
+	job := c.queue.find(c.id)
+	if job == nil {
+		return "", false
+	}
+	value, ok := job.Checkpoints[step]
+	return value, ok
+}
+
+// SaveCheckpoint records a finished step and writes it to disk
+func (c *CardJobCheckpoints) SaveCheckpoint(step string, value string) error {
+	c.queue.mu.Lock()
+	defer c.queue.mu.Unlock()
+
+	job := c.queue.find(c.id)
+	if job == nil {
+		return fmt.Errorf("card job %s not found", c.id)
+	}
+	if job.Checkpoints == nil {
+		job.Checkpoints = make(map[string]string)
+	}
+	job.Checkpoints[step] = value
+	return c.queue.save()
+}
+
+// CardJobStep is background work making up one step of a card job, such as rendering its
+// narration. A step an earlier attempt finished runs no work; otherwise it's checkpointed once
+// all of its work has succeeded.
+type CardJobStep struct {
+	checkpoints *CardJobCheckpoints
+	name        string
+	done        bool
+	wg          sync.WaitGroup
+	failed      atomic.Bool
+}
+
+// Step starts one of the job's steps
+func (c *CardJobCheckpoints) Step(name string) *CardJobStep {
+	_, done := c.Checkpoint(name)
+	return &CardJobStep{checkpoints: c, name: name, done: done}
+}
+
+// Done reports whether an earlier attempt finished the step
+func (s *CardJobStep) Done() bool {
+	return s != nil && s.done
+}
+
+// Go runs work in the background as part of the step, unless an earlier attempt finished it. A
+// nil step runs the work untracked.
+func (s *CardJobStep) Go(work func() error) {
+	if s == nil {
+		go work()
+		return
+	}
+	if s.done {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := work(); err != nil {
+			s.failed.Store(true)
+		}
+	}()
+}
+
+// Finish waits for the step's work and checkpoints the step when all of it succeeded
+func (s *CardJobStep) Finish() error {
+	s.wg.Wait()
+	if s.done || s.failed.Load() {
+		return nil
+	}
+	s.done = true
+	return s.checkpoints.SaveCheckpoint(s.name, time.Now().UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+)
+
+// fakeCardJob stands in for a card job's paid steps, fetching the song and rendering two pieces
+// of narration, then posts the content with the given result
+type fakeCardJob struct {
+	songFetches atomic.Int32
+	ttsRenders  atomic.Int32
+	failRender  bool
+}
+
+func (f *fakeCardJob) process(queue *CardJobQueue, post error) func(CardJob) error {
+	return func(job CardJob) error {
+		checkpoints := queue.Checkpoints(job.ID)
+		song := checkpoints.Step(yoto.StepSongFetched)
+		tts := checkpoints.Step(yoto.StepTTSRendered)
+		song.Go(func() error {
+			f.songFetches.Add(1)
+			return nil
+		})
+		for i := 0; i < 2; i++ {
+			failed := f.failRender && i == 1
+			tts.Go(func() error {
+				f.ttsRenders.Add(1)
+				if failed {
+					return errors.New("ElevenLabs unavailable")
+				}
+				return nil
+			})
+		}
+		for _, step := range []*CardJobStep{song, tts} {
+			if err := step.Finish(); err != nil {
+				return err
+			}
+		}
+		return post
+	}
+}
+
+func TestResumedCardJobSkipsFetchedSongAndRenderedTTS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "card_jobs.json")
+	queue := NewCardJobQueue(path, time.Millisecond)
+	job, err := queue.Enqueue(CardJob{ID: "card1|2026-01-05|scheduled|", CardID: "card1", BirdName: "Bald Eagle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeCardJob{}
+	if err := queue.Run(job.ID, fake.process(queue, errors.New("content post failed"))); err == nil {
+		t.Fatal("the failed post wasn't reported")
+	}
+
+	// A restarted server picks the job up from disk
+	resumed := NewCardJobQueue(path, time.Millisecond)
+	if err := resumed.Run(job.ID, fake.process(resumed, nil)); err != nil {
+		t.Fatalf("resumed job failed: %v", err)
+	}
+	if fetches := fake.songFetches.Load(); fetches != 1 {
+		t.Errorf("song fetched %d times, want once", fetches)
+	}
+	if renders := fake.ttsRenders.Load(); renders != 2 {
+		t.Errorf("%d TTS renders, want the first attempt's 2", renders)
+	}
+	if pending := resumed.Pending(); len(pending) != 0 {
+		t.Errorf("%d jobs still queued after the resumed job succeeded", len(pending))
+	}
+}
+
+func TestCardJobStepWithFailedWorkIsRunAgain(t *testing.T) {
+	queue := NewCardJobQueue(filepath.Join(t.TempDir(), "card_jobs.json"), time.Millisecond)
+	job, err := queue.Enqueue(CardJob{ID: "card1|2026-01-05|scheduled|", CardID: "card1", BirdName: "Bald Eagle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeCardJob{failRender: true}
+	queue.Run(job.ID, fake.process(queue, errors.New("content post failed")))
+
+	checkpoints := queue.Checkpoints(job.ID)
+	if _, ok := checkpoints.Checkpoint(yoto.StepSongFetched); !ok {
+		t.Error("the fetched song wasn't checkpointed")
+	}
+	if _, ok := checkpoints.Checkpoint(yoto.StepTTSRendered); ok {
+		t.Error("TTS was checkpointed though one render failed")
+	}
+
+	fake.failRender = false
+	if err := queue.Run(job.ID, fake.process(queue, nil)); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if fetches, renders := fake.songFetches.Load(), fake.ttsRenders.Load(); fetches != 1 || renders != 4 {
+		t.Errorf("%d song fetches and %d renders, want the song once and the narration rendered again", fetches, renders)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Seasons a cover can be chosen for
+const (
+	SeasonSpring = "spring"
+	SeasonSummer = "summer"
+	SeasonAutumn = "autumn"
+	SeasonWinter = "winter"
+)
+
+// CoverArtwork is one card cover in the rotation. Yoto fetches the image through the media API
+// and converts it to a cover when a card update uses it.
+type CoverArtwork struct {
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+	Months []int  `json:"months,omitempty"` // 1-12; the cover for those months
+	Season string `json:"season,omitempty"` // "spring", "summer", "autumn", or "winter" in the card's hemisphere
+	// Artwork with neither months nor a season is the year-round cover for dates nothing else covers
+}
+
+// CoverManager rotates card covers through an artwork set: each date gets the artwork listing
+// its month, then the artwork for its season, then the year-round artwork
+type CoverManager struct {
+	artwork []CoverArtwork
+}
+
+// NewCoverManager loads a JSON list of cover artwork from path. An empty path, or one that can't
+// be read, leaves rotation off and cards keep their covers.
+func NewCoverManager(path string) *CoverManager {
+	manager := &CoverManager{}
+	if path == "" {
+		return manager
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("[COVERS] Failed to read cover artwork %s: %v, cover rotation is off", path, err)
+		return manager
+	}
+
+	var artwork []CoverArtwork
+	if err := json.Unmarshal(data, &artwork); err != nil {
+		log.Printf("[COVERS] Failed to parse cover artwork %s: %v, cover rotation is off", path, err)
+		return manager
+	}
+	manager.artwork = validCoverArtwork(artwork)
+	log.Printf("[COVERS] Rotating %d covers from %s", len(manager.artwork), path)
+	return manager
+}
+
+// validCoverArtwork drops artwork without an image or with months or a season that don't parse
+func validCoverArtwork(artwork []CoverArtwork) []CoverArtwork {
+	valid := make([]CoverArtwork, 0, len(artwork))
+	for _, cover := range artwork {
+		if cover.URL == "" {
+			log.Printf("[COVERS] Skipping cover %s: no image URL", cover.Key)
+			continue
+		}
+		if cover.Season != "" && !isSeason(cover.Season) {
+			log.Printf("[COVERS] Skipping cover %s: unknown season %q", cover.Key, cover.Season)
+			continue
+		}
+		badMonth := false
+		for _, month := range cover.Months {
+			badMonth = badMonth || month < 1 || month > 12
+		}
+		if badMonth {
+			log.Printf("[COVERS] Skipping cover %s: months must be 1-12", cover.Key)
+			continue
+		}
+		valid = append(valid, cover)
+	}
+	return valid
+}
+
+// Enabled reports whether there is any artwork to rotate
+func (cm *CoverManager) Enabled() bool {
+	return len(cm.artwork) > 0
+}
+
+// CoverOn returns the artwork for a date, with seasons turned around for the southern hemisphere
+func (cm *CoverManager) CoverOn(date time.Time, southern bool) (*CoverArtwork, bool) {
+	month := int(date.Month())
+	season := SeasonOf(date.Month(), southern)
+
+	var seasonal, yearRound *CoverArtwork
+	for i := range cm.artwork {
+		cover := &cm.artwork[i]
+		for _, coverMonth := range cover.Months {
+			if coverMonth == month {
+				return cover, true
+			}
+		}
+		if seasonal == nil && strings.EqualFold(cover.Season, season) {
+			seasonal = cover
+		}
+		if yearRound == nil && len(cover.Months) == 0 && cover.Season == "" {
+			yearRound = cover
+		}
+	}
+
+	if seasonal != nil {
+		return seasonal, true
+	}
+	return yearRound, yearRound != nil
+}
+
+// SeasonOf names the meteorological season a month falls in
+func SeasonOf(month time.Month, southern bool) string {
+	if southern {
+		month = (month+5)%12 + 1 // Six months on
+	}
+	switch {
+	case month >= time.March && month <= time.May:
+		return SeasonSpring
+	case month >= time.June && month <= time.August:
+		return SeasonSummer
+	case month >= time.September && month <= time.November:
+		return SeasonAutumn
+	default:
+		return SeasonWinter
+	}
+}
+
+// isSeason reports whether name is one of the four seasons
+func isSeason(name string) bool {
+	switch strings.ToLower(name) {
+	case SeasonSpring, SeasonSummer, SeasonAutumn, SeasonWinter:
+		return true
+	}
+	return false
+}
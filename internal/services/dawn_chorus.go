@@ -0,0 +1,139 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Solar zenith angles: sunrise includes refraction and the sun's radius; the dawn chorus
+// usually begins around civil dawn, when the sun is 6 degrees below the horizon
+const (
+	sunriseZenith   = 90.833
+	civilDawnZenith = 96.0
+)
+
+// dawnChorusOn is whether guide scripts include the dawn chorus countdown line
+var dawnChorusOn atomic.Bool
+
+// ConfigureDawnChorus turns the dawn chorus countdown line in guide scripts on or off
+func ConfigureDawnChorus(enabled bool) {
+	dawnChorusOn.Store(enabled)
+}
+
+// dawnChorusEnabled reports whether guide scripts include the dawn chorus countdown line
+func dawnChorusEnabled() bool {
+	return dawnChorusOn.Load()
+}
+
+// Sunrise returns sunrise on a local calendar date at a location.
+// The second value is false during polar day or night when the sun doesn't rise.
+func Sunrise(lat, lng float64, date time.Time, loc *time.Location) (time.Time, bool) {
+	return localSolarEvent(lat, lng, date, loc, sunriseZenith)
+}
+
+// DawnChorusStart returns when the dawn chorus begins (civil dawn) on a local calendar date
+func DawnChorusStart(lat, lng float64, date time.Time, loc *time.Location) (time.Time, bool) {
+	return localSolarEvent(lat, lng, date, loc, civilDawnZenith)
+}
+
+// localSolarEvent computes a morning solar event and moves it onto the requested local day,
+// since the UTC calculation can land on the neighbouring day for far east or west longitudes
+func localSolarEvent(lat, lng float64, date time.Time, loc *time.Location, zenith float64) (time.Time, bool) {
+	event, ok := solarEventUTC(lat, lng, date, zenith)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	local := event.In(loc)
+	target := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	switch {
+	case local.Before(target):
+		local = local.Add(24 * time.Hour)
+	case !local.Before(target.AddDate(0, 0, 1)):
+		local = local.Add(-24 * time.Hour)
+	}
+	return local, true
+}
+
+// DawnChorusLine returns the countdown line for tomorrow's dawn chorus at the listener's location,
+// or "" when the location is unknown or the sun doesn't rise there tomorrow
+func DawnChorusLine(lat, lng float64, now time.Time, loc *time.Location) string {
+	if lat == 0 && lng == 0 {
+		return ""
+	}
+
+	local := now.In(loc)
+	tomorrow := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+	start, ok := DawnChorusStart(lat, lng, tomorrow, loc)
+	if !ok {
+		return ""
+	}
+
+	// Round to the minute so the narration doesn't promise seconds
+	start = start.Add(30 * time.Second).Truncate(time.Minute)
+	return fmt.Sprintf("Tomorrow the dawn chorus starts around %s - can you wake up to hear it?", start.Format("3:04 PM"))
+}
+
+// dawnChorusLocation picks the time zone for a listener, falling back to solar time when the
+// coordinate lookup only knows UTC
+func dawnChorusLocation(lat, lng float64) *time.Location {
+	if lookup, err := NewTimezoneLookupService(); err == nil {
+		if loc := lookup.GetTimezone(lat, lng); loc.String() != "UTC" || math.Abs(lng) < 7.5 {
+			return loc
+		}
+	}
+	return time.FixedZone("solar", int(math.Round(lng/15))*3600)
+}
+
+// dawnChorusSentence is the line guide generators add when the feature is enabled
+func dawnChorusSentence(lat, lng float64) string {
+	if !dawnChorusEnabled() {
+		return ""
+	}
+	return DawnChorusLine(lat, lng, time.Now(), dawnChorusLocation(lat, lng))
+}
+
+// solarEventUTC implements the Almanac for Computers sunrise algorithm for the given zenith
+func solarEventUTC(lat, lng float64, date time.Time, zenith float64) (time.Time, bool) {
+	rad := math.Pi / 180
+
+	dayOfYear := float64(date.YearDay())
+	lngHour := lng / 15
+	t := dayOfYear + (6-lngHour)/24
+
+	// Sun's mean anomaly and true longitude
+	meanAnomaly := 0.9856*t - 3.289
+	trueLongitude := normalizeDegrees(meanAnomaly + 1.916*math.Sin(meanAnomaly*rad) +
+		0.020*math.Sin(2*meanAnomaly*rad) + 282.634)
+
+	// Right ascension, moved into the same quadrant as the true longitude
+	rightAscension := normalizeDegrees(math.Atan(0.91764*math.Tan(trueLongitude*rad)) / rad)
+	rightAscension += math.Floor(trueLongitude/90)*90 - math.Floor(rightAscension/90)*90
+	rightAscension /= 15
+
+	sinDec := 0.39782 * math.Sin(trueLongitude*rad)
+	cosDec := math.Cos(math.Asin(sinDec))
+
+	cosHourAngle := (math.Cos(zenith*rad) - sinDec*math.Sin(lat*rad)) / (cosDec * math.Cos(lat*rad))
+	if cosHourAngle > 1 || cosHourAngle < -1 {
+		return time.Time{}, false
+	}
+
+	hourAngle := (360 - math.Acos(cosHourAngle)/rad) / 15
+	localMeanTime := hourAngle + rightAscension - 0.06571*t - 6.622
+	utcHours := math.Mod(localMeanTime-lngHour+48, 24)
+
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return midnight.Add(time.Duration(utcHours * float64(time.Hour))), true
+}
+
+// normalizeDegrees wraps an angle into [0, 360)
+func normalizeDegrees(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSunriseAcrossHemispheres(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng float64
+		zone     string
+		date     string
+		want     string // Local sunrise "15:04"; "" when the sun doesn't rise
+	}{
+		{"London midsummer", 51.5074, -0.1278, "Europe/London", "2024-06-21", "04:43"},
+		{"London midwinter", 51.5074, -0.1278, "Europe/London", "2024-12-21", "08:04"},
+		{"Honolulu June", 21.3069, -157.8583, "Pacific/Honolulu", "2024-06-21", "05:50"},
+		{"Quito equinox", -0.1807, -78.4678, "America/Guayaquil", "2024-03-20", "06:17"},
+		{"Sydney midwinter", -33.8688, 151.2093, "Australia/Sydney", "2024-06-21", "07:00"},
+		{"Sydney midsummer", -33.8688, 151.2093, "Australia/Sydney", "2024-12-21", "05:41"},
+		{"Auckland midsummer", -36.8485, 174.7633, "Pacific/Auckland", "2024-12-21", "05:58"},
+		{"Ushuaia midsummer", -54.8019, -68.3030, "America/Argentina/Ushuaia", "2024-12-21", "04:51"},
+		{"Ushuaia midwinter", -54.8019, -68.3030, "America/Argentina/Ushuaia", "2024-06-21", "09:58"},
+		{"Tromso polar day", 69.6492, 18.9553, "Europe/Oslo", "2024-06-21", ""},
+		{"Tromso polar night", 69.6492, 18.9553, "Europe/Oslo", "2024-12-21", ""},
+		{"McMurdo polar day", -77.8460, 166.6760, "Antarctica/McMurdo", "2024-12-21", ""},
+		{"McMurdo polar night", -77.8460, 166.6760, "Antarctica/McMurdo", "2024-06-21", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Skipf("time zone %s not available: %v", tt.zone, err)
+			}
+			date, _ := time.ParseInLocation("2006-01-02", tt.date, loc)
+
+			sunrise, ok := Sunrise(tt.lat, tt.lng, date, loc)
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("Sunrise() = %v, want no sunrise", sunrise)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Sunrise() found no sunrise, want %s", tt.want)
+			}
+
+			want, _ := time.ParseInLocation("2006-01-02 15:04", tt.date+" "+tt.want, loc)
+			if diff := sunrise.Sub(want); diff < -3*time.Minute || diff > 3*time.Minute {
+				t.Errorf("Sunrise() = %s, want %s within 3 minutes", sunrise.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+func TestDawnChorusStartsBeforeSunrise(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng float64
+		zone     string
+	}{
+		{"northern", 51.5074, -0.1278, "Europe/London"},
+		{"southern", -33.8688, 151.2093, "Australia/Sydney"},
+		{"far east of UTC", -36.8485, 174.7633, "Pacific/Auckland"},
+		{"far west of UTC", 21.3069, -157.8583, "Pacific/Honolulu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Skipf("time zone %s not available: %v", tt.zone, err)
+			}
+			for _, day := range []string{"2024-03-20", "2024-06-21", "2024-09-22", "2024-12-21"} {
+				date, _ := time.ParseInLocation("2006-01-02", day, loc)
+				dawn, ok := DawnChorusStart(tt.lat, tt.lng, date, loc)
+				sunrise, sunriseOK := Sunrise(tt.lat, tt.lng, date, loc)
+				if !ok || !sunriseOK {
+					t.Fatalf("%s: no dawn (%v) or sunrise (%v)", day, ok, sunriseOK)
+				}
+				if !dawn.Before(sunrise) {
+					t.Errorf("%s: dawn chorus %s isn't before sunrise %s", day, dawn.Format("15:04"), sunrise.Format("15:04"))
+				}
+				if got := dawn.Format("2006-01-02"); got != day {
+					t.Errorf("%s: dawn chorus falls on %s", day, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDawnChorusLine(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+	evening := time.Date(2024, time.June, 20, 19, 0, 0, 0, london)
+
+	line := DawnChorusLine(51.5074, -0.1278, evening, london)
+	if !strings.Contains(line, "3:55 AM") {
+		t.Errorf("DawnChorusLine() = %q, want tomorrow's 3:55 AM start", line)
+	}
+
+	if line := DawnChorusLine(0, 0, evening, london); line != "" {
+		t.Errorf("DawnChorusLine() without a location = %q, want none", line)
+	}
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+	if line := DawnChorusLine(78.2232, 15.6267, time.Date(2024, time.June, 20, 19, 0, 0, 0, oslo), oslo); line != "" {
+		t.Errorf("DawnChorusLine() in polar day = %q, want none", line)
+	}
+}
+
+func TestDawnChorusSentenceFollowsConfig(t *testing.T) {
+	t.Cleanup(func() { ConfigureDawnChorus(false) })
+
+	ConfigureDawnChorus(false)
+	if sentence := dawnChorusSentence(51.5074, -0.1278); sentence != "" {
+		t.Errorf("dawnChorusSentence() while disabled = %q, want none", sentence)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+)
+
+// DefaultLocationResolver supplies fallback coordinates when IP and timezone lookups fail.
+// Defaults can be set per deployment and per card; when neither is configured the resolver
+// reports "no location" so callers select from a continent-level species pool instead of
+// biasing every failed lookup toward one hardcoded region.
+type DefaultLocationResolver struct {
+	deployment *models.Location
+	perCard    map[string]*models.Location
+}
+
+// NewDefaultLocationResolver parses deployment and per-card default locations.
+// deploymentSpec is "lat,lon" and cardSpec is "cardID=lat,lon;cardID2=lat,lon".
+func NewDefaultLocationResolver(deploymentSpec string, cardSpec string) *DefaultLocationResolver {
+	resolver := &DefaultLocationResolver{
+		perCard: make(map[string]*models.Location),
+	}
+
+	if deploymentSpec != "" {
+		location, err := parseCoordinates(deploymentSpec)
+		if err != nil {
+			log.Printf("[DEFAULT_LOCATION] Ignoring invalid DEFAULT_LOCATION %q: %v", deploymentSpec, err)
+		} else {
+			resolver.deployment = location
+		}
+	}
+
+	for _, entry := range strings.Split(cardSpec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			log.Printf("[DEFAULT_LOCATION] Ignoring invalid card default %q (expected cardID=lat,lon)", entry)
+			continue
+		}
+
+		location, err := parseCoordinates(parts[1])
+		if err != nil {
+			log.Printf("[DEFAULT_LOCATION] Ignoring invalid card default %q: %v", entry, err)
+			continue
+		}
+		resolver.perCard[strings.TrimSpace(parts[0])] = location
+	}
+
+	return resolver
+}
+
+// Resolve returns the most specific configured default for a card.
+// The boolean is false in "no location" mode, when nothing is configured.
+func (r *DefaultLocationResolver) Resolve(cardID string) (*models.Location, bool) {
+	if location, ok := r.perCard[cardID]; ok {
+		copy := *location
+		return &copy, true
+	}
+
+	if r.deployment != nil {
+		copy := *r.deployment
+		return &copy, true
+	}
+
+	return nil, false
+}
+
+// HasDefaults reports whether any default location is configured
+func (r *DefaultLocationResolver) HasDefaults() bool {
+	return r.deployment != nil || len(r.perCard) > 0
+}
+
+// parseCoordinates parses a "lat,lon" pair
+func parseCoordinates(spec string) (*models.Location, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected lat,lon")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude %q", parts[0])
+	}
+
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude %q", parts[1])
+	}
+
+	return &models.Location{
+		Latitude:  lat,
+		Longitude: lon,
+	}, nil
+}
+
+// southAmericanTimezones are the "America/" zones south of Panama; the rest are North America's
+var southAmericanTimezones = map[string]bool{
+	"America/Araguaina": true, "America/Asuncion": true, "America/Bahia": true, "America/Belem": true,
+	"America/Boa_Vista": true, "America/Bogota": true, "America/Buenos_Aires": true, "America/Campo_Grande": true,
+	"America/Caracas": true, "America/Cayenne": true, "America/Cuiaba": true, "America/Eirunepe": true,
+	"America/Fortaleza": true, "America/Guayaquil": true, "America/Guyana": true, "America/La_Paz": true,
+	"America/Lima": true, "America/Maceio": true, "America/Manaus": true, "America/Montevideo": true,
+	"America/Noronha": true, "America/Paramaribo": true, "America/Porto_Velho": true, "America/Punta_Arenas": true,
+	"America/Recife": true, "America/Rio_Branco": true, "America/Santarem": true, "America/Santiago": true,
+	"America/Sao_Paulo": true,
+}
+
+// ContinentForTimezone maps an IANA timezone to the continent-level region used by AvailableBird.Regions
+func ContinentForTimezone(timezone string) string {
+	switch {
+	case southAmericanTimezones[timezone], strings.HasPrefix(timezone, "America/Argentina/"),
+		strings.HasPrefix(timezone, "Brazil/"), strings.HasPrefix(timezone, "Chile/"):
+		return "south_america"
+	case strings.HasPrefix(timezone, "America/"), strings.HasPrefix(timezone, "US/"), strings.HasPrefix(timezone, "Canada/"):
+		return "north_america"
+	case strings.HasPrefix(timezone, "Europe/"):
+		return "europe"
+	case strings.HasPrefix(timezone, "Asia/"):
+		return "asia"
+	case strings.HasPrefix(timezone, "Australia/"), strings.HasPrefix(timezone, "Pacific/Auckland"), strings.HasPrefix(timezone, "NZ"):
+		return "oceania"
+	default:
+		return "global"
+	}
+}
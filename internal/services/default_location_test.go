@@ -0,0 +1,21 @@
+package services
+
+import "testing"
+
+func TestContinentForTimezone(t *testing.T) {
+	tests := map[string]string{
+		"America/New_York":               "north_america",
+		"America/Mexico_City":            "north_america",
+		"America/Sao_Paulo":              "south_america",
+		"America/Lima":                   "south_america",
+		"America/Argentina/Buenos_Aires": "south_america",
+		"Europe/London":                  "europe",
+		"Australia/Sydney":               "oceania",
+		"Africa/Nairobi":                 "global",
+	}
+	for timezone, want := range tests {
+		if got := ContinentForTimezone(timezone); got != want {
+			t.Errorf("ContinentForTimezone(%q) = %q, want %q", timezone, got, want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Dependency health states
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // Working, with reduced features or recent failures
+	HealthDown     = "down"
+	HealthDisabled = "disabled" // Not configured, so the features it backs are off
+)
+
+// Dependencies the service reports on
+const (
+	DependencyYotoAuth   = "yoto_auth"
+	DependencyElevenLabs = "elevenlabs"
+	DependencyEBird      = "ebird"
+	DependencyXenoCanto  = "xeno_canto"
+	DependencyFFmpeg     = "ffmpeg"
+	DependencyAssetStore = "asset_store"
+)
+
+// Live-narrated chapters a degradation policy can leave off cards; the names match the card
+// template segments
+const (
+	ChapterQuiz     = "quiz"
+	ChapterHotspots = "hotspots"
+	ChapterBirdHero = "bird_hero"
+	ChapterCounting = "listen_count"
+)
+
+// chapterDependencies are the dependencies each optional chapter can't be made without
+var chapterDependencies = map[string][]string{
+	ChapterQuiz:     {DependencyElevenLabs, DependencyEBird, DependencyXenoCanto},
+	ChapterHotspots: {DependencyElevenLabs, DependencyEBird},
+	ChapterBirdHero: {DependencyElevenLabs},
+	ChapterCounting: {DependencyElevenLabs, DependencyXenoCanto},
+}
+
+// DependencyStatus is one dependency's health
+type DependencyStatus struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Critical bool   `json:"critical"` // The instance isn't ready while a critical dependency is down
+}
+
+// HealthCheck reports a dependency's current health. Checks run on every health request, so
+// they read local state (credentials, circuit breakers, quotas) rather than calling out.
+type HealthCheck func() DependencyStatus
+
+// DegradationPolicy is the set of fallbacks active while dependencies are unhealthy. Card
+// updates consult it instead of discovering each outage through its own failed call.
+type DegradationPolicy struct {
+	DeferCardUpdates  bool     `json:"defer_card_updates"`         // Yoto is unusable; queued updates wait for it
+	SkippedChapters   []string `json:"skipped_chapters,omitempty"` // Optional chapters left off cards
+	SkipNormalization bool     `json:"skip_normalization"`         // Audio is uploaded without loudness normalization
+	Reasons           []string `json:"reasons,omitempty"`
+}
+
+// Active reports whether any fallback is in effect
+func (p DegradationPolicy) Active() bool {
+	return len(p.Reasons) > 0
+}
+
+// SkipsChapter reports whether the chapter should be left off cards
+func (p DegradationPolicy) SkipsChapter(chapter string) bool {
+	for _, skipped := range p.SkippedChapters {
+		if skipped == chapter {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterChapters returns the chapters the policy doesn't skip
+func (p DegradationPolicy) FilterChapters(chapters []string) []string {
+	kept := make([]string, 0, len(chapters))
+	for _, chapter := range chapters {
+		if !p.SkipsChapter(chapter) {
+			kept = append(kept, chapter)
+		}
+	}
+	return kept
+}
+
+// DependencyMonitor runs the registered health checks and derives the degradation policy
+type DependencyMonitor struct {
+	mu     sync.Mutex
+	checks []HealthCheck
+}
+
+// NewDependencyMonitor creates a monitor with no checks
+func NewDependencyMonitor() *DependencyMonitor {
+	return &DependencyMonitor{}
+}
+
+// Register adds a dependency's health check
+func (m *DependencyMonitor) Register(check HealthCheck) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks = append(m.checks, check)
+}
+
+// Statuses runs every check, sorted by dependency name
+func (m *DependencyMonitor) Statuses() []DependencyStatus {
+	m.mu.Lock()
+	checks := append([]HealthCheck(nil), m.checks...)
+	m.mu.Unlock()
+
+	statuses := make([]DependencyStatus, 0, len(checks))
+	for _, check := range checks {
+		statuses = append(statuses, check())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// Ready reports whether no critical dependency is down
+func (m *DependencyMonitor) Ready(statuses []DependencyStatus) bool {
+	for _, status := range statuses {
+		if status.Critical && status.Status == HealthDown {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy returns the fallbacks called for by the dependencies' current health
+func (m *DependencyMonitor) Policy() DegradationPolicy {
+	return PolicyFor(m.Statuses())
+}
+
+// PolicyFor derives the degradation policy from dependency statuses
+func PolicyFor(statuses []DependencyStatus) DegradationPolicy {
+	unavailable := make(map[string]string)
+	var policy DegradationPolicy
+	for _, status := range statuses {
+		switch {
+		case status.Status == HealthDown || status.Status == HealthDisabled:
+			unavailable[status.Name] = status.Status
+		case status.Name == DependencyFFmpeg && status.Status == HealthDegraded:
+			// ffmpeg runs but lacks filters; normalization needs loudnorm and an MP3 encoder
+			policy.SkipNormalization = true
+			policy.Reasons = append(policy.Reasons, "ffmpeg is missing filters, skipping loudness normalization")
+		}
+	}
+
+	if state, ok := unavailable[DependencyYotoAuth]; ok {
+		policy.DeferCardUpdates = true
+		policy.Reasons = append(policy.Reasons, fmt.Sprintf("Yoto auth is %s, deferring card updates", state))
+	}
+	if _, ok := unavailable[DependencyFFmpeg]; ok && !policy.SkipNormalization {
+		policy.SkipNormalization = true
+		policy.Reasons = append(policy.Reasons, "ffmpeg is unavailable, skipping loudness normalization")
+	}
+
+	chapters := make([]string, 0, len(chapterDependencies))
+	for chapter := range chapterDependencies {
+		chapters = append(chapters, chapter)
+	}
+	sort.Strings(chapters)
+	for _, chapter := range chapters {
+		for _, dependency := range chapterDependencies[chapter] {
+			if state, ok := unavailable[dependency]; ok {
+				policy.SkippedChapters = append(policy.SkippedChapters, chapter)
+				policy.Reasons = append(policy.Reasons, fmt.Sprintf("%s is %s, leaving off the %s chapter", dependency, state, chapter))
+				break
+			}
+		}
+	}
+
+	return policy
+}
+
+// HostHealth reports a remote host's health from its circuit breaker: down while open, degraded
+// while a trial request is out or after recent failures
+func HostHealth(name string, host string, critical bool) DependencyStatus {
+	status := DependencyStatus{Name: name, Status: HealthOK, Critical: critical}
+	for _, breaker := range httpx.Breakers() {
+		if breaker.Host != host {
+			continue
+		}
+		switch {
+		case breaker.State == httpx.StateOpen:
+			status.Status = HealthDown
+			status.Detail = fmt.Sprintf("circuit open since %s after %d failures", breaker.OpenedAt.Format("15:04:05"), breaker.Failures)
+		case breaker.State == httpx.StateHalfOpen:
+			status.Status = HealthDegraded
+			status.Detail = "circuit half open, trial request pending"
+		case breaker.Failures > 0:
+			status.Status = HealthDegraded
+			status.Detail = fmt.Sprintf("%d recent failures", breaker.Failures)
+		}
+	}
+	return status
+}
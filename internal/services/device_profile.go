@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// Content length preferences for a device's guide
+const (
+	FactLengthShort    = "short"
+	FactLengthEnhanced = "enhanced"
+)
+
+// DeviceProfile holds a Yoto player's listening preferences. Empty fields fall back to the card
+// and deployment settings.
+type DeviceProfile struct {
+	DeviceID     string    `json:"device_id"`
+	VoiceID      string    `json:"voice_id,omitempty"`      // ElevenLabs narrator voice
+	Region       string    `json:"region,omitempty"`        // Species pool override ("north_america", "europe", ...)
+	FactLength   string    `json:"fact_length,omitempty"`   // "short" or "enhanced"
+	NatureIntros *bool     `json:"nature_intros,omitempty"` // Intros mixed with nature sounds
+	DynamicIntro *bool     `json:"dynamic_intro,omitempty"` // Intros open with a greeting for the listener's time of day and town
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FactGenerator returns the fact generator for the profile's content length, or "" when unset
+func (p DeviceProfile) FactGenerator() string {
+	switch p.FactLength {
+	case FactLengthShort:
+		return FactGeneratorBasic
+	case FactLengthEnhanced:
+		return FactGeneratorEnhanced
+	}
+	return ""
+}
+
+// Validate checks the profile's fields before it's stored
+func (p DeviceProfile) Validate() error {
+	if p.DeviceID == "" {
+		return fmt.Errorf("device_id is required")
+	}
+	if p.FactLength != "" && p.FactLength != FactLengthShort && p.FactLength != FactLengthEnhanced {
+		return fmt.Errorf("fact_length must be %q or %q", FactLengthShort, FactLengthEnhanced)
+	}
+	return nil
+}
+
+// DeviceProfileStore keeps device profiles keyed on Yoto device ID, persisted to a JSON file
+type DeviceProfileStore struct {
+	mu       sync.RWMutex
+	path     string
+	profiles map[string]*DeviceProfile
+}
+
+// NewDeviceProfileStore loads profiles from disk, starting empty if the file doesn't exist
+func NewDeviceProfileStore(path string) *DeviceProfileStore {
+	if path == "" {
+		path = "data/device_profiles.json"
+	}
+
+	store := &DeviceProfileStore{
+		path:     path,
+		profiles: make(map[string]*DeviceProfile),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &store.profiles); err != nil {
+			log.Printf("[DEVICE_PROFILES] Failed to parse %s, starting empty: %v", path, err)
+			store.profiles = make(map[string]*DeviceProfile)
+		}
+	}
+
+	return store
+}
+
+// Get returns the profile for a device
+func (ds *DeviceProfileStore) Get(deviceID string) (DeviceProfile, bool) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	profile, exists := ds.profiles[deviceID]
+	if !exists {
+		return DeviceProfile{}, false
+	}
+	return *profile, true
+}
+
+// Put validates and stores a profile, replacing any existing one for the device
+func (ds *DeviceProfileStore) Put(profile DeviceProfile) (DeviceProfile, error) {
+	if err := profile.Validate(); err != nil {
+		return DeviceProfile{}, err
+	}
+	profile.UpdatedAt = time.Now().UTC()
+
+	ds.mu.Lock()
+	ds.profiles[profile.DeviceID] = &profile
+	ds.mu.Unlock()
+
+	if err := ds.save(); err != nil {
+		return DeviceProfile{}, err
+	}
+	log.Printf("[DEVICE_PROFILES] Saved profile for %s", profile.DeviceID)
+	return profile, nil
+}
+
+// Delete removes a device's profile, reporting whether it existed
+func (ds *DeviceProfileStore) Delete(deviceID string) (bool, error) {
+	ds.mu.Lock()
+	_, exists := ds.profiles[deviceID]
+	delete(ds.profiles, deviceID)
+	ds.mu.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	return true, ds.save()
+}
+
+// All returns every profile, sorted by device ID
+func (ds *DeviceProfileStore) All() []DeviceProfile {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	profiles := make([]DeviceProfile, 0, len(ds.profiles))
+	for _, profile := range ds.profiles {
+		profiles = append(profiles, *profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].DeviceID < profiles[j].DeviceID
+	})
+	return profiles
+}
+
+// save writes the profiles to disk atomically
+func (ds *DeviceProfileStore) save() error {
+	ds.mu.RLock()
+	data, err := json.MarshalIndent(ds.profiles, "", "  ")
+	ds.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal device profiles: %w", err)
+	}
+	return filex.AtomicWriteFile(ds.path, data, 0644)
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// DeviceRecord tracks when a Yoto player first and last played the card
+type DeviceRecord struct {
+	DeviceID  string    `json:"device_id"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Plays     int       `json:"plays"`
+
+	// Smoothed location used for the regional bird pool, plus the recent lookups behind it
+	Location         *models.Location      `json:"location,omitempty"`
+	LocationEvidence []LocationObservation `json:"location_evidence,omitempty"`
+
+	// Details from the Yoto account's device list, set by Sync
+	Name     string    `json:"name,omitempty"`
+	Family   string    `json:"family,omitempty"`   // Device family, e.g. "v3" or "mini"
+	Timezone string    `json:"timezone,omitempty"` // The player's configured timezone
+	Online   bool      `json:"online"`
+	SyncedAt time.Time `json:"synced_at,omitempty"`
+}
+
+// SyncedDevice is a player as the Yoto API lists it
+type SyncedDevice struct {
+	DeviceID string
+	Name     string
+	Family   string
+	Timezone string // Empty when the device's config couldn't be read
+	Online   bool
+}
+
+// DeviceRegistry keeps per-device listening history, persisted to a JSON file
+type DeviceRegistry struct {
+	mu      sync.RWMutex
+	path    string
+	devices map[string]*DeviceRecord
+
+	locationWindowDays int // Days of location evidence kept per device
+	locationSwitchDays int // Consecutive days a new region must lead before switching
+}
+
+// NewDeviceRegistry loads the registry from disk, starting empty if the file doesn't exist
+func NewDeviceRegistry(path string) *DeviceRegistry {
+	if path == "" {
+		path = "data/device_registry.json"
+	}
+
+	registry := &DeviceRegistry{
+		path:               path,
+		devices:            make(map[string]*DeviceRecord),
+		locationWindowDays: defaultLocationWindowDays,
+		locationSwitchDays: defaultLocationSwitchDays,
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &registry.devices); err != nil {
+			log.Printf("[DEVICE_REGISTRY] Failed to parse %s, starting empty: %v", path, err)
+			registry.devices = make(map[string]*DeviceRecord)
+		}
+	}
+
+	return registry
+}
+
+// SetLocationSmoothing sets how many days of location evidence are kept per device and how many
+// of them a new region must lead before the device switches to it; 0 or less keeps the default
+func (dr *DeviceRegistry) SetLocationSmoothing(windowDays, switchDays int) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if windowDays > 0 {
+		dr.locationWindowDays = windowDays
+	}
+	if switchDays > 0 {
+		dr.locationSwitchDays = switchDays
+	}
+	if dr.locationSwitchDays > dr.locationWindowDays {
+		dr.locationSwitchDays = dr.locationWindowDays
+	}
+}
+
+// Touch records a play from a device, setting FirstSeen on the first contact
+func (dr *DeviceRegistry) Touch(deviceID string) DeviceRecord {
+	dr.mu.Lock()
+	now := time.Now().UTC()
+	record, exists := dr.devices[deviceID]
+	if !exists {
+		record = &DeviceRecord{
+			DeviceID:  deviceID,
+			FirstSeen: now,
+		}
+		dr.devices[deviceID] = record
+		log.Printf("[DEVICE_REGISTRY] New device: %s", deviceID)
+	}
+	record.LastSeen = now
+	record.Plays++
+	snapshot := *record
+	dr.mu.Unlock()
+
+	if err := dr.save(); err != nil {
+		log.Printf("[DEVICE_REGISTRY] Failed to save registry: %v", err)
+	}
+
+	return snapshot
+}
+
+// Sync records the account's devices from the Yoto API, adding any the registry hasn't seen play
+// yet. Devices missing from the list are kept, marked offline, since their listening history is
+// still useful. An empty timezone leaves the stored one in place.
+func (dr *DeviceRegistry) Sync(devices []SyncedDevice) int {
+	dr.mu.Lock()
+	now := time.Now().UTC()
+	listed := make(map[string]bool, len(devices))
+	added := 0
+	for _, device := range devices {
+		if device.DeviceID == "" {
+			continue
+		}
+		listed[device.DeviceID] = true
+
+		record, exists := dr.devices[device.DeviceID]
+		if !exists {
+			record = &DeviceRecord{DeviceID: device.DeviceID, FirstSeen: now}
+			dr.devices[device.DeviceID] = record
+			added++
+		}
+		record.Name = device.Name
+		record.Family = device.Family
+		record.Online = device.Online
+		if device.Timezone != "" {
+			record.Timezone = device.Timezone
+		}
+		record.SyncedAt = now
+	}
+	for deviceID, record := range dr.devices {
+		if !listed[deviceID] {
+			record.Online = false
+		}
+	}
+	dr.mu.Unlock()
+
+	if err := dr.save(); err != nil {
+		log.Printf("[DEVICE_REGISTRY] Failed to save registry: %v", err)
+	}
+	return added
+}
+
+// Get returns the record for a device
+func (dr *DeviceRegistry) Get(deviceID string) (DeviceRecord, bool) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	record, exists := dr.devices[deviceID]
+	if !exists {
+		return DeviceRecord{}, false
+	}
+	return *record, true
+}
+
+// IsNewListener reports whether a device was first seen within the given window.
+// Unknown devices count as new.
+func (dr *DeviceRegistry) IsNewListener(deviceID string, window time.Duration) bool {
+	record, exists := dr.Get(deviceID)
+	if !exists {
+		return true
+	}
+	return time.Since(record.FirstSeen) < window
+}
+
+// Locations returns the smoothed location of every device that has one
+func (dr *DeviceRegistry) Locations() []models.Location {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	var locations []models.Location
+	for _, record := range dr.devices {
+		if record.Location != nil {
+			locations = append(locations, *record.Location)
+		}
+	}
+	return locations
+}
+
+// All returns every device record, sorted by device ID
+func (dr *DeviceRegistry) All() []DeviceRecord {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	records := make([]DeviceRecord, 0, len(dr.devices))
+	for _, record := range dr.devices {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].DeviceID < records[j].DeviceID })
+	return records
+}
+
+// Timezones returns the distinct timezones devices are configured for, as synced from the Yoto API
+func (dr *DeviceRegistry) Timezones() []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var zones []string
+	for _, record := range dr.devices {
+		if record.Timezone != "" && !seen[record.Timezone] {
+			seen[record.Timezone] = true
+			zones = append(zones, record.Timezone)
+		}
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// Count returns the number of known devices
+func (dr *DeviceRegistry) Count() int {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	return len(dr.devices)
+}
+
+// save writes the registry to disk atomically
+func (dr *DeviceRegistry) save() error {
+	dr.mu.RLock()
+	data, err := json.MarshalIndent(dr.devices, "", "  ")
+	dr.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry: %w", err)
+	}
+	return filex.AtomicWriteFile(dr.path, data, 0644)
+}
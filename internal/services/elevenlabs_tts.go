@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
+)
+
+const elevenLabsBaseURL = "https://api.elevenlabs.io/v1"
+
+// elevenLabsCharacters tracks billed usage; cached renders cost nothing and aren't counted
+var elevenLabsCharacters = metrics.NewCounterVec("bird_explorer_elevenlabs_characters_total",
+	"Characters sent to ElevenLabs text-to-speech, by model", "model")
+
+// DefaultElevenLabsModel handles every supported narration locale
+const DefaultElevenLabsModel = "eleven_multilingual_v2"
+
+// defaultVoiceSettings are the stability and similarity used for all narration
+var defaultVoiceSettings = map[string]float64{
+	"stability":        0.5,
+	"similarity_boost": 0.75,
+}
+
+// ElevenLabsTTS renders speech with the ElevenLabs API, reusing cached audio for repeated scripts
+type ElevenLabsTTS struct {
+	apiKey         string
+	modelID        string
+	baseURL        string
+	httpClient     *http.Client
+	cache          *TTSCache
+	quota          *QuotaManager            // Optional character budget and concurrency limit
+	pronunciations *PronunciationDictionary // Respells hard bird and scientific names before rendering
+	moderator      *ScriptModerator         // Removes sentences unsuitable for children before rendering
+}
+
+// NewElevenLabsTTS creates a client using the given model (DefaultElevenLabsModel when empty),
+// the process-wide pronunciation dictionary and moderation rules, and a TTS cache under
+// data/tts_cache until SetCache replaces it
+func NewElevenLabsTTS(apiKey string, modelID string) *ElevenLabsTTS {
+	if modelID == "" {
+		modelID = DefaultElevenLabsModel
+	}
+	return &ElevenLabsTTS{
+		apiKey:         apiKey,
+		modelID:        modelID,
+		baseURL:        elevenLabsBaseURL,
+		httpClient:     httpx.NewClient(httpx.Options{Timeout: 2 * time.Minute, AttemptTimeout: 60 * time.Second, RetryUnprocessed: true}),
+		cache:          NewTTSCacheFor("disk", "", ""),
+		pronunciations: SharedPronunciations(),
+		moderator:      SharedModerator(),
+	}
+}
+
+// SetBaseURL points the client at another ElevenLabs API, such as ttstest's fake
+func (t *ElevenLabsTTS) SetBaseURL(baseURL string) {
+	t.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetCache replaces where rendered audio is cached
+func (t *ElevenLabsTTS) SetCache(cache *TTSCache) {
+	t.cache = cache
+}
+
+// SetQuotaManager makes renders count against a character budget; once it's spent, uncached
+// scripts fail with ErrTTSQuotaExhausted instead of being billed
+func (t *ElevenLabsTTS) SetQuotaManager(quota *QuotaManager) {
+	t.quota = quota
+}
+
+// Render returns MP3 speech for text in the given voice and whether it came from the cache.
+// Call cues are dropped (GuideCallSplicer renders the speech around them separately), flagged
+// sentences are removed, and hard words are rewritten with the pronunciation dictionary first,
+// so a rule or dictionary change re-renders the scripts it affects. The API call is bound to ctx
+// and logged with its request ID.
+func (t *ElevenLabsTTS) Render(ctx context.Context, text string, voiceID string) ([]byte, bool, error) {
+	text, removed := t.moderator.Moderate(ctx, WithoutCallCues(text))
+	if len(removed) > 0 && strings.TrimSpace(text) == "" {
+		return nil, false, fmt.Errorf("every sentence of the script was removed by moderation")
+	}
+	request := TTSRequest{
+		Text:     t.pronunciations.Apply(text, t.modelID),
+		VoiceID:  voiceID,
+		ModelID:  t.modelID,
+		Settings: defaultVoiceSettings,
+	}
+	return t.cache.GetOrRender(request, func() ([]byte, error) {
+		return t.synthesize(ctx, request)
+	})
+}
+
+// synthesize calls the ElevenLabs text-to-speech API
+func (t *ElevenLabsTTS) synthesize(ctx context.Context, request TTSRequest) ([]byte, error) {
+	if t.apiKey == "" {
+		return nil, fmt.Errorf("ELEVENLABS_API_KEY is not set")
+	}
+
+	characters := utf8.RuneCountInString(request.Text)
+	if t.quota != nil {
+		if err := t.quota.Reserve(characters); err != nil {
+			slog.WarnContext(ctx, "[TTS] Skipping render", "voice_id", request.VoiceID, "characters", characters, "error", err)
+			return nil, err
+		}
+		release, err := t.quota.Acquire(ctx)
+		if err != nil {
+			t.quota.Refund(characters)
+			return nil, err
+		}
+		defer release()
+	}
+
+	// A render that timed out or failed on ElevenLabs' side may still have been billed, so its
+	// characters stay spent; only calls that never ran are refunded. Renders aren't retried once
+	// they've reached ElevenLabs, so each render is billed at most once.
+	audio, billed, err := t.callAPI(ctx, request)
+	if billed {
+		elevenLabsCharacters.Add(float64(characters), request.ModelID)
+	} else if t.quota != nil {
+		t.quota.Refund(characters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "[TTS] Rendered speech", "voice_id", request.VoiceID, "model", request.ModelID, "characters", characters, "bytes", len(audio))
+	return audio, nil
+}
+
+// callAPI posts a request to the ElevenLabs text-to-speech endpoint and reports whether
+// ElevenLabs may have billed for it: anything but a call that never arrived or was rejected
+// with a 4xx
+func (t *ElevenLabsTTS) callAPI(ctx context.Context, request TTSRequest) ([]byte, bool, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"text":           request.Text,
+		"model_id":       request.ModelID,
+		"voice_settings": request.Settings,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	url := fmt.Sprintf("%s/text-to-speech/%s?output_format=mp3_44100_128", t.baseURL, request.VoiceID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("xi-api-key", t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, !httpx.NotSent(err), fmt.Errorf("TTS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	billed := resp.StatusCode < 400 || resp.StatusCode >= 500
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, billed, fmt.Errorf("failed to read TTS response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, billed, fmt.Errorf("TTS returned status %d: %s", resp.StatusCode, string(audio))
+	}
+	return audio, true, nil
+}
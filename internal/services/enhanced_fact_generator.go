@@ -1,7 +1,10 @@
 package services
 
 import (
+	"context"
+
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/randx"
 )
 
 // EnhancedFactGenerator wraps the existing ImprovedFactGeneratorV4
@@ -10,19 +13,24 @@ type EnhancedFactGenerator struct {
 }
 
 // NewEnhancedFactGenerator creates a new enhanced fact generator
-func NewEnhancedFactGenerator(ebirdAPIKey string) *EnhancedFactGenerator {
+func NewEnhancedFactGenerator(ebirdAPIKey string, rng *randx.Randomizer) *EnhancedFactGenerator {
 	return &EnhancedFactGenerator{
-		v4Generator: NewImprovedFactGeneratorV4(ebirdAPIKey),
+		v4Generator: NewImprovedFactGeneratorV4(ebirdAPIKey, rng),
 	}
 }
 
 // GetGeneratorType returns the type of this generator
 func (g *EnhancedFactGenerator) GetGeneratorType() string {
-	return "enhanced"
+	return FactGeneratorEnhanced
 }
 
 // GenerateFactScript creates an enhanced fact script for a bird
-func (g *EnhancedFactGenerator) GenerateFactScript(bird *models.Bird, latitude, longitude float64) string {
+func (g *EnhancedFactGenerator) GenerateFactScript(ctx context.Context, bird *models.Bird, latitude, longitude float64) string {
 	// Use the existing V4 generator's method
-	return g.v4Generator.GenerateExplorersGuideScriptWithLocation(bird, latitude, longitude)
-}
\ No newline at end of file
+	return g.v4Generator.GenerateExplorersGuideScriptWithLocation(ctx, bird, latitude, longitude)
+}
+
+// GenerateFactTranscript creates an enhanced fact script with the source of every sentence
+func (g *EnhancedFactGenerator) GenerateFactTranscript(ctx context.Context, bird *models.Bird, latitude, longitude float64) *ScriptTranscript {
+	return g.v4Generator.GenerateExplorersGuideTranscript(ctx, bird, latitude, longitude)
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services/factkit"
+	"github.com/callen/bird-song-explorer/pkg/inaturalist"
+	"github.com/callen/bird-song-explorer/pkg/wikipedia"
+)
+
+// Sentences longer than this read badly aloud and are left out of fact sheets
+const maxFactSentenceLength = 200
+
+// Facts taken from each Wikipedia page section, so one long section can't crowd out the rest
+const maxSectionFacts = 3
+
+// Field marks named in the "look for" sentence; more than this is hard to keep in mind
+const maxFieldMarks = 3
+
+// FactSources is the raw data a fact sheet is aggregated from; any field may be missing
+type FactSources struct {
+	SimpleWiki *wikipedia.PageSummary
+	Wiki       *wikipedia.PageSummary
+	Sections   *wikipedia.PageSections // English Wikipedia's description, behaviour, diet, breeding, and vocalization sections
+	Taxon      *inaturalist.Taxon
+	FieldMarks *inaturalist.FieldMarks // Colored body parts observers record for the taxon
+	Sightings  []RecentSighting
+	// English Wikipedia sentences above this Flesch-Kincaid grade are simplified, and left out
+	// when they still read above it; 0 keeps every sentence
+	ReadingGrade float64
+}
+
+// FactAggregator fetches the external sources for a bird's fact sheet
+type FactAggregator struct {
+	simpleWiki *wikipedia.Client
+	wiki       *wikipedia.Client
+	inat       *inaturalist.Client
+	grade      float64
+}
+
+// NewFactAggregator creates an aggregator over Simple English Wikipedia, English Wikipedia, and iNaturalist
+func NewFactAggregator(simpleWiki *wikipedia.Client, inat *inaturalist.Client) *FactAggregator {
+	return &FactAggregator{
+		simpleWiki: simpleWiki,
+		wiki:       wikipedia.NewEnglishClient(),
+		inat:       inat,
+		grade:      GuideReadingGrade(),
+	}
+}
+
+// Fetch gathers every source except sightings, which come from the caller's location context
+func (fa *FactAggregator) Fetch(ctx context.Context, bird *models.Bird) FactSources {
+	sources := FactSources{ReadingGrade: fa.grade}
+	sources.SimpleWiki, _ = fa.simpleWiki.GetBirdSummary(ctx, bird.CommonName)
+	sources.Wiki, _ = fa.wiki.GetBirdSummary(ctx, bird.CommonName)
+	sources.Sections, _ = fa.wiki.GetBirdSections(ctx, bird.CommonName)
+	sources.Taxon, _ = fa.inat.SearchTaxon(ctx, bird.CommonName)
+	if sources.Taxon != nil {
+		sources.FieldMarks, _ = fa.inat.GetFieldMarks(ctx, sources.Taxon.ID)
+	}
+	return sources
+}
+
+// Keywords that place a Wikipedia sentence in a section, checked in factSectionOrder
+var factSectionKeywords = map[FactSection][]string{
+	FactVocalization: {"song", "songs", "sing", "sings", "singing", "call", "calls", "voice", "whistle", "whistles", "drum", "drums", "drumming"},
+	FactNesting:      {"nest", "nests", "nesting", "egg", "eggs", "breed", "breeds", "breeding", "chick", "chicks", "incubate", "incubated"},
+	FactDiet:         {"eat", "eats", "eating", "feed", "feeds", "feeding", "diet", "food", "prey", "insects", "seeds", "nectar", "fish", "berries"},
+	FactColors:       {"color", "colors", "colour", "colours", "colored", "coloured", "marking", "markings", "plumage", "red", "orange", "yellow", "green", "blue", "black", "white", "grey", "gray", "brown"},
+	FactSize:         {"size", "wing", "wings", "wingspan", "length", "long", "weighs", "weight", "cm", "centimetres", "centimeters", "inches"},
+}
+
+var factSectionOrder = []FactSection{FactVocalization, FactNesting, FactDiet, FactColors, FactSize}
+
+// Terms that mark a summary sentence as too technical for kids. Page sections leave out the
+// taxonomy section, so only the summaries need the filter.
+var technicalFactTerms = []string{"genus", "taxonomy", "subspecies", "binomial", "phylogen"}
+
+// wikiSectionFacts are the fact sections each Wikipedia page section's sentences are filed under.
+// Sections without one are classified sentence by sentence.
+var wikiSectionFacts = map[string]FactSection{
+	wikipedia.SectionDiet:         FactDiet,
+	wikipedia.SectionBreeding:     FactNesting,
+	wikipedia.SectionVocalization: FactVocalization,
+}
+
+// wikiSectionOrder is the order page sections are read in
+var wikiSectionOrder = []string{
+	wikipedia.SectionVocalization,
+	wikipedia.SectionBreeding,
+	wikipedia.SectionDiet,
+	wikipedia.SectionDescription,
+	wikipedia.SectionBehaviour,
+}
+
+// AggregateFactSheet builds a fact sheet from already-fetched sources. Hand-written facts come
+// first, then Simple English Wikipedia, English Wikipedia's summary and page sections,
+// iNaturalist, and eBird, so each section's first fact is from the most kid-friendly source that
+// had one. Simple English Wikipedia is already written for young readers; English Wikipedia
+// sentences are simplified to the sources' reading grade. Repeated sentences are kept only once.
+func AggregateFactSheet(bird *models.Bird, sources FactSources) *FactSheet {
+	sheet := &FactSheet{
+		CommonName:     bird.CommonName,
+		ScientificName: bird.ScientificName,
+		Family:         bird.Family,
+	}
+
+	addCuratedFacts(sheet, bird)
+
+	if sources.SimpleWiki != nil {
+		addWikipediaFacts(sheet, sources.SimpleWiki.Extract, SourceSimpleWikipedia, 0)
+	}
+	if sources.Wiki != nil {
+		addWikipediaFacts(sheet, sources.Wiki.Extract, SourceWikipedia, sources.ReadingGrade)
+	}
+	addWikipediaSectionFacts(sheet, sources.Sections, sources.ReadingGrade)
+
+	if sources.Taxon != nil && sources.Taxon.ConservationStatus != nil && sources.Taxon.ConservationStatus.StatusName != "" {
+		status := strings.ToLower(sources.Taxon.ConservationStatus.StatusName)
+		sheet.AddValue(FactConservation, status, SourceINaturalist, valueConservationStatus)
+		sheet.AddFact(FactConservation,
+			fmt.Sprintf("Scientists list the %s as %s.", bird.CommonName, status),
+			SourceINaturalist, "conservation_status")
+	}
+
+	if sources.FieldMarks != nil && len(sources.FieldMarks.Marks) > 0 {
+		sheet.AddFact(FactFieldMarks, fieldMarksSentence(sources.FieldMarks), SourceINaturalist, "field_marks")
+	}
+
+	if len(sources.Sightings) > 0 {
+		sheet.AddFact(FactSightings,
+			fmt.Sprintf("%s seen %d time%s nearby in the last 30 days.", bird.CommonName, len(sources.Sightings), factkit.PluralS(float64(len(sources.Sightings)))),
+			SourceEBird, "recent_observations")
+	}
+
+	return sheet
+}
+
+// fieldMarksSentence tells listeners what to look for, from the most commonly recorded marks
+func fieldMarksSentence(marks *inaturalist.FieldMarks) string {
+	var phrases []string
+	for _, mark := range marks.Marks {
+		if len(phrases) == maxFieldMarks {
+			break
+		}
+		phrases = append(phrases, mark.Phrase())
+	}
+	return fmt.Sprintf("Look for the %s.", joinWithAnd(phrases))
+}
+
+// addWikipediaFacts sorts the extract's sentences into sections, skipping technical ones and
+// simplifying those above the reading grade. Sentences are classified before simplifying, since
+// simpler words can hide the keyword ("plumage") that placed them.
+func addWikipediaFacts(sheet *FactSheet, extract string, source string, grade float64) {
+	for _, sentence := range splitSentences(extract) {
+		if containsAny(strings.ToLower(sentence), technicalFactTerms) {
+			continue
+		}
+		section, ok := classifyFactSentence(sentence)
+		if !ok {
+			continue
+		}
+		for _, readable := range readableSentences(sentence, grade) {
+			if len(readable) < maxFactSentenceLength {
+				sheet.AddFact(section, readable, source, "summary")
+			}
+		}
+	}
+}
+
+// addWikipediaSectionFacts adds the first few readable sentences of each page section. Diet,
+// breeding, and vocalization sentences go straight to their fact section; description and
+// behaviour sentences are classified like summary sentences.
+func addWikipediaSectionFacts(sheet *FactSheet, sections *wikipedia.PageSections, grade float64) {
+	for _, name := range wikiSectionOrder {
+		added := 0
+		for _, sentence := range splitSentences(sections.Section(name)) {
+			if added == maxSectionFacts {
+				break
+			}
+			section, ok := wikiSectionFacts[name]
+			if !ok {
+				if section, ok = classifyFactSentence(sentence); !ok {
+					continue
+				}
+			}
+			for _, readable := range readableSentences(sentence, grade) {
+				if added < maxSectionFacts && len(readable) < maxFactSentenceLength && sheet.AddFact(section, readable, SourceWikipedia, "section_"+name) {
+					added++
+				}
+			}
+		}
+	}
+}
+
+// classifyFactSentence returns the first section whose keywords appear as whole words
+func classifyFactSentence(sentence string) (FactSection, bool) {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	}) {
+		words[word] = true
+	}
+
+	for _, section := range factSectionOrder {
+		for _, keyword := range factSectionKeywords[section] {
+			if words[keyword] {
+				return section, true
+			}
+		}
+	}
+	return "", false
+}
+
+func containsAny(text string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// addCuratedFacts adds the hand-written fact bank entries that match the bird
+func addCuratedFacts(sheet *FactSheet, bird *models.Bird) {
+	lowerName := strings.ToLower(bird.CommonName)
+
+	switch {
+	case strings.Contains(lowerName, "robin"):
+		sheet.AddFact(FactVocalization, "Robins sing a cheerful melody that sounds like 'cheerily, cheer-up, cheerio!'", SourceCuratedBank, "vocalizations")
+		sheet.AddFact(FactNesting, "Robin parents lay 3-5 blue eggs. Tiny pink babies hatch after two weeks!", SourceCuratedBank, "nesting")
+	case strings.Contains(lowerName, "cardinal"):
+		sheet.AddFact(FactVocalization, "Cardinals whistle clear notes like 'birdy-birdy-birdy' or 'cheer-cheer-cheer.'", SourceCuratedBank, "vocalizations")
+		sheet.AddFact(FactFunFacts, "Fun fact: Cardinals are the state bird of seven states!", SourceCuratedBank, "fun_facts")
+	case strings.Contains(lowerName, "hummingbird"):
+		sheet.AddFact(FactDiet, "Watch them feed! They hover at flowers, sipping nectar and catching tiny insects!", SourceCuratedBank, "diet")
+		sheet.AddFact(FactAbilities, "Incredible ability: They can fly backwards and hover! Hearts beat 1,200 times per minute!", SourceCuratedBank, "abilities")
+	}
+}
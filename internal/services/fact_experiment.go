@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"hash/fnv"
+	"log"
+
+	"github.com/callen/bird-song-explorer/internal/store"
+)
+
+// Fact generator names accepted by NewFactGenerator
+const (
+	FactGeneratorBasic    = "basic"
+	FactGeneratorEnhanced = "enhanced"
+	FactGeneratorLocation = "location"
+)
+
+// GeneratorOutcome counts plays of the guide and how many reached the outro
+type GeneratorOutcome struct {
+	Plays          int     `json:"plays"`
+	Completions    int     `json:"completions"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// FactExperiment assigns each card to the basic or enhanced fact generator and compares
+// how often kids listen through to the end. Assignment is sticky per card: the same card
+// always lands in the same bucket for a given salt. Assignments and plays are kept in an
+// ExperimentStore, so every instance serves and counts the same arms.
+type FactExperiment struct {
+	defaultGenerator string
+	enhancedPercent  int
+	salt             string
+	store            store.ExperimentStore
+}
+
+// NewFactExperiment creates an experiment persisted to experiments. With enhancedPercent of 0
+// every card uses defaultGenerator (the old global BIRD_FACT_GENERATOR behavior).
+func NewFactExperiment(defaultGenerator string, enhancedPercent int, salt string, experiments store.ExperimentStore) *FactExperiment {
+	if !IsFactGenerator(defaultGenerator) {
+		defaultGenerator = FactGeneratorBasic
+	}
+	if enhancedPercent < 0 {
+		enhancedPercent = 0
+	}
+	if enhancedPercent > 100 {
+		enhancedPercent = 100
+	}
+
+	return &FactExperiment{
+		defaultGenerator: defaultGenerator,
+		enhancedPercent:  enhancedPercent,
+		salt:             salt,
+		store:            experiments,
+	}
+}
+
+// Enabled reports whether cards are being split between generators
+func (fe *FactExperiment) Enabled() bool {
+	return fe.enhancedPercent > 0
+}
+
+// GeneratorForCard returns the card's sticky generator bucket
+func (fe *FactExperiment) GeneratorForCard(cardID string) string {
+	if !fe.Enabled() {
+		return fe.defaultGenerator
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(fe.salt + ":" + cardID))
+	if int(h.Sum32()%100) < fe.enhancedPercent {
+		return FactGeneratorEnhanced
+	}
+	return FactGeneratorBasic
+}
+
+// RecordAssignment logs which generator produced a card's guide for a date
+func (fe *FactExperiment) RecordAssignment(cardID string, date string, generator string) {
+	if err := fe.store.RecordAssignment(cardID, date, generator); err != nil {
+		log.Printf("[FACT_EXPERIMENT] Failed to record %s generator for card %s on %s: %v", generator, cardID, date, err)
+		return
+	}
+	log.Printf("[FACT_EXPERIMENT] Card %s uses %s generator for %s", cardID, generator, date)
+}
+
+// AssignmentFor returns the generator recorded for a card on a date, falling back to its bucket
+func (fe *FactExperiment) AssignmentFor(cardID string, date string) string {
+	generator, err := fe.store.Assignment(cardID, date)
+	if err == nil {
+		return generator
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		log.Printf("[FACT_EXPERIMENT] Failed to read card %s's generator for %s: %v", cardID, date, err)
+	}
+	return fe.GeneratorForCard(cardID)
+}
+
+// RecordGuideStarted counts a play of the guide track for a listening session
+func (fe *FactExperiment) RecordGuideStarted(sessionKey string, generator string) {
+	if err := fe.store.RecordGuideStarted(store.ExperimentPlay{SessionKey: sessionKey, Generator: generator}); err != nil {
+		log.Printf("[FACT_EXPERIMENT] Failed to record guide play: %v", err)
+	}
+}
+
+// RecordCompleted counts a session that reached the outro after hearing the guide
+func (fe *FactExperiment) RecordCompleted(sessionKey string) {
+	if err := fe.store.RecordCompleted(sessionKey); err != nil {
+		log.Printf("[FACT_EXPERIMENT] Failed to record completed play: %v", err)
+	}
+}
+
+// Outcomes returns plays, completions, and completion rate per generator
+func (fe *FactExperiment) Outcomes() map[string]GeneratorOutcome {
+	outcomes := map[string]GeneratorOutcome{
+		FactGeneratorBasic:    {},
+		FactGeneratorEnhanced: {},
+	}
+
+	tallies, err := fe.store.GeneratorTallies()
+	if err != nil {
+		log.Printf("[FACT_EXPERIMENT] Failed to read outcomes: %v", err)
+		return outcomes
+	}
+	for generator, tally := range tallies {
+		result := GeneratorOutcome{Plays: tally.Plays, Completions: tally.Completions}
+		if result.Plays > 0 {
+			result.CompletionRate = float64(result.Completions) / float64(result.Plays)
+		}
+		outcomes[generator] = result
+	}
+	return outcomes
+}
+
+// Stats summarizes the experiment for the stats module
+func (fe *FactExperiment) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":          fe.Enabled(),
+		"enhanced_percent": fe.enhancedPercent,
+		"outcomes":         fe.Outcomes(),
+	}
+}
@@ -1,25 +1,130 @@
 package services
 
-import "github.com/callen/bird-song-explorer/internal/models"
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
 
 // FactGenerator defines the interface for bird fact generation
 type FactGenerator interface {
 	// GenerateFactScript creates a fact script for a bird
 	// Returns the generated text script (not audio)
-	GenerateFactScript(bird *models.Bird, latitude, longitude float64) string
-	
-	// GetGeneratorType returns the type of generator (basic or enhanced)
+	GenerateFactScript(ctx context.Context, bird *models.Bird, latitude, longitude float64) string
+
+	// GenerateFactTranscript creates the same script with the source of every sentence
+	GenerateFactTranscript(ctx context.Context, bird *models.Bird, latitude, longitude float64) *ScriptTranscript
+
+	// GetGeneratorType returns the name the generator is registered under
 	GetGeneratorType() string
 }
 
-// FactGeneratorFactory creates the appropriate fact generator based on configuration
-func NewFactGenerator(generatorType string, ebirdAPIKey string) FactGenerator {
-	switch generatorType {
-	case "enhanced":
-		// Use the enhanced generator (formerly V4)
-		return NewEnhancedFactGenerator(ebirdAPIKey)
-	default:
-		// Use the basic generator (current standard)
-		return NewBasicFactGenerator()
+// FactGeneratorOptions is what a registered generator is built from
+type FactGeneratorOptions struct {
+	EBirdAPIKey string
+	Locale      string            // Normalized content language
+	Rand        *randx.Randomizer // Picks the script's phrasings
+}
+
+// FactGeneratorStyle is a script style NewFactGenerator can build by name. Shared phrasing
+// helpers live in the factkit package, so a new style only has to decide what its script says.
+type FactGeneratorStyle struct {
+	Name string
+
+	// Localized styles write in every content language; other languages get the basic style
+	Localized bool
+
+	New func(opts FactGeneratorOptions) FactGenerator
+}
+
+var (
+	factGeneratorsMu sync.RWMutex
+	factGenerators   = map[string]FactGeneratorStyle{
+		FactGeneratorBasic: {
+			Name:      FactGeneratorBasic,
+			Localized: true,
+			New: func(opts FactGeneratorOptions) FactGenerator {
+				return NewBasicFactGeneratorForLocale(opts.Locale, opts.Rand)
+			},
+		},
+		FactGeneratorEnhanced: {
+			Name: FactGeneratorEnhanced,
+			New: func(opts FactGeneratorOptions) FactGenerator {
+				return NewEnhancedFactGenerator(opts.EBirdAPIKey, opts.Rand)
+			},
+		},
+		FactGeneratorLocation: {
+			Name: FactGeneratorLocation,
+			New: func(opts FactGeneratorOptions) FactGenerator {
+				return NewLocationFactGenerator(opts.EBirdAPIKey, opts.Rand)
+			},
+		},
+	}
+)
+
+// RegisterFactGenerator adds a script style. It panics if the name is taken, as registering
+// the same style twice is a programming error.
+func RegisterFactGenerator(style FactGeneratorStyle) {
+	if style.Name == "" || style.New == nil {
+		panic("services: fact generator style needs a name and a constructor")
+	}
+
+	factGeneratorsMu.Lock()
+	defer factGeneratorsMu.Unlock()
+	if _, exists := factGenerators[style.Name]; exists {
+		panic(fmt.Sprintf("services: fact generator %q registered twice", style.Name))
+	}
+	factGenerators[style.Name] = style
+}
+
+// IsFactGenerator reports whether name is a registered script style
+func IsFactGenerator(name string) bool {
+	factGeneratorsMu.RLock()
+	defer factGeneratorsMu.RUnlock()
+	_, ok := factGenerators[name]
+	return ok
+}
+
+// FactGeneratorNames returns the registered script styles in alphabetical order
+func FactGeneratorNames() []string {
+	factGeneratorsMu.RLock()
+	defer factGeneratorsMu.RUnlock()
+
+	names := make([]string, 0, len(factGenerators))
+	for name := range factGenerators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FactGeneratorFactory creates the appropriate fact generator based on configuration, drawing its
+// template choices from rng
+func NewFactGenerator(generatorType string, ebirdAPIKey string, rng *randx.Randomizer) FactGenerator {
+	return NewFactGeneratorForLocale(generatorType, ebirdAPIKey, DefaultLocale, rng)
+}
+
+// NewFactGeneratorForLocale creates a fact generator that writes in the given language. Unknown
+// styles get the basic generator, as do languages the requested style can't write in.
+func NewFactGeneratorForLocale(generatorType string, ebirdAPIKey string, locale string, rng *randx.Randomizer) FactGenerator {
+	factGeneratorsMu.RLock()
+	style, ok := factGenerators[generatorType]
+	basic := factGenerators[FactGeneratorBasic]
+	factGeneratorsMu.RUnlock()
+
+	if !ok {
+		style = basic
 	}
-}
\ No newline at end of file
+	if locale = NormalizeLocale(locale); locale != DefaultLocale && !style.Localized {
+		log.Printf("[FACTS] %s generator is English-only, using basic generator for %s", style.Name, locale)
+		style = basic
+	}
+
+	generator := style.New(FactGeneratorOptions{EBirdAPIKey: ebirdAPIKey, Locale: locale, Rand: rng})
+	return moderatedFactGenerator{FactGenerator: generator, moderator: SharedModerator()}
+}
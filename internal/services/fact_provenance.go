@@ -0,0 +1,289 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// Fact sources recorded for each sentence of a generated script
+const (
+	SourceWikipedia       = "wikipedia"        // Detail is the page section the text came from
+	SourceSimpleWikipedia = "simple_wikipedia" // Simple English Wikipedia; detail as for SourceWikipedia
+	SourceINaturalist     = "inaturalist"      // Detail is the taxon field
+	SourceEBird           = "ebird"            // Detail is the eBird API data used
+	SourceCuratedBank     = "curated_bank"     // Detail is the hand-written fact bank
+	SourceTemplate        = "template"         // Detail is the template name
+	SourceWeather         = "weather"          // Detail is the weather condition
+	SourceMetadata        = "metadata"         // Detail is the stored metadata field
+)
+
+// CallMarker in a script marks where a snippet of the bird's recording is played. It follows the
+// sentence cueing the call, which is dropped with it when the script is read without the snippet.
+const CallMarker = "[call]"
+
+// callCueText asks the listener to listen for the snippet
+const callCueText = "Now listen closely to the %s's call!"
+
+// SentenceProvenance records where one sentence of a script came from
+type SentenceProvenance struct {
+	Text   string `json:"text"`
+	Source string `json:"source"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ScriptTranscript is a generated script along with the source of every sentence,
+// so factual errors heard on a card can be traced back and corrected upstream
+type ScriptTranscript struct {
+	BirdName    string               `json:"bird_name"`
+	Generator   string               `json:"generator"`
+	Script      string               `json:"script"`
+	Sentences   []SentenceProvenance `json:"sentences"`
+	Removed     []RemovedSentence    `json:"removed,omitempty"` // Sentences moderation took out before speech
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// transcriptBuilder assembles a script from attributed pieces. With a budget, pieces are grouped
+// into sections and the transcript leaves out whole sections to fit the budget's reading time.
+type transcriptBuilder struct {
+	parts     []string
+	sentences []SentenceProvenance
+
+	budget           *LengthBudgeter
+	layout           []ScriptSection // The sections the script can have, in order
+	plan             LengthPlan
+	sections         []ScriptSection // The sections started so far
+	partSections     []int           // Section of each part; -1 before the first section starts
+	sentenceSections []int
+}
+
+// newBudgetedBuilder creates a builder for a script laid out in sections, planning each
+// section's words before it's written and fitting the written script to the budget
+func newBudgetedBuilder(budget *LengthBudgeter, layout []ScriptSection) *transcriptBuilder {
+	return &transcriptBuilder{budget: budget, layout: layout, plan: budget.Plan(layout)}
+}
+
+// section starts the layout's named section, grouping the pieces added after it until the next
+// one starts. It reports false, starting nothing, when the plan leaves the section out.
+func (tb *transcriptBuilder) section(name string) bool {
+	if !tb.plan.Includes(name) {
+		return false
+	}
+	started := ScriptSection{Name: name, Priority: SectionExtra}
+	for _, section := range tb.layout {
+		if section.Name == name {
+			started = section
+			break
+		}
+	}
+	tb.sections = append(tb.sections, started)
+	return true
+}
+
+// shortened reports whether the plan gives the section fewer than its typical words
+func (tb *transcriptBuilder) shortened(name string) bool {
+	for _, section := range tb.layout {
+		if section.Name == name {
+			return tb.plan != nil && tb.plan.Words(name) < section.Words
+		}
+	}
+	return false
+}
+
+// record appends a piece and its attributed sentences to the current section
+func (tb *transcriptBuilder) record(part string, sentences ...SentenceProvenance) {
+	current := len(tb.sections) - 1
+	tb.parts = append(tb.parts, part)
+	tb.partSections = append(tb.partSections, current)
+	for _, sentence := range sentences {
+		tb.sentences = append(tb.sentences, sentence)
+		tb.sentenceSections = append(tb.sentenceSections, current)
+	}
+}
+
+// add appends text to the script, attributing each of its sentences to source
+func (tb *transcriptBuilder) add(text string, source string, detail string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	var sentences []SentenceProvenance
+	for _, sentence := range splitSentences(text) {
+		sentences = append(sentences, SentenceProvenance{
+			Text:   sentence,
+			Source: source,
+			Detail: detail,
+		})
+	}
+	tb.record(text, sentences...)
+}
+
+// addCallCue asks the listener to listen to the bird's call and marks where the snippet plays
+func (tb *transcriptBuilder) addCallCue(birdName string) {
+	cue := fmt.Sprintf(callCueText, birdName)
+	tb.record(cue+" "+CallMarker, SentenceProvenance{
+		Text:   cue,
+		Source: SourceTemplate,
+		Detail: "call_cue",
+	})
+}
+
+// addFacts appends fact sheet entries with their recorded sources
+func (tb *transcriptBuilder) addFacts(facts []SourcedFact) {
+	for _, fact := range facts {
+		tb.add(fact.Text, fact.Source, fact.Detail)
+	}
+}
+
+// length returns the current script length in bytes
+func (tb *transcriptBuilder) length() int {
+	return len(tb.script())
+}
+
+func (tb *transcriptBuilder) script() string {
+	return strings.ReplaceAll(strings.Join(tb.parts, " "), "  ", " ")
+}
+
+func (tb *transcriptBuilder) transcript(birdName string, generator string) *ScriptTranscript {
+	tb.fitBudget(birdName)
+	return &ScriptTranscript{
+		BirdName:    birdName,
+		Generator:   generator,
+		Script:      tb.script(),
+		Sentences:   tb.sentences,
+		GeneratedAt: time.Now().UTC(),
+	}
+}
+
+// fitBudget leaves out the sections the budget drops to bring the script within its reading time
+func (tb *transcriptBuilder) fitBudget(birdName string) {
+	if tb.budget == nil || len(tb.sections) == 0 {
+		return
+	}
+
+	text := make([]string, len(tb.sections))
+	for i, part := range tb.parts {
+		if section := tb.partSections[i]; section >= 0 {
+			text[section] += " " + part
+		}
+	}
+	seconds := make([]float64, len(tb.sections))
+	for i := range text {
+		seconds[i] = tb.budget.Seconds(text[i])
+	}
+	dropped := tb.budget.drop(tb.sections, seconds)
+	if len(dropped) == 0 {
+		return
+	}
+
+	var parts []string
+	for i, part := range tb.parts {
+		if !dropped[tb.partSections[i]] {
+			parts = append(parts, part)
+		}
+	}
+	var sentences []SentenceProvenance
+	for i, sentence := range tb.sentences {
+		if !dropped[tb.sentenceSections[i]] {
+			sentences = append(sentences, sentence)
+		}
+	}
+	var names []string
+	for i, section := range tb.sections {
+		if dropped[i] {
+			names = append(names, section.Name)
+		}
+	}
+	tb.parts, tb.sentences = parts, sentences
+	slog.Info("[LENGTH_BUDGET] Dropped sections to fit the target length", "bird", birdName,
+		"target_seconds", tb.budget.targetSeconds, "dropped", names)
+}
+
+// SplitAtCallMarkers returns the script's speech on either side of each call marker
+func SplitAtCallMarkers(script string) []string {
+	parts := strings.Split(script, CallMarker)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// WithoutCallCues returns the script for reading without the bird's call: each marker is removed
+// along with the sentence cueing it
+func WithoutCallCues(script string) string {
+	if !strings.Contains(script, CallMarker) {
+		return script
+	}
+
+	parts := SplitAtCallMarkers(script)
+	for i := 0; i < len(parts)-1; i++ {
+		speech := strings.TrimRight(parts[i], ".!? ")
+		parts[i] = speech[:strings.LastIndexAny(speech, ".!?")+1]
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// splitSentences breaks text on sentence-ending punctuation, dropping pause markers like ". . ."
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+
+	for i, r := range runes {
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		sentences = appendSentence(sentences, string(runes[start:i+1]))
+		start = i + 1
+	}
+	sentences = appendSentence(sentences, string(runes[start:]))
+
+	return sentences
+}
+
+func appendSentence(sentences []string, candidate string) []string {
+	candidate = strings.TrimSpace(candidate)
+	if strings.IndexFunc(candidate, unicode.IsLetter) < 0 {
+		return sentences
+	}
+	return append(sentences, candidate)
+}
+
+// transcriptPath returns where a bird's latest script transcript is stored: alongside its
+// narration, or under the transcript directory when one is set
+func (bs *BirdStorage) transcriptPath(birdName string) string {
+	dirName := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	if bs.transcriptDir != "" {
+		return filepath.Join(bs.transcriptDir, dirName, "transcript.json")
+	}
+	return filepath.Join(bs.basePath, "_global_species", dirName, "narration", "transcript.json")
+}
+
+// SaveTranscript stores a script transcript alongside the bird's narration
+func (bs *BirdStorage) SaveTranscript(transcript *ScriptTranscript) error {
+	return filex.AtomicWriteJSON(bs.transcriptPath(transcript.BirdName), transcript)
+}
+
+// GetTranscript loads the stored transcript for a bird
+func (bs *BirdStorage) GetTranscript(birdName string) (*ScriptTranscript, error) {
+	data, err := os.ReadFile(bs.transcriptPath(birdName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript for %s: %w", birdName, err)
+	}
+
+	var transcript ScriptTranscript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript for %s: %w", birdName, err)
+	}
+	return &transcript, nil
+}
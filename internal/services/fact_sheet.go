@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// FactSection is a typed slot in a fact sheet that script generators render from
+type FactSection string
+
+const (
+	FactSize         FactSection = "size"
+	FactColors       FactSection = "colors"
+	FactFieldMarks   FactSection = "field_marks"
+	FactDiet         FactSection = "diet"
+	FactNesting      FactSection = "nesting"
+	FactVocalization FactSection = "vocalization"
+	FactConservation FactSection = "conservation"
+	FactSightings    FactSection = "sightings"
+	FactAbilities    FactSection = "abilities"
+	FactFunFacts     FactSection = "fun_facts"
+	FactHabitat      FactSection = "habitat"
+)
+
+// Stored metadata fields a fact sheet records as values, named in each value's Detail
+const (
+	valueDiet                = "diet"
+	valueHabitats            = "habitats"
+	valuePrimaryHabitat      = "primary_habitat"
+	valueLengthCM            = "length_cm"
+	valueConservationStatus  = "conservation_status"
+	valueMigrationPattern    = "migration_pattern"
+	valueBreedingSeason      = "breeding_season"
+	valueDistinctiveFeatures = "distinctive_features"
+	valueFunFacts            = "fun_facts"
+)
+
+// SourcedFact is one fact in a section, tagged with where it came from. A fact is either a
+// sentence to read aloud (Text) or a bare value such as a food or a length (Value), which
+// quizzes and templates word themselves.
+type SourcedFact struct {
+	Section FactSection `json:"section"`
+	Text    string      `json:"text,omitempty"`
+	Value   string      `json:"value,omitempty"`
+	Source  string      `json:"source"`
+	Detail  string      `json:"detail,omitempty"`
+}
+
+// FactSheet is the structured set of facts known about a bird, independent of any script wording
+type FactSheet struct {
+	CommonName     string `json:"common_name"`
+	ScientificName string `json:"scientific_name"`
+	Family         string `json:"family"`
+
+	// Facts in the order sources were consulted
+	Facts []SourcedFact `json:"facts,omitempty"`
+	seen  map[string]bool
+}
+
+// NewFactSheetFromMetadata builds a fact sheet from stored bird metadata
+func NewFactSheetFromMetadata(metadata *BirdMetadata) *FactSheet {
+	if metadata == nil {
+		return nil
+	}
+
+	sheet := &FactSheet{
+		CommonName:     metadata.CommonName,
+		ScientificName: metadata.ScientificName,
+		Family:         metadata.Family,
+	}
+	for _, food := range metadata.Diet {
+		sheet.AddValue(FactDiet, food, SourceMetadata, valueDiet)
+	}
+	for _, habitat := range metadata.Habitats {
+		sheet.AddValue(FactHabitat, habitat, SourceMetadata, valueHabitats)
+	}
+	sheet.AddValue(FactHabitat, strings.ReplaceAll(metadata.PrimaryHabitat, "_", " "), SourceMetadata, valuePrimaryHabitat)
+	sheet.AddValue(FactHabitat, strings.ReplaceAll(metadata.MigrationPattern, "_", " "), SourceMetadata, valueMigrationPattern)
+	sheet.AddValue(FactSize, metadata.Size.LengthCM, SourceMetadata, valueLengthCM)
+	sheet.AddValue(FactConservation, metadata.ConservationStatus, SourceMetadata, valueConservationStatus)
+	sheet.AddValue(FactNesting, metadata.BreedingSeason, SourceMetadata, valueBreedingSeason)
+	for _, feature := range metadata.DistinctiveFeatures {
+		sheet.AddValue(FactFieldMarks, feature, SourceMetadata, valueDistinctiveFeatures)
+	}
+	for _, fact := range metadata.FunFacts {
+		sheet.AddValue(FactFunFacts, fact, SourceMetadata, valueFunFacts)
+	}
+	return sheet
+}
+
+// GetFactSheet loads the fact sheet for a bird from storage
+func (bs *BirdStorage) GetFactSheet(birdName string) (*FactSheet, error) {
+	metadata, err := bs.GetBirdMetadata(birdName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build fact sheet: %w", err)
+	}
+	return NewFactSheetFromMetadata(metadata), nil
+}
+
+// AddFact appends a fact to a section unless the same text is already on the sheet, in any section.
+// It reports whether the fact was added.
+func (fs *FactSheet) AddFact(section FactSection, text string, source string, detail string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+
+	key := normalizeFactText(text)
+	if fs.seen == nil {
+		fs.seen = make(map[string]bool)
+		for _, fact := range fs.Facts {
+			fs.seen[normalizeFactText(fact.Text)] = true
+		}
+	}
+	if fs.seen[key] {
+		return false
+	}
+
+	fs.seen[key] = true
+	fs.Facts = append(fs.Facts, SourcedFact{Section: section, Text: text, Source: source, Detail: detail})
+	return true
+}
+
+// AddValue appends a bare value to a section, named by detail. Empty values aren't added.
+func (fs *FactSheet) AddValue(section FactSection, value string, source string, detail string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	fs.Facts = append(fs.Facts, SourcedFact{Section: section, Value: value, Source: source, Detail: detail})
+}
+
+// Section returns a section's sentences in the order they were added
+func (fs *FactSheet) Section(section FactSection) []SourcedFact {
+	var facts []SourcedFact
+	for _, fact := range fs.Facts {
+		if fact.Section == section && fact.Text != "" {
+			facts = append(facts, fact)
+		}
+	}
+	return facts
+}
+
+// FirstFacts returns up to n sentences from any of the given sections, in the order they were added
+func (fs *FactSheet) FirstFacts(n int, sections ...FactSection) []SourcedFact {
+	var facts []SourcedFact
+	for _, fact := range fs.Facts {
+		if len(facts) == n {
+			break
+		}
+		if fact.Text == "" {
+			continue
+		}
+		for _, section := range sections {
+			if fact.Section == section {
+				facts = append(facts, fact)
+				break
+			}
+		}
+	}
+	return facts
+}
+
+// Values returns the values recorded under detail, in the order they were added
+func (fs *FactSheet) Values(detail string) []string {
+	var values []string
+	for _, fact := range fs.Facts {
+		if fact.Detail == detail && fact.Value != "" {
+			values = append(values, fact.Value)
+		}
+	}
+	return values
+}
+
+// value returns the first value recorded under detail, or ""
+func (fs *FactSheet) value(detail string) string {
+	if values := fs.Values(detail); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Diet lists the foods the bird eats
+func (fs *FactSheet) Diet() []string { return fs.Values(valueDiet) }
+
+// Habitats lists the places the bird can be found
+func (fs *FactSheet) Habitats() []string { return fs.Values(valueHabitats) }
+
+// PrimaryHabitat is where the bird is most often found
+func (fs *FactSheet) PrimaryHabitat() string { return fs.value(valuePrimaryHabitat) }
+
+// LengthCM is the bird's beak-to-tail length in centimeters
+func (fs *FactSheet) LengthCM() string { return fs.value(valueLengthCM) }
+
+// ConservationStatus is the bird's listed status, e.g. "least concern"
+func (fs *FactSheet) ConservationStatus() string { return fs.value(valueConservationStatus) }
+
+// MigrationPattern is how the bird moves between seasons, e.g. "short distance migrant"
+func (fs *FactSheet) MigrationPattern() string { return fs.value(valueMigrationPattern) }
+
+// BreedingSeason is when the bird raises its chicks
+func (fs *FactSheet) BreedingSeason() string { return fs.value(valueBreedingSeason) }
+
+// DistinctiveFeatures lists sentences describing how to recognize the bird
+func (fs *FactSheet) DistinctiveFeatures() []string { return fs.Values(valueDistinctiveFeatures) }
+
+// FunFacts lists the stored fun fact sentences
+func (fs *FactSheet) FunFacts() []string { return fs.Values(valueFunFacts) }
+
+// LogSources logs which sources supplied each section
+func (fs *FactSheet) LogSources() {
+	counts := make(map[FactSection]map[string]int)
+	var order []FactSection
+	for _, fact := range fs.Facts {
+		if counts[fact.Section] == nil {
+			counts[fact.Section] = make(map[string]int)
+			order = append(order, fact.Section)
+		}
+		counts[fact.Section][fact.Source]++
+	}
+
+	var parts []string
+	for _, section := range order {
+		var sources []string
+		for source, count := range counts[section] {
+			sources = append(sources, fmt.Sprintf("%s(%d)", source, count))
+		}
+		sort.Strings(sources)
+		parts = append(parts, fmt.Sprintf("%s=%s", section, strings.Join(sources, ",")))
+	}
+	log.Printf("[FACT_SHEET] %s: %s", fs.CommonName, strings.Join(parts, " "))
+}
+
+// normalizeFactText is the dedup key for a fact: lowercase, without surrounding punctuation
+func normalizeFactText(text string) string {
+	return strings.Trim(strings.ToLower(strings.Join(strings.Fields(text), " ")), ".!? ")
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/inaturalist"
+)
+
+func TestAddFactDedupesAcrossSections(t *testing.T) {
+	sheet := &FactSheet{CommonName: "American Robin"}
+
+	if !sheet.AddFact(FactDiet, "Robins eat worms.", SourceWikipedia, "diet") {
+		t.Fatal("first fact wasn't added")
+	}
+	if sheet.AddFact(FactFunFacts, "  robins eat   WORMS! ", SourceINaturalist, "fun_facts") {
+		t.Error("same text in another section was added again")
+	}
+	if sheet.AddFact(FactDiet, "   ", SourceWikipedia, "diet") {
+		t.Error("blank fact was added")
+	}
+	if got := len(sheet.Facts); got != 1 {
+		t.Fatalf("sheet has %d facts, want 1", got)
+	}
+	if fact := sheet.Facts[0]; fact.Source != SourceWikipedia || fact.Detail != "diet" {
+		t.Errorf("kept fact %+v, want the first source", fact)
+	}
+}
+
+func TestAddFactDedupesAgainstDecodedFacts(t *testing.T) {
+	// A sheet decoded from JSON has no seen map yet
+	sheet := &FactSheet{Facts: []SourcedFact{{Section: FactNesting, Text: "It nests in trees.", Source: SourceWikipedia}}}
+
+	if sheet.AddFact(FactNesting, "It nests in trees", SourceEBird, "") {
+		t.Error("fact already on the decoded sheet was added again")
+	}
+}
+
+func TestSectionAndFirstFactsKeepOrder(t *testing.T) {
+	sheet := &FactSheet{}
+	sheet.AddFact(FactDiet, "Diet one.", SourceCuratedBank, "")
+	sheet.AddFact(FactColors, "Colors one.", SourceWikipedia, "")
+	sheet.AddValue(FactDiet, "worms", SourceMetadata, valueDiet)
+	sheet.AddFact(FactDiet, "Diet two.", SourceWikipedia, "")
+	sheet.AddFact(FactSize, "Size one.", SourceWikipedia, "")
+
+	if got := factTexts(sheet.Section(FactDiet)); !reflect.DeepEqual(got, []string{"Diet one.", "Diet two."}) {
+		t.Errorf("Section(diet) = %q", got)
+	}
+	if got := factTexts(sheet.FirstFacts(2, FactSize, FactDiet)); !reflect.DeepEqual(got, []string{"Diet one.", "Diet two."}) {
+		t.Errorf("FirstFacts(2, size, diet) = %q", got)
+	}
+	if got := factTexts(sheet.FirstFacts(10, FactSize, FactColors)); !reflect.DeepEqual(got, []string{"Colors one.", "Size one."}) {
+		t.Errorf("FirstFacts(10, size, colors) = %q", got)
+	}
+}
+
+func TestFactSheetFromMetadataDerivesValues(t *testing.T) {
+	sheet := NewFactSheetFromMetadata(&BirdMetadata{
+		CommonName:          "American Robin",
+		PrimaryHabitat:      "urban_parks",
+		Habitats:            []string{"woodlands", "gardens"},
+		Diet:                []string{"worms", "berries"},
+		Size:                BirdSize{LengthCM: "25"},
+		ConservationStatus:  "least concern",
+		MigrationPattern:    "short_distance_migrant",
+		BreedingSeason:      "spring",
+		DistinctiveFeatures: []string{"It has an orange breast."},
+		FunFacts:            []string{"Robins can hear worms."},
+	})
+
+	if got := sheet.Diet(); !reflect.DeepEqual(got, []string{"worms", "berries"}) {
+		t.Errorf("Diet() = %q", got)
+	}
+	if got := sheet.Habitats(); !reflect.DeepEqual(got, []string{"woodlands", "gardens"}) {
+		t.Errorf("Habitats() = %q", got)
+	}
+	for _, tt := range []struct{ name, got, want string }{
+		{"PrimaryHabitat", sheet.PrimaryHabitat(), "urban parks"},
+		{"LengthCM", sheet.LengthCM(), "25"},
+		{"ConservationStatus", sheet.ConservationStatus(), "least concern"},
+		{"MigrationPattern", sheet.MigrationPattern(), "short distance migrant"},
+		{"BreedingSeason", sheet.BreedingSeason(), "spring"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s() = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if got := sheet.DistinctiveFeatures(); !reflect.DeepEqual(got, []string{"It has an orange breast."}) {
+		t.Errorf("DistinctiveFeatures() = %q", got)
+	}
+	if got := sheet.FunFacts(); !reflect.DeepEqual(got, []string{"Robins can hear worms."}) {
+		t.Errorf("FunFacts() = %q", got)
+	}
+
+	for _, fact := range sheet.Facts {
+		if fact.Source != SourceMetadata || fact.Text != "" {
+			t.Errorf("metadata fact %+v should be a value from %s", fact, SourceMetadata)
+		}
+	}
+	if got := sheet.FirstFacts(10, FactDiet, FactHabitat, FactFunFacts); len(got) != 0 {
+		t.Errorf("metadata values were returned as sentences: %+v", got)
+	}
+}
+
+func TestFactSheetFromMetadataSkipsEmptyFields(t *testing.T) {
+	sheet := NewFactSheetFromMetadata(&BirdMetadata{CommonName: "Mystery Bird"})
+	if len(sheet.Facts) != 0 {
+		t.Errorf("empty metadata produced facts: %+v", sheet.Facts)
+	}
+	if sheet.PrimaryHabitat() != "" || sheet.Diet() != nil {
+		t.Error("empty metadata produced values")
+	}
+	if NewFactSheetFromMetadata(nil) != nil {
+		t.Error("nil metadata produced a sheet")
+	}
+}
+
+func TestAggregateFactSheetRecordsConservationStatus(t *testing.T) {
+	sheet := AggregateFactSheet(&models.Bird{CommonName: "Snowy Owl"}, FactSources{
+		Taxon: &inaturalist.Taxon{ConservationStatus: &inaturalist.ConservationStatus{StatusName: "Vulnerable"}},
+	})
+
+	if got := sheet.ConservationStatus(); got != "vulnerable" {
+		t.Errorf("ConservationStatus() = %q, want vulnerable", got)
+	}
+	want := []string{"Scientists list the Snowy Owl as vulnerable."}
+	if got := factTexts(sheet.Section(FactConservation)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Section(conservation) = %q, want %q", got, want)
+	}
+}
+
+func TestWeeklyFactScriptWordsSheetValues(t *testing.T) {
+	sheet := NewFactSheetFromMetadata(&BirdMetadata{
+		CommonName: "American Robin",
+		Diet:       []string{"worms", "berries", "insects"},
+	})
+	theme := weeklyFactThemes[2] // Wednesday, food
+
+	script, err := BuildWeeklyFactScript("American Robin", theme, sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := theme.Opening + " Our bird of the week is the American Robin. " +
+		"Its favorite foods include worms, berries, and insects. " +
+		"Come back tomorrow to find out about " + weeklyFactThemes[3].Topic + "!"
+	if script != want {
+		t.Errorf("script =\n%q\nwant\n%q", script, want)
+	}
+}
+
+func factTexts(facts []SourcedFact) []string {
+	var texts []string
+	for _, fact := range facts {
+		texts = append(texts, fact.Text)
+	}
+	return texts
+}
@@ -0,0 +1,99 @@
+// Package factkit holds the phrasing helpers every fact generator shares, so a new script style
+// can reuse the transitions, plurals, and distance math instead of copying them
+package factkit
+
+import (
+	"math"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
+
+// TransitionKind is the job a transition does between two parts of a script
+type TransitionKind int
+
+const (
+	TransitionFact   TransitionKind = iota // Leads into a fact ("Did you know? ")
+	TransitionAction                       // Asks the listener to look or listen ("Watch for this: ")
+)
+
+var transitions = map[TransitionKind][]string{
+	TransitionFact: {
+		"Here's a feathered fact! ",
+		"Did you know? ",
+		"Fun fact: ",
+		"Here's something cool! ",
+		"Guess what? ",
+		"Want to know something special? ",
+		"Check this out: ",
+	},
+	TransitionAction: {
+		"Listen like a birdwatcher. ",
+		"Watch for this: ",
+		"Look closely, explorer! ",
+		"Keep your eyes open. ",
+		"Tune in like a bird! ",
+	},
+}
+
+// Transitions hands out transitions for one script, avoiding repeats while unused ones remain
+type Transitions struct {
+	rng  *randx.Randomizer
+	used map[string]bool
+}
+
+// NewTransitions creates the transitions for a script, drawing them from rng
+func NewTransitions(rng *randx.Randomizer) *Transitions {
+	return &Transitions{rng: rng, used: make(map[string]bool)}
+}
+
+// Next returns a transition of the given kind
+func (t *Transitions) Next(kind TransitionKind) string {
+	options := transitions[kind]
+	for attempts := 0; attempts < 10; attempts++ {
+		choice := Pick(t.rng, options)
+		if !t.used[choice] {
+			t.used[choice] = true
+			return choice
+		}
+	}
+	return Pick(t.rng, options)
+}
+
+// Pick returns one of options, chosen by rng
+func Pick(rng *randx.Randomizer, options []string) string {
+	return options[rng.Intn(len(options))]
+}
+
+// PluralS returns "s" if the count is not 1, empty string otherwise
+func PluralS(count float64) string {
+	if count == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// DistanceMiles is the great-circle distance between two coordinates, by the haversine formula
+func DistanceMiles(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 3959.0 // miles
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return earthRadius * c
+}
+
+// FamilyName turns a scientific family ("Turdidae") into the name read aloud ("Turd"), or returns
+// "" for names that aren't a Latin family
+func FamilyName(family string) string {
+	if !strings.HasSuffix(family, "idae") {
+		return ""
+	}
+	return strings.TrimSuffix(family, "idae")
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// notifyTimeout bounds each notifier's delivery of an alert
+const notifyTimeout = 30 * time.Second
+
+// CardFailure is a card update that was abandoned after its retries
+type CardFailure struct {
+	CardID   string
+	BirdName string
+	Day      string
+	Trigger  string
+	Stage    string
+	Error    string
+	Attempts int
+	Cause    string // Failures with the same cause share one alert
+}
+
+// FailureAlert is one notification for every card that failed with the same cause
+type FailureAlert struct {
+	Cause      string
+	Failures   []CardFailure
+	Suppressed int // Failures with this cause during the last alert's cooldown, which weren't sent
+}
+
+// Subject is a one-line summary of the alert
+func (a FailureAlert) Subject() string {
+	if len(a.Failures) == 1 {
+		return fmt.Sprintf("Bird Song Explorer: card %s failed to update (%s)", a.Failures[0].CardID, a.Cause)
+	}
+	return fmt.Sprintf("Bird Song Explorer: %d cards failed to update (%s)", len(a.Failures), a.Cause)
+}
+
+// Text lists each failed card with its stage and error
+func (a FailureAlert) Text() string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Cause: %s\n", a.Cause)
+	if a.Suppressed > 0 {
+		fmt.Fprintf(&text, "%d more failures with this cause weren't alerted on since the last alert\n", a.Suppressed)
+	}
+	for _, failure := range a.Failures {
+		fmt.Fprintf(&text, "\n- Card %s (%s, %s, %s) failed at %s after %d attempts: %s",
+			failure.CardID, failure.BirdName, failure.Day, failure.Trigger, failure.Stage, failure.Attempts, failure.Error)
+	}
+	return text.String()
+}
+
+// FailureNotifier delivers failure alerts to the people running the service
+type FailureNotifier interface {
+	Notify(ctx context.Context, alert FailureAlert) error
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook URL
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     httpx.NewClient(httpx.Options{Timeout: notifyTimeout}),
+	}
+}
+
+// Notify posts the alert as a Slack message
+func (n *SlackNotifier) Notify(ctx context.Context, alert FailureAlert) error {
+	body, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", alert.Subject(), alert.Text()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier mails alerts through an SMTP server
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewEmailNotifier creates a notifier that sends from one address to the recipients. Without a
+// username the server is used unauthenticated.
+func NewEmailNotifier(host string, port int, username, password, from string, to []string) *EmailNotifier {
+	notifier := &EmailNotifier{
+		addr: fmt.Sprintf("%s:%d", host, port),
+		from: from,
+		to:   to,
+	}
+	if username != "" {
+		notifier.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return notifier
+}
+
+// Notify mails the alert. net/smtp can't be cancelled, so ctx only stops a send that hasn't started.
+func (n *EmailNotifier) Notify(ctx context.Context, alert FailureAlert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", n.from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", alert.Subject())
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(alert.Text(), "\n", "\r\n"))
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, n.to, []byte(message.String())); err != nil {
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+	return nil
+}
+
+// FailureAlerts sends abandoned card updates to the notifiers, grouped by cause: the failures of
+// one cause within the window go out as a single alert, and the same cause isn't alerted on
+// again until the cooldown has passed. A provider outage that fails every card is one alert.
+type FailureAlerts struct {
+	notifiers []FailureNotifier
+	window    time.Duration
+	cooldown  time.Duration
+
+	mu         sync.Mutex
+	pending    map[string]*FailureAlert
+	lastSent   map[string]time.Time
+	suppressed map[string]int
+}
+
+// NewFailureAlerts creates an alerter; with no notifiers, failures are only logged
+func NewFailureAlerts(window, cooldown time.Duration, notifiers ...FailureNotifier) *FailureAlerts {
+	return &FailureAlerts{
+		notifiers:  notifiers,
+		window:     window,
+		cooldown:   cooldown,
+		pending:    make(map[string]*FailureAlert),
+		lastSent:   make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// Enabled reports whether any notifier is configured
+func (fa *FailureAlerts) Enabled() bool {
+	return len(fa.notifiers) > 0
+}
+
+// Report adds a failure to the pending alert for its cause, starting one if there is none
+func (fa *FailureAlerts) Report(failure CardFailure) {
+	log.Printf("[ALERT] Card %s update abandoned at %s after %d attempts: %s", failure.CardID, failure.Stage, failure.Attempts, failure.Error)
+	if !fa.Enabled() {
+		return
+	}
+
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+
+	if alert, ok := fa.pending[failure.Cause]; ok {
+		alert.Failures = append(alert.Failures, failure)
+		return
+	}
+	if sent, ok := fa.lastSent[failure.Cause]; ok && time.Since(sent) < fa.cooldown {
+		fa.suppressed[failure.Cause]++
+		return
+	}
+
+	fa.pending[failure.Cause] = &FailureAlert{
+		Cause:      failure.Cause,
+		Failures:   []CardFailure{failure},
+		Suppressed: fa.suppressed[failure.Cause],
+	}
+	delete(fa.suppressed, failure.Cause)
+	time.AfterFunc(fa.window, func() { fa.send(failure.Cause) })
+}
+
+// send delivers a cause's pending alert to every notifier
+func (fa *FailureAlerts) send(cause string) {
+	fa.mu.Lock()
+	alert, ok := fa.pending[cause]
+	delete(fa.pending, cause)
+	fa.lastSent[cause] = time.Now()
+	fa.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	sort.Slice(alert.Failures, func(i, j int) bool {
+		return alert.Failures[i].CardID < alert.Failures[j].CardID
+	})
+	for _, notifier := range fa.notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := notifier.Notify(ctx, *alert); err != nil {
+			log.Printf("[ALERT] Failed to send alert for %s: %v", cause, err)
+		}
+		cancel()
+	}
+	log.Printf("[ALERT] Sent alert for %d failed cards: %s", len(alert.Failures), cause)
+}
+
+var failureNumbers = regexp.MustCompile(`[0-9]+`)
+
+// maxCauseLength keeps error text used as a cause to a readable length
+const maxCauseLength = 160
+
+// FailureCause names what a failure is grouped on: the dependencies that are down, when any are,
+// otherwise the stage and error with the card ID and numbers (status codes, timestamps, IDs)
+// masked, so cards failing the same way share a cause
+func FailureCause(stage string, message string, cardID string, down []string) string {
+	if len(down) > 0 {
+		down = append([]string(nil), down...)
+		sort.Strings(down)
+		return strings.Join(down, ", ") + " down"
+	}
+
+	if cardID != "" {
+		message = strings.ReplaceAll(message, cardID, "<card>")
+	}
+	message = failureNumbers.ReplaceAllString(message, "#")
+	if len(message) > maxCauseLength {
+		message = message[:maxCauseLength] + "..."
+	}
+	return stage + ": " + message
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// primerWindow is how long a device counts as a new listener
+const primerWindow = 7 * 24 * time.Hour
+
+// FamilyPrimer is a short explanation of a bird family's signature sound style
+type FamilyPrimer struct {
+	Family string `json:"family"`
+	Key    string `json:"key"` // Audio file name under birds/_primers/
+	Text   string `json:"text"`
+}
+
+// familyPrimers maps scientific family names to their sound signature primers
+var familyPrimers = map[string]FamilyPrimer{
+	"Picidae": {
+		Key:  "woodpeckers",
+		Text: "Woodpeckers drum instead of singing! Listen for a fast rat-a-tat-tat on a tree trunk.",
+	},
+	"Alcidae": {
+		Key:  "auks",
+		Text: "Puffins and their cousins don't sing pretty songs. They growl and grumble, almost like a chainsaw!",
+	},
+	"Accipitridae": {
+		Key:  "raptors",
+		Text: "Eagles and hawks have big bodies but surprisingly small voices. Listen for high whistles and chirpy calls!",
+	},
+	"Apterygidae": {
+		Key:  "kiwis",
+		Text: "Kiwis call out at night with loud, shrill whistles so other kiwis can find them in the dark.",
+	},
+	"Alcedinidae": {
+		Key:  "kingfishers",
+		Text: "Kingfishers make sharp, rattling calls. Some of their cousins, the kookaburras, sound like they're laughing!",
+	},
+	"Icteridae": {
+		Key:  "blackbirds",
+		Text: "Meadowlarks and their relatives sing bubbly, flute-like songs from fence posts and treetops.",
+	},
+	"Strigidae": {
+		Key:  "owls",
+		Text: "Owls hoot! Listen for deep, soft hoo-hoo sounds, usually at night.",
+	},
+	"Turdidae": {
+		Key:  "thrushes",
+		Text: "Thrushes, like robins, sing cheerful songs made of short, whistled phrases.",
+	},
+}
+
+// GetFamilyPrimer returns the primer for a scientific family name
+func GetFamilyPrimer(family string) (FamilyPrimer, bool) {
+	primer, ok := familyPrimers[strings.TrimSpace(family)]
+	if !ok {
+		return FamilyPrimer{}, false
+	}
+	primer.Family = family
+	return primer, true
+}
+
+// PrimerService decides when a new listener should hear a family primer before the guide
+type PrimerService struct {
+	registry *DeviceRegistry
+	storage  *BirdStorage
+	window   time.Duration
+}
+
+// NewPrimerService creates a new primer service
+func NewPrimerService(registry *DeviceRegistry, storage *BirdStorage) *PrimerService {
+	return &PrimerService{
+		registry: registry,
+		storage:  storage,
+		window:   primerWindow,
+	}
+}
+
+// PrimerForDevice returns the family primer if the device is still in its first week
+func (ps *PrimerService) PrimerForDevice(deviceID string, birdName string) (FamilyPrimer, bool) {
+	if !ps.registry.IsNewListener(deviceID, ps.window) {
+		return FamilyPrimer{}, false
+	}
+
+	metadata, err := ps.storage.GetBirdMetadata(birdName)
+	if err != nil {
+		log.Printf("[PRIMER] No metadata for %s, skipping primer: %v", birdName, err)
+		return FamilyPrimer{}, false
+	}
+
+	return GetFamilyPrimer(metadata.Family)
+}
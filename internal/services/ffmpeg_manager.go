@@ -0,0 +1,333 @@
+package services
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Pinned static ffmpeg build used when the container image does not ship ffmpeg.
+// These are single gzip-compressed binaries, so no archive tooling is needed to unpack them.
+// Each binary is only installed and run when it matches its pinned SHA-256
+// (FFmpegOptions.FFmpegSHA256, FFprobeSHA256) for the release and architecture.
+const defaultFFmpegStaticURL = "https://github.com/eugeneware/ffmpeg-static/releases/download/b6.0"
+
+// FFmpegCapabilities describes which audio operations the local ffmpeg install supports
+type FFmpegCapabilities struct {
+	Available   bool   `json:"available"`    // ffmpeg binary found and runnable
+	Probe       bool   `json:"probe"`        // ffprobe available for duration detection
+	Mixing      bool   `json:"mixing"`       // amix filter available
+	Ducking     bool   `json:"ducking"`      // sidechaincompress filter available
+	Fades       bool   `json:"fades"`        // afade filter available
+	Loudnorm    bool   `json:"loudnorm"`     // loudnorm filter available
+	MP3Encode   bool   `json:"mp3_encode"`   // libmp3lame encoder available
+	Version     string `json:"version"`      // First line of `ffmpeg -version`
+	FFmpegPath  string `json:"ffmpeg_path"`  // Resolved ffmpeg binary path
+	FFprobePath string `json:"ffprobe_path"` // Resolved ffprobe binary path
+	Downloaded  bool   `json:"downloaded"`   // Binary came from the pinned static build
+}
+
+// FFmpegManager locates, optionally downloads, and verifies the ffmpeg binaries
+type FFmpegManager struct {
+	installDir   string
+	downloadURL  string
+	expectedSHA  map[string]string // Pinned SHA-256 of each decompressed binary
+	autoDownload bool
+	client       *http.Client
+
+	mu   sync.RWMutex
+	caps *FFmpegCapabilities
+}
+
+var (
+	defaultFFmpegManager *FFmpegManager
+	ffmpegBootstrapOnce  sync.Once
+)
+
+// FFmpegOptions says where the pinned static ffmpeg build comes from and whether to download it
+type FFmpegOptions struct {
+	InstallDir    string // Where the static binaries are installed (default under the temp dir)
+	StaticURL     string // Release the binaries are downloaded from (default the pinned release)
+	FFmpegSHA256  string // Pinned SHA-256 of the decompressed ffmpeg binary
+	FFprobeSHA256 string // Pinned SHA-256 of the decompressed ffprobe binary
+	AutoDownload  bool   // Download the static build when ffmpeg isn't installed
+}
+
+// NewFFmpegManager creates a new ffmpeg manager
+func NewFFmpegManager(options FFmpegOptions) *FFmpegManager {
+	installDir := options.InstallDir
+	if installDir == "" {
+		// Cloud Run only guarantees /tmp is writable
+		installDir = filepath.Join(os.TempDir(), "ffmpeg-static")
+	}
+
+	downloadURL := options.StaticURL
+	if downloadURL == "" {
+		downloadURL = defaultFFmpegStaticURL
+	}
+
+	return &FFmpegManager{
+		installDir:  installDir,
+		downloadURL: strings.TrimSuffix(downloadURL, "/"),
+		expectedSHA: map[string]string{
+			"ffmpeg":  strings.ToLower(options.FFmpegSHA256),
+			"ffprobe": strings.ToLower(options.FFprobeSHA256),
+		},
+		autoDownload: options.AutoDownload,
+		client:       httpx.NewClient(httpx.Options{Timeout: 5 * time.Minute, AttemptTimeout: 2 * time.Minute}),
+	}
+}
+
+// BootstrapFFmpeg verifies the ffmpeg install at startup and records its capabilities. Only the
+// first call's options are used.
+func BootstrapFFmpeg(options FFmpegOptions) *FFmpegCapabilities {
+	ffmpegBootstrapOnce.Do(func() {
+		defaultFFmpegManager = NewFFmpegManager(options)
+		defaultFFmpegManager.Bootstrap()
+	})
+	return defaultFFmpegManager.Capabilities()
+}
+
+// GetFFmpegCapabilities returns the capabilities detected at startup, bootstrapping with the
+// installed ffmpeg if needed
+func GetFFmpegCapabilities() FFmpegCapabilities {
+	return *BootstrapFFmpeg(FFmpegOptions{})
+}
+
+// ffmpegBinary returns the resolved ffmpeg path, falling back to PATH lookup
+func ffmpegBinary() string {
+	if caps := GetFFmpegCapabilities(); caps.FFmpegPath != "" {
+		return caps.FFmpegPath
+	}
+	return "ffmpeg"
+}
+
+// ffprobeBinary returns the resolved ffprobe path, falling back to PATH lookup
+func ffprobeBinary() string {
+	if caps := GetFFmpegCapabilities(); caps.FFprobePath != "" {
+		return caps.FFprobePath
+	}
+	return "ffprobe"
+}
+
+// Bootstrap locates ffmpeg, downloading the pinned static build if allowed, and detects capabilities
+func (m *FFmpegManager) Bootstrap() *FFmpegCapabilities {
+	caps := &FFmpegCapabilities{}
+
+	ffmpegPath := m.locate("ffmpeg")
+	if ffmpegPath == "" && m.autoDownload {
+		slog.Info("[FFMPEG] ffmpeg not found, downloading static build", "dir", m.installDir)
+		if path, err := m.download("ffmpeg"); err != nil {
+			slog.Error("[FFMPEG] Failed to download ffmpeg", "error", err)
+		} else {
+			ffmpegPath = path
+			caps.Downloaded = true
+		}
+	}
+
+	ffprobePath := m.locate("ffprobe")
+	if ffprobePath == "" && m.autoDownload {
+		if path, err := m.download("ffprobe"); err != nil {
+			slog.Error("[FFMPEG] Failed to download ffprobe", "error", err)
+		} else {
+			ffprobePath = path
+		}
+	}
+
+	if ffmpegPath != "" {
+		m.detectCapabilities(ffmpegPath, caps)
+	}
+	if ffprobePath != "" {
+		if err := exec.Command(ffprobePath, "-version").Run(); err == nil {
+			caps.Probe = true
+			caps.FFprobePath = ffprobePath
+		}
+	}
+
+	if caps.Available {
+		slog.Info("[FFMPEG] ffmpeg available", "version", caps.Version, "mix", caps.Mixing, "duck", caps.Ducking, "fade", caps.Fades,
+			"loudnorm", caps.Loudnorm, "mp3", caps.MP3Encode, "probe", caps.Probe)
+	} else {
+		slog.Warn("[FFMPEG] ffmpeg unavailable - fades and mixing are disabled (set FFMPEG_AUTO_DOWNLOAD=true with FFMPEG_STATIC_SHA256 and FFPROBE_STATIC_SHA256 to fetch a pinned static build)")
+	}
+
+	m.mu.Lock()
+	m.caps = caps
+	m.mu.Unlock()
+
+	return caps
+}
+
+// Capabilities returns a copy of the most recently detected capabilities
+func (m *FFmpegManager) Capabilities() *FFmpegCapabilities {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.caps == nil {
+		return &FFmpegCapabilities{}
+	}
+	caps := *m.caps
+	return &caps
+}
+
+// locate finds a binary in PATH or in the install directory. Binaries in the install directory
+// were downloaded, so they are only used while they match their pinned checksum.
+func (m *FFmpegManager) locate(name string) string {
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+
+	localPath := filepath.Join(m.installDir, name)
+	if info, err := os.Stat(localPath); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+		if err := m.verifyFile(name, localPath); err != nil {
+			slog.Warn("[FFMPEG] Refusing installed binary", "name", name, "path", localPath, "error", err)
+			return ""
+		}
+		return localPath
+	}
+
+	return ""
+}
+
+// verifyFile checks a binary on disk against its pinned checksum
+func (m *FFmpegManager) verifyFile(name string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return m.verifySum(name, hex.EncodeToString(hasher.Sum(nil)))
+}
+
+// verifySum checks a binary's SHA-256 against its pin; binaries without a pin are refused
+func (m *FFmpegManager) verifySum(name string, sum string) error {
+	expected := m.expectedSHA[name]
+	if expected == "" {
+		return fmt.Errorf("no pinned checksum for %s (set %s_STATIC_SHA256)", name, strings.ToUpper(name))
+	}
+	if sum != expected {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, sum, expected)
+	}
+	return nil
+}
+
+// download fetches a gzip-compressed static binary into the install directory
+func (m *FFmpegManager) download(name string) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("static builds are only pinned for linux, not %s", runtime.GOOS)
+	}
+
+	arch := "x64"
+	if runtime.GOARCH == "arm64" {
+		arch = "arm64"
+	}
+
+	if m.expectedSHA[name] == "" {
+		return "", fmt.Errorf("no pinned checksum for %s (set %s_STATIC_SHA256)", name, strings.ToUpper(name))
+	}
+
+	if err := os.MkdirAll(m.installDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create install directory: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s-linux-%s.gz", m.downloadURL, name, arch)
+	resp, err := m.client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download of %s failed with status %d", name, resp.StatusCode)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress %s: %w", name, err)
+	}
+	defer gz.Close()
+
+	tmpFile, err := os.CreateTemp(m.installDir, name+"-*.partial")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpFile, hasher), gz); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	tmpFile.Close()
+
+	// Nothing downloaded is made executable unless it matches its pin
+	if err := m.verifySum(name, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+		return "", err
+	}
+
+	finalPath := filepath.Join(m.installDir, name)
+	if err := os.Chmod(tmpFile.Name(), 0755); err != nil {
+		return "", fmt.Errorf("failed to make %s executable: %w", name, err)
+	}
+	if err := os.Rename(tmpFile.Name(), finalPath); err != nil {
+		return "", fmt.Errorf("failed to install %s: %w", name, err)
+	}
+
+	slog.Info("[FFMPEG] Installed binary", "name", name, "path", finalPath)
+	return finalPath, nil
+}
+
+// detectCapabilities runs ffmpeg to check its version, filters, and encoders
+func (m *FFmpegManager) detectCapabilities(ffmpegPath string, caps *FFmpegCapabilities) {
+	versionOut, err := exec.Command(ffmpegPath, "-hide_banner", "-version").Output()
+	if err != nil {
+		slog.Warn("[FFMPEG] ffmpeg is not runnable", "path", ffmpegPath, "error", err)
+		return
+	}
+
+	caps.Available = true
+	caps.FFmpegPath = ffmpegPath
+	caps.Version = strings.TrimSpace(strings.SplitN(string(versionOut), "\n", 2)[0])
+
+	if filtersOut, err := exec.Command(ffmpegPath, "-hide_banner", "-filters").Output(); err == nil {
+		filters := string(filtersOut)
+		caps.Mixing = hasFFmpegEntry(filters, "amix")
+		caps.Ducking = hasFFmpegEntry(filters, "sidechaincompress")
+		caps.Fades = hasFFmpegEntry(filters, "afade")
+		caps.Loudnorm = hasFFmpegEntry(filters, "loudnorm")
+	}
+
+	if encodersOut, err := exec.Command(ffmpegPath, "-hide_banner", "-encoders").Output(); err == nil {
+		caps.MP3Encode = hasFFmpegEntry(string(encodersOut), "libmp3lame")
+	}
+}
+
+// hasFFmpegEntry checks whether a filter/encoder name appears as a column in ffmpeg's listing output
+func hasFFmpegEntry(listing string, name string) bool {
+	for _, line := range strings.Split(listing, "\n") {
+		for _, field := range strings.Fields(line) {
+			if field == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Place is where a coordinate is, as named for listeners
+type Place struct {
+	City        string // City, town, or village; "" when the point isn't in one
+	State       string // State, province, or other first-level region
+	Country     string
+	CountryCode string // ISO 3166-1 alpha-2, upper case
+}
+
+// Geocoder names the place at a coordinate
+type Geocoder interface {
+	ReverseGeocode(ctx context.Context, lat, lng float64) (*Place, error)
+}
+
+// errNoPlace is returned for coordinates a geocoder can't name, e.g. open sea
+var errNoPlace = errors.New("no place found at coordinates")
+
+var (
+	sharedGeocoder     Geocoder
+	sharedGeocoderOnce sync.Once
+)
+
+// ConfigureGeocoder sets up the process-wide cached geocoder. Call it at startup, before any
+// location is looked up; later calls have no effect.
+func ConfigureGeocoder(provider, openCageAPIKey, nominatimURL string) {
+	sharedGeocoderOnce.Do(func() {
+		sharedGeocoder = NewGeocoder(provider, openCageAPIKey, nominatimURL)
+	})
+}
+
+// SharedGeocoder returns the process-wide cached geocoder, the public Nominatim server when
+// ConfigureGeocoder wasn't called
+func SharedGeocoder() Geocoder {
+	ConfigureGeocoder("nominatim", "", "")
+	return sharedGeocoder
+}
+
+// NewGeocoder uses OpenCage when provider is "opencage" (with openCageAPIKey), otherwise
+// OpenStreetMap's Nominatim at nominatimURL. A provider of "none" disables reverse geocoding.
+// Results are cached.
+func NewGeocoder(provider, openCageAPIKey, nominatimURL string) Geocoder {
+	switch provider {
+	case "none":
+		return nil
+	case "opencage":
+		if openCageAPIKey != "" {
+			return NewCachingGeocoder(NewOpenCageGeocoder(openCageAPIKey), geocodeCacheTTL)
+		}
+		log.Printf("[GEOCODER] OPENCAGE_API_KEY is not set, using Nominatim")
+	}
+	return NewCachingGeocoder(NewNominatimGeocoder(nominatimURL), geocodeCacheTTL)
+}
+
+// NominatimGeocoder reverse geocodes with a Nominatim server, by default the public
+// OpenStreetMap one, whose usage policy allows one request per second
+type NominatimGeocoder struct {
+	baseURL     string
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+// nominatimInterval spaces requests to the public Nominatim server
+const nominatimInterval = time.Second
+
+// NewNominatimGeocoder creates a geocoder for the server at baseURL ("" for OpenStreetMap's)
+func NewNominatimGeocoder(baseURL string) *NominatimGeocoder {
+	if baseURL == "" {
+		baseURL = "https://nominatim.openstreetmap.org"
+	}
+	return &NominatimGeocoder{baseURL: baseURL}
+}
+
+func (g *NominatimGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*Place, error) {
+	// Requests are serialized and spaced to respect the usage policy
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if wait := nominatimInterval - time.Since(g.lastRequest); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	g.lastRequest = time.Now()
+
+	query := url.Values{}
+	query.Set("format", "jsonv2")
+	query.Set("lat", fmt.Sprintf("%.5f", lat))
+	query.Set("lon", fmt.Sprintf("%.5f", lng))
+	query.Set("zoom", "10") // City level
+	query.Set("accept-language", "en")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", g.baseURL+"/reverse?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Nominatim requires an identifying User-Agent
+	req.Header.Set("User-Agent", "BirdSongExplorer/1.0")
+
+	resp, err := httpx.Default.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Error   string            `json:"error"`
+		Address map[string]string `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode nominatim response: %w", err)
+	}
+	if result.Error != "" || len(result.Address) == 0 {
+		return nil, errNoPlace
+	}
+	return placeFromAddress(result.Address), nil
+}
+
+// OpenCageGeocoder reverse geocodes with the OpenCage API
+type OpenCageGeocoder struct {
+	apiKey string
+}
+
+// NewOpenCageGeocoder creates a geocoder using the OpenCage API key
+func NewOpenCageGeocoder(apiKey string) *OpenCageGeocoder {
+	return &OpenCageGeocoder{apiKey: apiKey}
+}
+
+func (g *OpenCageGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*Place, error) {
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf("%.5f+%.5f", lat, lng))
+	query.Set("key", g.apiKey)
+	query.Set("language", "en")
+	query.Set("no_annotations", "1")
+	query.Set("limit", "1")
+
+	resp, err := httpx.Get(ctx, httpx.Default, "https://api.opencagedata.com/geocode/v1/json?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("opencage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("opencage returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Results []struct {
+			Components map[string]interface{} `json:"components"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode opencage response: %w", err)
+	}
+	if len(result.Results) == 0 {
+		return nil, errNoPlace
+	}
+
+	// Components mix strings with numbers (e.g. house numbers); only the names are needed
+	address := make(map[string]string)
+	for key, value := range result.Results[0].Components {
+		if text, ok := value.(string); ok {
+			address[key] = text
+		}
+	}
+	return placeFromAddress(address), nil
+}
+
+// placeFromAddress reads a Nominatim or OpenCage address. Both use OpenStreetMap's address keys.
+func placeFromAddress(address map[string]string) *Place {
+	place := &Place{
+		City:        firstAddressField(address, "city", "town", "village", "municipality", "hamlet"),
+		State:       firstAddressField(address, "state", "province", "region", "state_district", "county"),
+		Country:     address["country"],
+		CountryCode: address["country_code"],
+	}
+	place.CountryCode = strings.ToUpper(place.CountryCode)
+	return place
+}
+
+func firstAddressField(address map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := address[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// geocodeCacheTTL is how long a named place is reused; towns don't move
+const geocodeCacheTTL = 30 * 24 * time.Hour
+
+// geocodeFailureTTL is how long a failed lookup is remembered before it's retried
+const geocodeFailureTTL = time.Hour
+
+// CachingGeocoder caches another geocoder's results by coordinates rounded to about 1 km,
+// so every play from the same household reuses one lookup
+type CachingGeocoder struct {
+	geocoder Geocoder
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]geocodeEntry
+}
+
+type geocodeEntry struct {
+	place   *Place
+	err     error
+	expires time.Time
+}
+
+// NewCachingGeocoder wraps geocoder with a cache keeping places for ttl
+func NewCachingGeocoder(geocoder Geocoder, ttl time.Duration) *CachingGeocoder {
+	return &CachingGeocoder{
+		geocoder: geocoder,
+		ttl:      ttl,
+		entries:  make(map[string]geocodeEntry),
+	}
+}
+
+func (g *CachingGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*Place, error) {
+	key := fmt.Sprintf("%.2f,%.2f", math.Round(lat*100)/100, math.Round(lng*100)/100)
+
+	g.mu.Lock()
+	entry, cached := g.entries[key]
+	g.mu.Unlock()
+	if cached && time.Now().Before(entry.expires) {
+		return entry.place, entry.err
+	}
+
+	place, err := g.geocoder.ReverseGeocode(ctx, lat, lng)
+	if ctx.Err() != nil {
+		// A cancelled lookup says nothing about the place, so it isn't cached
+		return nil, ctx.Err()
+	}
+	entry = geocodeEntry{place: place, err: err, expires: time.Now().Add(g.ttl)}
+	if err != nil {
+		log.Printf("[GEOCODER] Reverse geocoding %s failed: %v", key, err)
+		entry.expires = time.Now().Add(geocodeFailureTTL)
+	}
+
+	g.mu.Lock()
+	g.entries[key] = entry
+	// Expired entries are dropped as the cache grows, so it stays bounded by active households
+	if len(g.entries) > 1000 {
+		now := time.Now()
+		for k, e := range g.entries {
+			if now.After(e.expires) {
+				delete(g.entries, k)
+			}
+		}
+	}
+	g.mu.Unlock()
+	return place, err
+}
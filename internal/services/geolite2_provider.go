@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/geoip"
+)
+
+// GeoLite2Provider locates IPs in a local MaxMind GeoLite2-City database, so lookups don't hit
+// the remote API's rate limit. The database can be swapped for a newer download and reloaded
+// with SIGHUP without restarting.
+type GeoLite2Provider struct {
+	path string
+
+	mu     sync.RWMutex
+	reader *geoip.Reader
+}
+
+// NewGeoLite2Provider opens the .mmdb file at path
+func NewGeoLite2Provider(path string) (*GeoLite2Provider, error) {
+	p := &GeoLite2Provider{path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *GeoLite2Provider) Name() string {
+	return "geolite2"
+}
+
+// Reload re-reads the database file, keeping the loaded one if the new file can't be opened
+func (p *GeoLite2Provider) Reload() error {
+	reader, err := geoip.Open(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to open GeoLite2 database %s: %w", p.path, err)
+	}
+
+	p.mu.Lock()
+	p.reader = reader
+	p.mu.Unlock()
+
+	metadata := reader.Metadata()
+	log.Printf("[LOCATION] Loaded %s database from %s (%d nodes)", metadata.DatabaseType, p.path, metadata.NodeCount)
+	return nil
+}
+
+// ReloadOnSIGHUP reloads the database whenever the process receives SIGHUP
+func (p *GeoLite2Provider) ReloadOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			if err := p.Reload(); err != nil {
+				log.Printf("[LOCATION] GeoLite2 reload failed, keeping the loaded database: %v", err)
+			}
+		}
+	}()
+}
+
+func (p *GeoLite2Provider) Locate(ip string) (*models.Location, error) {
+	address := net.ParseIP(ip)
+	if address == nil {
+		return nil, fmt.Errorf("invalid IP address for geolocation: %s", ip)
+	}
+
+	p.mu.RLock()
+	reader := p.reader
+	p.mu.RUnlock()
+
+	city, err := reader.City(address)
+	if err != nil {
+		return nil, fmt.Errorf("GeoLite2 lookup failed: %w", err)
+	}
+	if city == nil || !city.HasLocation {
+		return nil, fmt.Errorf("no GeoLite2 location for %s", ip)
+	}
+
+	log.Printf("[LOCATION] Resolved IP %s to %s, %s from GeoLite2", ip, city.City, city.Country)
+	return &models.Location{
+		Latitude:  city.Latitude,
+		Longitude: city.Longitude,
+		City:      city.City,
+		Region:    city.Subdivision,
+		Country:   city.Country,
+		IPAddress: ip,
+	}, nil
+}
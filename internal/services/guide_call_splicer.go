@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+const (
+	callSnippetSeconds   = 5.0
+	callSnippetMaxCached = 50
+)
+
+// GuideCallSplicer narrates Explorer's Guide scripts with the bird's real call: the speech on
+// either side of each call marker is rendered on its own, and a five-second snippet of the bird's
+// recording plays in between
+type GuideCallSplicer struct {
+	recordings *RecordingSelector
+	warmed     *RecordingWarmer // Optional pre-cached recordings, checked before the recording sources
+	tts        *ElevenLabsTTS
+	processor  AudioProcessor
+	trimmer    *SongTrimmer
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	snippets map[string][]byte // bird -> call snippet
+	guides   map[string][]byte // bird, voice, and script -> spliced guide
+}
+
+// NewGuideCallSplicer creates a splicer using the shared recording selector for the snippets
+func NewGuideCallSplicer(recordings *RecordingSelector, tts *ElevenLabsTTS) *GuideCallSplicer {
+	return &GuideCallSplicer{
+		recordings: recordings,
+		tts:        tts,
+		processor:  NewAudioProcessor(),
+		trimmer:    NewSongTrimmer(callSnippetSeconds, callSnippetSeconds),
+		httpClient: recordingDownloadClient,
+		snippets:   make(map[string][]byte),
+		guides:     make(map[string][]byte),
+	}
+}
+
+// SetRecordingCache reads recordings the warmer has already cached before asking the recording
+// sources
+func (gs *GuideCallSplicer) SetRecordingCache(warmer *RecordingWarmer) {
+	gs.warmed = warmer
+}
+
+// Render narrates the script in the voice with the bird's call spliced in at each call marker.
+// Scripts without a marker are rendered whole, and when the bird has no usable recording the
+// script is read without its call cues.
+func (gs *GuideCallSplicer) Render(ctx context.Context, birdName string, script string, voiceID string) ([]byte, error) {
+	if !strings.Contains(script, CallMarker) {
+		audio, _, err := gs.tts.Render(ctx, script, voiceID)
+		return audio, err
+	}
+
+	key := strings.ToLower(birdName) + "|" + voiceID + "|" + script
+	gs.mu.Lock()
+	cached, ok := gs.guides[key]
+	gs.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	snippet, err := gs.snippet(ctx, birdName)
+	if err != nil {
+		slog.InfoContext(ctx, "[GUIDE_CALL] No call snippet, reading the guide without it", "bird", birdName, "error", err)
+		audio, _, err := gs.tts.Render(ctx, WithoutCallCues(script), voiceID)
+		return audio, err
+	}
+
+	var segments []AudioSegment
+	var calls []int
+	for i, speech := range SplitAtCallMarkers(script) {
+		if i > 0 {
+			calls = append(calls, len(segments))
+			segments = append(segments, AudioSegment{Label: "call snippet", Audio: snippet})
+		}
+		if speech == "" {
+			continue
+		}
+		audio, _, err := gs.tts.Render(ctx, speech, voiceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render guide speech: %w", err)
+		}
+		segments = append(segments, AudioSegment{Label: fmt.Sprintf("speech %d", i+1), Audio: audio})
+	}
+
+	guide, offsets, err := AssembleSegments(gs.processor, segments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to splice call into guide: %w", err)
+	}
+	for _, call := range calls {
+		slog.InfoContext(ctx, "[GUIDE_CALL] Spliced call into guide", "bird", birdName, "at_seconds", offsets[call])
+	}
+
+	gs.mu.Lock()
+	if len(gs.guides) >= callSnippetMaxCached {
+		gs.guides = make(map[string][]byte)
+	}
+	gs.guides[key] = guide
+	gs.mu.Unlock()
+	return guide, nil
+}
+
+// snippet returns five seconds of the bird's most active calling, faded at both ends
+func (gs *GuideCallSplicer) snippet(ctx context.Context, birdName string) ([]byte, error) {
+	key := strings.ToLower(birdName)
+	gs.mu.Lock()
+	cached, ok := gs.snippets[key]
+	gs.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	scientificName := gs.recordings.scientificName(birdName)
+	recording, audio, warmed := gs.warmed.Cached(scientificName)
+	if !warmed {
+		var err error
+		if recording, err = gs.recordings.FindRecording(ctx, scientificName); err != nil {
+			return nil, fmt.Errorf("no recording for %s: %w", birdName, err)
+		}
+		if audio, err = gs.download(ctx, recording.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	// The trimmer centers on the loudest calling but leaves short recordings alone, so the
+	// snippet is cut to length after it
+	audio, err := gs.trimmer.Trim(audio)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := gs.processor.Duration(audio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure recording (%s): %w", gs.processor.Name(), err)
+	}
+	snippet, _, err := AssembleSegments(gs.processor, []AudioSegment{{
+		Label:    "call snippet",
+		Audio:    audio,
+		Duration: min(duration, callSnippetSeconds),
+		FadeIn:   0.3,
+		FadeOut:  0.5,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	gs.mu.Lock()
+	if len(gs.snippets) >= callSnippetMaxCached {
+		gs.snippets = make(map[string][]byte)
+	}
+	gs.snippets[key] = snippet
+	gs.mu.Unlock()
+
+	slog.InfoContext(ctx, "[GUIDE_CALL] Cut call snippet", "bird", birdName, "source", recording.Source, "recording", recording.ID)
+	return snippet, nil
+}
+
+// download fetches a recording
+func (gs *GuideCallSplicer) download(ctx context.Context, url string) ([]byte, error) {
+	resp, err := httpx.Get(ctx, gs.httpClient, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("recording download returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Holiday is a themed day with its own greeting and preferred species. The theme calendar runs
+// each holiday as a one-day theme.
+// Fixed-date holidays set Month and Day; floating ones set Month, Week (1-4, or -1 for last), and Weekday.
+type Holiday struct {
+	Key             string       `json:"key"` // Theme key, naming its asset folder under birds/_themes/
+	Name            string       `json:"name"`
+	Month           time.Month   `json:"month"`
+	Day             int          `json:"day,omitempty"`
+	Week            int          `json:"week,omitempty"`
+	Weekday         time.Weekday `json:"weekday,omitempty"`
+	Greeting        string       `json:"greeting"` // Template; {{.BirdName}} is available
+	SpeciesKeywords []string     `json:"species_keywords"`
+}
+
+// holidayCalendars are the built-in calendars per locale
+var holidayCalendars = map[string][]Holiday{
+	"en-US": {
+		{Key: "new_year", Name: "New Year's Day", Month: time.January, Day: 1, Greeting: "Happy New Year, explorers! Let's start the year with the {{.BirdName}}!", SpeciesKeywords: []string{"Dove"}},
+		{Key: "valentines", Name: "Valentine's Day", Month: time.February, Day: 14, Greeting: "Happy Valentine's Day, explorers! Today's bird is the lovely {{.BirdName}}!", SpeciesKeywords: []string{"Dove", "Cardinal", "Lovebird"}},
+		{Key: "earth_day", Name: "Earth Day", Month: time.April, Day: 22, Greeting: "Happy Earth Day, explorers! Let's celebrate our planet with the {{.BirdName}}!"},
+		{Key: "independence_day", Name: "Independence Day", Month: time.July, Day: 4, Greeting: "Happy Fourth of July, explorers! Today's bird is the {{.BirdName}}!", SpeciesKeywords: []string{"Eagle"}},
+		{Key: "halloween", Name: "Halloween", Month: time.October, Day: 31, Greeting: "Happy Halloween, night explorers! Who's hooting in the dark? It's the {{.BirdName}}!", SpeciesKeywords: []string{"Owl", "Raven", "Crow"}},
+		{Key: "thanksgiving", Name: "Thanksgiving", Month: time.November, Week: 4, Weekday: time.Thursday, Greeting: "Happy Thanksgiving, explorers! We're thankful for the {{.BirdName}}!", SpeciesKeywords: []string{"Turkey"}},
+		{Key: "christmas", Name: "Christmas", Month: time.December, Day: 25, Greeting: "Merry Christmas, explorers! Today's special bird is the {{.BirdName}}!", SpeciesKeywords: []string{"Robin", "Cardinal"}},
+	},
+	"en-GB": {
+		{Key: "new_year", Name: "New Year's Day", Month: time.January, Day: 1, Greeting: "Happy New Year, explorers! Let's start the year with the {{.BirdName}}!", SpeciesKeywords: []string{"Dove"}},
+		{Key: "valentines", Name: "Valentine's Day", Month: time.February, Day: 14, Greeting: "Happy Valentine's Day, explorers! Today's bird is the lovely {{.BirdName}}!", SpeciesKeywords: []string{"Dove"}},
+		{Key: "earth_day", Name: "Earth Day", Month: time.April, Day: 22, Greeting: "Happy Earth Day, explorers! Let's celebrate our planet with the {{.BirdName}}!"},
+		{Key: "halloween", Name: "Halloween", Month: time.October, Day: 31, Greeting: "Happy Halloween, night explorers! Who's hooting in the dark? It's the {{.BirdName}}!", SpeciesKeywords: []string{"Owl", "Raven", "Crow"}},
+		{Key: "bonfire_night", Name: "Bonfire Night", Month: time.November, Day: 5, Greeting: "Happy Bonfire Night, explorers! Before the fireworks, let's meet the {{.BirdName}}!", SpeciesKeywords: []string{"Owl"}},
+		{Key: "christmas", Name: "Christmas", Month: time.December, Day: 25, Greeting: "Happy Christmas, explorers! Today's special bird is the {{.BirdName}}!", SpeciesKeywords: []string{"Robin"}},
+	},
+}
+
+// loadHolidays returns the built-in holidays for a locale, or a JSON list of holidays from path.
+// An unknown locale with no path gives no holidays.
+func loadHolidays(locale string, path string) []Holiday {
+	holidays := holidayCalendars[locale]
+	if path == "" {
+		return holidays
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("[HOLIDAYS] Failed to read holiday calendar %s: %v, using built-in %s calendar", path, err, locale)
+		return holidays
+	}
+
+	var custom []Holiday
+	if err := json.Unmarshal(data, &custom); err != nil {
+		log.Printf("[HOLIDAYS] Failed to parse holiday calendar %s: %v, using built-in %s calendar", path, err, locale)
+		return holidays
+	}
+	return custom
+}
+
+// theme is the holiday as a one-day theme, its greeting read as the themed intro
+func (h Holiday) theme() Theme {
+	return Theme{
+		Key:             h.Key,
+		Name:            h.Name,
+		SpeciesKeywords: h.SpeciesKeywords,
+		Intros:          []string{h.Greeting},
+		holiday:         &h,
+	}
+}
+
+// fallsOn reports whether the holiday is on the given date
+func (h *Holiday) fallsOn(date time.Time) bool {
+	if date.Month() != h.Month {
+		return false
+	}
+	if h.Day > 0 {
+		return date.Day() == h.Day
+	}
+	if h.Week == 0 || date.Weekday() != h.Weekday {
+		return false
+	}
+
+	week := (date.Day()-1)/7 + 1
+	if h.Week > 0 {
+		return week == h.Week
+	}
+
+	// Last occurrence: a week later is in the next month
+	return date.AddDate(0, 0, 7).Month() != date.Month()
+}
+
+// matchesKeywords reports whether a common name contains any of the keywords, ignoring case
+func matchesKeywords(commonName string, keywords []string) bool {
+	name := strings.ToLower(commonName)
+	for _, keyword := range keywords {
+		if strings.Contains(name, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/services/factkit"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+)
+
+const (
+	hotspotSearchRadiusKm = 25
+	hotspotMaxPlaces      = 3
+	hotspotMaxCached      = 100
+)
+
+// hotspotParkWords mark eBird hotspots that are public, family-friendly places
+var hotspotParkWords = []string{
+	"park", "refuge", "preserve", "reserve", "sanctuary", "nature center", "nature centre",
+	"arboretum", "garden", "forest", "wildlife area", "greenway", "trail", "wetland", "marsh",
+	"lake", "beach", "woods", "meadow", "reservoir",
+}
+
+// hotspotExcludedPattern matches private, restricted, or otherwise off-limits hotspots
+var hotspotExcludedPattern = regexp.MustCompile(`(?i)\b(private|restricted|yards?|feeders?|residence|home|stakeout|no access|closed|pelagic|offshore|landfill|sewage|treatment|airport|industrial)\b`)
+
+// hotspotParenthetical strips notes like "(restricted access)" from hotspot names
+var hotspotParenthetical = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// hotspotStreetPattern matches roadside and address-style hotspot names
+var hotspotStreetPattern = regexp.MustCompile(`(?i)\b(rd|road|hwy|highway|street|ave|avenue|blvd|lane|ln|route|rte|i-\d+|us-\d+)\b\.?|\d`)
+
+// HotspotTour is the "where to see it" chapter: a few nearby public places and the script naming them
+type HotspotTour struct {
+	Bird   string   `json:"bird"`
+	Places []string `json:"places"`
+	Script string   `json:"script"`
+	Audio  []byte   `json:"-"`
+}
+
+// HotspotGuide finds kid-friendly eBird hotspots near a listener and narrates where families
+// could go to see today's bird
+type HotspotGuide struct {
+	ebirdClient *ebird.Client
+	tts         *ElevenLabsTTS
+
+	mu    sync.Mutex
+	cache map[string]*HotspotTour // bird, date, and rounded location -> rendered tour
+}
+
+// NewHotspotGuide creates a hotspot guide using eBird for nearby places and ElevenLabs for the narration
+func NewHotspotGuide(ebirdAPIKey string, tts *ElevenLabsTTS) *HotspotGuide {
+	return &HotspotGuide{
+		ebirdClient: ebird.NewClient(ebirdAPIKey),
+		tts:         tts,
+		cache:       make(map[string]*HotspotTour),
+	}
+}
+
+// GenerateTour returns today's "where to see it" narration for the bird near a location, reusing
+// a rendered tour for the same bird and area
+func (hg *HotspotGuide) GenerateTour(ctx context.Context, birdName string, lat, lng float64, voiceID string) (*HotspotTour, error) {
+	key := fmt.Sprintf("%s|%s|%.1f,%.1f", strings.ToLower(birdName), time.Now().Format("2006-01-02"), lat, lng)
+
+	hg.mu.Lock()
+	cached, ok := hg.cache[key]
+	hg.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	hotspots, err := hg.ebirdClient.GetNearbyHotspots(ctx, lat, lng, hotspotSearchRadiusKm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nearby hotspots: %w", err)
+	}
+
+	places := KidFriendlyPlaces(hotspots, lat, lng, hotspotMaxPlaces)
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no park-like hotspots near %.2f, %.2f", lat, lng)
+	}
+
+	tour := &HotspotTour{
+		Bird:   birdName,
+		Places: places,
+		Script: BuildHotspotScript(birdName, places),
+	}
+	if tour.Audio, _, err = hg.tts.Render(ctx, tour.Script, voiceID); err != nil {
+		return nil, fmt.Errorf("failed to render hotspot script: %w", err)
+	}
+
+	hg.mu.Lock()
+	if len(hg.cache) >= hotspotMaxCached {
+		hg.cache = make(map[string]*HotspotTour)
+	}
+	hg.cache[key] = tour
+	hg.mu.Unlock()
+
+	slog.InfoContext(ctx, "[HOTSPOTS] Generated tour", "bird", birdName, "places", strings.Join(places, "; "), "bytes", len(tour.Audio))
+	return tour, nil
+}
+
+// KidFriendlyPlaces returns up to max park-like public hotspot names, nearest first. Names are
+// trimmed to the place itself ("Forest Park--Lake" and "Forest Park, St. Louis" both become
+// "Forest Park") and each place is named once.
+func KidFriendlyPlaces(hotspots []ebird.Hotspot, lat, lng float64, max int) []string {
+	type candidate struct {
+		name     string
+		distance float64
+	}
+
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, hotspot := range hotspots {
+		// Access notes like "(private)" are checked before cleaning strips them
+		if hotspotExcludedPattern.MatchString(hotspot.LocationName) {
+			continue
+		}
+		name := cleanHotspotName(hotspot.LocationName)
+		if name == "" || !isParkLikeHotspot(name) || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		candidates = append(candidates, candidate{
+			name:     name,
+			distance: factkit.DistanceMiles(lat, lng, hotspot.Latitude, hotspot.Longitude),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	places := make([]string, 0, max)
+	for _, c := range candidates {
+		if len(places) == max {
+			break
+		}
+		places = append(places, c.name)
+	}
+	return places
+}
+
+// cleanHotspotName keeps the place name from an eBird hotspot name, dropping sub-locations,
+// parenthetical notes, and trailing city or state
+func cleanHotspotName(name string) string {
+	if i := strings.Index(name, "--"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	name = hotspotParenthetical.ReplaceAllString(name, "")
+	return strings.TrimSpace(name)
+}
+
+// isParkLikeHotspot reports whether a cleaned hotspot name looks like a public park, refuge,
+// or similar place rather than a private yard, road, or restricted site
+func isParkLikeHotspot(name string) bool {
+	if hotspotExcludedPattern.MatchString(name) || hotspotStreetPattern.MatchString(name) {
+		return false
+	}
+
+	lower := strings.ToLower(name)
+	for _, word := range hotspotParkWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildHotspotScript returns the narration naming where families could see the bird this weekend
+func BuildHotspotScript(birdName string, places []string) string {
+	var list string
+	switch len(places) {
+	case 0:
+		return ""
+	case 1:
+		list = places[0]
+	case 2:
+		list = places[0] + " or " + places[1]
+	default:
+		list = strings.Join(places[:len(places)-1], ", ") + ", or " + places[len(places)-1]
+	}
+
+	return fmt.Sprintf("Want to see the %s for yourself? This weekend, you and your grown-ups could visit %s. "+
+		"Walk slowly, stay very quiet, and listen for the song you heard today. "+
+		"If you spot one, you're a real bird explorer!", birdName, list)
+}
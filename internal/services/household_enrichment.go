@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
+
+// HouseholdDevice is a player in a split household with its own location
+type HouseholdDevice struct {
+	DeviceID string           `json:"device_id"`
+	Variant  string           `json:"variant"` // Narration file suffix, e.g. "household_1"
+	Location *models.Location `json:"location"`
+}
+
+// LocalSections holds the location-dependent parts of the day's guide for one device, and the
+// guide script narrated for the household's description variant. Species, song, and core facts
+// are shared; only these sections differ per household.
+type LocalSections struct {
+	DeviceID      string    `json:"device_id"`
+	Variant       string    `json:"variant"`
+	BirdName      string    `json:"bird_name"`
+	LocationIntro string    `json:"location_intro"`
+	Sightings     string    `json:"sightings"`
+	Script        string    `json:"script"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+// HouseholdEnricher generates per-device location sections in parallel for cards shared
+// between households (e.g. two homes in different cities playing the same card)
+type HouseholdEnricher struct {
+	ebirdAPIKey string
+	devices     map[string]*HouseholdDevice
+	mu          sync.RWMutex
+	date        string                    // Date of the cached sections
+	sections    map[string]*LocalSections // Keyed by device ID
+}
+
+// NewHouseholdEnricher parses "deviceID=lat,lon;deviceID2=lat,lon" into household devices
+func NewHouseholdEnricher(ebirdAPIKey string, spec string) *HouseholdEnricher {
+	enricher := &HouseholdEnricher{
+		ebirdAPIKey: ebirdAPIKey,
+		devices:     make(map[string]*HouseholdDevice),
+		sections:    make(map[string]*LocalSections),
+	}
+
+	var deviceIDs []string
+	locations := make(map[string]*models.Location)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			log.Printf("[HOUSEHOLD] Ignoring invalid household device %q (expected deviceID=lat,lon)", entry)
+			continue
+		}
+
+		location, err := parseCoordinates(parts[1])
+		if err != nil {
+			log.Printf("[HOUSEHOLD] Ignoring invalid household device %q: %v", entry, err)
+			continue
+		}
+
+		deviceID := strings.TrimSpace(parts[0])
+		deviceIDs = append(deviceIDs, deviceID)
+		locations[deviceID] = location
+	}
+
+	// Stable variant names regardless of spec order
+	sort.Strings(deviceIDs)
+	for i, deviceID := range deviceIDs {
+		enricher.devices[deviceID] = &HouseholdDevice{
+			DeviceID: deviceID,
+			Variant:  fmt.Sprintf("household_%d", i+1),
+			Location: locations[deviceID],
+		}
+	}
+
+	return enricher
+}
+
+// Enabled reports whether more than one household location is configured
+func (he *HouseholdEnricher) Enabled() bool {
+	return len(he.devices) > 1
+}
+
+// Device returns the household device for a device ID
+func (he *HouseholdEnricher) Device(deviceID string) (*HouseholdDevice, bool) {
+	device, ok := he.devices[deviceID]
+	return device, ok
+}
+
+// EnrichForBird generates location sections for every household device concurrently.
+// Results replace the previous day's cache so streaming requests never wait on eBird.
+func (he *HouseholdEnricher) EnrichForBird(ctx context.Context, bird *models.Bird, date string) map[string]*LocalSections {
+	if !he.Enabled() || bird == nil {
+		return nil
+	}
+
+	results := make(map[string]*LocalSections)
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, device := range he.devices {
+		wg.Add(1)
+		go func(device *HouseholdDevice) {
+			defer wg.Done()
+
+			// Each device's phrasing is seeded from the day, so a rebuild gives the same sections
+			generator := NewImprovedFactGeneratorV4(he.ebirdAPIKey, randx.Daily(date, device.DeviceID))
+			intro, sightings := generator.GenerateLocalSections(ctx, bird, device.Location.Latitude, device.Location.Longitude)
+			script := generator.GenerateExplorersGuideScriptWithLocation(ctx, bird, device.Location.Latitude, device.Location.Longitude)
+
+			resultsMu.Lock()
+			results[device.DeviceID] = &LocalSections{
+				DeviceID:      device.DeviceID,
+				Variant:       device.Variant,
+				BirdName:      bird.CommonName,
+				LocationIntro: intro,
+				Sightings:     sightings,
+				Script:        script,
+				GeneratedAt:   time.Now().UTC(),
+			}
+			resultsMu.Unlock()
+		}(device)
+	}
+	wg.Wait()
+
+	he.mu.Lock()
+	he.date = date
+	he.sections = results
+	he.mu.Unlock()
+
+	log.Printf("[HOUSEHOLD] Generated local sections for %d devices (%s, %s)", len(results), bird.CommonName, date)
+	return results
+}
+
+// Sections returns the cached sections for all devices and the date they were generated for
+func (he *HouseholdEnricher) Sections() (string, map[string]*LocalSections) {
+	he.mu.RLock()
+	defer he.mu.RUnlock()
+
+	sections := make(map[string]*LocalSections, len(he.sections))
+	for deviceID, deviceSections := range he.sections {
+		sections[deviceID] = deviceSections
+	}
+	return he.date, sections
+}
+
+// SectionsForDevice returns the cached sections for a device if they were generated for the given bird
+func (he *HouseholdEnricher) SectionsForDevice(deviceID string, birdName string) (*LocalSections, bool) {
+	he.mu.RLock()
+	defer he.mu.RUnlock()
+
+	sections, ok := he.sections[deviceID]
+	if !ok || !strings.EqualFold(sections.BirdName, birdName) {
+		return nil, false
+	}
+	return sections, true
+}
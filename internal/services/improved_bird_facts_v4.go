@@ -1,28 +1,37 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"math"
-	"math/rand"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services/factkit"
 	"github.com/callen/bird-song-explorer/pkg/ebird"
 	"github.com/callen/bird-song-explorer/pkg/inaturalist"
+	"github.com/callen/bird-song-explorer/pkg/randx"
 	"github.com/callen/bird-song-explorer/pkg/wikipedia"
 )
 
+// Generic lines used when no source has anything specific to say about a bird
+const (
+	genericPhysicalDescription = "The %s has unique markings and colors that make it special."
+	genericDietLine            = "Notice how they search for food - hopping, pecking, and exploring!"
+	genericFunFactLine         = "Keep watching - every bird has its own special story!"
+)
+
 // ImprovedFactGeneratorV4 generates bird facts with location-specific sightings
 // NOTE: This generator is NOT currently used in production but kept for future enhancement
 // It provides much more detailed, location-aware facts than the standard generator
 // To use: See generateEnhancedBirdDescription in content_update.go
 type ImprovedFactGeneratorV4 struct {
-	wikiClient  *wikipedia.Client
-	inatClient  *inaturalist.Client
+	aggregator  *FactAggregator
 	ebirdClient *ebird.Client
-	rng         *rand.Rand
+	taxonomy    *ebird.Taxonomy
+	geocoder    Geocoder // Names the listener's city and state; nil leaves them generic
+	rng         *randx.Randomizer // Picks phrasings; nil picks with a time seed
+	frequencies *RegionFrequencies // Rates the bird in the listener's country; nil skips special guests
 }
 
 // LocationContext holds location-specific information for the script
@@ -43,113 +52,184 @@ type RecentSighting struct {
 	DaysAgo      int
 }
 
-// NewImprovedFactGeneratorV4 creates a new fact generator with location awareness
-func NewImprovedFactGeneratorV4(ebirdAPIKey string) *ImprovedFactGeneratorV4 {
+// NewImprovedFactGeneratorV4 creates a new fact generator with location awareness, drawing its
+// phrasings from rng
+func NewImprovedFactGeneratorV4(ebirdAPIKey string, rng *randx.Randomizer) *ImprovedFactGeneratorV4 {
 	return &ImprovedFactGeneratorV4{
-		wikiClient:  wikipedia.NewClient(),
-		inatClient:  inaturalist.NewClient(),
+		aggregator:  NewFactAggregator(wikipedia.NewClient(), inaturalist.NewClient()),
 		ebirdClient: ebird.NewClient(ebirdAPIKey),
-		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
-	}
+		taxonomy:    ebird.SharedTaxonomy(ebirdAPIKey),
+		geocoder:    SharedGeocoder(),
+		rng:         rng,
+		frequencies: SharedRegionFrequencies(""),
+	}
+}
+
+// guideSections lays out the Explorer's Guide in script order with each section's priority and
+// typical words, so the budget can plan which sections a guide has room for
+var guideSections = []ScriptSection{
+	{Name: "introduction", Priority: SectionEssential, Words: 45},
+	{Name: "special_guest", Priority: SectionCore, Words: 25},
+	{Name: "appearance", Priority: SectionCore, Words: 45},
+	{Name: "voice", Priority: SectionCore, Words: 45}, // Includes the call snippet
+	{Name: "habitat", Priority: SectionExtra, Words: 35},
+	{Name: "diet", Priority: SectionCore, Words: 25},
+	{Name: "nesting", Priority: SectionExtra, Words: 30},
+	{Name: "abilities", Priority: SectionExtra, Words: 25},
+	{Name: "sightings", Priority: SectionFiller, Words: 25},
+	{Name: "conservation", Priority: SectionExtra, Words: 40},
+	{Name: "fun_facts", Priority: SectionExtra, Words: 25},
+	{Name: "dawn_chorus", Priority: SectionFiller, Words: 20},
+	{Name: "closing", Priority: SectionEssential, Words: 20},
 }
 
 // GenerateExplorersGuideScriptWithLocation creates a location-aware script
-func (fg *ImprovedFactGeneratorV4) GenerateExplorersGuideScriptWithLocation(bird *models.Bird, lat, lng float64) string {
-	sections := []string{}
-	usedTransitions := make(map[string]bool)
+func (fg *ImprovedFactGeneratorV4) GenerateExplorersGuideScriptWithLocation(ctx context.Context, bird *models.Bird, lat, lng float64) string {
+	return fg.GenerateExplorersGuideTranscript(ctx, bird, lat, lng).Script
+}
 
-	// Get Wikipedia data
-	wikiData, _ := fg.wikiClient.GetBirdSummary(bird.CommonName)
+// GenerateExplorersGuideTranscript creates a location-aware script, attributing each sentence to its source
+func (fg *ImprovedFactGeneratorV4) GenerateExplorersGuideTranscript(ctx context.Context, bird *models.Bird, lat, lng float64) *ScriptTranscript {
+	bird, _ = withTaxonomy(fg.taxonomy, bird)
+	builder := newBudgetedBuilder(NewGuideBudgeter(), guideSections)
+	transitions := factkit.NewTransitions(fg.rng)
 
 	// Get location context from eBird
-	locationContext := fg.getLocationContext(bird, lat, lng)
+	locationContext := fg.getLocationContext(ctx, bird, lat, lng)
+
+	// Aggregate every source into one fact sheet before writing any prose
+	sources := fg.aggregator.Fetch(ctx, bird)
+	sources.Sightings = locationContext.RecentSightings
+	sheet := AggregateFactSheet(bird, sources)
+	sheet.LogSources()
 
 	// 1. Scientific Introduction
-	scientificIntro := fg.generateScientificIntro(bird)
-	if scientificIntro != "" {
-		sections = append(sections, scientificIntro)
-	}
+	builder.section("introduction")
+	builder.add(fg.generateScientificIntro(bird), SourceTemplate, "scientific_intro")
 
 	// 2. Location-specific introduction (NEW)
-	locationIntro := fg.generateLocationIntro(bird, locationContext)
-	if locationIntro != "" {
-		sections = append(sections, locationIntro)
+	if len(locationContext.RecentSightings) > 0 {
+		builder.add(fg.generateLocationIntro(bird, locationContext), SourceEBird, "recent_observations")
+	} else {
+		builder.add(fg.generateLocationIntro(bird, locationContext), SourceTemplate, "location_greeting")
+	}
+	if builder.section("special_guest") {
+		builder.add(fg.specialGuestInfo(ctx, bird, lat, lng), SourceEBird, "historic_observations")
 	}
 
 	// 3. Physical Description
-	physicalDesc := fg.generateEnhancedPhysicalDescription(bird, wikiData)
-	if physicalDesc != "" {
-		transition := fg.getTransition(0, usedTransitions) // TransitionFact
-		if transition != "" {
-			sections = append(sections, transition+" "+physicalDesc)
+	if builder.section("appearance") {
+		physicalFacts := 2
+		if builder.shortened("appearance") {
+			physicalFacts = 1
+		}
+		builder.add(transitions.Next(factkit.TransitionFact), SourceTemplate, "transition")
+		if physical := sheet.FirstFacts(physicalFacts, FactSize, FactColors); len(physical) > 0 {
+			builder.addFacts(physical)
 		} else {
-			sections = append(sections, physicalDesc)
+			builder.add(fmt.Sprintf(genericPhysicalDescription, bird.CommonName), SourceTemplate, "physical_description")
+		}
+		if !builder.shortened("appearance") {
+			builder.addFacts(sheet.FirstFacts(1, FactFieldMarks))
 		}
 	}
 
 	// 4. Vocalizations
-	vocalDesc := fg.generateVocalizationDescription(bird, wikiData)
-	if vocalDesc != "" {
-		sections = append(sections, vocalDesc)
+	if builder.section("voice") {
+		if vocalization := sheet.FirstFacts(1, FactVocalization); len(vocalization) > 0 {
+			builder.add(fg.vocalizationIntro(), SourceTemplate, "vocalization_intro")
+			builder.addFacts(vocalization)
+		}
+		builder.addCallCue(bird.CommonName)
 	}
 
 	// 5. Local habitat and behavior (ENHANCED)
-	habitat := fg.generateLocalHabitatBehavior(bird, wikiData, locationContext)
-	if habitat != "" {
-		transition := fg.getTransition(1, usedTransitions) // TransitionAction
-		sections = append(sections, transition+" "+habitat)
+	if builder.section("habitat") {
+		habitat := fg.generateLocalHabitatBehavior(bird, locationContext)
+		if habitat != "" {
+			builder.add(transitions.Next(factkit.TransitionAction), SourceTemplate, "transition")
+			builder.add(habitat, SourceTemplate, "habitat")
+		}
 	}
 
 	// 6. Diet and Feeding
-	diet := fg.generateEnhancedDietInfo(bird, wikiData)
-	if diet != "" {
-		sections = append(sections, diet)
+	if builder.section("diet") {
+		if diet := sheet.FirstFacts(1, FactDiet); len(diet) > 0 {
+			builder.addFacts(diet)
+		} else {
+			builder.add(genericDietLine, SourceTemplate, "diet")
+		}
 	}
 
 	// 7. Nesting
-	nesting := fg.generateNestingInfo(bird, wikiData)
-	if nesting != "" {
-		transition := fg.getTransition(0, usedTransitions) // TransitionFact
-		sections = append(sections, transition+" "+nesting)
+	if builder.section("nesting") {
+		if nesting := sheet.FirstFacts(1, FactNesting); len(nesting) > 0 {
+			builder.add(transitions.Next(factkit.TransitionFact), SourceTemplate, "transition")
+			builder.addFacts(nesting)
+		}
 	}
 
 	// 8. Amazing Abilities
-	abilities := fg.generateAmazingAbilities(bird, wikiData)
-	if abilities != "" {
-		sections = append(sections, abilities)
+	if builder.section("abilities") {
+		builder.addFacts(sheet.FirstFacts(1, FactAbilities))
 	}
 
 	// 9. Recent local sightings (NEW)
-	sightings := fg.generateRecentSightingsInfo(bird, locationContext)
-	if sightings != "" {
-		sections = append(sections, sightings)
+	if builder.section("sightings") {
+		builder.add(fg.generateRecentSightingsInfo(bird, locationContext), SourceEBird, "recent_observations")
 	}
 
 	// 10. Conservation with local action
-	conservation := fg.generateLocalConservationInfo(bird, locationContext)
-	if conservation != "" {
-		sections = append(sections, conservation)
+	if builder.section("conservation") {
+		builder.addFacts(sheet.FirstFacts(1, FactConservation))
+		if !builder.shortened("conservation") {
+			builder.add(fg.generateLocalConservationInfo(bird, locationContext), SourceTemplate, "conservation")
+		}
 	}
 
 	// 11. Fun Facts
-	funFacts := fg.generateEnhancedFunFacts(bird, wikiData)
-	if funFacts != "" {
-		sections = append(sections, funFacts)
+	if builder.section("fun_facts") {
+		if funFacts := sheet.FirstFacts(1, FactFunFacts); len(funFacts) > 0 {
+			builder.addFacts(funFacts)
+		} else {
+			builder.add(genericFunFactLine, SourceTemplate, "fun_facts")
+		}
+	}
+
+	// 12. Dawn chorus countdown for the listener's sunrise
+	if builder.section("dawn_chorus") {
+		if dawnChorus := dawnChorusSentence(lat, lng); dawnChorus != "" {
+			builder.add(dawnChorus, SourceTemplate, "dawn_chorus")
+		}
+	}
+
+	// Close with natural flow; the sign-off is essential, so the budget always leaves room for it
+	if builder.length() == 0 {
+		builder.add(fg.joinSectionsNaturally(nil, bird.CommonName, locationContext), SourceTemplate, "empty_script")
+	} else {
+		builder.section("closing")
+		builder.add(fg.closingFor(bird.CommonName, locationContext), SourceTemplate, "closing")
 	}
 
-	// Join sections with natural flow
-	return fg.joinSectionsNaturally(sections, bird.CommonName, locationContext)
+	return builder.transcript(bird.CommonName, FactGeneratorEnhanced)
+}
+
+// GenerateLocalSections builds only the location-dependent sections (greeting and recent sightings)
+// so households in different places can share the rest of the day's script
+func (fg *ImprovedFactGeneratorV4) GenerateLocalSections(ctx context.Context, bird *models.Bird, lat, lng float64) (string, string) {
+	locationContext := fg.getLocationContext(ctx, bird, lat, lng)
+	return fg.generateLocationIntro(bird, locationContext), fg.generateRecentSightingsInfo(bird, locationContext)
 }
 
 // getLocationContext fetches location-specific information from eBird
-func (fg *ImprovedFactGeneratorV4) getLocationContext(bird *models.Bird, lat, lng float64) LocationContext {
+func (fg *ImprovedFactGeneratorV4) getLocationContext(ctx context.Context, bird *models.Bird, lat, lng float64) LocationContext {
 	context := LocationContext{
-		CityName:  fg.getCityFromCoordinates(lat, lng),
-		StateName: fg.getStateFromCoordinates(lat, lng),
+		CityName:  fg.getCityFromCoordinates(ctx, lat, lng),
+		StateName: fg.getStateFromCoordinates(ctx, lat, lng),
 	}
 
 	// Get recent observations from eBird (last 30 days)
-	observations, err := fg.ebirdClient.GetRecentObservations(lat, lng, 30)
+	observations, err := fg.ebirdClient.GetRecentObservations(ctx, lat, lng, 30)
 	if err == nil {
 		// Filter for this specific bird
 		for _, obs := range observations {
@@ -169,8 +249,8 @@ func (fg *ImprovedFactGeneratorV4) getLocationContext(bird *models.Bird, lat, ln
 				context.RecentSightings = append(context.RecentSightings, sighting)
 
 				// Calculate distance to nearest sighting
-				if context.Distance == 0 || context.Distance > fg.calculateDistance(lat, lng, obs.Latitude, obs.Longitude) {
-					context.Distance = fg.calculateDistance(lat, lng, obs.Latitude, obs.Longitude)
+				if context.Distance == 0 || context.Distance > factkit.DistanceMiles(lat, lng, obs.Latitude, obs.Longitude) {
+					context.Distance = factkit.DistanceMiles(lat, lng, obs.Latitude, obs.Longitude)
 				}
 			}
 		}
@@ -199,11 +279,11 @@ func (fg *ImprovedFactGeneratorV4) generateLocationIntro(bird *models.Bird, cont
 			fmt.Sprintf("Great news! %ss have been spotted near you in %s!", bird.CommonName, context.CityName),
 			fmt.Sprintf("You're in luck! A %s was seen just %d days ago near you!", bird.CommonName, mostRecent.DaysAgo),
 			fmt.Sprintf("Exciting! %ss are active in %s!", bird.CommonName, context.CityName),
-			fmt.Sprintf("Perfect timing! %ss have been seen %d time%s near %s this month!", bird.CommonName, len(context.RecentSightings), pluralS(float64(len(context.RecentSightings))), context.CityName),
+			fmt.Sprintf("Perfect timing! %ss have been seen %d time%s near %s this month!", bird.CommonName, len(context.RecentSightings), factkit.PluralS(float64(len(context.RecentSightings))), context.CityName),
 		}
 
 		if context.Distance < 5 {
-			intros = append(intros, fmt.Sprintf("Wow! A %s was spotted less than %.1f mile%s from you!", bird.CommonName, context.Distance, pluralS(context.Distance)))
+			intros = append(intros, fmt.Sprintf("Wow! A %s was spotted less than %.1f mile%s from you!", bird.CommonName, context.Distance, factkit.PluralS(context.Distance)))
 		}
 
 		return intros[fg.rng.Intn(len(intros))]
@@ -227,8 +307,8 @@ func (fg *ImprovedFactGeneratorV4) generateLocationIntro(bird *models.Bird, cont
 }
 
 // generateLocalHabitatBehavior creates habitat info with local context
-func (fg *ImprovedFactGeneratorV4) generateLocalHabitatBehavior(bird *models.Bird, wikiData *wikipedia.PageSummary, context LocationContext) string {
-	baseHabitat := fg.generateEnhancedHabitatBehavior(bird, wikiData)
+func (fg *ImprovedFactGeneratorV4) generateLocalHabitatBehavior(bird *models.Bird, context LocationContext) string {
+	baseHabitat := fg.generateEnhancedHabitatBehavior(bird)
 
 	// Skip local context if we don't have a real location
 	if context.CityName == "your city" || context.CityName == "" || context.StateName == "your state" || context.StateName == "" {
@@ -323,7 +403,7 @@ func (fg *ImprovedFactGeneratorV4) generateRecentSightingsInfo(bird *models.Bird
 
 	if len(context.RecentSightings) > 5 {
 		sightingPhrases = append(sightingPhrases,
-			fmt.Sprintf("Wow! %ss have been spotted %d time%s in %s this month!", bird.CommonName, thisMonth, pluralS(float64(thisMonth)), context.CityName))
+			fmt.Sprintf("Wow! %ss have been spotted %d time%s in %s this month!", bird.CommonName, thisMonth, factkit.PluralS(float64(thisMonth)), context.CityName))
 	}
 
 	// Mention group sightings without confusing location details
@@ -381,287 +461,60 @@ func (fg *ImprovedFactGeneratorV4) generateLocalConservationInfo(bird *models.Bi
 	return base
 }
 
-// Helper functions for location
-
-func (fg *ImprovedFactGeneratorV4) getCityFromCoordinates(lat, lng float64) string {
-	// If coordinates are zero, return generic text
-	if lat == 0 && lng == 0 {
-		return "your city"
-	}
-
-	// Use reverse geocoding to get actual city name
-	cityName := fg.reverseGeocode(lat, lng, "city")
-	if cityName != "" && !strings.Contains(strings.ToLower(cityName), "2023") && !strings.Contains(strings.ToLower(cityName), "2024") {
-		return cityName
-	}
-
-	// Specific fallback for known coordinates
-	// London area
-	if lat >= 51.0 && lat <= 52.0 && lng >= -1.0 && lng <= 1.0 {
-		return "London"
-	}
-
-	// Fallback to generic
-	return "your city"
-}
-
-func (fg *ImprovedFactGeneratorV4) getStateFromCoordinates(lat, lng float64) string {
-	// If coordinates are zero, return generic text
-	if lat == 0 && lng == 0 {
-		return "your state"
-	}
-
-	// Use reverse geocoding to get actual state name
-	stateName := fg.reverseGeocode(lat, lng, "state")
-	if stateName != "" && isValidState(stateName) {
-		return stateName
-	}
-
-	// Specific fallback for known coordinates
-	// London/England area
-	if lat >= 51.0 && lat <= 52.0 && lng >= -1.0 && lng <= 1.0 {
-		return "England"
-	}
-
-	// Fallback to generic
-	return "your state"
-}
-
-func (fg *ImprovedFactGeneratorV4) calculateDistance(lat1, lng1, lat2, lng2 float64) float64 {
-	// Haversine formula for distance between two points
-	const earthRadius = 3959.0 // miles
-
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLng := (lng2 - lng1) * math.Pi / 180
-
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
-			math.Sin(dLng/2)*math.Sin(dLng/2)
-
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-
-	return earthRadius * c
-}
-
-func (fg *ImprovedFactGeneratorV4) reverseGeocode(lat, lng float64, locationType string) string {
-	// Use eBird hotspots to get location names
-	// This is a simplified approach using nearby eBird hotspot names
-	hotspots, err := fg.ebirdClient.GetNearbyHotspots(lat, lng, 25)
-	if err != nil || len(hotspots) == 0 {
+// specialGuestInfo says why the bird is a treat when the listener's country has rarely reported it
+// lately, or returns "" when it's more common there or can't be rated
+func (fg *ImprovedFactGeneratorV4) specialGuestInfo(ctx context.Context, bird *models.Bird, lat, lng float64) string {
+	if fg.frequencies == nil || bird.ScientificName == "" {
 		return ""
 	}
-
-	// Collect city and state names, filtering out street-level details
-	cities := make(map[string]int)
-	states := make(map[string]int)
-
-	for _, hotspot := range hotspots {
-		parts := strings.Split(hotspot.LocationName, ", ")
-		if len(parts) >= 2 {
-			// Last part is usually state (or country)
-			statePart := strings.TrimSpace(parts[len(parts)-1])
-			// Clean the state name to remove dates/years
-			statePart = cleanLocationName(statePart)
-
-			// Filter out common state abbreviations and full names
-			if statePart != "" && isValidState(statePart) {
-				states[statePart]++
-			}
-
-			// Look for city name - usually second to last, but skip if it contains street indicators
-			if len(parts) >= 2 {
-				cityPart := strings.TrimSpace(parts[len(parts)-2])
-				// Clean the city name to remove dates/years
-				cityPart = cleanLocationName(cityPart)
-
-				// Skip if it looks like a street address
-				if cityPart != "" && !containsStreetIndicators(cityPart) && !containsNumbers(cityPart) {
-					cities[cityPart]++
-				} else if len(parts) >= 3 {
-					// Try third to last if second to last was a street
-					cityPart = strings.TrimSpace(parts[len(parts)-3])
-					// Clean the city name to remove dates/years
-					cityPart = cleanLocationName(cityPart)
-					if cityPart != "" && !containsStreetIndicators(cityPart) && !containsNumbers(cityPart) {
-						cities[cityPart]++
-					}
-				}
-			}
-		}
+	regionCode, regionName := SpecialGuestRegion(fg.placeAt(ctx, lat, lng))
+	if regionCode == "" {
+		return ""
 	}
-
-	// Return the most common city or state
-	if locationType == "state" {
-		return getMostCommon(states)
-	} else if locationType == "city" {
-		return getMostCommon(cities)
+	if regionName == "" {
+		regionName = "your country"
 	}
-
-	return ""
-}
-
-// Helper function to check if string contains street indicators
-func containsStreetIndicators(s string) bool {
-	streetWords := []string{"St", "Street", "Ave", "Avenue", "Rd", "Road", "Blvd",
-		"Boulevard", "Dr", "Drive", "Ln", "Lane", "Way", "Ct", "Court",
-		"Pl", "Place", "Block", "Park", "Trail", "Path"}
-	lower := strings.ToLower(s)
-	for _, word := range streetWords {
-		if strings.Contains(lower, strings.ToLower(word)) {
-			return true
-		}
+	frequency, known := fg.frequencies.Frequency(regionCode, bird.ScientificName)
+	if !known || frequency.Rarity() != RarityRare {
+		return ""
 	}
-	return false
-}
 
-// Helper function to check if string contains numbers (likely address)
-func containsNumbers(s string) bool {
-	for _, r := range s {
-		if r >= '0' && r <= '9' {
-			return true
-		}
+	options := []string{
+		fmt.Sprintf("Today's bird is a special guest! Birdwatchers in %s have only reported %ss on %d of the last %d days they checked, so spotting one would be a real treat.",
+			regionName, bird.CommonName, frequency.DaysReported, frequency.DaysSampled),
+		fmt.Sprintf("Here's what makes today special: %ss are rare visitors to %s. Lately they've turned up on just %d of the %d days birdwatchers there checked!",
+			bird.CommonName, regionName, frequency.DaysReported, frequency.DaysSampled),
 	}
-	return false
+	return options[fg.rng.Intn(len(options))]
 }
 
-// Helper function to clean location names by removing dates/years
-func cleanLocationName(s string) string {
-	// Remove common year patterns (4-digit numbers)
-	words := strings.Fields(s)
-	var cleaned []string
-
-	for _, word := range words {
-		// Skip if it's a 4-digit year (1900-2099)
-		if len(word) == 4 {
-			if year, err := strconv.Atoi(word); err == nil && year >= 1900 && year <= 2099 {
-				continue
-			}
-		}
-
-		// Skip if it contains date patterns like "2023-01-15" or "01/15/2023"
-		if strings.Contains(word, "-") || strings.Contains(word, "/") {
-			hasOnlyNumbersAndSeparators := true
-			for _, r := range word {
-				if !((r >= '0' && r <= '9') || r == '-' || r == '/') {
-					hasOnlyNumbersAndSeparators = false
-					break
-				}
-			}
-			if hasOnlyNumbersAndSeparators {
-				continue
-			}
-		}
+// Helper functions for location
 
-		cleaned = append(cleaned, word)
+func (fg *ImprovedFactGeneratorV4) getCityFromCoordinates(ctx context.Context, lat, lng float64) string {
+	if place := fg.placeAt(ctx, lat, lng); place != nil && place.City != "" {
+		return place.City
 	}
-
-	return strings.Join(cleaned, " ")
+	return "your city"
 }
 
-// Helper function to validate location names (states/provinces/countries)
-func isValidState(s string) bool {
-	// Clean the input
-	s = strings.TrimSpace(s)
-
-	// US states and abbreviations
-	if len(s) == 2 {
-		stateAbbr := strings.ToUpper(s)
-		validAbbr := []string{"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "FL", "GA",
-			"HI", "ID", "IL", "IN", "IA", "KS", "KY", "LA", "ME", "MD",
-			"MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ",
-			"NM", "NY", "NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC",
-			"SD", "TN", "TX", "UT", "VT", "VA", "WA", "WV", "WI", "WY", "DC"}
-		for _, abbr := range validAbbr {
-			if stateAbbr == abbr {
-				return true
-			}
-		}
-	}
-
-	// Full US state names
-	stateNames := []string{"Alabama", "Alaska", "Arizona", "Arkansas", "California",
-		"Colorado", "Connecticut", "Delaware", "Florida", "Georgia",
-		"Hawaii", "Idaho", "Illinois", "Indiana", "Iowa", "Kansas",
-		"Kentucky", "Louisiana", "Maine", "Maryland", "Massachusetts",
-		"Michigan", "Minnesota", "Mississippi", "Missouri", "Montana",
-		"Nebraska", "Nevada", "New Hampshire", "New Jersey", "New Mexico",
-		"New York", "North Carolina", "North Dakota", "Ohio", "Oklahoma",
-		"Oregon", "Pennsylvania", "Rhode Island", "South Carolina",
-		"South Dakota", "Tennessee", "Texas", "Utah", "Vermont",
-		"Virginia", "Washington", "West Virginia", "Wisconsin", "Wyoming",
-		"District of Columbia", "D.C."}
-
-	for _, state := range stateNames {
-		if strings.EqualFold(s, state) {
-			return true
-		}
-	}
-
-	// Canadian provinces
-	canadianRegions := []string{
-		"Ontario", "Quebec", "British Columbia", "Alberta", "Manitoba", "Saskatchewan",
-		"Nova Scotia", "New Brunswick", "Newfoundland and Labrador", "Prince Edward Island",
-		"Northwest Territories", "Yukon", "Nunavut",
-		"ON", "QC", "BC", "AB", "MB", "SK", "NS", "NB", "NL", "PE", "NT", "YT", "NU",
+func (fg *ImprovedFactGeneratorV4) getStateFromCoordinates(ctx context.Context, lat, lng float64) string {
+	if place := fg.placeAt(ctx, lat, lng); place != nil && place.State != "" {
+		return place.State
 	}
-
-	for _, region := range canadianRegions {
-		if strings.EqualFold(s, region) {
-			return true
-		}
-	}
-
-	// Countries
-	countries := []string{
-		"Canada", "United Kingdom", "UK", "England", "Scotland", "Wales",
-		"Australia", "New Zealand", "Ireland", "Mexico", "Costa Rica",
-		"Brazil", "Argentina", "Kenya", "South Africa", "India", "Japan",
-	}
-
-	for _, country := range countries {
-		if strings.EqualFold(s, country) {
-			return true
-		}
-	}
-
-	// Australian states
-	australianRegions := []string{
-		"New South Wales", "Victoria", "Queensland", "Western Australia",
-		"South Australia", "Tasmania", "NSW", "VIC", "QLD", "WA", "SA", "TAS",
-	}
-
-	for _, region := range australianRegions {
-		if strings.EqualFold(s, region) {
-			return true
-		}
-	}
-
-	// Accept capitalized strings that look like location names
-	if len(s) > 2 && !containsNumbers(s) && s[0] >= 'A' && s[0] <= 'Z' {
-		return true
-	}
-
-	return false
+	return "your state"
 }
 
-// Helper function to get most common item from map
-func getMostCommon(items map[string]int) string {
-	if len(items) == 0 {
-		return ""
+// placeAt reverse geocodes the listener's coordinates, or returns nil when they're unknown
+func (fg *ImprovedFactGeneratorV4) placeAt(ctx context.Context, lat, lng float64) *Place {
+	// Zero coordinates mean the location is unknown
+	if fg.geocoder == nil || (lat == 0 && lng == 0) {
+		return nil
 	}
-
-	var mostCommon string
-	maxCount := 0
-
-	for item, count := range items {
-		if count > maxCount {
-			maxCount = count
-			mostCommon = item
-		}
+	place, err := fg.geocoder.ReverseGeocode(ctx, lat, lng)
+	if err != nil {
+		return nil
 	}
-
-	// Clean the final result one more time to ensure no dates/years
-	return cleanLocationName(mostCommon)
+	return place
 }
 
 func (fg *ImprovedFactGeneratorV4) determineSeasonalPresence(sightings []RecentSighting) string {
@@ -689,13 +542,6 @@ func (fg *ImprovedFactGeneratorV4) determineSeasonalPresence(sightings []RecentS
 	}
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 // joinSectionsNaturally combines sections with location-aware closing
 func (fg *ImprovedFactGeneratorV4) joinSectionsNaturally(sections []string, birdName string, context LocationContext) string {
 	if len(sections) == 0 {
@@ -712,6 +558,15 @@ func (fg *ImprovedFactGeneratorV4) joinSectionsNaturally(sections []string, bird
 	result := strings.Join(sections, " ")
 	result = strings.ReplaceAll(result, "  ", " ")
 
+	if len(result) < 1500 {
+		result += fg.closingFor(birdName, context)
+	}
+
+	return result
+}
+
+// closingFor picks the script's sign-off, mentioning the listener's city only when it is known
+func (fg *ImprovedFactGeneratorV4) closingFor(birdName string, context LocationContext) string {
 	// Location-aware closings with proper grammar for actual vs generic locations
 	var closings []string
 
@@ -748,43 +603,7 @@ func (fg *ImprovedFactGeneratorV4) joinSectionsNaturally(sections []string, bird
 		}
 	}
 
-	if len(result) < 1500 {
-		result += closings[fg.rng.Intn(len(closings))]
-	}
-
-	return result
-}
-
-func (fg *ImprovedFactGeneratorV4) getTransition(transType int, usedTransitions map[string]bool) string {
-	transitions := map[int][]string{
-		0: { // TransitionFact
-			"Here's a feathered fact! ",
-			"Did you know? ",
-			"Fun fact: ",
-			"Here's something cool! ",
-			"Guess what? ",
-			"Want to know something special? ",
-			"Check this out: ",
-		},
-		1: { // TransitionAction
-			"Listen like a birdwatcher. ",
-			"Watch for this: ",
-			"Look closely, explorer! ",
-			"Keep your eyes open. ",
-			"Tune in like a bird! ",
-		},
-	}
-
-	options := transitions[transType]
-	for attempts := 0; attempts < 10; attempts++ {
-		choice := options[fg.rng.Intn(len(options))]
-		if !usedTransitions[choice] {
-			usedTransitions[choice] = true
-			return choice
-		}
-	}
-
-	return options[fg.rng.Intn(len(options))]
+	return closings[fg.rng.Intn(len(closings))]
 }
 
 // Include other essential methods from V3
@@ -809,137 +628,29 @@ func (fg *ImprovedFactGeneratorV4) generateScientificIntro(bird *models.Bird) st
 		intro = intros[fg.rng.Intn(len(intros))]
 	}
 
-	if bird.Family != "" {
-		familyName := bird.Family
-		if strings.HasSuffix(familyName, "idae") {
-			familyName = strings.TrimSuffix(familyName, "idae")
-			intro += fmt.Sprintf(" It belongs to the %s family of birds.", familyName)
-		}
+	if familyName := factkit.FamilyName(bird.Family); familyName != "" {
+		intro += fmt.Sprintf(" It belongs to the %s family of birds.", familyName)
 	}
 
 	return intro
 }
 
-// Copy remaining methods from V3...
-// (generateEnhancedPhysicalDescription, generateVocalizationDescription, etc.)
-// These would be identical to V3 implementation
-
-func (fg *ImprovedFactGeneratorV4) generateEnhancedPhysicalDescription(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
-	// Same as V3
-	if wikiData == nil {
-		return fmt.Sprintf("The %s has unique markings and colors that make it special.", bird.CommonName)
-	}
-
-	combinedText := wikiData.Extract
-	sentences := strings.Split(combinedText, ". ")
-
-	var physicalFacts []string
-	usedSentences := make(map[string]bool)
-
-	for _, sentence := range sentences {
-		sentence = strings.TrimSpace(sentence)
-		lower := strings.ToLower(sentence)
-
-		if usedSentences[lower] {
-			continue
-		}
-
-		if (strings.Contains(lower, "color") || strings.Contains(lower, "size") ||
-			strings.Contains(lower, "wing") || strings.Contains(lower, "marking")) &&
-			!strings.Contains(lower, "genus") && len(sentence) < 200 {
-
-			physicalFacts = append(physicalFacts, sentence)
-			usedSentences[lower] = true
-
-			if len(physicalFacts) >= 2 {
-				break
-			}
-		}
-	}
-
-	if len(physicalFacts) > 0 {
-		return strings.Join(physicalFacts, " ")
-	}
-
-	return fmt.Sprintf("The %s has unique markings and colors that make it special.", bird.CommonName)
-}
-
-func (fg *ImprovedFactGeneratorV4) generateVocalizationDescription(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
-	// Same implementation as V3
-	lowerName := strings.ToLower(bird.CommonName)
+// vocalizationIntro introduces the vocalization fact
+func (fg *ImprovedFactGeneratorV4) vocalizationIntro() string {
 	soundIntros := []string{
-		"Listen for their sound! ",
-		"Their voice is special! ",
-		"You can identify them by their call! ",
+		"Listen for their sound!",
+		"Their voice is special!",
+		"You can identify them by their call!",
 	}
-
-	intro := soundIntros[fg.rng.Intn(len(soundIntros))]
-
-	if strings.Contains(lowerName, "robin") {
-		return intro + "Robins sing a cheerful melody that sounds like 'cheerily, cheer-up, cheerio!'"
-	} else if strings.Contains(lowerName, "cardinal") {
-		return intro + "Cardinals whistle clear notes like 'birdy-birdy-birdy' or 'cheer-cheer-cheer.'"
-	}
-
-	return ""
+	return soundIntros[fg.rng.Intn(len(soundIntros))]
 }
 
-func (fg *ImprovedFactGeneratorV4) generateEnhancedHabitatBehavior(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
+func (fg *ImprovedFactGeneratorV4) generateEnhancedHabitatBehavior(bird *models.Bird) string {
 	// Basic implementation - enhanced version uses generateLocalHabitatBehavior
 	return fmt.Sprintf("You might spot %ss in parks, gardens, or natural areas.", bird.CommonName)
 }
 
-func (fg *ImprovedFactGeneratorV4) generateEnhancedDietInfo(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
-	// Same as V3
-	lowerName := strings.ToLower(bird.CommonName)
-	if strings.Contains(lowerName, "hummingbird") {
-		return "Watch them feed! They hover at flowers, sipping nectar and catching tiny insects!"
-	}
-	return "Notice how they search for food - hopping, pecking, and exploring!"
-}
-
-func (fg *ImprovedFactGeneratorV4) generateNestingInfo(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
-	// Same as V3
-	lowerName := strings.ToLower(bird.CommonName)
-	if strings.Contains(lowerName, "robin") {
-		return "Robin parents lay 3-5 blue eggs. Tiny pink babies hatch after two weeks!"
-	}
-	return ""
-}
-
-func (fg *ImprovedFactGeneratorV4) generateAmazingAbilities(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
-	// Same as V3
-	lowerName := strings.ToLower(bird.CommonName)
-	if strings.Contains(lowerName, "hummingbird") {
-		return "Incredible ability: They can fly backwards and hover! Hearts beat 1,200 times per minute!"
-	}
-	return ""
-}
-
 func (fg *ImprovedFactGeneratorV4) generateConservationInfo(bird *models.Bird) string {
 	// Basic version - enhanced version uses generateLocalConservationInfo
 	return fmt.Sprintf("You can help %ss by providing bird feeders and keeping cats indoors!", bird.CommonName)
 }
-
-func (fg *ImprovedFactGeneratorV4) generateEnhancedFunFacts(bird *models.Bird, wikiData *wikipedia.PageSummary) string {
-	// Same as V3
-	lowerName := strings.ToLower(bird.CommonName)
-	if strings.Contains(lowerName, "cardinal") {
-		return "Fun fact: Cardinals are the state bird of seven states!"
-	}
-	return "Keep watching - every bird has its own special story!"
-}
-
-// EstimateReadingTime calculates approximate speech duration
-// pluralS returns "s" if the count is not 1, empty string otherwise
-func pluralS(count float64) string {
-	if count == 1 {
-		return ""
-	}
-	return "s"
-}
-
-func (fg *ImprovedFactGeneratorV4) EstimateReadingTime(text string) int {
-	words := len(strings.Fields(text))
-	return int(math.Ceil(float64(words) / 150.0 * 60))
-}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const introComposerMaxCached = 50
+
+// leadInCityPattern accepts plain place names; anything else an IP lookup returns is left unsaid
+var leadInCityPattern = regexp.MustCompile(`^[\p{L} .'-]{2,30}$`)
+
+// IntroComposer opens the pre-recorded intro with a short narrated greeting for the listener's
+// time of day and town ("Good morning, explorers in Portland!"). The greeting is narrated through
+// the TTS cache, so each greeting is rendered once per voice, and gets its own nature ambience
+// before being spliced onto the intro.
+type IntroComposer struct {
+	tts       *ElevenLabsTTS
+	mixer     *IntroMixer
+	processor AudioProcessor
+
+	mu    sync.Mutex
+	cache map[string][]byte // intro, greeting, and voice -> composed intro
+}
+
+// NewIntroComposer creates an intro composer narrating greetings with ElevenLabs
+func NewIntroComposer(tts *ElevenLabsTTS) *IntroComposer {
+	return &IntroComposer{
+		tts:       tts,
+		mixer:     NewIntroMixer(),
+		processor: NewAudioProcessor(),
+		cache:     make(map[string][]byte),
+	}
+}
+
+// LeadInScript is the greeting for the listener's local time, naming their town when it's known
+func LeadInScript(localNow time.Time, city string) string {
+	greeting := "Hello"
+	switch hour := localNow.Hour(); {
+	case hour >= 5 && hour < 12:
+		greeting = "Good morning"
+	case hour >= 12 && hour < 17:
+		greeting = "Good afternoon"
+	case hour >= 17 && hour < 21:
+		greeting = "Good evening"
+	}
+
+	city = strings.TrimSpace(city)
+	if leadInCityPattern.MatchString(city) {
+		return fmt.Sprintf("%s, explorers in %s!", greeting, city)
+	}
+	return greeting + ", explorers!"
+}
+
+// Compose returns the intro with the greeting spliced before it. introKey identifies the intro
+// clip for caching. ambience names the nature sound played under the greeting, as one is under
+// the intro; "" leaves the greeting dry.
+func (ic *IntroComposer) Compose(ctx context.Context, intro []byte, introKey string, localNow time.Time, city string, voiceID string, ambience string) ([]byte, error) {
+	script := LeadInScript(localNow, city)
+	key := fmt.Sprintf("%s|%s|%s|%s", introKey, script, voiceID, ambience)
+
+	ic.mu.Lock()
+	composed, ok := ic.cache[key]
+	ic.mu.Unlock()
+	if ok {
+		return composed, nil
+	}
+
+	leadIn, cached, err := ic.tts.Render(ctx, script, voiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render intro greeting: %w", err)
+	}
+	if ambience != "" {
+		if mixed, err := ic.mixer.MixIntroWithNatureSounds(leadIn, ambience); err == nil {
+			leadIn = mixed
+		} else {
+			slog.WarnContext(ctx, "[INTRO_COMPOSER] Failed to mix ambience under the greeting, using the voice alone", "error", err)
+		}
+	}
+
+	composed, err = ic.processor.Concat(leadIn, intro)
+	if err != nil {
+		return nil, fmt.Errorf("failed to splice intro greeting: %w", err)
+	}
+
+	ic.mu.Lock()
+	if len(ic.cache) >= introComposerMaxCached {
+		ic.cache = make(map[string][]byte)
+	}
+	ic.cache[key] = composed
+	ic.mu.Unlock()
+
+	slog.InfoContext(ctx, "[INTRO_COMPOSER] Composed intro", "greeting", script, "tts_cached", cached, "bytes", len(composed))
+	return composed, nil
+}
@@ -2,16 +2,19 @@ package services
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/randx"
 )
 
 type IntroManager struct {
-	intros []string
+	intros     []string
+	birdIntros []string
+	rng        *randx.Randomizer // Picks the intro; nil picks with a time seed
 }
 
-func NewIntroManager() *IntroManager {
+func NewIntroManager(rng *randx.Randomizer) *IntroManager {
 	return &IntroManager{
+		rng: rng,
 		intros: []string{
 			"Welcome, nature detectives! Time to discover an amazing bird from your neighborhood.",
 			"Hello, bird explorers! Today's special bird is waiting to sing for you.",
@@ -22,23 +25,38 @@ func NewIntroManager() *IntroManager {
 			"Time for today's bird adventure! Listen closely to nature's music.",
 			"Welcome to your daily bird journey! Let's discover who's singing today.",
 		},
+		birdIntros: []string{
+			"Today's featured friend is the %s! Let's hear their beautiful song.",
+			"Listen closely! The amazing %s has something special to share with you.",
+			"Get ready to meet the wonderful %s from your neighborhood!",
+			"Your bird discovery today is the %s! What an incredible creature!",
+		},
+	}
+}
+
+// NewIntroManagerForLocale creates an intro manager with intros in the given language
+func NewIntroManagerForLocale(locale string, rng *randx.Randomizer) *IntroManager {
+	text, ok := TemplatesForLocale(locale)
+	if !ok {
+		return NewIntroManager(rng)
+	}
+	return &IntroManager{
+		intros:     text.Intros,
+		birdIntros: text.BirdIntros,
+		rng:        rng,
 	}
 }
 
 func (im *IntroManager) GetRandomIntro() string {
-	rand.Seed(time.Now().UnixNano())
-	return im.intros[rand.Intn(len(im.intros))]
+	return im.intros[im.rng.Intn(len(im.intros))]
 }
 
 func (im *IntroManager) GetIntroForBird(birdName string) string {
-	templates := []string{
-		"Today's featured friend is the %s! Let's hear their beautiful song.",
-		"Listen closely! The amazing %s has something special to share with you.",
-		"Get ready to meet the wonderful %s from your neighborhood!",
-		"Your bird discovery today is the %s! What an incredible creature!",
-	}
-
-	rand.Seed(time.Now().UnixNano())
-	template := templates[rand.Intn(len(templates))]
+	template := im.birdIntros[im.rng.Intn(len(im.birdIntros))]
 	return fmt.Sprintf(template, birdName)
 }
+
+// Intros returns the generic intro scripts, in order, for pre-recording with a new voice
+func (im *IntroManager) Intros() []string {
+	return append([]string(nil), im.intros...)
+}
@@ -3,88 +3,84 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"time"
 )
 
+// introMix is how loud the nature sounds sit around the intro voice
+type introMix struct {
+	leadIn     float64 // Volume of the lead-in, and of the pauses when ducking
+	background float64 // Volume under the voice when ffmpeg can't duck
+	duckRatio  float64 // Compression ratio applied to the nature sounds while the voice speaks
+}
+
+var (
+	dayIntroMix = introMix{leadIn: 0.25, background: 0.10, duckRatio: 8}
+	// Night intros keep the ambience barely audible so bedtime listening stays calm
+	nightIntroMix = introMix{leadIn: 0.12, background: 0.05, duckRatio: 6}
+)
+
+// Ducking keys a compressor on the voice, so the nature sounds dip whenever the narrator speaks
+// and swell back in the pauses, however long the intro or quick the speech
+const (
+	duckThreshold = 0.02 // Voice level that starts ducking, above room tone and below speech
+	duckAttackMs  = 20
+	duckReleaseMs = 600 // Slow enough that the ambience doesn't pump between words
+)
+
+const (
+	introLeadInSeconds  = 3.0 // Nature sounds before the voice
+	introFadeOutSeconds = 2.0 // Nature sounds fading out after the voice
+)
+
 // IntroMixer handles mixing intro tracks with nature sounds
 type IntroMixer struct {
-	natureSoundsPath string
-	introPath        string
-	soundFetcher     *NatureSoundFetcher
+	assets       AssetStore
+	soundFetcher *NatureSoundFetcher
+	processor    AudioProcessor
 }
 
-// NewIntroMixer creates a new intro mixer
+// NewIntroMixer creates a new intro mixer reading intros from the shared asset store
 func NewIntroMixer() *IntroMixer {
-	// Try different possible paths for nature sounds
-	possiblePaths := []string{
-		"/root/assets/nature_sounds", // Docker container path
-		"./assets/nature_sounds",     // Local development path
-		"assets/nature_sounds",       // Relative path
-	}
-
-	natureSoundsPath := "./assets/nature_sounds" // Default
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			natureSoundsPath = path
-			break
-		}
-	}
-
 	return &IntroMixer{
-		natureSoundsPath: natureSoundsPath,
-		introPath:        "assets/final_intros",
-		soundFetcher:     NewNatureSoundFetcher(),
+		assets:       SharedAssetStore(),
+		soundFetcher: NewNatureSoundFetcher(nil),
+		processor:    NewAudioProcessor(),
 	}
 }
 
 // MixIntroWithNatureSounds mixes a pre-recorded intro with nature sounds
-// Nature sounds start 2-3 seconds before the voice and continue softly in the background
+// Nature sounds start 3 seconds before the voice and dip under it while it speaks
 func (im *IntroMixer) MixIntroWithNatureSounds(introData []byte, natureSoundType string) ([]byte, error) {
 	return im.MixIntroWithNatureSoundsForUser(introData, natureSoundType, "")
 }
 
 // MixIntroWithNatureSoundsForUser mixes intro with nature sounds based on user's timezone
 func (im *IntroMixer) MixIntroWithNatureSoundsForUser(introData []byte, natureSoundType string, userTimezone string) ([]byte, error) {
-	fmt.Printf("[INTRO_MIXER] Starting intro mixing with nature sounds\n")
-
-	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		fmt.Printf("[INTRO_MIXER] ffmpeg not found in PATH, returning intro only\n")
-		return introData, nil
-	}
-
-	// Check if ffprobe is available for duration detection
-	if _, err := exec.LookPath("ffprobe"); err != nil {
-		fmt.Printf("[INTRO_MIXER] ffprobe not found, using default timing\n")
-		// Fall back to the full mixing with default timing
-		return im.mixWithDefaultTiming(introData, natureSoundType, userTimezone)
-	}
+	return im.mixIntro(introData, natureSoundType, userTimezone, dayIntroMix)
+}
 
-	// Determine nature sound type based on user's timezone if not specified
-	if natureSoundType == "" && userTimezone != "" {
-		timeHelper := NewUserTimeHelper()
-		natureSoundType = timeHelper.GetNatureSoundForUserTime(userTimezone)
-		fmt.Printf("[INTRO_MIXER] Selected %s sound based on user's local time in %s\n",
-			natureSoundType, userTimezone)
-	}
+// MixNightIntro renders the night mode intro: night sounds at a lower volume than the daytime mix
+func (im *IntroMixer) MixNightIntro(introData []byte) ([]byte, error) {
+	return im.mixIntro(introData, "night", "", nightIntroMix)
+}
 
-	// Fetch nature sound from API
-	var natureSoundData []byte
-	var err error
+func (im *IntroMixer) mixIntro(introData []byte, natureSoundType string, userTimezone string, mix introMix) ([]byte, error) {
+	slog.Info("[INTRO_MIXER] Starting intro mixing with nature sounds")
 
-	if natureSoundType == "" {
-		// Get ambient soundscape based on server time (fallback)
-		natureSoundData, err = im.soundFetcher.GetAmbientSoundscape()
-	} else {
-		// Get specific type of nature sound
-		natureSoundData, err = im.soundFetcher.GetNatureSoundByType(natureSoundType)
+	// Without ffmpeg the nature sounds can't play under the voice, so they lead into it instead
+	if !GetFFmpegCapabilities().Mixing {
+		slog.Warn("[INTRO_MIXER] ffmpeg mixing unavailable, sequencing nature lead-in before intro")
+		return im.sequenceIntroWithNatureSounds(introData, natureSoundType, userTimezone, mix)
 	}
 
+	natureSoundData, err := im.fetchNatureSound(natureSoundType, userTimezone)
 	if err != nil {
-		fmt.Printf("[INTRO_MIXER] Failed to fetch nature sounds: %v, returning intro only\n", err)
+		slog.Warn("[INTRO_MIXER] Failed to fetch nature sounds, returning intro only", "error", err)
 		return introData, nil
 	}
 
@@ -96,61 +92,42 @@ func (im *IntroMixer) MixIntroWithNatureSoundsForUser(introData []byte, natureSo
 
 	// Write intro data to temp file
 	if err := os.WriteFile(introFile, introData, 0644); err != nil {
-		fmt.Printf("[INTRO_MIXER] Failed to write intro file: %v\n", err)
+		slog.Error("[INTRO_MIXER] Failed to write intro file", "error", err)
 		return nil, fmt.Errorf("failed to write intro file: %w", err)
 	}
 	defer os.Remove(introFile)
 
 	// Write nature sound data to temp file
 	if err := os.WriteFile(natureFile, natureSoundData, 0644); err != nil {
-		fmt.Printf("[INTRO_MIXER] Failed to write nature sound file: %v\n", err)
+		slog.Error("[INTRO_MIXER] Failed to write nature sound file", "error", err)
 		return nil, fmt.Errorf("failed to write nature sound file: %w", err)
 	}
 	defer os.Remove(natureFile)
 	defer os.Remove(outputFile)
 
-	// Get intro duration using ffprobe
-	introDuration := im.getAudioDuration(introFile)
-	if introDuration <= 0 {
+	// Get intro duration (ffprobe, or read from the MP3 frames without it)
+	introDuration, err := im.processor.Duration(introData)
+	if err != nil || introDuration <= 0 {
 		// Default to 5 seconds if we can't detect
 		introDuration = 5.0
 	}
-	fmt.Printf("[INTRO_MIXER] Intro duration: %.2f seconds\n", introDuration)
+	slog.Debug("[INTRO_MIXER] Measured intro", "seconds", introDuration)
 
-	// Calculate timings for short intro
-	leadInTime := 3.0  // Nature sounds lead-in before voice
-	fadeOutTime := 2.0 // Fade out duration after voice ends
-	totalDuration := leadInTime + introDuration + fadeOutTime
-	fadeOutStart := leadInTime + introDuration // When to start fading out
+	totalDuration := introLeadInSeconds + introDuration + introFadeOutSeconds
 
-	// Mix audio using ffmpeg with dynamic timing
-	cmd := exec.Command("ffmpeg",
+	// Duck the nature sounds under the voice as it speaks, or hold them at a fixed level under it
+	// when this ffmpeg has no sidechain compressor
+	filter := fixedIntroFilter(mix, introDuration)
+	if GetFFmpegCapabilities().Ducking {
+		filter = duckedIntroFilter(mix, introDuration)
+	}
+
+	// Mix audio using ffmpeg, looping the nature sounds so they outlast any intro
+	cmd := exec.Command(ffmpegBinary(),
+		"-stream_loop", "-1",
 		"-i", natureFile, // Input: nature sounds
 		"-i", introFile, // Input: voice intro
-		"-filter_complex",
-		fmt.Sprintf(
-			// Nature sounds: fade in at 25% volume for lead-in, then duck to 10% under voice
-			"[0:a]afade=t=in:st=0:d=1.5,volume=0.25[nature_intro];"+
-				"[0:a]volume=0.10[nature_bg];"+
-				// Split nature sounds: lead-in part and background part
-				"[nature_intro]atrim=0:%.1f[nature_start];"+
-				"[nature_bg]atrim=%.1f:%.1f[nature_rest];"+
-				// Delay voice by lead-in time
-				"[1:a]adelay=%d|%d[voice_delayed];"+
-				// Combine nature parts
-				"[nature_start][nature_rest]concat=n=2:v=0:a=1[nature_full];"+
-				// Mix voice with nature, using "first" duration to end when voice ends
-				"[voice_delayed][nature_full]amix=inputs=2:duration=first:dropout_transition=0.5[mixed];"+
-				// Add fade out starting when voice ends
-				"[mixed]afade=t=out:st=%.1f:d=%.1f[out]",
-			leadInTime,           // Trim nature_start to lead-in duration
-			leadInTime,           // Start nature_rest after lead-in
-			totalDuration,        // End nature_rest at total duration
-			int(leadInTime*1000), // Delay voice (in milliseconds)
-			int(leadInTime*1000), // Delay voice for second channel
-			fadeOutStart,         // Start fade out when voice ends
-			fadeOutTime,          // Fade out duration
-		),
+		"-filter_complex", filter,
 		"-map", "[out]",
 		"-t", fmt.Sprintf("%.2f", totalDuration), // Total duration based on intro length
 		"-c:a", "libmp3lame", // MP3 codec
@@ -165,7 +142,7 @@ func (im *IntroMixer) MixIntroWithNatureSoundsForUser(introData []byte, natureSo
 
 	if err := cmd.Run(); err != nil {
 		// If ffmpeg fails, return intro only
-		fmt.Printf("[INTRO_MIXER] ffmpeg mixing failed: %v\nStderr: %s\n", err, stderr.String())
+		slog.Error("[INTRO_MIXER] ffmpeg mixing failed", "error", err, "stderr", stderr.String())
 		return introData, nil
 	}
 
@@ -175,11 +152,57 @@ func (im *IntroMixer) MixIntroWithNatureSoundsForUser(introData []byte, natureSo
 		return nil, fmt.Errorf("failed to read mixed audio: %w", err)
 	}
 
-	fmt.Printf("[INTRO_MIXER] Successfully mixed intro with nature sounds (size: %d bytes)\n", len(mixedData))
+	slog.Info("[INTRO_MIXER] Mixed intro with nature sounds", "bytes", len(mixedData), "ducked", GetFFmpegCapabilities().Ducking)
 	return mixedData, nil
 }
 
-// selectNatureSound selects appropriate nature sound based on type or time of day
+// duckedIntroFilter builds the ffmpeg graph that plays the nature sounds at the lead-in volume
+// throughout, compressed by the voice itself: a copy of the delayed voice keys a sidechain
+// compressor on the nature sounds, which then fade out after the voice ends.
+func duckedIntroFilter(mix introMix, introDuration float64) string {
+	leadInMs := int(introLeadInSeconds * 1000)
+	totalDuration := introLeadInSeconds + introDuration + introFadeOutSeconds
+
+	return fmt.Sprintf(
+		// Delay the voice past the lead-in and pad it with silence, so the nature sounds recover
+		// after it, then split off a copy to key the compressor
+		"[1:a]adelay=%d|%d,apad=whole_dur=%.2f,asplit=2[voice][key];"+
+			"[0:a]atrim=0:%.2f,afade=t=in:st=0:d=1.5,volume=%.2f[nature];"+
+			"[nature][key]sidechaincompress=threshold=%.3f:ratio=%.1f:attack=%d:release=%d[ducked];"+
+			"[voice][ducked]amix=inputs=2:duration=first:dropout_transition=0.5[mixed];"+
+			"[mixed]afade=t=out:st=%.2f:d=%.1f[out]",
+		leadInMs, leadInMs, totalDuration,
+		totalDuration, mix.leadIn,
+		duckThreshold, mix.duckRatio, duckAttackMs, duckReleaseMs,
+		introLeadInSeconds+introDuration, introFadeOutSeconds,
+	)
+}
+
+// fixedIntroFilter builds the ffmpeg graph that plays the nature sounds at the lead-in volume
+// until the voice starts and at the background volume from then on, whether or not it's speaking
+func fixedIntroFilter(mix introMix, introDuration float64) string {
+	leadInMs := int(introLeadInSeconds * 1000)
+	totalDuration := introLeadInSeconds + introDuration + introFadeOutSeconds
+
+	return fmt.Sprintf(
+		// Nature sounds: fade in at the lead-in volume, then drop to the background volume
+		"[0:a]atrim=0:%.2f,asplit=2[nature_a][nature_b];"+
+			"[nature_a]afade=t=in:st=0:d=1.5,volume=%.2f,atrim=0:%.1f[nature_start];"+
+			"[nature_b]volume=%.2f,atrim=%.1f:%.2f[nature_rest];"+
+			"[nature_start][nature_rest]concat=n=2:v=0:a=1[nature_full];"+
+			// Delay the voice past the lead-in and pad it so the mix runs to the fade out
+			"[1:a]adelay=%d|%d,apad=whole_dur=%.2f[voice];"+
+			"[voice][nature_full]amix=inputs=2:duration=first:dropout_transition=0.5[mixed];"+
+			"[mixed]afade=t=out:st=%.2f:d=%.1f[out]",
+		totalDuration,
+		mix.leadIn, introLeadInSeconds,
+		mix.background, introLeadInSeconds, totalDuration,
+		leadInMs, leadInMs, totalDuration,
+		introLeadInSeconds+introDuration, introFadeOutSeconds,
+	)
+}
+
+// selectNatureSound selects the nature sound asset for a type, or for the time of day
 func (im *IntroMixer) selectNatureSound(soundType string) string {
 	// Define available nature sounds
 	natureSounds := map[string]string{
@@ -194,7 +217,7 @@ func (im *IntroMixer) selectNatureSound(soundType string) string {
 
 	// If specific type requested, use it
 	if sound, exists := natureSounds[soundType]; exists {
-		return filepath.Join(im.natureSoundsPath, sound)
+		return "nature_sounds/" + sound
 	}
 
 	// Otherwise, select based on time of day
@@ -212,91 +235,95 @@ func (im *IntroMixer) selectNatureSound(soundType string) string {
 		selected = natureSounds["night"]
 	}
 
-	return filepath.Join(im.natureSoundsPath, selected)
+	return "nature_sounds/" + selected
 }
 
-// getAudioDuration gets the duration of an audio file using ffprobe
-func (im *IntroMixer) getAudioDuration(audioFile string) float64 {
-	cmd := exec.Command("ffprobe",
-		"-v", "error",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
-		audioFile,
-	)
-
-	output, err := cmd.Output()
-	if err != nil {
-		fmt.Printf("[INTRO_MIXER] Failed to get audio duration: %v\n", err)
-		return 0
+// fetchNatureSound fetches the requested nature sound, choosing one for the user's local time when
+// no type is given
+func (im *IntroMixer) fetchNatureSound(natureSoundType string, userTimezone string) ([]byte, error) {
+	// Determine nature sound type based on user's timezone if not specified
+	if natureSoundType == "" && userTimezone != "" {
+		timeHelper := NewUserTimeHelper()
+		natureSoundType = timeHelper.GetNatureSoundForUserTime(userTimezone)
+		slog.Info("[INTRO_MIXER] Selected nature sound for the user's local time", "type", natureSoundType, "timezone", userTimezone)
 	}
 
-	duration := 0.0
-	fmt.Sscanf(string(output), "%f", &duration)
-	return duration
+	if natureSoundType == "" {
+		// Get ambient soundscape based on server time (fallback)
+		return im.soundFetcher.GetAmbientSoundscape()
+	}
+	// Get specific type of nature sound
+	return im.soundFetcher.GetNatureSoundByType(natureSoundType)
 }
 
-// mixWithDefaultTiming is the fallback mixing method when ffprobe is not available
-func (im *IntroMixer) mixWithDefaultTiming(introData []byte, natureSoundType string, userTimezone string) ([]byte, error) {
-	// This is the original mixing logic with fixed 30-second duration
-	// Kept as fallback for systems without ffprobe
+// sequenceIntroWithNatureSounds is the no-mixing fallback: a quiet, faded-in nature lead-in
+// followed by the intro voice
+func (im *IntroMixer) sequenceIntroWithNatureSounds(introData []byte, natureSoundType string, userTimezone string, mix introMix) ([]byte, error) {
+	natureSoundData, err := im.fetchNatureSound(natureSoundType, userTimezone)
+	if err != nil {
+		slog.Warn("[INTRO_MIXER] Failed to fetch nature sounds, returning intro only", "error", err)
+		return introData, nil
+	}
+
+	leadIn, err := im.processor.Trim(natureSoundData, 0, introLeadInSeconds)
+	if err == nil {
+		leadIn, err = im.processor.Gain(leadIn, mix.leadIn)
+	}
+	if err == nil {
+		leadIn, err = im.processor.Fade(leadIn, 1.5, 0.5)
+	}
+	if err == nil {
+		var sequenced []byte
+		if sequenced, err = im.processor.Concat(leadIn, introData); err == nil {
+			slog.Info("[INTRO_MIXER] Sequenced nature lead-in before intro", "processor", im.processor.Name(), "bytes", len(sequenced))
+			return sequenced, nil
+		}
+	}
 
-	// [Previous mixing logic would go here - simplified for now]
-	// In production, this would contain the original mixing code
+	slog.Warn("[INTRO_MIXER] Failed to sequence nature lead-in, returning intro only", "processor", im.processor.Name(), "error", err)
 	return introData, nil
 }
 
-// PreprocessAllIntros processes all intro files to add nature sounds
-// This can be run as a batch job to prepare all intros
+// PreprocessAllIntros processes all intro files to add nature sounds, saving the results under
+// final_intros/with_nature in the asset store. This can be run as a batch job to prepare all intros
 func (im *IntroMixer) PreprocessAllIntros() error {
-	introDir := im.introPath
-	outputDir := filepath.Join(introDir, "with_nature")
-
-	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
 	// Get all intro files
-	files, err := os.ReadDir(introDir)
+	files, err := im.assets.Glob("final_intros/*.mp3")
 	if err != nil {
-		return fmt.Errorf("failed to read intro directory: %w", err)
+		return fmt.Errorf("failed to list intros: %w", err)
 	}
 
-	for _, file := range files {
-		if filepath.Ext(file.Name()) != ".mp3" {
-			continue
-		}
-
-		inputPath := filepath.Join(introDir, file.Name())
-		outputPath := filepath.Join(outputDir, file.Name())
+	for _, inputPath := range files {
+		name := path.Base(inputPath)
+		outputPath := "final_intros/with_nature/" + name
 
 		// Skip if already processed
-		if _, err := os.Stat(outputPath); err == nil {
-			fmt.Printf("[INTRO_MIXER] Already processed: %s\n", file.Name())
+		if im.assets.Exists(outputPath) {
+			slog.Info("[INTRO_MIXER] Already processed", "file", name)
 			continue
 		}
 
 		// Read intro file
-		introData, err := os.ReadFile(inputPath)
+		introData, err := im.assets.ReadFile(inputPath)
 		if err != nil {
-			fmt.Printf("[INTRO_MIXER] Failed to read %s: %v\n", file.Name(), err)
+			slog.Error("[INTRO_MIXER] Failed to read intro", "file", name, "error", err)
 			continue
 		}
 
 		// Mix with nature sounds (using time-based selection)
 		mixedData, err := im.MixIntroWithNatureSounds(introData, "")
 		if err != nil {
-			fmt.Printf("[INTRO_MIXER] Failed to mix %s: %v\n", file.Name(), err)
+			slog.Error("[INTRO_MIXER] Failed to mix intro", "file", name, "error", err)
 			continue
 		}
 
 		// Save mixed version
-		if err := os.WriteFile(outputPath, mixedData, 0644); err != nil {
-			fmt.Printf("[INTRO_MIXER] Failed to save mixed %s: %v\n", file.Name(), err)
+		if err := im.assets.WriteFile(outputPath, mixedData); err != nil {
+			slog.Error("[INTRO_MIXER] Failed to save mixed intro", "file", name, "error", err)
 			continue
 		}
 
-		fmt.Printf("[INTRO_MIXER] Successfully processed: %s\n", file.Name())
+		slog.Info("[INTRO_MIXER] Processed intro", "file", name)
 	}
 
 	return nil
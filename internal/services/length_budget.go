@@ -0,0 +1,155 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultGuideTargetSeconds is how long an Explorer's Guide is planned to read for unless
+// ConfigureGuideLength says otherwise
+const defaultGuideTargetSeconds = 120.0
+
+// guideTargetSeconds holds the configured guide length as float64 bits; zero means the default
+var guideTargetSeconds atomic.Uint64
+
+// Section priorities: when a script runs long, sections are dropped from the highest number down
+const (
+	SectionEssential = 0 // Never dropped: the introduction and the sign-off
+	SectionCore      = 1 // What the bird looks like, sounds like, and eats
+	SectionExtra     = 2 // Habitat, nesting, abilities, conservation, fun facts
+	SectionFiller    = 3 // Nice to have: sightings and the dawn chorus countdown
+)
+
+// ScriptSection is a part of a script that is kept or dropped whole
+type ScriptSection struct {
+	Name     string
+	Priority int
+	Words    int // Typical length, used to plan before the text is written
+}
+
+// LengthPlan is the words each section may use; sections planned out have none. A nil plan
+// includes every section.
+type LengthPlan map[string]int
+
+// Includes reports whether the section should be written
+func (p LengthPlan) Includes(name string) bool {
+	return p == nil || p[name] > 0
+}
+
+// Words returns the section's word budget, or 0 when there's no plan
+func (p LengthPlan) Words(name string) int {
+	return p[name]
+}
+
+// LengthBudgeter fits scripts to a target reading time by whole sections, so a long script
+// loses its least important sections rather than being cut off mid-thought
+type LengthBudgeter struct {
+	targetSeconds  float64
+	wordsPerMinute float64
+}
+
+// ConfigureGuideLength sets how long Explorer's Guide scripts are planned to read for; a target
+// of 0 or less restores the default
+func ConfigureGuideLength(targetSeconds float64) {
+	if targetSeconds <= 0 {
+		targetSeconds = 0
+	}
+	guideTargetSeconds.Store(math.Float64bits(targetSeconds))
+}
+
+// GuideTargetSeconds returns how long Explorer's Guide scripts are planned to read for
+func GuideTargetSeconds() float64 {
+	if target := math.Float64frombits(guideTargetSeconds.Load()); target > 0 {
+		return target
+	}
+	return defaultGuideTargetSeconds
+}
+
+// NewGuideBudgeter creates a budgeter for Explorer's Guide scripts at the kids' reading pace
+func NewGuideBudgeter() *LengthBudgeter {
+	return NewLengthBudgeter(GuideTargetSeconds(), "kids")
+}
+
+// NewLengthBudgeter creates a budgeter for a target reading time at a VoiceSpeedProfiles pace
+// ("normal" for unknown profiles)
+func NewLengthBudgeter(targetSeconds float64, profile string) *LengthBudgeter {
+	wordsPerMinute, ok := VoiceSpeedProfiles[profile]
+	if !ok {
+		wordsPerMinute = VoiceSpeedProfiles["normal"]
+	}
+	return &LengthBudgeter{targetSeconds: targetSeconds, wordsPerMinute: wordsPerMinute}
+}
+
+// Words is the script's whole word budget
+func (lb *LengthBudgeter) Words() int {
+	return int(lb.targetSeconds / 60 * lb.wordsPerMinute)
+}
+
+// Plan divides the word budget among sections before anything is written. Sections are funded
+// in priority order, script order breaking ties, with their typical words; a section with at
+// least half of those left gets what's left, to be written short, and one with less is planned
+// out so its facts needn't be fetched. Essential sections are always funded in full.
+func (lb *LengthBudgeter) Plan(sections []ScriptSection) LengthPlan {
+	plan := make(LengthPlan, len(sections))
+	remaining := lb.Words()
+	for _, i := range byPriority(sections) {
+		section := sections[i]
+		words := section.Words
+		if section.Priority != SectionEssential && words > remaining {
+			if remaining*2 < words {
+				continue
+			}
+			words = remaining
+		}
+		plan[section.Name] = max(words, 1)
+		remaining -= words
+	}
+	return plan
+}
+
+// Seconds estimates how long text takes to read at the budgeter's pace, counting pause markers
+// and the call snippets spliced in at call markers
+func (lb *LengthBudgeter) Seconds(text string) float64 {
+	calls := strings.Count(text, CallMarker)
+	text = strings.ReplaceAll(text, CallMarker, " ")
+	pauses := len(pausePattern.FindAllString(text, -1))
+	return float64(countWords(strings.Fields(text)))/lb.wordsPerMinute*60 +
+		float64(pauses)*pauseSeconds + float64(calls)*callSnippetSeconds
+}
+
+// drop returns the sections to leave out so the rest read within the target: the lowest
+// priority first, and the latest of equal priority first. Essential sections are never dropped,
+// even when they alone run over.
+func (lb *LengthBudgeter) drop(sections []ScriptSection, seconds []float64) map[int]bool {
+	total := 0.0
+	for _, s := range seconds {
+		total += s
+	}
+
+	dropped := make(map[int]bool)
+	order := byPriority(sections)
+	for j := len(order) - 1; j >= 0 && total > lb.targetSeconds; j-- {
+		i := order[j]
+		if sections[i].Priority == SectionEssential {
+			break
+		}
+		dropped[i] = true
+		total -= seconds[i]
+	}
+	return dropped
+}
+
+// byPriority returns section indexes from most to least important, in script order within a
+// priority
+func byPriority(sections []ScriptSection) []int {
+	order := make([]int, len(sections))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sections[order[a]].Priority < sections[order[b]].Priority
+	})
+	return order
+}
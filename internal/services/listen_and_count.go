@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+const (
+	countClipSeconds  = 12.0
+	countMinBouts     = 2 // Fewer is too easy to be a game
+	countMaxBouts     = 8 // More is too many for young children to keep track of
+	countMaxWindows   = 5 // Clip windows tried before giving up on a recording
+	countingMaxCached = 50
+)
+
+// countingPromptScript is read before the clip; the answer is saved for the outro
+var countingPromptScript = template.Must(template.New("counting_prompt").Parse(
+	"Let's play listen and count! In a moment you'll hear the {{.BirdName}}. " +
+		"Count on your fingers how many times it sings. Ready? Listen carefully!"))
+
+// countingAfterScript follows the clip
+var countingAfterScript = template.Must(template.New("counting_after").Parse(
+	"How many did you count? Keep your number safe, and we'll find out the answer at the very end!"))
+
+// countingAnswerScript opens the outro
+var countingAnswerScript = template.Must(template.New("counting_answer").Parse(
+	"Before we say goodbye, do you remember our listen and count game? " +
+		"The {{.BirdName}} sang {{.Count}} {{if eq .Count 1}}time{{else}}times{{end}}! " +
+		"Did you get it right? Great listening, explorer!"))
+
+// CountingActivity is the "listen and count" chapter: a prompt, a clip of the bird singing a
+// known number of times, and the answer saved for the outro
+type CountingActivity struct {
+	Bird      string         `json:"bird"`
+	Count     int            `json:"count"`
+	Bouts     []SongBout     `json:"bouts"`
+	Recording *SongRecording `json:"recording,omitempty"`
+	Script    string         `json:"script"`
+	Answer    string         `json:"answer"`
+	Audio     []byte         `json:"-"`
+}
+
+// countingClip is a bird's counted clip for a day, shared by every voice
+type countingClip struct {
+	recording *SongRecording
+	clip      []byte
+	bouts     []SongBout
+}
+
+// CountingGenerator builds "listen and count" activities: it picks a stretch of the bird's
+// recording with a countable number of song bouts, counts them with the song analysis, and
+// narrates the question for the activity chapter and the answer for the outro
+type CountingGenerator struct {
+	recordings *RecordingSelector
+	warmed     *RecordingWarmer // Optional pre-cached recordings, checked before the recording sources
+	tts        *ElevenLabsTTS
+	processor  AudioProcessor
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	clips      map[string]*countingClip     // bird and date -> counted clip
+	activities map[string]*CountingActivity // bird, date, and voice -> rendered activity
+	answers    map[string][]byte            // bird, date, and voice -> rendered answer
+}
+
+// NewCountingGenerator creates a generator using the shared recording selector for the clip
+func NewCountingGenerator(recordings *RecordingSelector, tts *ElevenLabsTTS) *CountingGenerator {
+	return &CountingGenerator{
+		recordings: recordings,
+		tts:        tts,
+		processor:  NewAudioProcessor(),
+		httpClient: recordingDownloadClient,
+		clips:      make(map[string]*countingClip),
+		activities: make(map[string]*CountingActivity),
+		answers:    make(map[string][]byte),
+	}
+}
+
+// SetRecordingCache reads recordings the warmer has already cached before asking the recording
+// sources
+func (cg *CountingGenerator) SetRecordingCache(warmer *RecordingWarmer) {
+	cg.warmed = warmer
+}
+
+// GenerateActivity returns the bird's activity for day, reusing a rendered activity for the same
+// bird, day, and voice
+func (cg *CountingGenerator) GenerateActivity(ctx context.Context, birdName string, day time.Time, voiceID string) (*CountingActivity, error) {
+	key := countingKey(birdName, day) + "|" + voiceID
+	cg.mu.Lock()
+	cached, ok := cg.activities[key]
+	cg.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	clip, err := cg.countedClip(ctx, birdName, day)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &CountingActivity{
+		Bird:      birdName,
+		Count:     len(clip.bouts),
+		Bouts:     clip.bouts,
+		Recording: clip.recording,
+	}
+	prompt, after, answer, err := BuildCountingScripts(birdName, activity.Count)
+	if err != nil {
+		return nil, err
+	}
+	activity.Script = prompt + " " + after
+	activity.Answer = answer
+
+	promptAudio, _, err := cg.tts.Render(ctx, prompt, voiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render counting prompt: %w", err)
+	}
+	afterAudio, _, err := cg.tts.Render(ctx, after, voiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render counting follow-up: %w", err)
+	}
+	if activity.Audio, err = cg.processor.Concat(promptAudio, clip.clip, afterAudio); err != nil {
+		return nil, fmt.Errorf("failed to splice counting audio (%s): %w", cg.processor.Name(), err)
+	}
+
+	cg.mu.Lock()
+	if len(cg.activities) >= countingMaxCached {
+		cg.activities = make(map[string]*CountingActivity)
+	}
+	cg.activities[key] = activity
+	cg.mu.Unlock()
+
+	slog.InfoContext(ctx, "[LISTEN_COUNT] Generated activity", "bird", birdName, "count", activity.Count,
+		"source", clip.recording.Source, "recording", clip.recording.ID, "bytes", len(activity.Audio))
+	return activity, nil
+}
+
+// PrependAnswer returns the outro with the day's counting answer read before it. The outro is
+// returned unchanged when the activity couldn't be made or the answer can't be rendered.
+func (cg *CountingGenerator) PrependAnswer(ctx context.Context, birdName string, day time.Time, voiceID string, outro []byte) []byte {
+	key := countingKey(birdName, day) + "|" + voiceID
+	cg.mu.Lock()
+	answer, ok := cg.answers[key]
+	cg.mu.Unlock()
+
+	if !ok {
+		clip, err := cg.countedClip(ctx, birdName, day)
+		if err != nil {
+			slog.InfoContext(ctx, "[LISTEN_COUNT] No activity, playing the outro alone", "bird", birdName, "error", err)
+			return outro
+		}
+		_, _, script, err := BuildCountingScripts(birdName, len(clip.bouts))
+		if err != nil {
+			return outro
+		}
+		if answer, _, err = cg.tts.Render(ctx, script, voiceID); err != nil {
+			slog.WarnContext(ctx, "[LISTEN_COUNT] Failed to render answer, playing the outro alone", "bird", birdName, "error", err)
+			return outro
+		}
+
+		cg.mu.Lock()
+		if len(cg.answers) >= countingMaxCached {
+			cg.answers = make(map[string][]byte)
+		}
+		cg.answers[key] = answer
+		cg.mu.Unlock()
+	}
+
+	combined, err := cg.processor.Concat(answer, outro)
+	if err != nil {
+		slog.WarnContext(ctx, "[LISTEN_COUNT] Failed to splice answer into outro", "bird", birdName, "processor", cg.processor.Name(), "error", err)
+		return outro
+	}
+	return combined
+}
+
+// countedClip returns the bird's counted clip for day, choosing and counting it on first use so
+// the activity and the outro's answer agree
+func (cg *CountingGenerator) countedClip(ctx context.Context, birdName string, day time.Time) (*countingClip, error) {
+	key := countingKey(birdName, day)
+	cg.mu.Lock()
+	cached, ok := cg.clips[key]
+	cg.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	scientificName := cg.recordings.scientificName(birdName)
+	recording, audio, warmed := cg.warmed.Cached(scientificName)
+	if !warmed {
+		var err error
+		if recording, err = cg.recordings.FindRecording(ctx, scientificName); err != nil {
+			return nil, fmt.Errorf("no recording for %s: %w", birdName, err)
+		}
+		if audio, err = cg.download(ctx, recording.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	clip, err := cg.selectClip(ctx, audio, recording)
+	if err != nil {
+		return nil, fmt.Errorf("no countable clip of %s: %w", birdName, err)
+	}
+
+	cg.mu.Lock()
+	if len(cg.clips) >= countingMaxCached {
+		cg.clips = make(map[string]*countingClip)
+	}
+	cg.clips[key] = clip
+	cg.mu.Unlock()
+	return clip, nil
+}
+
+// selectClip tries successive windows of the recording until one has a countable number of bouts.
+// Bouts are counted in the faded clip, so the answer matches what the child hears.
+func (cg *CountingGenerator) selectClip(ctx context.Context, audio []byte, recording *SongRecording) (*countingClip, error) {
+	duration, err := cg.processor.Duration(audio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure recording (%s): %w", cg.processor.Name(), err)
+	}
+
+	for window := 0; window < countMaxWindows; window++ {
+		start := float64(window) * countClipSeconds
+		if start+countClipSeconds > duration {
+			break
+		}
+
+		clip, err := cg.processor.Trim(audio, start, countClipSeconds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to trim clip (%s): %w", cg.processor.Name(), err)
+		}
+		if clip, err = cg.processor.Fade(clip, 0.3, 0.3); err != nil {
+			return nil, fmt.Errorf("failed to fade clip (%s): %w", cg.processor.Name(), err)
+		}
+
+		bouts, err := CountSongBouts(clip)
+		if err != nil {
+			return nil, err
+		}
+		if len(bouts) >= countMinBouts && len(bouts) <= countMaxBouts {
+			return &countingClip{recording: recording, clip: clip, bouts: bouts}, nil
+		}
+		slog.DebugContext(ctx, "[LISTEN_COUNT] Window not countable", "recording", recording.ID, "start", start, "bouts", len(bouts))
+	}
+	return nil, fmt.Errorf("recording %s has no %gs stretch with %d-%d song bouts", recording.ID, countClipSeconds, countMinBouts, countMaxBouts)
+}
+
+// download fetches a recording
+func (cg *CountingGenerator) download(ctx context.Context, url string) ([]byte, error) {
+	resp, err := httpx.Get(ctx, cg.httpClient, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("recording download returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// countingKey identifies a bird's activity for a day
+func countingKey(birdName string, day time.Time) string {
+	return strings.ToLower(birdName) + "|" + day.Format("2006-01-02")
+}
+
+// BuildCountingScripts returns the question read before the clip, the line read after it, and the
+// answer that opens the outro
+func BuildCountingScripts(birdName string, count int) (prompt, after, answer string, err error) {
+	data := struct {
+		BirdName string
+		Count    int
+	}{birdName, count}
+
+	var buf bytes.Buffer
+	for _, part := range []struct {
+		script *template.Template
+		out    *string
+	}{
+		{countingPromptScript, &prompt},
+		{countingAfterScript, &after},
+		{countingAnswerScript, &answer},
+	} {
+		buf.Reset()
+		if err := part.script.Execute(&buf, data); err != nil {
+			return "", "", "", fmt.Errorf("failed to build counting script: %w", err)
+		}
+		*part.out = buf.String()
+	}
+	return prompt, after, answer, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import "strings"
+
+// DefaultLocale is the language the original scripts are written in
+const DefaultLocale = "en"
+
+// SupportedLocales are the narration languages with translated templates
+var SupportedLocales = []string{"en", "fr", "de", "es"}
+
+// NormalizeLocale reduces a language tag ("fr-FR", "de_AT", "ES") to a supported base language,
+// falling back to English
+func NormalizeLocale(locale string) string {
+	base := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+	for _, supported := range SupportedLocales {
+		if base == supported {
+			return base
+		}
+	}
+	return DefaultLocale
+}
+
+// LocaleTemplates holds the translated text for one narration language. English keeps its
+// original, richer templates in the generators and managers themselves.
+type LocaleTemplates struct {
+	Intros     []string // Generic intros (no bird name)
+	BirdIntros []string // Intros with one %s for the bird name
+
+	// Basic fact generator
+	ScientificName    string // %s bird, %s scientific name
+	DidYouKnow        string
+	AmazingBirdIntro  string // %s bird
+	FallbackFact      string // %s bird
+	ClosingScientific string
+	ClosingPlain      string
+	GenericFacts      []string
+
+	// Static outros by outro type (see StaticOutroTypes)
+	Outros map[string][]string
+}
+
+// TemplatesForLocale returns the translated templates for a locale, or false for English
+func TemplatesForLocale(locale string) (*LocaleTemplates, bool) {
+	templates, ok := localeTemplates[NormalizeLocale(locale)]
+	return templates, ok
+}
+
+var localeTemplates = map[string]*LocaleTemplates{
+	"fr": {
+		Intros: []string{
+			"Bienvenue, petits détectives de la nature ! C'est l'heure de découvrir un oiseau extraordinaire.",
+			"Bonjour, jeunes explorateurs ! L'oiseau du jour attend de chanter pour vous.",
+			"Prêts pour l'aventure ? Allons rencontrer l'oiseau du jour !",
+			"Bon retour, petits auditeurs ! Un merveilleux oiseau chante rien que pour vous.",
+		},
+		BirdIntros: []string{
+			"Notre ami du jour est le %s ! Écoutons son joli chant.",
+			"Écoutez bien ! Le %s a quelque chose de spécial à vous dire.",
+			"Préparez-vous à rencontrer le merveilleux %s !",
+		},
+		ScientificName:    "Le nom scientifique du %s est %s.",
+		DidYouKnow:        "Le savais-tu ?",
+		AmazingBirdIntro:  "Laisse-moi te présenter l'incroyable %s ! Le savais-tu ?",
+		FallbackFact:      "Le %s est un oiseau extraordinaire !",
+		ClosingScientific: "On trouve des oiseaux partout dans le monde, chacun parfaitement adapté à sa maison !",
+		ClosingPlain:      "Chaque oiseau a sa propre histoire. Écoute bien pour découvrir son chant unique !",
+		GenericFacts: []string{
+			"Les oiseaux sont les seuls animaux à avoir des plumes !",
+			"Les os des oiseaux sont creux, ce qui les rend assez légers pour voler !",
+			"Les oiseaux voient des couleurs que nous ne pouvons même pas imaginer !",
+			"Les oiseaux sont des dinosaures vivants !",
+		},
+		Outros: map[string][]string{
+			"joke": {
+				"Une petite blague avant de partir ! Quel oiseau ne se lave jamais ? <break time=\"1.0s\" /> Le moineau sale ! À demain pour une nouvelle aventure, explorateurs !",
+			},
+			"wisdom": {
+				"Souvenez-vous, petits explorateurs : même le plus petit oiseau peut chanter la plus belle chanson. <break time=\"1.0s\" /> Déployez vos ailes aujourd'hui ! À demain !",
+			},
+			"teaser": {
+				"Quel oiseau incroyable ! Demain, nous rencontrerons un nouvel ami à plumes. Sera-t-il grand ou petit ? <break time=\"1.0s\" /> Revenez demain pour le découvrir, explorateurs !",
+			},
+			"challenge": {
+				"Ton défi d'explorateur : peux-tu imiter le chant d'aujourd'hui trois fois ? <break time=\"1.0s\" /> Demain, nous découvrirons un nouvel oiseau ensemble. Bonne exploration !",
+			},
+			"funfact": {
+				"Avant de partir, le savais-tu ? La sterne arctique vole du pôle Nord au pôle Sud chaque année ! <break time=\"1.0s\" /> Incroyable, non ? À demain pour un nouvel oiseau !",
+			},
+		},
+	},
+	"de": {
+		Intros: []string{
+			"Willkommen, kleine Naturdetektive! Zeit, einen erstaunlichen Vogel zu entdecken.",
+			"Hallo, Vogelforscher! Der Vogel des Tages wartet schon darauf, für euch zu singen.",
+			"Bereit für ein Abenteuer? Lasst uns den Vogel des Tages kennenlernen!",
+			"Willkommen zurück, kleine Zuhörer! Ein wunderbarer Vogel singt nur für euch.",
+		},
+		BirdIntros: []string{
+			"Unser Freund des Tages ist der %s! Hören wir uns sein schönes Lied an.",
+			"Hört gut zu! Der %s hat euch etwas Besonderes zu erzählen.",
+			"Macht euch bereit für den wunderbaren %s!",
+		},
+		ScientificName:    "Der wissenschaftliche Name des %s ist %s.",
+		DidYouKnow:        "Wusstest du schon?",
+		AmazingBirdIntro:  "Ich erzähle dir vom erstaunlichen %s! Wusstest du schon?",
+		FallbackFact:      "Der %s ist ein erstaunlicher Vogel!",
+		ClosingScientific: "Vögel gibt es auf der ganzen Welt, und jeder ist perfekt an sein Zuhause angepasst!",
+		ClosingPlain:      "Jeder Vogel hat seine eigene Geschichte. Hör genau hin, um sein besonderes Lied zu lernen!",
+		GenericFacts: []string{
+			"Vögel sind die einzigen Tiere mit Federn!",
+			"Vogelknochen sind hohl, deshalb sind Vögel leicht genug zum Fliegen!",
+			"Vögel können Farben sehen, die wir Menschen uns nicht einmal vorstellen können!",
+			"Vögel sind lebende Dinosaurier!",
+		},
+		Outros: map[string][]string{
+			"joke": {
+				"Noch ein kleiner Witz zum Schluss! Welcher Vogel ist immer pünktlich? <break time=\"1.0s\" /> Die Kuckucksuhr! Bis morgen zum nächsten Vogelabenteuer, Forscher!",
+			},
+			"wisdom": {
+				"Denkt daran, kleine Forscher: Auch der kleinste Vogel kann das schönste Lied singen. <break time=\"1.0s\" /> Breitet heute eure Flügel aus! Bis morgen!",
+			},
+			"teaser": {
+				"Was für ein toller Vogel! Morgen treffen wir einen neuen gefiederten Freund. Wird er groß oder klein sein? <break time=\"1.0s\" /> Kommt morgen wieder und findet es heraus!",
+			},
+			"challenge": {
+				"Deine Forscher-Aufgabe: Kannst du das Vogellied von heute dreimal nachmachen? <break time=\"1.0s\" /> Morgen entdecken wir zusammen einen neuen Vogel. Viel Spaß beim Forschen!",
+			},
+			"funfact": {
+				"Bevor du gehst, wusstest du schon? Die Küstenseeschwalbe fliegt jedes Jahr vom Nordpol zum Südpol! <break time=\"1.0s\" /> Erstaunlich, oder? Bis morgen!",
+			},
+		},
+	},
+	"es": {
+		Intros: []string{
+			"¡Bienvenidos, pequeños detectives de la naturaleza! Es hora de descubrir un ave increíble.",
+			"¡Hola, exploradores de aves! El ave de hoy está esperando para cantar para ustedes.",
+			"¿Listos para la aventura? ¡Vamos a conocer el ave del día!",
+			"¡Bienvenidos de nuevo, pequeños oyentes! Un ave maravillosa canta solo para ustedes.",
+		},
+		BirdIntros: []string{
+			"¡Nuestro amigo de hoy es el %s! Escuchemos su hermoso canto.",
+			"¡Escucha con atención! El %s tiene algo especial que contarte.",
+			"¡Prepárate para conocer al maravilloso %s!",
+		},
+		ScientificName:    "El nombre científico del %s es %s.",
+		DidYouKnow:        "¿Sabías que?",
+		AmazingBirdIntro:  "¡Déjame contarte sobre el increíble %s! ¿Sabías que?",
+		FallbackFact:      "¡El %s es un ave increíble!",
+		ClosingScientific: "¡Hay aves en todo el mundo, y cada una está perfectamente adaptada a su hogar!",
+		ClosingPlain:      "Cada ave tiene su propia historia. ¡Escucha con atención para aprender su canto único!",
+		GenericFacts: []string{
+			"¡Las aves son los únicos animales con plumas!",
+			"¡Los huesos de las aves son huecos, por eso son tan ligeras para volar!",
+			"¡Las aves pueden ver colores que nosotros ni siquiera imaginamos!",
+			"¡Las aves son dinosaurios vivientes!",
+		},
+		Outros: map[string][]string{
+			"joke": {
+				"¡Un chiste antes de irte! ¿Qué le dijo un pájaro a otro? <break time=\"1.0s\" /> ¡Pío, pío, qué bonito es volar! ¡Hasta mañana, exploradores!",
+			},
+			"wisdom": {
+				"Recuerden, pequeños exploradores: hasta el pájaro más pequeño puede cantar la canción más bonita. <break time=\"1.0s\" /> ¡Extiendan sus alas hoy! ¡Hasta mañana!",
+			},
+			"teaser": {
+				"¡Qué ave tan increíble! Mañana conoceremos a otro amigo con plumas. ¿Será grande o pequeño? <break time=\"1.0s\" /> ¡Vuelve mañana para descubrirlo, explorador!",
+			},
+			"challenge": {
+				"Tu reto de explorador: ¿puedes imitar el canto de hoy tres veces? <break time=\"1.0s\" /> Mañana descubriremos un ave nueva juntos. ¡Feliz exploración!",
+			},
+			"funfact": {
+				"Antes de irte, ¿sabías que? ¡El charrán ártico vuela del Polo Norte al Polo Sur cada año! <break time=\"1.0s\" /> Increíble, ¿verdad? ¡Hasta mañana!",
+			},
+		},
+	},
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/callen/bird-song-explorer/pkg/inaturalist"
+)
+
+// LocalizedNameService resolves proper Spanish/French common names for bilingual mode from
+// iNaturalist's locale-tagged taxon names, rather than machine-translating English names.
+// Lookups are cached per taxon, including species iNaturalist doesn't know, so each species
+// costs one API call; transient errors are not cached.
+type LocalizedNameService struct {
+	client *inaturalist.Client
+	locale string
+
+	mu    sync.RWMutex
+	cache map[string]*inaturalist.Taxon // Keyed by scientific name; nil means not found
+}
+
+// NewLocalizedNameService creates a service for the given locale ("es", "fr", "es-MX", ...).
+// An empty locale disables bilingual mode.
+func NewLocalizedNameService(locale string) *LocalizedNameService {
+	return &LocalizedNameService{
+		client: inaturalist.NewClient(),
+		locale: strings.TrimSpace(locale),
+		cache:  make(map[string]*inaturalist.Taxon),
+	}
+}
+
+// Enabled reports whether bilingual mode is on
+func (s *LocalizedNameService) Enabled() bool {
+	return s.locale != ""
+}
+
+// Locale returns the configured second language
+func (s *LocalizedNameService) Locale() string {
+	return s.locale
+}
+
+// CommonName returns the localized common name for a species.
+// Falls back to the English name when bilingual mode is off or no localized name exists.
+func (s *LocalizedNameService) CommonName(ctx context.Context, commonName string, scientificName string) string {
+	if !s.Enabled() {
+		return commonName
+	}
+
+	taxon := s.taxon(ctx, commonName, scientificName)
+	if taxon == nil {
+		return commonName
+	}
+
+	if name, ok := taxon.CommonNameForLocale(s.locale); ok {
+		return name
+	}
+	return commonName
+}
+
+func (s *LocalizedNameService) taxon(ctx context.Context, commonName string, scientificName string) *inaturalist.Taxon {
+	key := strings.ToLower(scientificName)
+	if key == "" {
+		key = strings.ToLower(commonName)
+	}
+
+	s.mu.RLock()
+	taxon, cached := s.cache[key]
+	s.mu.RUnlock()
+	if cached {
+		return taxon
+	}
+
+	query := scientificName
+	if query == "" {
+		query = commonName
+	}
+
+	taxon, err := s.client.SearchTaxon(ctx, query)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		log.Printf("[LOCALIZED_NAMES] No iNaturalist taxon for %s: %v", query, err)
+		if !strings.Contains(err.Error(), "no results found") {
+			return nil
+		}
+		taxon = nil
+	}
+
+	s.mu.Lock()
+	s.cache[key] = taxon
+	s.mu.Unlock()
+
+	return taxon
+}
+
+// bilingualLanguages names each second language the way the English narration says it
+var bilingualLanguages = map[string]string{
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+}
+
+// BilingualNarration holds the intro and announcement lines naming the bird in both languages
+type BilingualNarration struct {
+	Intro        string
+	Announcement string
+}
+
+// BilingualNarrationFor writes the intro and announcement lines for a bird and its name in the
+// second language. It returns false when the language has no English name or the localized name
+// is just the English one, so there's nothing to teach.
+func BilingualNarrationFor(locale string, birdName string, localizedName string) (BilingualNarration, bool) {
+	language, ok := bilingualLanguages[NormalizeLocale(locale)]
+	if !ok || localizedName == "" || strings.EqualFold(localizedName, birdName) {
+		return BilingualNarration{}, false
+	}
+	return BilingualNarration{
+		Intro: fmt.Sprintf("Get ready to meet the wonderful %s! In %s, this bird is called the %s.",
+			birdName, language, localizedName),
+		Announcement: fmt.Sprintf("Today's bird is the %s. In %s, we say %s. Can you say %s?",
+			birdName, language, localizedName, localizedName),
+	}, true
+}
@@ -0,0 +1,26 @@
+package services
+
+import "testing"
+
+func TestBilingualNarrationNamesBothLanguages(t *testing.T) {
+	narration, ok := BilingualNarrationFor("es-MX", "American Robin", "Mirlo primavera")
+	if !ok {
+		t.Fatal("no bilingual narration for Spanish")
+	}
+	if want := "Get ready to meet the wonderful American Robin! In Spanish, this bird is called the Mirlo primavera."; narration.Intro != want {
+		t.Errorf("intro = %q, want %q", narration.Intro, want)
+	}
+	if want := "Today's bird is the American Robin. In Spanish, we say Mirlo primavera. Can you say Mirlo primavera?"; narration.Announcement != want {
+		t.Errorf("announcement = %q, want %q", narration.Announcement, want)
+	}
+
+	for _, tt := range []struct{ locale, localized string }{
+		{"fr", "american robin"}, // No localized name, so the English one came back
+		{"fr", ""},
+		{"ja", "コマツグミ"}, // No English name for the language
+	} {
+		if _, ok := BilingualNarrationFor(tt.locale, "American Robin", tt.localized); ok {
+			t.Errorf("BilingualNarrationFor(%q, %q) wrote narration", tt.locale, tt.localized)
+		}
+	}
+}
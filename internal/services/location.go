@@ -4,15 +4,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 
 	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
-type LocationService struct{}
+// IPLocationProvider resolves an IP address to a location
+type IPLocationProvider interface {
+	Name() string
+	Locate(ip string) (*models.Location, error)
+}
+
+// LocationService resolves listener IPs through its providers in order, falling through to the
+// next one when a provider fails or doesn't know the address
+type LocationService struct {
+	providers []IPLocationProvider
+}
 
-func NewLocationService() *LocationService {
-	return &LocationService{}
+// NewLocationService uses the GeoLite2 database at geoLite2Path when there is one, with the
+// remote lookup API as fallback
+func NewLocationService(geoLite2Path string) *LocationService {
+	var providers []IPLocationProvider
+	if geoLite2Path != "" {
+		geoLite2, err := NewGeoLite2Provider(geoLite2Path)
+		if err != nil {
+			log.Printf("[LOCATION] GeoLite2 database unavailable, using remote lookups only: %v", err)
+		} else {
+			geoLite2.ReloadOnSIGHUP()
+			providers = append(providers, geoLite2)
+		}
+	}
+	providers = append(providers, NewRemoteIPProvider())
+	return &LocationService{providers: providers}
 }
 
 func (s *LocationService) GetLocationFromIP(ip string) (*models.Location, error) {
@@ -20,9 +43,35 @@ func (s *LocationService) GetLocationFromIP(ip string) (*models.Location, error)
 		return nil, fmt.Errorf("invalid IP address for geolocation: %s", ip)
 	}
 
+	var lastErr error
+	for _, provider := range s.providers {
+		location, err := provider.Locate(ip)
+		if err == nil {
+			return location, nil
+		}
+		lastErr = err
+		if len(s.providers) > 1 {
+			log.Printf("[LOCATION] %s lookup failed for %s: %v", provider.Name(), ip, err)
+		}
+	}
+	return nil, lastErr
+}
+
+// RemoteIPProvider looks addresses up with the ip-api.com API, which rate limits the free tier
+type RemoteIPProvider struct{}
+
+func NewRemoteIPProvider() *RemoteIPProvider {
+	return &RemoteIPProvider{}
+}
+
+func (p *RemoteIPProvider) Name() string {
+	return "ip-api"
+}
+
+func (p *RemoteIPProvider) Locate(ip string) (*models.Location, error) {
 	// Using ip-api.com instead of ipapi.co (better rate limits for free tier)
 	url := fmt.Sprintf("http://ip-api.com/json/%s", ip)
-	resp, err := http.Get(url)
+	resp, err := httpx.Default.Get(url)
 	if err != nil {
 		log.Printf("[LOCATION] Failed to get IP location for %s: %v", ip, err)
 		return nil, fmt.Errorf("failed to get IP location: %w", err)
@@ -50,7 +99,7 @@ func (s *LocationService) GetLocationFromIP(ip string) (*models.Location, error)
 	}
 
 	log.Printf("[LOCATION] Successfully resolved IP %s to %s, %s", ip, result.City, result.Country)
-	
+
 	return &models.Location{
 		Latitude:  result.Latitude,
 		Longitude: result.Longitude,
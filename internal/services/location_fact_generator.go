@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services/factkit"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
+
+// LocationFactGenerator writes a short guide about the bird around the listener: recent eBird
+// sightings nearby, where to look in their town, and how to help in their state
+type LocationFactGenerator struct {
+	v4Generator *ImprovedFactGeneratorV4
+}
+
+// NewLocationFactGenerator creates a location-focused fact generator
+func NewLocationFactGenerator(ebirdAPIKey string, rng *randx.Randomizer) *LocationFactGenerator {
+	return &LocationFactGenerator{
+		v4Generator: NewImprovedFactGeneratorV4(ebirdAPIKey, rng),
+	}
+}
+
+// GetGeneratorType returns the type of this generator
+func (g *LocationFactGenerator) GetGeneratorType() string {
+	return FactGeneratorLocation
+}
+
+// GenerateFactScript creates a location-focused fact script for a bird
+func (g *LocationFactGenerator) GenerateFactScript(ctx context.Context, bird *models.Bird, latitude, longitude float64) string {
+	return g.GenerateFactTranscript(ctx, bird, latitude, longitude).Script
+}
+
+// GenerateFactTranscript creates a location-focused fact script with the source of every sentence
+func (g *LocationFactGenerator) GenerateFactTranscript(ctx context.Context, bird *models.Bird, latitude, longitude float64) *ScriptTranscript {
+	return g.v4Generator.GenerateLocalTranscript(ctx, bird, latitude, longitude)
+}
+
+// GenerateLocalTranscript creates the location sections of the enhanced script on their own,
+// without the fact sheet, so it needs only eBird and the geocoder
+func (fg *ImprovedFactGeneratorV4) GenerateLocalTranscript(ctx context.Context, bird *models.Bird, lat, lng float64) *ScriptTranscript {
+	bird, _ = withTaxonomy(fg.taxonomy, bird)
+	var builder transcriptBuilder
+	transitions := factkit.NewTransitions(fg.rng)
+	locationContext := fg.getLocationContext(ctx, bird, lat, lng)
+
+	builder.add(fg.generateScientificIntro(bird), SourceTemplate, "scientific_intro")
+	if len(locationContext.RecentSightings) > 0 {
+		builder.add(fg.generateLocationIntro(bird, locationContext), SourceEBird, "recent_observations")
+		builder.add(fg.generateRecentSightingsInfo(bird, locationContext), SourceEBird, "recent_observations")
+	} else {
+		builder.add(fg.generateLocationIntro(bird, locationContext), SourceTemplate, "location_greeting")
+	}
+	builder.add(fg.specialGuestInfo(ctx, bird, lat, lng), SourceEBird, "historic_observations")
+
+	builder.add(transitions.Next(factkit.TransitionAction), SourceTemplate, "transition")
+	builder.add(fg.generateLocalHabitatBehavior(bird, locationContext), SourceTemplate, "habitat")
+	builder.add(fg.generateLocalConservationInfo(bird, locationContext), SourceTemplate, "conservation")
+
+	if dawnChorus := dawnChorusSentence(lat, lng); dawnChorus != "" {
+		builder.add(dawnChorus, SourceTemplate, "dawn_chorus")
+	}
+	builder.addCallCue(bird.CommonName)
+	builder.add(fg.closingFor(bird.CommonName, locationContext), SourceTemplate, "closing")
+
+	return builder.transcript(bird.CommonName, FactGeneratorLocation)
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+)
+
+// Defaults for location smoothing; override with LOCATION_SMOOTHING_WINDOW_DAYS / LOCATION_SMOOTHING_SWITCH_DAYS
+const (
+	defaultLocationWindowDays = 7
+	defaultLocationSwitchDays = 3
+)
+
+// LocationObservation is one day's IP lookups for a device that resolved to the same region
+type LocationObservation struct {
+	Day       string  `json:"day"`    // UTC date, YYYY-MM-DD
+	RegionKey string  `json:"region"` // "Region, Country"
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	City      string  `json:"city,omitempty"`
+	Region    string  `json:"region_name,omitempty"`
+	Country   string  `json:"country,omitempty"`
+	Weight    float64 `json:"weight"` // Summed lookup confidence for the day
+}
+
+// SmoothLocation records an IP-resolved location for a device and returns the location to use
+// for its regional bird pool. Lookups within the current region refine the coordinates as a
+// confidence-weighted average; a different region only takes over once it has been the leading
+// region for locationSwitchDays consecutive observed days, so VPN and hotspot hops are ignored.
+func (dr *DeviceRegistry) SmoothLocation(deviceID string, observed *models.Location) *models.Location {
+	if observed == nil {
+		return nil
+	}
+
+	dr.mu.Lock()
+	now := time.Now().UTC()
+	record, exists := dr.devices[deviceID]
+	if !exists {
+		record = &DeviceRecord{
+			DeviceID:  deviceID,
+			FirstSeen: now,
+			LastSeen:  now,
+		}
+		dr.devices[deviceID] = record
+	}
+
+	// Repeat lookups on the same day only refine the coordinates, so they aren't worth a write;
+	// the registry is saved when a device, a day's region, or a move is first seen
+	changed := !exists
+	if dr.addObservation(record, observed, now) {
+		changed = true
+	}
+
+	observedKey := regionKey(observed)
+	switch {
+	case record.Location == nil:
+		record.Location = dr.averageLocation(record, observedKey)
+		changed = true
+	case regionKey(record.Location) == observedKey:
+		record.Location = dr.averageLocation(record, observedKey)
+	case dr.regionLeadsRecentDays(record, observedKey):
+		log.Printf("[DEVICE_REGISTRY] %s moved from %s to %s after %d consistent days",
+			deviceID, regionKey(record.Location), observedKey, dr.locationSwitchDays)
+		record.Location = dr.averageLocation(record, observedKey)
+		changed = true
+	default:
+		log.Printf("[DEVICE_REGISTRY] %s resolved to %s, keeping %s until the move is consistent",
+			deviceID, observedKey, regionKey(record.Location))
+	}
+
+	smoothed := *record.Location
+	smoothed.IPAddress = observed.IPAddress
+	dr.mu.Unlock()
+
+	if !changed {
+		return &smoothed
+	}
+	if err := dr.save(); err != nil {
+		log.Printf("[DEVICE_REGISTRY] Failed to save registry: %v", err)
+	}
+
+	return &smoothed
+}
+
+// addObservation merges a lookup into today's evidence for its region and drops evidence outside
+// the window. It reports whether the lookup started a new day's evidence for its region.
+func (dr *DeviceRegistry) addObservation(record *DeviceRecord, observed *models.Location, now time.Time) bool {
+	day := now.Format("2006-01-02")
+	key := regionKey(observed)
+	weight := locationConfidence(observed)
+
+	merged := false
+	for i := range record.LocationEvidence {
+		evidence := &record.LocationEvidence[i]
+		if evidence.Day != day || evidence.RegionKey != key {
+			continue
+		}
+		total := evidence.Weight + weight
+		evidence.Latitude = (evidence.Latitude*evidence.Weight + observed.Latitude*weight) / total
+		evidence.Longitude = (evidence.Longitude*evidence.Weight + observed.Longitude*weight) / total
+		evidence.Weight = total
+		if observed.City != "" {
+			evidence.City = observed.City
+		}
+		merged = true
+		break
+	}
+
+	if !merged {
+		record.LocationEvidence = append(record.LocationEvidence, LocationObservation{
+			Day:       day,
+			RegionKey: key,
+			Latitude:  observed.Latitude,
+			Longitude: observed.Longitude,
+			City:      observed.City,
+			Region:    observed.Region,
+			Country:   observed.Country,
+			Weight:    weight,
+		})
+	}
+
+	cutoff := now.AddDate(0, 0, -dr.locationWindowDays).Format("2006-01-02")
+	kept := record.LocationEvidence[:0]
+	for _, evidence := range record.LocationEvidence {
+		if evidence.Day > cutoff {
+			kept = append(kept, evidence)
+		}
+	}
+	record.LocationEvidence = kept
+	return !merged
+}
+
+// regionLeadsRecentDays reports whether key had the most evidence on each of the most recent switch days
+func (dr *DeviceRegistry) regionLeadsRecentDays(record *DeviceRecord, key string) bool {
+	leaders := make(map[string]string)
+	leaderWeight := make(map[string]float64)
+	for _, evidence := range record.LocationEvidence {
+		if evidence.Weight > leaderWeight[evidence.Day] {
+			leaders[evidence.Day] = evidence.RegionKey
+			leaderWeight[evidence.Day] = evidence.Weight
+		}
+	}
+
+	days := make([]string, 0, len(leaders))
+	for day := range leaders {
+		days = append(days, day)
+	}
+	if len(days) < dr.locationSwitchDays {
+		return false
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+
+	for _, day := range days[:dr.locationSwitchDays] {
+		if leaders[day] != key {
+			return false
+		}
+	}
+	return true
+}
+
+// averageLocation returns the confidence-weighted location of all evidence in a region
+func (dr *DeviceRegistry) averageLocation(record *DeviceRecord, key string) *models.Location {
+	var location models.Location
+	var totalWeight float64
+	var latestDay string
+
+	for _, evidence := range record.LocationEvidence {
+		if evidence.RegionKey != key {
+			continue
+		}
+		location.Latitude += evidence.Latitude * evidence.Weight
+		location.Longitude += evidence.Longitude * evidence.Weight
+		totalWeight += evidence.Weight
+
+		if evidence.Day >= latestDay {
+			latestDay = evidence.Day
+			location.City = evidence.City
+			location.Region = evidence.Region
+			location.Country = evidence.Country
+		}
+	}
+
+	if totalWeight > 0 {
+		location.Latitude /= totalWeight
+		location.Longitude /= totalWeight
+	}
+	return &location
+}
+
+// regionKey identifies the state/province-level region that selects a bird pool
+func regionKey(location *models.Location) string {
+	if location.Region == "" {
+		return location.Country
+	}
+	return fmt.Sprintf("%s, %s", location.Region, location.Country)
+}
+
+// locationConfidence weights a lookup by how specific the IP geolocation was
+func locationConfidence(location *models.Location) float64 {
+	switch {
+	case location.City != "" && location.Region != "":
+		return 1.0
+	case location.Region != "":
+		return 0.6
+	default:
+		return 0.3
+	}
+}
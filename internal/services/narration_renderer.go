@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// narrationBucket holds every bird's pre-rendered narration under birds/{bird}/narration/
+const narrationBucket = "bird-song-explorer-audio"
+
+// NarrationRenderer renders narration variants (a fact generator's guide, a household's
+// description, a holiday intro) to the bucket the streaming endpoints play them from. Variants
+// already in the bucket aren't rendered again.
+type NarrationRenderer struct {
+	tts   *ElevenLabsTTS
+	store AssetStore
+}
+
+// NewNarrationRenderer renders with tts into store, named relative to the birds/ folder
+func NewNarrationRenderer(tts *ElevenLabsTTS, store AssetStore) *NarrationRenderer {
+	return &NarrationRenderer{tts: tts, store: store}
+}
+
+// NewNarrationStore returns the bucket folder holding every bird's narration, or files under dir
+// when it is set
+func NewNarrationStore(dir string) AssetStore {
+	if dir != "" {
+		return NewLocalAssetStore(dir)
+	}
+	return NewGCSAssetStore(narrationBucket, "birds/")
+}
+
+// NarrationName is a bird's narration file relative to the birds/ folder, e.g.
+// "american_robin/narration/description_enhanced.mp3"
+func NarrationName(birdName string, file string) string {
+	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	return birdDir + "/narration/" + file
+}
+
+// Render narrates script with voiceID to name unless it is already rendered, and reports whether
+// it rendered it
+func (nr *NarrationRenderer) Render(ctx context.Context, name string, script string, voiceID string) (bool, error) {
+	if nr.store.Exists(name) {
+		return false, nil
+	}
+	if strings.TrimSpace(script) == "" {
+		return false, fmt.Errorf("no script to narrate for %s", name)
+	}
+
+	audio, _, err := nr.tts.Render(ctx, script, voiceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to narrate %s: %w", name, err)
+	}
+	if err := nr.store.WriteFile(name, audio); err != nil {
+		return false, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+
+	slog.InfoContext(ctx, "[NARRATION] Rendered variant", "name", name, "bytes", len(audio))
+	return true, nil
+}
@@ -0,0 +1,558 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// NativeAudioProcessor edits audio without ffmpeg. WAV (16-bit PCM) is edited sample by sample.
+// MP3 is edited at the frame level without decoding: gain and fades rewrite each granule's
+// global_gain (steps of about 1.5 dB), and cuts land on frame boundaries (about 26 ms).
+type NativeAudioProcessor struct{}
+
+// Name returns "native"
+func (np *NativeAudioProcessor) Name() string {
+	return "native"
+}
+
+// Concat joins clips of the same format; MP3 clips must share a sample rate and channel count
+func (np *NativeAudioProcessor) Concat(clips ...[]byte) ([]byte, error) {
+	if len(clips) == 0 {
+		return nil, fmt.Errorf("no clips to concatenate")
+	}
+
+	if isWAV(clips[0]) {
+		var joined *pcmAudio
+		for i, clip := range clips {
+			pcm, err := parseWAV(clip)
+			if err != nil {
+				return nil, fmt.Errorf("clip %d: %w", i, err)
+			}
+			if joined == nil {
+				joined = pcm
+				continue
+			}
+			if pcm.sampleRate != joined.sampleRate || pcm.channels != joined.channels {
+				return nil, fmt.Errorf("clip %d is %d Hz/%d ch, expected %d Hz/%d ch", i, pcm.sampleRate, pcm.channels, joined.sampleRate, joined.channels)
+			}
+			joined.samples = append(joined.samples, pcm.samples...)
+		}
+		return joined.encode(), nil
+	}
+
+	var joined []mp3Frame
+	for i, clip := range clips {
+		frames, err := parseMP3Frames(clip)
+		if err != nil {
+			return nil, fmt.Errorf("clip %d: %w", i, err)
+		}
+		frames = audioFrames(frames)
+		if len(frames) == 0 {
+			continue
+		}
+		if len(joined) > 0 {
+			if frames[0].sampleRate != joined[0].sampleRate || frames[0].channels != joined[0].channels {
+				return nil, fmt.Errorf("clip %d is %d Hz/%d ch, expected %d Hz/%d ch", i, frames[0].sampleRate, frames[0].channels, joined[0].sampleRate, joined[0].channels)
+			}
+			// Its bit reservoir would point into the previous clip's frames
+			frames[0] = frames[0].silenced()
+		}
+		joined = append(joined, frames...)
+	}
+	return encodeMP3Frames(joined), nil
+}
+
+// Gain scales the amplitude by factor
+func (np *NativeAudioProcessor) Gain(audio []byte, factor float64) ([]byte, error) {
+	if isWAV(audio) {
+		pcm, err := parseWAV(audio)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pcm.samples {
+			pcm.samples[i] = clampSample(float64(pcm.samples[i]) * factor)
+		}
+		return pcm.encode(), nil
+	}
+
+	frames, err := parseMP3Frames(audio)
+	if err != nil {
+		return nil, err
+	}
+	steps := gainSteps(factor)
+	for i := range frames {
+		frames[i] = frames[i].withGain(steps)
+	}
+	return encodeMP3Frames(frames), nil
+}
+
+// Fade applies linear fades at either end
+func (np *NativeAudioProcessor) Fade(audio []byte, fadeIn, fadeOut float64) ([]byte, error) {
+	if isWAV(audio) {
+		pcm, err := parseWAV(audio)
+		if err != nil {
+			return nil, err
+		}
+		total := float64(len(pcm.samples)/pcm.channels) / float64(pcm.sampleRate)
+		for i := range pcm.samples {
+			t := float64(i/pcm.channels) / float64(pcm.sampleRate)
+			pcm.samples[i] = clampSample(float64(pcm.samples[i]) * fadeFactor(t, total, fadeIn, fadeOut))
+		}
+		return pcm.encode(), nil
+	}
+
+	frames, err := parseMP3Frames(audio)
+	if err != nil {
+		return nil, err
+	}
+	total := framesDuration(frames)
+	elapsed := 0.0
+	for i := range frames {
+		if frames[i].isTag {
+			continue
+		}
+		length := float64(frames[i].samples) / float64(frames[i].sampleRate)
+		// Evaluate the curve mid-frame so the first and last frames aren't fully silent
+		frames[i] = frames[i].withGain(gainSteps(fadeFactor(elapsed+length/2, total, fadeIn, fadeOut)))
+		elapsed += length
+	}
+	return encodeMP3Frames(frames), nil
+}
+
+// Trim keeps duration seconds starting at start
+func (np *NativeAudioProcessor) Trim(audio []byte, start, duration float64) ([]byte, error) {
+	if isWAV(audio) {
+		pcm, err := parseWAV(audio)
+		if err != nil {
+			return nil, err
+		}
+		first := int(start*float64(pcm.sampleRate)) * pcm.channels
+		last := int((start+duration)*float64(pcm.sampleRate)) * pcm.channels
+		first, last = min(max(first, 0), len(pcm.samples)), min(max(last, 0), len(pcm.samples))
+		pcm.samples = pcm.samples[first:last]
+		return pcm.encode(), nil
+	}
+
+	frames, err := parseMP3Frames(audio)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []mp3Frame
+	elapsed := 0.0
+	for _, frame := range audioFrames(frames) {
+		length := float64(frame.samples) / float64(frame.sampleRate)
+		if middle := elapsed + length/2; middle >= start && middle < start+duration {
+			if len(kept) == 0 && elapsed > 0 {
+				frame = frame.silenced()
+			}
+			kept = append(kept, frame)
+		}
+		elapsed += length
+	}
+	return encodeMP3Frames(kept), nil
+}
+
+// Duration returns the clip length
+func (np *NativeAudioProcessor) Duration(audio []byte) (float64, error) {
+	if isWAV(audio) {
+		pcm, err := parseWAV(audio)
+		if err != nil {
+			return 0, err
+		}
+		return float64(len(pcm.samples)/pcm.channels) / float64(pcm.sampleRate), nil
+	}
+
+	frames, err := parseMP3Frames(audio)
+	if err != nil {
+		return 0, err
+	}
+	return framesDuration(frames), nil
+}
+
+// silentFrameHeader is an MPEG-1 Layer III frame header for 128 kbps, 44.1 kHz mono, the format
+// ElevenLabs renders; a 417-byte frame with zeroed side info decodes to 1152 samples of silence
+var silentFrameHeader = []byte{0xFF, 0xFB, 0x90, 0xC0}
+
+const silentFrameSize = 417
+
+// SilentMP3 returns an MP3 of silence lasting about seconds, for standing in for narration that
+// isn't rendered, such as in a dry run
+func SilentMP3(seconds float64) []byte {
+	frames := int(math.Ceil(seconds * 44100 / 1152))
+	audio := make([]byte, 0, frames*silentFrameSize)
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, silentFrameSize)
+		copy(frame, silentFrameHeader)
+		audio = append(audio, frame...)
+	}
+	return audio
+}
+
+// fadeFactor is the linear fade gain at time t of a clip lasting total seconds
+func fadeFactor(t, total, fadeIn, fadeOut float64) float64 {
+	factor := 1.0
+	if fadeIn > 0 && t < fadeIn {
+		factor = t / fadeIn
+	}
+	if fadeOut > 0 && t > total-fadeOut {
+		factor = math.Min(factor, (total-t)/fadeOut)
+	}
+	return math.Max(factor, 0)
+}
+
+func clampSample(value float64) int16 {
+	return int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(value))))
+}
+
+// MP3 global_gain changes the quantizer step by 2^(1/4) in amplitude
+const mp3GainStepsPerOctave = 4
+
+// mp3SilenceSteps is low enough to drive any global_gain to zero
+const mp3SilenceSteps = -255
+
+// gainSteps converts an amplitude factor to global_gain steps
+func gainSteps(factor float64) int {
+	if factor < 0.001 {
+		return mp3SilenceSteps
+	}
+	return int(math.Round(mp3GainStepsPerOctave * math.Log2(factor)))
+}
+
+// Layer III bitrates (kbps) by index, for MPEG-1 and MPEG-2/2.5
+var (
+	mp3BitratesV1  = [15]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesV2  = [15]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3SampleRates = [3]int{44100, 48000, 32000}
+)
+
+// mp3Frame is one Layer III frame, header included
+type mp3Frame struct {
+	data       []byte
+	mpeg1      bool
+	sampleRate int
+	channels   int
+	samples    int
+	crc        bool
+	isTag      bool // Xing/Info/VBRI header frame carrying no audio
+}
+
+// sideInfoLength is the size in bytes of the frame's side information
+func (f mp3Frame) sideInfoLength() int {
+	switch {
+	case f.mpeg1 && f.channels == 1:
+		return 17
+	case f.mpeg1:
+		return 32
+	case f.channels == 1:
+		return 9
+	default:
+		return 17
+	}
+}
+
+func (f mp3Frame) sideInfoOffset() int {
+	if f.crc {
+		return 6
+	}
+	return 4
+}
+
+// granuleBlocks returns the bit offset of each granule/channel block within the side info and the block size
+func (f mp3Frame) granuleBlocks() ([]int, int) {
+	var start, size, granules int
+	switch {
+	case f.mpeg1 && f.channels == 1:
+		start, size, granules = 18, 59, 2 // main_data_begin 9, private 5, scfsi 4
+	case f.mpeg1:
+		start, size, granules = 20, 59, 2 // main_data_begin 9, private 3, scfsi 8
+	case f.channels == 1:
+		start, size, granules = 9, 63, 1 // main_data_begin 8, private 1
+	default:
+		start, size, granules = 10, 63, 1 // main_data_begin 8, private 2
+	}
+
+	offsets := make([]int, 0, granules*f.channels)
+	for i := 0; i < granules*f.channels; i++ {
+		offsets = append(offsets, start+i*size)
+	}
+	return offsets, size
+}
+
+// withGain returns a copy with every granule's global_gain shifted by steps
+func (f mp3Frame) withGain(steps int) mp3Frame {
+	if steps == 0 || f.isTag {
+		return f
+	}
+
+	f.data = append([]byte(nil), f.data...)
+	base := f.sideInfoOffset() * 8
+	offsets, _ := f.granuleBlocks()
+	for _, offset := range offsets {
+		// part2_3_length (12) and big_values (9) precede global_gain
+		position := base + offset + 21
+		gain := readBits(f.data, position, 8) + steps
+		writeBits(f.data, position, 8, min(max(gain, 0), 255))
+	}
+	f.updateCRC()
+	return f
+}
+
+// silenced returns a copy that decodes to silence: no main data and no reservoir reference
+func (f mp3Frame) silenced() mp3Frame {
+	f.data = append([]byte(nil), f.data...)
+	base := f.sideInfoOffset() * 8
+
+	reservoirBits := 9
+	if !f.mpeg1 {
+		reservoirBits = 8
+	}
+	writeBits(f.data, base, reservoirBits, 0)
+
+	offsets, _ := f.granuleBlocks()
+	for _, offset := range offsets {
+		writeBits(f.data, base+offset, 12, 0) // part2_3_length
+	}
+	f.updateCRC()
+	return f
+}
+
+// updateCRC recomputes the CRC-16 over the header's last two bytes and the side info
+func (f mp3Frame) updateCRC() {
+	if !f.crc {
+		return
+	}
+	crc := uint16(0xFFFF)
+	for _, b := range append(append([]byte(nil), f.data[2:4]...), f.data[6:6+f.sideInfoLength()]...) {
+		for bit := 7; bit >= 0; bit-- {
+			carry := (crc>>15)&1 != uint16(b>>uint(bit))&1
+			crc <<= 1
+			if carry {
+				crc ^= 0x8005
+			}
+		}
+	}
+	binary.BigEndian.PutUint16(f.data[4:6], crc)
+}
+
+// parseMP3Frames splits an MP3 stream into Layer III frames, skipping ID3 tags and resyncing past junk
+func parseMP3Frames(data []byte) ([]mp3Frame, error) {
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		size += 10
+		if data[5]&0x10 != 0 {
+			size += 10 // Footer
+		}
+		if size > len(data) {
+			return nil, fmt.Errorf("truncated ID3 tag")
+		}
+		data = data[size:]
+	}
+	if len(data) >= 128 && string(data[len(data)-128:len(data)-125]) == "TAG" {
+		data = data[:len(data)-128]
+	}
+
+	var frames []mp3Frame
+	for i := 0; i+4 <= len(data); {
+		frame, length, ok := parseMP3Header(data[i:])
+		if !ok || i+length > len(data) {
+			i++
+			continue
+		}
+		frame.data = data[i : i+length]
+		frame.isTag = frame.hasInfoTag()
+		frames = append(frames, frame)
+		i += length
+	}
+
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no MP3 frames found")
+	}
+	return frames, nil
+}
+
+// parseMP3Header decodes a Layer III frame header, returning the frame and its length in bytes
+func parseMP3Header(header []byte) (mp3Frame, int, bool) {
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, 0, false
+	}
+
+	version := (header[1] >> 3) & 3 // 0 = MPEG-2.5, 2 = MPEG-2, 3 = MPEG-1
+	layer := (header[1] >> 1) & 3   // 1 = Layer III
+	bitrateIndex := int(header[2] >> 4)
+	rateIndex := int((header[2] >> 2) & 3)
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, 0, false
+	}
+
+	frame := mp3Frame{
+		mpeg1:    version == 3,
+		crc:      header[1]&1 == 0,
+		channels: 2,
+	}
+	if header[3]>>6 == 3 {
+		frame.channels = 1
+	}
+
+	padding := int((header[2] >> 1) & 1)
+	var length int
+	switch version {
+	case 3:
+		frame.sampleRate = mp3SampleRates[rateIndex]
+		frame.samples = 1152
+		length = 144000*mp3BitratesV1[bitrateIndex]/frame.sampleRate + padding
+	case 2:
+		frame.sampleRate = mp3SampleRates[rateIndex] / 2
+		frame.samples = 576
+		length = 72000*mp3BitratesV2[bitrateIndex]/frame.sampleRate + padding
+	default:
+		frame.sampleRate = mp3SampleRates[rateIndex] / 4
+		frame.samples = 576
+		length = 72000*mp3BitratesV2[bitrateIndex]/frame.sampleRate + padding
+	}
+
+	if length < frame.sideInfoOffset()+frame.sideInfoLength() {
+		return mp3Frame{}, 0, false
+	}
+	return frame, length, true
+}
+
+// hasInfoTag reports whether the frame is an encoder's Xing/Info or VBRI summary frame
+func (f mp3Frame) hasInfoTag() bool {
+	offset := f.sideInfoOffset() + f.sideInfoLength()
+	if offset+4 <= len(f.data) {
+		if tag := string(f.data[offset : offset+4]); tag == "Xing" || tag == "Info" {
+			return true
+		}
+	}
+	return len(f.data) >= 40 && string(f.data[36:40]) == "VBRI"
+}
+
+// audioFrames drops summary frames, whose frame counts are wrong once frames are added or removed
+func audioFrames(frames []mp3Frame) []mp3Frame {
+	var kept []mp3Frame
+	for _, frame := range frames {
+		if !frame.isTag {
+			kept = append(kept, frame)
+		}
+	}
+	return kept
+}
+
+func framesDuration(frames []mp3Frame) float64 {
+	total := 0.0
+	for _, frame := range frames {
+		if !frame.isTag {
+			total += float64(frame.samples) / float64(frame.sampleRate)
+		}
+	}
+	return total
+}
+
+func encodeMP3Frames(frames []mp3Frame) []byte {
+	var buf bytes.Buffer
+	for _, frame := range frames {
+		buf.Write(frame.data)
+	}
+	return buf.Bytes()
+}
+
+func readBits(data []byte, offset, count int) int {
+	value := 0
+	for i := offset; i < offset+count; i++ {
+		value = value<<1 | int(data[i/8]>>(7-uint(i%8))&1)
+	}
+	return value
+}
+
+func writeBits(data []byte, offset, count, value int) {
+	for i := 0; i < count; i++ {
+		position := offset + i
+		shift := 7 - uint(position%8)
+		bit := byte(value>>(count-1-i)) & 1
+		data[position/8] = data[position/8]&^(1<<shift) | bit<<shift
+	}
+}
+
+// pcmAudio is interleaved 16-bit PCM from a WAV file
+type pcmAudio struct {
+	sampleRate int
+	channels   int
+	samples    []int16
+}
+
+func isWAV(data []byte) bool {
+	return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+}
+
+// parseWAV reads the fmt and data chunks of a 16-bit PCM WAV file
+func parseWAV(data []byte) (*pcmAudio, error) {
+	pcm := &pcmAudio{}
+	var pcmData []byte
+
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+		if size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			if format := binary.LittleEndian.Uint16(body[0:2]); format != 1 {
+				return nil, fmt.Errorf("unsupported WAV format %d (only PCM)", format)
+			}
+			if bits := binary.LittleEndian.Uint16(body[14:16]); bits != 16 {
+				return nil, fmt.Errorf("unsupported WAV bit depth %d (only 16-bit)", bits)
+			}
+			pcm.channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			pcm.sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+		case "data":
+			pcmData = body
+		}
+
+		offset += 8 + size + size%2 // Chunks are word aligned
+	}
+
+	if pcm.channels == 0 || pcm.sampleRate == 0 {
+		return nil, fmt.Errorf("WAV file has no fmt chunk")
+	}
+
+	pcm.samples = make([]int16, len(pcmData)/2)
+	for i := range pcm.samples {
+		pcm.samples[i] = int16(binary.LittleEndian.Uint16(pcmData[2*i:]))
+	}
+	return pcm, nil
+}
+
+// encode writes a canonical 44-byte-header WAV file
+func (p *pcmAudio) encode() []byte {
+	dataSize := len(p.samples) * 2
+	buf := make([]byte, 44+dataSize)
+
+	copy(buf[0:], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:], uint32(36+dataSize))
+	copy(buf[8:], "WAVE")
+	copy(buf[12:], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:], 16)
+	binary.LittleEndian.PutUint16(buf[20:], 1)
+	binary.LittleEndian.PutUint16(buf[22:], uint16(p.channels))
+	binary.LittleEndian.PutUint32(buf[24:], uint32(p.sampleRate))
+	binary.LittleEndian.PutUint32(buf[28:], uint32(p.sampleRate*p.channels*2))
+	binary.LittleEndian.PutUint16(buf[32:], uint16(p.channels*2))
+	binary.LittleEndian.PutUint16(buf[34:], 16)
+	copy(buf[36:], "data")
+	binary.LittleEndian.PutUint32(buf[40:], uint32(dataSize))
+
+	for i, sample := range p.samples {
+		binary.LittleEndian.PutUint16(buf[44+2*i:], uint16(sample))
+	}
+	return buf
+}
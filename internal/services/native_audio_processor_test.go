@@ -0,0 +1,283 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// MPEG-1 Layer III, 128 kbps, 44.1 kHz mono: 417-byte frames of 1152 samples, side info at bit
+// 32 with each granule's block at bit 18 and 77
+const (
+	testFrameSeconds = 1152.0 / 44100
+	testSideInfoBit  = 32
+)
+
+var testGranuleBits = []int{18, 77}
+
+// testFrame builds a fixture frame whose granules have global_gain gain and some main data
+func testFrame(gain int) []byte {
+	frame := make([]byte, silentFrameSize)
+	copy(frame, silentFrameHeader)
+	writeBits(frame, testSideInfoBit, 9, 5) // main_data_begin
+	for _, block := range testGranuleBits {
+		writeBits(frame, testSideInfoBit+block, 12, 100) // part2_3_length
+		writeBits(frame, testSideInfoBit+block+21, 8, gain)
+	}
+	return frame
+}
+
+// testClip joins fixture frames, the nth with global_gain gains[n]
+func testClip(gains ...int) []byte {
+	var clip []byte
+	for _, gain := range gains {
+		clip = append(clip, testFrame(gain)...)
+	}
+	return clip
+}
+
+// frameGains reads the first granule's global_gain of every frame in clip
+func frameGains(t *testing.T, clip []byte) []int {
+	t.Helper()
+	frames, err := parseMP3Frames(clip)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var gains []int
+	for _, frame := range frames {
+		gains = append(gains, readBits(frame.data, testSideInfoBit+testGranuleBits[0]+21, 8))
+	}
+	return gains
+}
+
+func isSilenced(frame []byte) bool {
+	if readBits(frame, testSideInfoBit, 9) != 0 {
+		return false
+	}
+	for _, block := range testGranuleBits {
+		if readBits(frame, testSideInfoBit+block, 12) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseMP3FramesFindsFrameBoundaries(t *testing.T) {
+	id3 := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 6, 1, 2, 3, 4, 5, 6}
+	padded := testFrame(30)
+	padded[2] |= 0x02 // Padding bit: one byte longer
+	padded = append(padded, 0)
+	id3v1 := append([]byte("TAG"), make([]byte, 125)...)
+
+	var clip []byte
+	clip = append(clip, id3...)
+	clip = append(clip, 0xFF, 0x00, 0x42) // Junk before the first frame
+	clip = append(clip, testFrame(10)...)
+	clip = append(clip, padded...)
+	clip = append(clip, testFrame(50)...)
+	clip = append(clip, id3v1...)
+
+	frames, err := parseMP3Frames(clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("found %d frames, want 3", len(frames))
+	}
+	for i, want := range []int{417, 418, 417} {
+		if got := len(frames[i].data); got != want {
+			t.Errorf("frame %d is %d bytes, want %d", i, got, want)
+		}
+	}
+	if got := frameGains(t, clip); !equalInts(got, []int{10, 30, 50}) {
+		t.Errorf("frame gains = %v, want [10 30 50]", got)
+	}
+
+	duration, err := (&NativeAudioProcessor{}).Duration(clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3 * testFrameSeconds; math.Abs(duration-want) > 1e-9 {
+		t.Errorf("duration = %f, want %f", duration, want)
+	}
+}
+
+func TestParseMP3FramesRejectsNonAudio(t *testing.T) {
+	if _, err := parseMP3Frames([]byte("not an mp3 at all")); err == nil {
+		t.Error("parsed frames from text")
+	}
+	if _, err := parseMP3Frames([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 1, 0}); err == nil {
+		t.Error("parsed a truncated ID3 tag")
+	}
+}
+
+func TestMP3GainClampsGlobalGain(t *testing.T) {
+	np := &NativeAudioProcessor{}
+	clip := testClip(250, 100, 3)
+
+	tests := []struct {
+		name   string
+		factor float64
+		want   []int
+	}{
+		{"unchanged", 1, []int{250, 100, 3}},
+		{"louder clamps at 255", 4, []int{255, 108, 11}},
+		{"quieter clamps at 0", 0.25, []int{242, 92, 0}},
+		{"muted", 0, []int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := np.Gain(clip, tt.factor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := frameGains(t, out); !equalInts(got, tt.want) {
+				t.Errorf("gains = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := frameGains(t, clip); !equalInts(got, []int{250, 100, 3}) {
+		t.Errorf("Gain modified its input: %v", got)
+	}
+}
+
+func TestMP3TrimCutsOnFrameBoundaries(t *testing.T) {
+	np := &NativeAudioProcessor{}
+	clip := testClip(10, 11, 12, 13, 14, 15)
+
+	// Frames whose middle falls in [2.4, 5.1) frames are kept
+	out, err := np.Trim(clip, 2.4*testFrameSeconds, 2.7*testFrameSeconds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := frameGains(t, out); !equalInts(got, []int{12, 13, 14}) {
+		t.Fatalf("kept gains %v, want [12 13 14]", got)
+	}
+	if !isSilenced(out[:silentFrameSize]) {
+		t.Error("first kept frame still references the cut frames' bit reservoir")
+	}
+	if isSilenced(out[silentFrameSize : 2*silentFrameSize]) {
+		t.Error("second kept frame was silenced")
+	}
+
+	fromStart, err := np.Trim(clip, 0, 2*testFrameSeconds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fromStart, clip[:2*silentFrameSize]) {
+		t.Error("trimming from the start changed the kept frames")
+	}
+}
+
+func TestMP3ConcatDropsInfoFramesAndSilencesJoins(t *testing.T) {
+	np := &NativeAudioProcessor{}
+	info := testFrame(0)
+	copy(info[4+17:], "Info")
+
+	first := append(append([]byte(nil), info...), testClip(20, 21)...)
+	second := append(append([]byte(nil), info...), testClip(30, 31)...)
+
+	out, err := np.Concat(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := frameGains(t, out); !equalInts(got, []int{20, 21, 30, 31}) {
+		t.Fatalf("joined gains %v, want [20 21 30 31]", got)
+	}
+	if isSilenced(out[:silentFrameSize]) {
+		t.Error("the first clip's first frame was silenced")
+	}
+	if !isSilenced(out[2*silentFrameSize : 3*silentFrameSize]) {
+		t.Error("the second clip's first frame still references the first clip's reservoir")
+	}
+
+	stereo := testFrame(40)
+	stereo[3] = 0x00
+	if _, err := np.Concat(testClip(1), stereo); err == nil {
+		t.Error("joined mono and stereo clips")
+	}
+}
+
+func TestSilentMP3Duration(t *testing.T) {
+	duration, err := (&NativeAudioProcessor{}).Duration(SilentMP3(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duration < 1 || duration > 1+testFrameSeconds {
+		t.Errorf("duration = %f, want about 1 second", duration)
+	}
+}
+
+// testWAV builds a mono 16-bit WAV at 1 kHz
+func testWAV(samples ...int16) []byte {
+	return (&pcmAudio{sampleRate: 1000, channels: 1, samples: samples}).encode()
+}
+
+func wavSamples(t *testing.T, wav []byte) []int16 {
+	t.Helper()
+	pcm, err := parseWAV(wav)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return pcm.samples
+}
+
+func TestWAVGainClampsSamples(t *testing.T) {
+	out, err := (&NativeAudioProcessor{}).Gain(testWAV(20000, -20000, 100), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := wavSamples(t, out)
+	want := []int16{math.MaxInt16, math.MinInt16, 200}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWAVTrim(t *testing.T) {
+	np := &NativeAudioProcessor{}
+	wav := testWAV(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+
+	out, err := np.Trim(wav, 0.003, 0.004)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := wavSamples(t, out); len(got) != 4 || got[0] != 3 || got[3] != 6 {
+		t.Errorf("trimmed samples = %v, want [3 4 5 6]", got)
+	}
+
+	past, err := np.Trim(wav, 0.008, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := wavSamples(t, past); len(got) != 2 {
+		t.Errorf("trimming past the end kept %v, want [8 9]", got)
+	}
+
+	duration, err := np.Duration(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(duration-0.01) > 1e-9 {
+		t.Errorf("duration = %f, want 0.01", duration)
+	}
+	if size := binary.LittleEndian.Uint32(out[40:44]); size != 8 {
+		t.Errorf("data chunk is %d bytes, want 8", size)
+	}
+}
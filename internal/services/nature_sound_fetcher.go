@@ -4,28 +4,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math/rand"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+	"github.com/callen/bird-song-explorer/pkg/randx"
 )
 
-// NatureSoundFetcher fetches ambient nature sounds from Xeno-canto
+// NatureSoundFetcher serves nature sounds from the shared library, fetching a category's sound
+// from Xeno-canto when the library has none
 type NatureSoundFetcher struct {
-	cacheDir string
-	client   *http.Client
+	library *NatureSoundLibrary
+	client  *http.Client
+	rng     *randx.Randomizer // Picks among the good recordings; nil picks with a time seed
 }
 
 // NewNatureSoundFetcher creates a new nature sound fetcher
-func NewNatureSoundFetcher() *NatureSoundFetcher {
+func NewNatureSoundFetcher(rng *randx.Randomizer) *NatureSoundFetcher {
 	return &NatureSoundFetcher{
-		cacheDir: "audio_cache/nature_sounds",
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		library: SharedNatureSoundLibrary(),
+		client:  httpx.NewClient(httpx.Options{Timeout: 30 * time.Second}),
+		rng:     rng,
 	}
 }
 
@@ -49,32 +51,30 @@ type XenoCantoNatureRecording struct {
 	Also     interface{} `json:"also"`      // Other species in recording (can be string or array)
 	Rmk      string      `json:"rmk"`       // Remarks
 	Q        string      `json:"q"`         // Quality rating
+	URL      string      `json:"url"`       // Recording page
+	License  string      `json:"lic"`       // Creative Commons license URL
 }
 
-// GetNatureSoundByType fetches nature sounds based on type
+// GetNatureSoundByType returns the library's sound for a category, fetching and recording one
+// from Xeno-canto when the library has none
 func (nsf *NatureSoundFetcher) GetNatureSoundByType(soundType string) ([]byte, error) {
-	// Create cache directory if it doesn't exist
-	if err := os.MkdirAll(nsf.cacheDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
-	}
-
-	// Check cache first
-	cacheFile := filepath.Join(nsf.cacheDir, fmt.Sprintf("%s.mp3", soundType))
-	if data, err := nsf.checkCache(cacheFile); err == nil {
-		fmt.Printf("[NATURE_FETCHER] Using cached nature sound: %s\n", soundType)
-		return data, nil
+	if sound, ok := nsf.library.Sound(soundType); ok {
+		if data, err := nsf.library.Read(sound); err == nil {
+			slog.Info("[NATURE_FETCHER] Using library nature sound", "type", soundType, "sound", sound.ID, "license", sound.License)
+			return data, nil
+		}
 	}
 
 	// Map sound types to search queries
 	queries := nsf.getSoundTypeQuery(soundType)
 
-	fmt.Printf("[NATURE_FETCHER] Fetching nature sounds for type: %s\n", soundType)
+	slog.Info("[NATURE_FETCHER] Fetching nature sounds", "type", soundType)
 
 	// Try each query until we find suitable recordings
 	for _, query := range queries {
 		recordings, err := nsf.searchXenoCanto(query)
 		if err != nil {
-			fmt.Printf("[NATURE_FETCHER] Error searching for %s: %v\n", query, err)
+			slog.Warn("[NATURE_FETCHER] Search failed", "query", query, "error", err)
 			continue
 		}
 
@@ -85,15 +85,17 @@ func (nsf *NatureSoundFetcher) GetNatureSoundByType(soundType string) ([]byte, e
 				// Download the audio
 				audioData, err := nsf.downloadAudio(selected.File)
 				if err != nil {
-					fmt.Printf("[NATURE_FETCHER] Error downloading audio: %v\n", err)
+					slog.Warn("[NATURE_FETCHER] Failed to download audio", "error", err)
 					continue
 				}
 
-				// Cache the audio
-				os.WriteFile(cacheFile, audioData, 0644)
+				// Record it in the library with its license, so it's credited and reused
+				sound := natureSoundFromXenoCanto(soundType, *selected)
+				if err := nsf.library.Add(sound, audioData); err != nil {
+					slog.Warn("[NATURE_FETCHER] Failed to add nature sound to the library", "sound", sound.ID, "error", err)
+				}
 
-				fmt.Printf("[NATURE_FETCHER] Successfully fetched nature sound: %s (%s)\n",
-					soundType, selected.En)
+				slog.Info("[NATURE_FETCHER] Fetched nature sound", "type", soundType, "recording", selected.En, "license", sound.License)
 				return audioData, nil
 			}
 		}
@@ -102,67 +104,15 @@ func (nsf *NatureSoundFetcher) GetNatureSoundByType(soundType string) ([]byte, e
 	return nil, fmt.Errorf("no suitable nature sounds found for type: %s", soundType)
 }
 
-// getSoundTypeQuery maps sound types to Xeno-canto search queries
+// getSoundTypeQuery returns the Xeno-canto searches for a category, or general soundscapes for
+// types that aren't a category
 func (nsf *NatureSoundFetcher) getSoundTypeQuery(soundType string) []string {
-	switch soundType {
-	case "forest":
-		// Forest ambience - look for dawn chorus or forest recordings
-		return []string{
-			"type:dawn chorus",
-			"type:soundscape forest",
-			"rmk:ambient forest",
-		}
-	case "morning_birds":
-		// Dawn chorus
-		return []string{
-			"type:dawn chorus",
-			"time:05-08",
-			"rmk:morning chorus",
-		}
-	case "gentle_rain":
-		// Rain sounds - look for recordings with rain in remarks
-		return []string{
-			"rmk:rain",
-			"rmk:light rain",
-			"rmk:drizzle",
-		}
-	case "wind_trees":
-		// Wind sounds
-		return []string{
-			"rmk:wind",
-			"rmk:windy",
-			"rmk:breeze",
-		}
-	case "stream":
-		// Water sounds
-		return []string{
-			"rmk:stream",
-			"rmk:creek",
-			"rmk:water",
-			"rmk:river",
-		}
-	case "meadow":
-		// Open field sounds - insects and distant birds
-		return []string{
-			"type:soundscape meadow",
-			"rmk:grassland",
-			"rmk:field",
-			"rmk:meadow",
-		}
-	case "night":
-		// Night sounds - owls, crickets
-		return []string{
-			"type:nocturnal",
-			"time:20-04",
-			"rmk:night",
-			"gen:Strix", // Owls
-		}
-	default:
-		// Default to general soundscapes
-		return []string{
-			"type:soundscape",
-			"type:dawn chorus",
-		}
+	if category := NatureSoundCategoryByID(soundType); category != nil {
+		return category.Queries
+	}
+	return []string{
+		"type:soundscape",
+		"type:dawn chorus",
 	}
 }
 
@@ -219,18 +169,25 @@ func (nsf *NatureSoundFetcher) selectBestRecording(recordings []XenoCantoNatureR
 	}
 
 	// Select randomly from high quality recordings
-	rand.Seed(time.Now().UnixNano())
-	selected := highQuality[rand.Intn(len(highQuality))]
+	selected := highQuality[nsf.rng.Intn(len(highQuality))]
 
 	return &selected
 }
 
 // isDurationSufficient checks if the recording is long enough
 func (nsf *NatureSoundFetcher) isDurationSufficient(duration string) bool {
-	// Duration is in format "0:30" or "1:45"
+	seconds, ok := parseRecordingLength(duration)
+	if !ok {
+		return true // Can't parse, assume it's okay
+	}
+	return seconds >= 20 // At least 20 seconds
+}
+
+// parseRecordingLength reads a Xeno-canto length ("0:30" or "1:45") as seconds
+func parseRecordingLength(duration string) (float64, bool) {
 	parts := strings.Split(duration, ":")
 	if len(parts) != 2 {
-		return true // Can't parse, assume it's okay
+		return 0, false
 	}
 
 	// Convert to seconds
@@ -239,17 +196,13 @@ func (nsf *NatureSoundFetcher) isDurationSufficient(duration string) bool {
 	fmt.Sscanf(parts[0], "%d", &minutes)
 	fmt.Sscanf(parts[1], "%d", &seconds)
 
-	totalSeconds := minutes*60 + seconds
-	return totalSeconds >= 20 // At least 20 seconds
+	return float64(minutes*60 + seconds), true
 }
 
 // downloadAudio downloads the audio file
 func (nsf *NatureSoundFetcher) downloadAudio(audioURL string) ([]byte, error) {
-	// Xeno-canto URLs need to be modified
-	// They provide URLs like "//www.xeno-canto.org/sounds/..."
-	if strings.HasPrefix(audioURL, "//") {
-		audioURL = "https:" + audioURL
-	}
+	// Xeno-canto provides URLs like "//www.xeno-canto.org/sounds/..."
+	audioURL = httpsURL(audioURL)
 
 	resp, err := nsf.client.Get(audioURL)
 	if err != nil {
@@ -265,21 +218,6 @@ func (nsf *NatureSoundFetcher) downloadAudio(audioURL string) ([]byte, error) {
 	return data, nil
 }
 
-// checkCache checks if a cached version exists and is recent
-func (nsf *NatureSoundFetcher) checkCache(cacheFile string) ([]byte, error) {
-	info, err := os.Stat(cacheFile)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use cache if less than 7 days old
-	if time.Since(info.ModTime()) > 7*24*time.Hour {
-		return nil, fmt.Errorf("cache expired")
-	}
-
-	return os.ReadFile(cacheFile)
-}
-
 // GetAmbientSoundscape fetches a general ambient soundscape
 func (nsf *NatureSoundFetcher) GetAmbientSoundscape() ([]byte, error) {
 	// Based on time of day, select appropriate soundscape
@@ -288,13 +226,13 @@ func (nsf *NatureSoundFetcher) GetAmbientSoundscape() ([]byte, error) {
 	var soundType string
 	switch {
 	case hour >= 5 && hour < 9:
-		soundType = "morning_birds"
+		soundType = NatureMorningBirds
 	case hour >= 9 && hour < 17:
-		soundType = "forest"
+		soundType = NatureForest
 	case hour >= 17 && hour < 20:
-		soundType = "meadow"
+		soundType = NatureMeadow
 	default:
-		soundType = "night"
+		soundType = NatureNight
 	}
 
 	return nsf.GetNatureSoundByType(soundType)
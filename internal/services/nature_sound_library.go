@@ -0,0 +1,338 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Nature sound categories the intro mixer can play under the voice
+const (
+	NatureForest       = "forest"
+	NatureMorningBirds = "morning_birds"
+	NatureGentleRain   = "gentle_rain"
+	NatureWindTrees    = "wind_trees"
+	NatureStream       = "stream"
+	NatureMeadow       = "meadow"
+	NatureNight        = "night"
+	NatureOcean        = "ocean"
+	NatureDesert       = "desert"
+	NatureRainforest   = "rainforest"
+	NatureSnowstorm    = "snowstorm"
+)
+
+const (
+	natureSoundLibraryDir = "audio_cache/nature_sounds"
+	natureSoundManifest   = "library.json"
+
+	// Sounds fetched from Xeno-canto are replaced weekly so intros don't always share one clip;
+	// sounds added to the manifest by hand are kept
+	natureSoundMaxAge = 7 * 24 * time.Hour
+
+	// A soundscape this long is treated as loopable: the few intros longer than it are
+	// under a minute, and the mixer's fade hides the seam
+	natureSoundLoopableSeconds = 60
+
+	natureSoundSourceXenoCanto = "xeno-canto"
+)
+
+// NatureSoundCategory is a kind of ambience and the Xeno-canto searches that find it
+type NatureSoundCategory struct {
+	ID      string
+	Name    string
+	Queries []string // Tried in order until one finds a usable recording
+}
+
+var natureSoundCategories = []NatureSoundCategory{
+	{ID: NatureForest, Name: "Forest", Queries: []string{"type:dawn chorus", "type:soundscape forest", "rmk:ambient forest"}},
+	{ID: NatureMorningBirds, Name: "Morning birds", Queries: []string{"type:dawn chorus", "time:05-08", "rmk:morning chorus"}},
+	{ID: NatureGentleRain, Name: "Gentle rain", Queries: []string{"rmk:rain", "rmk:light rain", "rmk:drizzle"}},
+	{ID: NatureWindTrees, Name: "Wind in the trees", Queries: []string{"rmk:wind", "rmk:windy", "rmk:breeze"}},
+	{ID: NatureStream, Name: "Stream", Queries: []string{"rmk:stream", "rmk:creek", "rmk:water", "rmk:river"}},
+	{ID: NatureMeadow, Name: "Meadow", Queries: []string{"type:soundscape meadow", "rmk:grassland", "rmk:field", "rmk:meadow"}},
+	{ID: NatureNight, Name: "Night", Queries: []string{"type:nocturnal", "time:20-04", "rmk:night", "gen:Strix"}},
+	{ID: NatureOcean, Name: "Ocean", Queries: []string{"rmk:surf", "rmk:waves", "rmk:ocean", "rmk:seashore"}},
+	{ID: NatureDesert, Name: "Desert", Queries: []string{"type:soundscape desert", "rmk:desert", "rmk:dunes"}},
+	{ID: NatureRainforest, Name: "Rainforest", Queries: []string{"type:soundscape rainforest", "rmk:rainforest", "rmk:jungle"}},
+	{ID: NatureSnowstorm, Name: "Snowstorm", Queries: []string{"rmk:blizzard", "rmk:snowstorm", "rmk:snow wind"}},
+}
+
+// NatureSoundCategories returns the nature sound categories
+func NatureSoundCategories() []NatureSoundCategory {
+	return natureSoundCategories
+}
+
+// NatureSoundCategoryByID returns the category with the given ID, or nil
+func NatureSoundCategoryByID(id string) *NatureSoundCategory {
+	for i := range natureSoundCategories {
+		if natureSoundCategories[i].ID == id {
+			return &natureSoundCategories[i]
+		}
+	}
+	return nil
+}
+
+// NatureSound is one ambience recording in the library, with what's needed to credit it
+type NatureSound struct {
+	ID              string    `json:"id"`
+	Category        string    `json:"category"`
+	File            string    `json:"file"`                 // Path relative to the library directory
+	Source          string    `json:"source"`               // "xeno-canto", or wherever a hand-added sound came from
+	SourceURL       string    `json:"source_url,omitempty"` // Page for the recording
+	License         string    `json:"license"`              // e.g. "CC BY-NC-SA 4.0"
+	Attribution     string    `json:"attribution"`          // Credit line for the recordist
+	DurationSeconds float64   `json:"duration_seconds"`
+	Loopable        bool      `json:"loopable"` // Can be looped under a long intro without an obvious seam
+	FetchedAt       time.Time `json:"fetched_at,omitempty"`
+}
+
+// NatureSoundLibrary is the managed set of nature sounds, stored in a directory with a manifest
+// recording each sound's source, license, and attribution
+type NatureSoundLibrary struct {
+	dir string
+
+	mu     sync.Mutex
+	Sounds []NatureSound `json:"sounds"`
+}
+
+var (
+	sharedNatureSounds     *NatureSoundLibrary
+	sharedNatureSoundsOnce sync.Once
+)
+
+// SharedNatureSoundLibrary returns the process-wide library, so every mixer records into one
+// manifest
+func SharedNatureSoundLibrary() *NatureSoundLibrary {
+	sharedNatureSoundsOnce.Do(func() {
+		library, err := LoadNatureSoundLibrary(natureSoundLibraryDir)
+		if err != nil {
+			slog.Warn("[NATURE_LIBRARY] Failed to load the library, starting an empty one", "error", err)
+			library = &NatureSoundLibrary{dir: natureSoundLibraryDir}
+		}
+		sharedNatureSounds = library
+	})
+	return sharedNatureSounds
+}
+
+// LoadNatureSoundLibrary reads the library in dir, returning an empty one if it has no manifest yet
+func LoadNatureSoundLibrary(dir string) (*NatureSoundLibrary, error) {
+	library := &NatureSoundLibrary{dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, natureSoundManifest))
+	if os.IsNotExist(err) {
+		return library, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nature sound manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, library); err != nil {
+		return nil, fmt.Errorf("failed to parse nature sound manifest: %w", err)
+	}
+	return library, nil
+}
+
+// Sound returns the category's best usable sound: a loopable one if there is one, then the
+// longest. Xeno-canto sounds past their refresh age and sounds whose file is missing are skipped.
+func (l *NatureSoundLibrary) Sound(category string) (NatureSound, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var candidates []NatureSound
+	for _, sound := range l.Sounds {
+		if sound.Category != category || l.expired(sound) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(l.dir, sound.File)); err != nil {
+			continue
+		}
+		candidates = append(candidates, sound)
+	}
+	if len(candidates) == 0 {
+		return NatureSound{}, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Loopable != candidates[j].Loopable {
+			return candidates[i].Loopable
+		}
+		return candidates[i].DurationSeconds > candidates[j].DurationSeconds
+	})
+	return candidates[0], true
+}
+
+// Read returns a sound's audio
+func (l *NatureSoundLibrary) Read(sound NatureSound) ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, sound.File))
+}
+
+// Add stores a fetched sound and records it in the manifest, replacing the category's expired
+// Xeno-canto sounds
+func (l *NatureSoundLibrary) Add(sound NatureSound, audio []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create nature sound directory: %w", err)
+	}
+	if sound.File == "" {
+		sound.File = fmt.Sprintf("%s_%s.mp3", sound.Category, sound.ID)
+	}
+	if err := os.WriteFile(filepath.Join(l.dir, sound.File), audio, 0644); err != nil {
+		return fmt.Errorf("failed to write nature sound: %w", err)
+	}
+
+	kept := l.Sounds[:0]
+	for _, existing := range l.Sounds {
+		if existing.File == sound.File {
+			continue
+		}
+		if existing.Category == sound.Category && l.expired(existing) {
+			os.Remove(filepath.Join(l.dir, existing.File))
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	l.Sounds = append(kept, sound)
+
+	return l.save()
+}
+
+// expired reports whether a fetched sound is due to be replaced
+func (l *NatureSoundLibrary) expired(sound NatureSound) bool {
+	return sound.Source == natureSoundSourceXenoCanto && time.Since(sound.FetchedAt) > natureSoundMaxAge
+}
+
+// save writes the manifest; the caller holds l.mu
+func (l *NatureSoundLibrary) save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode nature sound manifest: %w", err)
+	}
+	return os.WriteFile(filepath.Join(l.dir, natureSoundManifest), data, 0644)
+}
+
+// natureSoundFromXenoCanto describes a Xeno-canto recording as a library sound
+func natureSoundFromXenoCanto(category string, recording XenoCantoNatureRecording) NatureSound {
+	seconds, _ := parseRecordingLength(recording.Length)
+	return NatureSound{
+		ID:              "xc" + recording.ID,
+		Category:        category,
+		Source:          natureSoundSourceXenoCanto,
+		SourceURL:       httpsURL(recording.URL),
+		License:         xenoCantoLicense(recording.License),
+		Attribution:     fmt.Sprintf("%s, XC%s (xeno-canto.org)", recording.Rec, recording.ID),
+		DurationSeconds: seconds,
+		Loopable:        seconds >= natureSoundLoopableSeconds,
+		FetchedAt:       time.Now().UTC(),
+	}
+}
+
+// xenoCantoLicense names a Creative Commons license from its URL
+// ("//creativecommons.org/licenses/by-nc-sa/4.0/" is "CC BY-NC-SA 4.0")
+func xenoCantoLicense(licenseURL string) string {
+	parts := strings.Split(strings.Trim(licenseURL, "/"), "/")
+	for i, part := range parts {
+		if part == "licenses" && i+2 < len(parts) {
+			return fmt.Sprintf("CC %s %s", strings.ToUpper(parts[i+1]), parts[i+2])
+		}
+	}
+	return httpsURL(licenseURL)
+}
+
+// httpsURL completes Xeno-canto's scheme-relative URLs ("//xeno-canto.org/123")
+func httpsURL(link string) string {
+	if strings.HasPrefix(link, "//") {
+		return "https:" + link
+	}
+	return link
+}
+
+// natureBiome is a rough box around a region whose ambience is distinctive enough to play
+// instead of the time-of-day sound
+type natureBiome struct {
+	name           string
+	category       string
+	minLat, maxLat float64
+	minLng, maxLng float64
+}
+
+// natureBiomes are checked in order; places outside every box get the time-of-day sound
+var natureBiomes = []natureBiome{
+	{name: "Amazon", category: NatureRainforest, minLat: -15, maxLat: 5, minLng: -75, maxLng: -47},
+	{name: "Congo Basin", category: NatureRainforest, minLat: -5, maxLat: 5, minLng: 10, maxLng: 30},
+	{name: "Southeast Asia", category: NatureRainforest, minLat: -10, maxLat: 10, minLng: 95, maxLng: 150},
+	{name: "Central America", category: NatureRainforest, minLat: 7, maxLat: 18, minLng: -92, maxLng: -77},
+	{name: "Queensland Wet Tropics", category: NatureRainforest, minLat: -19, maxLat: -15, minLng: 144, maxLng: 147},
+	{name: "Sahara", category: NatureDesert, minLat: 16, maxLat: 32, minLng: -17, maxLng: 33},
+	{name: "Arabian Desert", category: NatureDesert, minLat: 15, maxLat: 32, minLng: 35, maxLng: 60},
+	{name: "North American deserts", category: NatureDesert, minLat: 25, maxLat: 37.5, minLng: -117, maxLng: -104},
+	{name: "Atacama", category: NatureDesert, minLat: -30, maxLat: -18, minLng: -71.5, maxLng: -68},
+	{name: "Australian Outback", category: NatureDesert, minLat: -31, maxLat: -20, minLng: 120, maxLng: 142},
+	{name: "Gobi", category: NatureDesert, minLat: 37, maxLat: 46, minLng: 77, maxLng: 112},
+	{name: "Kalahari and Namib", category: NatureDesert, minLat: -28, maxLat: -17, minLng: 12, maxLng: 25},
+	{name: "Hawaii", category: NatureOcean, minLat: 18.5, maxLat: 22.5, minLng: -160.5, maxLng: -154.5},
+	{name: "Caribbean islands", category: NatureOcean, minLat: 10, maxLat: 19, minLng: -75, maxLng: -59},
+	{name: "Canary Islands", category: NatureOcean, minLat: 27.5, maxLat: 29.5, minLng: -18.5, maxLng: -13},
+	{name: "Fiji", category: NatureOcean, minLat: -21, maxLat: -15, minLng: 176, maxLng: 180},
+}
+
+// snowstormLatitude is how far from the equator winter intros get a snowstorm
+const snowstormLatitude = 55
+
+// NatureSoundForPlace picks the nature sound for a listener: night sounds at night, a snowstorm
+// in a high-latitude winter, the biome's ambience in a desert, rainforest, or on an island, and
+// otherwise the sound for the time of day. Zero coordinates mean the location is unknown.
+func NatureSoundForPlace(latitude, longitude float64, localNow time.Time) string {
+	byHour := natureSoundForHour(localNow.Hour())
+	if byHour == NatureNight || (latitude == 0 && longitude == 0) {
+		return byHour
+	}
+
+	if isLocalWinter(latitude, localNow.Month()) && (latitude >= snowstormLatitude || latitude <= -snowstormLatitude) {
+		return NatureSnowstorm
+	}
+	for _, biome := range natureBiomes {
+		if latitude >= biome.minLat && latitude <= biome.maxLat && longitude >= biome.minLng && longitude <= biome.maxLng {
+			return biome.category
+		}
+	}
+	return byHour
+}
+
+// isLocalWinter reports whether month is winter in the latitude's hemisphere
+func isLocalWinter(latitude float64, month time.Month) bool {
+	if latitude < 0 {
+		return month >= time.June && month <= time.August
+	}
+	return month == time.December || month <= time.February
+}
+
+// natureSoundForHour is the nature sound for a local hour
+func natureSoundForHour(hour int) string {
+	switch {
+	case hour >= 5 && hour < 9:
+		// Early morning (5am-9am)
+		return NatureMorningBirds
+	case hour >= 9 && hour < 12:
+		// Late morning (9am-12pm)
+		return NatureForest
+	case hour >= 12 && hour < 17:
+		// Afternoon (12pm-5pm)
+		return NatureMeadow
+	case hour >= 17 && hour < 20:
+		// Evening (5pm-8pm)
+		return NatureGentleRain
+	case hour >= 20 && hour < 22:
+		// Late evening (8pm-10pm)
+		return NatureStream
+	default:
+		// Night (10pm-5am)
+		return NatureNight
+	}
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
+
+const (
+	// Outros remembered per card; a card forgets its oldest outros beyond this
+	outroHistoryPerCard   = 200
+	outroContentMaxCached = 50
+)
+
+// OutroItem is one joke, teaser, wisdom quote, challenge, or fun fact in the rotation
+type OutroItem struct {
+	ID   string `json:"id"`   // Outro type and a hash of the text, so editing the banks doesn't reshuffle history
+	Kind string `json:"kind"` // One of StaticOutroTypes
+	Text string `json:"text"`
+}
+
+// Script is the narration for the item, wrapped in its outro type's sign-off
+func (item OutroItem) Script(birdName string) string {
+	switch item.Kind {
+	case "joke":
+		return fmt.Sprintf("Here's today's giggle before you go! %s <break time=\"1.0s\" /> See you tomorrow for another amazing bird adventure, explorers!", item.Text)
+	case "wisdom":
+		return fmt.Sprintf("Remember, little explorers: %s <break time=\"1.0s\" /> Think of our %s friend today and remember to spread your wings! Until tomorrow!", item.Text, birdName)
+	case "challenge":
+		return fmt.Sprintf("Your Bird Explorer Challenge: %s <break time=\"1.0s\" /> Tomorrow, we'll learn about a new bird together. Happy exploring!", item.Text)
+	case "funfact":
+		return fmt.Sprintf("Before you go, did you know? %s <break time=\"1.0s\" /> Amazing, right? Sweet dreams, and tomorrow we'll discover another incredible bird together!", item.Text)
+	default:
+		return item.Text
+	}
+}
+
+// OutroContent is a card's outro for one day
+type OutroContent struct {
+	Item   OutroItem `json:"item"`
+	Script string    `json:"script"`
+	Audio  []byte    `json:"-"`
+}
+
+// heardOutro records the day a card heard an outro
+type heardOutro struct {
+	ID  string `json:"id"`
+	Day string `json:"day"`
+}
+
+// OutroContentService rotates each card through the outro banks so an outro isn't repeated until
+// the card has heard every other outro of its type. The weekday picks the type, as with the
+// pre-recorded outros. What each card has heard is persisted to a JSON file, and new outros are
+// narrated on demand.
+type OutroContentService struct {
+	tts   *ElevenLabsTTS
+	pools map[string][]OutroItem // outro type -> items
+
+	mu      sync.Mutex
+	path    string
+	history map[string][]heardOutro // card ID -> outros heard, oldest first
+	audio   map[string][]byte       // script and voice -> narration
+}
+
+// NewOutroContentService loads the history from path (default data/outro_history.json),
+// starting empty if the file doesn't exist
+func NewOutroContentService(path string, tts *ElevenLabsTTS) *OutroContentService {
+	if path == "" {
+		path = "data/outro_history.json"
+	}
+
+	service := &OutroContentService{
+		tts:     tts,
+		path:    path,
+		history: make(map[string][]heardOutro),
+		audio:   make(map[string][]byte),
+		pools: map[string][]OutroItem{
+			"joke":      newOutroItems("joke", generalBirdJokes),
+			"teaser":    newOutroItems("teaser", birdTeasers),
+			"wisdom":    newOutroItems("wisdom", birdWisdom),
+			"challenge": newOutroItems("challenge", birdChallenges),
+			"funfact":   newOutroItems("funfact", birdFunFacts),
+		},
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &service.history); err != nil {
+			slog.Warn("[OUTRO_CONTENT] Failed to parse history, starting empty", "path", path, "error", err)
+			service.history = make(map[string][]heardOutro)
+		}
+	}
+
+	return service
+}
+
+func newOutroItems(kind string, texts []string) []OutroItem {
+	items := make([]OutroItem, 0, len(texts))
+	for _, text := range texts {
+		items = append(items, newOutroItem(kind, text))
+	}
+	return items
+}
+
+func newOutroItem(kind string, text string) OutroItem {
+	sum := sha256.Sum256([]byte(text))
+	return OutroItem{ID: kind + "_" + hex.EncodeToString(sum[:4]), Kind: kind, Text: text}
+}
+
+// Pick returns the card's outro for day. Every play on the same day gets the same outro; a new
+// day gets one the card hasn't heard, starting the type's rotation over once all are heard.
+// Joke days use the bird's own joke the first time the card hears that bird.
+func (s *OutroContentService) Pick(cardID string, birdName string, day time.Time) OutroItem {
+	date := day.Format("2006-01-02")
+	kind := outroTypeOn(day.Weekday())
+
+	candidates := s.pools[kind]
+	var birdJoke *OutroItem
+	if joke, ok := specificJokes[birdName]; ok && kind == "joke" {
+		item := newOutroItem(kind, joke)
+		birdJoke = &item
+		candidates = append([]OutroItem{item}, candidates...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	heard := make(map[string]bool)
+	for _, entry := range s.history[cardID] {
+		if !strings.HasPrefix(entry.ID, kind+"_") {
+			continue
+		}
+		if entry.Day == date {
+			for _, item := range candidates {
+				if item.ID == entry.ID {
+					return item
+				}
+			}
+		}
+		heard[entry.ID] = true
+	}
+
+	var unheard []OutroItem
+	for _, item := range candidates {
+		if !heard[item.ID] {
+			unheard = append(unheard, item)
+		}
+	}
+	if len(unheard) == 0 {
+		slog.Info("[OUTRO_CONTENT] Card has heard every outro of this type, starting over", "card_id", cardID, "type", kind)
+		s.forget(cardID, kind)
+		unheard = candidates
+	}
+
+	item := randx.Pick(randx.Daily(date, cardID), unheard)
+	if birdJoke != nil && unheard[0].ID == birdJoke.ID {
+		item = *birdJoke
+	}
+
+	s.history[cardID] = append(s.history[cardID], heardOutro{ID: item.ID, Day: date})
+	if excess := len(s.history[cardID]) - outroHistoryPerCard; excess > 0 {
+		s.history[cardID] = s.history[cardID][excess:]
+	}
+	if err := s.save(); err != nil {
+		slog.Warn("[OUTRO_CONTENT] Failed to save history", "error", err)
+	}
+	return item
+}
+
+// forget drops the card's history for one outro type; callers hold s.mu
+func (s *OutroContentService) forget(cardID string, kind string) {
+	var kept []heardOutro
+	for _, entry := range s.history[cardID] {
+		if !strings.HasPrefix(entry.ID, kind+"_") {
+			kept = append(kept, entry)
+		}
+	}
+	s.history[cardID] = kept
+}
+
+// GenerateOutro picks the card's outro for day and narrates it. Narration is cached in memory and
+// by the TTS cache, so an outro is only rendered the first time any card hears it in a voice.
+func (s *OutroContentService) GenerateOutro(ctx context.Context, cardID string, birdName string, day time.Time, voiceID string) (*OutroContent, error) {
+	item := s.Pick(cardID, birdName, day)
+	content := &OutroContent{Item: item, Script: item.Script(birdName)}
+
+	key := content.Script + "|" + voiceID
+	s.mu.Lock()
+	audio, ok := s.audio[key]
+	s.mu.Unlock()
+	if ok {
+		content.Audio = audio
+		return content, nil
+	}
+
+	audio, cached, err := s.tts.Render(ctx, content.Script, voiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render outro %s: %w", item.ID, err)
+	}
+	content.Audio = audio
+
+	s.mu.Lock()
+	if len(s.audio) >= outroContentMaxCached {
+		s.audio = make(map[string][]byte)
+	}
+	s.audio[key] = audio
+	s.mu.Unlock()
+
+	slog.InfoContext(ctx, "[OUTRO_CONTENT] Generated outro", "card_id", cardID, "bird", birdName, "outro", item.ID, "tts_cached", cached, "bytes", len(audio))
+	return content, nil
+}
+
+// save writes the history to disk atomically; callers hold s.mu
+func (s *OutroContentService) save() error {
+	return filex.AtomicWriteJSON(s.path, s.history)
+}
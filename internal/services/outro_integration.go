@@ -3,27 +3,44 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"time"
 )
 
+// outroMix is how the outro voice, ambience, and closing jingle are balanced
+type outroMix struct {
+	voiceGain float64
+	ambience  float64 // Ambience volume under the voice
+	jingle    bool    // Close with the ukulele jingle
+}
+
+var (
+	dayOutroMix = outroMix{voiceGain: 2.2, ambience: 0.15, jingle: true}
+	// The goodnight outro stays quieter and ends on the ambience instead of the jingle
+	nightOutroMix = outroMix{voiceGain: 1.4, ambience: 0.08}
+)
+
 // OutroIntegration handles the complete outro flow with pre-recorded files
 type OutroIntegration struct {
 	staticManager *StaticOutroManager
 	audioMixer    *AudioMixer
+	processor     AudioProcessor
+	assets        AssetStore
 	useStatic     bool
 }
 
-// NewOutroIntegration creates a new outro integration service
-func NewOutroIntegration() *OutroIntegration {
-	// Check environment variable
-	useStatic := os.Getenv("USE_STATIC_OUTROS") != "false" // Default to true
-
+// NewOutroIntegration creates a new outro integration service, playing pre-recorded outros when
+// useStatic is set and narrating them live otherwise
+func NewOutroIntegration(useStatic bool) *OutroIntegration {
 	return &OutroIntegration{
 		staticManager: NewStaticOutroManager(),
-		audioMixer:    NewAudioMixer(),
+		audioMixer:    NewAudioMixer(nil),
+		processor:     NewAudioProcessor(),
+		assets:        SharedAssetStore(),
 		useStatic:     useStatic,
 	}
 }
@@ -41,59 +58,92 @@ func (oi *OutroIntegration) GenerateOutroWithAmbience(
 		return oi.generateDynamicOutro(voiceName, dayOfWeek, ambienceData)
 	}
 
-	// Get the pre-recorded outro file path
+	// Get the pre-recorded outro asset
 	outroPath, err := oi.getStaticOutroPath(voiceName, dayOfWeek)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get outro path: %w", err)
 	}
 
 	// Read the pre-recorded outro
-	outroData, err := os.ReadFile(outroPath)
+	outroData, err := oi.assets.ReadFile(outroPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read outro file: %w", err)
 	}
 
-	fmt.Printf("[OUTRO] Using pre-recorded outro: %s\n", filepath.Base(outroPath))
+	slog.Info("[OUTRO] Using pre-recorded outro", "file", path.Base(outroPath))
+	return oi.finishOutro(outroData, ambienceData, dayOutroMix)
+}
 
+// GenerateGoodnightOutro creates the night mode outro: a pre-recorded "goodnight explorers" outro
+// (final_outros/outro_goodnight_*_<voice>.mp3, falling back to the day's outro) mixed quietly
+// with the ambience and no jingle
+func (oi *OutroIntegration) GenerateGoodnightOutro(voiceName string, dayOfWeek time.Weekday, ambienceData []byte) ([]byte, error) {
+	outroPath, err := oi.pickOutro(fmt.Sprintf("final_outros/outro_goodnight_*_%s.mp3", voiceName))
+	if err != nil {
+		slog.Info("[OUTRO] No goodnight outro recorded, using the day's outro", "voice", voiceName)
+		if outroPath, err = oi.getStaticOutroPath(voiceName, dayOfWeek); err != nil {
+			return nil, fmt.Errorf("failed to get outro path: %w", err)
+		}
+	}
+
+	outroData, err := oi.assets.ReadFile(outroPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outro file: %w", err)
+	}
+
+	slog.Info("[OUTRO] Using pre-recorded goodnight outro", "file", path.Base(outroPath))
+	return oi.finishOutro(outroData, ambienceData, nightOutroMix)
+}
+
+// finishOutro mixes the outro with ambient sounds when there are any, otherwise only boosts it
+func (oi *OutroIntegration) finishOutro(outroData []byte, ambienceData []byte, mix outroMix) ([]byte, error) {
 	// Mix with ambient sounds if available
-	if ambienceData != nil && len(ambienceData) > 0 {
-		fmt.Printf("[OUTRO] Mixing with ambient sounds (%d bytes)\n", len(ambienceData))
-		mixedAudio, err := oi.mixOutroWithAmbience(outroData, ambienceData)
+	if len(ambienceData) > 0 {
+		slog.Info("[OUTRO] Mixing with ambient sounds", "bytes", len(ambienceData))
+		mixedAudio, err := oi.mixOutroWithAmbience(outroData, ambienceData, mix)
 		if err != nil {
-			fmt.Printf("[OUTRO] Mixing failed, applying volume boost only: %v\n", err)
+			slog.Warn("[OUTRO] Mixing failed, applying volume boost only", "error", err)
 			// Apply volume boost even if mixing fails
-			return oi.applyVolumeBoost(outroData)
+			return oi.boostVoice(outroData, mix.voiceGain)
 		}
-		fmt.Printf("[OUTRO] Successfully mixed outro with ambient sounds\n")
+		slog.Info("[OUTRO] Mixed outro with ambient sounds")
 		return mixedAudio, nil
 	}
 
 	// Apply volume boost to match intro track even without ambient sounds
-	fmt.Printf("[OUTRO] No ambient sounds, applying volume boost to outro\n")
-	return oi.applyVolumeBoost(outroData)
+	slog.Info("[OUTRO] No ambient sounds, applying volume boost to outro")
+	return oi.boostVoice(outroData, mix.voiceGain)
 }
 
-// getStaticOutroPath selects the appropriate pre-recorded outro file
+// getStaticOutroPath selects the appropriate pre-recorded outro asset
 func (oi *OutroIntegration) getStaticOutroPath(voiceName string, dayOfWeek time.Weekday) (string, error) {
 	outroType := oi.getOutroType(dayOfWeek)
 
 	// Find available outros of this type for this voice
-	pattern := filepath.Join("assets/final_outros", fmt.Sprintf("outro_%s_*_%s.mp3", outroType, voiceName))
-	matches, err := filepath.Glob(pattern)
-	if err != nil || len(matches) == 0 {
+	pattern := fmt.Sprintf("final_outros/outro_%s_*_%s.mp3", outroType, voiceName)
+	selectedFile, err := oi.pickOutro(pattern)
+	if err != nil {
 		return "", fmt.Errorf("no outros found for %s/%s (pattern: %s)", outroType, voiceName, pattern)
 	}
 
+	slog.Info("[OUTRO] Selected outro", "file", path.Base(selectedFile), "type", outroType, "voice", voiceName)
+	return selectedFile, nil
+}
+
+// pickOutro selects one of the outros matching pattern, the same one all day
+func (oi *OutroIntegration) pickOutro(pattern string) (string, error) {
+	matches, err := oi.assets.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no outros match %s", pattern)
+	}
+
 	// Select one deterministically based on date
 	now := time.Now()
 	daySeed := now.Year()*10000 + int(now.Month())*100 + now.Day()
-	outroIndex := daySeed % len(matches)
-	selectedFile := matches[outroIndex]
-
-	fmt.Printf("[OUTRO] Selected: %s (type: %s, voice: %s, index: %d of %d)\n",
-		filepath.Base(selectedFile), outroType, voiceName, outroIndex, len(matches))
-
-	return selectedFile, nil
+	return matches[daySeed%len(matches)], nil
 }
 
 // getOutroType determines which type of outro to use based on the day
@@ -129,26 +179,22 @@ func (oi *OutroIntegration) GetOutroURL(voiceName string, dayOfWeek time.Weekday
 	}
 
 	// Return URL to the outro file
-	filename := filepath.Base(outroPath)
+	filename := path.Base(outroPath)
 	return fmt.Sprintf("%s/audio/outros/%s", baseURL, filename), nil
 }
 
 // ValidateOutros checks that all required outro files exist
 func (oi *OutroIntegration) ValidateOutros() error {
-	// Hardcoded list of human voice narrators
-	voices := []string{"Amelia", "Antoni", "Charlotte", "Peter", "Drake", "Sally"}
-	types := []string{"joke", "wisdom", "teaser", "challenge", "funfact"}
-
 	missingCount := 0
-	for _, voice := range voices {
-		for _, outroType := range types {
-			pattern := filepath.Join("assets/final_outros", fmt.Sprintf("outro_%s_*_%s.mp3", outroType, voice))
-			matches, _ := filepath.Glob(pattern)
+	for _, voice := range narratorVoices() {
+		for _, outroType := range StaticOutroTypes {
+			pattern := fmt.Sprintf("final_outros/outro_%s_*_%s.mp3", outroType, voice)
+			matches, _ := oi.assets.Glob(pattern)
 			if len(matches) == 0 {
-				fmt.Printf("❌ Missing: %s outros for %s\n", outroType, voice)
+				slog.Error("[OUTRO] Missing outros", "type", outroType, "voice", voice)
 				missingCount++
 			} else {
-				fmt.Printf("✅ Found %d %s outros for %s\n", len(matches), outroType, voice)
+				slog.Info("[OUTRO] Found outros", "type", outroType, "voice", voice, "count", len(matches))
 			}
 		}
 	}
@@ -161,62 +207,24 @@ func (oi *OutroIntegration) ValidateOutros() error {
 	return nil
 }
 
-// applyVolumeBoost applies a 2.2x volume boost to match the intro track
-func (oi *OutroIntegration) applyVolumeBoost(audioData []byte) ([]byte, error) {
-	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		fmt.Printf("[OUTRO] ffmpeg not found, returning original audio\n")
-		return audioData, nil
-	}
-
-	// Create temp files
-	tempDir := os.TempDir()
-	inputFile := filepath.Join(tempDir, fmt.Sprintf("outro_input_%d.mp3", time.Now().Unix()))
-	outputFile := filepath.Join(tempDir, fmt.Sprintf("outro_boosted_%d.mp3", time.Now().Unix()))
-
-	// Write input data
-	if err := os.WriteFile(inputFile, audioData, 0644); err != nil {
-		return audioData, nil
-	}
-	defer os.Remove(inputFile)
-	defer os.Remove(outputFile)
-
-	// Apply volume boost to match intro (2.2x)
-	cmd := exec.Command("ffmpeg",
-		"-i", inputFile,
-		"-filter_complex",
-		"[0:a]volume=2.2[boosted]",
-		"-map", "[boosted]",
-		"-c:a", "libmp3lame",
-		"-b:a", "192k",
-		"-ar", "44100",
-		"-y",
-		outputFile,
-	)
-
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("[OUTRO] Volume boost failed: %v\n", err)
-		return audioData, nil
-	}
-
-	// Read the boosted audio
-	boostedData, err := os.ReadFile(outputFile)
+// boostVoice applies the mix's volume boost (2.2x by day, to match the intro track)
+func (oi *OutroIntegration) boostVoice(audioData []byte, gain float64) ([]byte, error) {
+	boostedData, err := oi.processor.Gain(audioData, gain)
 	if err != nil {
+		slog.Warn("[OUTRO] Volume boost failed", "processor", oi.processor.Name(), "error", err)
 		return audioData, nil
 	}
 
-	fmt.Printf("[OUTRO] Successfully applied 2.2x volume boost\n")
+	slog.Info("[OUTRO] Applied volume boost", "gain", gain, "processor", oi.processor.Name())
 	return boostedData, nil
 }
 
-// mixOutroWithAmbience mixes the outro with ambient sounds at 15% volume and adds ukulele jingle
-func (oi *OutroIntegration) mixOutroWithAmbience(outroData []byte, ambienceData []byte) ([]byte, error) {
-	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return oi.applyVolumeBoost(outroData)
+// mixOutroWithAmbience mixes the outro with looped ambient sounds, then closes with the ukulele
+// jingle or, without one, lets the ambience fade out after the voice
+func (oi *OutroIntegration) mixOutroWithAmbience(outroData []byte, ambienceData []byte, mix outroMix) ([]byte, error) {
+	// Without ffmpeg the ambience can't be layered under the voice, so it follows it instead
+	if !GetFFmpegCapabilities().Mixing {
+		return oi.sequenceOutroWithAmbience(outroData, ambienceData, mix)
 	}
 
 	// Create temp files
@@ -226,23 +234,30 @@ func (oi *OutroIntegration) mixOutroWithAmbience(outroData []byte, ambienceData
 	outputFile := filepath.Join(tempDir, fmt.Sprintf("outro_mixed_%d.mp3", time.Now().Unix()))
 
 	// Path to ukulele jingle
-	ukulelePath := "assets/sound_effects/chimes/ukulele_short.mp3"
+	var ukulelePath string
+	if mix.jingle {
+		var err error
+		if ukulelePath, err = oi.assets.LocalPath(ukuleleJingleAsset); err != nil {
+			slog.Warn("[OUTRO] Ukulele jingle unavailable", "error", err)
+			return oi.boostVoice(outroData, mix.voiceGain)
+		}
+	}
 
 	// Write files
 	if err := os.WriteFile(outroFile, outroData, 0644); err != nil {
-		return oi.applyVolumeBoost(outroData)
+		return oi.boostVoice(outroData, mix.voiceGain)
 	}
 	defer os.Remove(outroFile)
 
 	if err := os.WriteFile(ambienceFile, ambienceData, 0644); err != nil {
-		return oi.applyVolumeBoost(outroData)
+		return oi.boostVoice(outroData, mix.voiceGain)
 	}
 	defer os.Remove(ambienceFile)
 	defer os.Remove(outputFile)
 
 	// Get outro duration for timing
-	outroDuration := oi.getAudioDuration(outroFile)
-	if outroDuration <= 0 {
+	outroDuration, err := oi.processor.Duration(outroData)
+	if err != nil || outroDuration <= 0 {
 		outroDuration = 10.0 // Fallback
 	}
 
@@ -251,31 +266,46 @@ func (oi *OutroIntegration) mixOutroWithAmbience(outroData []byte, ambienceData
 	ukuleleStartTime := ambienceEndTime + 0.2 // Small gap before ukulele
 	totalDuration := ukuleleStartTime + 3.0   // Allow time for ukulele to play
 
-	// Mix with ambient sounds at 15% (matching intro), apply 2.2x boost to voice, and add ukulele at end
-	cmd := exec.Command("ffmpeg",
-		"-i", outroFile,
-		"-stream_loop", "-1",
-		"-i", ambienceFile,
-		"-i", ukulelePath,
-		"-filter_complex",
-		fmt.Sprintf(
-			// Ambience: 15%% volume, fade out before ukulele
-			"[1:a]volume=0.15,afade=t=in:st=0:d=1,afade=t=out:st=%.1f:d=1[ambience_quiet];"+
-				// Voice: 2.2x boost
-				"[0:a]volume=2.2[voice_boosted];"+
-				// Mix voice with ambience
-				"[voice_boosted][ambience_quiet]amix=inputs=2:duration=first:dropout_transition=0.5[voice_with_ambience];"+
-				// Ukulele: delay to start after ambience ends, with volume adjustment
-				"[2:a]adelay=%d|%d,volume=0.8[ukulele_delayed];"+
-				// Combine voice+ambience with ukulele
-				"[voice_with_ambience][ukulele_delayed]amix=inputs=2:duration=longest[mixed];"+
-				// Final fade out
-				"[mixed]afade=t=out:st=%.1f:d=0.5[out]",
-			ambienceEndTime-1.0,        // Start fading ambience 1 second before it ends
-			int(ukuleleStartTime*1000), // Ukulele delay in ms
-			int(ukuleleStartTime*1000), // Ukulele delay for second channel
-			totalDuration-0.5,          // Final fade start
-		),
+	// Mix with ambient sounds (matching intro), boost the voice, and add ukulele at end
+	inputs := []string{"-i", outroFile, "-stream_loop", "-1", "-i", ambienceFile}
+	filter := fmt.Sprintf(
+		// Ambience: quiet, fade out before ukulele
+		"[1:a]volume=%.2f,afade=t=in:st=0:d=1,afade=t=out:st=%.1f:d=1[ambience_quiet];"+
+			// Voice: boost
+			"[0:a]volume=%.2f[voice_boosted];"+
+			// Mix voice with ambience
+			"[voice_boosted][ambience_quiet]amix=inputs=2:duration=first:dropout_transition=0.5[voice_with_ambience];"+
+			// Ukulele: delay to start after ambience ends, with volume adjustment
+			"[2:a]adelay=%d|%d,volume=0.8[ukulele_delayed];"+
+			// Combine voice+ambience with ukulele
+			"[voice_with_ambience][ukulele_delayed]amix=inputs=2:duration=longest[mixed];"+
+			// Final fade out
+			"[mixed]afade=t=out:st=%.1f:d=0.5[out]",
+		mix.ambience,               // Ambience volume
+		ambienceEndTime-1.0,        // Start fading ambience 1 second before it ends
+		mix.voiceGain,              // Voice boost
+		int(ukuleleStartTime*1000), // Ukulele delay in ms
+		int(ukuleleStartTime*1000), // Ukulele delay for second channel
+		totalDuration-0.5,          // Final fade start
+	)
+	if mix.jingle {
+		inputs = append(inputs, "-i", ukulelePath)
+	} else {
+		// No jingle: the ambience carries on for a few seconds after the voice and fades away
+		totalDuration = outroDuration + 3.0
+		filter = fmt.Sprintf(
+			"[1:a]volume=%.2f,afade=t=in:st=0:d=1[ambience_quiet];"+
+				"[0:a]volume=%.2f,apad=pad_dur=3[voice_padded];"+
+				"[voice_padded][ambience_quiet]amix=inputs=2:duration=first:dropout_transition=0.5[mixed];"+
+				"[mixed]afade=t=out:st=%.1f:d=2.5[out]",
+			mix.ambience,
+			mix.voiceGain,
+			outroDuration+0.5, // Start fading just after the voice ends
+		)
+	}
+
+	args := append(inputs,
+		"-filter_complex", filter,
 		"-map", "[out]",
 		"-t", fmt.Sprintf("%.2f", totalDuration),
 		"-c:a", "libmp3lame",
@@ -283,41 +313,58 @@ func (oi *OutroIntegration) mixOutroWithAmbience(outroData []byte, ambienceData
 		"-y",
 		outputFile,
 	)
+	cmd := exec.Command(ffmpegBinary(), args...)
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("[OUTRO] Mixing failed: %v\nStderr: %s\n", err, stderr.String())
-		return oi.applyVolumeBoost(outroData)
+		slog.Error("[OUTRO] Mixing failed", "error", err, "stderr", stderr.String())
+		return oi.boostVoice(outroData, mix.voiceGain)
 	}
 
 	// Read the mixed audio
 	mixedData, err := os.ReadFile(outputFile)
 	if err != nil {
-		return oi.applyVolumeBoost(outroData)
+		return oi.boostVoice(outroData, mix.voiceGain)
 	}
 
-	fmt.Printf("[OUTRO] Successfully mixed with ambient sounds and applied volume boost\n")
+	slog.Info("[OUTRO] Mixed with ambient sounds and applied volume boost")
 	return mixedData, nil
 }
 
-// getAudioDuration gets the duration of an audio file using ffprobe
-func (oi *OutroIntegration) getAudioDuration(audioFile string) float64 {
-	cmd := exec.Command("ffprobe",
-		"-v", "error",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
-		audioFile,
-	)
+// sequenceOutroWithAmbience is the no-mixing fallback: the boosted voice, then a short faded
+// ambience tail, then the ukulele jingle if the mix has one
+func (oi *OutroIntegration) sequenceOutroWithAmbience(outroData []byte, ambienceData []byte, mix outroMix) ([]byte, error) {
+	voice, _ := oi.boostVoice(outroData, mix.voiceGain)
+
+	tail, err := oi.processor.Trim(ambienceData, 0, 2.0)
+	if err == nil {
+		tail, err = oi.processor.Gain(tail, mix.ambience)
+	}
+	if err == nil {
+		tail, err = oi.processor.Fade(tail, 0.5, 1.0)
+	}
+	if err != nil {
+		slog.Warn("[OUTRO] Failed to prepare ambience tail", "error", err)
+		return voice, nil
+	}
+
+	clips := [][]byte{voice, tail}
+	if mix.jingle {
+		if ukulele, err := oi.assets.ReadFile(ukuleleJingleAsset); err == nil {
+			if quieter, err := oi.processor.Gain(ukulele, 0.8); err == nil {
+				clips = append(clips, quieter)
+			}
+		}
+	}
 
-	output, err := cmd.Output()
+	sequenced, err := oi.processor.Concat(clips...)
 	if err != nil {
-		fmt.Printf("[OUTRO] Failed to get audio duration: %v\n", err)
-		return 0
+		slog.Warn("[OUTRO] Failed to sequence ambience", "processor", oi.processor.Name(), "error", err)
+		return voice, nil
 	}
 
-	duration := 0.0
-	fmt.Sscanf(string(output), "%f", &duration)
-	return duration
+	slog.Info("[OUTRO] Sequenced outro with ambience tail (no ffmpeg mixing)", "processor", oi.processor.Name())
+	return sequenced, nil
 }
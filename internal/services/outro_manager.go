@@ -2,8 +2,9 @@ package services
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/randx"
 )
 
 // OutroManager handles generation of outro content
@@ -12,11 +13,14 @@ type OutroManager struct {
 	specificBirdJokes map[string]string
 	wisdomQuotes      []string
 	funFacts          []string
+	localized         map[string][]string // Translated outros by type; nil for English
+	rng               *randx.Randomizer   // Picks jokes, quotes, and challenges; nil picks with a time seed
 }
 
-// NewOutroManager creates a new outro manager
-func NewOutroManager() *OutroManager {
+// NewOutroManager creates a new outro manager drawing its choices from rng
+func NewOutroManager(rng *randx.Randomizer) *OutroManager {
 	return &OutroManager{
+		rng:               rng,
 		generalJokes:      generalBirdJokes,
 		specificBirdJokes: specificJokes,
 		wisdomQuotes:      birdWisdom,
@@ -24,10 +28,25 @@ func NewOutroManager() *OutroManager {
 	}
 }
 
+// NewOutroManagerForLocale creates an outro manager for the given language. Translated outros
+// come from a smaller fixed set, without the bird-specific jokes or seasonal additions.
+func NewOutroManagerForLocale(locale string, rng *randx.Randomizer) *OutroManager {
+	om := NewOutroManager(rng)
+	if text, ok := TemplatesForLocale(locale); ok {
+		om.localized = text.Outros
+	}
+	return om
+}
+
 // GenerateOutroText generates the appropriate outro text based on day and bird
 func (om *OutroManager) GenerateOutroText(birdName string, dayOfWeek time.Weekday) string {
 	outroType := om.getOutroType(dayOfWeek)
 
+	if om.localized != nil {
+		outros := om.localized[outroType]
+		return outros[om.rng.Intn(len(outros))]
+	}
+
 	var baseOutro string
 	switch outroType {
 	case "joke":
@@ -55,6 +74,11 @@ func (om *OutroManager) GenerateOutroText(birdName string, dayOfWeek time.Weekda
 
 // getOutroType determines which type of outro to use based on the day
 func (om *OutroManager) getOutroType(dayOfWeek time.Weekday) string {
+	return outroTypeOn(dayOfWeek)
+}
+
+// outroTypeOn is the weekday's outro theme
+func outroTypeOn(dayOfWeek time.Weekday) string {
 	switch dayOfWeek {
 	case time.Monday, time.Friday:
 		return "joke"
@@ -80,8 +104,7 @@ func (om *OutroManager) getJokeOutro(birdName string) string {
 		joke = specificJoke
 	} else {
 		// Use general joke
-		rand.Seed(time.Now().UnixNano())
-		joke = om.generalJokes[rand.Intn(len(om.generalJokes))]
+		joke = om.generalJokes[om.rng.Intn(len(om.generalJokes))]
 	}
 
 	return fmt.Sprintf("Here's today's giggle before you go! %s <break time=\"1.0s\" /> See you tomorrow for another amazing bird adventure, explorers!", joke)
@@ -89,8 +112,7 @@ func (om *OutroManager) getJokeOutro(birdName string) string {
 
 // getWisdomOutro returns a wisdom/inspirational outro
 func (om *OutroManager) getWisdomOutro(birdName string) string {
-	rand.Seed(time.Now().UnixNano())
-	wisdom := om.wisdomQuotes[rand.Intn(len(om.wisdomQuotes))]
+	wisdom := om.wisdomQuotes[om.rng.Intn(len(om.wisdomQuotes))]
 
 	return fmt.Sprintf("Remember, little explorers: %s <break time=\"1.0s\" /> Think of our %s friend today and remember to spread your wings! Until tomorrow!", wisdom, birdName)
 }
@@ -109,16 +131,14 @@ func (om *OutroManager) getChallengeOutro(birdName string) string {
 		fmt.Sprintf("Can you flap your arms like the %s? Count how many flaps you can do!", birdName),
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	challenge := challenges[rand.Intn(len(challenges))]
+	challenge := challenges[om.rng.Intn(len(challenges))]
 
 	return fmt.Sprintf("Your Bird Explorer Challenge: %s <break time=\"1.0s\" /> Tomorrow, we'll learn about a new bird together. Happy exploring!", challenge)
 }
 
 // getFunFactOutro returns a fun fact outro
 func (om *OutroManager) getFunFactOutro(birdName string) string {
-	rand.Seed(time.Now().UnixNano())
-	fact := om.funFacts[rand.Intn(len(om.funFacts))]
+	fact := om.funFacts[om.rng.Intn(len(om.funFacts))]
 
 	return fmt.Sprintf("Before you go, did you know? %s <break time=\"1.0s\" /> Amazing, right? Sweet dreams, and tomorrow we'll discover another incredible bird together!", fact)
 }
@@ -182,8 +202,7 @@ func (om *OutroManager) getSeasonalAddition() string {
 		)
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	return seasonalMessages[rand.Intn(len(seasonalMessages))]
+	return seasonalMessages[om.rng.Intn(len(seasonalMessages))]
 }
 
 // getCurrentSeason returns the current season as a string
@@ -281,3 +300,64 @@ var birdFunFacts = []string{
 	"Birds have hollow bones that make them light enough to fly!",
 	"The Arctic Tern flies from the North Pole to the South Pole every year!",
 }
+
+// Bird-agnostic teasers for tomorrow's bird
+var birdTeasers = []string{
+	"Wow, wasn't that bird amazing? Tomorrow we'll meet another incredible feathered friend! Will it be big or small? Colorful or camouflaged? <break time=\"1.0s\" /> You'll have to come back to find out! Keep your ears open for bird songs today, explorers!",
+	"What a wonderful song! Tomorrow a brand new bird is waiting to meet you. Will it hoot, tweet, or quack? <break time=\"1.0s\" /> Come back tomorrow to find out, explorers!",
+	"Great listening today! Tomorrow's bird might live in a forest, by the sea, or right outside your window. <break time=\"1.0s\" /> See you tomorrow for another bird adventure!",
+	"Here's a clue about tomorrow's bird: it has feathers! Okay, that was too easy. <break time=\"1.0s\" /> Come back tomorrow to meet it, explorers!",
+	"Tomorrow's bird is practicing its song right now, just for you. Will it be loud or soft? <break time=\"1.0s\" /> Listen again tomorrow to find out!",
+	"Somewhere out there, tomorrow's bird is waking up and stretching its wings. <break time=\"1.0s\" /> We'll meet it together tomorrow, explorers!",
+}
+
+// Bird-agnostic explorer challenges
+var birdChallenges = []string{
+	"Can you copy today's bird song three times? Try it at breakfast, lunch, and dinner!",
+	"Can you spot a bird outside your window today? Watch how it moves and hops!",
+	"Can you draw a picture of the bird you heard today? Show someone special your artwork!",
+	"Can you flap your arms like a bird? Count how many flaps you can do!",
+	"Can you find something the same color as today's bird? Look around your home!",
+	"Can you sit very still and quiet for one whole minute and listen for birds outside?",
+	"Can you build a pretend nest out of pillows and blankets?",
+	"Can you make up your very own bird song and teach it to someone in your family?",
+}
+
+// StaticOutroScripts returns bird-agnostic outro scripts for pre-recording, keyed by outro type.
+// Each type gets up to perType scripts drawn in order from the same banks the dynamic outros use.
+func (om *OutroManager) StaticOutroScripts(perType int) map[string][]string {
+	scripts := make(map[string][]string, len(StaticOutroTypes))
+
+	if om.localized != nil {
+		for _, outroType := range StaticOutroTypes {
+			outros := om.localized[outroType]
+			if len(outros) > perType {
+				outros = outros[:perType]
+			}
+			scripts[outroType] = append([]string(nil), outros...)
+		}
+		return scripts
+	}
+
+	for i := 0; i < perType && i < len(om.generalJokes); i++ {
+		scripts["joke"] = append(scripts["joke"], fmt.Sprintf("Here's today's giggle before you go! %s <break time=\"1.0s\" /> See you tomorrow for another amazing bird adventure, explorers!", om.generalJokes[i]))
+	}
+
+	for i := 0; i < perType && i < len(om.wisdomQuotes); i++ {
+		scripts["wisdom"] = append(scripts["wisdom"], fmt.Sprintf("Remember, little explorers: %s <break time=\"1.0s\" /> Think of our feathered friend today and remember to spread your wings! Until tomorrow!", om.wisdomQuotes[i]))
+	}
+
+	for i := 0; i < perType && i < len(birdTeasers); i++ {
+		scripts["teaser"] = append(scripts["teaser"], birdTeasers[i])
+	}
+
+	for i := 0; i < perType && i < len(birdChallenges); i++ {
+		scripts["challenge"] = append(scripts["challenge"], fmt.Sprintf("Your Bird Explorer Challenge: %s <break time=\"1.0s\" /> Tomorrow, we'll learn about a new bird together. Happy exploring!", birdChallenges[i]))
+	}
+
+	for i := 0; i < perType && i < len(om.funFacts); i++ {
+		scripts["funfact"] = append(scripts["funfact"], fmt.Sprintf("Before you go, did you know? %s <break time=\"1.0s\" /> Amazing, right? Sweet dreams, and tomorrow we'll discover another incredible bird together!", om.funFacts[i]))
+	}
+
+	return scripts
+}
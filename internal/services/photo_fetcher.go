@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/inaturalist"
+	"github.com/callen/bird-song-explorer/pkg/wikipedia"
+)
+
+// birdPhotoMaxAge is how long a looked-up photo is reused; misses are retried after an hour
+const birdPhotoMaxAge = 24 * time.Hour
+
+// DefaultPhotoLicenses are the iNaturalist licenses that allow showing a cropped photo with
+// credit. All-rights-reserved and no-derivatives photos are skipped.
+var DefaultPhotoLicenses = []string{"cc0", "cc-by", "cc-by-sa", "cc-by-nc", "cc-by-nc-sa"}
+
+// BirdPhoto is a photo of a species with the credit to show alongside it
+type BirdPhoto struct {
+	URL          string `json:"url"`       // Medium size, for pages and icons
+	LargeURL     string `json:"large_url"` // Full size, for card covers
+	Attribution  string `json:"attribution"`
+	License      string `json:"license,omitempty"`
+	Source       string `json:"source"` // "inaturalist" or "wikipedia"
+	WikipediaURL string `json:"wikipedia_url,omitempty"`
+}
+
+type cachedBirdPhoto struct {
+	photo     *BirdPhoto
+	fetchedAt time.Time
+}
+
+// PhotoFetcher finds a photo of a bird, preferring iNaturalist's default taxon photo when its
+// license allows reuse and falling back to the Wikipedia page image, which comes from Wikimedia
+// Commons
+type PhotoFetcher struct {
+	inatClient      *inaturalist.Client
+	wikipediaClient *wikipedia.Client
+	licenses        map[string]bool
+
+	regionalWikipedia map[string]*wikipedia.Client // Region pack ID to its editions, in preference order
+
+	mu    sync.Mutex
+	cache map[string]cachedBirdPhoto
+}
+
+// NewPhotoFetcher creates a photo fetcher accepting iNaturalist photos under a comma-separated
+// list of license codes ("cc0,cc-by"), or DefaultPhotoLicenses when the list is empty
+func NewPhotoFetcher(licenses string) *PhotoFetcher {
+	codes := DefaultPhotoLicenses
+	if strings.TrimSpace(licenses) != "" {
+		codes = strings.Split(licenses, ",")
+	}
+	allowed := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		allowed[strings.ToLower(strings.TrimSpace(code))] = true
+	}
+
+	return &PhotoFetcher{
+		inatClient:      inaturalist.NewClient(),
+		wikipediaClient: wikipedia.NewEnglishClient(),
+		licenses:        allowed,
+		cache:           make(map[string]cachedBirdPhoto),
+
+		regionalWikipedia: make(map[string]*wikipedia.Client),
+	}
+}
+
+// PhotoForBird returns a reusable photo of the bird
+func (pf *PhotoFetcher) PhotoForBird(ctx context.Context, birdName string) (*BirdPhoto, error) {
+	return pf.PhotoForBirdInRegion(ctx, birdName, "")
+}
+
+// PhotoForBirdInRegion returns a reusable photo of the bird, looking it up in the region pack's
+// Wikipedia editions, so birds missing from English Wikipedia can still be found
+func (pf *PhotoFetcher) PhotoForBirdInRegion(ctx context.Context, birdName string, region string) (*BirdPhoto, error) {
+	key := strings.ToLower(birdName)
+
+	pf.mu.Lock()
+	cached, ok := pf.cache[key]
+	pf.mu.Unlock()
+	if ok {
+		maxAge := birdPhotoMaxAge
+		if cached.photo == nil {
+			maxAge = time.Hour
+		}
+		if time.Since(cached.fetchedAt) < maxAge {
+			if cached.photo == nil {
+				return nil, fmt.Errorf("no photo found for %s", birdName)
+			}
+			return cached.photo, nil
+		}
+	}
+
+	photo := pf.lookup(ctx, birdName, pf.wikipediaFor(region))
+	if ctx.Err() != nil {
+		// A cancelled lookup may have missed a photo that exists, so it isn't cached
+		return nil, ctx.Err()
+	}
+
+	pf.mu.Lock()
+	pf.cache[key] = cachedBirdPhoto{photo: photo, fetchedAt: time.Now()}
+	pf.mu.Unlock()
+
+	if photo == nil {
+		return nil, fmt.Errorf("no photo found for %s", birdName)
+	}
+	return photo, nil
+}
+
+// wikipediaFor returns the Wikipedia client for a region pack, or English Wikipedia
+func (pf *PhotoFetcher) wikipediaFor(region string) *wikipedia.Client {
+	pack := RegionPackByID(region)
+	if pack == nil || len(pack.WikipediaLanguages) == 0 {
+		return pf.wikipediaClient
+	}
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	client, ok := pf.regionalWikipedia[pack.ID]
+	if !ok {
+		client = wikipedia.NewClientWithFallbacks(pack.WikipediaLanguages...)
+		pf.regionalWikipedia[pack.ID] = client
+	}
+	return client
+}
+
+func (pf *PhotoFetcher) lookup(ctx context.Context, birdName string, wikipediaClient *wikipedia.Client) *BirdPhoto {
+	var wikipediaURL string
+	summary, wikiErr := wikipediaClient.GetBirdSummary(ctx, birdName)
+	if wikiErr == nil {
+		wikipediaURL = summary.ContentURLs.Desktop.Page
+	}
+
+	taxon, err := pf.inatClient.SearchTaxon(ctx, birdName)
+	if err != nil {
+		slog.Warn("[PHOTO_FETCHER] iNaturalist lookup failed", "bird", birdName, "error", err)
+	} else if photo := taxon.DefaultPhoto; photo != nil {
+		license := strings.ToLower(photo.LicenseCode)
+		if !pf.licenses[license] {
+			slog.Info("[PHOTO_FETCHER] Skipping iNaturalist photo with disallowed license", "bird", birdName, "license", license)
+		} else if photoURL := firstNonEmpty(photo.MediumURL, photo.URL, photo.SquareURL); photoURL != "" {
+			return &BirdPhoto{
+				URL:          photoURL,
+				LargeURL:     strings.Replace(photoURL, "/medium.", "/large.", 1),
+				Attribution:  photo.Attribution,
+				License:      license,
+				Source:       "inaturalist",
+				WikipediaURL: wikipediaURL,
+			}
+		}
+	}
+
+	if wikiErr == nil && summary.Thumbnail.Source != "" {
+		return &BirdPhoto{
+			URL:          summary.Thumbnail.Source,
+			LargeURL:     firstNonEmpty(summary.OriginalImage.Source, summary.Thumbnail.Source),
+			Attribution:  "Wikimedia Commons",
+			Source:       "wikipedia",
+			WikipediaURL: wikipediaURL,
+		}
+	}
+	return nil
+}
+
+// WithPhoto fills in the bird's photo and Wikipedia link when one can be found
+func (pf *PhotoFetcher) WithPhoto(ctx context.Context, bird *models.Bird) *models.Bird {
+	photo, err := pf.PhotoForBird(ctx, bird.CommonName)
+	if err != nil {
+		return bird
+	}
+
+	withPhoto := *bird
+	withPhoto.PhotoURL = photo.URL
+	withPhoto.PhotoAttribution = photo.Attribution
+	if withPhoto.WikipediaURL == "" {
+		withPhoto.WikipediaURL = photo.WikipediaURL
+	}
+	return &withPhoto
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
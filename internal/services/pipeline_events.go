@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Pipeline event types published to dashboard subscribers
+const (
+	EventCardPlayed   = "card_played"
+	EventJobStarted   = "job_started"
+	EventTTSDone      = "tts_done"
+	EventUploadDone   = "upload_done"
+	EventPublished    = "published"
+	EventJobDeferred  = "job_deferred"
+	EventPipelineFail = "error"
+	EventCardReverted = "card_reverted"
+)
+
+// recentEventLimit is how many events a new subscriber receives as backlog
+const recentEventLimit = 50
+
+// PipelineEvent is one step of a card update, streamed to the dashboard
+type PipelineEvent struct {
+	Type      string    `json:"type"`
+	CardID    string    `json:"card_id,omitempty"`
+	BirdName  string    `json:"bird_name,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PipelineEvents fans pipeline events out to live subscribers.
+// Slow subscribers drop events rather than blocking the pipeline.
+type PipelineEvents struct {
+	mu          sync.Mutex
+	subscribers map[chan PipelineEvent]struct{}
+	recent      []PipelineEvent
+}
+
+// NewPipelineEvents creates an empty event broadcaster
+func NewPipelineEvents() *PipelineEvents {
+	return &PipelineEvents{
+		subscribers: make(map[chan PipelineEvent]struct{}),
+	}
+}
+
+// Publish sends an event to every subscriber
+func (pe *PipelineEvents) Publish(eventType string, cardID string, birdName string, message string) {
+	event := PipelineEvent{
+		Type:      eventType,
+		CardID:    cardID,
+		BirdName:  birdName,
+		Message:   message,
+		Timestamp: time.Now().UTC(),
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.recent = append(pe.recent, event)
+	if len(pe.recent) > recentEventLimit {
+		pe.recent = pe.recent[len(pe.recent)-recentEventLimit:]
+	}
+
+	for ch := range pe.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of new events and a backlog of recent ones
+func (pe *PipelineEvents) Subscribe() (chan PipelineEvent, []PipelineEvent) {
+	ch := make(chan PipelineEvent, 16)
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.subscribers[ch] = struct{}{}
+	backlog := make([]PipelineEvent, len(pe.recent))
+	copy(backlog, pe.recent)
+
+	return ch, backlog
+}
+
+// Unsubscribe stops delivery to a subscriber channel
+func (pe *PipelineEvents) Unsubscribe(ch chan PipelineEvent) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	delete(pe.subscribers, ch)
+}
+
+// SubscriberCount returns the number of connected subscribers
+func (pe *PipelineEvents) SubscriberCount() int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	return len(pe.subscribers)
+}
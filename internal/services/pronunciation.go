@@ -0,0 +1,193 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Pronunciation is how a hard word should be spoken
+type Pronunciation struct {
+	Word     string `json:"word"`          // As written in scripts; matched case-insensitively as a whole word
+	Phonetic string `json:"phonetic"`      // Respelling any model can read, e.g. "pie-lee-ay-tid"
+	IPA      string `json:"ipa,omitempty"` // Sent as an SSML phoneme tag to models that support them
+}
+
+// phonemeModels are the ElevenLabs models that honour SSML phoneme tags; the others read the
+// respelling instead
+var phonemeModels = map[string]bool{
+	"eleven_flash_v2":       true,
+	"eleven_turbo_v2":       true,
+	"eleven_monolingual_v1": true,
+}
+
+// defaultPronunciations are bird names and scientific names ElevenLabs is known to mispronounce
+var defaultPronunciations = []Pronunciation{
+	{Word: "Pileated", Phonetic: "pie-lee-ay-tid", IPA: "ˈpaɪliˌeɪtɪd"},
+	{Word: "Towhee", Phonetic: "toe-ee", IPA: "ˈtoʊi"},
+	{Word: "Vireo", Phonetic: "veer-ee-oh", IPA: "ˈvɪrioʊ"},
+	{Word: "Phoebe", Phonetic: "fee-bee", IPA: "ˈfiːbi"},
+	{Word: "Gnatcatcher", Phonetic: "nat-catcher", IPA: "ˈnætˌkætʃər"},
+	{Word: "Grosbeak", Phonetic: "grohs-beek", IPA: "ˈɡroʊsbiːk"},
+	{Word: "Plover", Phonetic: "pluv-er", IPA: "ˈplʌvər"},
+	{Word: "Guillemot", Phonetic: "gil-ih-mot", IPA: "ˈɡɪlɪmɒt"},
+	{Word: "Phainopepla", Phonetic: "fay-no-pep-luh", IPA: "ˌfeɪnoʊˈpɛplə"},
+	{Word: "Pyrrhuloxia", Phonetic: "peer-uh-lock-see-uh", IPA: "ˌpɪrəˈlɒksiə"},
+	{Word: "Chachalaca", Phonetic: "chah-chah-lah-kah"},
+	{Word: "Hoopoe", Phonetic: "hoo-poo", IPA: "ˈhuːpuː"},
+	{Word: "Tui", Phonetic: "too-ee", IPA: "ˈtuːi"},
+	{Word: "Kea", Phonetic: "kee-uh", IPA: "ˈkiːə"},
+
+	// Scientific names, genus and species epithet separately so they combine
+	{Word: "Dryocopus", Phonetic: "dry-ock-oh-pus"},
+	{Word: "pileatus", Phonetic: "pie-lee-ay-tus"},
+	{Word: "Haliaeetus", Phonetic: "hal-ee-eye-uh-tus"},
+	{Word: "leucocephalus", Phonetic: "loo-koh-seff-uh-lus"},
+	{Word: "Turdus", Phonetic: "tur-dus"},
+	{Word: "migratorius", Phonetic: "my-gruh-tor-ee-us"},
+	{Word: "Cyanocitta", Phonetic: "sigh-an-oh-sit-uh"},
+	{Word: "cristata", Phonetic: "kris-tah-tuh"},
+	{Word: "Poecile", Phonetic: "pee-sih-lee"},
+	{Word: "atricapillus", Phonetic: "at-rih-kuh-pill-us"},
+	{Word: "Zenaida", Phonetic: "zen-ay-ih-duh"},
+	{Word: "macroura", Phonetic: "mak-roo-ruh"},
+	{Word: "Erithacus", Phonetic: "eh-rith-uh-kus"},
+	{Word: "rubecula", Phonetic: "roo-beck-yoo-luh"},
+	{Word: "Fratercula", Phonetic: "fruh-tur-kyoo-luh"},
+	{Word: "Dacelo", Phonetic: "duh-see-loh"},
+	{Word: "novaeguineae", Phonetic: "noh-vee-gih-nee-ee"},
+	{Word: "Apteryx", Phonetic: "ap-ter-iks"},
+}
+
+// PronunciationDictionary rewrites hard words in scripts before they're sent to text-to-speech
+type PronunciationDictionary struct {
+	entries map[string]Pronunciation // lowercased word -> pronunciation
+	pattern *regexp.Regexp
+}
+
+var (
+	sharedPronunciations     *PronunciationDictionary
+	sharedPronunciationsOnce sync.Once
+)
+
+// ConfigurePronunciations sets up the process-wide dictionary: the built-in entries extended by
+// the JSON list at path, if set. Call it at startup, before anything narrates; later calls have
+// no effect.
+func ConfigurePronunciations(path string) {
+	sharedPronunciationsOnce.Do(func() {
+		sharedPronunciations = NewPronunciationDictionary(path)
+	})
+}
+
+// SharedPronunciations returns the process-wide dictionary, with only the built-in entries when
+// ConfigurePronunciations wasn't called
+func SharedPronunciations() *PronunciationDictionary {
+	ConfigurePronunciations("")
+	return sharedPronunciations
+}
+
+// NewPronunciationDictionary loads the built-in pronunciations plus a JSON list of entries from
+// path, which override built-in entries for the same word. A file that can't be read leaves the
+// built-in entries in place.
+func NewPronunciationDictionary(path string) *PronunciationDictionary {
+	pd := &PronunciationDictionary{entries: make(map[string]Pronunciation)}
+	for _, entry := range defaultPronunciations {
+		pd.add(entry)
+	}
+
+	if path != "" {
+		if entries, err := loadPronunciations(path); err != nil {
+			log.Printf("[PRONUNCIATION] %v, using built-in pronunciations", err)
+		} else {
+			for _, entry := range entries {
+				pd.add(entry)
+			}
+			log.Printf("[PRONUNCIATION] Loaded %d pronunciations from %s", len(entries), path)
+		}
+	}
+
+	pd.compile()
+	return pd
+}
+
+// loadPronunciations reads a JSON list of pronunciations
+func loadPronunciations(path string) ([]Pronunciation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pronunciations %s: %w", path, err)
+	}
+	var entries []Pronunciation
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse pronunciations %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// add stores an entry, skipping ones with nothing to say
+func (pd *PronunciationDictionary) add(entry Pronunciation) {
+	entry.Word = strings.TrimSpace(entry.Word)
+	if entry.Word == "" || (entry.Phonetic == "" && entry.IPA == "") {
+		log.Printf("[PRONUNCIATION] Skipping incomplete entry %q", entry.Word)
+		return
+	}
+	pd.entries[strings.ToLower(entry.Word)] = entry
+}
+
+// compile builds one pattern matching every word, longest first so phrases beat their parts
+func (pd *PronunciationDictionary) compile() {
+	if len(pd.entries) == 0 {
+		return
+	}
+
+	words := make([]string, 0, len(pd.entries))
+	for _, entry := range pd.entries {
+		words = append(words, regexp.QuoteMeta(entry.Word))
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i]) != len(words[j]) {
+			return len(words[i]) > len(words[j])
+		}
+		return words[i] < words[j]
+	})
+	pd.pattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(words, "|") + `)\b`)
+}
+
+// Apply rewrites the dictionary's words in text for the given model: an SSML phoneme tag when
+// the model supports them and the entry has IPA, otherwise the phonetic respelling
+func (pd *PronunciationDictionary) Apply(text string, modelID string) string {
+	if pd == nil || pd.pattern == nil {
+		return text
+	}
+
+	phonemes := phonemeModels[modelID]
+	return pd.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		entry, ok := pd.entries[strings.ToLower(word)]
+		if !ok {
+			return word
+		}
+		if phonemes && entry.IPA != "" {
+			return fmt.Sprintf(`<phoneme alphabet="ipa" ph="%s">%s</phoneme>`, entry.IPA, word)
+		}
+		if entry.Phonetic == "" {
+			return word
+		}
+		return entry.Phonetic
+	})
+}
+
+// Entries returns the dictionary's pronunciations, sorted by word
+func (pd *PronunciationDictionary) Entries() []Pronunciation {
+	entries := make([]Pronunciation, 0, len(pd.entries))
+	for _, entry := range pd.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Word) < strings.ToLower(entries[j].Word)
+	})
+	return entries
+}
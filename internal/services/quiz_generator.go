@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+const (
+	quizSearchRadiusKm = 25
+	quizSearchDays     = 14
+	quizClipSeconds    = 8.0
+	quizMaxCached      = 100
+)
+
+// BirdQuiz is a "Can you guess the bird?" round built around a second local bird
+type BirdQuiz struct {
+	MainBird              string         `json:"main_bird"`
+	MysteryBird           string         `json:"mystery_bird"`
+	MysteryScientificName string         `json:"mystery_scientific_name"`
+	Prompt                string         `json:"prompt"`
+	Reveal                string         `json:"reveal"`
+	Recording             *SongRecording `json:"recording,omitempty"`
+	Audio                 []byte         `json:"-"`
+}
+
+// QuizGenerator picks a contrasting nearby species for the quiz chapter and renders its audio:
+// the spoken question, a short clip of the mystery bird, and the answer
+type QuizGenerator struct {
+	ebirdClient *ebird.Client
+	recordings  *RecordingSelector
+	tts         *ElevenLabsTTS
+	processor   AudioProcessor
+	httpClient  *http.Client
+	warmed      *RecordingWarmer // Optional pre-cached recordings, checked before the recording sources
+
+	mu    sync.Mutex
+	cache map[string]*BirdQuiz // main bird, date, and rounded location -> rendered quiz
+}
+
+// NewQuizGenerator creates a quiz generator using eBird for nearby species and the shared
+// recording selector for the mystery clip
+func NewQuizGenerator(ebirdAPIKey string, recordings *RecordingSelector, tts *ElevenLabsTTS) *QuizGenerator {
+	return &QuizGenerator{
+		ebirdClient: ebird.NewClient(ebirdAPIKey),
+		recordings:  recordings,
+		tts:         tts,
+		processor:   NewAudioProcessor(),
+		httpClient:  recordingDownloadClient,
+		cache:       make(map[string]*BirdQuiz),
+	}
+}
+
+// SetRecordingCache reads mystery recordings the warmer has already cached before asking the
+// recording sources
+func (qg *QuizGenerator) SetRecordingCache(warmer *RecordingWarmer) {
+	qg.warmed = warmer
+}
+
+// GenerateQuiz returns today's quiz for the main bird at a location, reusing a rendered quiz
+// for the same bird and area
+func (qg *QuizGenerator) GenerateQuiz(ctx context.Context, mainBird string, lat, lng float64, voiceID string) (*BirdQuiz, error) {
+	key := fmt.Sprintf("%s|%s|%.1f,%.1f", strings.ToLower(mainBird), time.Now().Format("2006-01-02"), lat, lng)
+
+	qg.mu.Lock()
+	cached, ok := qg.cache[key]
+	qg.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	mystery, err := qg.SelectMysteryBird(ctx, mainBird, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+
+	recording, clip, warmed := qg.warmed.Cached(mystery.ScientificName)
+	if !warmed {
+		if recording, err = qg.recordings.FindRecording(ctx, mystery.ScientificName); err != nil {
+			return nil, fmt.Errorf("no recording for %s: %w", mystery.CommonName, err)
+		}
+	}
+
+	quiz := &BirdQuiz{
+		MainBird:              mainBird,
+		MysteryBird:           mystery.CommonName,
+		MysteryScientificName: mystery.ScientificName,
+		Recording:             recording,
+	}
+	quiz.Prompt, quiz.Reveal = BuildQuizScript(mainBird, mystery.CommonName)
+
+	if quiz.Audio, err = qg.renderQuizAudio(ctx, quiz, clip, voiceID); err != nil {
+		return nil, err
+	}
+
+	qg.mu.Lock()
+	if len(qg.cache) >= quizMaxCached {
+		qg.cache = make(map[string]*BirdQuiz)
+	}
+	qg.cache[key] = quiz
+	qg.mu.Unlock()
+
+	slog.InfoContext(ctx, "[QUIZ] Generated quiz", "bird", mainBird, "mystery_bird", mystery.CommonName, "source", recording.Source,
+		"warmed", warmed, "bytes", len(quiz.Audio))
+	return quiz, nil
+}
+
+// SelectMysteryBird picks the most frequently reported nearby species that sounds unlike the main
+// bird. Species sharing the main bird's group name (another "sparrow" for a sparrow) are only
+// used when nothing else was seen.
+func (qg *QuizGenerator) SelectMysteryBird(ctx context.Context, mainBird string, lat, lng float64) (*ebird.Observation, error) {
+	observations, err := qg.ebirdClient.GetRecentObservationsWithRadius(ctx, lat, lng, quizSearchRadiusKm, quizSearchDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nearby observations: %w", err)
+	}
+
+	// Count reports per species, remembering one observation for each
+	counts := make(map[string]int)
+	bySpecies := make(map[string]ebird.Observation)
+	for _, obs := range observations {
+		if obs.ScientificName == "" || strings.EqualFold(obs.CommonName, mainBird) {
+			continue
+		}
+		counts[obs.SpeciesCode]++
+		bySpecies[obs.SpeciesCode] = obs
+	}
+	if len(bySpecies) == 0 {
+		return nil, fmt.Errorf("no other species reported near %.2f, %.2f", lat, lng)
+	}
+
+	codes := make([]string, 0, len(bySpecies))
+	for code := range bySpecies {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if counts[codes[i]] != counts[codes[j]] {
+			return counts[codes[i]] > counts[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+
+	mainGroup := birdGroupName(mainBird)
+	for _, code := range codes {
+		if obs := bySpecies[code]; birdGroupName(obs.CommonName) != mainGroup {
+			return &obs, nil
+		}
+	}
+	obs := bySpecies[codes[0]]
+	return &obs, nil
+}
+
+// BuildQuizScript returns the question read before the mystery clip and the answer read after it
+func BuildQuizScript(mainBird, mysteryBird string) (prompt, reveal string) {
+	hint := ""
+	if first := strings.TrimSpace(mysteryBird); first != "" {
+		hint = fmt.Sprintf(" Here's a hint: its name starts with the letter %s.", strings.ToUpper(first[:1]))
+	}
+
+	prompt = fmt.Sprintf("Quiz time! The %s isn't the only bird singing near you. "+
+		"Listen carefully to this mystery bird, and see if you can guess who it is.%s", mainBird, hint)
+	reveal = fmt.Sprintf("Did you guess it? That was the %s! "+
+		"Its song sounds very different from the %s's, doesn't it? "+
+		"Next time you're outside, listen for both of them.", mysteryBird, mainBird)
+	return prompt, reveal
+}
+
+// renderQuizAudio splices the spoken question, a faded clip of the mystery bird, and the answer.
+// The recording is downloaded unless a cached clip is passed in.
+func (qg *QuizGenerator) renderQuizAudio(ctx context.Context, quiz *BirdQuiz, clip []byte, voiceID string) ([]byte, error) {
+	prompt, _, err := qg.tts.Render(ctx, quiz.Prompt, voiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render quiz prompt: %w", err)
+	}
+	reveal, _, err := qg.tts.Render(ctx, quiz.Reveal, voiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render quiz reveal: %w", err)
+	}
+
+	if clip == nil {
+		if clip, err = qg.downloadRecording(ctx, quiz.Recording.URL); err != nil {
+			return nil, err
+		}
+	}
+	if clip, err = qg.processor.Trim(clip, 0, quizClipSeconds); err != nil {
+		return nil, fmt.Errorf("failed to trim mystery clip (%s): %w", qg.processor.Name(), err)
+	}
+	if clip, err = qg.processor.Fade(clip, 0.5, 1.5); err != nil {
+		return nil, fmt.Errorf("failed to fade mystery clip (%s): %w", qg.processor.Name(), err)
+	}
+
+	audio, err := qg.processor.Concat(prompt, clip, reveal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to splice quiz audio (%s): %w", qg.processor.Name(), err)
+	}
+	return audio, nil
+}
+
+// downloadRecording fetches the mystery bird's recording
+func (qg *QuizGenerator) downloadRecording(ctx context.Context, url string) ([]byte, error) {
+	resp, err := httpx.Get(ctx, qg.httpClient, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("recording download returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// birdGroupName returns the last word of a common name ("Song Sparrow" -> "sparrow"), which
+// groups similar-sounding birds well enough to keep the quiz from being too hard
+func birdGroupName(commonName string) string {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(commonName, "-", " ")))
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// ErrTTSQuotaExhausted means rendering would go over the ElevenLabs character budget. Callers
+// fall back to cached or pre-recorded audio.
+var ErrTTSQuotaExhausted = errors.New("ElevenLabs character budget exhausted")
+
+// QuotaUsage is the characters billed in the current day and month (UTC, matching ElevenLabs billing)
+type QuotaUsage struct {
+	Day        string `json:"day"`   // "2006-01-02"
+	Month      string `json:"month"` // "2006-01"
+	DayChars   int    `json:"day_chars"`
+	MonthChars int    `json:"month_chars"`
+}
+
+// QuotaStatus reports usage against the budgets; a budget or remainder of -1 means unlimited
+type QuotaStatus struct {
+	QuotaUsage
+	DailyBudget      int       `json:"daily_budget"`
+	MonthlyBudget    int       `json:"monthly_budget"`
+	DailyRemaining   int       `json:"daily_remaining"`
+	MonthlyRemaining int       `json:"monthly_remaining"`
+	Rejected         int       `json:"rejected"` // Renders refused since startup
+	ResetsAt         time.Time `json:"resets_at"`
+}
+
+// QuotaManager tracks ElevenLabs character usage against daily and monthly budgets and limits
+// how many renders run at once. Usage is saved to disk (ELEVENLABS_QUOTA_PATH,
+// data/elevenlabs_quota.json by default) so restarts don't reset the count.
+type QuotaManager struct {
+	mu            sync.Mutex
+	path          string
+	dailyBudget   int
+	monthlyBudget int
+	usage         QuotaUsage
+	rejected      int
+
+	slots chan struct{} // Concurrent render slots; nil for no limit
+}
+
+// NewQuotaManager creates a quota manager. Budgets of 0 or less are unlimited, as is a
+// maxConcurrent of 0 or less.
+func NewQuotaManager(path string, dailyBudget, monthlyBudget, maxConcurrent int) *QuotaManager {
+	if path == "" {
+		path = "data/elevenlabs_quota.json"
+	}
+
+	qm := &QuotaManager{
+		path:          path,
+		dailyBudget:   dailyBudget,
+		monthlyBudget: monthlyBudget,
+	}
+	if maxConcurrent > 0 {
+		qm.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &qm.usage); err != nil {
+			log.Printf("[QUOTA] Failed to parse %s: %v, starting from zero", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		log.Printf("[QUOTA] Failed to read %s: %v, starting from zero", path, err)
+	}
+	return qm
+}
+
+// Reserve books characters against the budgets before a render. It returns ErrTTSQuotaExhausted
+// when either budget would be exceeded; a failed render gives its characters back with Refund.
+func (qm *QuotaManager) Reserve(chars int) error {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	qm.rollOver(time.Now().UTC())
+
+	if qm.dailyBudget > 0 && qm.usage.DayChars+chars > qm.dailyBudget {
+		qm.rejected++
+		return fmt.Errorf("%w: %d of %d daily characters used", ErrTTSQuotaExhausted, qm.usage.DayChars, qm.dailyBudget)
+	}
+	if qm.monthlyBudget > 0 && qm.usage.MonthChars+chars > qm.monthlyBudget {
+		qm.rejected++
+		return fmt.Errorf("%w: %d of %d monthly characters used", ErrTTSQuotaExhausted, qm.usage.MonthChars, qm.monthlyBudget)
+	}
+
+	qm.usage.DayChars += chars
+	qm.usage.MonthChars += chars
+	qm.save()
+	return nil
+}
+
+// Refund returns characters reserved for a render that wasn't billed
+func (qm *QuotaManager) Refund(chars int) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	qm.rollOver(time.Now().UTC())
+	qm.usage.DayChars = max(qm.usage.DayChars-chars, 0)
+	qm.usage.MonthChars = max(qm.usage.MonthChars-chars, 0)
+	qm.save()
+}
+
+// HasBudget reports whether neither the daily nor the monthly budget is spent
+func (qm *QuotaManager) HasBudget() bool {
+	status := qm.Status()
+	return status.DailyRemaining != 0 && status.MonthlyRemaining != 0
+}
+
+// Acquire waits for a free render slot; the returned func releases it
+func (qm *QuotaManager) Acquire(ctx context.Context) (func(), error) {
+	if qm.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case qm.slots <- struct{}{}:
+		return func() { <-qm.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Status returns the current usage and what's left of each budget
+func (qm *QuotaManager) Status() QuotaStatus {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	now := time.Now().UTC()
+	qm.rollOver(now)
+
+	status := QuotaStatus{
+		QuotaUsage:       qm.usage,
+		DailyBudget:      -1,
+		MonthlyBudget:    -1,
+		DailyRemaining:   -1,
+		MonthlyRemaining: -1,
+		Rejected:         qm.rejected,
+		ResetsAt:         time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+	}
+	if qm.dailyBudget > 0 {
+		status.DailyBudget = qm.dailyBudget
+		status.DailyRemaining = max(qm.dailyBudget-qm.usage.DayChars, 0)
+	}
+	if qm.monthlyBudget > 0 {
+		status.MonthlyBudget = qm.monthlyBudget
+		status.MonthlyRemaining = max(qm.monthlyBudget-qm.usage.MonthChars, 0)
+	}
+	return status
+}
+
+// rollOver starts new day and month counts when the date moves on; the caller holds qm.mu
+func (qm *QuotaManager) rollOver(now time.Time) {
+	day, month := now.Format("2006-01-02"), now.Format("2006-01")
+	if qm.usage.Month != month {
+		qm.usage.Month = month
+		qm.usage.MonthChars = 0
+	}
+	if qm.usage.Day != day {
+		qm.usage.Day = day
+		qm.usage.DayChars = 0
+	}
+}
+
+// save writes the usage atomically; the caller holds qm.mu. A failed write only risks
+// under-counting after a restart, so it's logged.
+func (qm *QuotaManager) save() {
+	data, err := json.Marshal(qm.usage)
+	if err != nil {
+		return
+	}
+	if err := filex.AtomicWriteFile(qm.path, data, 0644); err != nil {
+		log.Printf("[QUOTA] Failed to save usage: %v", err)
+	}
+}
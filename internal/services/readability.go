@@ -0,0 +1,148 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// defaultGuideReadingGrade is the Flesch-Kincaid grade Explorer's Guide facts are held to
+// unless GUIDE_READING_GRADE says otherwise
+const defaultGuideReadingGrade = 6.0
+
+// Simplified clauses shorter than this are fragments, not facts
+const minSimplifiedWords = 4
+
+// simplerWords swaps encyclopedia vocabulary for words young listeners know. Keys are lowercase;
+// the replacement keeps the original's leading capital.
+var simplerWords = map[string]string{
+	"additionally":  "also",
+	"approximately": "about",
+	"commonly":      "often",
+	"conspicuous":   "easy to see",
+	"consume":       "eat",
+	"consumes":      "eats",
+	"distinctive":   "special",
+	"forage":        "look for food",
+	"forages":       "looks for food",
+	"foraging":      "looking for food",
+	"frequently":    "often",
+	"however":       "but",
+	"individuals":   "birds",
+	"inhabit":       "live in",
+	"inhabits":      "lives in",
+	"juveniles":     "young birds",
+	"numerous":      "many",
+	"plumage":       "coloring",
+	"predominantly": "mostly",
+	"primarily":     "mostly",
+	"principally":   "mostly",
+	"typically":     "usually",
+	"utilize":       "use",
+	"utilizes":      "uses",
+	"vocalization":  "sound",
+	"vocalizations": "sounds",
+}
+
+var (
+	// parentheticalPattern matches asides like "(Turdus migratorius)" or "(10 in)"
+	parentheticalPattern = regexp.MustCompile(`\s*\([^()]*\)`)
+	// clauseBreakPattern matches the joins a long sentence can be split at into two sentences
+	clauseBreakPattern = regexp.MustCompile(`\s*;\s*|,\s+(?:which|while|whereas|although)\s+`)
+	wordPattern        = regexp.MustCompile(`[A-Za-z]+`)
+)
+
+// ReadingGrade returns the Flesch-Kincaid grade level of a piece of text
+func ReadingGrade(text string) float64 {
+	sentences := len(splitSentences(text))
+	if sentences == 0 {
+		return 0
+	}
+	return readabilityGrade(strings.Fields(text), sentences)
+}
+
+// guideReadingGrade holds the configured grade level as float64 bits; zero means the default
+var guideReadingGrade atomic.Uint64
+
+// ConfigureReadingGrade sets the grade level Explorer's Guide facts are simplified to; a grade
+// of 0 or less restores the default
+func ConfigureReadingGrade(grade float64) {
+	if grade <= 0 {
+		grade = 0
+	}
+	guideReadingGrade.Store(math.Float64bits(grade))
+}
+
+// GuideReadingGrade returns the grade level Explorer's Guide facts are simplified to
+func GuideReadingGrade() float64 {
+	if grade := math.Float64frombits(guideReadingGrade.Load()); grade > 0 {
+		return grade
+	}
+	return defaultGuideReadingGrade
+}
+
+// SimplifySentence rewrites an encyclopedia sentence for young listeners: asides in brackets are
+// dropped, clauses joined by semicolons or ", which" become sentences of their own, and hard words
+// are swapped for simpler ones. Fragments too short to stand alone are left out.
+func SimplifySentence(sentence string) []string {
+	sentence = parentheticalPattern.ReplaceAllString(sentence, "")
+	sentence = strings.TrimRight(strings.TrimSpace(sentence), ".!?")
+
+	var simplified []string
+	for i, clause := range clauseBreakPattern.Split(sentence, -1) {
+		clause = strings.Trim(clause, " ,")
+		lower := strings.ToLower(clause)
+		// ", which is ..." describes the subject of the sentence before it
+		if i > 0 && strings.HasPrefix(lower, "is ") {
+			clause = "It " + clause
+		} else if i > 0 && strings.HasPrefix(lower, "are ") {
+			clause = "They " + clause
+		}
+		clause = simplerVocabulary(clause)
+		if len(strings.Fields(clause)) < minSimplifiedWords {
+			continue
+		}
+		simplified = append(simplified, capitalizeFirst(clause)+".")
+	}
+	return simplified
+}
+
+// simplerVocabulary swaps each word in simplerWords for its replacement
+func simplerVocabulary(text string) string {
+	return wordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		replacement, ok := simplerWords[strings.ToLower(word)]
+		if !ok {
+			return word
+		}
+		if unicode.IsUpper([]rune(word)[0]) {
+			return capitalizeFirst(replacement)
+		}
+		return replacement
+	})
+}
+
+func capitalizeFirst(text string) string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return text
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// readableSentences returns the sentence as-is when it is at or below the grade, and otherwise
+// the simplified sentences that are. A grade of 0 accepts every sentence.
+func readableSentences(sentence string, grade float64) []string {
+	if grade <= 0 || ReadingGrade(sentence) <= grade {
+		return []string{sentence}
+	}
+	var readable []string
+	for _, simplified := range SimplifySentence(sentence) {
+		if ReadingGrade(simplified) <= grade {
+			readable = append(readable, simplified)
+		}
+	}
+	return readable
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+	"github.com/callen/bird-song-explorer/pkg/macaulay"
+	"github.com/callen/bird-song-explorer/pkg/xenocanto"
+)
+
+// Recording filters: the bird song track must run at least this long, and Macaulay
+// assets need a community rating comparable to xeno-canto's "A" quality
+const (
+	minSongRecordingSeconds = 20
+	minMacaulayRating       = 4.0
+)
+
+// SongRecording is a bird recording from any provider
+type SongRecording struct {
+	Source          string `json:"source"`
+	ID              string `json:"id"`
+	URL             string `json:"url"`
+	Type            string `json:"type,omitempty"`
+	DurationSeconds int    `json:"duration_seconds"`
+	Attribution     string `json:"attribution"`
+}
+
+// RecordingSource provides top-quality recordings for a species
+type RecordingSource interface {
+	Name() string
+	TopRecordings(ctx context.Context, scientificName string) ([]SongRecording, error)
+}
+
+// RecordingSelector asks each source in turn for a top-quality recording that is long enough,
+// so a species xeno-canto has no usable "A" recording for can still get a song from Macaulay
+type RecordingSelector struct {
+	sources    []RecordingSource
+	minSeconds int
+	verifier   SongVerifier    // Optional check that the clip really features the species
+	taxonomy   *ebird.Taxonomy // Resolves common names to the scientific names sources search by
+}
+
+// NewRecordingSelector tries xeno-canto first, then the Macaulay Library when an eBird key
+// is available to resolve species codes
+func NewRecordingSelector(xenoCantoAPIKey, ebirdAPIKey string) *RecordingSelector {
+	sources := []RecordingSource{&xenoCantoSource{client: xenocanto.NewClient(xenoCantoAPIKey)}}
+	if ebirdAPIKey != "" {
+		sources = append(sources, &macaulaySource{
+			client: macaulay.NewClient(),
+			ebird:  ebird.NewClient(ebirdAPIKey),
+		})
+	}
+
+	return &RecordingSelector{
+		sources:    sources,
+		minSeconds: minSongRecordingSeconds,
+		taxonomy:   ebird.SharedTaxonomy(ebirdAPIKey),
+	}
+}
+
+// SetVerifier checks each candidate before it's selected; rejected recordings are skipped
+// in favor of the next candidate
+func (rs *RecordingSelector) SetVerifier(verifier SongVerifier) {
+	rs.verifier = verifier
+}
+
+// FindRecording returns the first qualifying recording, preferring songs over calls. A common
+// name is accepted too, and resolved to its scientific name through the eBird taxonomy.
+func (rs *RecordingSelector) FindRecording(ctx context.Context, name string) (*SongRecording, error) {
+	scientificName := rs.scientificName(name)
+
+	for _, source := range rs.sources {
+		recordings, err := source.TopRecordings(ctx, scientificName)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			log.Printf("[RECORDINGS] %s lookup failed for %s: %v", source.Name(), scientificName, err)
+			continue
+		}
+
+		candidates := rs.candidates(recordings)
+		for i := range candidates {
+			if rs.verified(ctx, candidates[i], scientificName) {
+				return &candidates[i], nil
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+		}
+		log.Printf("[RECORDINGS] %s has no verified top-quality recording of %s over %ds, trying next source",
+			source.Name(), scientificName, rs.minSeconds)
+	}
+
+	return nil, fmt.Errorf("no recording of %s at least %ds long", scientificName, rs.minSeconds)
+}
+
+// scientificName returns the scientific name for a species name, or the name unchanged when the
+// taxonomy doesn't know it
+func (rs *RecordingSelector) scientificName(name string) string {
+	if rs.taxonomy == nil {
+		return name
+	}
+	species, ok := rs.taxonomy.Resolve(name)
+	if !ok {
+		return name
+	}
+	if !strings.EqualFold(species.ScientificName, name) {
+		log.Printf("[RECORDINGS] Resolved %s to %s", name, species.ScientificName)
+	}
+	return species.ScientificName
+}
+
+// candidates returns the long-enough recordings, songs before calls, otherwise in source order
+func (rs *RecordingSelector) candidates(recordings []SongRecording) []SongRecording {
+	var songs, calls []SongRecording
+	for _, rec := range recordings {
+		if rec.DurationSeconds < rs.minSeconds {
+			continue
+		}
+		if strings.Contains(rec.Type, "song") {
+			songs = append(songs, rec)
+		} else {
+			calls = append(calls, rec)
+		}
+	}
+	return append(songs, calls...)
+}
+
+// verified runs the verifier on a candidate. Recordings that can't be checked are accepted, so an
+// unreachable analyzer doesn't leave a species without a song.
+func (rs *RecordingSelector) verified(ctx context.Context, recording SongRecording, scientificName string) bool {
+	if rs.verifier == nil {
+		return true
+	}
+
+	verdict, err := rs.verifier.Verify(ctx, recording, scientificName)
+	if err != nil {
+		log.Printf("[RECORDINGS] Could not verify %s, accepting it unverified: %v", recording.ID, err)
+		return true
+	}
+	if !verdict.Accepted {
+		log.Printf("[RECORDINGS] Rejected %s for %s: %s", recording.ID, scientificName, verdict.Reason)
+		return false
+	}
+	log.Printf("[RECORDINGS] Verified %s: %s", recording.ID, verdict.Reason)
+	return true
+}
+
+// xenoCantoSource returns only "A" quality xeno-canto recordings
+type xenoCantoSource struct {
+	client *xenocanto.Client
+}
+
+func (s *xenoCantoSource) Name() string {
+	return "xeno-canto"
+}
+
+func (s *xenoCantoSource) TopRecordings(ctx context.Context, scientificName string) ([]SongRecording, error) {
+	resp, err := s.client.SearchRecordings(ctx, scientificName, "A")
+	if err != nil {
+		return nil, err
+	}
+
+	var recordings []SongRecording
+	for _, rec := range resp.Recordings {
+		recordings = append(recordings, SongRecording{
+			Source:          s.Name(),
+			ID:              "XC" + rec.ID,
+			URL:             rec.File,
+			Type:            rec.Type,
+			DurationSeconds: xenocanto.ParseLength(rec.Length),
+			Attribution:     rec.Attribution,
+		})
+	}
+	return recordings, nil
+}
+
+// macaulaySource returns Macaulay Library recordings rated at least minMacaulayRating
+type macaulaySource struct {
+	client *macaulay.Client
+	ebird  *ebird.Client
+}
+
+func (s *macaulaySource) Name() string {
+	return "macaulay"
+}
+
+func (s *macaulaySource) TopRecordings(ctx context.Context, scientificName string) ([]SongRecording, error) {
+	speciesCode, err := s.ebird.FindSpeciesCode(scientificName)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.client.SearchRecordings(ctx, speciesCode, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	var recordings []SongRecording
+	for _, rec := range results {
+		if rec.RatingValue() < minMacaulayRating {
+			continue
+		}
+		recordings = append(recordings, SongRecording{
+			Source:          s.Name(),
+			ID:              "ML" + rec.AssetID.String(),
+			URL:             rec.MediaURL,
+			DurationSeconds: rec.DurationSeconds(),
+			Attribution:     rec.Attribution,
+		})
+	}
+	return recordings, nil
+}
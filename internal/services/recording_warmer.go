@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// RecordingWarmDays is how far ahead the warmer pre-caches recordings
+const RecordingWarmDays = 7
+
+// recordingCacheDir is where warmed recordings live in the asset store
+const recordingCacheDir = "recordings"
+
+// RecordingWarmer pre-downloads and normalizes the best recording of each species likely to be
+// needed in the coming days and stores it in the asset store, so card updates read it from
+// there instead of waiting on xeno-canto
+type RecordingWarmer struct {
+	store      AssetStore
+	recordings *RecordingSelector
+	normalizer *AudioNormalizer
+	trimmer    *SongTrimmer // Optional trimming of noisy lead-ins and tails
+	httpClient *http.Client
+}
+
+// NewRecordingWarmer creates a warmer storing recordings in store, normalized by normalizer
+// when one is given
+func NewRecordingWarmer(store AssetStore, recordings *RecordingSelector, normalizer *AudioNormalizer) *RecordingWarmer {
+	return &RecordingWarmer{
+		store:      store,
+		recordings: recordings,
+		normalizer: normalizer,
+		httpClient: recordingDownloadClient,
+	}
+}
+
+// SetTrimmer trims each recording to its bird activity before it's normalized and cached
+func (rw *RecordingWarmer) SetTrimmer(trimmer *SongTrimmer) {
+	rw.trimmer = trimmer
+}
+
+// recordingAssetNames returns the audio and metadata asset names for a species
+func recordingAssetNames(scientificName string) (audio string, metadata string) {
+	slug := strings.ToLower(strings.Join(strings.Fields(scientificName), "_"))
+	base := fmt.Sprintf("%s/%s", recordingCacheDir, slug)
+	return base + ".mp3", base + ".json"
+}
+
+// Cached returns a warmed recording and its audio, or false when the species hasn't been warmed.
+// A nil warmer has nothing cached.
+func (rw *RecordingWarmer) Cached(scientificName string) (*SongRecording, []byte, bool) {
+	if rw == nil || scientificName == "" {
+		return nil, nil, false
+	}
+	audioName, metadataName := recordingAssetNames(scientificName)
+
+	data, err := rw.store.ReadFile(metadataName)
+	if err != nil {
+		return nil, nil, false
+	}
+	var recording SongRecording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		slog.Warn("[RECORDING_WARMER] Ignoring unreadable cached recording", "species", scientificName, "error", err)
+		return nil, nil, false
+	}
+	audio, err := rw.store.ReadFile(audioName)
+	if err != nil {
+		return nil, nil, false
+	}
+	return &recording, audio, true
+}
+
+// Warm caches a recording for each species not cached yet and reports how many were added and
+// how many couldn't be
+func (rw *RecordingWarmer) Warm(ctx context.Context, scientificNames []string) (warmed int, failed int) {
+	seen := make(map[string]bool)
+	for _, name := range scientificNames {
+		if ctx.Err() != nil {
+			break
+		}
+		audioName, metadataName := recordingAssetNames(name)
+		if name == "" || seen[audioName] {
+			continue
+		}
+		seen[audioName] = true
+		if rw.store.Exists(metadataName) {
+			continue
+		}
+
+		if err := rw.warm(ctx, name, audioName, metadataName); err != nil {
+			slog.WarnContext(ctx, "[RECORDING_WARMER] Failed to cache recording", "species", name, "error", err)
+			failed++
+			continue
+		}
+		warmed++
+	}
+	return warmed, failed
+}
+
+// warm downloads, trims, normalizes, and stores one species' best recording. The metadata is written
+// last, so a species only counts as cached once its audio is in place.
+func (rw *RecordingWarmer) warm(ctx context.Context, scientificName string, audioName string, metadataName string) error {
+	recording, err := rw.recordings.FindRecording(ctx, scientificName)
+	if err != nil {
+		return err
+	}
+
+	audio, err := rw.download(ctx, recording.URL)
+	if err != nil {
+		return err
+	}
+	if rw.trimmer != nil {
+		if trimmed, err := rw.trimmer.Trim(audio); err != nil {
+			slog.Warn("[RECORDING_WARMER] Caching recording untrimmed", "species", scientificName, "error", err)
+		} else {
+			audio = trimmed
+		}
+	}
+	if rw.normalizer != nil {
+		if normalized, err := rw.normalizer.Normalize(audio); err != nil {
+			slog.Warn("[RECORDING_WARMER] Caching recording without normalization", "species", scientificName, "error", err)
+		} else {
+			audio = normalized
+		}
+	}
+
+	metadata, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+	if err := rw.store.WriteFile(audioName, audio); err != nil {
+		return fmt.Errorf("failed to store recording: %w", err)
+	}
+	if err := rw.store.WriteFile(metadataName, metadata); err != nil {
+		return fmt.Errorf("failed to store recording metadata: %w", err)
+	}
+	slog.Info("[RECORDING_WARMER] Cached recording", "species", scientificName, "source", recording.Source, "id", recording.ID, "bytes", len(audio))
+	return nil
+}
+
+// download fetches a recording
+func (rw *RecordingWarmer) download(ctx context.Context, url string) ([]byte, error) {
+	resp, err := httpx.Get(ctx, rw.httpClient, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("recording download returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Start warms the species returned by upcoming once a day at hour (UTC), an off-peak time well
+// before the morning card updates
+func (rw *RecordingWarmer) Start(hour int, upcoming func(ctx context.Context, now time.Time) []string) {
+	go func() {
+		for {
+			time.Sleep(time.Until(nextWarmRun(time.Now().UTC(), hour)))
+
+			ctx := context.Background()
+			start := time.Now()
+			names := upcoming(ctx, start.UTC())
+			warmed, failed := rw.Warm(ctx, names)
+			slog.Info("[RECORDING_WARMER] Warm run finished", "species", len(names), "warmed", warmed,
+				"failed", failed, "duration", time.Since(start).Round(time.Second))
+		}
+	}()
+}
+
+// nextWarmRun returns the next time after now at hour o'clock UTC
+func nextWarmRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+)
+
+// RegionAuto is the card or device region that picks a region pack from the listener's country
+const RegionAuto = "auto"
+
+// CandidateSpecies is a bird a region pack would like to feature
+type CandidateSpecies struct {
+	CommonName     string
+	ScientificName string
+}
+
+// RegionPack is a country or area's species pool settings. Its pool is the available birds tagged
+// with the pack's ID or named among its candidates; candidates without recorded audio are ignored
+// until they're added to the available birds.
+type RegionPack struct {
+	ID        string
+	Name      string
+	Continent string   // Pool used when none of the pack's birds are available
+	Countries []string // ISO 3166-1 alpha-2 codes and English names the pack is chosen for
+
+	// eBird region whose checklist the pool is filtered by ("GB", "JP"); empty skips the filter
+	EBirdRegion string
+
+	// Wikipedia editions to look birds up in, in order of preference
+	WikipediaLanguages []string
+
+	Candidates []CandidateSpecies
+}
+
+var europeanCandidates = []CandidateSpecies{
+	{CommonName: "Common Kingfisher", ScientificName: "Alcedo atthis"},
+	{CommonName: "Great Spotted Woodpecker", ScientificName: "Dendrocopos major"},
+	{CommonName: "Common Nightingale", ScientificName: "Luscinia megarhynchos"},
+	{CommonName: "Common Chaffinch", ScientificName: "Fringilla coelebs"},
+	{CommonName: "Great Tit", ScientificName: "Parus major"},
+	{CommonName: "Common Cuckoo", ScientificName: "Cuculus canorus"},
+	{CommonName: "White Stork", ScientificName: "Ciconia ciconia"},
+}
+
+// regionPacks are the built-in packs, most specific first so a country's own pack wins
+var regionPacks = []RegionPack{
+	{
+		ID:                 "uk",
+		Name:               "United Kingdom",
+		Continent:          "europe",
+		Countries:          []string{"GB", "United Kingdom", "UK", "Great Britain", "England", "Scotland", "Wales", "Northern Ireland"},
+		EBirdRegion:        "GB",
+		WikipediaLanguages: []string{"en"},
+		Candidates: []CandidateSpecies{
+			{CommonName: "European Robin", ScientificName: "Erithacus rubecula"},
+			{CommonName: "Eurasian Blackbird", ScientificName: "Turdus merula"},
+			{CommonName: "Eurasian Wren", ScientificName: "Troglodytes troglodytes"},
+			{CommonName: "Song Thrush", ScientificName: "Turdus philomelos"},
+			{CommonName: "Eurasian Skylark", ScientificName: "Alauda arvensis"},
+			{CommonName: "Common Kingfisher", ScientificName: "Alcedo atthis"},
+			{CommonName: "Great Spotted Woodpecker", ScientificName: "Dendrocopos major"},
+			{CommonName: "Atlantic Puffin", ScientificName: "Fratercula arctica"},
+		},
+	},
+	{
+		ID:                 "germany",
+		Name:               "Germany",
+		Continent:          "europe",
+		Countries:          []string{"DE", "Germany", "AT", "Austria"},
+		EBirdRegion:        "DE",
+		WikipediaLanguages: []string{"de", "en"},
+		Candidates:         europeanCandidates,
+	},
+	{
+		ID:                 "france",
+		Name:               "France",
+		Continent:          "europe",
+		Countries:          []string{"FR", "France", "BE", "Belgium"},
+		EBirdRegion:        "FR",
+		WikipediaLanguages: []string{"fr", "en"},
+		Candidates:         europeanCandidates,
+	},
+	{
+		ID:                 "spain",
+		Name:               "Spain",
+		Continent:          "europe",
+		Countries:          []string{"ES", "Spain"},
+		EBirdRegion:        "ES",
+		WikipediaLanguages: []string{"es", "en"},
+		Candidates: append([]CandidateSpecies{
+			{CommonName: "Eurasian Hoopoe", ScientificName: "Upupa epops"},
+			{CommonName: "European Bee-eater", ScientificName: "Merops apiaster"},
+		}, europeanCandidates...),
+	},
+	{
+		ID:        "europe",
+		Name:      "Europe",
+		Continent: "europe",
+		Countries: []string{
+			"IE", "Ireland", "NL", "Netherlands", "IT", "Italy", "PT", "Portugal", "CH", "Switzerland",
+			"DK", "Denmark", "SE", "Sweden", "NO", "Norway", "FI", "Finland", "IS", "Iceland",
+			"PL", "Poland", "CZ", "Czechia", "GR", "Greece",
+		},
+		WikipediaLanguages: []string{"en"},
+		Candidates:         europeanCandidates,
+	},
+	{
+		ID:                 "australia",
+		Name:               "Australia",
+		Continent:          "oceania",
+		Countries:          []string{"AU", "Australia"},
+		EBirdRegion:        "AU",
+		WikipediaLanguages: []string{"en"},
+		Candidates: []CandidateSpecies{
+			{CommonName: "Laughing Kookaburra", ScientificName: "Dacelo novaeguineae"},
+			{CommonName: "Australian Magpie", ScientificName: "Gymnorhina tibicen"},
+			{CommonName: "Superb Fairywren", ScientificName: "Malurus cyaneus"},
+			{CommonName: "Sulphur-crested Cockatoo", ScientificName: "Cacatua galerita"},
+			{CommonName: "Rainbow Lorikeet", ScientificName: "Trichoglossus moluccanus"},
+			{CommonName: "Superb Lyrebird", ScientificName: "Menura novaehollandiae"},
+		},
+	},
+	{
+		ID:                 "new_zealand",
+		Name:               "New Zealand",
+		Continent:          "oceania",
+		Countries:          []string{"NZ", "New Zealand"},
+		EBirdRegion:        "NZ",
+		WikipediaLanguages: []string{"en"},
+		Candidates: []CandidateSpecies{
+			{CommonName: "Brown Kiwi", ScientificName: "Apteryx mantelli"},
+			{CommonName: "Tui", ScientificName: "Prosthemadera novaeseelandiae"},
+			{CommonName: "New Zealand Bellbird", ScientificName: "Anthornis melanura"},
+			{CommonName: "Kea", ScientificName: "Nestor notabilis"},
+			{CommonName: "New Zealand Fantail", ScientificName: "Rhipidura fuliginosa"},
+		},
+	},
+	{
+		ID:                 "japan",
+		Name:               "Japan",
+		Continent:          "asia",
+		Countries:          []string{"JP", "Japan"},
+		EBirdRegion:        "JP",
+		WikipediaLanguages: []string{"ja", "en"},
+		Candidates: []CandidateSpecies{
+			{CommonName: "Japanese Bush Warbler", ScientificName: "Horornis diphone"},
+			{CommonName: "Warbling White-eye", ScientificName: "Zosterops japonicus"},
+			{CommonName: "Varied Tit", ScientificName: "Sittiparus varius"},
+			{CommonName: "Brown-eared Bulbul", ScientificName: "Hypsipetes amaurotis"},
+			{CommonName: "Red-crowned Crane", ScientificName: "Grus japonensis"},
+			{CommonName: "Common Kingfisher", ScientificName: "Alcedo atthis"},
+			{CommonName: "Great Spotted Woodpecker", ScientificName: "Dendrocopos major"},
+		},
+	},
+	{
+		ID:                 "brazil",
+		Name:               "Brazil",
+		Continent:          "south_america",
+		Countries:          []string{"BR", "Brazil"},
+		EBirdRegion:        "BR",
+		WikipediaLanguages: []string{"pt", "en"},
+		Candidates: []CandidateSpecies{
+			{CommonName: "Rufous Hornero", ScientificName: "Furnarius rufus"},
+			{CommonName: "Great Kiskadee", ScientificName: "Pitangus sulphuratus"},
+			{CommonName: "Rufous-bellied Thrush", ScientificName: "Turdus rufiventris"},
+			{CommonName: "Toco Toucan", ScientificName: "Ramphastos toco"},
+			{CommonName: "Hyacinth Macaw", ScientificName: "Anodorhynchus hyacinthinus"},
+			{CommonName: "Southern Lapwing", ScientificName: "Vanellus chilensis"},
+		},
+	},
+	{
+		ID:                 "north_america",
+		Name:               "North America",
+		Continent:          "north_america",
+		Countries:          []string{"US", "United States", "USA", "CA", "Canada", "MX", "Mexico"},
+		EBirdRegion:        "US",
+		WikipediaLanguages: []string{"en"},
+		Candidates: []CandidateSpecies{
+			{CommonName: "American Robin", ScientificName: "Turdus migratorius"},
+			{CommonName: "Northern Cardinal", ScientificName: "Cardinalis cardinalis"},
+			{CommonName: "Blue Jay", ScientificName: "Cyanocitta cristata"},
+			{CommonName: "Black-capped Chickadee", ScientificName: "Poecile atricapillus"},
+			{CommonName: "Mourning Dove", ScientificName: "Zenaida macroura"},
+			{CommonName: "Western Meadowlark", ScientificName: "Sturnella neglecta"},
+			{CommonName: "Bald Eagle", ScientificName: "Haliaeetus leucocephalus"},
+		},
+	},
+}
+
+// RegionPacks returns the built-in region packs
+func RegionPacks() []RegionPack {
+	return regionPacks
+}
+
+// RegionPackByID returns the pack with the given ID, or nil
+func RegionPackByID(id string) *RegionPack {
+	for i := range regionPacks {
+		if strings.EqualFold(regionPacks[i].ID, id) {
+			return &regionPacks[i]
+		}
+	}
+	return nil
+}
+
+// RegionPackForCountry returns the pack for a country code ("GB") or English name
+// ("United Kingdom"), or nil when the country has no pack
+func RegionPackForCountry(country string) *RegionPack {
+	country = strings.TrimSpace(country)
+	if country == "" {
+		return nil
+	}
+	for i := range regionPacks {
+		for _, name := range regionPacks[i].Countries {
+			if strings.EqualFold(name, country) {
+				return &regionPacks[i]
+			}
+		}
+	}
+	return nil
+}
+
+// hasCandidate reports whether the pack features the species
+func (p *RegionPack) hasCandidate(scientificName string) bool {
+	for _, candidate := range p.Candidates {
+		if strings.EqualFold(candidate.ScientificName, scientificName) {
+			return true
+		}
+	}
+	return false
+}
+
+// regionChecklistMaxAge is how long a region's checklist is reused; new country records are rare
+const regionChecklistMaxAge = 7 * 24 * time.Hour
+
+// RegionChecklists reports whether species have been recorded in an eBird region, so a pack never
+// features a bird its country doesn't have. Checklists are fetched on first use and kept for a week.
+type RegionChecklists struct {
+	client   *ebird.Client
+	taxonomy *ebird.Taxonomy
+
+	mu    sync.Mutex
+	lists map[string]regionChecklist
+}
+
+type regionChecklist struct {
+	speciesCodes map[string]bool
+	fetchedAt    time.Time
+}
+
+// NewRegionChecklists creates a checklist cache using the eBird API key
+func NewRegionChecklists(apiKey string) *RegionChecklists {
+	return &RegionChecklists{
+		client:   ebird.NewClient(apiKey),
+		taxonomy: ebird.SharedTaxonomy(apiKey),
+		lists:    make(map[string]regionChecklist),
+	}
+}
+
+// Contains reports whether the species is on the region's checklist. known is false when the
+// checklist or the species code can't be looked up, and callers then keep the bird.
+func (r *RegionChecklists) Contains(regionCode string, scientificName string) (present bool, known bool) {
+	species, ok := r.taxonomy.ByScientificName(scientificName)
+	if !ok {
+		return false, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, cached := r.lists[regionCode]
+	maxAge := regionChecklistMaxAge
+	if cached && list.speciesCodes == nil {
+		maxAge = time.Hour
+	}
+	if !cached || time.Since(list.fetchedAt) >= maxAge {
+		list = regionChecklist{fetchedAt: time.Now()}
+		// The checklist is shared by every caller, so one caller's cancellation shouldn't cut it short
+		codes, err := r.client.GetRegionSpeciesList(context.Background(), regionCode)
+		if err != nil {
+			log.Printf("[REGION_PACKS] Failed to fetch the %s checklist: %v", regionCode, err)
+		} else {
+			list.speciesCodes = make(map[string]bool, len(codes))
+			for _, code := range codes {
+				list.speciesCodes[code] = true
+			}
+		}
+		r.lists[regionCode] = list
+	}
+
+	if list.speciesCodes == nil {
+		return false, false
+	}
+	return list.speciesCodes[species.SpeciesCode], true
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// RolloutTarget is a card and one timezone its listeners are in
+type RolloutTarget struct {
+	CardID   string
+	Timezone string // IANA name, e.g. "America/New_York"
+}
+
+// DueRollout is a target whose local date has moved past its last update
+type DueRollout struct {
+	CardID    string    `json:"card_id"`
+	Timezone  string    `json:"timezone"`
+	LocalDate string    `json:"local_date"`
+	LocalTime time.Time `json:"-"`
+}
+
+// RolloutScheduler tracks the local date each card was last updated for in each timezone, so the
+// cron endpoint can push a fresh bird as every listener's midnight passes instead of on the
+// server's clock. State is persisted to a JSON file so restarts don't repeat a day's update.
+type RolloutScheduler struct {
+	mu          sync.Mutex
+	path        string
+	lastUpdated map[string]string // "cardID|timezone" -> local date (YYYY-MM-DD)
+}
+
+// NewRolloutScheduler loads rollout state from path, defaulting to data/rollout_state.json
+func NewRolloutScheduler(path string) *RolloutScheduler {
+	if path == "" {
+		path = "data/rollout_state.json"
+	}
+
+	scheduler := &RolloutScheduler{
+		path:        path,
+		lastUpdated: make(map[string]string),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &scheduler.lastUpdated); err != nil {
+			log.Printf("[ROLLOUT] Failed to parse %s, starting empty: %v", path, err)
+			scheduler.lastUpdated = make(map[string]string)
+		}
+	}
+
+	return scheduler
+}
+
+func rolloutKey(cardID, timezone string) string {
+	return cardID + "|" + timezone
+}
+
+// Due returns the targets whose local date at now differs from the date they were last updated for.
+// Targets with an unknown timezone are treated as UTC.
+func (rs *RolloutScheduler) Due(targets []RolloutTarget, now time.Time) []DueRollout {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var due []DueRollout
+	for _, target := range targets {
+		loc, err := time.LoadLocation(target.Timezone)
+		if err != nil {
+			log.Printf("[ROLLOUT] Unknown timezone %q for card %s, using UTC", target.Timezone, target.CardID)
+			loc = time.UTC
+		}
+
+		localTime := now.In(loc)
+		localDate := localTime.Format("2006-01-02")
+		if rs.lastUpdated[rolloutKey(target.CardID, target.Timezone)] == localDate {
+			continue
+		}
+
+		due = append(due, DueRollout{
+			CardID:    target.CardID,
+			Timezone:  target.Timezone,
+			LocalDate: localDate,
+			LocalTime: localTime,
+		})
+	}
+
+	// Earliest local dates first, so a card never steps backwards within one run
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].LocalDate < due[j].LocalDate
+	})
+	return due
+}
+
+// CardUpdatedOn reports whether any of a card's timezones has already been updated for date,
+// in which case the card already carries that date's bird
+func (rs *RolloutScheduler) CardUpdatedOn(cardID string, date string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	prefix := cardID + "|"
+	for key, updated := range rs.lastUpdated {
+		if updated == date && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkUpdated records that a card has been updated for a timezone's local date
+func (rs *RolloutScheduler) MarkUpdated(cardID string, timezone string, date string) {
+	rs.mu.Lock()
+	rs.lastUpdated[rolloutKey(cardID, timezone)] = date
+	rs.mu.Unlock()
+
+	if err := rs.save(); err != nil {
+		log.Printf("[ROLLOUT] Failed to save rollout state: %v", err)
+	}
+}
+
+// Stats returns the number of tracked card/timezone pairs
+func (rs *RolloutScheduler) Stats() map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return map[string]interface{}{
+		"tracked_targets": len(rs.lastUpdated),
+	}
+}
+
+// save writes the rollout state to disk atomically
+func (rs *RolloutScheduler) save() error {
+	rs.mu.Lock()
+	data, err := json.MarshalIndent(rs.lastUpdated, "", "  ")
+	rs.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout state: %w", err)
+	}
+	return filex.AtomicWriteFile(rs.path, data, 0644)
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Moderation categories of the built-in rules
+const (
+	ModerationGore   = "gore"   // Graphic predation and injury
+	ModerationMating = "mating" // Mating and reproductive detail
+)
+
+// ModerationRule flags sentences for a category, by regular expression or by whole-word keywords
+type ModerationRule struct {
+	Category string   `json:"category"`
+	Pattern  string   `json:"pattern,omitempty"`  // Matched case-insensitively
+	Keywords []string `json:"keywords,omitempty"` // Matched case-insensitively as whole words
+}
+
+// defaultModerationRules cover the sentences parents have flagged in Wikipedia-sourced scripts
+var defaultModerationRules = []ModerationRule{
+	{Category: ModerationGore, Keywords: []string{
+		"entrails", "intestines", "innards", "guts", "gutted", "disembowel", "disembowels", "disembowelled",
+		"eviscerate", "eviscerates", "eviscerated", "decapitate", "decapitates", "decapitated",
+		"dismember", "dismembers", "dismembered", "bloody", "bloodied", "gore", "gory", "mutilated",
+		"siblicide", "infanticide", "cannibalism", "cannibalistic",
+	}},
+	{Category: ModerationGore, Pattern: `\b(tears?|tearing|tore|rips?|ripping|ripped) (it|them|its prey|the prey|\w+) (apart|to pieces)\b`},
+	{Category: ModerationMating, Keywords: []string{
+		"copulate", "copulates", "copulated", "copulating", "copulation", "copulatory", "coitus",
+		"cloaca", "cloacal", "sperm", "semen", "genitals", "genitalia", "penis", "phallus",
+		"promiscuous", "promiscuity", "extra-pair",
+	}},
+	{Category: ModerationMating, Pattern: `\bforced (copulation|mating)\b`},
+}
+
+// RemovedSentence is a sentence moderation took out of a script
+type RemovedSentence struct {
+	Text     string `json:"text"`
+	Category string `json:"category"`
+}
+
+// ModerationHook is an optional second opinion on sentences the rules passed, such as a
+// Perspective API or LLM classifier. It returns the category a sentence is flagged for, or ""
+// to keep it.
+type ModerationHook interface {
+	Flag(ctx context.Context, sentence string) (string, error)
+}
+
+type moderationPattern struct {
+	category string
+	pattern  *regexp.Regexp
+}
+
+// ScriptModerator removes sentences that aren't suitable for children from scripts before
+// they're spoken, logging each one so the rules can be tuned
+type ScriptModerator struct {
+	patterns []moderationPattern
+	hook     ModerationHook
+
+	mu       sync.Mutex
+	verdicts map[string]string // Hook verdicts by sentence, so cached scripts don't call it again
+}
+
+var (
+	sharedModerator     *ScriptModerator
+	sharedModeratorOnce sync.Once
+)
+
+// ConfigureModeration sets up the process-wide moderator: the built-in rules extended by the
+// JSON list at rulesPath, if set, with the Perspective API as a hook when perspectiveAPIKey is
+// set. Call it at startup, before anything narrates; later calls have no effect.
+func ConfigureModeration(rulesPath string, perspectiveAPIKey string, perspectiveThreshold float64) {
+	sharedModeratorOnce.Do(func() {
+		sharedModerator = NewScriptModerator(rulesPath)
+		if perspectiveAPIKey != "" {
+			if perspectiveThreshold <= 0 || perspectiveThreshold > 1 {
+				perspectiveThreshold = defaultPerspectiveThreshold
+			}
+			sharedModerator.SetHook(NewPerspectiveModerator(perspectiveAPIKey, perspectiveThreshold))
+			log.Printf("[MODERATION] Checking sentences with the Perspective API (threshold %.2f)", perspectiveThreshold)
+		}
+	})
+}
+
+// SharedModerator returns the process-wide moderator, with only the built-in rules when
+// ConfigureModeration wasn't called
+func SharedModerator() *ScriptModerator {
+	sharedModeratorOnce.Do(func() {
+		sharedModerator = NewScriptModerator("")
+	})
+	return sharedModerator
+}
+
+// NewScriptModerator loads the built-in rules plus a JSON list of rules from path, which are
+// added to the built-in ones. A file that can't be read leaves the built-in rules in place, and
+// a rule that doesn't compile is skipped.
+func NewScriptModerator(path string) *ScriptModerator {
+	sm := &ScriptModerator{verdicts: make(map[string]string)}
+	for _, rule := range defaultModerationRules {
+		sm.add(rule)
+	}
+
+	if path != "" {
+		if rules, err := loadModerationRules(path); err != nil {
+			log.Printf("[MODERATION] %v, using built-in rules", err)
+		} else {
+			for _, rule := range rules {
+				sm.add(rule)
+			}
+			log.Printf("[MODERATION] Loaded %d rules from %s", len(rules), path)
+		}
+	}
+	return sm
+}
+
+// loadModerationRules reads a JSON list of rules
+func loadModerationRules(path string) ([]ModerationRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moderation rules %s: %w", path, err)
+	}
+	var rules []ModerationRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// add compiles a rule's pattern and keywords
+func (sm *ScriptModerator) add(rule ModerationRule) {
+	if rule.Category == "" {
+		rule.Category = "custom"
+	}
+
+	var expressions []string
+	if rule.Pattern != "" {
+		expressions = append(expressions, rule.Pattern)
+	}
+	var words []string
+	for _, keyword := range rule.Keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			words = append(words, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(words) > 0 {
+		expressions = append(expressions, `\b(`+strings.Join(words, "|")+`)\b`)
+	}
+	if len(expressions) == 0 {
+		log.Printf("[MODERATION] Skipping %s rule with no pattern or keywords", rule.Category)
+		return
+	}
+
+	for _, expression := range expressions {
+		pattern, err := regexp.Compile("(?i)" + expression)
+		if err != nil {
+			log.Printf("[MODERATION] Skipping %s rule %q: %v", rule.Category, expression, err)
+			continue
+		}
+		sm.patterns = append(sm.patterns, moderationPattern{category: rule.Category, pattern: pattern})
+	}
+}
+
+// SetHook adds a classifier that checks the sentences the rules pass
+func (sm *ScriptModerator) SetHook(hook ModerationHook) {
+	sm.hook = hook
+}
+
+// Moderate returns text without its flagged sentences, and the sentences that were removed.
+// Text with nothing flagged comes back unchanged. A hook that fails keeps the sentence, so an
+// outage of the classifier doesn't silence a card.
+func (sm *ScriptModerator) Moderate(ctx context.Context, text string) (string, []RemovedSentence) {
+	if sm == nil {
+		return text, nil
+	}
+
+	var removed []RemovedSentence
+	for _, speech := range SplitAtCallMarkers(text) {
+		for _, sentence := range splitSentences(speech) {
+			category := sm.flag(ctx, sentence)
+			if category == "" {
+				continue
+			}
+			text = strings.Replace(text, sentence, "", 1)
+			removed = append(removed, RemovedSentence{Text: sentence, Category: category})
+			log.Printf("[MODERATION] Removed %s sentence: %q", category, sentence)
+		}
+	}
+	if len(removed) > 0 {
+		text = strings.TrimSpace(strings.Join(strings.Fields(text), " "))
+	}
+	return text, removed
+}
+
+// ModerateTranscript removes flagged sentences from a transcript's script and sentence list,
+// recording them on the transcript
+func (sm *ScriptModerator) ModerateTranscript(ctx context.Context, transcript *ScriptTranscript) {
+	if sm == nil || transcript == nil {
+		return
+	}
+
+	script, removed := sm.Moderate(ctx, transcript.Script)
+	if len(removed) == 0 {
+		return
+	}
+	flagged := make(map[string]bool, len(removed))
+	for _, sentence := range removed {
+		flagged[sentence.Text] = true
+	}
+	kept := transcript.Sentences[:0:0]
+	for _, sentence := range transcript.Sentences {
+		if !flagged[sentence.Text] {
+			kept = append(kept, sentence)
+		}
+	}
+
+	transcript.Script = script
+	transcript.Sentences = kept
+	transcript.Removed = append(transcript.Removed, removed...)
+}
+
+// flag returns the category a sentence is flagged for, or "" when it's fine
+func (sm *ScriptModerator) flag(ctx context.Context, sentence string) string {
+	for _, rule := range sm.patterns {
+		if rule.pattern.MatchString(sentence) {
+			return rule.category
+		}
+	}
+	if sm.hook == nil {
+		return ""
+	}
+
+	sm.mu.Lock()
+	category, ok := sm.verdicts[sentence]
+	sm.mu.Unlock()
+	if ok {
+		return category
+	}
+
+	category, err := sm.hook.Flag(ctx, sentence)
+	if err != nil {
+		log.Printf("[MODERATION] Hook failed, keeping sentence: %v", err)
+		return ""
+	}
+	sm.mu.Lock()
+	sm.verdicts[sentence] = category
+	sm.mu.Unlock()
+	return category
+}
+
+// moderatedFactGenerator moderates the scripts and transcripts of the generator it wraps
+type moderatedFactGenerator struct {
+	FactGenerator
+	moderator *ScriptModerator
+}
+
+func (g moderatedFactGenerator) GenerateFactScript(ctx context.Context, bird *models.Bird, latitude, longitude float64) string {
+	script, _ := g.moderator.Moderate(ctx, g.FactGenerator.GenerateFactScript(ctx, bird, latitude, longitude))
+	return script
+}
+
+func (g moderatedFactGenerator) GenerateFactTranscript(ctx context.Context, bird *models.Bird, latitude, longitude float64) *ScriptTranscript {
+	transcript := g.FactGenerator.GenerateFactTranscript(ctx, bird, latitude, longitude)
+	g.moderator.ModerateTranscript(ctx, transcript)
+	return transcript
+}
+
+const (
+	perspectiveURL              = "https://commentanalyzer.googleapis.com/v1alpha1/comments:analyze"
+	defaultPerspectiveThreshold = 0.7
+)
+
+// perspectiveAttributes maps the Perspective attributes checked to moderation categories
+var perspectiveAttributes = map[string]string{
+	"SEXUALLY_EXPLICIT": ModerationMating,
+	"THREAT":            ModerationGore,
+	"TOXICITY":          "toxicity",
+}
+
+// PerspectiveModerator flags sentences the Perspective API scores at or above a threshold
+type PerspectiveModerator struct {
+	apiKey    string
+	threshold float64
+	client    *http.Client
+}
+
+// NewPerspectiveModerator creates a hook scoring sentences with the Perspective API
+func NewPerspectiveModerator(apiKey string, threshold float64) *PerspectiveModerator {
+	return &PerspectiveModerator{
+		apiKey:    apiKey,
+		threshold: threshold,
+		client:    httpx.NewClient(httpx.Options{Timeout: 10 * time.Second}),
+	}
+}
+
+// Flag returns the category of the highest-scoring attribute over the threshold
+func (p *PerspectiveModerator) Flag(ctx context.Context, sentence string) (string, error) {
+	attributes := make(map[string]struct{}, len(perspectiveAttributes))
+	for attribute := range perspectiveAttributes {
+		attributes[attribute] = struct{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"comment":             map[string]string{"text": sentence},
+		"languages":           []string{"en"},
+		"requestedAttributes": attributes,
+		"doNotStore":          true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Perspective request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, perspectiveURL+"?key="+p.apiKey, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Perspective request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Perspective API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("perspective API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AttributeScores map[string]struct {
+			SummaryScore struct {
+				Value float64 `json:"value"`
+			} `json:"summaryScore"`
+		} `json:"attributeScores"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode Perspective response: %w", err)
+	}
+
+	category, highest := "", p.threshold
+	for attribute, score := range result.AttributeScores {
+		if score.SummaryScore.Value >= highest {
+			category, highest = perspectiveAttributes[attribute], score.SummaryScore.Value
+		}
+	}
+	return category, nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// VoiceSpeedProfiles are TTS speaking rates in words per minute.
+// "kids" is the slowed, pause-heavy pacing used for Bird Explorer's Guide narration.
+var VoiceSpeedProfiles = map[string]float64{
+	"kids":   130,
+	"normal": 150,
+	"fast":   170,
+}
+
+// pauseSeconds is how long TTS holds on a ". . ." pause marker
+const pauseSeconds = 0.75
+
+// maxScriptStatsCache bounds the memoized results; the cache is cleared when it fills
+const maxScriptStatsCache = 256
+
+var (
+	sentencePattern = regexp.MustCompile(`[.!?]+(\s|$)`)
+	pausePattern    = regexp.MustCompile(`\.\s\.\s\.`)
+	sectionPattern  = regexp.MustCompile(`\n\s*\n|\s*\.\s\.\s\.\s*`)
+)
+
+// SectionStats describes one section of a script
+type SectionStats struct {
+	Index            int                `json:"index"`
+	Preview          string             `json:"preview"`
+	WordCount        int                `json:"word_count"`
+	EstimatedSeconds map[string]float64 `json:"estimated_seconds"`
+}
+
+// ScriptStats summarizes a narration script's length and reading level
+type ScriptStats struct {
+	WordCount        int                `json:"word_count"`
+	SentenceCount    int                `json:"sentence_count"`
+	PauseCount       int                `json:"pause_count"`
+	ReadabilityGrade float64            `json:"readability_grade"` // Flesch-Kincaid grade level
+	EstimatedSeconds map[string]float64 `json:"estimated_seconds"` // Keyed by VoiceSpeedProfiles name
+	Sections         []SectionStats     `json:"sections"`
+}
+
+var (
+	scriptStatsMu    sync.Mutex
+	scriptStatsCache = make(map[string]*ScriptStats)
+)
+
+// EstimateScript computes word count, reading time per voice speed, readability grade,
+// and a per-section breakdown. Results are memoized by script content.
+func EstimateScript(script string) *ScriptStats {
+	sum := sha256.Sum256([]byte(script))
+	key := hex.EncodeToString(sum[:])
+
+	scriptStatsMu.Lock()
+	if stats, ok := scriptStatsCache[key]; ok {
+		scriptStatsMu.Unlock()
+		return stats
+	}
+	scriptStatsMu.Unlock()
+
+	stats := computeScriptStats(script)
+
+	scriptStatsMu.Lock()
+	if len(scriptStatsCache) >= maxScriptStatsCache {
+		scriptStatsCache = make(map[string]*ScriptStats)
+	}
+	scriptStatsCache[key] = stats
+	scriptStatsMu.Unlock()
+
+	return stats
+}
+
+// EstimateSeconds returns the reading time for a script at a voice speed profile,
+// falling back to "normal" for unknown profiles
+func EstimateSeconds(script string, profile string) float64 {
+	stats := EstimateScript(script)
+	if seconds, ok := stats.EstimatedSeconds[profile]; ok {
+		return seconds
+	}
+	return stats.EstimatedSeconds["normal"]
+}
+
+func computeScriptStats(script string) *ScriptStats {
+	words := strings.Fields(script)
+	pauses := len(pausePattern.FindAllString(script, -1))
+
+	// Pause markers end in periods, so don't count them as sentences
+	sentences := len(sentencePattern.FindAllString(pausePattern.ReplaceAllString(script, " "), -1))
+	if sentences == 0 && len(words) > 0 {
+		sentences = 1
+	}
+
+	stats := &ScriptStats{
+		WordCount:        countWords(words),
+		SentenceCount:    sentences,
+		PauseCount:       pauses,
+		ReadabilityGrade: readabilityGrade(words, sentences),
+		EstimatedSeconds: estimateSeconds(countWords(words), pauses),
+	}
+
+	index := 0
+	for _, section := range sectionPattern.Split(script, -1) {
+		sectionWords := strings.Fields(section)
+		if countWords(sectionWords) == 0 {
+			continue
+		}
+		index++
+		stats.Sections = append(stats.Sections, SectionStats{
+			Index:            index,
+			Preview:          sectionPreview(sectionWords),
+			WordCount:        countWords(sectionWords),
+			EstimatedSeconds: estimateSeconds(countWords(sectionWords), 0),
+		})
+	}
+
+	return stats
+}
+
+// countWords counts tokens that contain at least one letter or digit
+func countWords(words []string) int {
+	count := 0
+	for _, word := range words {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func estimateSeconds(wordCount int, pauses int) map[string]float64 {
+	estimates := make(map[string]float64, len(VoiceSpeedProfiles))
+	for profile, wpm := range VoiceSpeedProfiles {
+		seconds := float64(wordCount)/wpm*60 + float64(pauses)*pauseSeconds
+		estimates[profile] = math.Round(seconds*10) / 10
+	}
+	return estimates
+}
+
+// readabilityGrade computes the Flesch-Kincaid grade level
+func readabilityGrade(words []string, sentences int) float64 {
+	wordCount := countWords(words)
+	if wordCount == 0 || sentences == 0 {
+		return 0
+	}
+
+	syllables := 0
+	for _, word := range words {
+		syllables += countSyllables(word)
+	}
+
+	grade := 0.39*float64(wordCount)/float64(sentences) + 11.8*float64(syllables)/float64(wordCount) - 15.59
+	if grade < 0 {
+		grade = 0
+	}
+	return math.Round(grade*10) / 10
+}
+
+// countSyllables approximates syllables by counting vowel groups
+func countSyllables(word string) int {
+	word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }))
+	if word == "" {
+		return 0
+	}
+
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+
+	// Silent trailing "e" ("whistle" has two syllables, not three)
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+func sectionPreview(words []string) string {
+	if len(words) <= 8 {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:8], " ") + "..."
+}
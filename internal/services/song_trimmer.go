@@ -0,0 +1,305 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Seconds this far below the recording's loudest second are wind, handling noise, or silence
+	songTrimQuietDB = 25.0
+
+	// Cuts shorter than this aren't worth a re-encode
+	songTrimMinCutSeconds = 2.0
+
+	// Short recordings are looped with crossfades this long (at most a quarter of the recording)
+	songCrossfadeSeconds = 1.5
+
+	// Recordings needing more copies than this to reach the minimum are too short to loop
+	maxSongLoops = 20
+)
+
+// SongTrimmer cleans up field recordings before they're cached: it cuts the wind, handling noise,
+// and talking that open and close many xeno-canto clips, then clamps what's left to a window
+// centered on the loudest bird activity. Recordings shorter than MinSeconds are looped with
+// crossfades up to it.
+type SongTrimmer struct {
+	MinSeconds float64
+	MaxSeconds float64
+}
+
+// NewSongTrimmer creates a trimmer keeping between minSeconds and maxSeconds of each recording
+func NewSongTrimmer(minSeconds, maxSeconds float64) *SongTrimmer {
+	return &SongTrimmer{MinSeconds: minSeconds, MaxSeconds: maxSeconds}
+}
+
+// Trim returns the recording cut to its bird activity, or looped up to MinSeconds when it's
+// shorter. Recordings that are already all activity and within range are returned unchanged, as
+// is the original audio when the analysis fails. Without ffmpeg, short recordings are still
+// looped but nothing is trimmed.
+func (st *SongTrimmer) Trim(audio []byte) ([]byte, error) {
+	caps := GetFFmpegCapabilities()
+	if !caps.Probe || !caps.Mixing {
+		slog.Warn("[SONG_TRIMMER] ffmpeg unavailable, leaving recording untrimmed")
+		return st.loopNative(audio)
+	}
+
+	tempDir := os.TempDir()
+	stamp := time.Now().UnixNano()
+	inputFile := filepath.Join(tempDir, fmt.Sprintf("trim_in_%d.mp3", stamp))
+	outputFile := filepath.Join(tempDir, fmt.Sprintf("trim_out_%d.mp3", stamp))
+
+	if err := os.WriteFile(inputFile, audio, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write recording file: %w", err)
+	}
+	defer os.Remove(inputFile)
+	defer os.Remove(outputFile)
+
+	duration := probeDuration(inputFile)
+	if duration <= 0 {
+		slog.Warn("[SONG_TRIMMER] Could not read recording duration, leaving untrimmed")
+		return audio, nil
+	}
+	energy, err := energyProfile(inputFile)
+	if err != nil || len(energy) == 0 {
+		slog.Warn("[SONG_TRIMMER] Energy analysis failed, leaving untrimmed", "error", err)
+		return audio, nil
+	}
+
+	var cmd *exec.Cmd
+	start, length := st.window(energy, duration)
+	switch {
+	case length < st.MinSeconds:
+		copies := songLoopCopies(duration, songCrossfade(duration), st.MinSeconds)
+		if copies > maxSongLoops {
+			slog.Warn("[SONG_TRIMMER] Recording too short to loop, leaving unchanged", "seconds", duration, "min_seconds", st.MinSeconds)
+			return audio, nil
+		}
+		slog.Info("[SONG_TRIMMER] Looping short recording", "seconds", duration, "min_seconds", st.MinSeconds, "copies", copies)
+		// An extra copy covers encoder padding, so the cut always lands inside the audio
+		cmd = st.loopCommand(inputFile, outputFile, duration, copies+1)
+	case start < songTrimMinCutSeconds && duration-start-length < songTrimMinCutSeconds:
+		return audio, nil
+	default:
+		slog.Info("[SONG_TRIMMER] Trimming recording to its bird activity", "seconds", duration, "start", start, "length", length)
+		cmd = trimCommand(inputFile, outputFile, start, length)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		slog.Error("[SONG_TRIMMER] ffmpeg failed", "error", err, "stderr", stderr.String())
+		return audio, nil
+	}
+
+	trimmed, err := os.ReadFile(outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trimmed recording: %w", err)
+	}
+	return trimmed, nil
+}
+
+// trimCommand cuts length seconds starting at start, with short fades at both ends
+func trimCommand(inputFile, outputFile string, start, length float64) *exec.Cmd {
+	fade := math.Min(1.5, length/4)
+	return exec.Command(ffmpegBinary(),
+		"-ss", fmt.Sprintf("%.2f", start),
+		"-t", fmt.Sprintf("%.2f", length),
+		"-i", inputFile,
+		"-af", fmt.Sprintf("afade=t=in:st=0:d=0.5,afade=t=out:st=%.2f:d=%.2f", length-fade, fade),
+		"-c:a", "libmp3lame",
+		"-b:a", "192k",
+		"-y", outputFile,
+	)
+}
+
+// loopCommand chains copies of the recording with crossfades and cuts the result at MinSeconds
+func (st *SongTrimmer) loopCommand(inputFile, outputFile string, duration float64, copies int) *exec.Cmd {
+	crossfade := songCrossfade(duration)
+	args := []string{}
+	for i := 0; i < copies; i++ {
+		args = append(args, "-i", inputFile)
+	}
+
+	var filter strings.Builder
+	previous := "0:a"
+	for i := 1; i < copies; i++ {
+		label := fmt.Sprintf("x%d", i)
+		fmt.Fprintf(&filter, "[%s][%d:a]acrossfade=d=%.2f[%s];", previous, i, crossfade, label)
+		previous = label
+	}
+	fmt.Fprintf(&filter, "[%s]atrim=0:%.2f,afade=t=out:st=%.2f:d=1[out]", previous, st.MinSeconds, st.MinSeconds-1)
+
+	args = append(args,
+		"-filter_complex", filter.String(),
+		"-map", "[out]",
+		"-c:a", "libmp3lame",
+		"-b:a", "192k",
+		"-y", outputFile,
+	)
+	return exec.Command(ffmpegBinary(), args...)
+}
+
+// loopNative loops a short recording with the pure-Go processor, which can't overlap audio:
+// each copy fades out and the next fades in, and whole copies are kept so the song isn't cut
+// off mid-phrase. Recordings it can't read or that don't need looping are returned unchanged.
+func (st *SongTrimmer) loopNative(audio []byte) ([]byte, error) {
+	processor := &NativeAudioProcessor{}
+	duration, err := processor.Duration(audio)
+	if err != nil || duration <= 0 || duration >= st.MinSeconds {
+		return audio, nil
+	}
+	copies := songLoopCopies(duration, 0, st.MinSeconds)
+	if copies > maxSongLoops {
+		slog.Warn("[SONG_TRIMMER] Recording too short to loop, leaving unchanged", "seconds", duration, "min_seconds", st.MinSeconds)
+		return audio, nil
+	}
+	slog.Info("[SONG_TRIMMER] Looping short recording", "seconds", duration, "min_seconds", st.MinSeconds, "copies", copies, "processor", processor.Name())
+
+	fade := songCrossfade(duration) / 2
+	segments := make([]AudioSegment, copies)
+	for i := range segments {
+		segments[i] = AudioSegment{Label: fmt.Sprintf("loop %d", i+1), Audio: audio, FadeIn: fade, FadeOut: fade}
+	}
+	segments[0].FadeIn = 0
+	segments[copies-1].FadeOut = 1
+
+	looped, _, err := AssembleSegments(processor, segments)
+	if err != nil {
+		slog.Warn("[SONG_TRIMMER] Looping failed, leaving recording unchanged", "error", err)
+		return audio, nil
+	}
+	return looped, nil
+}
+
+// songCrossfade is how long looped copies of a recording overlap
+func songCrossfade(duration float64) float64 {
+	return math.Min(songCrossfadeSeconds, duration/4)
+}
+
+// songLoopCopies is how many copies of a recording, each overlapping the last by overlap
+// seconds, it takes to last minSeconds
+func songLoopCopies(duration, overlap, minSeconds float64) int {
+	if duration >= minSeconds {
+		return 1
+	}
+	return int(math.Ceil((minSeconds - overlap) / (duration - overlap)))
+}
+
+// window picks the part of the recording to keep from its per-second energy: the span between
+// the first and last seconds of activity, widened to MinSeconds around its middle when short, or
+// narrowed to its loudest MaxSeconds when long
+func (st *SongTrimmer) window(energy []float64, duration float64) (start float64, length float64) {
+	secondsPerReading := duration / float64(len(energy))
+
+	peak := 0.0
+	for _, e := range energy {
+		peak = math.Max(peak, e)
+	}
+	if peak == 0 {
+		return 0, duration
+	}
+	threshold := peak * math.Pow(10, -songTrimQuietDB/10)
+
+	first, last := -1, -1
+	for i, e := range energy {
+		if e >= threshold {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	activeStart := float64(first) * secondsPerReading
+	activeLength := float64(last-first+1) * secondsPerReading
+
+	switch {
+	case activeLength > st.MaxSeconds:
+		windowSize := int(st.MaxSeconds / secondsPerReading)
+		offset := loudestWindow(energy[first:last+1], windowSize)
+		return float64(first+offset) * secondsPerReading, st.MaxSeconds
+	case activeLength < st.MinSeconds:
+		length = math.Min(st.MinSeconds, duration)
+		start = activeStart + activeLength/2 - length/2
+		return math.Max(0, math.Min(start, duration-length)), length
+	default:
+		return activeStart, math.Min(activeLength, duration-activeStart)
+	}
+}
+
+// energyProfile measures an audio file's acoustic energy (linear power), one reading per ~second
+// of audio (at 44.1kHz)
+func energyProfile(inputFile string) ([]float64, error) {
+	cmd := exec.Command(ffmpegBinary(),
+		"-i", inputFile,
+		"-af", "asetnsamples=n=44100,astats=metadata=1:reset=1,ametadata=print:key=lavfi.astats.Overall.RMS_level:file=-",
+		"-f", "null", "-",
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var energy []float64
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, "RMS_level=")
+		if idx < 0 {
+			continue
+		}
+		level, err := strconv.ParseFloat(strings.TrimSpace(line[idx+len("RMS_level="):]), 64)
+		if err != nil || math.IsInf(level, 0) {
+			level = -120
+		}
+		energy = append(energy, math.Pow(10, level/10))
+	}
+	return energy, nil
+}
+
+// loudestWindow returns the index where the windowSize readings with the most energy start
+func loudestWindow(energy []float64, windowSize int) int {
+	best, bestStart := 0.0, 0
+	current := 0.0
+	for i, e := range energy {
+		current += e
+		if i >= windowSize {
+			current -= energy[i-windowSize]
+		}
+		if i >= windowSize-1 && current > best {
+			best = current
+			bestStart = i - windowSize + 1
+		}
+	}
+	return bestStart
+}
+
+// probeDuration returns an audio file's duration in seconds, or 0 if it can't be read
+func probeDuration(audioFile string) float64 {
+	cmd := exec.Command(ffprobeBinary(),
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		audioFile,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
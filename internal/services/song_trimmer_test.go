@@ -0,0 +1,85 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSongLoopCopiesLastTheMinimum(t *testing.T) {
+	tests := []struct {
+		duration, overlap, minSeconds float64
+	}{
+		{duration: 6, overlap: 1.5, minSeconds: 20},
+		{duration: 19.9, overlap: 1.5, minSeconds: 20},
+		{duration: 7, overlap: 0, minSeconds: 45},
+		{duration: 2, overlap: 0.5, minSeconds: 5},
+	}
+	for _, tt := range tests {
+		copies := songLoopCopies(tt.duration, tt.overlap, tt.minSeconds)
+		if total := float64(copies)*tt.duration - float64(copies-1)*tt.overlap; total < tt.minSeconds {
+			t.Errorf("%d copies of %.1fs overlapping by %.1fs last %.1fs, want at least %.1fs", copies, tt.duration, tt.overlap, total, tt.minSeconds)
+		}
+	}
+	if copies := songLoopCopies(30, 1.5, 20); copies != 1 {
+		t.Errorf("a recording already past the minimum needs %d copies, want 1", copies)
+	}
+}
+
+func TestShortRecordingIsLoopedToTheMinimum(t *testing.T) {
+	np := &NativeAudioProcessor{}
+	gains := make([]int, 230) // About six seconds
+	for i := range gains {
+		gains[i] = 150
+	}
+	clip := testClip(gains...)
+
+	trimmer := NewSongTrimmer(20, 90)
+	looped, err := trimmer.loopNative(clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	duration, err := np.Duration(looped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duration < trimmer.MinSeconds {
+		t.Errorf("looped recording lasts %.1fs, want at least %.0fs", duration, trimmer.MinSeconds)
+	}
+
+	long := testClip(append(gains, gains...)...)
+	long = append(long, long...)
+	if out, _ := trimmer.loopNative(long); len(out) != len(long) {
+		t.Error("a recording past the minimum was looped")
+	}
+}
+
+func TestTrimLoopsShortRecordingWithFFmpeg(t *testing.T) {
+	caps := GetFFmpegCapabilities()
+	if !caps.Probe || !caps.Mixing || !caps.MP3Encode {
+		t.Skip("ffmpeg not available")
+	}
+	source := filepath.Join(t.TempDir(), "short.mp3")
+	generate := exec.Command(ffmpegBinary(), "-f", "lavfi", "-i", "sine=frequency=2000:duration=6", "-c:a", "libmp3lame", "-y", source)
+	if output, err := generate.CombinedOutput(); err != nil {
+		t.Fatalf("failed to generate recording: %v\n%s", err, output)
+	}
+	audio, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trimmer := NewSongTrimmer(20, 90)
+	looped, err := trimmer.Trim(audio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := filepath.Join(t.TempDir(), "looped.mp3")
+	if err := os.WriteFile(result, looped, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if duration := probeDuration(result); duration < trimmer.MinSeconds {
+		t.Errorf("looped recording lasts %.1fs, want at least %.0fs", duration, trimmer.MinSeconds)
+	}
+}
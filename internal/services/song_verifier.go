@@ -0,0 +1,445 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Verification thresholds
+const (
+	maxVerifyClipBytes      = 25 << 20 // Recordings larger than this aren't downloaded for checking
+	minBirdNETConfidence    = 0.5      // Dominant BirdNET detection must be at least this confident
+	minSpectralBirdActivity = 0.15     // Share of non-quiet frames dominated by the bird-song band
+	spectralSampleRate      = 22050
+	spectralFrameSize       = 1024
+	spectralBandLowHz       = 1500.0
+	spectralBandHighHz      = 8000.0
+	spectralMaxSeconds      = 60
+)
+
+// Song bout detection: an audible frame is louder than boutEnergyRatio times the median frame and
+// mostly in the bird-song band; audible frames closer than boutMergeSeconds belong to one bout, and
+// bouts shorter than boutMinSeconds are clicks rather than song
+const (
+	boutEnergyRatio  = 4.0
+	boutMergeSeconds = 0.3
+	boutMinSeconds   = 0.1
+)
+
+// SongVerdict is the outcome of checking a candidate recording
+type SongVerdict struct {
+	Accepted   bool    `json:"accepted"`
+	Species    string  `json:"species,omitempty"` // Dominant species detected, when the verifier identifies species
+	Confidence float64 `json:"confidence,omitempty"`
+	Reason     string  `json:"reason"`
+}
+
+// SongVerifier checks that a recording actually features the expected species. An error means the
+// recording couldn't be checked (no ffmpeg, analyzer unreachable), not that it failed the check.
+type SongVerifier interface {
+	Verify(ctx context.Context, recording SongRecording, scientificName string) (SongVerdict, error)
+}
+
+// NewSongVerifier uses the BirdNET analyzer at birdNETURL when set, otherwise the spectral heuristic
+func NewSongVerifier(birdNETURL string) SongVerifier {
+	if birdNETURL != "" {
+		return NewBirdNETVerifier(birdNETURL)
+	}
+	return &SpectralVerifier{minActivity: minSpectralBirdActivity}
+}
+
+// BirdNETVerifier sends the clip to a BirdNET-Analyzer server and checks the dominant detection
+type BirdNETVerifier struct {
+	apiURL        string
+	httpClient    *http.Client
+	minConfidence float64
+}
+
+// NewBirdNETVerifier creates a verifier for a BirdNET-Analyzer server (its /analyze endpoint)
+func NewBirdNETVerifier(apiURL string) *BirdNETVerifier {
+	return &BirdNETVerifier{
+		apiURL:        strings.TrimRight(apiURL, "/"),
+		httpClient:    httpx.NewClient(httpx.Options{Timeout: 4 * time.Minute, AttemptTimeout: 2 * time.Minute, RetryUnprocessed: true}),
+		minConfidence: minBirdNETConfidence,
+	}
+}
+
+// birdNETResponse is the analyzer's reply: results are ["Scientific name_Common name", confidence] pairs
+type birdNETResponse struct {
+	Msg     string              `json:"msg"`
+	Results [][]json.RawMessage `json:"results"`
+}
+
+// Verify rejects the recording unless BirdNET's most confident detection is the expected species
+func (bv *BirdNETVerifier) Verify(ctx context.Context, recording SongRecording, scientificName string) (SongVerdict, error) {
+	clipPath, cleanup, err := downloadVerificationClip(ctx, recording.URL)
+	if err != nil {
+		return SongVerdict{}, err
+	}
+	defer cleanup()
+
+	detections, err := bv.analyze(ctx, clipPath)
+	if err != nil {
+		return SongVerdict{}, err
+	}
+	if len(detections) == 0 {
+		return SongVerdict{Reason: "BirdNET detected no species"}, nil
+	}
+
+	top := detections[0]
+	verdict := SongVerdict{Species: top.species, Confidence: top.confidence}
+	switch {
+	case !strings.EqualFold(top.species, scientificName):
+		verdict.Reason = fmt.Sprintf("dominant species is %s (%.2f), expected %s", top.species, top.confidence, scientificName)
+	case top.confidence < bv.minConfidence:
+		verdict.Reason = fmt.Sprintf("%s detected with low confidence %.2f", top.species, top.confidence)
+	default:
+		verdict.Accepted = true
+		verdict.Reason = fmt.Sprintf("BirdNET confirmed %s (%.2f)", top.species, top.confidence)
+	}
+	return verdict, nil
+}
+
+type birdNETDetection struct {
+	species    string
+	confidence float64
+}
+
+// analyze uploads the clip and returns detections, most confident first
+func (bv *BirdNETVerifier) analyze(ctx context.Context, clipPath string) ([]birdNETDetection, error) {
+	clip, err := os.Open(clipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer clip.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("audio", filepath.Base(clipPath))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, clip); err != nil {
+		return nil, err
+	}
+	// Location and week unknown (-1) so the analyzer doesn't filter by range
+	meta := `{"lat": -1, "lon": -1, "week": -1, "overlap": 0, "sensitivity": 1, "sf_thresh": 0.03, "pmode": "avg", "num_results": 5, "save": false}`
+	if err := writer.WriteField("meta", meta); err != nil {
+		return nil, err
+	}
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", bv.apiURL+"/analyze", &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := bv.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("BirdNET request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("BirdNET returned status %d", resp.StatusCode)
+	}
+
+	var result birdNETResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode BirdNET response: %w", err)
+	}
+	if result.Msg != "success" {
+		return nil, fmt.Errorf("BirdNET analysis failed: %s", result.Msg)
+	}
+
+	var detections []birdNETDetection
+	for _, pair := range result.Results {
+		if len(pair) != 2 {
+			continue
+		}
+		var label string
+		var confidence float64
+		if json.Unmarshal(pair[0], &label) != nil || json.Unmarshal(pair[1], &confidence) != nil {
+			continue
+		}
+		species, _, _ := strings.Cut(label, "_")
+		detections = append(detections, birdNETDetection{species: species, confidence: confidence})
+	}
+
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].confidence > detections[j].confidence
+	})
+	return detections, nil
+}
+
+// SpectralVerifier can't tell species apart, but rejects clips that are mostly background noise:
+// it needs enough of the audible frames to be dominated by the 1.5-8 kHz band most songbirds sing in
+type SpectralVerifier struct {
+	minActivity float64
+}
+
+// Verify decodes the start of the clip with ffmpeg and measures bird-band activity
+func (sv *SpectralVerifier) Verify(ctx context.Context, recording SongRecording, scientificName string) (SongVerdict, error) {
+	if !GetFFmpegCapabilities().Available {
+		return SongVerdict{}, fmt.Errorf("ffmpeg unavailable for spectral check")
+	}
+
+	clipPath, cleanup, err := downloadVerificationClip(ctx, recording.URL)
+	if err != nil {
+		return SongVerdict{}, err
+	}
+	defer cleanup()
+
+	samples, err := decodeMonoPCM(clipPath)
+	if err != nil {
+		return SongVerdict{}, err
+	}
+
+	activity := birdBandActivity(samples)
+	verdict := SongVerdict{Confidence: activity}
+	if activity < sv.minActivity {
+		verdict.Reason = fmt.Sprintf("mostly background noise (bird-band activity %.2f)", activity)
+	} else {
+		verdict.Accepted = true
+		verdict.Reason = fmt.Sprintf("bird-band activity %.2f", activity)
+	}
+	return verdict, nil
+}
+
+// recordingDownloadClient downloads recordings for verification and the quiz
+var recordingDownloadClient = httpx.NewClient(httpx.Options{Timeout: 2 * time.Minute, AttemptTimeout: 60 * time.Second})
+
+// downloadVerificationClip fetches a recording into a temp file, returning a cleanup func
+func downloadVerificationClip(ctx context.Context, url string) (string, func(), error) {
+	resp, err := httpx.Get(ctx, recordingDownloadClient, url)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("recording download returned status %d", resp.StatusCode)
+	}
+
+	tempFile, err := os.CreateTemp("", "verify_*.mp3")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tempFile.Name()) }
+
+	_, err = io.Copy(tempFile, io.LimitReader(resp.Body, maxVerifyClipBytes))
+	tempFile.Close()
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to save recording: %w", err)
+	}
+	return tempFile.Name(), cleanup, nil
+}
+
+// decodeMonoPCM decodes the first spectralMaxSeconds of a clip to mono 16-bit samples
+func decodeMonoPCM(path string) ([]float64, error) {
+	cmd := exec.Command(ffmpegBinary(),
+		"-i", path,
+		"-t", fmt.Sprintf("%d", spectralMaxSeconds),
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", spectralSampleRate),
+		"-f", "s16le",
+		"-",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decode recording: %w: %s", err, stderr.String())
+	}
+
+	raw := stdout.Bytes()
+	samples := make([]float64, len(raw)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(raw[2*i:]))) / 32768
+	}
+	return samples, nil
+}
+
+// birdBandActivity returns the share of audible frames whose energy is mostly in the bird-song band.
+// Frames quieter than the median are ignored so pauses between phrases don't count against the clip.
+func birdBandActivity(samples []float64) float64 {
+	totals, ratios := bandFrames(samples)
+	if len(totals) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), totals...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var audible, active int
+	for f := range totals {
+		if totals[f] < median || totals[f] == 0 {
+			continue
+		}
+		audible++
+		if ratios[f] >= 0.5 {
+			active++
+		}
+	}
+	if audible == 0 {
+		return 0
+	}
+	return float64(active) / float64(audible)
+}
+
+// SongBout is one burst of singing in a clip, in seconds from its start
+type SongBout struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// CountSongBouts decodes a clip with ffmpeg and finds the separate bursts of singing in it, the
+// answer to "how many times did the bird sing?"
+func CountSongBouts(audio []byte) ([]SongBout, error) {
+	if !GetFFmpegCapabilities().Available {
+		return nil, fmt.Errorf("ffmpeg unavailable for song bout detection")
+	}
+
+	tempFile, err := os.CreateTemp("", "bouts_*.mp3")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(audio)
+	tempFile.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save clip: %w", err)
+	}
+
+	samples, err := decodeMonoPCM(tempFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	return songBouts(samples), nil
+}
+
+// songBouts groups loud bird-band frames into bouts separated by quieter gaps
+func songBouts(samples []float64) []SongBout {
+	totals, ratios := bandFrames(samples)
+	if len(totals) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64(nil), totals...)
+	sort.Float64s(sorted)
+	threshold := sorted[len(sorted)/2] * boutEnergyRatio
+	frameSeconds := float64(spectralFrameSize) / spectralSampleRate
+
+	var bouts []SongBout
+	var current *SongBout
+	for f := range totals {
+		if totals[f] <= threshold || ratios[f] < 0.5 {
+			continue
+		}
+		start := float64(f) * frameSeconds
+		if current != nil && start-current.End <= boutMergeSeconds {
+			current.End = start + frameSeconds
+			continue
+		}
+		if current != nil && current.End-current.Start >= boutMinSeconds {
+			bouts = append(bouts, *current)
+		}
+		current = &SongBout{Start: start, End: start + frameSeconds}
+	}
+	if current != nil && current.End-current.Start >= boutMinSeconds {
+		bouts = append(bouts, *current)
+	}
+	return bouts
+}
+
+// bandFrames splits samples into frames and returns each frame's total power and the share of it
+// in the bird-song band
+func bandFrames(samples []float64) (totals []float64, ratios []float64) {
+	frameCount := len(samples) / spectralFrameSize
+	if frameCount == 0 {
+		return nil, nil
+	}
+
+	binHz := float64(spectralSampleRate) / spectralFrameSize
+	lowBin := int(spectralBandLowHz / binHz)
+	highBin := int(spectralBandHighHz / binHz)
+
+	window := make([]float64, spectralFrameSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(spectralFrameSize-1))
+	}
+
+	totals = make([]float64, frameCount)
+	ratios = make([]float64, frameCount)
+	frame := make([]complex128, spectralFrameSize)
+	for f := 0; f < frameCount; f++ {
+		offset := f * spectralFrameSize
+		for i := range frame {
+			frame[i] = complex(samples[offset+i]*window[i], 0)
+		}
+		fft(frame)
+
+		var total, band float64
+		for bin := 1; bin < spectralFrameSize/2; bin++ {
+			power := real(frame[bin])*real(frame[bin]) + imag(frame[bin])*imag(frame[bin])
+			total += power
+			if bin >= lowBin && bin <= highBin {
+				band += power
+			}
+		}
+		totals[f] = total
+		if total > 0 {
+			ratios[f] = band / total
+		}
+	}
+	return totals, ratios
+}
+
+// fft is an in-place iterative radix-2 FFT; len(x) must be a power of two
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		angle := -2 * math.Pi / float64(size)
+		step := complex(math.Cos(angle), math.Sin(angle))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even := x[start+k]
+				odd := w * x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Visualizer icon layout: four 3px bars on a 16x16 Yoto display icon
+const (
+	visualizerSize       = 16
+	visualizerFrames     = 5
+	visualizerBars       = 4
+	visualizerSampleRate = 8000
+	visualizerMinHeight  = 2
+	visualizerMaxHeight  = 14
+)
+
+var visualizerPalette = color.Palette{
+	color.RGBA{0x00, 0x00, 0x00, 0xff}, // Background
+	color.RGBA{0x3c, 0xc8, 0x5a, 0xff}, // Bar body
+	color.RGBA{0xff, 0xd2, 0x3c, 0xff}, // Bar peak
+}
+
+// SongVisualizer renders tiny animated "song bars" icons whose heights follow a bird song's
+// amplitude envelope, for use as the Explorer's Guide track icon
+type SongVisualizer struct {
+	storage  *BirdStorage
+	cacheDir string
+}
+
+// NewSongVisualizer creates a visualizer that reads songs from bird storage
+func NewSongVisualizer(storage *BirdStorage) *SongVisualizer {
+	return &SongVisualizer{
+		storage:  storage,
+		cacheDir: filepath.Join(os.TempDir(), "song_visualizer"),
+	}
+}
+
+// IconForBird returns the path to an animated GIF for the bird's song, generating it on first use.
+// It returns "" when there is no local recording or ffmpeg can't decode it, so callers keep the static icon.
+func (sv *SongVisualizer) IconForBird(birdName string) string {
+	dirName := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	outputPath := filepath.Join(sv.cacheDir, dirName+".gif")
+	if _, err := os.Stat(outputPath); err == nil {
+		return outputPath
+	}
+
+	if !GetFFmpegCapabilities().Available {
+		return ""
+	}
+
+	songPath := sv.firstSong(birdName)
+	if songPath == "" {
+		slog.Info("[SONG_VISUALIZER] No local recording, using static icon", "bird", birdName)
+		return ""
+	}
+
+	envelope, err := amplitudeEnvelope(songPath, visualizerFrames*visualizerBars)
+	if err != nil {
+		slog.Warn("[SONG_VISUALIZER] Failed to read envelope", "bird", birdName, "error", err)
+		return ""
+	}
+
+	if err := os.MkdirAll(sv.cacheDir, 0755); err != nil {
+		slog.Error("[SONG_VISUALIZER] Failed to create cache directory", "error", err)
+		return ""
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		slog.Error("[SONG_VISUALIZER] Failed to create file", "path", outputPath, "error", err)
+		return ""
+	}
+	defer file.Close()
+
+	if err := gif.EncodeAll(file, renderVisualizer(envelope, probeDuration(songPath))); err != nil {
+		os.Remove(outputPath)
+		slog.Error("[SONG_VISUALIZER] Failed to encode GIF", "bird", birdName, "error", err)
+		return ""
+	}
+
+	slog.Info("[SONG_VISUALIZER] Generated song visualizer", "bird", birdName)
+	return outputPath
+}
+
+// firstSong returns the first recording in the bird's songs directory
+func (sv *SongVisualizer) firstSong(birdName string) string {
+	songsDir := filepath.Dir(sv.storage.GetSongPath(birdName, "song"))
+	matches, _ := filepath.Glob(filepath.Join(songsDir, "*.mp3"))
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[0]
+}
+
+// amplitudeEnvelope decodes a recording to mono PCM and returns the RMS of windows equal-length windows,
+// normalized so the loudest window is 1
+func amplitudeEnvelope(audioFile string, windows int) ([]float64, error) {
+	cmd := exec.Command(ffmpegBinary(),
+		"-v", "error",
+		"-i", audioFile,
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", visualizerSampleRate),
+		"-f", "s16le",
+		"-",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg decode failed: %w: %s", err, stderr.String())
+	}
+
+	samples := make([]int16, stdout.Len()/2)
+	if err := binary.Read(&stdout, binary.LittleEndian, samples); err != nil {
+		return nil, fmt.Errorf("failed to read samples: %w", err)
+	}
+	if len(samples) < windows {
+		return nil, fmt.Errorf("recording too short")
+	}
+
+	envelope := make([]float64, windows)
+	windowSize := len(samples) / windows
+	peak := 0.0
+	for w := 0; w < windows; w++ {
+		var sum float64
+		for _, sample := range samples[w*windowSize : (w+1)*windowSize] {
+			value := float64(sample) / math.MaxInt16
+			sum += value * value
+		}
+		envelope[w] = math.Sqrt(sum / float64(windowSize))
+		peak = math.Max(peak, envelope[w])
+	}
+
+	if peak > 0 {
+		for w := range envelope {
+			envelope[w] /= peak
+		}
+	}
+	return envelope, nil
+}
+
+// renderVisualizer draws one frame per group of visualizerBars envelope values.
+// Frame delays spread the animation across the song so it loosely tracks playback.
+func renderVisualizer(envelope []float64, durationSeconds float64) *gif.GIF {
+	delay := 25 // 1/100ths of a second
+	if durationSeconds > 0 {
+		delay = int(math.Min(math.Max(durationSeconds/visualizerFrames*100, 15), 50))
+	}
+
+	animation := &gif.GIF{LoopCount: 0}
+	for frame := 0; frame < visualizerFrames; frame++ {
+		img := image.NewPaletted(image.Rect(0, 0, visualizerSize, visualizerSize), visualizerPalette)
+
+		for bar := 0; bar < visualizerBars; bar++ {
+			level := envelope[frame*visualizerBars+bar]
+			height := visualizerMinHeight + int(math.Round(level*float64(visualizerMaxHeight-visualizerMinHeight)))
+			left := 1 + bar*4
+			top := visualizerSize - 1 - height
+
+			for y := top; y < visualizerSize-1; y++ {
+				colorIndex := uint8(1)
+				if y == top {
+					colorIndex = 2
+				}
+				for x := left; x < left+3; x++ {
+					img.SetColorIndex(x, y, colorIndex)
+				}
+			}
+		}
+
+		animation.Image = append(animation.Image, img)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	return animation
+}
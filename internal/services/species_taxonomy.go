@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/ebird"
+)
+
+// withTaxonomy returns a copy of the bird with a missing scientific name, family, or order filled
+// in from the eBird taxonomy, and whether the scientific name came from it
+func withTaxonomy(taxonomy *ebird.Taxonomy, bird *models.Bird) (*models.Bird, bool) {
+	if taxonomy == nil || bird == nil || (bird.ScientificName != "" && bird.Family != "" && bird.Order != "") {
+		return bird, false
+	}
+
+	var species ebird.Species
+	var ok bool
+	if bird.ScientificName != "" {
+		species, ok = taxonomy.ByScientificName(bird.ScientificName)
+	} else {
+		species, ok = taxonomy.ByCommonName(bird.CommonName)
+	}
+	if !ok {
+		return bird, false
+	}
+
+	resolved := *bird
+	fromTaxonomy := resolved.ScientificName == ""
+	if fromTaxonomy {
+		resolved.ScientificName = species.ScientificName
+	}
+	if resolved.Family == "" {
+		resolved.Family = species.Family
+	}
+	if resolved.Order == "" {
+		resolved.Order = species.Order
+	}
+	return &resolved, fromTaxonomy
+}
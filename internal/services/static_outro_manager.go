@@ -2,20 +2,20 @@ package services
 
 import (
 	"fmt"
-	"path/filepath"
+	"path"
 	"time"
 )
 
 // StaticOutroManager uses pre-recorded outro files instead of TTS
 type StaticOutroManager struct {
-	outroDir  string
+	assets    AssetStore
 	useStatic bool
 }
 
 // NewStaticOutroManager creates a manager for pre-recorded outros
 func NewStaticOutroManager() *StaticOutroManager {
 	return &StaticOutroManager{
-		outroDir:  "assets/final_outros",
+		assets:    SharedAssetStore(),
 		useStatic: true, // Can be toggled via env var
 	}
 }
@@ -25,8 +25,8 @@ func (som *StaticOutroManager) GetOutroURL(voiceName string, dayOfWeek time.Week
 	outroType := som.getOutroType(dayOfWeek)
 
 	// Find available outros of this type for this voice
-	pattern := filepath.Join(som.outroDir, fmt.Sprintf("outro_%s_*_%s.mp3", outroType, voiceName))
-	matches, err := filepath.Glob(pattern)
+	pattern := fmt.Sprintf("final_outros/outro_%s_*_%s.mp3", outroType, voiceName)
+	matches, err := som.assets.Glob(pattern)
 	if err != nil || len(matches) == 0 {
 		return "", fmt.Errorf("no outros found for %s/%s", outroType, voiceName)
 	}
@@ -39,7 +39,7 @@ func (som *StaticOutroManager) GetOutroURL(voiceName string, dayOfWeek time.Week
 	selectedFile := matches[outroIndex]
 
 	// Return URL to the outro file
-	filename := filepath.Base(selectedFile)
+	filename := path.Base(selectedFile)
 	return fmt.Sprintf("%s/audio/outros/%s", baseURL, filename), nil
 }
 
@@ -88,14 +88,10 @@ func (som *StaticOutroManager) getOutroType(dayOfWeek time.Weekday) string {
 // CountAvailableOutros returns how many outros are available
 func (som *StaticOutroManager) CountAvailableOutros() map[string]int {
 	counts := make(map[string]int)
-	types := []string{"joke", "wisdom", "teaser", "challenge", "funfact"}
-	// Hardcoded list of human voice narrators
-	voices := []string{"Amelia", "Antoni", "Charlotte", "Peter", "Drake", "Sally"}
-
-	for _, voice := range voices {
-		for _, outroType := range types {
-			pattern := filepath.Join(som.outroDir, fmt.Sprintf("outro_%s_*_%s.mp3", outroType, voice))
-			matches, _ := filepath.Glob(pattern)
+	for _, voice := range narratorVoices() {
+		for _, outroType := range StaticOutroTypes {
+			pattern := fmt.Sprintf("final_outros/outro_%s_*_%s.mp3", outroType, voice)
+			matches, _ := som.assets.Glob(pattern)
 			key := fmt.Sprintf("%s_%s", voice, outroType)
 			counts[key] = len(matches)
 		}
@@ -107,7 +103,7 @@ func (som *StaticOutroManager) CountAvailableOutros() map[string]int {
 // MixOutroWithBirdSong mixes a pre-recorded outro with bird song
 func (som *StaticOutroManager) MixOutroWithBirdSong(outroData []byte, birdSongData []byte) ([]byte, error) {
 	// Use the existing AudioMixer to combine outro speech with bird song
-	mixer := NewAudioMixer()
+	mixer := NewAudioMixer(nil)
 
 	// Mix at lower volume since it's background for the outro
 	// Bird song plays softly under the outro speech
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+const (
+	defaultStreamCacheMB  = 64
+	defaultStreamCacheTTL = 6 * time.Hour
+)
+
+// StreamAudio is audio served by the card streaming endpoints, with what's needed for
+// conditional and range requests
+type StreamAudio struct {
+	Data      []byte
+	ETag      string
+	FetchedAt time.Time
+}
+
+// NewStreamAudio wraps generated audio, deriving its ETag from the content
+func NewStreamAudio(data []byte) *StreamAudio {
+	sum := sha256.Sum256(data)
+	return &StreamAudio{
+		Data:      data,
+		ETag:      `"` + hex.EncodeToString(sum[:8]) + `"`,
+		FetchedAt: time.Now().UTC(),
+	}
+}
+
+type streamCacheEntry struct {
+	url   string
+	audio *StreamAudio
+}
+
+// StreamCache keeps recently served narration in memory, evicting the least recently used
+// clips once the size limit is reached
+type StreamCache struct {
+	mu         sync.Mutex
+	maxBytes   int
+	size       int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	order      *list.List // front is most recently used
+	httpClient *http.Client
+}
+
+// NewStreamCache creates a cache holding up to maxMB megabytes (64 when maxMB is 0 or less)
+func NewStreamCache(maxMB int) *StreamCache {
+	if maxMB <= 0 {
+		maxMB = defaultStreamCacheMB
+	}
+	return &StreamCache{
+		maxBytes:   maxMB * 1024 * 1024,
+		ttl:        defaultStreamCacheTTL,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 30 * time.Second}),
+	}
+}
+
+// Fetch returns the audio at url, downloading it when it isn't cached or has expired
+func (sc *StreamCache) Fetch(ctx context.Context, url string) (*StreamAudio, error) {
+	sc.mu.Lock()
+	if element, ok := sc.entries[url]; ok {
+		entry := element.Value.(*streamCacheEntry)
+		if time.Since(entry.audio.FetchedAt) < sc.ttl {
+			sc.order.MoveToFront(element)
+			sc.mu.Unlock()
+			recordCacheLookup("stream", true)
+			return entry.audio, nil
+		}
+		sc.remove(element)
+	}
+	sc.mu.Unlock()
+	recordCacheLookup("stream", false)
+
+	resp, err := httpx.Get(ctx, sc.httpClient, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+
+	audio := NewStreamAudio(data)
+	sc.store(url, audio)
+	return audio, nil
+}
+
+// Stats returns the cache size for monitoring
+func (sc *StreamCache) Stats() map[string]interface{} {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return map[string]interface{}{
+		"entries":   sc.order.Len(),
+		"bytes":     sc.size,
+		"max_bytes": sc.maxBytes,
+	}
+}
+
+// store adds audio to the cache, evicting old clips to stay under the size limit
+func (sc *StreamCache) store(url string, audio *StreamAudio) {
+	if len(audio.Data) > sc.maxBytes {
+		log.Printf("[STREAM_CACHE] %s is larger than the cache (%d bytes), not caching", url, len(audio.Data))
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if element, ok := sc.entries[url]; ok {
+		sc.remove(element)
+	}
+	for sc.size+len(audio.Data) > sc.maxBytes && sc.order.Len() > 0 {
+		sc.remove(sc.order.Back())
+	}
+
+	sc.entries[url] = sc.order.PushFront(&streamCacheEntry{url: url, audio: audio})
+	sc.size += len(audio.Data)
+}
+
+// remove drops an entry; the caller holds the lock
+func (sc *StreamCache) remove(element *list.Element) {
+	entry := element.Value.(*streamCacheEntry)
+	sc.order.Remove(element)
+	delete(sc.entries, entry.url)
+	sc.size -= len(entry.audio.Data)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/template"
+	"time"
+)
+
+const themeAssetBaseURL = "https://storage.googleapis.com/bird-song-explorer-audio/birds"
+
+// Theme is a seasonal content pack that runs between two dates every year, or a holiday that runs
+// on its one day. It biases bird selection toward its species and swaps in themed intro/outro
+// narration and an intro icon.
+type Theme struct {
+	Key             string   `json:"key"` // Asset folder under birds/_themes/ and icon name under assets/icons/themes/
+	Name            string   `json:"name"`
+	Start           string   `json:"start"` // "MM-DD", inclusive
+	End             string   `json:"end"`   // "MM-DD", inclusive; earlier than Start wraps over the new year
+	SpeciesKeywords []string `json:"species_keywords"`
+	Intros          []string `json:"intros"` // Templates; {{.BirdName}} is available
+	Outros          []string `json:"outros"`
+	Icon            string   `json:"icon,omitempty"` // 16x16 intro icon; defaults to assets/icons/themes/<key>_16x16.png
+
+	holiday *Holiday // Set for holidays, which run on the holiday's date instead of Start to End
+}
+
+// defaultThemes are the built-in seasonal packs
+var defaultThemes = []Theme{
+	{
+		Key:             "spring_migration",
+		Name:            "Spring Migration Week",
+		Start:           "05-04",
+		End:             "05-10",
+		SpeciesKeywords: []string{"Warbler", "Oriole", "Tanager", "Swallow", "Hummingbird", "Cuckoo"},
+		Intros: []string{
+			"It's spring migration week, explorers! Millions of birds are flying home, and today the {{.BirdName}} has arrived!",
+			"Look up, explorers! Birds are travelling thousands of miles this week. Let's welcome the {{.BirdName}}!",
+		},
+		Outros: []string{
+			"Keep watching the skies this week, explorers. You might spot a {{.BirdName}} on its long journey home!",
+			"What a traveller! Tomorrow another migrating bird will land right here. See you then!",
+		},
+	},
+	{
+		Key:             "halloween_owls",
+		Name:            "Halloween Owls",
+		Start:           "10-24",
+		End:             "10-31",
+		SpeciesKeywords: []string{"Owl", "Raven", "Crow"},
+		Intros: []string{
+			"Hoo's there? It's owl week, night explorers! Tonight's mysterious bird is the {{.BirdName}}!",
+			"The moon is up and the leaves are crunchy. Who's calling in the dark? It's the {{.BirdName}}!",
+		},
+		Outros: []string{
+			"Listen carefully tonight, explorers. You might hear the {{.BirdName}} calling from the shadows!",
+			"Sweet spooky dreams, explorers! Another creature of the night is waiting for you tomorrow.",
+		},
+	},
+	{
+		Key:             "winter_feeders",
+		Name:            "Winter Feeder Birds",
+		Start:           "12-01",
+		End:             "02-28",
+		SpeciesKeywords: []string{"Chickadee", "Cardinal", "Finch", "Sparrow", "Jay", "Nuthatch", "Titmouse", "Junco", "Woodpecker", "Robin"},
+		Intros: []string{
+			"Brrr, it's chilly, explorers! Hungry birds are visiting feeders everywhere, like the {{.BirdName}}!",
+			"Bundle up, explorers! Let's peek out the window at today's winter visitor, the {{.BirdName}}!",
+		},
+		Outros: []string{
+			"A few seeds on a cold day help birds like the {{.BirdName}} stay warm. Can you spot one at a feeder?",
+			"Stay cosy, explorers! Tomorrow another winter bird will stop by for a snack.",
+		},
+	},
+}
+
+// ThemeManager is the content calendar: the locale's holidays and the seasonal themes. It finds
+// the one theme running on a date, a holiday winning over the season around it.
+type ThemeManager struct {
+	holidays []Theme
+	themes   []Theme
+}
+
+// NewThemeManager loads the built-in themes, or a JSON list of themes from path, with the holidays
+// of holidayLocale, or a JSON list of holidays from holidayPath. A path of "none" disables the
+// seasonal themes; a locale of "none" with no holiday path disables holidays.
+func NewThemeManager(path string, holidayLocale string, holidayPath string) *ThemeManager {
+	manager := &ThemeManager{themes: defaultThemes}
+	for _, holiday := range loadHolidays(holidayLocale, holidayPath) {
+		manager.holidays = append(manager.holidays, holiday.theme())
+	}
+
+	if path == "none" {
+		manager.themes = nil
+		return manager
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("[THEMES] Failed to read themes %s: %v, using built-in themes", path, err)
+			return manager
+		}
+
+		var themes []Theme
+		if err := json.Unmarshal(data, &themes); err != nil {
+			log.Printf("[THEMES] Failed to parse themes %s: %v, using built-in themes", path, err)
+			return manager
+		}
+		manager.themes = validThemes(themes)
+	}
+
+	return manager
+}
+
+// validThemes drops themes whose dates don't parse
+func validThemes(themes []Theme) []Theme {
+	valid := make([]Theme, 0, len(themes))
+	for _, theme := range themes {
+		if _, _, err := theme.bounds(); err != nil {
+			log.Printf("[THEMES] Skipping theme %s: %v", theme.Key, err)
+			continue
+		}
+		valid = append(valid, theme)
+	}
+	return valid
+}
+
+// ThemeOn returns the holiday falling on the given date, else the first seasonal theme running on it
+func (tm *ThemeManager) ThemeOn(date time.Time) (*Theme, bool) {
+	for i := range tm.holidays {
+		if tm.holidays[i].runsOn(date) {
+			return &tm.holidays[i], true
+		}
+	}
+	for i := range tm.themes {
+		if tm.themes[i].runsOn(date) {
+			return &tm.themes[i], true
+		}
+	}
+	return nil, false
+}
+
+// Themes returns the configured holidays and seasonal themes
+func (tm *ThemeManager) Themes() []Theme {
+	return append(append([]Theme(nil), tm.holidays...), tm.themes...)
+}
+
+// IsHoliday reports whether the theme is a one-day holiday rather than a season
+func (t *Theme) IsHoliday() bool {
+	return t.holiday != nil
+}
+
+// bounds parses the start and end dates as month*100+day
+func (t *Theme) bounds() (int, int, error) {
+	start, err := parseMonthDay(t.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start %q: %w", t.Start, err)
+	}
+	end, err := parseMonthDay(t.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end %q: %w", t.End, err)
+	}
+	return start, end, nil
+}
+
+func parseMonthDay(value string) (int, error) {
+	parsed, err := time.Parse("01-02", value)
+	if err != nil {
+		return 0, err
+	}
+	return int(parsed.Month())*100 + parsed.Day(), nil
+}
+
+// runsOn reports whether the date falls inside the theme's window, or is the holiday's day
+func (t *Theme) runsOn(date time.Time) bool {
+	if t.holiday != nil {
+		return t.holiday.fallsOn(date)
+	}
+
+	start, end, err := t.bounds()
+	if err != nil {
+		return false
+	}
+
+	day := int(date.Month())*100 + date.Day()
+	if start <= end {
+		return day >= start && day <= end
+	}
+	return day >= start || day <= end
+}
+
+// MatchesSpecies reports whether a bird's common name fits the theme
+func (t *Theme) MatchesSpecies(commonName string) bool {
+	return matchesKeywords(commonName, t.SpeciesKeywords)
+}
+
+// variant picks the day's entry from n variants, so every listener hears the same one
+func (t *Theme) variant(date time.Time, n int) int {
+	return date.YearDay() % n
+}
+
+// IntroText renders the date's themed intro for the bird, or "" when the theme has none
+func (t *Theme) IntroText(date time.Time, birdName string) string {
+	if len(t.Intros) == 0 {
+		return ""
+	}
+	return t.render(t.Intros[t.variant(date, len(t.Intros))], birdName)
+}
+
+// OutroText renders the date's themed outro for the bird, or "" when the theme has none
+func (t *Theme) OutroText(date time.Time, birdName string) string {
+	if len(t.Outros) == 0 {
+		return ""
+	}
+	return t.render(t.Outros[t.variant(date, len(t.Outros))], birdName)
+}
+
+func (t *Theme) render(text string, birdName string) string {
+	tmpl, err := template.New(t.Key).Parse(text)
+	if err != nil {
+		log.Printf("[THEMES] Invalid template for %s: %v", t.Key, err)
+		return text
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ BirdName string }{birdName}); err != nil {
+		return text
+	}
+	return buf.String()
+}
+
+// IntroName is the date's themed intro for a bird relative to the birds/ folder
+func (t *Theme) IntroName(date time.Time, birdDir string) string {
+	return fmt.Sprintf("_themes/%s/%s/intro_%02d.mp3", t.Key, birdDir, t.variant(date, max(len(t.Intros), 1))+1)
+}
+
+// OutroName is the date's themed outro for a bird relative to the birds/ folder
+func (t *Theme) OutroName(date time.Time, birdDir string) string {
+	return fmt.Sprintf("_themes/%s/%s/outro_%02d.mp3", t.Key, birdDir, t.variant(date, max(len(t.Outros), 1))+1)
+}
+
+// IntroURL returns the location of the date's themed intro audio for a bird
+func (t *Theme) IntroURL(date time.Time, birdDir string) string {
+	return themeAssetBaseURL + "/" + t.IntroName(date, birdDir)
+}
+
+// OutroURL returns the location of the date's themed outro audio for a bird
+func (t *Theme) OutroURL(date time.Time, birdDir string) string {
+	return themeAssetBaseURL + "/" + t.OutroName(date, birdDir)
+}
+
+// IconPath returns the theme's intro icon file
+func (t *Theme) IconPath() string {
+	if t.Icon != "" {
+		return t.Icon
+	}
+	return fmt.Sprintf("./assets/icons/themes/%s_16x16.png", t.Key)
+}
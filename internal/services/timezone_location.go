@@ -246,6 +246,7 @@ var timezoneLocationMap = map[string]*models.Location{
 }
 
 // GetLocationFromTimezone returns an approximate location based on timezone
+// Returns nil when the timezone is unknown so callers can apply their configured defaults
 func (s *TimezoneLocationService) GetLocationFromTimezone(timezone string) *models.Location {
 	// Clean up timezone string
 	timezone = strings.TrimSpace(timezone)
@@ -298,12 +299,6 @@ func (s *TimezoneLocationService) GetLocationFromTimezone(timezone string) *mode
 		}
 	}
 
-	// Default fallback - use London, England
-	return &models.Location{
-		Latitude:  51.5074,
-		Longitude: -0.1278,
-		City:      "London",
-		Region:    "England",
-		Country:   "United Kingdom",
-	}
+	// Unknown timezone - let the caller fall back to DefaultLocationResolver
+	return nil
 }
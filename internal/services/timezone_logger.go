@@ -55,7 +55,7 @@ func (tl *TimezoneLogger) LogTimezoneUsage(deviceID, cardID, timezone, location
 		LocalTime:      userTime.Format("15:04:05"),
 		LocalHour:      userTime.Hour(),
 		NatureSound:    timeHelper.GetNatureSoundForUserTime(timezone),
-		TimePeriod:     getTimePeriod(userTime.Hour()),
+		TimePeriod:     TimePeriod(userTime.Hour()),
 		Location:       location,
 		ServerTime:     serverTime,
 		TimeDifference: timeDiff,
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// TriviaQuestion is a multiple-choice question parents can ask after listening
+type TriviaQuestion struct {
+	Question    string   `json:"question"`
+	Choices     []string `json:"choices"`
+	AnswerIndex int      `json:"answer_index"`
+	Explanation string   `json:"explanation"`
+}
+
+// TriviaGenerator builds kid-friendly quiz questions from a bird's fact sheet
+type TriviaGenerator struct {
+	storage *BirdStorage
+}
+
+// Kid-friendly fallback distractors for when related species don't provide enough options
+var (
+	fallbackDietDistractors    = []string{"leaves", "acorns", "grass", "flowers", "mushrooms"}
+	fallbackHabitatDistractors = []string{"deserts", "ice sheets", "city rooftops", "caves", "mountain peaks"}
+	fallbackSizeDistractors    = []string{"5-8", "120-150", "250-300"}
+)
+
+// NewTriviaGenerator creates a new trivia generator
+func NewTriviaGenerator(storage *BirdStorage) *TriviaGenerator {
+	if storage == nil {
+		storage = NewBirdStorage("")
+	}
+	return &TriviaGenerator{
+		storage: storage,
+	}
+}
+
+// GenerateForBird creates three multiple-choice questions for a bird
+// Questions are stable for a given bird and day so the dashboard doesn't reshuffle on refresh
+func (tg *TriviaGenerator) GenerateForBird(birdName string, date time.Time) ([]TriviaQuestion, error) {
+	sheet, err := tg.storage.GetFactSheet(birdName)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := tg.storage.ListAllBirdMetadata()
+	if err != nil {
+		related = nil
+	}
+
+	var others []*FactSheet
+	for _, md := range related {
+		if !strings.EqualFold(md.CommonName, sheet.CommonName) {
+			others = append(others, NewFactSheetFromMetadata(md))
+		}
+	}
+
+	rng := rand.New(rand.NewSource(triviaSeed(sheet.CommonName, date)))
+	return tg.Generate(sheet, others, rng), nil
+}
+
+// Generate creates questions from a fact sheet, using related species for distractors
+func (tg *TriviaGenerator) Generate(sheet *FactSheet, others []*FactSheet, rng *rand.Rand) []TriviaQuestion {
+	var questions []TriviaQuestion
+
+	if q, ok := tg.dietQuestion(sheet, others, rng); ok {
+		questions = append(questions, q)
+	}
+	if q, ok := tg.habitatQuestion(sheet, others, rng); ok {
+		questions = append(questions, q)
+	}
+	if q, ok := tg.sizeQuestion(sheet, others, rng); ok {
+		questions = append(questions, q)
+	}
+
+	return questions
+}
+
+// dietQuestion asks what the bird likes to eat
+func (tg *TriviaGenerator) dietQuestion(sheet *FactSheet, others []*FactSheet, rng *rand.Rand) (TriviaQuestion, bool) {
+	if len(sheet.Diet()) == 0 {
+		return TriviaQuestion{}, false
+	}
+
+	answer := sheet.Diet()[rng.Intn(len(sheet.Diet()))]
+
+	var pool []string
+	for _, other := range others {
+		pool = append(pool, other.Diet()...)
+	}
+	pool = append(pool, fallbackDietDistractors...)
+
+	return buildTriviaQuestion(
+		fmt.Sprintf("What does the %s like to eat?", sheet.CommonName),
+		answer,
+		pickDistractors(pool, sheet.Diet(), 2, rng),
+		fmt.Sprintf("The %s eats %s.", sheet.CommonName, joinWithAnd(sheet.Diet())),
+		rng,
+	), true
+}
+
+// habitatQuestion asks where the bird can be found
+func (tg *TriviaGenerator) habitatQuestion(sheet *FactSheet, others []*FactSheet, rng *rand.Rand) (TriviaQuestion, bool) {
+	answer := sheet.PrimaryHabitat()
+	if answer == "" && len(sheet.Habitats()) > 0 {
+		answer = sheet.Habitats()[0]
+	}
+	if answer == "" {
+		return TriviaQuestion{}, false
+	}
+
+	var pool []string
+	for _, other := range others {
+		if other.PrimaryHabitat() != "" {
+			pool = append(pool, other.PrimaryHabitat())
+		}
+	}
+	pool = append(pool, fallbackHabitatDistractors...)
+
+	exclude := append([]string{answer}, sheet.Habitats()...)
+
+	// The explanation leads with the answer, which birds with only a primary habitat don't list
+	places := []string{answer}
+	for _, habitat := range sheet.Habitats() {
+		if !strings.EqualFold(habitat, answer) {
+			places = append(places, habitat)
+		}
+	}
+
+	return buildTriviaQuestion(
+		fmt.Sprintf("Where would you most likely find a %s?", sheet.CommonName),
+		answer,
+		pickDistractors(pool, exclude, 2, rng),
+		fmt.Sprintf("The %s lives in %s.", sheet.CommonName, joinWithAnd(places)),
+		rng,
+	), true
+}
+
+// sizeQuestion asks how long the bird is from beak to tail
+func (tg *TriviaGenerator) sizeQuestion(sheet *FactSheet, others []*FactSheet, rng *rand.Rand) (TriviaQuestion, bool) {
+	if sheet.LengthCM() == "" {
+		return TriviaQuestion{}, false
+	}
+
+	var pool []string
+	for _, other := range others {
+		if other.LengthCM() != "" {
+			pool = append(pool, other.LengthCM())
+		}
+	}
+	pool = append(pool, fallbackSizeDistractors...)
+
+	distractors := pickDistractors(pool, []string{sheet.LengthCM()}, 2, rng)
+	for i, d := range distractors {
+		distractors[i] = d + " centimeters"
+	}
+
+	return buildTriviaQuestion(
+		fmt.Sprintf("How long is a %s from beak to tail?", sheet.CommonName),
+		sheet.LengthCM()+" centimeters",
+		distractors,
+		fmt.Sprintf("A %s is about %s centimeters long.", sheet.CommonName, sheet.LengthCM()),
+		rng,
+	), true
+}
+
+// buildTriviaQuestion shuffles the answer in among the distractors
+func buildTriviaQuestion(question, answer string, distractors []string, explanation string, rng *rand.Rand) TriviaQuestion {
+	choices := append([]string{answer}, distractors...)
+	rng.Shuffle(len(choices), func(i, j int) {
+		choices[i], choices[j] = choices[j], choices[i]
+	})
+
+	answerIndex := 0
+	for i, choice := range choices {
+		if choice == answer {
+			answerIndex = i
+			break
+		}
+	}
+
+	return TriviaQuestion{
+		Question:    question,
+		Choices:     choices,
+		AnswerIndex: answerIndex,
+		Explanation: explanation,
+	}
+}
+
+// pickDistractors selects unique wrong answers from the pool that don't overlap the correct ones
+func pickDistractors(pool []string, exclude []string, count int, rng *rand.Rand) []string {
+	excluded := make(map[string]bool)
+	for _, e := range exclude {
+		excluded[strings.ToLower(e)] = true
+	}
+
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, p := range pool {
+		key := strings.ToLower(p)
+		if excluded[key] || seen[key] || overlapsExcluded(key, excluded) {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, p)
+	}
+
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	return candidates
+}
+
+// overlapsExcluded catches near-duplicates like "small fish" vs "fish" that would make two answers correct
+func overlapsExcluded(candidate string, excluded map[string]bool) bool {
+	for e := range excluded {
+		if strings.Contains(candidate, e) || strings.Contains(e, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinWithAnd joins items as a spoken list ("a, b, and c")
+func joinWithAnd(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+	}
+}
+
+// triviaSeed derives a stable seed from the bird name and date
+func triviaSeed(birdName string, date time.Time) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(birdName)))
+	h.Write([]byte(date.Format("2006-01-02")))
+	return int64(h.Sum64())
+}
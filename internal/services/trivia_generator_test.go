@@ -0,0 +1,41 @@
+package services
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHabitatExplanationNamesTheAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *BirdMetadata
+		want     string
+	}{
+		{
+			"primary habitat only",
+			&BirdMetadata{CommonName: "Snowy Owl", PrimaryHabitat: "arctic_tundra"},
+			"The Snowy Owl lives in arctic tundra.",
+		},
+		{
+			"primary habitat among others",
+			&BirdMetadata{CommonName: "American Robin", PrimaryHabitat: "gardens", Habitats: []string{"woodlands", "gardens"}},
+			"The American Robin lives in gardens and woodlands.",
+		},
+		{
+			"habitats only",
+			&BirdMetadata{CommonName: "Blue Jay", Habitats: []string{"forests", "parks", "yards"}},
+			"The Blue Jay lives in forests, parks, and yards.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, ok := (&TriviaGenerator{}).habitatQuestion(NewFactSheetFromMetadata(tt.metadata), nil, rand.New(rand.NewSource(1)))
+			if !ok {
+				t.Fatal("no habitat question")
+			}
+			if q.Explanation != tt.want {
+				t.Errorf("explanation = %q, want %q", q.Explanation, tt.want)
+			}
+		})
+	}
+}
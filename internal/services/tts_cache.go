@@ -0,0 +1,234 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+	"github.com/callen/bird-song-explorer/pkg/gcp"
+)
+
+// TTSRequest is everything that determines the audio ElevenLabs renders for a script
+type TTSRequest struct {
+	Text     string             `json:"text"`
+	VoiceID  string             `json:"voice_id"`
+	ModelID  string             `json:"model_id"`
+	Settings map[string]float64 `json:"voice_settings"`
+}
+
+// Key is a content hash of the request; identical text, voice, model, and settings share audio
+func (r TTSRequest) Key() string {
+	// json.Marshal sorts map keys, so equal settings always hash the same
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// TTSCacheBackend stores rendered audio by request key
+type TTSCacheBackend interface {
+	Get(key string) ([]byte, bool, error)
+	Put(key string, audio []byte) error
+}
+
+// TTSCache avoids paying to re-render speech whose text and voice haven't changed
+type TTSCache struct {
+	backend TTSCacheBackend
+
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+// NewTTSCache wraps a storage backend
+func NewTTSCache(backend TTSCacheBackend) *TTSCache {
+	return &TTSCache{backend: backend}
+}
+
+// NewTTSCacheFor keeps audio under tts_cache/ in bucket when backend is "gcs", otherwise in files
+// under dir (default data/tts_cache)
+func NewTTSCacheFor(backend, bucket, dir string) *TTSCache {
+	if backend == "gcs" {
+		return NewTTSCache(NewGCSTTSCache(bucket, "tts_cache/"))
+	}
+	if dir == "" {
+		dir = "data/tts_cache"
+	}
+	return NewTTSCache(NewDiskTTSCache(dir))
+}
+
+// GetOrRender returns cached audio for the request, or calls render and caches its result.
+// Cache failures are logged and fall through to rendering, so the cache can never block an update.
+func (tc *TTSCache) GetOrRender(req TTSRequest, render func() ([]byte, error)) ([]byte, bool, error) {
+	key := req.Key()
+
+	audio, found, err := tc.backend.Get(key)
+	if err != nil {
+		log.Printf("[TTS_CACHE] Lookup failed for %s: %v", key[:12], err)
+	}
+	if found {
+		tc.record(true)
+		return audio, true, nil
+	}
+
+	tc.record(false)
+	audio, err = render()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tc.backend.Put(key, audio); err != nil {
+		log.Printf("[TTS_CACHE] Failed to store %s: %v", key[:12], err)
+	}
+	return audio, false, nil
+}
+
+func (tc *TTSCache) record(hit bool) {
+	recordCacheLookup("tts", hit)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if hit {
+		tc.hits++
+	} else {
+		tc.misses++
+	}
+}
+
+// Stats returns hit and miss counts since startup
+func (tc *TTSCache) Stats() map[string]interface{} {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return map[string]interface{}{
+		"hits":   tc.hits,
+		"misses": tc.misses,
+	}
+}
+
+// DiskTTSCache stores audio as <dir>/<key[:2]>/<key>.mp3
+type DiskTTSCache struct {
+	dir string
+}
+
+// NewDiskTTSCache creates a file-backed cache rooted at dir
+func NewDiskTTSCache(dir string) *DiskTTSCache {
+	return &DiskTTSCache{dir: dir}
+}
+
+func (dc *DiskTTSCache) path(key string) string {
+	return filepath.Join(dc.dir, key[:2], key+".mp3")
+}
+
+// Get reads cached audio, reporting a miss if the file doesn't exist
+func (dc *DiskTTSCache) Get(key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(dc.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put writes audio atomically
+func (dc *DiskTTSCache) Put(key string, audio []byte) error {
+	return filex.AtomicWriteFile(dc.path(key), audio, 0644)
+}
+
+// GCSTTSCache stores audio as objects in a Cloud Storage bucket using the XML API and
+// application default credentials
+type GCSTTSCache struct {
+	bucket string
+	prefix string
+
+	once       sync.Once
+	httpClient *http.Client
+	clientErr  error
+}
+
+// NewGCSTTSCache creates a cache storing objects under prefix in bucket
+func NewGCSTTSCache(bucket string, prefix string) *GCSTTSCache {
+	return &GCSTTSCache{bucket: bucket, prefix: prefix}
+}
+
+// client creates the authenticated HTTP client on first use
+func (gc *GCSTTSCache) client() (*http.Client, error) {
+	gc.once.Do(func() {
+		client, err := gcp.DefaultClient(context.Background(), "GCS bucket "+gc.bucket, "https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			gc.clientErr = fmt.Errorf("failed to create GCS client: %w", err)
+			return
+		}
+		client.Timeout = 60 * time.Second
+		gc.httpClient = client
+	})
+	return gc.httpClient, gc.clientErr
+}
+
+func (gc *GCSTTSCache) objectURL(key string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s%s.mp3", gc.bucket, gc.prefix, key)
+}
+
+// Get downloads a cached object, reporting a miss on 404
+func (gc *GCSTTSCache) Get(key string) ([]byte, bool, error) {
+	client, err := gc.client()
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := client.Get(gc.objectURL(key))
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("GCS returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put uploads audio as an object
+func (gc *GCSTTSCache) Put(key string, audio []byte) error {
+	client, err := gc.client()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", gc.objectURL(key), bytes.NewReader(audio))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "audio/mpeg")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GCS upload returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
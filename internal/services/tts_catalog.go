@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UnavailableSongDir holds species whose narration exists but that have no usable song recording
+const UnavailableSongDir = "bird-song-unavailable"
+
+// RequiredTTSAssets are the narration files every voice needs for a species, matching the streaming tracks
+var RequiredTTSAssets = []string{"intro", "announcement", "description", "outro"}
+
+// regionSuffixes are appended to species directory names when a region has its own narration
+var regionSuffixes = []string{"north-america", "south-america", "europe", "asia", "oceania", "africa"}
+
+// CatalogAsset is one pre-recorded narration file
+type CatalogAsset struct {
+	Voice           string    `json:"voice"`
+	Name            string    `json:"name"` // Asset name without extension, e.g. "intro"
+	File            string    `json:"file"` // Path relative to the catalog root
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	ModifiedAt      time.Time `json:"modified_at"`
+}
+
+// CatalogSpecies summarizes the pre-recorded narration for one species directory
+type CatalogSpecies struct {
+	Directory       string         `json:"directory"`
+	Species         string         `json:"species"`          // Directory name without region suffix
+	Region          string         `json:"region,omitempty"` // Region suffix, if any
+	SongUnavailable bool           `json:"song_unavailable"`
+	Voices          []string       `json:"voices"`
+	Assets          []CatalogAsset `json:"assets"`
+	Missing         []string       `json:"missing"` // "voice/asset" pairs not yet recorded
+	LastUpdated     time.Time      `json:"last_updated"`
+}
+
+// TTSCatalog indexes the prerecorded_tts directory, laid out as <species>/<voice>/<asset>.mp3
+// with species lacking a song recording under bird-song-unavailable/
+type TTSCatalog struct {
+	root string
+}
+
+// NewTTSCatalog creates a catalog rooted at root, or "prerecorded_tts"
+func NewTTSCatalog(root string) *TTSCatalog {
+	if root == "" {
+		root = "prerecorded_tts"
+	}
+	return &TTSCatalog{root: root}
+}
+
+// Root returns the catalog directory
+func (tc *TTSCatalog) Root() string {
+	return tc.root
+}
+
+// List scans every species directory. Durations are probed with ffprobe when withDurations is set.
+// A species is expected to have every voice found anywhere in the catalog.
+func (tc *TTSCatalog) List(withDurations bool) ([]CatalogSpecies, error) {
+	var species []CatalogSpecies
+
+	for _, dir := range []string{tc.root, filepath.Join(tc.root, UnavailableSongDir)} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == UnavailableSongDir {
+				continue
+			}
+			scanned, err := tc.scanSpecies(filepath.Join(dir, entry.Name()), dir != tc.root, withDurations)
+			if err != nil {
+				return nil, err
+			}
+			species = append(species, *scanned)
+		}
+	}
+
+	allVoices := make(map[string]bool)
+	for _, s := range species {
+		for _, voice := range s.Voices {
+			allVoices[voice] = true
+		}
+	}
+	for i := range species {
+		species[i].Missing = missingAssets(&species[i], allVoices)
+	}
+
+	sort.Slice(species, func(i, j int) bool {
+		return species[i].Directory < species[j].Directory
+	})
+	return species, nil
+}
+
+// Get returns one species by directory name (with or without region suffix)
+func (tc *TTSCatalog) Get(name string, withDurations bool) (*CatalogSpecies, error) {
+	all, err := tc.List(withDurations)
+	if err != nil {
+		return nil, err
+	}
+
+	name = SpeciesDirName(name)
+	for i := range all {
+		if all[i].Directory == name || all[i].Species == name {
+			return &all[i], nil
+		}
+	}
+	return nil, fmt.Errorf("species %s not found in %s", name, tc.root)
+}
+
+// scanSpecies reads the voice directories of one species
+func (tc *TTSCatalog) scanSpecies(path string, songUnavailable bool, withDurations bool) (*CatalogSpecies, error) {
+	dirName := filepath.Base(path)
+	speciesName, region := SplitRegionSuffix(dirName)
+
+	species := &CatalogSpecies{
+		Directory:       dirName,
+		Species:         speciesName,
+		Region:          region,
+		SongUnavailable: songUnavailable,
+	}
+
+	voices, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	probe := withDurations && GetFFmpegCapabilities().Probe
+	for _, voice := range voices {
+		if !voice.IsDir() || strings.HasPrefix(voice.Name(), ".") {
+			continue
+		}
+		species.Voices = append(species.Voices, voice.Name())
+
+		files, _ := filepath.Glob(filepath.Join(path, voice.Name(), "*.mp3"))
+		sort.Strings(files)
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				continue
+			}
+
+			relPath, _ := filepath.Rel(tc.root, file)
+			asset := CatalogAsset{
+				Voice:      voice.Name(),
+				Name:       strings.TrimSuffix(filepath.Base(file), ".mp3"),
+				File:       relPath,
+				ModifiedAt: info.ModTime().UTC(),
+			}
+			if probe {
+				asset.DurationSeconds = probeDuration(file)
+			}
+			if asset.ModifiedAt.After(species.LastUpdated) {
+				species.LastUpdated = asset.ModifiedAt
+			}
+			species.Assets = append(species.Assets, asset)
+		}
+	}
+
+	return species, nil
+}
+
+// missingAssets lists required voice/asset pairs a species doesn't have
+func missingAssets(species *CatalogSpecies, allVoices map[string]bool) []string {
+	present := make(map[string]bool, len(species.Assets))
+	for _, asset := range species.Assets {
+		present[asset.Voice+"/"+asset.Name] = true
+	}
+
+	voices := make([]string, 0, len(allVoices))
+	for voice := range allVoices {
+		voices = append(voices, voice)
+	}
+	sort.Strings(voices)
+
+	missing := []string{}
+	for _, voice := range voices {
+		for _, name := range RequiredTTSAssets {
+			if !present[voice+"/"+name] {
+				missing = append(missing, voice+"/"+name)
+			}
+		}
+	}
+	return missing
+}
+
+// SpeciesDirName converts a common name like "American Robin" to the catalog's "american-robin" form
+func SpeciesDirName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "-"))
+}
+
+// SplitRegionSuffix separates a directory like "european-robin-europe" into species and region
+func SplitRegionSuffix(dirName string) (string, string) {
+	for _, region := range regionSuffixes {
+		if strings.HasSuffix(dirName, "-"+region) {
+			return strings.TrimSuffix(dirName, "-"+region), region
+		}
+	}
+	return dirName, ""
+}
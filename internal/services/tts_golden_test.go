@@ -0,0 +1,225 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/internal/services"
+	"github.com/callen/bird-song-explorer/internal/services/ttstest"
+	"github.com/callen/bird-song-explorer/pkg/randx"
+)
+
+var updateGoldens = flag.Bool("update", false, "Rewrite the TTS golden files with what was rendered")
+
+// The known combination every golden is rendered for
+const (
+	goldenBird    = "American Robin"
+	goldenMystery = "Northern Cardinal"
+	goldenDate    = "2026-04-22"
+	goldenVoice   = "ttstest-voice"
+	goldenCard    = "golden-card"
+	goldenCity    = "Portland"
+)
+
+var goldenBirdDetails = &models.Bird{
+	CommonName:     goldenBird,
+	ScientificName: "Turdus migratorius",
+	Family:         "Turdidae",
+	Order:          "Passeriformes",
+	Description:    "The American robin is a migratory songbird of the true thrush genus. It is widely distributed throughout North America.",
+}
+
+// goldenFixture is what a track role is narrated with
+type goldenFixture struct {
+	t       *testing.T
+	ctx     context.Context
+	server  *ttstest.Server
+	tts     *services.ElevenLabsTTS
+	day     time.Time
+	tempDir string
+}
+
+// TestTTSGoldens narrates every track role for a known bird, date, and voice against ttstest's
+// fake ElevenLabs API and compares the requests sent with the golden files, so a change to a
+// script, the moderation rules, or the pronunciation dictionary shows up as a diff. Run with
+// -update to rewrite the goldens after an intended change.
+func TestTTSGoldens(t *testing.T) {
+	// Goldens are rendered with the built-in rules and dictionary, without the Perspective hook or
+	// the dawn chorus line, which is what the services use until the server configures them
+	roles := []struct {
+		name string
+		run  func(f goldenFixture) error
+	}{
+		{services.VoiceRoleIntro, renderGoldenIntro},
+		{services.VoiceRoleGuide, renderGoldenGuide},
+		{services.VoiceRoleQuiz, renderGoldenQuiz},
+		{services.VoiceRoleHotspots, renderGoldenHotspots},
+		{services.VoiceRoleBirdHero, renderGoldenBirdHero},
+		{services.VoiceRoleWeekly, renderGoldenWeeklyFact},
+		{services.VoiceRoleCounting, renderGoldenListenAndCount},
+		{services.VoiceRoleOutro, renderGoldenOutro},
+	}
+
+	day, _ := time.Parse("2006-01-02", goldenDate)
+	for _, role := range roles {
+		t.Run(role.name, func(t *testing.T) {
+			// A fresh fake and an empty TTS cache, so every script reaches the fake
+			tempDir := t.TempDir()
+			server := ttstest.NewServer()
+			defer server.Close()
+			tts := server.TTS("")
+			tts.SetCache(services.NewTTSCacheFor("disk", "", filepath.Join(tempDir, "tts_cache")))
+
+			f := goldenFixture{t: t, ctx: context.Background(), server: server, tts: tts, day: day, tempDir: tempDir}
+			if err := role.run(f); err != nil {
+				t.Fatal(err)
+			}
+			requests := server.Requests()
+			if len(requests) == 0 {
+				t.Fatal("nothing was sent to text-to-speech")
+			}
+			got := formatGoldenRequests(requests)
+
+			path := filepath.Join("testdata", "tts_golden", role.name+".golden")
+			if *updateGoldens {
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("rendered requests differ from %s\n%s", path, diffGoldenLines(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// render narrates one script in the golden voice, checking the audio is what the fake rendered
+// for the text it was sent
+func (f goldenFixture) render(script string) error {
+	audio, _, err := f.tts.Render(f.ctx, script, goldenVoice)
+	if err != nil {
+		return err
+	}
+	requests := f.server.Requests()
+	if len(requests) == 0 || !bytes.Equal(audio, ttstest.Audio(requests[len(requests)-1].Text)) {
+		return fmt.Errorf("audio for %q isn't the fake's rendering", script)
+	}
+	return nil
+}
+
+func renderGoldenIntro(f goldenFixture) error {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		f.t.Skipf("time zone America/Los_Angeles not available: %v", err)
+	}
+	morning := time.Date(f.day.Year(), f.day.Month(), f.day.Day(), 7, 30, 0, 0, loc)
+	return f.render(services.LeadInScript(morning, goldenCity))
+}
+
+func renderGoldenGuide(f goldenFixture) error {
+	generator := services.NewFactGeneratorForLocale(services.FactGeneratorBasic, "", services.DefaultLocale,
+		randx.Daily(goldenDate, goldenCard))
+	return f.render(generator.GenerateFactScript(f.ctx, goldenBirdDetails, 0, 0))
+}
+
+func renderGoldenQuiz(f goldenFixture) error {
+	prompt, reveal := services.BuildQuizScript(goldenBird, goldenMystery)
+	if err := f.render(prompt); err != nil {
+		return err
+	}
+	return f.render(reveal)
+}
+
+func renderGoldenHotspots(f goldenFixture) error {
+	return f.render(services.BuildHotspotScript(goldenBird, []string{"Forest Park", "Sauvie Island"}))
+}
+
+func renderGoldenBirdHero(f goldenFixture) error {
+	script, err := services.BuildBirdHeroScript(goldenBird, "EN")
+	if err != nil {
+		return err
+	}
+	return f.render(script)
+}
+
+func renderGoldenWeeklyFact(f goldenFixture) error {
+	script, err := services.BuildWeeklyFactScript(goldenBird, services.WeeklyFactThemeOn(f.day), nil)
+	if err != nil {
+		return err
+	}
+	return f.render(script)
+}
+
+func renderGoldenListenAndCount(f goldenFixture) error {
+	prompt, after, answer, err := services.BuildCountingScripts(goldenBird, 3)
+	if err != nil {
+		return err
+	}
+	for _, script := range []string{prompt, after, answer} {
+		if err := f.render(script); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderGoldenOutro(f goldenFixture) error {
+	outros := services.NewOutroContentService(filepath.Join(f.tempDir, "outro_history.json"), f.tts)
+	_, err := outros.GenerateOutro(f.ctx, goldenCard, goldenBird, f.day, goldenVoice)
+	return err
+}
+
+// formatGoldenRequests writes one block per request, in the order they were sent
+func formatGoldenRequests(requests []ttstest.Request) []byte {
+	var buf bytes.Buffer
+	for i, request := range requests {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		settings := make([]string, 0, len(request.Settings))
+		for name, value := range request.Settings {
+			settings = append(settings, fmt.Sprintf("%s=%g", name, value))
+		}
+		sort.Strings(settings)
+
+		fmt.Fprintf(&buf, "voice: %s\n", request.VoiceID)
+		fmt.Fprintf(&buf, "model: %s\n", request.ModelID)
+		fmt.Fprintf(&buf, "format: %s\n", request.OutputFormat)
+		fmt.Fprintf(&buf, "settings: %s\n", strings.Join(settings, " "))
+		fmt.Fprintf(&buf, "text: %s\n", request.Text)
+	}
+	return buf.Bytes()
+}
+
+// diffGoldenLines lists the lines that differ between the golden and what was rendered
+func diffGoldenLines(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var buf strings.Builder
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&buf, "  line %d\n    - %s\n    + %s\n", i+1, w, g)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,149 @@
+// Package ttstest provides a fake ElevenLabs text-to-speech API, so narration can be exercised end
+// to end without an API key or billed characters. The fake checks requests have the shape the real
+// API expects, answers with silent MP3 audio whose length follows the text, and records every
+// request's text, voice, model, and voice settings for inspection.
+package ttstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/callen/bird-song-explorer/internal/services"
+)
+
+// APIKey is the key the fake accepts
+const APIKey = "ttstest-key"
+
+// wordsPerSecond paces the silent audio like unhurried narration
+const wordsPerSecond = 2.5
+
+// Request is a text-to-speech request the fake received
+type Request struct {
+	VoiceID      string
+	ModelID      string
+	Text         string
+	Settings     map[string]float64
+	OutputFormat string
+}
+
+type injectedFailure struct {
+	status int
+	body   string
+}
+
+// Server is a fake ElevenLabs API backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	failures []injectedFailure
+}
+
+// NewServer starts a fake ElevenLabs API. Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/text-to-speech/{voiceID}", s.handleTextToSpeech)
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// TTS returns an ElevenLabsTTS pointed at the fake with the model given ("" for the default).
+// It uses the process-wide pronunciations and moderation rules and the default TTS cache, so
+// callers wanting every render to reach the fake should SetCache to an empty directory.
+func (s *Server) TTS(modelID string) *services.ElevenLabsTTS {
+	tts := services.NewElevenLabsTTS(APIKey, modelID)
+	tts.SetBaseURL(s.URL + "/v1")
+	return tts
+}
+
+// Requests returns every text-to-speech request so far, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// FailNext makes the next request fail with status and body, before any checks
+func (s *Server) FailNext(status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, injectedFailure{status: status, body: body})
+}
+
+// Audio returns the silent MP3 the fake renders for text, for comparing with what a client
+// received
+func Audio(text string) []byte {
+	words := len(strings.Fields(text))
+	return services.SilentMP3(max(float64(words)/wordsPerSecond, 0.5))
+}
+
+func (s *Server) handleTextToSpeech(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if len(s.failures) > 0 {
+		failure := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		http.Error(w, failure.body, failure.status)
+		return
+	}
+	s.mu.Unlock()
+
+	if r.Header.Get("xi-api-key") != APIKey {
+		writeError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid API key")
+		return
+	}
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		writeError(w, http.StatusUnsupportedMediaType, "invalid_content_type", "Expected application/json")
+		return
+	}
+
+	var body struct {
+		Text          string             `json:"text"`
+		ModelID       string             `json:"model_id"`
+		VoiceSettings map[string]float64 `json:"voice_settings"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+	if strings.TrimSpace(body.Text) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "empty_text", "Text must not be empty")
+		return
+	}
+	for setting, value := range body.VoiceSettings {
+		if value < 0 || value > 1 {
+			writeError(w, http.StatusUnprocessableEntity, "invalid_voice_settings", fmt.Sprintf("%s must be between 0 and 1", setting))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		VoiceID:      r.PathValue("voiceID"),
+		ModelID:      body.ModelID,
+		Text:         body.Text,
+		Settings:     body.VoiceSettings,
+		OutputFormat: r.URL.Query().Get("output_format"),
+	})
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "audio/mpeg")
+	w.Write(Audio(body.Text))
+}
+
+// writeError answers with the error shape ElevenLabs uses
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"detail": map[string]string{"status": code, "message": message},
+	})
+}
@@ -4,8 +4,23 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/metrics"
 )
 
+// cacheRequests counts lookups in the update, TTS, and stream caches
+var cacheRequests = metrics.NewCounterVec("bird_explorer_cache_requests_total",
+	"Cache lookups by cache and result (hit or miss)", "cache", "result")
+
+// recordCacheLookup counts a lookup in the named cache
+func recordCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.Inc(cache, result)
+}
+
 // UpdateCache tracks which cards have been updated for which locations today
 type UpdateCache struct {
 	mu      sync.RWMutex
@@ -18,6 +33,15 @@ type CacheEntry struct {
 	BirdAudioURL string // Store the audio URL to avoid re-fetching
 	UpdatedAt    time.Time
 	LocationKey  string
+	Tracks       []CachedTrack // Tracks the update published, when known
+	ContentHash  string        // SHA-256 of the content the update posted, when known
+}
+
+// CachedTrack is one track an update published to the card
+type CachedTrack struct {
+	Chapter string
+	Title   string
+	URL     string
 }
 
 // NewUpdateCache creates a new cache
@@ -53,12 +77,10 @@ func (uc *UpdateCache) HasBeenUpdated(cardID string, date string, locationKey st
 	key := uc.GetCacheKey(cardID, date, locationKey)
 	entry, exists := uc.entries[key]
 
-	if !exists {
-		return false
-	}
-
 	// Check if the entry is from today
-	return entry.UpdatedAt.Format("2006-01-02") == date
+	updated := exists && entry.UpdatedAt.Format("2006-01-02") == date
+	recordCacheLookup("update", updated)
+	return updated
 }
 
 // GetBirdName returns the bird name for a cached update
@@ -76,8 +98,23 @@ func (uc *UpdateCache) GetBirdName(cardID string, date string, locationKey strin
 	return entry.BirdName
 }
 
+// GetEntry returns the cached update for a card, date, and location
+func (uc *UpdateCache) GetEntry(cardID string, date string, locationKey string) (CacheEntry, bool) {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+
+	entry, exists := uc.entries[uc.GetCacheKey(cardID, date, locationKey)]
+	return entry, exists
+}
+
 // MarkUpdated records that a card has been updated
 func (uc *UpdateCache) MarkUpdated(cardID string, date string, locationKey string, birdName string) {
+	uc.MarkPublished(cardID, date, locationKey, birdName, nil, "")
+}
+
+// MarkPublished records that a card has been updated, along with the tracks and content it was
+// published with
+func (uc *UpdateCache) MarkPublished(cardID string, date string, locationKey string, birdName string, tracks []CachedTrack, contentHash string) {
 	uc.mu.Lock()
 	defer uc.mu.Unlock()
 
@@ -86,6 +123,8 @@ func (uc *UpdateCache) MarkUpdated(cardID string, date string, locationKey strin
 		BirdName:    birdName,
 		UpdatedAt:   time.Now(),
 		LocationKey: locationKey,
+		Tracks:      tracks,
+		ContentHash: contentHash,
 	}
 }
 
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// UpdateQueue limits how many card updates run at once. When every slot is busy, or the TTS
+// budget is spent, jobs are refused so the caller can retry them later instead of piling more
+// work on; the webhook queue keeps refused events and retries them after RetryAfter.
+type UpdateQueue struct {
+	slots      chan struct{}
+	retryAfter time.Duration
+
+	mu      sync.Mutex
+	running map[string]bool // Keys of running jobs, so repeated deliveries run one update
+	budget  func() bool     // Optional extra saturation check (e.g. remaining TTS budget)
+}
+
+// NewUpdateQueue creates a queue running at most maxConcurrent jobs
+func NewUpdateQueue(maxConcurrent int, retryAfter time.Duration) *UpdateQueue {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	if retryAfter <= 0 {
+		retryAfter = 30 * time.Second
+	}
+
+	return &UpdateQueue{
+		slots:      make(chan struct{}, maxConcurrent),
+		retryAfter: retryAfter,
+		running:    make(map[string]bool),
+	}
+}
+
+// SetBudgetCheck registers a function reporting whether there's budget left for new work
+func (q *UpdateQueue) SetBudgetCheck(hasBudget func() bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.budget = hasBudget
+}
+
+// RetryAfter is how long callers refused by a saturated queue should wait before trying again
+func (q *UpdateQueue) RetryAfter() time.Duration {
+	return q.retryAfter
+}
+
+// Saturated reports whether new jobs would be refused
+func (q *UpdateQueue) Saturated() bool {
+	q.mu.Lock()
+	budget := q.budget
+	q.mu.Unlock()
+
+	if budget != nil && !budget() {
+		return true
+	}
+	return len(q.slots) == cap(q.slots)
+}
+
+// TryRun starts the job in the background if a slot is free, there's budget left, and no job with
+// the same key is running, and reports whether it started
+func (q *UpdateQueue) TryRun(key string, job func()) bool {
+	q.mu.Lock()
+	budget := q.budget
+	if q.running[key] {
+		q.mu.Unlock()
+		return false
+	}
+	q.mu.Unlock()
+
+	if budget != nil && !budget() {
+		return false
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+	default:
+		return false
+	}
+
+	q.mu.Lock()
+	if q.running[key] {
+		q.mu.Unlock()
+		<-q.slots
+		return false
+	}
+	q.running[key] = true
+	q.mu.Unlock()
+
+	go func() {
+		defer func() {
+			q.mu.Lock()
+			delete(q.running, key)
+			q.mu.Unlock()
+			<-q.slots
+		}()
+		job()
+	}()
+	return true
+}
+
+// Stats returns queue occupancy for monitoring
+func (q *UpdateQueue) Stats() map[string]interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return map[string]interface{}{
+		"running":  len(q.slots),
+		"capacity": cap(q.slots),
+	}
+}
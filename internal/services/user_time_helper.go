@@ -1,20 +1,58 @@
 package services
 
 import (
-	"fmt"
+	"log/slog"
 	"time"
 )
 
+// Content modes chosen by the listener's local time
+const (
+	ContentModeDay   = "day"
+	ContentModeNight = "night"
+)
+
 // UserTimeHelper helps determine the user's local time
 type UserTimeHelper struct {
 	timezoneService *TimezoneLocationService
+	bedtimeHour     int
+	wakeHour        int
 }
 
-// NewUserTimeHelper creates a new user time helper
+// NewUserTimeHelper creates a new user time helper with bedtime from 7pm to 6am
 func NewUserTimeHelper() *UserTimeHelper {
 	return &UserTimeHelper{
 		timezoneService: NewTimezoneLocationService(),
+		bedtimeHour:     19,
+		wakeHour:        6,
+	}
+}
+
+// SetBedtime sets the local hours (0-23) night mode starts and ends
+func (uth *UserTimeHelper) SetBedtime(bedtimeHour, wakeHour int) {
+	uth.bedtimeHour = bedtimeHour
+	uth.wakeHour = wakeHour
+}
+
+// IsBedtimeAt reports whether a local time falls in bedtime hours, which may span midnight
+func (uth *UserTimeHelper) IsBedtimeAt(localTime time.Time) bool {
+	hour := localTime.Hour()
+	if uth.bedtimeHour <= uth.wakeHour {
+		return hour >= uth.bedtimeHour && hour < uth.wakeHour
+	}
+	return hour >= uth.bedtimeHour || hour < uth.wakeHour
+}
+
+// IsBedtime reports whether it's after bedtime for the user
+func (uth *UserTimeHelper) IsBedtime(deviceTimezone string) bool {
+	return uth.IsBedtimeAt(uth.GetUserLocalTime(deviceTimezone))
+}
+
+// ContentModeAt returns ContentModeNight during bedtime hours, otherwise ContentModeDay
+func (uth *UserTimeHelper) ContentModeAt(localTime time.Time) string {
+	if uth.IsBedtimeAt(localTime) {
+		return ContentModeNight
 	}
+	return ContentModeDay
 }
 
 // GetUserLocalTime returns the user's local time based on their timezone
@@ -23,13 +61,13 @@ func (uth *UserTimeHelper) GetUserLocalTime(deviceTimezone string) time.Time {
 	loc, err := time.LoadLocation(deviceTimezone)
 	if err != nil {
 		// Fallback to server time if timezone is invalid
-		fmt.Printf("[USER_TIME] Failed to load timezone %s: %v, using server time\n", deviceTimezone, err)
+		slog.Warn("[USER_TIME] Failed to load timezone, using server time", "timezone", deviceTimezone, "error", err)
 		return time.Now()
 	}
 
 	// Return current time in user's timezone
 	userTime := time.Now().In(loc)
-	fmt.Printf("[USER_TIME] User timezone: %s, Local time: %s\n", deviceTimezone, userTime.Format("15:04:05"))
+	slog.Info("[USER_TIME] Resolved user time", "timezone", deviceTimezone, "local_time", userTime.Format("15:04:05"))
 	return userTime
 }
 
@@ -41,29 +79,7 @@ func (uth *UserTimeHelper) GetUserLocalHour(deviceTimezone string) int {
 
 // GetNatureSoundForUserTime selects appropriate nature sound based on user's local time
 func (uth *UserTimeHelper) GetNatureSoundForUserTime(deviceTimezone string) string {
-	hour := uth.GetUserLocalHour(deviceTimezone)
-
-	// Select nature sound based on user's local hour
-	switch {
-	case hour >= 5 && hour < 9:
-		// Early morning (5am-9am)
-		return "morning_birds"
-	case hour >= 9 && hour < 12:
-		// Late morning (9am-12pm)
-		return "forest"
-	case hour >= 12 && hour < 17:
-		// Afternoon (12pm-5pm)
-		return "meadow"
-	case hour >= 17 && hour < 20:
-		// Evening (5pm-8pm)
-		return "gentle_rain"
-	case hour >= 20 && hour < 22:
-		// Late evening (8pm-10pm)
-		return "stream"
-	default:
-		// Night (10pm-5am)
-		return "night"
-	}
+	return natureSoundForHour(uth.GetUserLocalHour(deviceTimezone))
 }
 
 // GetTimeOfDayGreeting returns a greeting based on user's local time
@@ -100,12 +116,13 @@ func (uth *UserTimeHelper) GetUserTimeContext(deviceTimezone string) map[string]
 		"greeting":     uth.GetTimeOfDayGreeting(deviceTimezone),
 		"is_daytime":   uth.IsUserDaytime(deviceTimezone),
 		"nature_sound": uth.GetNatureSoundForUserTime(deviceTimezone),
-		"time_period":  getTimePeriod(hour),
+		"time_period":  TimePeriod(hour),
+		"content_mode": uth.ContentModeAt(userTime),
 	}
 }
 
-// getTimePeriod returns a descriptive time period
-func getTimePeriod(hour int) string {
+// TimePeriod returns a descriptive time period for a local hour
+func TimePeriod(hour int) string {
 	switch {
 	case hour >= 5 && hour < 7:
 		return "early_morning"
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"hash/fnv"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Track roles a voice cast can give their own narrators. These are the tracks narrated live; the
+// intro, announcement, and description are pre-recorded by their own narrators, as is the outro
+// unless outro rotation narrates it and the description unless guide calls narrate it. The intro
+// role voices the greeting before a dynamic intro.
+const (
+	VoiceRoleIntro    = "intro"
+	VoiceRoleQuiz     = "quiz"
+	VoiceRoleHotspots = "hotspots"
+	VoiceRoleBirdHero = "bird_hero"
+	VoiceRoleWeekly   = "weekly_fact"
+	VoiceRoleCounting = "listen_count"
+	VoiceRoleGuide    = "guide"
+	VoiceRoleOutro    = "outro"
+)
+
+var voiceRoles = map[string]bool{
+	VoiceRoleIntro:    true,
+	VoiceRoleQuiz:     true,
+	VoiceRoleHotspots: true,
+	VoiceRoleBirdHero: true,
+	VoiceRoleWeekly:   true,
+	VoiceRoleCounting: true,
+	VoiceRoleGuide:    true,
+	VoiceRoleOutro:    true,
+}
+
+// VoiceCast maps track roles to the voices they rotate through, one voice per day
+type VoiceCast map[string][]string
+
+// VoiceManager maps narration languages to ElevenLabs voice IDs, so French scripts are read by a
+// French voice rather than an English one attempting the accent
+type VoiceManager struct {
+	defaultVoiceID string
+	voices         map[string]string    // locale -> ElevenLabs voice ID
+	casts          map[string]VoiceCast // locale -> voices for each track role
+}
+
+// NewVoiceManager parses a "fr=voiceID;de=voiceID" spec (LOCALE_VOICES). Locales without an
+// entry, including English unless listed, use defaultVoiceID.
+func NewVoiceManager(spec string, defaultVoiceID string) *VoiceManager {
+	vm := &VoiceManager{
+		defaultVoiceID: defaultVoiceID,
+		voices:         make(map[string]string),
+		casts:          make(map[string]VoiceCast),
+	}
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		locale, voiceID, ok := strings.Cut(entry, "=")
+		locale, voiceID = strings.TrimSpace(locale), strings.TrimSpace(voiceID)
+		if !ok || voiceID == "" || NormalizeLocale(locale) != strings.ToLower(locale) {
+			log.Printf("[VOICES] Ignoring invalid locale voice %q", entry)
+			continue
+		}
+		vm.voices[strings.ToLower(locale)] = voiceID
+	}
+
+	return vm
+}
+
+// VoiceForLocale returns the voice ID configured for a locale, falling back to the default voice
+func (vm *VoiceManager) VoiceForLocale(locale string) string {
+	if voiceID, ok := vm.voices[NormalizeLocale(locale)]; ok {
+		return voiceID
+	}
+	return vm.defaultVoiceID
+}
+
+// SetVoiceCasts parses a "quiz=voiceA,voiceB;hotspots=voiceC;fr:quiz=voiceD" spec (VOICE_CASTS).
+// Entries without a locale prefix cast the default locale. Roles left out of a locale's cast
+// keep the locale's voice.
+func (vm *VoiceManager) SetVoiceCasts(spec string) {
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, voiceList, ok := strings.Cut(entry, "=")
+		locale, role, hasLocale := strings.Cut(strings.TrimSpace(key), ":")
+		if !hasLocale {
+			locale, role = DefaultLocale, locale
+		}
+		locale, role = strings.ToLower(strings.TrimSpace(locale)), strings.ToLower(strings.TrimSpace(role))
+
+		var voices []string
+		for _, voiceID := range strings.Split(voiceList, ",") {
+			if voiceID = strings.TrimSpace(voiceID); voiceID != "" {
+				voices = append(voices, voiceID)
+			}
+		}
+		if !ok || len(voices) == 0 || !voiceRoles[role] || NormalizeLocale(locale) != locale {
+			log.Printf("[VOICES] Ignoring invalid voice cast %q", entry)
+			continue
+		}
+
+		if vm.casts[locale] == nil {
+			vm.casts[locale] = make(VoiceCast)
+		}
+		vm.casts[locale][role] = voices
+	}
+}
+
+// VoiceForRole returns the voice narrating a track role on a day. Roles with several cast voices
+// rotate daily, each from its own starting point so roles sharing voices don't move in step.
+// Uncast roles use the locale's voice.
+func (vm *VoiceManager) VoiceForRole(locale string, role string, day time.Time) string {
+	locale = NormalizeLocale(locale)
+	voices := vm.casts[locale][role]
+	if len(voices) == 0 {
+		return vm.VoiceForLocale(locale)
+	}
+
+	year, month, date := day.Date()
+	dayNumber := time.Date(year, month, date, 0, 0, 0, 0, time.UTC).Unix() / 86400
+	hash := fnv.New32a()
+	hash.Write([]byte(role))
+	return voices[(uint64(dayNumber)+uint64(hash.Sum32()))%uint64(len(voices))]
+}
+
+// Locales returns the locales with a configured voice, sorted
+func (vm *VoiceManager) Locales() []string {
+	locales := make([]string, 0, len(vm.voices))
+	for locale := range vm.voices {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// Manifest locations for pre-recorded narration, one per asset directory
+const (
+	IntroManifestPath = "assets/final_intros/manifest.json"
+	OutroManifestPath = "assets/final_outros/manifest.json"
+)
+
+// StaticOutroTypes are the outro categories rotated through the week by getOutroType
+var StaticOutroTypes = []string{"joke", "wisdom", "teaser", "challenge", "funfact"}
+
+// defaultNarratorVoices are the narrators recorded before the outro manifest existed
+var defaultNarratorVoices = []string{"Amelia", "Antoni", "Charlotte", "Peter", "Drake", "Sally"}
+
+// VoiceAssetFile is one rendered narration file recorded in a manifest
+type VoiceAssetFile struct {
+	File            string  `json:"file"`               // Path relative to the manifest directory
+	Kind            string  `json:"kind"`               // "intro" or an outro type
+	Ambience        string  `json:"ambience,omitempty"` // Nature sound mixed underneath, if any
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// VoiceAssets lists the files generated for one narrator voice
+type VoiceAssets struct {
+	VoiceID     string           `json:"voice_id"`         // ElevenLabs voice ID
+	Locale      string           `json:"locale,omitempty"` // Narration language; empty means English
+	GeneratedAt time.Time        `json:"generated_at"`
+	Files       []VoiceAssetFile `json:"files"`
+}
+
+// VoiceManifest records which narrator voices have rendered assets in a directory
+type VoiceManifest struct {
+	path   string
+	Voices map[string]*VoiceAssets `json:"voices"`
+}
+
+// LoadVoiceManifest reads a manifest, returning an empty one if the file does not exist yet
+func LoadVoiceManifest(path string) (*VoiceManifest, error) {
+	manifest := &VoiceManifest{
+		path:   path,
+		Voices: make(map[string]*VoiceAssets),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if manifest.Voices == nil {
+		manifest.Voices = make(map[string]*VoiceAssets)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest back to the path it was loaded from
+func (m *VoiceManifest) Save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return filex.AtomicWriteFile(m.path, append(data, '\n'), 0644)
+}
+
+// SetVoice replaces the recorded assets for a voice
+func (m *VoiceManifest) SetVoice(voiceName string, assets *VoiceAssets) {
+	m.Voices[voiceName] = assets
+}
+
+// VoiceNames returns the manifest's voices in sorted order
+func (m *VoiceManifest) VoiceNames() []string {
+	names := make([]string, 0, len(m.Voices))
+	for name := range m.Voices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate probes every file recorded for a voice and returns a description of each problem found.
+// Files shorter than minSeconds are treated as failed renders.
+func (m *VoiceManifest) Validate(voiceName string, minSeconds float64) []string {
+	assets, ok := m.Voices[voiceName]
+	if !ok {
+		return []string{fmt.Sprintf("voice %s is not in %s", voiceName, m.path)}
+	}
+
+	var problems []string
+	dir := filepath.Dir(m.path)
+	for i := range assets.Files {
+		file := &assets.Files[i]
+		path := filepath.Join(dir, file.File)
+
+		if _, err := os.Stat(path); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: missing", file.File))
+			continue
+		}
+
+		if !GetFFmpegCapabilities().Probe {
+			continue
+		}
+
+		duration := probeDuration(path)
+		if duration < minSeconds {
+			problems = append(problems, fmt.Sprintf("%s: duration %.2fs is below %.2fs", file.File, duration, minSeconds))
+			continue
+		}
+		file.DurationSeconds = duration
+	}
+
+	return problems
+}
+
+// narratorVoices returns the built-in narrators plus any voices added to the outro manifest
+func narratorVoices() []string {
+	voices := append([]string(nil), defaultNarratorVoices...)
+
+	manifest, err := LoadVoiceManifest(OutroManifestPath)
+	if err != nil {
+		return voices
+	}
+	for _, name := range manifest.VoiceNames() {
+		known := false
+		for _, voice := range voices {
+			known = known || voice == name
+		}
+		if !known {
+			voices = append(voices, name)
+		}
+	}
+	return voices
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/models"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// Weather conditions, from wettest to driest
+const (
+	WeatherStorm  = "storm"
+	WeatherSnow   = "snow"
+	WeatherRain   = "rain"
+	WeatherFog    = "fog"
+	WeatherCloudy = "cloudy"
+	WeatherClear  = "clear"
+)
+
+// Weather is the current weather at a listener's location
+type Weather struct {
+	Condition    string
+	TemperatureC float64
+	WindKPH      float64
+	IsDay        bool
+}
+
+// WeatherService reports the current weather at a coordinate
+type WeatherService interface {
+	CurrentWeather(ctx context.Context, lat, lng float64) (*Weather, error)
+}
+
+// NewWeatherService returns the cached weather service for provider: Open-Meteo, which needs no
+// key, at openMeteoURL ("" for Open-Meteo's own), or nil for "none", which disables lookups
+func NewWeatherService(provider string, openMeteoURL string) WeatherService {
+	switch provider {
+	case "none":
+		return nil
+	case "", "open-meteo":
+	default:
+		log.Printf("[WEATHER] Unknown weather provider %q, using Open-Meteo", provider)
+	}
+	return NewCachingWeatherService(NewOpenMeteoWeather(openMeteoURL), weatherCacheTTL)
+}
+
+// OpenMeteoWeather reads current conditions from the Open-Meteo forecast API
+type OpenMeteoWeather struct {
+	baseURL string
+}
+
+// NewOpenMeteoWeather creates a weather service for the server at baseURL ("" for Open-Meteo's)
+func NewOpenMeteoWeather(baseURL string) *OpenMeteoWeather {
+	if baseURL == "" {
+		baseURL = "https://api.open-meteo.com"
+	}
+	return &OpenMeteoWeather{baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (w *OpenMeteoWeather) CurrentWeather(ctx context.Context, lat, lng float64) (*Weather, error) {
+	query := url.Values{}
+	query.Set("latitude", fmt.Sprintf("%.3f", lat))
+	query.Set("longitude", fmt.Sprintf("%.3f", lng))
+	query.Set("current", "temperature_2m,weather_code,wind_speed_10m,is_day")
+
+	resp, err := httpx.Get(ctx, httpx.Default, w.baseURL+"/v1/forecast?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open-meteo request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("open-meteo returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Current *struct {
+			Temperature float64 `json:"temperature_2m"`
+			WeatherCode int     `json:"weather_code"`
+			WindSpeed   float64 `json:"wind_speed_10m"`
+			IsDay       int     `json:"is_day"`
+		} `json:"current"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode open-meteo response: %w", err)
+	}
+	if result.Current == nil {
+		return nil, fmt.Errorf("open-meteo response has no current weather")
+	}
+
+	return &Weather{
+		Condition:    weatherCondition(result.Current.WeatherCode),
+		TemperatureC: result.Current.Temperature,
+		WindKPH:      result.Current.WindSpeed,
+		IsDay:        result.Current.IsDay == 1,
+	}, nil
+}
+
+// weatherCondition groups a WMO weather interpretation code into a condition
+func weatherCondition(code int) string {
+	switch {
+	case code >= 95:
+		return WeatherStorm
+	case code >= 71 && code <= 77, code == 85, code == 86:
+		return WeatherSnow
+	case code >= 51 && code <= 67, code >= 80 && code <= 82:
+		return WeatherRain
+	case code == 45, code == 48:
+		return WeatherFog
+	case code == 2, code == 3:
+		return WeatherCloudy
+	default:
+		return WeatherClear
+	}
+}
+
+// weatherCacheTTL is how long current conditions are reused; forecasts update about hourly
+const weatherCacheTTL = 30 * time.Minute
+
+// weatherFailureTTL is how long a failed lookup is remembered before it's retried
+const weatherFailureTTL = 10 * time.Minute
+
+// CachingWeatherService caches another weather service's results by coordinates rounded to
+// about 10 km, so neighbouring households share one lookup
+type CachingWeatherService struct {
+	weather WeatherService
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]weatherEntry
+}
+
+type weatherEntry struct {
+	weather *Weather
+	err     error
+	expires time.Time
+}
+
+// NewCachingWeatherService wraps weather with a cache keeping conditions for ttl
+func NewCachingWeatherService(weather WeatherService, ttl time.Duration) *CachingWeatherService {
+	return &CachingWeatherService{
+		weather: weather,
+		ttl:     ttl,
+		entries: make(map[string]weatherEntry),
+	}
+}
+
+func (w *CachingWeatherService) CurrentWeather(ctx context.Context, lat, lng float64) (*Weather, error) {
+	key := fmt.Sprintf("%.1f,%.1f", math.Round(lat*10)/10, math.Round(lng*10)/10)
+
+	w.mu.Lock()
+	entry, cached := w.entries[key]
+	w.mu.Unlock()
+	if cached && time.Now().Before(entry.expires) {
+		return entry.weather, entry.err
+	}
+
+	weather, err := w.weather.CurrentWeather(ctx, lat, lng)
+	if ctx.Err() != nil {
+		// A cancelled lookup says nothing about the weather, so it isn't cached
+		return nil, ctx.Err()
+	}
+	entry = weatherEntry{weather: weather, err: err, expires: time.Now().Add(w.ttl)}
+	if err != nil {
+		log.Printf("[WEATHER] Weather lookup for %s failed: %v", key, err)
+		entry.expires = time.Now().Add(weatherFailureTTL)
+	}
+
+	w.mu.Lock()
+	w.entries[key] = entry
+	// Expired entries are dropped as the cache grows, so it stays bounded by active households
+	if len(w.entries) > 1000 {
+		now := time.Now()
+		for k, e := range w.entries {
+			if now.After(e.expires) {
+				delete(w.entries, k)
+			}
+		}
+	}
+	w.mu.Unlock()
+	return weather, err
+}
+
+// Temperatures and wind speed that get their own line whatever the sky is doing
+const (
+	hotWeatherC     = 30.0
+	freezingWeather = -5.0
+	windyWeatherKPH = 40.0
+)
+
+// WeatherLine returns a sentence tying the weather to listening for the bird, or "" for no weather
+func WeatherLine(weather *Weather, birdName string) string {
+	if weather == nil {
+		return ""
+	}
+
+	switch weather.Condition {
+	case WeatherStorm:
+		return fmt.Sprintf("There's a storm outside today, so let's stay cozy indoors and listen for the %s together!", birdName)
+	case WeatherSnow:
+		return fmt.Sprintf("It's snowy outside today - watch from a warm window to see if a %s comes to visit!", birdName)
+	case WeatherRain:
+		return fmt.Sprintf("It's a rainy day - a great day to listen for the %s from your window!", birdName)
+	case WeatherFog:
+		return fmt.Sprintf("It's foggy today, and birds call to find each other in the mist - perfect for listening for the %s!", birdName)
+	}
+
+	switch {
+	case weather.WindKPH >= windyWeatherKPH:
+		return fmt.Sprintf("It's a windy day! Birds sing from sheltered spots when it's breezy - can you hear the %s?", birdName)
+	case weather.TemperatureC >= hotWeatherC:
+		return fmt.Sprintf("It's a hot day! Birds look for shade and water, so a birdbath might bring a %s your way!", birdName)
+	case weather.TemperatureC <= freezingWeather:
+		return fmt.Sprintf("Brrr, it's freezing today! Birds like the %s fluff up their feathers to stay warm!", birdName)
+	case !weather.IsDay:
+		return fmt.Sprintf("It's a quiet night outside - a lovely time to listen to the %s with us!", birdName)
+	case weather.Condition == WeatherCloudy:
+		return fmt.Sprintf("It's a cloudy day, but birds sing rain or shine - let's listen for the %s!", birdName)
+	default:
+		return fmt.Sprintf("The sun is shining - a perfect day to step outside and listen for the %s!", birdName)
+	}
+}
+
+// weatherFactGenerator opens the scripts of the generator it wraps with a line about the
+// listener's current weather. Without a location or weather, the scripts are unchanged.
+type weatherFactGenerator struct {
+	FactGenerator
+	weather WeatherService
+}
+
+// WithWeather opens the generator's scripts with the weather at the listener's location. The
+// lines are English, so it should only wrap English generators.
+func WithWeather(generator FactGenerator, weather WeatherService) FactGenerator {
+	if weather == nil {
+		return generator
+	}
+	return weatherFactGenerator{FactGenerator: generator, weather: weather}
+}
+
+func (g weatherFactGenerator) GenerateFactScript(ctx context.Context, bird *models.Bird, latitude, longitude float64) string {
+	script := g.FactGenerator.GenerateFactScript(ctx, bird, latitude, longitude)
+	if line, _ := g.line(ctx, bird, latitude, longitude); line != "" {
+		return line + " " + script
+	}
+	return script
+}
+
+func (g weatherFactGenerator) GenerateFactTranscript(ctx context.Context, bird *models.Bird, latitude, longitude float64) *ScriptTranscript {
+	transcript := g.FactGenerator.GenerateFactTranscript(ctx, bird, latitude, longitude)
+	line, condition := g.line(ctx, bird, latitude, longitude)
+	if line == "" || transcript == nil {
+		return transcript
+	}
+
+	transcript.Script = line + " " + transcript.Script
+	transcript.Sentences = append([]SentenceProvenance{{Text: line, Source: SourceWeather, Detail: condition}}, transcript.Sentences...)
+	return transcript
+}
+
+// line returns the weather line for the location and the condition it describes
+func (g weatherFactGenerator) line(ctx context.Context, bird *models.Bird, latitude, longitude float64) (string, string) {
+	if bird == nil || (latitude == 0 && longitude == 0) {
+		return "", ""
+	}
+	weather, err := g.weather.CurrentWeather(ctx, latitude, longitude)
+	if err != nil || weather == nil {
+		return "", ""
+	}
+	return WeatherLine(weather, bird.CommonName), weather.Condition
+}
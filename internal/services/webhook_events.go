@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Yoto webhook event types
+const (
+	WebhookCardPlayed   = "card.played"
+	WebhookCardStopped  = "card.stopped"
+	WebhookDeviceOnline = "device.online"
+)
+
+// WebhookEnvelope holds the fields every Yoto webhook event carries
+type WebhookEnvelope struct {
+	EventID   string `json:"eventId"`
+	EventType string `json:"eventType"`
+	CardID    string `json:"cardId,omitempty"`
+	DeviceID  string `json:"deviceId,omitempty"`
+}
+
+// Envelope returns the common event fields
+func (e WebhookEnvelope) Envelope() WebhookEnvelope {
+	return e
+}
+
+// WebhookEvent is a decoded webhook payload; type-switch on it for the typed fields
+type WebhookEvent interface {
+	Envelope() WebhookEnvelope
+}
+
+// CardPlayedEvent is sent when a card is inserted or a playlist starts
+type CardPlayedEvent struct {
+	WebhookEnvelope
+	ChapterKey string `json:"chapterKey,omitempty"`
+	TrackKey   string `json:"trackKey,omitempty"`
+}
+
+// CardStoppedEvent is sent when playback stops or the card is removed
+type CardStoppedEvent struct {
+	WebhookEnvelope
+	ChapterKey string  `json:"chapterKey,omitempty"`
+	TrackKey   string  `json:"trackKey,omitempty"`
+	Position   float64 `json:"position,omitempty"` // Seconds into the track
+}
+
+// DeviceOnlineEvent is sent when a player connects
+type DeviceOnlineEvent struct {
+	WebhookEnvelope
+}
+
+// UnknownWebhookEvent is an event type with no registered decoder; Payload is the raw body
+type UnknownWebhookEvent struct {
+	WebhookEnvelope
+	Payload json.RawMessage `json:"-"`
+}
+
+// webhookDecoders create the typed struct for each known event type. Payloads without an
+// eventType are the original card event shape and decode as card.played.
+var webhookDecoders = map[string]func() WebhookEvent{
+	"":                  func() WebhookEvent { return &CardPlayedEvent{} },
+	WebhookCardPlayed:   func() WebhookEvent { return &CardPlayedEvent{} },
+	WebhookCardStopped:  func() WebhookEvent { return &CardStoppedEvent{} },
+	WebhookDeviceOnline: func() WebhookEvent { return &DeviceOnlineEvent{} },
+}
+
+// webhookRouteType maps an event type to the type its handlers are registered under
+func webhookRouteType(eventType string) string {
+	if eventType == "" {
+		return WebhookCardPlayed
+	}
+	return eventType
+}
+
+// RegisterWebhookEventType adds a decoder for a new event type. Call it during startup, before
+// webhooks are served.
+func RegisterWebhookEventType(eventType string, newEvent func() WebhookEvent) {
+	webhookDecoders[eventType] = newEvent
+}
+
+// DecodeWebhookEvent decodes a webhook payload into the typed struct for its eventType.
+// Unrecognized types decode as *UnknownWebhookEvent so they can still be routed.
+func DecodeWebhookEvent(payload []byte) (WebhookEvent, error) {
+	var envelope WebhookEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	decoder, known := webhookDecoders[envelope.EventType]
+	if !known {
+		return &UnknownWebhookEvent{WebhookEnvelope: envelope, Payload: payload}, nil
+	}
+
+	event := decoder()
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", webhookRouteType(envelope.EventType), err)
+	}
+	return event, nil
+}
+
+// WebhookHandler handles one decoded event. entry is the queued delivery it came from. Handlers
+// may run more than once for an event, because a failure in any handler retries the whole entry.
+type WebhookHandler func(ctx context.Context, event WebhookEvent, entry WebhookQueueEntry) error
+
+// WebhookDispatcher routes decoded webhook events to the handlers registered for their type, so
+// features can react to player events without changing the webhook endpoint
+type WebhookDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string][]WebhookHandler
+}
+
+// NewWebhookDispatcher creates a dispatcher with no handlers
+func NewWebhookDispatcher() *WebhookDispatcher {
+	return &WebhookDispatcher{handlers: make(map[string][]WebhookHandler)}
+}
+
+// On registers a handler for an event type. Handlers run in registration order.
+func (d *WebhookDispatcher) On(eventType string, handler WebhookHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// HandlerCount returns how many handlers are registered for the event type
+func (d *WebhookDispatcher) HandlerCount(eventType string) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.handlers[webhookRouteType(eventType)])
+}
+
+// Dispatch runs every handler registered for the event's type, returning their combined errors.
+// Every handler runs even if an earlier one fails.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, event WebhookEvent, entry WebhookQueueEntry) error {
+	d.mu.RLock()
+	handlers := d.handlers[webhookRouteType(event.Envelope().EventType)]
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/store"
+)
+
+// maxWebhookAttempts is how many times an entry is processed before it's dropped
+const maxWebhookAttempts = 10
+
+// WebhookQueueEntry is one accepted webhook delivery waiting for the consumer
+type WebhookQueueEntry struct {
+	Key         string          `json:"key"`
+	EventID     string          `json:"event_id,omitempty"`
+	EventType   string          `json:"event_type,omitempty"`
+	CardID      string          `json:"card_id"`
+	DeviceID    string          `json:"device_id,omitempty"`
+	Day         string          `json:"day"`
+	BaseURL     string          `json:"base_url"`
+	RequestID   string          `json:"request_id,omitempty"` // Correlation ID of the delivery that queued the event
+	Payload     json.RawMessage `json:"payload,omitempty"`    // Original webhook body, decoded by the consumer
+	ReceivedAt  time.Time       `json:"received_at"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+}
+
+// webhookClaimLease is how long a claimed entry is hidden from other consumers. The consumer renews
+// the lease while it processes the entry, however long that takes, so an entry only becomes
+// claimable again once an instance that died mid-delivery stops renewing it.
+const webhookClaimLease = 5 * time.Minute
+
+// WebhookQueue is a durable at-least-once queue for webhook deliveries. Entries are stored before
+// the webhook is acknowledged and removed only after the consumer succeeds, so events survive cold
+// starts; with the SQL store they survive scale-down too, and any instance may process them.
+// Repeated deliveries of the same (event ID, device, day) are dropped at enqueue time.
+type WebhookQueue struct {
+	backend    store.WebhookQueueStore
+	retryDelay time.Duration
+	lease      time.Duration
+	wake       chan struct{}
+
+	mu      sync.Mutex
+	started bool
+}
+
+// NewWebhookQueue creates a queue kept in backend
+func NewWebhookQueue(backend store.WebhookQueueStore, retryDelay time.Duration) *WebhookQueue {
+	if retryDelay <= 0 {
+		retryDelay = 30 * time.Second
+	}
+
+	queue := &WebhookQueue{
+		backend:    backend,
+		retryDelay: retryDelay,
+		lease:      webhookClaimLease,
+		wake:       make(chan struct{}, 1),
+	}
+	if pending, _, err := backend.PendingWebhooks(); err == nil && pending > 0 {
+		log.Printf("[WEBHOOK_QUEUE] Recovered %d pending webhook events", pending)
+	}
+	return queue
+}
+
+// WebhookDedupeKey identifies a delivery for deduplication
+func WebhookDedupeKey(eventID, deviceID, day string) string {
+	return fmt.Sprintf("%s|%s|%s", eventID, deviceID, day)
+}
+
+// Enqueue persists an entry and wakes the consumer. It returns false without queueing when the
+// entry's key was already seen. An error means the entry wasn't stored and the sender should retry.
+func (q *WebhookQueue) Enqueue(entry WebhookQueueEntry) (bool, error) {
+	if entry.ReceivedAt.IsZero() {
+		entry.ReceivedAt = time.Now().UTC()
+	}
+	entry.NextAttempt = entry.ReceivedAt
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal webhook entry: %w", err)
+	}
+	queued, err := q.backend.EnqueueWebhook(store.QueuedWebhook{
+		Key:         entry.Key,
+		Day:         entry.Day,
+		Entry:       data,
+		NextAttempt: entry.NextAttempt,
+	})
+	if err != nil || !queued {
+		return false, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return true, nil
+}
+
+// Start runs the consumer in the background. process is called for one entry at a time;
+// failed entries are retried with a growing delay until maxWebhookAttempts.
+func (q *WebhookQueue) Start(process func(WebhookQueueEntry) error) {
+	q.mu.Lock()
+	if q.started {
+		q.mu.Unlock()
+		return
+	}
+	q.started = true
+	q.mu.Unlock()
+
+	go q.consume(process)
+}
+
+// consume processes due entries, sleeping until the next one is due or a new entry arrives
+func (q *WebhookQueue) consume(process func(WebhookQueueEntry) error) {
+	for {
+		entry, leaseUntil, wait := q.next()
+		if entry == nil {
+			select {
+			case <-q.wake:
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		stopRenewing := q.renewLease(entry.Key, leaseUntil)
+		err := process(*entry)
+		stopRenewing()
+		q.complete(*entry, err)
+	}
+}
+
+// next claims the oldest due entry and returns it with its lease expiry, or returns how long to
+// wait for one. Entries other instances queue aren't announced, so the wait is capped at a minute.
+func (q *WebhookQueue) next() (*WebhookQueueEntry, time.Time, time.Duration) {
+	// Databases keep microseconds, and the lease is renewed by matching its expiry exactly
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	claimed, err := q.backend.ClaimWebhook(now, q.lease)
+	if err != nil {
+		log.Printf("[WEBHOOK_QUEUE] Failed to claim webhook: %v", err)
+		return nil, time.Time{}, q.retryDelay
+	}
+	if claimed == nil {
+		_, due, err := q.backend.PendingWebhooks()
+		if err != nil || due.IsZero() {
+			return nil, time.Time{}, time.Minute
+		}
+		return nil, time.Time{}, min(max(due.Sub(now), time.Second), time.Minute)
+	}
+
+	var entry WebhookQueueEntry
+	if err := json.Unmarshal(claimed.Entry, &entry); err != nil || entry.Key == "" {
+		log.Printf("[WEBHOOK_QUEUE] Dropping unreadable entry %s: %v", claimed.Key, err)
+		if err := q.backend.DeleteWebhook(claimed.Key); err != nil {
+			log.Printf("[WEBHOOK_QUEUE] Failed to drop %s: %v", claimed.Key, err)
+		}
+		return nil, time.Time{}, 0
+	}
+	entry.Attempts = claimed.Attempts
+	return &entry, now.Add(q.lease), 0
+}
+
+// renewLease keeps a claimed entry hidden from other consumers while it's processed, pushing the
+// lease out every third of its length. The returned function stops the renewals.
+func (q *WebhookQueue) renewLease(key string, until time.Time) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(q.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			renewed := time.Now().UTC().Truncate(time.Microsecond).Add(q.lease)
+			held, err := q.backend.ExtendWebhookLease(key, until, renewed)
+			if err != nil {
+				// The lease is still held until it expires, so the next tick tries again
+				log.Printf("[WEBHOOK_QUEUE] Failed to renew the lease on %s: %v", key, err)
+				continue
+			}
+			if !held {
+				log.Printf("[WEBHOOK_QUEUE] Lost the lease on %s; another consumer may process it too", key)
+				return
+			}
+			until = renewed
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// complete removes a processed entry, or schedules a retry if processing failed
+func (q *WebhookQueue) complete(entry WebhookQueueEntry, err error) {
+	if err == nil {
+		if err := q.backend.DeleteWebhook(entry.Key); err != nil {
+			log.Printf("[WEBHOOK_QUEUE] Failed to remove %s: %v", entry.Key, err)
+		}
+		return
+	}
+
+	attempts := entry.Attempts + 1
+	if attempts >= maxWebhookAttempts {
+		log.Printf("[WEBHOOK_QUEUE] Dropping %s after %d attempts: %v", entry.Key, attempts, err)
+		if err := q.backend.DeleteWebhook(entry.Key); err != nil {
+			log.Printf("[WEBHOOK_QUEUE] Failed to remove %s: %v", entry.Key, err)
+		}
+		return
+	}
+
+	delay := q.retryDelay * time.Duration(attempts)
+	log.Printf("[WEBHOOK_QUEUE] %s failed (attempt %d), retrying in %v: %v", entry.Key, attempts, delay, err)
+	if err := q.backend.RetryWebhook(entry.Key, attempts, time.Now().UTC().Add(delay)); err != nil {
+		log.Printf("[WEBHOOK_QUEUE] Failed to reschedule %s: %v", entry.Key, err)
+	}
+}
+
+// Stats returns queue depth for monitoring
+func (q *WebhookQueue) Stats() map[string]interface{} {
+	pending, _, err := q.backend.PendingWebhooks()
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return map[string]interface{}{
+		"pending": pending,
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/callen/bird-song-explorer/internal/store"
+)
+
+func TestProcessingPastTheLeaseKeepsTheEntryClaimed(t *testing.T) {
+	backend := store.NewFileWebhookQueue(filepath.Join(t.TempDir(), "webhook_queue.json"))
+	queue := NewWebhookQueue(backend, time.Millisecond)
+	queue.lease = 40 * time.Millisecond
+	// Another instance polls the same store while the entry is processed
+	other := NewWebhookQueue(backend, time.Millisecond)
+	other.lease = queue.lease
+
+	if _, err := queue.Enqueue(WebhookQueueEntry{Key: "event1|device1|2026-01-05", Day: "2026-01-05", CardID: "card1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var processed atomic.Int32
+	done := make(chan struct{})
+	queue.Start(func(entry WebhookQueueEntry) error {
+		processed.Add(1)
+		// Several leases long, as a card update that waits on TTS and transcodes can be
+		time.Sleep(5 * queue.lease)
+		close(done)
+		return nil
+	})
+
+	for waiting := true; waiting; {
+		select {
+		case <-done:
+			waiting = false
+		case <-time.After(5 * time.Millisecond):
+			if entry, _, _ := other.next(); entry != nil {
+				t.Fatalf("%s was claimed again while it was still being processed", entry.Key)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		pending, _, err := backend.PendingWebhooks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d entries still queued after processing finished", pending)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if count := processed.Load(); count != 1 {
+		t.Errorf("entry processed %d times, want once", count)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	weeklyFactsPerScript = 3
+	weeklyFactMaxCached  = 50
+)
+
+// WeeklyFactTheme is one day's topic in bird-of-the-week mode
+type WeeklyFactTheme struct {
+	Key     string `json:"key"`
+	Title   string `json:"title"` // Chapter title on the card
+	Topic   string `json:"topic"` // What the day covers, read in the previous day's sign-off
+	Opening string `json:"-"`
+
+	sections   []FactSection
+	sheetFacts func(sheet *FactSheet) []string // Sentences worded from the sheet's values, after its own sentences
+}
+
+// weeklyFactThemes are the daily topics, Monday first
+var weeklyFactThemes = []WeeklyFactTheme{
+	{
+		Key: "song", Title: "Song Secrets", Topic: "its song",
+		Opening:  "It's Monday, and that means song day!",
+		sections: []FactSection{FactVocalization},
+	},
+	{
+		Key: "nesting", Title: "Nest Builders", Topic: "its nest and chicks",
+		Opening:  "It's Tuesday, and today we're peeking into the nest!",
+		sections: []FactSection{FactNesting},
+		sheetFacts: func(sheet *FactSheet) []string {
+			if sheet.BreedingSeason() == "" {
+				return nil
+			}
+			return []string{fmt.Sprintf("It raises its chicks in %s.", sheet.BreedingSeason())}
+		},
+	},
+	{
+		Key: "food", Title: "What's for Dinner?", Topic: "what it eats",
+		Opening:  "It's Wednesday, and today is all about food!",
+		sections: []FactSection{FactDiet},
+		sheetFacts: func(sheet *FactSheet) []string {
+			if len(sheet.Diet()) == 0 {
+				return nil
+			}
+			return []string{fmt.Sprintf("Its favorite foods include %s.", joinWithAnd(sheet.Diet()))}
+		},
+	},
+	{
+		Key: "home", Title: "Where It Lives", Topic: "where it lives",
+		Opening:  "It's Thursday, so let's explore where this bird lives!",
+		sections: []FactSection{FactSightings},
+		sheetFacts: func(sheet *FactSheet) []string {
+			var facts []string
+			if len(sheet.Habitats()) > 0 {
+				facts = append(facts, fmt.Sprintf("You can find it in %s.", joinWithAnd(sheet.Habitats())))
+			} else if sheet.PrimaryHabitat() != "" {
+				facts = append(facts, fmt.Sprintf("It makes its home in %s.", sheet.PrimaryHabitat()))
+			}
+			if sheet.MigrationPattern() != "" {
+				facts = append(facts, fmt.Sprintf("When the seasons change, it is %s.", sheet.MigrationPattern()))
+			}
+			return facts
+		},
+	},
+	{
+		Key: "looks", Title: "Spot the Bird", Topic: "how to spot it",
+		Opening:  "It's Friday, time to become a bird spotter!",
+		sections: []FactSection{FactColors, FactSize},
+		sheetFacts: func(sheet *FactSheet) []string {
+			facts := append([]string(nil), sheet.DistinctiveFeatures()...)
+			if sheet.LengthCM() != "" {
+				facts = append(facts, fmt.Sprintf("From beak to tail it is about %s centimeters long.", sheet.LengthCM()))
+			}
+			return facts
+		},
+	},
+	{
+		Key: "superpowers", Title: "Bird Superpowers", Topic: "its superpowers",
+		Opening:  "It's Saturday, and today we're finding out about bird superpowers!",
+		sections: []FactSection{FactAbilities},
+	},
+	{
+		Key: "fun", Title: "Fun Fact Sunday", Topic: "its funniest surprises",
+		Opening:  "It's Sunday, the day for our silliest, most surprising facts!",
+		sections: []FactSection{FactFunFacts},
+		sheetFacts: func(sheet *FactSheet) []string {
+			return sheet.FunFacts()
+		},
+	},
+}
+
+// weeklyFactScript is the fact chapter's narration
+var weeklyFactScript = template.Must(template.New("weekly_fact").Parse(
+	"{{.Opening}} Our bird of the week is the {{.BirdName}}. " +
+		"{{if .Facts}}{{range .Facts}}{{.}} {{end}}" +
+		"{{else}}We're still finding out about {{.Topic}}, so next time you see a {{.BirdName}}, watch closely and discover it for yourself! {{end}}" +
+		"{{if .NextTopic}}Come back tomorrow to find out about {{.NextTopic}}!" +
+		"{{else}}That's the end of the {{.BirdName}}'s week. A brand new bird is coming tomorrow!{{end}}"))
+
+// WeeklyFactThemeOn returns the theme for day's weekday
+func WeeklyFactThemeOn(day time.Time) WeeklyFactTheme {
+	return weeklyFactThemes[(int(day.Weekday())+6)%7]
+}
+
+// WeeklyFact is one day's fact chapter for the bird of the week
+type WeeklyFact struct {
+	Bird   string `json:"bird"`
+	Theme  string `json:"theme"`
+	Script string `json:"script"`
+	Audio  []byte `json:"-"`
+}
+
+// WeeklyFactGuide narrates the bird of the week's themed fact for each day
+type WeeklyFactGuide struct {
+	storage *BirdStorage
+	tts     *ElevenLabsTTS
+
+	mu    sync.Mutex
+	facts map[string]*WeeklyFact // bird, date, and voice -> rendered fact
+}
+
+// NewWeeklyFactGuide creates a guide reading fact sheets from storage and narrating with ElevenLabs
+func NewWeeklyFactGuide(storage *BirdStorage, tts *ElevenLabsTTS) *WeeklyFactGuide {
+	return &WeeklyFactGuide{
+		storage: storage,
+		tts:     tts,
+		facts:   make(map[string]*WeeklyFact),
+	}
+}
+
+// GenerateFact returns the bird's themed fact for day, reusing a rendered fact for the same bird,
+// day, and voice
+func (wg *WeeklyFactGuide) GenerateFact(ctx context.Context, birdName string, day time.Time, voiceID string) (*WeeklyFact, error) {
+	key := fmt.Sprintf("%s|%s|%s", strings.ToLower(birdName), day.Format("2006-01-02"), voiceID)
+	wg.mu.Lock()
+	cached, ok := wg.facts[key]
+	wg.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	sheet, err := wg.storage.GetFactSheet(birdName)
+	if err != nil {
+		slog.WarnContext(ctx, "[BIRD_OF_WEEK] No fact sheet, using the theme's fallback", "bird", birdName, "error", err)
+		sheet = nil
+	}
+
+	theme := WeeklyFactThemeOn(day)
+	script, err := BuildWeeklyFactScript(birdName, theme, sheet)
+	if err != nil {
+		return nil, err
+	}
+	fact := &WeeklyFact{Bird: birdName, Theme: theme.Key, Script: script}
+	if fact.Audio, _, err = wg.tts.Render(ctx, fact.Script, voiceID); err != nil {
+		return nil, fmt.Errorf("failed to render weekly fact script: %w", err)
+	}
+
+	wg.mu.Lock()
+	if len(wg.facts) >= weeklyFactMaxCached {
+		wg.facts = make(map[string]*WeeklyFact)
+	}
+	wg.facts[key] = fact
+	wg.mu.Unlock()
+
+	slog.InfoContext(ctx, "[BIRD_OF_WEEK] Generated fact", "bird", birdName, "theme", theme.Key, "bytes", len(fact.Audio))
+	return fact, nil
+}
+
+// BuildWeeklyFactScript returns the narration for a theme: up to three facts from the sheet, then
+// a teaser for tomorrow's topic, or a goodbye on Sunday. A nil sheet gets the theme's fallback.
+func BuildWeeklyFactScript(birdName string, theme WeeklyFactTheme, sheet *FactSheet) (string, error) {
+	var facts []string
+	if sheet != nil {
+		for _, fact := range sheet.FirstFacts(weeklyFactsPerScript, theme.sections...) {
+			facts = append(facts, fact.Text)
+		}
+		if theme.sheetFacts != nil {
+			for _, fact := range theme.sheetFacts(sheet) {
+				if len(facts) == weeklyFactsPerScript {
+					break
+				}
+				facts = append(facts, fact)
+			}
+		}
+	}
+
+	nextTopic := ""
+	for i, t := range weeklyFactThemes {
+		if t.Key == theme.Key && i+1 < len(weeklyFactThemes) {
+			nextTopic = weeklyFactThemes[i+1].Topic
+		}
+	}
+
+	var buf bytes.Buffer
+	err := weeklyFactScript.Execute(&buf, struct {
+		Opening, BirdName, Topic, NextTopic string
+		Facts                               []string
+	}{theme.Opening, birdName, theme.Topic, nextTopic, facts})
+	if err != nil {
+		return "", fmt.Errorf("failed to build weekly fact script: %w", err)
+	}
+	return buf.String(), nil
+}
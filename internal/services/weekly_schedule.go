@@ -0,0 +1,126 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// WeeklyCardState is a bird-of-the-week card's current week
+type WeeklyCardState struct {
+	WeekStart     string `json:"week_start"`               // Monday the week began, YYYY-MM-DD
+	Bird          string `json:"bird"`                     // The week's bird
+	PublishedWeek string `json:"published_week,omitempty"` // Week whose full card was last published
+	FactDay       string `json:"fact_day,omitempty"`       // Day whose fact chapter was last published
+}
+
+// WeeklySchedule keeps each bird-of-the-week card's bird for the week and which of its chapters
+// have been published, so the first update of a week publishes the whole card and later days only
+// swap the fact chapter. State is persisted to a JSON file so restarts keep the week's bird.
+type WeeklySchedule struct {
+	mu     sync.Mutex
+	path   string
+	states map[string]WeeklyCardState // card ID -> state
+}
+
+// NewWeeklySchedule loads schedule state from path, defaulting to data/weekly_schedule.json
+func NewWeeklySchedule(path string) *WeeklySchedule {
+	if path == "" {
+		path = "data/weekly_schedule.json"
+	}
+
+	schedule := &WeeklySchedule{
+		path:   path,
+		states: make(map[string]WeeklyCardState),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &schedule.states); err != nil {
+			log.Printf("[BIRD_OF_WEEK] Failed to parse %s, starting empty: %v", path, err)
+			schedule.states = make(map[string]WeeklyCardState)
+		}
+	}
+
+	return schedule
+}
+
+// WeekStart returns the Monday of day's week, YYYY-MM-DD
+func WeekStart(day time.Time) string {
+	offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+	return day.AddDate(0, 0, -offset).Format("2006-01-02")
+}
+
+// BirdForWeek returns the card's bird for the week starting weekStart, if one has been chosen
+func (ws *WeeklySchedule) BirdForWeek(cardID string, weekStart string) (string, bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	state, ok := ws.states[cardID]
+	if !ok || state.WeekStart != weekStart || state.Bird == "" {
+		return "", false
+	}
+	return state.Bird, true
+}
+
+// StartWeek records the card's bird for a new week
+func (ws *WeeklySchedule) StartWeek(cardID string, weekStart string, bird string) {
+	ws.update(cardID, func(state *WeeklyCardState) {
+		*state = WeeklyCardState{WeekStart: weekStart, Bird: bird}
+	})
+}
+
+// WeekPublished reports whether the card's full content has been published for the week
+func (ws *WeeklySchedule) WeekPublished(cardID string, weekStart string) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.states[cardID].PublishedWeek == weekStart
+}
+
+// MarkPublished records that the card carries the week's content and day's fact chapter
+func (ws *WeeklySchedule) MarkPublished(cardID string, weekStart string, day string) {
+	ws.update(cardID, func(state *WeeklyCardState) {
+		state.PublishedWeek = weekStart
+		state.FactDay = day
+	})
+}
+
+// States returns every card's weekly state
+func (ws *WeeklySchedule) States() map[string]WeeklyCardState {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	states := make(map[string]WeeklyCardState, len(ws.states))
+	for cardID, state := range ws.states {
+		states[cardID] = state
+	}
+	return states
+}
+
+// update applies change to the card's state and saves the schedule
+func (ws *WeeklySchedule) update(cardID string, change func(state *WeeklyCardState)) {
+	ws.mu.Lock()
+	state := ws.states[cardID]
+	change(&state)
+	ws.states[cardID] = state
+	ws.mu.Unlock()
+
+	if err := ws.save(); err != nil {
+		log.Printf("[BIRD_OF_WEEK] Failed to save weekly schedule: %v", err)
+	}
+}
+
+// save writes the schedule to disk atomically
+func (ws *WeeklySchedule) save() error {
+	ws.mu.Lock()
+	data, err := json.MarshalIndent(ws.states, "", "  ")
+	ws.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal weekly schedule: %w", err)
+	}
+	return filex.AtomicWriteFile(ws.path, data, 0644)
+}
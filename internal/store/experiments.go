@@ -0,0 +1,175 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// experimentRetention is how long assignments and plays are kept
+const experimentRetention = 90 * 24 * time.Hour
+
+// ExperimentPlay is one listen-through of a card's guide in the fact generator experiment
+type ExperimentPlay struct {
+	SessionKey string    `json:"session_key"` // Card, day, and device of the play
+	Generator  string    `json:"generator"`
+	StartedAt  time.Time `json:"started_at"`
+	Completed  bool      `json:"completed"` // The listener reached the outro
+}
+
+// GeneratorTally counts guide plays for one generator and how many reached the outro
+type GeneratorTally struct {
+	Plays       int `json:"plays"`
+	Completions int `json:"completions"`
+}
+
+// ExperimentStore persists the fact generator experiment, so assignments and outcomes survive
+// restarts and are shared by every instance
+type ExperimentStore interface {
+	// RecordAssignment stores the generator a card's guide uses on a date, replacing any earlier one
+	RecordAssignment(cardID, date, generator string) error
+
+	// Assignment returns the generator recorded for a card and date, or ErrNotFound
+	Assignment(cardID, date string) (string, error)
+
+	// RecordGuideStarted stores a play unless its session key is already stored
+	RecordGuideStarted(play ExperimentPlay) error
+
+	// RecordCompleted marks a stored play as having reached the outro
+	RecordCompleted(sessionKey string) error
+
+	// GeneratorTallies counts plays and completions per generator
+	GeneratorTallies() (map[string]GeneratorTally, error)
+}
+
+// FileExperimentStore keeps the experiment in a JSON file, for single-instance deployments and local runs
+type FileExperimentStore struct {
+	mu   sync.Mutex
+	path string
+	data experimentFile
+}
+
+type experimentFile struct {
+	Assignments map[string]string          `json:"assignments"` // "cardID|date" -> generator
+	Plays       map[string]*ExperimentPlay `json:"plays"`       // session key -> play
+}
+
+// NewFileExperimentStore loads the store from disk, starting empty if the file doesn't exist
+func NewFileExperimentStore(path string) *FileExperimentStore {
+	if path == "" {
+		path = "data/fact_experiment.json"
+	}
+
+	fs := &FileExperimentStore{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &fs.data); err != nil {
+			log.Printf("[EXPERIMENT_STORE] Failed to parse %s, starting empty: %v", path, err)
+			fs.data = experimentFile{}
+		}
+	}
+	if fs.data.Assignments == nil {
+		fs.data.Assignments = make(map[string]string)
+	}
+	if fs.data.Plays == nil {
+		fs.data.Plays = make(map[string]*ExperimentPlay)
+	}
+	return fs
+}
+
+// RecordAssignment stores a card's generator for a date
+func (fs *FileExperimentStore) RecordAssignment(cardID, date, generator string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.data.Assignments[recordKey(cardID, date)] = generator
+	return fs.save()
+}
+
+// Assignment returns the generator recorded for a card and date
+func (fs *FileExperimentStore) Assignment(cardID, date string) (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	generator, ok := fs.data.Assignments[recordKey(cardID, date)]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return generator, nil
+}
+
+// RecordGuideStarted stores a play the first time its session key is seen
+func (fs *FileExperimentStore) RecordGuideStarted(play ExperimentPlay) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, seen := fs.data.Plays[play.SessionKey]; seen {
+		return nil
+	}
+	if play.StartedAt.IsZero() {
+		play.StartedAt = time.Now().UTC()
+	}
+	fs.data.Plays[play.SessionKey] = &play
+	fs.prune()
+	return fs.save()
+}
+
+// RecordCompleted marks a play as completed
+func (fs *FileExperimentStore) RecordCompleted(sessionKey string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	play, ok := fs.data.Plays[sessionKey]
+	if !ok || play.Completed {
+		return nil
+	}
+	play.Completed = true
+	return fs.save()
+}
+
+// GeneratorTallies counts the stored plays per generator
+func (fs *FileExperimentStore) GeneratorTallies() (map[string]GeneratorTally, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	tallies := make(map[string]GeneratorTally)
+	for _, play := range fs.data.Plays {
+		tally := tallies[play.Generator]
+		tally.Plays++
+		if play.Completed {
+			tally.Completions++
+		}
+		tallies[play.Generator] = tally
+	}
+	return tallies, nil
+}
+
+// prune drops plays and assignments older than experimentRetention. Callers hold fs.mu.
+func (fs *FileExperimentStore) prune() {
+	cutoff := time.Now().Add(-experimentRetention)
+	for key, play := range fs.data.Plays {
+		if play.StartedAt.Before(cutoff) {
+			delete(fs.data.Plays, key)
+		}
+	}
+
+	cutoffDate := cutoff.UTC().Format("2006-01-02")
+	for key := range fs.data.Assignments {
+		if len(key) > 10 && key[len(key)-10:] < cutoffDate {
+			delete(fs.data.Assignments, key)
+		}
+	}
+}
+
+// save writes the store to disk atomically. Callers hold fs.mu.
+func (fs *FileExperimentStore) save() error {
+	data, err := json.Marshal(fs.data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fact experiment: %w", err)
+	}
+	return filex.AtomicWriteFile(fs.path, data, 0644)
+}
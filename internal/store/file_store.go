@@ -0,0 +1,89 @@
+package store
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// FileStore keeps bird-of-day records in a JSON file, for single-instance deployments and local runs
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]*BirdOfDay // "region|date" -> record
+}
+
+// NewFileStore loads the store from disk, starting empty if the file doesn't exist
+func NewFileStore(path string) *FileStore {
+	if path == "" {
+		path = "data/bird_of_day.json"
+	}
+
+	fs := &FileStore{
+		path:    path,
+		records: make(map[string]*BirdOfDay),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &fs.records); err != nil {
+			log.Printf("[BIRD_STORE] Failed to parse %s, starting empty: %v", path, err)
+			fs.records = make(map[string]*BirdOfDay)
+		}
+	}
+
+	return fs
+}
+
+// Get returns the bird recorded for a region and date
+func (fs *FileStore) Get(region, date string) (*BirdOfDay, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	record, exists := fs.records[recordKey(region, date)]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	snapshot := *record
+	return &snapshot, nil
+}
+
+// Record stores a selection unless the region already has one for the date
+func (fs *FileStore) Record(record BirdOfDay) (*BirdOfDay, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	key := recordKey(record.Region, record.Date)
+	if existing, exists := fs.records[key]; exists {
+		snapshot := *existing
+		return &snapshot, nil
+	}
+
+	if record.SelectedAt.IsZero() {
+		record.SelectedAt = time.Now().UTC()
+	}
+	fs.records[key] = &record
+
+	if err := fs.save(); err != nil {
+		delete(fs.records, key)
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Close is a no-op; every write is flushed immediately
+func (fs *FileStore) Close() error {
+	return nil
+}
+
+// save writes the store to disk atomically. Callers hold fs.mu.
+func (fs *FileStore) save() error {
+	return filex.AtomicWriteJSON(fs.path, fs.records)
+}
+
+func recordKey(region, date string) string {
+	return region + "|" + date
+}
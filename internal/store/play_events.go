@@ -0,0 +1,121 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// Play event types
+const (
+	PlayStarted = "played"
+	PlayStopped = "stopped"
+)
+
+// playEventRetention is how long the file store keeps play events
+const playEventRetention = 180 * 24 * time.Hour
+
+// PlayEvent is one playback event reported by a Yoto player
+type PlayEvent struct {
+	EventID       string    `json:"event_id,omitempty"` // Yoto's event ID; repeats are dropped
+	Type          string    `json:"type"`               // PlayStarted or PlayStopped
+	CardID        string    `json:"card_id"`
+	DeviceID      string    `json:"device_id,omitempty"`
+	BirdName      string    `json:"bird_name,omitempty"`
+	Chapter       string    `json:"chapter,omitempty"` // Chapter key, e.g. "03"
+	Track         string    `json:"track,omitempty"`
+	ListenSeconds float64   `json:"listen_seconds,omitempty"` // Position in the track when playback stopped
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// PlayEventStore persists playback events for the listening report
+type PlayEventStore interface {
+	// RecordPlay stores an event, ignoring one whose EventID is already stored
+	RecordPlay(event PlayEvent) error
+
+	// PlaysSince returns events at or after since, oldest first
+	PlaysSince(since time.Time) ([]PlayEvent, error)
+}
+
+// FilePlayStore keeps play events in a JSON file, dropping them after playEventRetention
+type FilePlayStore struct {
+	mu     sync.Mutex
+	path   string
+	events []PlayEvent
+}
+
+// NewFilePlayStore loads the store from disk, starting empty if the file doesn't exist
+func NewFilePlayStore(path string) *FilePlayStore {
+	if path == "" {
+		path = "data/play_events.json"
+	}
+
+	fs := &FilePlayStore{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &fs.events); err != nil {
+			log.Printf("[PLAY_STORE] Failed to parse %s, starting empty: %v", path, err)
+			fs.events = nil
+		}
+	}
+	return fs
+}
+
+// RecordPlay appends an event and prunes expired ones
+func (fs *FilePlayStore) RecordPlay(event PlayEvent) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if event.EventID != "" {
+		for _, existing := range fs.events {
+			if existing.EventID == event.EventID {
+				return nil
+			}
+		}
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	cutoff := time.Now().Add(-playEventRetention)
+	kept := fs.events[:0]
+	for _, existing := range fs.events {
+		if !existing.OccurredAt.Before(cutoff) {
+			kept = append(kept, existing)
+		}
+	}
+	fs.events = append(kept, event)
+
+	if err := fs.save(); err != nil {
+		fs.events = fs.events[:len(fs.events)-1]
+		return err
+	}
+	return nil
+}
+
+// PlaysSince returns a copy of the events at or after since
+func (fs *FilePlayStore) PlaysSince(since time.Time) ([]PlayEvent, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	var events []PlayEvent
+	for _, event := range fs.events {
+		if !event.OccurredAt.Before(since) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// save writes the store to disk atomically. Callers hold fs.mu.
+func (fs *FilePlayStore) save() error {
+	data, err := json.Marshal(fs.events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal play events: %w", err)
+	}
+	return filex.AtomicWriteFile(fs.path, data, 0644)
+}
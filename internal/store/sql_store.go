@@ -0,0 +1,393 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// Registers the "pgx" database/sql driver for Postgres (Cloud SQL)
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// createBirdOfDayTable works on both Postgres and SQLite
+const createBirdOfDayTable = `CREATE TABLE IF NOT EXISTS bird_of_day (
+	region      TEXT NOT NULL,
+	date        TEXT NOT NULL,
+	bird_name   TEXT NOT NULL,
+	selected_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (region, date)
+)`
+
+// createPlayEventsTable stores playback events; NULL event IDs never conflict
+const createPlayEventsTable = `CREATE TABLE IF NOT EXISTS play_events (
+	event_id       TEXT UNIQUE,
+	type           TEXT NOT NULL,
+	card_id        TEXT NOT NULL,
+	device_id      TEXT NOT NULL,
+	bird_name      TEXT NOT NULL,
+	chapter        TEXT NOT NULL,
+	track          TEXT NOT NULL,
+	listen_seconds REAL NOT NULL,
+	occurred_at    TIMESTAMP NOT NULL
+)`
+
+// createExperimentAssignmentsTable and createExperimentPlaysTable store the fact generator experiment's assignments and plays
+const createExperimentAssignmentsTable = `CREATE TABLE IF NOT EXISTS experiment_assignments (
+	card_id   TEXT NOT NULL,
+	date      TEXT NOT NULL,
+	generator TEXT NOT NULL,
+	PRIMARY KEY (card_id, date)
+)`
+
+const createExperimentPlaysTable = `CREATE TABLE IF NOT EXISTS experiment_plays (
+	session_key TEXT PRIMARY KEY,
+	generator   TEXT NOT NULL,
+	started_at  TIMESTAMP NOT NULL,
+	completed   BOOLEAN NOT NULL
+)`
+
+// createWebhookQueueTable holds accepted webhook deliveries until an instance processes them, and
+// createWebhookSeenTable their dedupe keys for today and yesterday
+const createWebhookQueueTable = `CREATE TABLE IF NOT EXISTS webhook_queue (
+	key          TEXT PRIMARY KEY,
+	day          TEXT NOT NULL,
+	entry        TEXT NOT NULL,
+	attempts     INTEGER NOT NULL,
+	next_attempt TIMESTAMP NOT NULL
+)`
+
+const createWebhookSeenTable = `CREATE TABLE IF NOT EXISTS webhook_seen (
+	key TEXT PRIMARY KEY,
+	day TEXT NOT NULL
+)`
+
+// schema is created in order when the store opens
+var schema = []struct {
+	table  string
+	create string
+}{
+	{"bird_of_day", createBirdOfDayTable},
+	{"play_events", createPlayEventsTable},
+	{"experiment_assignments", createExperimentAssignmentsTable},
+	{"experiment_plays", createExperimentPlaysTable},
+	{"webhook_queue", createWebhookQueueTable},
+	{"webhook_seen", createWebhookSeenTable},
+}
+
+// SQLStore keeps bird-of-day records, play events, and the fact generator experiment in Postgres or SQLite, so every Cloud Run
+// instance shares them.
+// Postgres is available as the "pgx" driver; other drivers must be registered by the binary.
+type SQLStore struct {
+	db       *sql.DB
+	postgres bool
+}
+
+// NewSQLStore connects with a registered database/sql driver and creates the table if needed
+func NewSQLStore(driver, dsn string) (*SQLStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %w", driver, err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s store: %w", driver, err)
+	}
+	for _, table := range schema {
+		if _, err := db.Exec(table.create); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create %s table: %w", table.table, err)
+		}
+	}
+
+	return &SQLStore{
+		db:       db,
+		postgres: strings.HasPrefix(driver, "postgres") || driver == "pgx",
+	}, nil
+}
+
+// Get returns the bird recorded for a region and date
+func (s *SQLStore) Get(region, date string) (*BirdOfDay, error) {
+	record := BirdOfDay{Region: region, Date: date}
+	err := s.db.QueryRow(
+		s.bind("SELECT bird_name, selected_at FROM bird_of_day WHERE region = ? AND date = ?"),
+		region, date,
+	).Scan(&record.BirdName, &record.SelectedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bird of day: %w", err)
+	}
+	return &record, nil
+}
+
+// Record inserts a selection, keeping the existing row if another instance got there first
+func (s *SQLStore) Record(record BirdOfDay) (*BirdOfDay, error) {
+	if record.SelectedAt.IsZero() {
+		record.SelectedAt = time.Now().UTC()
+	}
+
+	_, err := s.db.Exec(
+		s.bind("INSERT INTO bird_of_day (region, date, bird_name, selected_at) VALUES (?, ?, ?, ?) ON CONFLICT (region, date) DO NOTHING"),
+		record.Region, record.Date, record.BirdName, record.SelectedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record bird of day: %w", err)
+	}
+	return s.Get(record.Region, record.Date)
+}
+
+// RecordPlay inserts a play event, skipping one whose event ID is already stored
+func (s *SQLStore) RecordPlay(event PlayEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	var eventID sql.NullString
+	if event.EventID != "" {
+		eventID = sql.NullString{String: event.EventID, Valid: true}
+	}
+
+	_, err := s.db.Exec(
+		s.bind("INSERT INTO play_events (event_id, type, card_id, device_id, bird_name, chapter, track, listen_seconds, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING"),
+		eventID, event.Type, event.CardID, event.DeviceID, event.BirdName, event.Chapter, event.Track, event.ListenSeconds, event.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record play event: %w", err)
+	}
+	return nil
+}
+
+// PlaysSince returns play events at or after since, oldest first
+func (s *SQLStore) PlaysSince(since time.Time) ([]PlayEvent, error) {
+	rows, err := s.db.Query(
+		s.bind("SELECT event_id, type, card_id, device_id, bird_name, chapter, track, listen_seconds, occurred_at FROM play_events WHERE occurred_at >= ? ORDER BY occurred_at"),
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read play events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []PlayEvent
+	for rows.Next() {
+		var event PlayEvent
+		var eventID sql.NullString
+		if err := rows.Scan(&eventID, &event.Type, &event.CardID, &event.DeviceID, &event.BirdName,
+			&event.Chapter, &event.Track, &event.ListenSeconds, &event.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to read play event: %w", err)
+		}
+		event.EventID = eventID.String
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// RecordAssignment stores a card's generator for a date, replacing any earlier one
+func (s *SQLStore) RecordAssignment(cardID, date, generator string) error {
+	_, err := s.db.Exec(
+		s.bind("INSERT INTO experiment_assignments (card_id, date, generator) VALUES (?, ?, ?) ON CONFLICT (card_id, date) DO UPDATE SET generator = excluded.generator"),
+		cardID, date, generator,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record experiment assignment: %w", err)
+	}
+	return nil
+}
+
+// Assignment returns the generator recorded for a card and date
+func (s *SQLStore) Assignment(cardID, date string) (string, error) {
+	var generator string
+	err := s.db.QueryRow(
+		s.bind("SELECT generator FROM experiment_assignments WHERE card_id = ? AND date = ?"),
+		cardID, date,
+	).Scan(&generator)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read experiment assignment: %w", err)
+	}
+	return generator, nil
+}
+
+// RecordGuideStarted inserts a play, keeping the existing row for a session key already seen
+func (s *SQLStore) RecordGuideStarted(play ExperimentPlay) error {
+	if play.StartedAt.IsZero() {
+		play.StartedAt = time.Now().UTC()
+	}
+	_, err := s.db.Exec(
+		s.bind("INSERT INTO experiment_plays (session_key, generator, started_at, completed) VALUES (?, ?, ?, ?) ON CONFLICT (session_key) DO NOTHING"),
+		play.SessionKey, play.Generator, play.StartedAt, false,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record experiment play: %w", err)
+	}
+	return nil
+}
+
+// RecordCompleted marks a play as having reached the outro
+func (s *SQLStore) RecordCompleted(sessionKey string) error {
+	_, err := s.db.Exec(s.bind("UPDATE experiment_plays SET completed = ? WHERE session_key = ?"), true, sessionKey)
+	if err != nil {
+		return fmt.Errorf("failed to record experiment completion: %w", err)
+	}
+	return nil
+}
+
+// GeneratorTallies counts plays and completions per generator
+func (s *SQLStore) GeneratorTallies() (map[string]GeneratorTally, error) {
+	rows, err := s.db.Query("SELECT generator, completed FROM experiment_plays")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read experiment plays: %w", err)
+	}
+	defer rows.Close()
+
+	tallies := make(map[string]GeneratorTally)
+	for rows.Next() {
+		var generator string
+		var completed bool
+		if err := rows.Scan(&generator, &completed); err != nil {
+			return nil, fmt.Errorf("failed to read experiment play: %w", err)
+		}
+		tally := tallies[generator]
+		tally.Plays++
+		if completed {
+			tally.Completions++
+		}
+		tallies[generator] = tally
+	}
+	return tallies, rows.Err()
+}
+
+// EnqueueWebhook stores a delivery unless another instance already saw its key
+func (s *SQLStore) EnqueueWebhook(webhook QueuedWebhook) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to queue webhook: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(s.bind("DELETE FROM webhook_seen WHERE day < ?"), previousDay(webhook.Day)); err != nil {
+		return false, fmt.Errorf("failed to prune webhook keys: %w", err)
+	}
+	result, err := tx.Exec(s.bind("INSERT INTO webhook_seen (key, day) VALUES (?, ?) ON CONFLICT (key) DO NOTHING"), webhook.Key, webhook.Day)
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook key: %w", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return false, err
+	}
+
+	_, err = tx.Exec(
+		s.bind("INSERT INTO webhook_queue (key, day, entry, attempts, next_attempt) VALUES (?, ?, ?, ?, ?)"),
+		webhook.Key, webhook.Day, string(webhook.Entry), webhook.Attempts, webhook.NextAttempt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to queue webhook: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to queue webhook: %w", err)
+	}
+	return true, nil
+}
+
+// ClaimWebhook leases the delivery due first. The lease is taken with a conditional update, so
+// when two instances pick the same delivery only one of them gets it.
+func (s *SQLStore) ClaimWebhook(now time.Time, lease time.Duration) (*QueuedWebhook, error) {
+	var webhook QueuedWebhook
+	var entry string
+	err := s.db.QueryRow(
+		s.bind("SELECT key, day, entry, attempts, next_attempt FROM webhook_queue WHERE next_attempt <= ? ORDER BY next_attempt LIMIT 1"),
+		now,
+	).Scan(&webhook.Key, &webhook.Day, &entry, &webhook.Attempts, &webhook.NextAttempt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook queue: %w", err)
+	}
+	webhook.Entry = json.RawMessage(entry)
+
+	result, err := s.db.Exec(
+		s.bind("UPDATE webhook_queue SET next_attempt = ? WHERE key = ? AND next_attempt = ?"),
+		now.Add(lease), webhook.Key, webhook.NextAttempt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook: %w", err)
+	}
+	if claimed, err := result.RowsAffected(); err != nil || claimed == 0 {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ExtendWebhookLease pushes out a lease with the same conditional update that took it, so a lease
+// another instance has claimed since isn't taken back
+func (s *SQLStore) ExtendWebhookLease(key string, until time.Time, newUntil time.Time) (bool, error) {
+	result, err := s.db.Exec(
+		s.bind("UPDATE webhook_queue SET next_attempt = ? WHERE key = ? AND next_attempt = ?"),
+		newUntil, key, until,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend webhook lease: %w", err)
+	}
+	extended, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to extend webhook lease: %w", err)
+	}
+	return extended > 0, nil
+}
+
+// RetryWebhook records a failed attempt
+func (s *SQLStore) RetryWebhook(key string, attempts int, nextAttempt time.Time) error {
+	_, err := s.db.Exec(s.bind("UPDATE webhook_queue SET attempts = ?, next_attempt = ? WHERE key = ?"), attempts, nextAttempt, key)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook removes a delivery
+func (s *SQLStore) DeleteWebhook(key string) error {
+	if _, err := s.db.Exec(s.bind("DELETE FROM webhook_queue WHERE key = ?"), key); err != nil {
+		return fmt.Errorf("failed to remove webhook: %w", err)
+	}
+	return nil
+}
+
+// PendingWebhooks counts the queue and finds the next due delivery
+func (s *SQLStore) PendingWebhooks() (int, time.Time, error) {
+	var count int
+	var next sql.NullTime
+	if err := s.db.QueryRow("SELECT COUNT(*), MIN(next_attempt) FROM webhook_queue").Scan(&count, &next); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to count webhook queue: %w", err)
+	}
+	return count, next.Time, nil
+}
+
+// Close closes the database connection
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// bind rewrites ? placeholders to $n for Postgres
+func (s *SQLStore) bind(query string) string {
+	if !s.postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// RegionGlobal is the region key for the scheduler's single daily bird
+const RegionGlobal = "global"
+
+// ErrNotFound is returned when no bird has been recorded for a region and date
+var ErrNotFound = errors.New("no bird of the day recorded")
+
+// BirdOfDay records which bird was selected for a region on a date
+type BirdOfDay struct {
+	Region     string    `json:"region"`
+	Date       string    `json:"date"` // YYYY-MM-DD
+	BirdName   string    `json:"bird_name"`
+	SelectedAt time.Time `json:"selected_at"`
+}
+
+// BirdOfDayStore persists daily selections so restarts don't pick a different bird mid-day
+type BirdOfDayStore interface {
+	// Get returns the bird recorded for a region and date, or ErrNotFound
+	Get(region, date string) (*BirdOfDay, error)
+
+	// Record stores a selection unless one already exists for the region and date.
+	// It returns whichever record is stored, so concurrent selectors agree on the first one.
+	Record(record BirdOfDay) (*BirdOfDay, error)
+
+	Close() error
+}
+
+// Open returns the SQL store when a driver is configured, otherwise the JSON file store at path
+func Open(driver, dsn, path string) (BirdOfDayStore, error) {
+	if driver == "" {
+		return NewFileStore(path), nil
+	}
+	return NewSQLStore(driver, dsn)
+}
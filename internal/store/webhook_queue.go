@@ -0,0 +1,198 @@
+package store
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// QueuedWebhook is a webhook delivery waiting for the consumer. The entry itself is opaque to the
+// store; the queue decodes it.
+type QueuedWebhook struct {
+	Key         string          `json:"key"` // Dedupe key
+	Day         string          `json:"day"` // YYYY-MM-DD the key is deduplicated on
+	Entry       json.RawMessage `json:"entry"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+}
+
+// WebhookQueueStore persists accepted webhook deliveries until they're processed. The SQL store
+// shares them between instances; each delivery is claimed by one instance at a time.
+type WebhookQueueStore interface {
+	// EnqueueWebhook stores a delivery unless its key was seen today or yesterday, reporting
+	// whether it was stored
+	EnqueueWebhook(webhook QueuedWebhook) (bool, error)
+
+	// ClaimWebhook returns the delivery due first at now, hidden from other claims until now+lease,
+	// or nil when none is due
+	ClaimWebhook(now time.Time, lease time.Duration) (*QueuedWebhook, error)
+
+	// ExtendWebhookLease pushes a claimed delivery's lease from until out to newUntil, reporting
+	// false when the lease was lost: it expired and the delivery was claimed again or completed
+	ExtendWebhookLease(key string, until time.Time, newUntil time.Time) (bool, error)
+
+	// RetryWebhook records a failed attempt and when to try again
+	RetryWebhook(key string, attempts int, nextAttempt time.Time) error
+
+	// DeleteWebhook removes a processed or abandoned delivery
+	DeleteWebhook(key string) error
+
+	// PendingWebhooks counts stored deliveries and the time the next one is due
+	PendingWebhooks() (int, time.Time, error)
+}
+
+// FileWebhookQueue keeps webhook deliveries in a JSON file, for single-instance deployments and local runs
+type FileWebhookQueue struct {
+	mu    sync.Mutex
+	path  string
+	state webhookQueueFile
+}
+
+type webhookQueueFile struct {
+	Pending []*QueuedWebhook  `json:"pending"`
+	Seen    map[string]string `json:"seen"` // Dedupe key -> day, kept for today and yesterday
+}
+
+// NewFileWebhookQueue loads the queue from disk, starting empty if the file doesn't exist
+func NewFileWebhookQueue(path string) *FileWebhookQueue {
+	if path == "" {
+		path = "data/webhook_queue.json"
+	}
+
+	fq := &FileWebhookQueue{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &fq.state); err != nil {
+			log.Printf("[WEBHOOK_QUEUE] Failed to parse %s, starting empty: %v", path, err)
+			fq.state = webhookQueueFile{}
+		}
+	}
+	if fq.state.Seen == nil {
+		fq.state.Seen = make(map[string]string)
+	}
+	return fq
+}
+
+// EnqueueWebhook appends a delivery whose key hasn't been seen
+func (fq *FileWebhookQueue) EnqueueWebhook(webhook QueuedWebhook) (bool, error) {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	yesterday := previousDay(webhook.Day)
+	for key, day := range fq.state.Seen {
+		if day != webhook.Day && day != yesterday {
+			delete(fq.state.Seen, key)
+		}
+	}
+	if _, seen := fq.state.Seen[webhook.Key]; seen {
+		return false, nil
+	}
+
+	fq.state.Pending = append(fq.state.Pending, &webhook)
+	fq.state.Seen[webhook.Key] = webhook.Day
+	if err := fq.save(); err != nil {
+		fq.state.Pending = fq.state.Pending[:len(fq.state.Pending)-1]
+		delete(fq.state.Seen, webhook.Key)
+		return false, err
+	}
+	return true, nil
+}
+
+// ClaimWebhook returns the first due delivery and pushes its next attempt out by lease
+func (fq *FileWebhookQueue) ClaimWebhook(now time.Time, lease time.Duration) (*QueuedWebhook, error) {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	if len(fq.state.Pending) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(fq.state.Pending, func(i, j int) bool {
+		return fq.state.Pending[i].NextAttempt.Before(fq.state.Pending[j].NextAttempt)
+	})
+
+	first := fq.state.Pending[0]
+	if first.NextAttempt.After(now) {
+		return nil, nil
+	}
+	claimed := *first
+	first.NextAttempt = now.Add(lease)
+	return &claimed, fq.save()
+}
+
+// ExtendWebhookLease pushes out the lease of a delivery that's still claimed until until
+func (fq *FileWebhookQueue) ExtendWebhookLease(key string, until time.Time, newUntil time.Time) (bool, error) {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	for _, webhook := range fq.state.Pending {
+		if webhook.Key == key {
+			if !webhook.NextAttempt.Equal(until) {
+				return false, nil
+			}
+			webhook.NextAttempt = newUntil
+			return true, fq.save()
+		}
+	}
+	return false, nil
+}
+
+// RetryWebhook records a failed attempt
+func (fq *FileWebhookQueue) RetryWebhook(key string, attempts int, nextAttempt time.Time) error {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	for _, webhook := range fq.state.Pending {
+		if webhook.Key == key {
+			webhook.Attempts = attempts
+			webhook.NextAttempt = nextAttempt
+			return fq.save()
+		}
+	}
+	return nil
+}
+
+// DeleteWebhook removes a delivery
+func (fq *FileWebhookQueue) DeleteWebhook(key string) error {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	for i, webhook := range fq.state.Pending {
+		if webhook.Key == key {
+			fq.state.Pending = append(fq.state.Pending[:i], fq.state.Pending[i+1:]...)
+			return fq.save()
+		}
+	}
+	return nil
+}
+
+// PendingWebhooks counts the queue and finds the next due delivery
+func (fq *FileWebhookQueue) PendingWebhooks() (int, time.Time, error) {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	var next time.Time
+	for _, webhook := range fq.state.Pending {
+		if next.IsZero() || webhook.NextAttempt.Before(next) {
+			next = webhook.NextAttempt
+		}
+	}
+	return len(fq.state.Pending), next, nil
+}
+
+// save writes the queue to disk atomically. Callers hold fq.mu.
+func (fq *FileWebhookQueue) save() error {
+	return filex.AtomicWriteJSON(fq.path, fq.state)
+}
+
+// previousDay returns the YYYY-MM-DD before day, or "" when day doesn't parse
+func previousDay(day string) string {
+	parsed, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return ""
+	}
+	return parsed.AddDate(0, 0, -1).Format("2006-01-02")
+}
@@ -1,10 +1,13 @@
 package ebird
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
 const baseURL = "https://api.ebird.org/v2"
@@ -46,122 +49,106 @@ type Hotspot struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 30 * time.Second}),
 	}
 }
 
-func (c *Client) GetRecentObservations(lat, lng float64, days int) ([]Observation, error) {
-	return c.GetRecentObservationsWithRadius(lat, lng, 50, days)
+func (c *Client) GetRecentObservations(ctx context.Context, lat, lng float64, days int) ([]Observation, error) {
+	return c.GetRecentObservationsWithRadius(ctx, lat, lng, 50, days)
 }
 
-// GetRecentObservationsWithRadius gets recent bird observations within a specified radius
-func (c *Client) GetRecentObservationsWithRadius(lat, lng float64, radiusKm, days int) ([]Observation, error) {
-	endpoint := fmt.Sprintf("%s/data/obs/geo/recent", baseURL)
-
+// GetRecentObservationsWithRadius gets recent bird observations within a specified radius.
+// Locations are rounded to about a kilometre and responses are cached for the day.
+func (c *Client) GetRecentObservationsWithRadius(ctx context.Context, lat, lng float64, radiusKm, days int) ([]Observation, error) {
 	params := url.Values{}
-	params.Add("lat", fmt.Sprintf("%.4f", lat))
-	params.Add("lng", fmt.Sprintf("%.4f", lng))
+	params.Add("lat", roundedCoordinate(lat))
+	params.Add("lng", roundedCoordinate(lng))
 	params.Add("dist", fmt.Sprintf("%d", radiusKm))
 	params.Add("back", fmt.Sprintf("%d", days))
-	params.Add("maxResults", "200")  // Increase for wider searches
-
-	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
-
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, err
-	}
+	params.Add("maxResults", "200") // Increase for wider searches
 
-	req.Header.Set("X-eBirdApiToken", c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	var observations []Observation
+	if err := c.get(ctx, "/data/obs/geo/recent", params, &observations); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return observations, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("eBird API error: %d", resp.StatusCode)
-	}
+// GetRecentSpeciesObservations gets recent observations of one species within a radius, rounded
+// and cached like GetRecentObservationsWithRadius
+func (c *Client) GetRecentSpeciesObservations(ctx context.Context, speciesCode string, lat, lng float64, radiusKm, days int) ([]Observation, error) {
+	params := url.Values{}
+	params.Add("lat", roundedCoordinate(lat))
+	params.Add("lng", roundedCoordinate(lng))
+	params.Add("dist", fmt.Sprintf("%d", radiusKm))
+	params.Add("back", fmt.Sprintf("%d", days))
 
 	var observations []Observation
-	if err := json.NewDecoder(resp.Body).Decode(&observations); err != nil {
+	if err := c.get(ctx, "/data/obs/geo/recent/"+url.PathEscape(speciesCode), params, &observations); err != nil {
 		return nil, err
 	}
-
 	return observations, nil
 }
 
-func (c *Client) GetNearbyHotspots(lat, lng float64, dist int) ([]Hotspot, error) {
-	endpoint := fmt.Sprintf("%s/ref/hotspot/geo", baseURL)
-
+func (c *Client) GetNearbyHotspots(ctx context.Context, lat, lng float64, dist int) ([]Hotspot, error) {
 	params := url.Values{}
-	params.Add("lat", fmt.Sprintf("%.4f", lat))
-	params.Add("lng", fmt.Sprintf("%.4f", lng))
+	params.Add("lat", roundedCoordinate(lat))
+	params.Add("lng", roundedCoordinate(lng))
 	params.Add("dist", fmt.Sprintf("%d", dist))
 	params.Add("fmt", "json")
 
-	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
-
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("X-eBirdApiToken", c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("eBird API error: %d", resp.StatusCode)
-	}
-
 	var hotspots []Hotspot
-	if err := json.NewDecoder(resp.Body).Decode(&hotspots); err != nil {
+	if err := c.get(ctx, "/ref/hotspot/geo", params, &hotspots); err != nil {
 		return nil, err
 	}
-
 	return hotspots, nil
 }
 
-func (c *Client) GetSpeciesInfo(speciesCode string) (*Species, error) {
-	endpoint := fmt.Sprintf("%s/ref/taxonomy/ebird", baseURL)
-
+func (c *Client) GetSpeciesInfo(ctx context.Context, speciesCode string) (*Species, error) {
 	params := url.Values{}
 	params.Add("species", speciesCode)
 	params.Add("fmt", "json")
 
-	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
-
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
+	var species []Species
+	if err := c.get(ctx, "/ref/taxonomy/ebird", params, &species); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("X-eBirdApiToken", c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	if len(species) > 0 {
+		return &species[0], nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("eBird API error: %d", resp.StatusCode)
+	return nil, fmt.Errorf("species not found")
+}
+
+// FindSpeciesCode returns the eBird species code for a scientific name, resolved through the
+// shared taxonomy cache
+func (c *Client) FindSpeciesCode(scientificName string) (string, error) {
+	species, ok := SharedTaxonomy(c.apiKey).ByScientificName(scientificName)
+	if !ok {
+		return "", fmt.Errorf("no eBird species code for %s", scientificName)
 	}
+	return species.SpeciesCode, nil
+}
 
-	var species []Species
-	if err := json.NewDecoder(resp.Body).Decode(&species); err != nil {
+// GetRegionSpeciesList returns the species codes ever reported in an eBird region, such as a
+// country ("GB", "JP") or a subnational region ("AU-NSW")
+func (c *Client) GetRegionSpeciesList(ctx context.Context, regionCode string) ([]string, error) {
+	var speciesCodes []string
+	if err := c.get(ctx, "/product/spplist/"+url.PathEscape(regionCode), nil, &speciesCodes); err != nil {
 		return nil, err
 	}
+	return speciesCodes, nil
+}
 
-	if len(species) > 0 {
-		return &species[0], nil
-	}
+// GetHistoricObservations returns one observation of each species reported in an eBird region on
+// a date, cached like the other endpoints
+func (c *Client) GetHistoricObservations(ctx context.Context, regionCode string, date time.Time) ([]Observation, error) {
+	path := fmt.Sprintf("/data/obs/%s/historic/%d/%d/%d", url.PathEscape(regionCode), date.Year(), int(date.Month()), date.Day())
 
-	return nil, fmt.Errorf("species not found")
+	var observations []Observation
+	if err := c.get(ctx, path, nil, &observations); err != nil {
+		return nil, err
+	}
+	return observations, nil
 }
@@ -0,0 +1,180 @@
+package ebird
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// eBird asks API users to keep request rates modest; every client in the process shares one
+// bucket so parallel card updates can't add up to a burst
+const (
+	requestsPerSecond = 2.0
+	requestBurst      = 5
+
+	// rateLimitPause is how long requests are held after a 429 without a Retry-After
+	rateLimitPause   = 30 * time.Second
+	responseCacheMax = 500
+)
+
+// ErrRateLimited is returned when eBird is still answering 429 after the retries
+var ErrRateLimited = errors.New("eBird rate limit reached")
+
+// tokenBucket is a token-bucket rate limiter that can also be paused, when eBird says to back off
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64 // Tokens per second
+	burst       float64
+	tokens      float64
+	updatedAt   time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), updatedAt: time.Now()}
+}
+
+// Wait blocks until a request may be made, or until ctx is done
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*b.rate)
+		b.updatedAt = now
+
+		var delay time.Duration
+		switch {
+		case now.Before(b.pausedUntil):
+			delay = b.pausedUntil.Sub(now)
+		case b.tokens >= 1:
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		default:
+			delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		}
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Pause holds every request for d
+func (b *tokenBucket) Pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until := time.Now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// responseCache keeps eBird responses for the rest of the UTC day they were fetched on
+type responseCache struct {
+	mu      sync.Mutex
+	day     string
+	entries map[string][]byte
+}
+
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.day != today() {
+		return nil, false
+	}
+	body, ok := rc.entries[key]
+	return body, ok
+}
+
+func (rc *responseCache) put(key string, body []byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if day := today(); rc.day != day || len(rc.entries) >= responseCacheMax {
+		rc.day = day
+		rc.entries = make(map[string][]byte)
+	}
+	rc.entries[key] = body
+}
+
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+var (
+	sharedBucket = newTokenBucket(requestsPerSecond, requestBurst)
+	sharedCache  = &responseCache{}
+)
+
+// roundedCoordinate rounds a latitude or longitude to about a kilometre, so nearby listeners
+// share cached responses
+func roundedCoordinate(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', 2, 64)
+}
+
+// get fetches an eBird endpoint through the shared rate limiter and response cache, where responses
+// are keyed on the path and query, and decodes the JSON body into out
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	fullURL := baseURL + path
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
+
+	body, cached := sharedCache.get(fullURL)
+	if !cached {
+		var err error
+		if body, err = c.fetch(ctx, fullURL); err != nil {
+			return err
+		}
+		sharedCache.put(fullURL, body)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+func (c *Client) fetch(ctx context.Context, fullURL string) ([]byte, error) {
+	if err := sharedBucket.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-eBirdApiToken", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// The retries have already honoured Retry-After; hold every other request too
+		pause := rateLimitPause
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			pause = time.Duration(seconds) * time.Second
+		}
+		sharedBucket.Pause(pause)
+		log.Printf("[EBIRD] Rate limited, pausing requests for %s", pause)
+		return nil, ErrRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("eBird API error: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,264 @@
+package ebird
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+// taxonomyMaxAge is how long a downloaded taxonomy is used before it's refreshed; eBird
+// publishes taxonomy updates once a year
+const taxonomyMaxAge = 30 * 24 * time.Hour
+
+// Taxonomy resolves between common names, scientific names, and species codes using the full
+// eBird species taxonomy. It's downloaded on first use and cached on disk (EBIRD_TAXONOMY_PATH,
+// data/ebird_taxonomy.json by default), so restarts don't fetch it again.
+type Taxonomy struct {
+	mu     sync.Mutex
+	apiKey string
+	path   string
+	client *http.Client
+
+	loadedAt      time.Time
+	lastAttempt   time.Time
+	byCommonName  map[string]Species
+	byScientific  map[string]Species
+	bySpeciesCode map[string]Species
+}
+
+var (
+	sharedTaxonomyMu   sync.Mutex
+	sharedTaxonomy     *Taxonomy
+	sharedTaxonomyPath string
+)
+
+// ConfigureTaxonomy sets where the process-wide taxonomy is cached on disk. Call it at startup,
+// before anything looks up a species; later calls have no effect.
+func ConfigureTaxonomy(path string) {
+	sharedTaxonomyMu.Lock()
+	defer sharedTaxonomyMu.Unlock()
+	if sharedTaxonomy == nil {
+		sharedTaxonomyPath = path
+	}
+}
+
+// SharedTaxonomy returns the process-wide taxonomy. The first non-empty API key seen is used to
+// download it; an empty key reuses whichever key was registered, or only the disk cache.
+func SharedTaxonomy(apiKey string) *Taxonomy {
+	sharedTaxonomyMu.Lock()
+	defer sharedTaxonomyMu.Unlock()
+
+	if sharedTaxonomy == nil {
+		sharedTaxonomy = NewTaxonomy(apiKey, sharedTaxonomyPath)
+	} else if apiKey != "" {
+		sharedTaxonomy.setAPIKey(apiKey)
+	}
+	return sharedTaxonomy
+}
+
+// NewTaxonomy creates a taxonomy cache stored at path
+func NewTaxonomy(apiKey string, path string) *Taxonomy {
+	if path == "" {
+		path = "data/ebird_taxonomy.json"
+	}
+
+	return &Taxonomy{
+		apiKey: apiKey,
+		path:   path,
+		client: httpx.NewClient(httpx.Options{Timeout: 60 * time.Second}),
+	}
+}
+
+func (t *Taxonomy) setAPIKey(apiKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.apiKey == "" {
+		t.apiKey = apiKey
+	}
+}
+
+// ByCommonName finds a species by English common name. Case, hyphens, underscores, and
+// apostrophes are ignored, so "black-capped-chickadee" finds "Black-capped Chickadee".
+func (t *Taxonomy) ByCommonName(commonName string) (Species, bool) {
+	if err := t.ensureLoaded(); err != nil {
+		return Species{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	species, ok := t.byCommonName[normalizeTaxonName(commonName)]
+	return species, ok
+}
+
+// ByScientificName finds a species by scientific name, ignoring case
+func (t *Taxonomy) ByScientificName(scientificName string) (Species, bool) {
+	if err := t.ensureLoaded(); err != nil {
+		return Species{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	species, ok := t.byScientific[normalizeTaxonName(scientificName)]
+	return species, ok
+}
+
+// BySpeciesCode finds a species by eBird species code
+func (t *Taxonomy) BySpeciesCode(code string) (Species, bool) {
+	if err := t.ensureLoaded(); err != nil {
+		return Species{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	species, ok := t.bySpeciesCode[strings.ToLower(strings.TrimSpace(code))]
+	return species, ok
+}
+
+// Resolve finds a species from any of its names: common name, scientific name, or species code
+func (t *Taxonomy) Resolve(name string) (Species, bool) {
+	if species, ok := t.ByScientificName(name); ok {
+		return species, true
+	}
+	if species, ok := t.ByCommonName(name); ok {
+		return species, true
+	}
+	return t.BySpeciesCode(name)
+}
+
+// Size returns the number of species loaded
+func (t *Taxonomy) Size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.bySpeciesCode)
+}
+
+// Load reads or downloads the taxonomy ahead of the first lookup
+func (t *Taxonomy) Load() error {
+	return t.ensureLoaded()
+}
+
+// ensureLoaded loads the taxonomy from disk, downloading it when the cache is missing or stale.
+// A failed download falls back to a stale cache, and is retried at most every few minutes.
+func (t *Taxonomy) ensureLoaded() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.bySpeciesCode != nil && time.Since(t.loadedAt) < taxonomyMaxAge {
+		return nil
+	}
+
+	if t.bySpeciesCode == nil {
+		if species, modTime, err := t.readCache(); err == nil {
+			t.index(species, modTime)
+			if time.Since(modTime) < taxonomyMaxAge {
+				return nil
+			}
+		}
+	}
+
+	if time.Since(t.lastAttempt) < 5*time.Minute || t.apiKey == "" {
+		if t.bySpeciesCode != nil {
+			return nil
+		}
+		return fmt.Errorf("eBird taxonomy not cached and no download possible")
+	}
+	t.lastAttempt = time.Now()
+
+	species, err := t.download()
+	if err != nil {
+		if t.bySpeciesCode != nil {
+			log.Printf("[EBIRD] Taxonomy refresh failed, using cached copy: %v", err)
+			return nil
+		}
+		return fmt.Errorf("failed to download eBird taxonomy: %w", err)
+	}
+
+	t.index(species, time.Now())
+	if err := t.writeCache(species); err != nil {
+		log.Printf("[EBIRD] Failed to cache taxonomy at %s: %v", t.path, err)
+	}
+	log.Printf("[EBIRD] Loaded taxonomy with %d species", len(species))
+	return nil
+}
+
+// index builds the lookup maps; the caller holds t.mu
+func (t *Taxonomy) index(species []Species, loadedAt time.Time) {
+	t.byCommonName = make(map[string]Species, len(species))
+	t.byScientific = make(map[string]Species, len(species))
+	t.bySpeciesCode = make(map[string]Species, len(species))
+	for _, s := range species {
+		t.byCommonName[normalizeTaxonName(s.CommonName)] = s
+		t.byScientific[normalizeTaxonName(s.ScientificName)] = s
+		t.bySpeciesCode[strings.ToLower(s.SpeciesCode)] = s
+	}
+	t.loadedAt = loadedAt
+}
+
+func (t *Taxonomy) readCache() ([]Species, time.Time, error) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var species []Species
+	if err := json.Unmarshal(data, &species); err != nil {
+		return nil, time.Time{}, err
+	}
+	return species, info.ModTime(), nil
+}
+
+// writeCache saves the taxonomy atomically
+func (t *Taxonomy) writeCache(species []Species) error {
+	data, err := json.Marshal(species)
+	if err != nil {
+		return err
+	}
+	return filex.AtomicWriteFile(t.path, data, 0644)
+}
+
+// download fetches every species in the eBird taxonomy
+func (t *Taxonomy) download() ([]Species, error) {
+	params := url.Values{}
+	params.Add("cat", "species")
+	params.Add("fmt", "json")
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/ref/taxonomy/ebird?%s", baseURL, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-eBirdApiToken", t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("eBird API error: %d", resp.StatusCode)
+	}
+
+	var species []Species
+	if err := json.NewDecoder(resp.Body).Decode(&species); err != nil {
+		return nil, err
+	}
+	return species, nil
+}
+
+// normalizeTaxonName lowercases a name and treats hyphens and underscores as spaces
+func normalizeTaxonName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("-", " ", "_", " ", "'", "", "’", "").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
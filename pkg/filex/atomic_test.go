@@ -0,0 +1,62 @@
+package filex
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAtomicWriteJSONReplacesTheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "jobs.json")
+	if err := AtomicWriteJSON(path, map[string]int{"pending": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AtomicWriteJSON(path, map[string]int{"pending": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]int
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("written file isn't JSON: %v", err)
+	}
+	if got["pending"] != 1 {
+		t.Errorf("pending = %d, want the second write's 1", got["pending"])
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("%d files in the directory, want only jobs.json and no leftover temporary files", len(entries))
+	}
+
+	// A value that can't be marshaled leaves the last good file in place
+	if err := AtomicWriteJSON(path, map[string]any{"bad": func() {}}); err == nil {
+		t.Error("unmarshalable value was written")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(data) {
+		t.Error("a failed write changed the file")
+	}
+}
+
+func TestAtomicWriteFileKeepsPrivateFilesPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets", "tokens.json")
+	if err := AtomicWriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]os.FileMode{path: 0600, filepath.Dir(path): 0700} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s has mode %o, want %o", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"golang.org/x/oauth2/google"
+)
+
+// clientGuard, when set, is asked before any Google Cloud client is created
+var clientGuard atomic.Pointer[func(purpose string) error]
+
+// SetClientGuard has every Google Cloud client ask guard before it is created and fail with its
+// error, so a dry run can't reach production buckets or secrets; nil removes the guard
+func SetClientGuard(guard func(purpose string) error) {
+	if guard == nil {
+		clientGuard.Store(nil)
+		return
+	}
+	clientGuard.Store(&guard)
+}
+
+// checkClient asks the guard, if any, whether a client for purpose may be created
+func checkClient(purpose string) error {
+	if guard := clientGuard.Load(); guard != nil {
+		return (*guard)(purpose)
+	}
+	return nil
+}
+
+// DefaultClient returns an HTTP client authenticated with Application Default Credentials for
+// scopes, described by purpose (e.g. "GCS bucket my-bucket") to the client guard
+func DefaultClient(ctx context.Context, purpose string, scopes ...string) (*http.Client, error) {
+	if err := checkClient(purpose); err != nil {
+		return nil, err
+	}
+	return google.DefaultClient(ctx, scopes...)
+}
@@ -4,27 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 )
 
-// UpdateSecret updates a secret in GCP Secret Manager
-func UpdateSecret(secretName, secretValue string) error {
-	projectID := os.Getenv("GCP_PROJECT")
-	if projectID == "" {
-		return fmt.Errorf("GCP_PROJECT environment variable not set")
-	}
-
-	autoUpdate := os.Getenv("AUTO_UPDATE_SECRETS")
-	if autoUpdate != "true" {
-		log.Printf("[SECRETS] AUTO_UPDATE_SECRETS not enabled, skipping update for %s", secretName)
-		return nil
+// newSecretClient creates a Secret Manager client, unless the client guard refuses it
+func newSecretClient(ctx context.Context) (*secretmanager.Client, error) {
+	if err := checkClient("Secret Manager"); err != nil {
+		return nil, err
 	}
+	return secretmanager.NewClient(ctx)
+}
 
+// AddSecretVersion writes a new version of a secret
+func AddSecretVersion(projectID, secretName, secretValue string) error {
 	ctx := context.Background()
-	client, err := secretmanager.NewClient(ctx)
+	client, err := newSecretClient(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create Secret Manager client: %w", err)
 	}
@@ -47,19 +43,38 @@ func UpdateSecret(secretName, secretValue string) error {
 	return nil
 }
 
-// UpdateYotoTokens updates both access and refresh tokens in Secret Manager
-func UpdateYotoTokens(accessToken, refreshToken string) error {
+// AccessSecret reads the latest version of a secret
+func AccessSecret(projectID, secretName string) (string, error) {
+	ctx := context.Background()
+	client, err := newSecretClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Secret Manager client: %w", err)
+	}
+	defer client.Close()
+
+	result, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", projectID, secretName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to access secret %s: %w", secretName, err)
+	}
+
+	return string(result.Payload.Data), nil
+}
+
+// UpdateYotoTokens updates both access and refresh tokens in the project's Secret Manager
+func UpdateYotoTokens(projectID, accessToken, refreshToken string) error {
 	var errs []error
 
 	if accessToken != "" {
-		if err := UpdateSecret("yoto-access-token", accessToken); err != nil {
+		if err := AddSecretVersion(projectID, "yoto-access-token", accessToken); err != nil {
 			log.Printf("[SECRETS] Failed to update yoto-access-token: %v", err)
 			errs = append(errs, err)
 		}
 	}
 
 	if refreshToken != "" {
-		if err := UpdateSecret("yoto-refresh-token", refreshToken); err != nil {
+		if err := AddSecretVersion(projectID, "yoto-refresh-token", refreshToken); err != nil {
 			log.Printf("[SECRETS] Failed to update yoto-refresh-token: %v", err)
 			errs = append(errs, err)
 		}
@@ -0,0 +1,60 @@
+package geoip
+
+import "net"
+
+// City is the part of a GeoLite2-City or GeoIP2-City record used to locate a listener
+type City struct {
+	City        string // English names
+	Subdivision string
+	Country     string
+	CountryCode string
+	Latitude    float64
+	Longitude   float64
+	TimeZone    string
+	HasLocation bool // Latitude and Longitude were present
+}
+
+// City returns the city record for ip, or nil when the database has none
+func (r *Reader) City(ip net.IP) (*City, error) {
+	record, err := r.Lookup(ip)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	fields, ok := record.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	city := &City{
+		City:    englishName(fields["city"]),
+		Country: englishName(fields["country"]),
+	}
+	if country, ok := fields["country"].(map[string]interface{}); ok {
+		city.CountryCode, _ = country["iso_code"].(string)
+	}
+	if subdivisions, ok := fields["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+		city.Subdivision = englishName(subdivisions[0])
+	}
+	if location, ok := fields["location"].(map[string]interface{}); ok {
+		latitude, hasLatitude := location["latitude"].(float64)
+		longitude, hasLongitude := location["longitude"].(float64)
+		city.Latitude, city.Longitude = latitude, longitude
+		city.HasLocation = hasLatitude && hasLongitude
+		city.TimeZone, _ = location["time_zone"].(string)
+	}
+	return city, nil
+}
+
+// englishName reads names.en from a city, country, or subdivision record
+func englishName(record interface{}) string {
+	fields, ok := record.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	names, ok := fields["names"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := names["en"].(string)
+	return name
+}
@@ -0,0 +1,359 @@
+// Package geoip reads MaxMind DB (.mmdb) files such as GeoLite2-City, so IP addresses can be
+// located without calling a rate-limited lookup API.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+// metadataMarker precedes the metadata map at the end of every MaxMind DB file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the run of zero bytes between the search tree and the data section
+const dataSectionSeparator = 16
+
+// maxDecodeDepth bounds nested maps and arrays, so a corrupt file can't recurse forever
+const maxDecodeDepth = 32
+
+// Metadata describes a database
+type Metadata struct {
+	DatabaseType string
+	BuildEpoch   uint64
+	IPVersion    int
+	NodeCount    int
+	RecordSize   int
+}
+
+// Reader looks up records in an in-memory MaxMind DB file. It's safe for concurrent use.
+type Reader struct {
+	buffer      []byte
+	metadata    Metadata
+	dataSection []byte
+	ipv4Start   int // Node reached after the 96 leading zero bits of an IPv4-mapped address
+}
+
+// Open reads and validates the database at path
+func Open(path string) (*Reader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buffer)
+}
+
+// FromBytes creates a reader over a database already in memory
+func FromBytes(buffer []byte) (*Reader, error) {
+	markerAt := bytes.LastIndex(buffer, metadataMarker)
+	if markerAt < 0 {
+		return nil, errors.New("invalid MaxMind DB: metadata not found")
+	}
+	metadataStart := markerAt + len(metadataMarker)
+	raw, _, err := (&decoder{buffer: buffer[metadataStart:]}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MaxMind DB metadata: %w", err)
+	}
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB metadata: not a map")
+	}
+
+	metadata := Metadata{
+		DatabaseType: stringField(fields, "database_type"),
+		BuildEpoch:   uintField(fields, "build_epoch"),
+		IPVersion:    int(uintField(fields, "ip_version")),
+		NodeCount:    int(uintField(fields, "node_count")),
+		RecordSize:   int(uintField(fields, "record_size")),
+	}
+	switch metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported MaxMind DB record size %d", metadata.RecordSize)
+	}
+
+	treeSize := metadata.NodeCount * metadata.RecordSize / 4
+	if treeSize+dataSectionSeparator > markerAt {
+		return nil, errors.New("invalid MaxMind DB: search tree is larger than the file")
+	}
+
+	r := &Reader{
+		buffer:      buffer,
+		metadata:    metadata,
+		dataSection: buffer[treeSize+dataSectionSeparator : markerAt],
+	}
+	if metadata.IPVersion == 6 {
+		node := 0
+		for i := 0; i < 96 && node < metadata.NodeCount; i++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// Metadata returns the database's metadata
+func (r *Reader) Metadata() Metadata {
+	return r.metadata
+}
+
+// Lookup returns the decoded record for the network containing ip, or nil when the database has
+// none. Records are maps, arrays, strings, numbers, and booleans, as stored.
+func (r *Reader) Lookup(ip net.IP) (interface{}, error) {
+	if ip == nil {
+		return nil, errors.New("invalid IP address")
+	}
+
+	address := ip.To4()
+	node := 0
+	if address != nil {
+		node = r.ipv4Start
+	} else {
+		if r.metadata.IPVersion == 4 {
+			return nil, fmt.Errorf("IPv6 address %s can't be looked up in an IPv4 database", ip)
+		}
+		address = ip.To16()
+	}
+
+	bitCount := len(address) * 8
+	for i := 0; i < bitCount && node < r.metadata.NodeCount; i++ {
+		bit := (address[i/8] >> (7 - uint(i%8))) & 1
+		node = r.readNode(node, int(bit))
+	}
+
+	if node == r.metadata.NodeCount {
+		return nil, nil
+	}
+	if node < r.metadata.NodeCount {
+		return nil, errors.New("invalid MaxMind DB: search tree ended without a record")
+	}
+
+	offset := node - r.metadata.NodeCount - dataSectionSeparator
+	if offset < 0 || offset >= len(r.dataSection) {
+		return nil, errors.New("invalid MaxMind DB: record pointer out of range")
+	}
+	value, _, err := (&decoder{buffer: r.dataSection}).decode(offset, 0)
+	return value, err
+}
+
+// readNode returns the left (bit 0) or right (bit 1) record of a search tree node
+func (r *Reader) readNode(node int, bit int) int {
+	switch r.metadata.RecordSize {
+	case 24:
+		b := r.buffer[node*6:]
+		if bit == 0 {
+			return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+		return int(b[3])<<16 | int(b[4])<<8 | int(b[5])
+	case 28:
+		b := r.buffer[node*7:]
+		if bit == 0 {
+			return int(b[3]&0xF0)<<20 | int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+		return int(b[3]&0x0F)<<24 | int(b[4])<<16 | int(b[5])<<8 | int(b[6])
+	default:
+		b := r.buffer[node*8:]
+		if bit == 0 {
+			return int(binary.BigEndian.Uint32(b[0:4]))
+		}
+		return int(binary.BigEndian.Uint32(b[4:8]))
+	}
+}
+
+// Data section field types
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// decoder decodes values from a data section; pointers are offsets into buffer
+type decoder struct {
+	buffer []byte
+}
+
+var errTruncated = errors.New("invalid MaxMind DB: unexpected end of data")
+
+// decode returns the value at offset and the offset just past it
+func (d *decoder) decode(offset int, depth int) (interface{}, int, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, errors.New("invalid MaxMind DB: data nested too deeply")
+	}
+	if offset >= len(d.buffer) {
+		return nil, 0, errTruncated
+	}
+
+	control := d.buffer[offset]
+	offset++
+	fieldType := int(control >> 5)
+
+	if fieldType == typePointer {
+		pointer, next, err := d.pointer(control, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	if fieldType == typeExtended {
+		if offset >= len(d.buffer) {
+			return nil, 0, errTruncated
+		}
+		fieldType = 7 + int(d.buffer[offset])
+		offset++
+	}
+
+	size, offset, err := d.size(control, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch fieldType {
+	case typeMap:
+		values := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("invalid MaxMind DB: map key is not a string")
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			values[name] = value
+			offset = next
+		}
+		return values, offset, nil
+	case typeArray:
+		values := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, value)
+			offset = next
+		}
+		return values, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > len(d.buffer) {
+		return nil, 0, errTruncated
+	}
+	data := d.buffer[offset : offset+size]
+	next := offset + size
+
+	switch fieldType {
+	case typeString:
+		return string(data), next, nil
+	case typeBytes:
+		return append([]byte(nil), data...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid MaxMind DB: double is not 8 bytes")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid MaxMind DB: float is not 4 bytes")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		var value uint64
+		for _, b := range data {
+			value = value<<8 | uint64(b)
+		}
+		return value, next, nil
+	case typeInt32:
+		var value uint32
+		for _, b := range data {
+			value = value<<8 | uint32(b)
+		}
+		return int64(int32(value)), next, nil
+	case typeUint128:
+		// Nothing the lookups here read is this wide; keep the raw bytes
+		return append([]byte(nil), data...), next, nil
+	default:
+		return nil, 0, fmt.Errorf("invalid MaxMind DB: unsupported field type %d", fieldType)
+	}
+}
+
+// size decodes a field's payload size from its control byte and any size bytes that follow
+func (d *decoder) size(control byte, offset int) (int, int, error) {
+	size := int(control & 0x1F)
+	if size < 29 {
+		return size, offset, nil
+	}
+
+	extra := size - 28
+	if offset+extra > len(d.buffer) {
+		return 0, 0, errTruncated
+	}
+	b := d.buffer[offset : offset+extra]
+	switch size {
+	case 29:
+		size = 29 + int(b[0])
+	case 30:
+		size = 285 + (int(b[0])<<8 | int(b[1]))
+	default:
+		size = 65821 + (int(b[0])<<16 | int(b[1])<<8 | int(b[2]))
+	}
+	return size, offset + extra, nil
+}
+
+// pointer decodes a pointer field, returning its target offset and the offset past the pointer
+func (d *decoder) pointer(control byte, offset int) (int, int, error) {
+	pointerSize := int((control>>3)&0x3) + 1
+	if offset+pointerSize > len(d.buffer) {
+		return 0, 0, errTruncated
+	}
+	b := d.buffer[offset : offset+pointerSize]
+	high := int(control & 0x7)
+
+	var pointer int
+	switch pointerSize {
+	case 1:
+		pointer = high<<8 | int(b[0])
+	case 2:
+		pointer = (high<<16 | int(b[0])<<8 | int(b[1])) + 2048
+	case 3:
+		pointer = (high<<24 | int(b[0])<<16 | int(b[1])<<8 | int(b[2])) + 526336
+	default:
+		pointer = int(binary.BigEndian.Uint32(b))
+	}
+	return pointer, offset + pointerSize, nil
+}
+
+func stringField(fields map[string]interface{}, key string) string {
+	value, _ := fields[key].(string)
+	return value
+}
+
+func uintField(fields map[string]interface{}, key string) uint64 {
+	value, _ := fields[key].(uint64)
+	return value
+}
@@ -0,0 +1,136 @@
+package httpx
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while a host's breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+const (
+	breakerFailureThreshold = 5
+	breakerCooldown         = 30 * time.Second
+)
+
+// Breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// Breaker stops calls to a host after consecutive failures. Once the cooldown passes a single
+// trial request is let through; its success closes the breaker and its failure reopens it.
+type Breaker struct {
+	mu        sync.Mutex
+	host      string
+	state     string
+	failures  int
+	openedAt  time.Time
+	trialSent bool
+}
+
+// BreakerStatus is a breaker's state for monitoring
+type BreakerStatus struct {
+	Host     string    `json:"host"`
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"opened_at,omitempty"`
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*Breaker)
+)
+
+// breakerFor returns the shared breaker for a host, so every client calling it sees the same state
+func breakerFor(host string) *Breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	breaker, ok := breakers[host]
+	if !ok {
+		breaker = &Breaker{host: host, state: StateClosed}
+		breakers[host] = breaker
+	}
+	return breaker
+}
+
+// Allow reports whether a request may be sent now
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < breakerCooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.trialSent = true
+		return true
+	case StateHalfOpen:
+		if b.trialSent {
+			return false
+		}
+		b.trialSent = true
+		return true
+	}
+	return true
+}
+
+// Record updates the breaker with the outcome of a request
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		if b.state != StateClosed {
+			log.Printf("[HTTPX] Circuit closed for %s", b.host)
+		}
+		b.state = StateClosed
+		b.failures = 0
+		b.trialSent = false
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= breakerFailureThreshold {
+		if b.state != StateOpen {
+			log.Printf("[HTTPX] Circuit open for %s after %d failures, pausing calls for %v", b.host, b.failures, breakerCooldown)
+		}
+		b.state = StateOpen
+		b.openedAt = time.Now()
+		b.trialSent = false
+	}
+}
+
+// Breakers returns the state of every host's breaker, sorted by host
+func Breakers() []BreakerStatus {
+	breakersMu.Lock()
+	list := make([]*Breaker, 0, len(breakers))
+	for _, breaker := range breakers {
+		list = append(list, breaker)
+	}
+	breakersMu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(list))
+	for _, breaker := range list {
+		breaker.mu.Lock()
+		statuses = append(statuses, BreakerStatus{
+			Host:     breaker.host,
+			State:    breaker.state,
+			Failures: breaker.failures,
+			OpenedAt: breaker.openedAt,
+		})
+		breaker.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Host < statuses[j].Host
+	})
+	return statuses
+}
@@ -0,0 +1,232 @@
+// Package httpx provides the HTTP client used for every outbound API call: transient failures
+// (timeouts, connection resets, 429 and 5xx responses) are retried with jittered exponential
+// backoff, and hosts that keep failing are short-circuited by a per-host circuit breaker.
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Options configures a client. Zero values use the defaults noted on each field.
+type Options struct {
+	// Timeout bounds the whole call, retries and backoff included (0 means no limit)
+	Timeout time.Duration
+	// AttemptTimeout bounds each attempt, so one hung connection doesn't use the whole Timeout (0 means no limit)
+	AttemptTimeout time.Duration
+	// MaxRetries is the number of retries after the first attempt (default 3; negative disables retries)
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubling each time (default 250ms)
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and any Retry-After the server asks for (default 5s)
+	MaxDelay time.Duration
+	// RetryUnprocessed also retries POST and PATCH requests, but only when the server can't have
+	// acted on them: the connection was refused, or it answered 429 with a Retry-After. Timeouts and
+	// 5xx responses are returned as they are, since the work may have been done (and billed).
+	RetryUnprocessed bool
+	// DisableBreaker skips the per-host circuit breaker
+	DisableBreaker bool
+}
+
+const (
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 250 * time.Millisecond
+	defaultMaxDelay   = 5 * time.Second
+)
+
+// Default is a general-purpose client for one-off downloads
+var Default = NewClient(Options{Timeout: 30 * time.Second})
+
+// NewClient returns an *http.Client whose transport retries transient failures
+func NewClient(options Options) *http.Client {
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: NewTransport(http.DefaultTransport, options),
+	}
+}
+
+// Get sends a GET request with client, abandoning it when ctx is done
+func Get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// Transport is an http.RoundTripper that adds retries and circuit breaking to another transport
+type Transport struct {
+	base    http.RoundTripper
+	options Options
+}
+
+// NewTransport wraps base (http.DefaultTransport when nil)
+func NewTransport(base http.RoundTripper, options Options) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = defaultMaxRetries
+	} else if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	if options.BaseDelay <= 0 {
+		options.BaseDelay = defaultBaseDelay
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = defaultMaxDelay
+	}
+	return &Transport{base: base, options: options}
+}
+
+// RoundTrip sends the request, retrying transient failures while the request's context allows
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var breaker *Breaker
+	if !t.options.DisableBreaker {
+		breaker = breakerFor(req.URL.Host)
+		if !breaker.Allow() {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, req.URL.Host)
+		}
+	}
+
+	replayable, idempotent := t.canRetry(req)
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, attempt)
+
+		transient := isTransient(req.Context(), resp, err)
+		if breaker != nil {
+			breaker.Record(!transient)
+		}
+		retryable := replayable && (idempotent || (t.options.RetryUnprocessed && isUnprocessed(resp, err)))
+		if !transient || !retryable || attempt >= t.options.MaxRetries {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if err != nil {
+			log.Printf("[HTTPX] %s %s failed (%v), retry %d/%d in %v", req.Method, req.URL.Host, err, attempt+1, t.options.MaxRetries, delay)
+		} else {
+			log.Printf("[HTTPX] %s %s returned %d, retry %d/%d in %v", req.Method, req.URL.Host, resp.StatusCode, attempt+1, t.options.MaxRetries, delay)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+
+		if breaker != nil && !breaker.Allow() {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, req.URL.Host)
+		}
+	}
+}
+
+// attempt sends one try of the request, with a fresh body and the per-attempt deadline
+func (t *Transport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	try := req
+	if attempt > 0 && req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		try = req.Clone(req.Context())
+		try.Body = body
+	}
+
+	if t.options.AttemptTimeout <= 0 {
+		return t.base.RoundTrip(try)
+	}
+
+	ctx, cancel := context.WithTimeout(try.Context(), t.options.AttemptTimeout)
+	resp, err := t.base.RoundTrip(try.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The deadline stays in force while the caller reads the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// canRetry reports whether the request's body, if any, can be replayed and whether its method is
+// idempotent
+func (t *Transport) canRetry(req *http.Request) (replayable bool, idempotent bool) {
+	replayable = req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return replayable, true
+	}
+	return replayable, false
+}
+
+// isUnprocessed reports whether a failed attempt certainly never ran on the server: the connection
+// couldn't be made, or the server turned the call away with 429 and said when to come back
+func isUnprocessed(resp *http.Response, err error) bool {
+	if err != nil {
+		return NotSent(err)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != ""
+}
+
+// NotSent reports whether a request error means the request never reached the server: the
+// connection was refused or couldn't be dialed, or the host's circuit breaker is open
+func NotSent(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, ErrCircuitOpen) || errors.Is(err, syscall.ECONNREFUSED) ||
+		(errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// backoff returns the delay before the next attempt: the server's Retry-After when it sends one,
+// otherwise exponential backoff with full jitter, capped at MaxDelay
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.options.MaxDelay)
+		}
+	}
+
+	ceiling := min(t.options.BaseDelay<<attempt, t.options.MaxDelay)
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// isTransient reports whether a failure is worth retrying. Cancellation by the caller is not.
+func isTransient(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+			errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// cancelOnClose releases a per-attempt context once the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
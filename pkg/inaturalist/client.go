@@ -1,12 +1,15 @@
 package inaturalist
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
 type Client struct {
@@ -27,6 +30,16 @@ type Taxon struct {
 	ConservationStatus  *ConservationStatus `json:"conservation_status"`
 	DefaultPhoto        *Photo              `json:"default_photo"`
 	TaxonPhotos         []TaxonPhoto        `json:"taxon_photos"`
+	TaxonNames          []TaxonName         `json:"taxon_names"`
+}
+
+// TaxonName is a locale-tagged vernacular name (returned when all_names=true)
+type TaxonName struct {
+	Name     string `json:"name"`
+	Locale   string `json:"locale"`  // e.g. "es", "fr", "es-MX"
+	Lexicon  string `json:"lexicon"` // e.g. "Spanish", "French", "Scientific Names"
+	IsValid  bool   `json:"is_valid"`
+	Position int    `json:"position"` // Lower is more preferred
 }
 
 type ConservationStatus struct {
@@ -52,13 +65,15 @@ type ObservationSearch struct {
 }
 
 type Observation struct {
-	ID          int     `json:"id"`
-	PlaceGuess  string  `json:"place_guess"`
-	ObservedOn  string  `json:"observed_on"`
-	Description string  `json:"description"`
-	Taxon       *Taxon  `json:"taxon"`
-	Photos      []Photo `json:"photos"`
-	Sounds      []Sound `json:"sounds"`
+	ID          int          `json:"id"`
+	PlaceGuess  string       `json:"place_guess"`
+	ObservedOn  string       `json:"observed_on"`
+	Description string       `json:"description"`
+	Taxon       *Taxon       `json:"taxon"`
+	Photos      []Photo      `json:"photos"`
+	Sounds      []Sound      `json:"sounds"`
+	Annotations []Annotation `json:"annotations"`
+	FieldValues []FieldValue `json:"ofvs"`
 }
 
 type Sound struct {
@@ -69,22 +84,20 @@ type Sound struct {
 
 func NewClient() *Client {
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		baseURL: "https://api.inaturalist.org/v1",
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 10 * time.Second}),
+		baseURL:    "https://api.inaturalist.org/v1",
 	}
 }
 
 // SearchTaxon searches for a bird species in iNaturalist
-func (c *Client) SearchTaxon(birdName string) (*Taxon, error) {
+func (c *Client) SearchTaxon(ctx context.Context, birdName string) (*Taxon, error) {
 	// URL encode the bird name
 	encodedName := url.QueryEscape(birdName)
 
 	// Search for the taxon, specifically birds (Aves)
-	apiURL := fmt.Sprintf("%s/taxa?q=%s&iconic_taxa=Aves&per_page=1", c.baseURL, encodedName)
+	apiURL := fmt.Sprintf("%s/taxa?q=%s&iconic_taxa=Aves&per_page=1&all_names=true", c.baseURL, encodedName)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -113,13 +126,63 @@ func (c *Client) SearchTaxon(birdName string) (*Taxon, error) {
 	return &result.Results[0], nil
 }
 
+// lexiconLocales maps iNaturalist lexicon names to locales for names without a locale tag
+var lexiconLocales = map[string]string{
+	"spanish": "es",
+	"french":  "fr",
+	"english": "en",
+}
+
+// CommonNameForLocale returns the preferred vernacular name for a locale such as "es" or "fr-CA".
+// An exact locale match wins, then the base language, then names tagged only by lexicon.
+// Invalid (outdated) names are skipped.
+func (t *Taxon) CommonNameForLocale(locale string) (string, bool) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" {
+		return "", false
+	}
+	language := strings.SplitN(locale, "-", 2)[0]
+
+	var exact, base, lexicon *TaxonName
+	for i := range t.TaxonNames {
+		name := &t.TaxonNames[i]
+		if !name.IsValid || name.Name == "" {
+			continue
+		}
+
+		nameLocale := strings.ToLower(name.Locale)
+		switch {
+		case nameLocale == locale:
+			exact = preferredName(exact, name)
+		case strings.SplitN(nameLocale, "-", 2)[0] == language:
+			base = preferredName(base, name)
+		case nameLocale == "" && lexiconLocales[strings.ToLower(name.Lexicon)] == language:
+			lexicon = preferredName(lexicon, name)
+		}
+	}
+
+	for _, candidate := range []*TaxonName{exact, base, lexicon} {
+		if candidate != nil {
+			return candidate.Name, true
+		}
+	}
+	return "", false
+}
+
+func preferredName(current *TaxonName, candidate *TaxonName) *TaxonName {
+	if current == nil || candidate.Position < current.Position {
+		return candidate
+	}
+	return current
+}
+
 // GetRecentObservations gets recent observations of a bird species
-func (c *Client) GetRecentObservations(taxonID int, lat, lng float64) ([]Observation, error) {
+func (c *Client) GetRecentObservations(ctx context.Context, taxonID int, lat, lng float64) ([]Observation, error) {
 	// Search for recent observations near the location
 	apiURL := fmt.Sprintf("%s/observations?taxon_id=%d&lat=%f&lng=%f&radius=50&order_by=observed_on&order=desc&per_page=5&photos=true",
 		c.baseURL, taxonID, lat, lng)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -215,15 +278,15 @@ func (c *Client) FormatForKids(taxon *Taxon, observations []Observation) []strin
 		// We handle location awareness properly in Track 4 with actual eBird sightings
 		// This prevents generic/incorrect location claims
 		/*
-		if len(locations) > 0 {
-			if len(locations) == 1 {
-				facts = append(facts, fmt.Sprintf("Someone recently spotted this bird near %s! Bird watchers love to record where they see different birds.", locations[0]))
-			} else if len(locations) == 2 {
-				facts = append(facts, fmt.Sprintf("People have recently seen this bird in places like %s and %s. Isn't it amazing how birds can live in different neighborhoods?", locations[0], locations[1]))
-			} else {
-				facts = append(facts, fmt.Sprintf("Bird watchers have spotted this bird in many places nearby, including %s! These birds might even live in your neighborhood.", strings.Join(locations[:2], " and ")))
+			if len(locations) > 0 {
+				if len(locations) == 1 {
+					facts = append(facts, fmt.Sprintf("Someone recently spotted this bird near %s! Bird watchers love to record where they see different birds.", locations[0]))
+				} else if len(locations) == 2 {
+					facts = append(facts, fmt.Sprintf("People have recently seen this bird in places like %s and %s. Isn't it amazing how birds can live in different neighborhoods?", locations[0], locations[1]))
+				} else {
+					facts = append(facts, fmt.Sprintf("Bird watchers have spotted this bird in many places nearby, including %s! These birds might even live in your neighborhood.", strings.Join(locations[:2], " and ")))
+				}
 			}
-		}
 		*/
 
 		// Media observation fact
@@ -0,0 +1,187 @@
+package inaturalist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Controlled annotation term and value iNaturalist observers tag adult birds' photos with
+const (
+	AttributeLifeStage = 1
+	LifeStageAdult     = 2
+)
+
+// Observations per taxon examined for field marks
+const fieldMarkSampleSize = 100
+
+// A field mark must be recorded on at least this many observations to be trusted
+const minFieldMarkObservations = 3
+
+// Annotation is a controlled term an observer attached to an observation's photos, such as
+// life stage "adult" or sex "female"
+type Annotation struct {
+	ControlledAttributeID int `json:"controlled_attribute_id"`
+	ControlledValueID     int `json:"controlled_value_id"`
+}
+
+// FieldValue is an observation field an observer filled in, such as "Cap color" = "Black"
+type FieldValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// FieldMark is a colored body part that identifies a bird ("black cap"), with how many adult
+// observations recorded it
+type FieldMark struct {
+	Part  string `json:"part"`
+	Color string `json:"color"`
+	Count int    `json:"count"`
+}
+
+// Phrase is the mark as it reads in a sentence, e.g. "black cap"
+func (m FieldMark) Phrase() string {
+	return m.Color + " " + m.Part
+}
+
+// FieldMarks are the marks observers most often record for a taxon, most common first
+type FieldMarks struct {
+	TaxonID      int         `json:"taxon_id"`
+	Observations int         `json:"observations"` // Adult observations examined
+	Marks        []FieldMark `json:"marks"`
+}
+
+// fieldMarkColors are the values a color field can hold; multi-word values ("black and white")
+// must be made only of these
+var fieldMarkColors = map[string]bool{
+	"black": true, "white": true, "gray": true, "grey": true, "brown": true, "red": true,
+	"orange": true, "yellow": true, "green": true, "blue": true, "purple": true, "pink": true,
+	"buff": true, "chestnut": true, "rufous": true, "olive": true, "tan": true, "and": true,
+}
+
+// fieldMarkSuffixes end the names of observation fields that record a body part's color
+var fieldMarkSuffixes = []string{" color", " colour", " colors", " colours"}
+
+// GetFieldMarks samples research-grade photo observations of a taxon and summarizes the colored
+// body parts observers recorded in observation fields
+func (c *Client) GetFieldMarks(ctx context.Context, taxonID int) (*FieldMarks, error) {
+	apiURL := fmt.Sprintf("%s/observations?taxon_id=%d&quality_grade=research&photos=true&order_by=votes&per_page=%d",
+		c.baseURL, taxonID, fieldMarkSampleSize)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "BirdSongExplorer/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch observations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("iNaturalist API returned status %d", resp.StatusCode)
+	}
+
+	var result ObservationSearch
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	marks := SummarizeFieldMarks(result.Results)
+	marks.TaxonID = taxonID
+	if len(marks.Marks) == 0 {
+		return nil, fmt.Errorf("no field marks recorded for taxon %d", taxonID)
+	}
+	return marks, nil
+}
+
+// SummarizeFieldMarks tallies the color fields of adult observations; observations annotated
+// with another life stage are skipped, since young birds often look different. Each body part
+// keeps its most common color when most observations recording the part agree on it.
+func SummarizeFieldMarks(observations []Observation) *FieldMarks {
+	summary := &FieldMarks{}
+	counts := make(map[string]map[string]int) // part -> color -> observations
+	var parts []string
+
+	for _, obs := range observations {
+		if !obs.isAdult() {
+			continue
+		}
+		summary.Observations++
+
+		recorded := make(map[string]bool)
+		for _, field := range obs.FieldValues {
+			part, color, ok := parseFieldMark(field)
+			if !ok || recorded[part] {
+				continue
+			}
+			recorded[part] = true
+			if counts[part] == nil {
+				counts[part] = make(map[string]int)
+				parts = append(parts, part)
+			}
+			counts[part][color]++
+		}
+	}
+
+	for _, part := range parts {
+		var best FieldMark
+		total := 0
+		for color, count := range counts[part] {
+			total += count
+			if count > best.Count || count == best.Count && color < best.Color {
+				best = FieldMark{Part: part, Color: color, Count: count}
+			}
+		}
+		if best.Count >= minFieldMarkObservations && best.Count*2 > total {
+			summary.Marks = append(summary.Marks, best)
+		}
+	}
+
+	sort.SliceStable(summary.Marks, func(i, j int) bool {
+		return summary.Marks[i].Count > summary.Marks[j].Count
+	})
+	return summary
+}
+
+// isAdult reports whether the observation is of an adult or has no life stage annotation
+func (o Observation) isAdult() bool {
+	for _, annotation := range o.Annotations {
+		if annotation.ControlledAttributeID == AttributeLifeStage {
+			return annotation.ControlledValueID == LifeStageAdult
+		}
+	}
+	return true
+}
+
+// parseFieldMark reads a "<part> color" field whose value is a color, lowercased
+func parseFieldMark(field FieldValue) (string, string, bool) {
+	name := strings.ToLower(strings.TrimSpace(field.Name))
+	part := ""
+	for _, suffix := range fieldMarkSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			part = strings.TrimSpace(strings.TrimSuffix(name, suffix))
+			break
+		}
+	}
+	if part == "" {
+		return "", "", false
+	}
+
+	words := strings.Fields(strings.ToLower(field.Value))
+	if len(words) == 0 || words[0] == "and" || words[len(words)-1] == "and" {
+		return "", "", false
+	}
+	for _, word := range words {
+		if !fieldMarkColors[word] {
+			return "", "", false
+		}
+	}
+	return part, strings.Join(words, " "), true
+}
@@ -0,0 +1,95 @@
+package macaulay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+)
+
+const (
+	searchURL = "https://search.macaulaylibrary.org/api/v1/search"
+	assetURL  = "https://macaulaylibrary.org/asset"
+)
+
+type Client struct {
+	httpClient *http.Client
+}
+
+type searchResponse struct {
+	Results struct {
+		Count   int         `json:"count"`
+		Content []Recording `json:"content"`
+	} `json:"results"`
+}
+
+// Recording is one Macaulay Library audio asset
+type Recording struct {
+	AssetID     json.Number `json:"assetId"`
+	CommonName  string      `json:"commonName"`
+	SciName     string      `json:"sciName"`
+	Recordist   string      `json:"userDisplayName"`
+	Rating      json.Number `json:"rating"`   // Community rating, 0-5 stars
+	Duration    json.Number `json:"duration"` // Seconds
+	MediaURL    string      `json:"mediaUrl"`
+	Location    string      `json:"location"`
+	Attribution string      `json:"-"`
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 30 * time.Second}),
+	}
+}
+
+// SearchRecordings returns audio for an eBird species code (e.g. "amerob"), best rated first
+func (c *Client) SearchRecordings(ctx context.Context, speciesCode string, count int) ([]Recording, error) {
+	params := url.Values{}
+	params.Add("taxonCode", speciesCode)
+	params.Add("mediaType", "audio")
+	params.Add("sort", "rating_rank_desc")
+	params.Add("count", fmt.Sprintf("%d", count))
+
+	resp, err := httpx.Get(ctx, c.httpClient, fmt.Sprintf("%s?%s", searchURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Macaulay Library API error: %d", resp.StatusCode)
+	}
+
+	var result searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	recordings := result.Results.Content
+	for i := range recordings {
+		rec := &recordings[i]
+		if rec.MediaURL == "" {
+			rec.MediaURL = fmt.Sprintf("https://cdn.download.ams.birds.cornell.edu/api/v1/asset/%s/audio", rec.AssetID)
+		}
+		rec.Attribution = fmt.Sprintf("%s, ML%s, Macaulay Library at the Cornell Lab of Ornithology, %s/%s",
+			rec.Recordist, rec.AssetID, assetURL, rec.AssetID)
+	}
+
+	return recordings, nil
+}
+
+// RatingValue returns the community rating, or 0 when unrated
+func (r Recording) RatingValue() float64 {
+	rating, _ := r.Rating.Float64()
+	return rating
+}
+
+// DurationSeconds returns the recording length in whole seconds
+func (r Recording) DurationSeconds() int {
+	duration, _ := r.Duration.Float64()
+	return int(duration)
+}
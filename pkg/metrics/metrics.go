@@ -0,0 +1,284 @@
+// Package metrics records counters and histograms and serves them in the Prometheus text
+// exposition format, so operators can scrape /metrics without pulling in a client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets suit durations of outbound calls and uploads, in seconds
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// collector is a metric family that can write itself out
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]collector)
+)
+
+// register adds a metric family, panicking on a duplicate name since that is a programming error
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: %s registered twice", c.name()))
+	}
+	registry[c.name()] = c
+}
+
+// series holds one labelled series' key and values, in label order
+type series struct {
+	labelValues []string
+}
+
+// labelKey joins label values into a map key
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// checkLabels panics when a call passes the wrong number of label values
+func checkLabels(metric string, labels []string, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", metric, len(labels), len(values)))
+	}
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+	series map[string]series
+}
+
+// NewCounterVec creates and registers a counter
+func NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		values:     make(map[string]float64),
+		series:     make(map[string]series),
+	}
+	register(counter)
+	return counter
+}
+
+// Inc adds one to the series with the given label values
+func (cv *CounterVec) Inc(labelValues ...string) {
+	cv.Add(1, labelValues...)
+}
+
+// Add adds value (which must not be negative) to the series with the given label values
+func (cv *CounterVec) Add(value float64, labelValues ...string) {
+	checkLabels(cv.metricName, cv.labels, labelValues)
+	if value < 0 {
+		return
+	}
+	key := labelKey(labelValues)
+
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	if _, ok := cv.series[key]; !ok {
+		cv.series[key] = series{labelValues: append([]string(nil), labelValues...)}
+	}
+	cv.values[key] += value
+}
+
+func (cv *CounterVec) name() string { return cv.metricName }
+
+func (cv *CounterVec) write(w io.Writer) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	writeHeader(w, cv.metricName, cv.help, "counter")
+	for _, key := range sortedKeys(cv.series) {
+		fmt.Fprintf(w, "%s%s %s\n", cv.metricName, formatLabels(cv.labels, cv.series[key].labelValues, "", ""), formatValue(cv.values[key]))
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]series
+	counts map[string][]uint64 // per bucket, not cumulative
+	sums   map[string]float64
+	totals map[string]uint64
+}
+
+// NewHistogramVec creates and registers a histogram with the given upper bounds (DefaultBuckets when nil)
+func NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	histogram := &HistogramVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		buckets:    buckets,
+		series:     make(map[string]series),
+		counts:     make(map[string][]uint64),
+		sums:       make(map[string]float64),
+		totals:     make(map[string]uint64),
+	}
+	register(histogram)
+	return histogram
+}
+
+// Observe records a value in the series with the given label values
+func (hv *HistogramVec) Observe(value float64, labelValues ...string) {
+	checkLabels(hv.metricName, hv.labels, labelValues)
+	key := labelKey(labelValues)
+
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+	if _, ok := hv.series[key]; !ok {
+		hv.series[key] = series{labelValues: append([]string(nil), labelValues...)}
+		hv.counts[key] = make([]uint64, len(hv.buckets))
+	}
+	for i, bound := range hv.buckets {
+		if value <= bound {
+			hv.counts[key][i]++
+			break
+		}
+	}
+	hv.sums[key] += value
+	hv.totals[key]++
+}
+
+// ObserveSince records the seconds elapsed since start
+func (hv *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	hv.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (hv *HistogramVec) name() string { return hv.metricName }
+
+func (hv *HistogramVec) write(w io.Writer) {
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	writeHeader(w, hv.metricName, hv.help, "histogram")
+	for _, key := range sortedKeys(hv.series) {
+		values := hv.series[key].labelValues
+
+		var cumulative uint64
+		for i, bound := range hv.buckets {
+			cumulative += hv.counts[key][i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", hv.metricName, formatLabels(hv.labels, values, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", hv.metricName, formatLabels(hv.labels, values, "le", "+Inf"), hv.totals[key])
+		fmt.Fprintf(w, "%s_sum%s %s\n", hv.metricName, formatLabels(hv.labels, values, "", ""), formatValue(hv.sums[key]))
+		fmt.Fprintf(w, "%s_count%s %d\n", hv.metricName, formatLabels(hv.labels, values, "", ""), hv.totals[key])
+	}
+}
+
+// GaugeFunc reports a value read when metrics are scraped
+type GaugeFunc struct {
+	metricName string
+	help       string
+	value      func() float64
+}
+
+// NewGaugeFunc creates and registers a gauge whose value comes from fn
+func NewGaugeFunc(name string, help string, fn func() float64) *GaugeFunc {
+	gauge := &GaugeFunc{metricName: name, help: help, value: fn}
+	register(gauge)
+	return gauge
+}
+
+func (g *GaugeFunc) name() string { return g.metricName }
+
+func (g *GaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.metricName, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatValue(g.value()))
+}
+
+// WriteText writes every registered metric in the Prometheus text format, sorted by name
+func WriteText(w io.Writer) {
+	registryMu.Lock()
+	collectors := make([]collector, 0, len(registry))
+	for _, c := range registry {
+		collectors = append(collectors, c)
+	}
+	registryMu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler serves the registered metrics for Prometheus to scrape
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+func writeHeader(w io.Writer, name string, help string, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// formatLabels renders {label="value",...}, with an optional extra label such as a bucket's le
+func formatLabels(labels []string, values []string, extraLabel string, extraValue string) string {
+	if len(labels) == 0 && extraLabel == "" {
+		return ""
+	}
+	escape := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+	parts := make([]string, 0, len(labels)+1)
+	for i, label := range labels {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, label, escape.Replace(values[i])))
+	}
+	if extraLabel != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, extraLabel, escape.Replace(extraValue)))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]series) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+// Package randx provides the seeded random source content builds draw from, so a card's build
+// for a day can be reproduced and tests can pin its choices
+package randx
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// unseeded serves nil randomizers, for callers that don't need reproducible choices
+var unseeded = New(time.Now().UnixNano())
+
+// Randomizer is a seeded random source safe for concurrent use. A nil Randomizer draws from a
+// time-seeded source.
+type Randomizer struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// New creates a randomizer with a fixed seed
+func New(seed int64) *Randomizer {
+	return &Randomizer{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Daily creates a randomizer seeded from a day (YYYY-MM-DD) and a key such as a card ID, so every
+// build of that card on that day makes the same choices
+func Daily(day string, key string) *Randomizer {
+	h := fnv.New64a()
+	h.Write([]byte(day))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return New(int64(h.Sum64()))
+}
+
+// Intn returns a number in [0, n); n must be positive
+func (r *Randomizer) Intn(n int) int {
+	if r == nil {
+		r = unseeded
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Intn(n)
+}
+
+// Int63n returns a number in [0, n); n must be positive
+func (r *Randomizer) Int63n(n int64) int64 {
+	if r == nil {
+		r = unseeded
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Int63n(n)
+}
+
+// Pick returns a random element of items, which must not be empty
+func Pick[T any](r *Randomizer, items []T) T {
+	return items[r.Intn(len(items))]
+}
@@ -1,17 +1,21 @@
 package wikipedia
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	fallbacks  []*Client // Tried in order when this edition has no page
 }
 
 type PageSummary struct {
@@ -19,7 +23,13 @@ type PageSummary struct {
 	DisplayTitle string `json:"displaytitle"`
 	Extract      string `json:"extract"`
 	Description  string `json:"description"`
-	ContentURLs  struct {
+	Thumbnail    struct {
+		Source string `json:"source"`
+	} `json:"thumbnail"`
+	OriginalImage struct {
+		Source string `json:"source"`
+	} `json:"originalimage"`
+	ContentURLs struct {
 		Desktop struct {
 			Page string `json:"page"`
 		} `json:"desktop"`
@@ -28,20 +38,67 @@ type PageSummary struct {
 
 func NewClient() *Client {
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 10 * time.Second}),
 		// Using Simple English Wikipedia for more kid-friendly content
 		baseURL: "https://simple.wikipedia.org/api/rest_v1",
 	}
 }
 
-func (c *Client) GetBirdSummary(birdName string) (*PageSummary, error) {
+// NewEnglishClient creates a client for the full English Wikipedia, which has more detail
+// (measurements, nesting, calls) than the Simple English pages
+func NewEnglishClient() *Client {
+	return &Client{
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 10 * time.Second}),
+		baseURL:    "https://en.wikipedia.org/api/rest_v1",
+	}
+}
+
+// NewClientForLanguage creates a client for the Wikipedia in a language code ("fr", "de", "es").
+// English keeps the kid-friendly Simple English Wikipedia.
+func NewClientForLanguage(lang string) *Client {
+	if lang == "" || lang == "en" {
+		return NewClient()
+	}
+	return &Client{
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 10 * time.Second}),
+		baseURL:    fmt.Sprintf("https://%s.wikipedia.org/api/rest_v1", lang),
+	}
+}
+
+// NewClientWithFallbacks creates a client for the first language's full Wikipedia that falls back
+// to the following languages' editions when a bird has no page, e.g. ("pt", "en") for Brazil
+func NewClientWithFallbacks(langs ...string) *Client {
+	if len(langs) == 0 {
+		return NewEnglishClient()
+	}
+	clients := make([]*Client, len(langs))
+	for i, lang := range langs {
+		clients[i] = &Client{
+			httpClient: httpx.NewClient(httpx.Options{Timeout: 10 * time.Second}),
+			baseURL:    fmt.Sprintf("https://%s.wikipedia.org/api/rest_v1", lang),
+		}
+	}
+	clients[0].fallbacks = clients[1:]
+	return clients[0]
+}
+
+func (c *Client) GetBirdSummary(ctx context.Context, birdName string) (*PageSummary, error) {
+	summary, err := c.getSummary(ctx, birdName)
+	for _, fallback := range c.fallbacks {
+		if err == nil {
+			break
+		}
+		summary, err = fallback.getSummary(ctx, birdName)
+	}
+	return summary, err
+}
+
+func (c *Client) getSummary(ctx context.Context, birdName string) (*PageSummary, error) {
 	encodedName := url.QueryEscape(strings.ReplaceAll(birdName, " ", "_"))
 
 	apiURL := fmt.Sprintf("%s/page/summary/%s", c.baseURL, encodedName)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -61,7 +118,7 @@ func (c *Client) GetBirdSummary(birdName string) (*PageSummary, error) {
 			encodedName = url.QueryEscape(strings.ReplaceAll(scientificNameParts[0]+" "+scientificNameParts[1], " ", "_"))
 			apiURL = fmt.Sprintf("%s/page/summary/%s", c.baseURL, encodedName)
 
-			req, err = http.NewRequest("GET", apiURL, nil)
+			req, err = http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create request: %w", err)
 			}
@@ -0,0 +1,171 @@
+package wikipedia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Page sections GetBirdSections extracts
+const (
+	SectionDescription  = "description"
+	SectionBehaviour    = "behaviour"
+	SectionDiet         = "diet"
+	SectionBreeding     = "breeding"
+	SectionVocalization = "vocalization"
+)
+
+// sectionHeadings are the headings bird pages use for each section, lowercased. Headings that
+// combine two topics ("Food and feeding") are listed under the more specific one.
+var sectionHeadings = map[string][]string{
+	SectionDescription:  {"description", "appearance", "identification", "plumage"},
+	SectionBehaviour:    {"behaviour", "behavior", "ecology", "behaviour and ecology", "ecology and behaviour", "behavior and ecology", "ecology and behavior"},
+	SectionDiet:         {"diet", "feeding", "food", "food and feeding", "diet and feeding", "feeding ecology", "foraging"},
+	SectionBreeding:     {"breeding", "nesting", "reproduction", "breeding and nesting", "nesting and breeding"},
+	SectionVocalization: {"vocalization", "vocalizations", "vocalisation", "vocalisations", "voice", "song", "songs", "calls", "song and calls", "songs and calls"},
+}
+
+// headingPattern matches the "== Heading ==" lines of a plain-text extract
+var headingPattern = regexp.MustCompile(`^(={2,6})\s*(.*?)\s*={2,6}$`)
+
+// PageSections is the plain text of the sections of a bird's page the fact generators use
+type PageSections struct {
+	Title    string            `json:"title"`
+	Sections map[string]string `json:"sections"` // Section constant -> text, for the sections the page has
+}
+
+// Section returns the text of a section, or "" when the page doesn't have it
+func (p *PageSections) Section(name string) string {
+	if p == nil {
+		return ""
+	}
+	return p.Sections[name]
+}
+
+// GetBirdSections fetches the bird's full page and extracts its description, behaviour, diet,
+// breeding, and vocalization sections. Subsections are included in the section they sit under
+// unless they are a section of their own, so "Diet" under "Behaviour" is filed as diet.
+func (c *Client) GetBirdSections(ctx context.Context, birdName string) (*PageSections, error) {
+	sections, err := c.getSections(ctx, birdName)
+	for _, fallback := range c.fallbacks {
+		if err == nil {
+			break
+		}
+		sections, err = fallback.getSections(ctx, birdName)
+	}
+	return sections, err
+}
+
+func (c *Client) getSections(ctx context.Context, birdName string) (*PageSections, error) {
+	query := url.Values{
+		"action":          {"query"},
+		"prop":            {"extracts"},
+		"explaintext":     {"1"},
+		"exsectionformat": {"wiki"},
+		"redirects":       {"1"},
+		"titles":          {birdName},
+		"format":          {"json"},
+		"formatversion":   {"2"},
+	}
+	apiURL := fmt.Sprintf("%s/w/api.php?%s", strings.TrimSuffix(c.baseURL, "/api/rest_v1"), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "BirdSongExplorer/1.0 (https://github.com/callen/bird-song-explorer)")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Wikipedia page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Wikipedia API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Query struct {
+			Pages []struct {
+				Title   string `json:"title"`
+				Extract string `json:"extract"`
+				Missing bool   `json:"missing"`
+			} `json:"pages"`
+		} `json:"query"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Wikipedia response: %w", err)
+	}
+	if len(result.Query.Pages) == 0 || result.Query.Pages[0].Missing {
+		return nil, fmt.Errorf("no Wikipedia page for %s", birdName)
+	}
+
+	page := result.Query.Pages[0]
+	return &PageSections{Title: page.Title, Sections: SplitSections(page.Extract)}, nil
+}
+
+// SplitSections files a plain-text extract's paragraphs under the sections their headings name.
+// Text under an unrecognised heading belongs to the nearest recognised heading above it at a
+// higher level, and is dropped when there is none (Taxonomy, Distribution, References, ...).
+func SplitSections(extract string) map[string]string {
+	type heading struct {
+		level   int
+		section string // "" when the heading isn't one of ours
+	}
+
+	var stack []heading
+	current := ""
+	texts := make(map[string][]string)
+
+	for _, line := range strings.Split(extract, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			level := len(match[1])
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, heading{level: level, section: sectionForHeading(match[2])})
+
+			current = ""
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].section != "" {
+					current = stack[i].section
+					break
+				}
+			}
+			continue
+		}
+
+		if current != "" {
+			texts[current] = append(texts[current], line)
+		}
+	}
+
+	sections := make(map[string]string, len(texts))
+	for section, paragraphs := range texts {
+		sections[section] = strings.Join(paragraphs, "\n")
+	}
+	return sections
+}
+
+// sectionForHeading returns the section a heading names, or ""
+func sectionForHeading(title string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	for section, headings := range sectionHeadings {
+		for _, h := range headings {
+			if title == h {
+				return section
+			}
+		}
+	}
+	return ""
+}
@@ -1,15 +1,24 @@
 package xenocanto
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
 )
 
 const baseURL = "https://xeno-canto.org/api/3"
 
+var requestDuration = metrics.NewHistogramVec("bird_explorer_xenocanto_request_duration_seconds",
+	"Latency of Xeno-canto recording searches, by result", nil, "result")
+
 type Client struct {
 	httpClient *http.Client
 	apiKey     string
@@ -47,12 +56,12 @@ type Recording struct {
 
 func NewClient(apiKey string) *Client {
 	return &Client{
-		httpClient: &http.Client{},
+		httpClient: httpx.NewClient(httpx.Options{Timeout: 30 * time.Second}),
 		apiKey:     apiKey,
 	}
 }
 
-func (c *Client) SearchRecordings(scientificName string, quality string) (*SearchResponse, error) {
+func (c *Client) SearchRecordings(ctx context.Context, scientificName string, quality string) (*SearchResponse, error) {
 	// Split scientific name into genus and species
 	parts := strings.Split(scientificName, " ")
 	if len(parts) < 2 {
@@ -74,17 +83,21 @@ func (c *Client) SearchRecordings(scientificName string, quality string) (*Searc
 
 	endpoint := fmt.Sprintf("%s/recordings?%s", baseURL, params.Encode())
 
-	fmt.Printf("Xeno-canto API request: %s\n", endpoint)
+	slog.Info("[XENO_CANTO] Searching recordings", "query", searchQuery)
 
-	resp, err := c.httpClient.Get(endpoint)
+	start := time.Now()
+	resp, err := httpx.Get(ctx, c.httpClient, endpoint)
 	if err != nil {
+		requestDuration.ObserveSince(start, "error")
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		requestDuration.ObserveSince(start, "error")
 		return nil, fmt.Errorf("Xeno-canto API error: %d", resp.StatusCode)
 	}
+	requestDuration.ObserveSince(start, "ok")
 
 	var result SearchResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -104,14 +117,20 @@ func (c *Client) SearchRecordings(scientificName string, quality string) (*Searc
 	return &result, nil
 }
 
-func (c *Client) GetBestRecording(scientificName string) (*Recording, error) {
-	searchResp, err := c.SearchRecordings(scientificName, "A")
+func (c *Client) GetBestRecording(ctx context.Context, scientificName string) (*Recording, error) {
+	return c.GetBestRecordingInRange(ctx, scientificName, 15, 60)
+}
+
+// GetBestRecordingInRange prefers songs and calls whose length is within [minSeconds, maxSeconds].
+// When none fit, the recording closest to the range is returned so it can be looped or trimmed.
+func (c *Client) GetBestRecordingInRange(ctx context.Context, scientificName string, minSeconds int, maxSeconds int) (*Recording, error) {
+	searchResp, err := c.SearchRecordings(ctx, scientificName, "A")
 	if err != nil {
 		return nil, err
 	}
 
 	if len(searchResp.Recordings) == 0 {
-		searchResp, err = c.SearchRecordings(scientificName, "")
+		searchResp, err = c.SearchRecordings(ctx, scientificName, "")
 		if err != nil {
 			return nil, err
 		}
@@ -121,19 +140,40 @@ func (c *Client) GetBestRecording(scientificName string) (*Recording, error) {
 		return nil, fmt.Errorf("no recordings found for %s", scientificName)
 	}
 
-	for _, rec := range searchResp.Recordings {
-		if rec.Type == "song" || rec.Type == "call" {
-			duration := c.parseDuration(rec.Length)
-			if duration >= 15 && duration <= 60 {
-				return &rec, nil
-			}
+	var closest *Recording
+	closestDistance := -1
+	for i, rec := range searchResp.Recordings {
+		if rec.Type != "song" && rec.Type != "call" {
+			continue
+		}
+
+		duration := c.parseDuration(rec.Length)
+		if duration >= minSeconds && duration <= maxSeconds {
+			return &searchResp.Recordings[i], nil
+		}
+
+		distance := minSeconds - duration
+		if duration > maxSeconds {
+			distance = duration - maxSeconds
+		}
+		if duration > 0 && (closestDistance < 0 || distance < closestDistance) {
+			closest = &searchResp.Recordings[i]
+			closestDistance = distance
 		}
 	}
 
+	if closest != nil {
+		return closest, nil
+	}
 	return &searchResp.Recordings[0], nil
 }
 
 func (c *Client) parseDuration(length string) int {
+	return ParseLength(length)
+}
+
+// ParseLength converts a xeno-canto "m:ss" length to seconds
+func ParseLength(length string) int {
 	parts := strings.Split(length, ":")
 	if len(parts) != 2 {
 		return 0
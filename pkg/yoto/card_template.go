@@ -0,0 +1,105 @@
+package yoto
+
+// Card segments, named after the streaming track each one plays
+const (
+	SegmentIntro        = "intro"
+	SegmentAnnouncement = "announcement"
+	SegmentPrimer       = "primer"
+	SegmentDescription  = "description"
+	SegmentQuiz         = "quiz"
+	SegmentHotspots     = "hotspots"
+	SegmentBirdHero     = "bird_hero"
+	SegmentWeeklyFact   = "weekly_fact"
+	SegmentListenCount  = "listen_count"
+	SegmentOutro        = "outro"
+)
+
+// CardTemplate is the ordered list of segments a card plays, one chapter each. Stitched templates
+// play the segments back to back as one gapless track in a single chapter.
+type CardTemplate struct {
+	Segments []string `json:"segments"`
+	Stitched bool     `json:"stitched,omitempty"`
+}
+
+// DefaultCardTemplate is the standard layout: intro, announcement, the optional family primer,
+// the guide, the optional quiz and hotspot chapters, then the outro
+func DefaultCardTemplate(includePrimer, includeQuiz, includeHotspots bool) CardTemplate {
+	segments := []string{SegmentIntro, SegmentAnnouncement}
+	if includePrimer {
+		segments = append(segments, SegmentPrimer)
+	}
+	segments = append(segments, SegmentDescription)
+	if includeQuiz {
+		segments = append(segments, SegmentQuiz)
+	}
+	if includeHotspots {
+		segments = append(segments, SegmentHotspots)
+	}
+	segments = append(segments, SegmentOutro)
+	return CardTemplate{Segments: segments}
+}
+
+// Has reports whether the template includes the segment
+func (t CardTemplate) Has(segment string) bool {
+	for _, s := range t.Segments {
+		if s == segment {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCardTemplate replaces the default chapter layout, and with it the primer, quiz, and hotspot
+// settings. Templates with unknown or repeated segments are rejected.
+func (cm *ContentManager) SetCardTemplate(template CardTemplate) error {
+	if err := cm.assembler.Validate(template); err != nil {
+		return err
+	}
+	cm.cardTemplate = &template
+	return nil
+}
+
+// RegisterChapterBuilder adds a segment card templates can use, or replaces how one is built
+func (cm *ContentManager) RegisterChapterBuilder(segment string, builder ChapterBuilder) {
+	cm.assembler.Register(segment, builder)
+}
+
+// Segments returns the card's chapter segments in play order, as the next update would publish them
+func (cm *ContentManager) Segments() []string {
+	return append([]string(nil), cm.template().Segments...)
+}
+
+// template returns the card's chapter layout. The standard layout gets the weekly fact chapter
+// after the guide in bird-of-the-week mode, then the listen-and-count activity when enabled, and
+// the bird hero chapter before the outro when the bird is threatened; custom templates place them
+// themselves.
+func (cm *ContentManager) template() CardTemplate {
+	if cm.cardTemplate != nil {
+		template := *cm.cardTemplate
+		template.Stitched = template.Stitched || cm.singleTrack
+		return template
+	}
+	template := DefaultCardTemplate(cm.includePrimer, cm.includeQuiz, cm.includeHotspots)
+	template.Stitched = cm.singleTrack
+	if cm.weeklyFactTitle != "" || cm.includeListenCount {
+		segments := make([]string, 0, len(template.Segments)+2)
+		for _, segment := range template.Segments {
+			segments = append(segments, segment)
+			if segment != SegmentDescription {
+				continue
+			}
+			if cm.weeklyFactTitle != "" {
+				segments = append(segments, SegmentWeeklyFact)
+			}
+			if cm.includeListenCount {
+				segments = append(segments, SegmentListenCount)
+			}
+		}
+		template.Segments = segments
+	}
+	if cm.conservationStatus != "" {
+		outro := len(template.Segments) - 1
+		template.Segments = append(template.Segments[:outro], SegmentBirdHero, SegmentOutro)
+	}
+	return template
+}
@@ -0,0 +1,201 @@
+package yoto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// cardVerifyDelay gives the Yoto API a moment to settle before the card is read back, doubling
+// before each retry of a read-back that failed
+var cardVerifyDelay = 2 * time.Second
+
+// cardReadBackAttempts is how many times a card is read back before it's left unverified
+const cardReadBackAttempts = 3
+
+// CardVerificationError reports a card update that did not read back as sent.
+// Reverted is true when the previous content was restored.
+type CardVerificationError struct {
+	CardID   string
+	Mismatch string
+	Reverted bool
+}
+
+func (e *CardVerificationError) Error() string {
+	if e.Reverted {
+		return fmt.Sprintf("card %s failed verification (%s), reverted to previous content", e.CardID, e.Mismatch)
+	}
+	return fmt.Sprintf("card %s failed verification (%s) and could not be reverted", e.CardID, e.Mismatch)
+}
+
+// publishVerified posts card content, reads it back, and checks it matches what was sent.
+// A failed check is re-sent once; if it still fails the previous card content is restored
+// so players never keep a half-built card. A card that can't be read back is left as posted
+// and logged as unverified, since nothing says the update went wrong.
+func (cm *ContentManager) publishVerified(cardID string, content map[string]interface{}, chapters []StreamingChapter, previous *Card) error {
+	var mismatch string
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := cm.postContent(cardID, content); err != nil {
+			return err
+		}
+
+		var err error
+		mismatch, err = cm.readBackCard(cardID, chapters)
+		if err != nil {
+			if cm.ctx.Err() != nil {
+				return cm.ctx.Err()
+			}
+			slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Card published but unverified: read-back failed", "card_id", cardID, "error", err)
+			cm.recordPublished(content, chapters)
+			return nil
+		}
+		if mismatch == "" {
+			cm.recordPublished(content, chapters)
+			return nil
+		}
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Card failed verification", "card_id", cardID, "attempt", attempt, "mismatch", mismatch)
+	}
+
+	verifyErr := &CardVerificationError{CardID: cardID, Mismatch: mismatch}
+	if previous == nil || previous.Content == nil {
+		slog.ErrorContext(cm.ctx, "[STREAMING_UPDATE] No previous content, cannot revert", "card_id", cardID)
+		return verifyErr
+	}
+
+	previousContent := make(map[string]interface{}, len(previous.Content)+2)
+	for key, value := range previous.Content {
+		previousContent[key] = value
+	}
+	previousContent["title"] = previous.Title
+	if previous.Metadata != nil {
+		previousContent["metadata"] = previous.Metadata
+	}
+
+	if err := cm.postContent(cardID, previousContent); err != nil {
+		slog.ErrorContext(cm.ctx, "[STREAMING_UPDATE] Failed to revert card", "card_id", cardID, "error", err)
+		return verifyErr
+	}
+
+	verifyErr.Reverted = true
+	slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Reverted card to its previous content", "card_id", cardID)
+	return verifyErr
+}
+
+// recordPublished keeps the chapters and a hash of the content the card was verified with
+func (cm *ContentManager) recordPublished(content map[string]interface{}, chapters []StreamingChapter) {
+	cm.published = chapters
+	cm.publishedHash = ""
+	if data, err := json.Marshal(content); err == nil {
+		sum := sha256.Sum256(data)
+		cm.publishedHash = hex.EncodeToString(sum[:])
+	}
+}
+
+// PublishedContent returns the chapters this manager last published to a card and the SHA-256 of
+// the content posted, or nil and "" when it hasn't published any, such as when a resumed update
+// finds its content already posted
+func (cm *ContentManager) PublishedContent() ([]StreamingChapter, string) {
+	return cm.published, cm.publishedHash
+}
+
+// postContent sends card content to the Yoto content endpoint
+func (cm *ContentManager) postContent(cardID string, content map[string]interface{}) error {
+	contentReq := map[string]interface{}{
+		"cardId":  cardID,
+		"content": content,
+	}
+
+	jsonData, err := json.Marshal(contentReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/content", cm.client.baseURL)
+	req, err := http.NewRequestWithContext(cm.ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cm.client.bearerToken()))
+
+	resp, err := cm.client.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update card: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return apiErrorWithBody("failed to update card content", resp, body).forCard()
+	}
+	return nil
+}
+
+// readBackCard waits for the Yoto API to settle and verifies the card's chapters, backing off and
+// trying again when the card can't be read. It returns the error of the last read when every
+// attempt failed, or the context's error when it is done first.
+func (cm *ContentManager) readBackCard(cardID string, expected []StreamingChapter) (string, error) {
+	delay := cardVerifyDelay
+	var err error
+	for attempt := 1; attempt <= cardReadBackAttempts; attempt++ {
+		select {
+		case <-cm.ctx.Done():
+			return "", cm.ctx.Err()
+		case <-time.After(delay):
+		}
+
+		var card *Card
+		if card, err = cm.client.GetCard(cm.ctx, cardID); err == nil {
+			return verifyCardChapters(card, expected), nil
+		}
+		slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Card read-back failed", "card_id", cardID, "attempt", attempt, "error", err)
+		delay *= 2
+	}
+	return "", err
+}
+
+// verifyCardChapters compares a card's chapter count, titles, and track durations with what was
+// sent. It returns a description of the first mismatch, or "" if the card matches.
+func verifyCardChapters(card *Card, expected []StreamingChapter) string {
+
+	raw, err := json.Marshal(card.Content)
+	if err != nil {
+		return fmt.Sprintf("unreadable content: %v", err)
+	}
+	var actual StreamingContent
+	if err := json.Unmarshal(raw, &actual); err != nil {
+		return fmt.Sprintf("unreadable chapters: %v", err)
+	}
+
+	if len(actual.Chapters) != len(expected) {
+		return fmt.Sprintf("expected %d chapters, found %d", len(expected), len(actual.Chapters))
+	}
+
+	for i, want := range expected {
+		got := actual.Chapters[i]
+		if got.Title != want.Title {
+			return fmt.Sprintf("chapter %d title %q, expected %q", i+1, got.Title, want.Title)
+		}
+		if len(got.Tracks) != len(want.Tracks) {
+			return fmt.Sprintf("chapter %d has %d tracks, expected %d", i+1, len(got.Tracks), len(want.Tracks))
+		}
+		for j, track := range want.Tracks {
+			if got.Tracks[j].Title != track.Title {
+				return fmt.Sprintf("chapter %d track %d title %q, expected %q", i+1, j+1, got.Tracks[j].Title, track.Title)
+			}
+			if got.Tracks[j].Duration != track.Duration {
+				return fmt.Sprintf("chapter %d track %d duration %ds, expected %ds", i+1, j+1, got.Tracks[j].Duration, track.Duration)
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,28 @@
+package yoto
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SequenceChapters numbers a card's chapters in their final order: keys "01", "02", ... and the
+// matching overlay labels "1", "2", ..., with each chapter's tracks keyed from "01" and labelled
+// like their chapter. Chapters are built unnumbered and sequenced once the list is complete, so
+// leaving a segment out or swapping one in can't leave a gap or a repeated key. Playback options
+// are keyed by chapter key, so chapters are sequenced before they're applied.
+func SequenceChapters(chapters []StreamingChapter) {
+	for i := range chapters {
+		label := strconv.Itoa(i + 1)
+		chapters[i].Key = chapterKey(i)
+		chapters[i].OverlayLabel = label
+		for j := range chapters[i].Tracks {
+			chapters[i].Tracks[j].Key = chapterKey(j)
+			chapters[i].Tracks[j].OverlayLabel = label
+		}
+	}
+}
+
+// chapterKey is the key for the chapter or track at a 0-based position
+func chapterKey(index int) string {
+	return fmt.Sprintf("%02d", index+1)
+}
@@ -0,0 +1,109 @@
+package yoto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"time"
+)
+
+// ErrChapterNotFound is returned by UpdateStreamingChapter when the card has no chapter streaming
+// the segment, so the caller can publish the whole card instead
+var ErrChapterNotFound = errors.New("card has no chapter for segment")
+
+// UpdateStreamingChapter replaces the chapter streaming one segment with a freshly built one,
+// leaving the card's other chapters, icons, title, and cover as they are. Bird-of-the-week cards
+// use it to swap in each day's fact chapter without republishing the week's content.
+func (cm *ContentManager) UpdateStreamingChapter(cardID string, segment string, baseURL string, sessionID string) error {
+	if _, posted := cm.checkpoint(StepContentPosted); posted {
+		slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Chapter already posted by an earlier attempt", "card_id", cardID, "segment", segment)
+		return nil
+	}
+
+	builder, known := cm.assembler.builders[segment]
+	if !known {
+		return fmt.Errorf("unknown card segment %q", segment)
+	}
+
+	if err := cm.client.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	existingCard, err := cm.client.GetCard(cm.ctx, cardID)
+	if err != nil {
+		return fmt.Errorf("failed to get card: %w", err)
+	}
+	chapters, err := streamingChapters(existingCard)
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i, chapter := range chapters {
+		if len(chapter.Tracks) > 0 && trackSegment(chapter.Tracks[0].TrackURL) == segment {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%w %s on card %s", ErrChapterNotFound, segment, cardID)
+	}
+
+	if sessionID == "" {
+		sessionID = fmt.Sprintf("%s_%d", cardID, time.Now().Unix())
+	}
+
+	// The chapter keeps the icons the week's full update uploaded
+	track := builder.BuildTrack(TrackIcons{}, cm.streamURL(baseURL, cardID, segment, sessionID))
+	track.TrackIcon = chapters[index].Tracks[0].Display.Icon16x16
+	track.ChapterIcon = chapters[index].Display.Icon16x16
+	chapters[index] = track.Chapter()
+	SequenceChapters(chapters)
+
+	cm.titleFormatter.FormatStreamingChapters(chapters[index : index+1])
+	cm.playbackOptions.ApplyToStreamingChapters(chapters[index : index+1])
+
+	content := make(map[string]interface{}, len(existingCard.Content)+2)
+	for key, value := range existingCard.Content {
+		content[key] = value
+	}
+	content["title"] = existingCard.Title
+	content["chapters"] = chapters
+	if existingCard.Metadata != nil {
+		content["metadata"] = existingCard.Metadata
+	}
+
+	if err := cm.publishVerified(cardID, content, chapters, existingCard); err != nil {
+		return err
+	}
+	cm.saveCheckpoint(StepContentPosted, time.Now().UTC().Format(time.RFC3339))
+
+	slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Chapter updated", "card_id", cardID, "segment", segment, "title", track.Title, "session", sessionID)
+	return nil
+}
+
+// streamingChapters decodes a card's chapters
+func streamingChapters(card *Card) ([]StreamingChapter, error) {
+	raw, err := json.Marshal(card.Content)
+	if err != nil {
+		return nil, fmt.Errorf("unreadable card content: %w", err)
+	}
+	var content StreamingContent
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return nil, fmt.Errorf("unreadable card chapters: %w", err)
+	}
+	return content.Chapters, nil
+}
+
+// trackSegment returns the segment a streaming track URL plays, the last element of its path for
+// both the session and card-scoped endpoints
+func trackSegment(trackURL string) string {
+	parsed, err := url.Parse(trackURL)
+	if err != nil {
+		return ""
+	}
+	return path.Base(parsed.Path)
+}
@@ -0,0 +1,52 @@
+package yoto
+
+import "log/slog"
+
+// Checkpointer stores the results of finished update steps, so a retried update resumes where
+// the failed attempt stopped instead of repeating its uploads
+type Checkpointer interface {
+	Checkpoint(step string) (string, bool)
+	SaveCheckpoint(step string, value string) error
+}
+
+// Steps UpdateCardWithStreamingTracks records
+const (
+	StepIconPrefix    = "icon:"          // followed by the icon name; the value is the uploaded media ID
+	StepCoverImage    = "cover_image"    // the value is the uploaded cover image URL
+	StepContentPosted = "content_posted" // the card content was posted and verified
+)
+
+// Steps a card job runs alongside the update, which it records when an attempt fails so the
+// retry doesn't pay for them again
+const (
+	StepSongFetched = "song_fetched" // the bird's recording was downloaded and cached
+	StepTTSRendered = "tts_rendered" // the job's narration was rendered and uploaded
+)
+
+// StepTranscodePrefix, followed by the track title, records the upload ID of audio CreateBirdPlaylist
+// or a stitched card update left transcoding in async mode
+const StepTranscodePrefix = "transcode:"
+
+// SetCheckpointer makes card updates record and resume from step checkpoints
+func (cm *ContentManager) SetCheckpointer(checkpointer Checkpointer) {
+	cm.checkpointer = checkpointer
+}
+
+// checkpoint returns a step's recorded result
+func (cm *ContentManager) checkpoint(step string) (string, bool) {
+	if cm.checkpointer == nil {
+		return "", false
+	}
+	return cm.checkpointer.Checkpoint(step)
+}
+
+// saveCheckpoint records a finished step. A failure to record only costs a repeated step on
+// retry, so it is logged rather than failing the update.
+func (cm *ContentManager) saveCheckpoint(step string, value string) {
+	if cm.checkpointer == nil {
+		return
+	}
+	if err := cm.checkpointer.SaveCheckpoint(step, value); err != nil {
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Failed to save checkpoint", "step", step, "error", err)
+	}
+}
@@ -2,22 +2,25 @@ package yoto
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/callen/bird-song-explorer/pkg/gcp"
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
 const (
-	defaultAuthURL = "https://login.yotoplay.com/oauth/token"
+	defaultAuthURL      = "https://login.yotoplay.com/oauth/token"
+	defaultYotoiconsURL = "https://www.yotoicons.com"
 )
 
 type Client struct {
@@ -25,10 +28,26 @@ type Client struct {
 	clientSecret string
 	baseURL      string
 	authURL      string
+	yotoiconsURL string // Community icon site searched for bird icons
 	httpClient   *http.Client
-	accessToken  string
-	refreshToken string
-	tokenExpiry  time.Time
+
+	// mu guards the token state below. It is held for a whole refresh, so concurrent callers wait
+	// for one rotation instead of each spending the refresh token.
+	mu             sync.Mutex
+	accessToken    string
+	refreshToken   string
+	tokenExpiry    time.Time
+	tokenStore     TokenStore // Optional; rotated tokens are saved here after every refresh
+	secretsProject string     // Optional; GCP project rotated tokens are saved to without a store
+	authErr        error      // Most recent authentication failure, cleared by the next success
+}
+
+// AuthStatus describes the client's Yoto credentials for health checks
+type AuthStatus struct {
+	HasAccessToken  bool      `json:"has_access_token"`
+	HasRefreshToken bool      `json:"has_refresh_token"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	LastError       string    `json:"last_error,omitempty"`
 }
 
 type TokenResponse struct {
@@ -68,20 +87,18 @@ type UpdateCardRequest struct {
 
 func NewClient(clientID, clientSecret, baseURL string) *Client {
 	// Create HTTP client that doesn't follow redirects automatically
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Log redirects but don't follow them automatically
-			fmt.Printf("REDIRECT: From %s to %s\n", via[len(via)-1].URL, req.URL)
-			if len(via) >= 10 {
-				return fmt.Errorf("stopped after 10 redirects")
-			}
-			// Only follow redirects to trusted domains
-			if req.URL.Host != "api.yotoplay.com" && req.URL.Host != "login.yotoplay.com" {
-				return fmt.Errorf("refusing to redirect to untrusted host: %s", req.URL.Host)
-			}
-			return nil
-		},
+	httpClient := httpx.NewClient(httpx.Options{Timeout: 30 * time.Second})
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// Log redirects but don't follow them automatically
+		slog.InfoContext(req.Context(), "[YOTO] Redirect", "from", via[len(via)-1].URL.String(), "to", req.URL.String())
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		// Only follow redirects to trusted domains
+		if req.URL.Host != "api.yotoplay.com" && req.URL.Host != "login.yotoplay.com" {
+			return fmt.Errorf("refusing to redirect to untrusted host: %s", req.URL.Host)
+		}
+		return nil
 	}
 
 	return &Client{
@@ -89,17 +106,107 @@ func NewClient(clientID, clientSecret, baseURL string) *Client {
 		clientSecret: clientSecret,
 		baseURL:      baseURL,
 		authURL:      defaultAuthURL,
+		yotoiconsURL: defaultYotoiconsURL,
 		httpClient:   httpClient,
 	}
 }
 
 // SetTokens allows setting pre-obtained tokens (e.g., from OAuth flow)
 func (c *Client) SetTokens(accessToken, refreshToken string, expiresIn int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.accessToken = accessToken
 	c.refreshToken = refreshToken
 	c.tokenExpiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
 }
 
+// SetConfiguredTokens sets the tokens the deployment was configured with, expiring when the
+// access token's JWT claims say (24 hours from now when they don't). Tokens in a token store
+// replace them, since they may have been rotated since deploy.
+func (c *Client) SetConfiguredTokens(accessToken, refreshToken string) {
+	expiresIn := 24 * 60 * 60
+	if exp := extractTokenExpiry(accessToken); exp > 0 {
+		expiresIn = int(time.Until(time.Unix(exp, 0)).Seconds())
+	}
+	c.SetTokens(accessToken, refreshToken, expiresIn)
+}
+
+// SetSecretsProject saves rotated tokens as new versions of the yoto-access-token and
+// yoto-refresh-token secrets in the GCP project when no token store is set
+func (c *Client) SetSecretsProject(projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secretsProject = projectID
+}
+
+// SetAuthURL points token refreshes at another OAuth token endpoint, such as a fake Yoto API
+func (c *Client) SetAuthURL(authURL string) {
+	c.authURL = authURL
+}
+
+// SetYotoiconsURL points bird icon searches at another yotoicons.com, such as a fake one
+func (c *Client) SetYotoiconsURL(baseURL string) {
+	c.yotoiconsURL = baseURL
+}
+
+// SetTokenStore persists tokens through store and loads any tokens it already holds,
+// which take precedence over environment tokens since they may have been rotated since deploy
+func (c *Client) SetTokenStore(store TokenStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenStore = store
+	c.loadStoredTokens()
+}
+
+// loadStoredTokens replaces the client's tokens with the store's and reports whether any were found.
+// The caller holds c.mu.
+func (c *Client) loadStoredTokens() bool {
+	if c.tokenStore == nil {
+		return false
+	}
+
+	tokens, err := c.tokenStore.Load()
+	if err != nil {
+		log.Printf("[YOTO_CLIENT] Failed to load stored tokens: %v", err)
+		return false
+	}
+	if tokens == nil || tokens.AccessToken == "" {
+		return false
+	}
+
+	c.accessToken = tokens.AccessToken
+	c.refreshToken = tokens.RefreshToken
+	c.tokenExpiry = tokens.Expiry
+	if c.tokenExpiry.IsZero() {
+		c.tokenExpiry = time.Now().Add(24 * time.Hour)
+	}
+	return true
+}
+
+// PersistTokens saves the current tokens to the token store, or to Secret Manager (when a
+// secrets project is set) if no store is configured
+func (c *Client) PersistTokens() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.persistTokens()
+}
+
+// persistTokens is PersistTokens for callers already holding c.mu
+func (c *Client) persistTokens() error {
+	if c.tokenStore == nil {
+		if c.secretsProject == "" {
+			return nil
+		}
+		return gcp.UpdateYotoTokens(c.secretsProject, c.accessToken, c.refreshToken)
+	}
+
+	return c.tokenStore.Save(StoredTokens{
+		AccessToken:  c.accessToken,
+		RefreshToken: c.refreshToken,
+		Expiry:       c.tokenExpiry,
+	})
+}
+
 // extractTokenExpiry extracts the expiry time from a JWT token
 func extractTokenExpiry(token string) int64 {
 	parts := strings.Split(token, ".")
@@ -125,16 +232,9 @@ func extractTokenExpiry(token string) int64 {
 }
 
 func (c *Client) authenticate() error {
-	if accessToken := os.Getenv("YOTO_ACCESS_TOKEN"); accessToken != "" && c.accessToken == "" {
-		c.accessToken = accessToken
-		if refreshToken := os.Getenv("YOTO_REFRESH_TOKEN"); refreshToken != "" {
-			c.refreshToken = refreshToken
-		}
-		if exp := extractTokenExpiry(accessToken); exp > 0 {
-			c.tokenExpiry = time.Unix(exp, 0)
-		} else {
-			c.tokenExpiry = time.Now().Add(24 * time.Hour)
-		}
+	// Another instance may have rotated the tokens, so check the store before giving up
+	if c.accessToken == "" && c.loadStoredTokens() {
+		log.Printf("[YOTO_CLIENT] Loaded tokens from token store")
 	}
 
 	if c.refreshToken != "" && time.Now().After(c.tokenExpiry) {
@@ -145,7 +245,7 @@ func (c *Client) authenticate() error {
 		return nil
 	}
 
-	return fmt.Errorf("no authentication method available - set YOTO_ACCESS_TOKEN and YOTO_REFRESH_TOKEN environment variables")
+	return fmt.Errorf("%w: no authentication method available - set YOTO_ACCESS_TOKEN and YOTO_REFRESH_TOKEN environment variables", ErrUnauthorized)
 }
 
 func (c *Client) refreshAccessToken() error {
@@ -168,8 +268,7 @@ func (c *Client) refreshAccessToken() error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("token refresh failed: %d - %s", resp.StatusCode, string(body))
+		return newAuthError("token refresh failed", resp)
 	}
 
 	var tokenResp TokenResponse
@@ -183,16 +282,54 @@ func (c *Client) refreshAccessToken() error {
 	}
 	c.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
 
-	// Update tokens in Secret Manager if AUTO_UPDATE_SECRETS is enabled
-	if err := gcp.UpdateYotoTokens(c.accessToken, c.refreshToken); err != nil {
-		log.Printf("[YOTO_CLIENT] Warning: Failed to update tokens in Secret Manager: %v", err)
-		// Don't fail the refresh if Secret Manager update fails
+	// Persist the rotated refresh token so restarts and other instances don't reuse a stale one
+	if err := c.persistTokens(); err != nil {
+		log.Printf("[YOTO_CLIENT] Warning: Failed to persist refreshed tokens: %v", err)
+		// Don't fail the refresh if persistence fails
 	}
 
 	return nil
 }
 
+// ExpireAccessToken makes the next call refresh the access token, for when Yoto has rejected it
+// with ErrUnauthorized before its recorded expiry
+func (c *Client) ExpireAccessToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenExpiry = time.Time{}
+}
+
+// AuthStatus reports whether the client holds tokens and whether its last authentication failed
+func (c *Client) AuthStatus() AuthStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := AuthStatus{
+		HasAccessToken:  c.accessToken != "",
+		HasRefreshToken: c.refreshToken != "",
+		ExpiresAt:       c.tokenExpiry,
+	}
+	if c.authErr != nil {
+		status.LastError = c.authErr.Error()
+	}
+	return status
+}
+
+// ensureAuthenticated refreshes or loads tokens when needed, remembering the outcome for AuthStatus
 func (c *Client) ensureAuthenticated() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authErr = c.authenticateIfNeeded()
+	return c.authErr
+}
+
+// bearerToken returns the current access token for an Authorization header
+func (c *Client) bearerToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken
+}
+
+func (c *Client) authenticateIfNeeded() error {
 	if c.accessToken == "" {
 		return c.authenticate()
 	}
@@ -211,7 +348,7 @@ func (c *Client) ensureAuthenticated() error {
 	return nil
 }
 
-func (c *Client) GetCard(cardID string) (*Card, error) {
+func (c *Client) GetCard(ctx context.Context, cardID string) (*Card, error) {
 	if err := c.ensureAuthenticated(); err != nil {
 		return nil, err
 	}
@@ -219,12 +356,12 @@ func (c *Client) GetCard(cardID string) (*Card, error) {
 	// Use the /content/{contentId} endpoint to get card content
 	url := fmt.Sprintf("%s/content/%s", c.baseURL, cardID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Authorization", "Bearer "+c.bearerToken())
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -233,8 +370,7 @@ func (c *Client) GetCard(cardID string) (*Card, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get card: %d - %s", resp.StatusCode, string(body))
+		return nil, newCardError("failed to get card", resp)
 	}
 
 	var response struct {
@@ -247,7 +383,7 @@ func (c *Client) GetCard(cardID string) (*Card, error) {
 	return &response.Card, nil
 }
 
-func (c *Client) UpdateCard(cardID string, update UpdateCardRequest) (*Card, error) {
+func (c *Client) UpdateCard(ctx context.Context, cardID string, update UpdateCardRequest) (*Card, error) {
 	if err := c.ensureAuthenticated(); err != nil {
 		return nil, err
 	}
@@ -259,12 +395,12 @@ func (c *Client) UpdateCard(cardID string, update UpdateCardRequest) (*Card, err
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Authorization", "Bearer "+c.bearerToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
@@ -274,8 +410,7 @@ func (c *Client) UpdateCard(cardID string, update UpdateCardRequest) (*Card, err
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to update card: %d - %s", resp.StatusCode, string(body))
+		return nil, newCardError("failed to update card", resp)
 	}
 
 	var card Card
@@ -286,19 +421,19 @@ func (c *Client) UpdateCard(cardID string, update UpdateCardRequest) (*Card, err
 	return &card, nil
 }
 
-func (c *Client) SearchLibrary(query string) ([]LibraryItem, error) {
+func (c *Client) SearchLibrary(ctx context.Context, query string) ([]LibraryItem, error) {
 	if err := c.ensureAuthenticated(); err != nil {
 		return nil, err
 	}
 
 	url := fmt.Sprintf("%s/library/search?q=%s", c.baseURL, query)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Authorization", "Bearer "+c.bearerToken())
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -307,8 +442,7 @@ func (c *Client) SearchLibrary(query string) ([]LibraryItem, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("library search failed: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError("library search failed", resp)
 	}
 
 	var items []LibraryItem
@@ -352,19 +486,19 @@ type DeviceConfig struct {
 	} `json:"device"`
 }
 
-func (c *Client) GetDeviceConfig(deviceID string) (*DeviceConfig, error) {
+func (c *Client) GetDeviceConfig(ctx context.Context, deviceID string) (*DeviceConfig, error) {
 	if err := c.ensureAuthenticated(); err != nil {
 		return nil, err
 	}
 
 	url := fmt.Sprintf("%s/device-v2/%s/config", c.baseURL, deviceID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Authorization", "Bearer "+c.bearerToken())
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -373,8 +507,7 @@ func (c *Client) GetDeviceConfig(deviceID string) (*DeviceConfig, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get device config: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError("failed to get device config", resp)
 	}
 
 	var config DeviceConfig
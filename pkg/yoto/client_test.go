@@ -0,0 +1,67 @@
+package yoto_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/callen/bird-song-explorer/pkg/yoto/yototest"
+)
+
+func newFakeAPI(t *testing.T) *yototest.Server {
+	t.Helper()
+	server := yototest.NewServer()
+	t.Cleanup(server.Close)
+	// Keep learned icon mappings out of the working tree
+	yoto.ConfigureIconMappings(t.TempDir() + "/icon_mappings.json")
+
+	delay := *yoto.CardVerifyDelay
+	*yoto.CardVerifyDelay = time.Millisecond
+	t.Cleanup(func() { *yoto.CardVerifyDelay = delay })
+	return server
+}
+
+func TestExpiredTokensAreRefreshedBeforeTheCall(t *testing.T) {
+	server := newFakeAPI(t)
+	client := server.Client()
+	staleAccess, refresh := server.Tokens()
+	client.SetTokens(staleAccess, refresh, 0)
+
+	var device yoto.DeviceConfig
+	device.Device.DeviceID = "device1"
+	server.AddDevice(device)
+
+	if _, err := client.GetDeviceConfig(context.Background(), "device1"); err != nil {
+		t.Fatalf("device config after refresh: %v", err)
+	}
+	if refreshes := len(server.RequestsTo("POST", "/oauth/token")); refreshes != 1 {
+		t.Errorf("expected 1 token refresh, got %d", refreshes)
+	}
+
+	access, _ := server.Tokens()
+	if access == staleAccess {
+		t.Fatal("access token was not rotated")
+	}
+	for _, request := range server.RequestsTo("GET", "/device-v2/device1/config") {
+		if got := request.Header.Get("Authorization"); got != "Bearer "+access {
+			t.Errorf("device config sent %q, want the refreshed token", got)
+		}
+	}
+}
+
+func TestDeviceConfigIsReadBack(t *testing.T) {
+	server := newFakeAPI(t)
+	var device yoto.DeviceConfig
+	device.Device.DeviceID = "device1"
+	device.Device.Config.GeoTimezone = "Europe/London"
+	server.AddDevice(device)
+
+	config, err := server.Client().GetDeviceConfig(context.Background(), "device1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Device.Config.GeoTimezone != "Europe/London" {
+		t.Errorf("geoTimezone is %q, want Europe/London", config.Device.Config.GeoTimezone)
+	}
+}
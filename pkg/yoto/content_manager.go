@@ -2,12 +2,17 @@ package yoto
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math/rand"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/randx"
+	"golang.org/x/sync/errgroup"
 )
 
 type ContentManager struct {
@@ -20,8 +25,38 @@ type ContentManager struct {
 	lastDescriptionText  string // Store description text for transitions (see: 'previous_track')
 	selectedAmbience     string // Store which ambience was used in intro for continuity
 	ambienceData         []byte // Store ambience audio data for Track 2 and outro
+	playbackOptions      *PlaybackOptions
+	includePrimer        bool              // Insert the family primer chapter before the guide
+	includeQuiz          bool              // Insert the "Can you guess the bird?" chapter before the outro
+	includeHotspots      bool              // Insert the "Where can you see it?" chapter before the outro
+	conservationStatus   string            // IUCN code of a threatened bird, which gets the bird hero chapter before the outro
+	weeklyFactTitle      string            // Title of the bird-of-the-week fact chapter after the guide; "" leaves it out
+	includeListenCount   bool              // Insert the "Listen and count!" activity chapter after the guide
+	singleTrack          bool              // Stitch the template into one uploaded track instead of streaming chapters
+	stitcher             TrackStitcher     // Joins the segments' audio for single-track cards
+	assembler            *ContentAssembler // Builds the chapters for each template segment
+	cardTemplate         *CardTemplate     // Chapter layout replacing the default and the include options; nil uses the default
+	titleFormatter       *TitleFormatter
+	guideIconProvider    func(birdName string) string // Returns an animated GIF path for Track 3, or ""
+	birdIconProvider     BirdIconProvider             // Returns a generated icon path for species without an asset, or ""
+	coverImageURL        string                       // Image to use as the card cover; "" keeps the existing cover
+	themeIconPath        string                       // Seasonal theme icon for Track 1, used when the file exists
+	cardTitle            string                       // Playlist title shown on the card
+	listenerOptions      ListenerOptions              // Device preferences passed to the streaming endpoints
+	dynamicStreams       bool                         // Use the card-scoped streaming endpoints
+	streamingCard        bool                         // Publish a layout that doesn't change with the bird, and only when it differs
+	nightMode            bool                         // Ask the streaming endpoints for the calmer night variant
+	ctx                  context.Context              // Carries the request ID and cancels the update's API calls
+	checkpointer         Checkpointer                 // Records finished steps so a retried update can resume
+	progress             ProgressReporter             // Told as rendering and upload steps finish
+	rng                  *randx.Randomizer            // Picks icons; nil picks with a time seed
+	published            []StreamingChapter           // Chapters of the last content that passed verification
+	publishedHash        string                       // SHA-256 of that content, as posted
 }
 
+// BirdIconProvider returns the path of a generated icon for a bird, or "" when there is none
+type BirdIconProvider func(ctx context.Context, birdName string) string
+
 type CreateContentResponse struct {
 	CardID string `json:"cardId"` // The API returns cardId, not contentId
 	Status string `json:"status"`
@@ -44,17 +79,121 @@ var radioIconsManager = []string{
 }
 
 // getRandomRadioIconManager returns a random radio icon from the available options
-func getRandomRadioIconManager() string {
-	rand.Seed(time.Now().UnixNano())
-	return radioIconsManager[rand.Intn(len(radioIconsManager))]
+func getRandomRadioIconManager(rng *randx.Randomizer) string {
+	return randx.Pick(rng, radioIconsManager)
 }
 
 func NewContentManager(client *Client) *ContentManager {
 	return &ContentManager{
-		client:       client,
-		uploader:     NewAudioUploader(client),
-		iconUploader: NewIconUploader(client),
-		iconSearcher: NewIconSearcher(client),
+		client:         client,
+		uploader:       NewAudioUploader(client),
+		iconUploader:   NewIconUploader(client),
+		iconSearcher:   NewIconSearcher(client),
+		titleFormatter: NewTitleFormatter(EnglishVariantNone),
+		assembler:      NewContentAssembler(),
+		cardTitle:      "Bird Song Explorer",
+		ctx:            context.Background(),
+	}
+}
+
+// SetContext sets the context of the request driving this update, so log entries from the
+// update and its uploads carry the request's correlation ID and its deadline cancels their API calls
+func (cm *ContentManager) SetContext(ctx context.Context) {
+	cm.ctx = ctx
+	cm.uploader.ctx = ctx
+	cm.iconUploader.ctx = ctx
+	cm.iconSearcher.ctx = ctx
+}
+
+// SetRandomizer sets the source of the update's random choices, so the same card and day always
+// get the same icons
+func (cm *ContentManager) SetRandomizer(rng *randx.Randomizer) {
+	cm.rng = rng
+}
+
+// SetPlaybackOptions sets autoplay, resume, and ambient behavior for subsequent card updates
+func (cm *ContentManager) SetPlaybackOptions(options *PlaybackOptions) {
+	cm.playbackOptions = options
+}
+
+// SetIncludePrimer controls whether streaming cards get a family primer chapter before the guide
+func (cm *ContentManager) SetIncludePrimer(include bool) {
+	cm.includePrimer = include
+}
+
+// SetIncludeQuiz controls whether streaming cards get a bird quiz chapter before the outro
+func (cm *ContentManager) SetIncludeQuiz(include bool) {
+	cm.includeQuiz = include
+}
+
+// SetIncludeHotspots controls whether streaming cards get a "Where can you see it?" chapter before the outro
+func (cm *ContentManager) SetIncludeHotspots(include bool) {
+	cm.includeHotspots = include
+}
+
+// SetConservationStatus sets the IUCN status of a threatened bird (VU, EN, or CR), adding the
+// "Be a Bird Hero!" chapter before the outro; "" leaves it out
+func (cm *ContentManager) SetConservationStatus(status string) {
+	cm.conservationStatus = status
+}
+
+// SetWeeklyFact adds the bird-of-the-week fact chapter after the guide, titled with the day's
+// theme; "" leaves it out
+func (cm *ContentManager) SetWeeklyFact(title string) {
+	cm.weeklyFactTitle = title
+	if title != "" {
+		cm.assembler.Register(SegmentWeeklyFact, fixedChapter{title, 45, guideIcon, birdIcon})
+	}
+}
+
+// SetIncludeListenCount controls whether streaming cards get the "Listen and count!" activity
+// chapter after the guide
+func (cm *ContentManager) SetIncludeListenCount(include bool) {
+	cm.includeListenCount = include
+}
+
+// SetTitleFormatter replaces the formatter applied to chapter and track titles
+func (cm *ContentManager) SetTitleFormatter(formatter *TitleFormatter) {
+	cm.titleFormatter = formatter
+}
+
+// SetGuideIconProvider sets the source of animated Track 3 icons; the static bird icon is used when it returns ""
+func (cm *ContentManager) SetGuideIconProvider(provider func(birdName string) string) {
+	cm.guideIconProvider = provider
+}
+
+// SetBirdIconProvider sets the source of generated bird icons for species without an icon asset;
+// the generic bird icon is used when it returns ""
+func (cm *ContentManager) SetBirdIconProvider(provider BirdIconProvider) {
+	cm.birdIconProvider = provider
+}
+
+// SetThemeIcon swaps a seasonal theme's icon in for Track 1's binoculars
+func (cm *ContentManager) SetThemeIcon(iconPath string) {
+	cm.themeIconPath = iconPath
+}
+
+// SetAudioNormalizer sets the loudness normalization applied to every uploaded track
+func (cm *ContentManager) SetAudioNormalizer(normalizer func(audioData []byte) ([]byte, error)) {
+	cm.uploader.SetNormalizer(normalizer)
+}
+
+// SetTranscodeWait sets how often uploads poll Yoto for a finished transcode and how long they wait
+func (cm *ContentManager) SetTranscodeWait(pollInterval, maxWait time.Duration) {
+	cm.uploader.SetTranscodeWait(pollInterval, maxWait)
+}
+
+// SetAsyncTranscode makes CreateBirdPlaylist return ErrTranscodePending instead of blocking on slow
+// transcodes. With a checkpointer set, the retry picks up the pending uploads rather than
+// uploading again.
+func (cm *ContentManager) SetAsyncTranscode(async bool) {
+	cm.uploader.SetAsyncTranscode(async)
+}
+
+// SetCardTitle sets the playlist title for cards with their own profile
+func (cm *ContentManager) SetCardTitle(title string) {
+	if title != "" {
+		cm.cardTitle = title
 	}
 }
 
@@ -69,17 +208,30 @@ func (cm *ContentManager) CreateBirdPlaylist(birdName string, introURL string, b
 		return "", fmt.Errorf("authentication failed: %w", err)
 	}
 
-	introSha, introInfo, err := cm.uploader.UploadAudioFromURL(introURL, "Bird Song Explorer Intro")
-	if err != nil {
-		return "", fmt.Errorf("failed to upload intro: %w", err)
-	}
-
-	birdSongSha, birdInfo, err := cm.uploader.UploadAudioFromURL(birdSongURL, birdName+" Song")
-	if err != nil {
-		return "", fmt.Errorf("failed to upload bird song: %w", err)
+	// The intro and song upload and transcode independently, so they run side by side
+	var introSha, birdSongSha string
+	var introInfo, birdInfo *TranscodeResponse
+	var g errgroup.Group
+	g.Go(func() error {
+		var err error
+		if introSha, introInfo, err = cm.uploadTrackAudio(introURL, "Bird Song Explorer Intro"); err != nil {
+			return fmt.Errorf("failed to upload intro: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		if birdSongSha, birdInfo, err = cm.uploadTrackAudio(birdSongURL, birdName+" Song"); err != nil {
+			return fmt.Errorf("failed to upload bird song: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return "", err
 	}
+	cm.reportProgress(ProgressUploaded, "Uploaded intro and song")
 
-	radioIcon := getRandomRadioIconManager()
+	radioIcon := getRandomRadioIconManager(cm.rng)
 
 	tracks := []PlaylistTrack{
 		{
@@ -127,8 +279,10 @@ func (cm *ContentManager) CreateBirdPlaylist(birdName string, introURL string, b
 		},
 	}
 
+	cm.titleFormatter.FormatChapters(chapters)
+
 	content := PlaylistContent{
-		Title: "Bird Song Explorer - " + birdName,
+		Title: "Bird Song Explorer - " + cm.titleFormatter.Format(birdName),
 		Content: Content{
 			Chapters: chapters,
 		},
@@ -141,6 +295,11 @@ func (cm *ContentManager) CreateBirdPlaylist(birdName string, introURL string, b
 		},
 	}
 
+	if !cm.playbackOptions.IsZero() && cm.playbackOptions.Config != (ContentConfig{}) {
+		config := cm.playbackOptions.Config
+		content.Content.Config = &config
+	}
+
 	contentID, err := cm.createContent(content)
 	if err != nil {
 		return "", fmt.Errorf("failed to create playlist: %w", err)
@@ -149,6 +308,30 @@ func (cm *ContentManager) CreateBirdPlaylist(birdName string, introURL string, b
 	return contentID, nil
 }
 
+// uploadTrackAudio uploads a playlist track's audio. A transcode an earlier async attempt left
+// pending is checked on rather than uploaded again.
+func (cm *ContentManager) uploadTrackAudio(audioURL string, title string) (string, *TranscodeResponse, error) {
+	step := StepTranscodePrefix + title
+	if uploadID, pending := cm.checkpoint(step); pending {
+		if err := cm.client.ensureAuthenticated(); err != nil {
+			return "", nil, fmt.Errorf("authentication failed: %w", err)
+		}
+		transcodeInfo, err := cm.uploader.CheckTranscode(uploadID)
+		if err != nil {
+			return "", nil, fmt.Errorf("transcoding failed: %w", err)
+		}
+		return transcodeInfo.Transcode.TranscodedSha256, transcodeInfo, nil
+	}
+
+	sha, transcodeInfo, err := cm.uploader.UploadAudioFromURL(audioURL, title)
+	var pending *TranscodePendingError
+	if errors.As(err, &pending) {
+		slog.InfoContext(cm.ctx, "[CONTENT_MANAGER] Transcode still running, resuming later", "title", title, "upload_id", pending.UploadID)
+		cm.saveCheckpoint(step, pending.UploadID)
+	}
+	return sha, transcodeInfo, err
+}
+
 // UpdateCardContent updates a MYO card with new content
 func (cm *ContentManager) UpdateCardContent(cardID string, contentID string) error {
 	if err := cm.client.ensureAuthenticated(); err != nil {
@@ -166,12 +349,12 @@ func (cm *ContentManager) UpdateCardContent(cardID string, contentID string) err
 		return err
 	}
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(cm.ctx, "PUT", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+cm.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+cm.client.bearerToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := cm.client.httpClient.Do(req)
@@ -181,8 +364,7 @@ func (cm *ContentManager) UpdateCardContent(cardID string, contentID string) err
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to update card: %d - %s", resp.StatusCode, string(body))
+		return newCardError("failed to update card", resp)
 	}
 
 	return nil
@@ -196,12 +378,12 @@ func (cm *ContentManager) createContent(content PlaylistContent) (string, error)
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(cm.ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+cm.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+cm.client.bearerToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := cm.client.httpClient.Do(req)
@@ -211,8 +393,7 @@ func (cm *ContentManager) createContent(content PlaylistContent) (string, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to create content: %d - %s", resp.StatusCode, string(body))
+		return "", newAPIError("failed to create content", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -222,7 +403,7 @@ func (cm *ContentManager) createContent(content PlaylistContent) (string, error)
 
 	var result CreateContentResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Printf("Create content response: %s\n", string(body))
+		slog.ErrorContext(cm.ctx, "[CONTENT_MANAGER] Unreadable create content response", "body", string(body), "error", err)
 		return "", err
 	}
 
@@ -0,0 +1,133 @@
+package yoto_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+)
+
+// updateTestCard publishes a streaming update from the repository root, where card updates read
+// ./assets/icons
+func updateTestCard(t *testing.T, cm *yoto.ContentManager) {
+	t.Helper()
+	t.Chdir("../..")
+	if err := cm.UpdateCardWithStreamingTracks("card1", "Bald Eagle", "https://birds.example", "session1"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+}
+
+func TestStreamingUpdateReplacesTheCardByCardID(t *testing.T) {
+	server := newFakeAPI(t)
+	server.AddCard(yoto.Card{
+		CardID:   "card1",
+		Title:    "Bird Song Explorer",
+		Content:  map[string]interface{}{"chapters": []interface{}{}},
+		Metadata: map[string]interface{}{"cover": map[string]interface{}{"imageL": "https://covers.example/original.png"}},
+	})
+
+	updateTestCard(t, server.Client().NewContentManager())
+
+	posts := server.RequestsTo("POST", "/content")
+	if len(posts) == 0 {
+		t.Fatal("no content was posted")
+	}
+	var body map[string]interface{}
+	for _, post := range posts {
+		body = nil
+		if err := json.Unmarshal(post.Body, &body); err != nil {
+			t.Fatalf("content body isn't JSON: %v", err)
+		}
+		if body["cardId"] != "card1" {
+			t.Errorf("content posted with cardId %v, want card1", body["cardId"])
+		}
+		if _, ok := body["contentId"]; ok {
+			t.Error("content posted with a contentId")
+		}
+	}
+
+	card, _ := server.Card("card1")
+	if imageL := coverImageL(card); imageL != "https://covers.example/original.png" {
+		t.Errorf("cover was %q after the update, want it preserved", imageL)
+	}
+	content, _ := body["content"].(map[string]interface{})
+	posted, _ := content["chapters"].([]interface{})
+	chapters, _ := card.Content["chapters"].([]interface{})
+	if len(posted) == 0 || len(chapters) != len(posted) {
+		t.Errorf("card has %d chapters, want the %d posted", len(chapters), len(posted))
+	}
+}
+
+func TestChaptersAreNumberedInCardOrder(t *testing.T) {
+	server := newFakeAPI(t)
+	server.AddCard(yoto.Card{CardID: "card1", Title: "Bird Song Explorer"})
+
+	updateTestCard(t, server.Client().NewContentManager())
+
+	card, _ := server.Card("card1")
+	raw, err := json.Marshal(card.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content yoto.StreamingContent
+	if err := json.Unmarshal(raw, &content); err != nil {
+		t.Fatalf("card content isn't streaming chapters: %v", err)
+	}
+	if len(content.Chapters) == 0 {
+		t.Fatal("card has no chapters")
+	}
+	for i, chapter := range content.Chapters {
+		key, label := fmt.Sprintf("%02d", i+1), fmt.Sprintf("%d", i+1)
+		if chapter.Key != key || chapter.OverlayLabel != label {
+			t.Errorf("chapter %d has key %q and label %q, want %q and %q", i+1, chapter.Key, chapter.OverlayLabel, key, label)
+		}
+		for j, track := range chapter.Tracks {
+			if want := fmt.Sprintf("%02d", j+1); track.Key != want || track.OverlayLabel != label {
+				t.Errorf("chapter %s track %d has key %q and label %q, want %q and %q", key, j+1, track.Key, track.OverlayLabel, want, label)
+			}
+		}
+	}
+}
+
+func TestCoverImageLandsInMetadata(t *testing.T) {
+	server := newFakeAPI(t)
+	server.AddCard(yoto.Card{CardID: "card1", Title: "Bird Song Explorer"})
+
+	cm := server.Client().NewContentManager()
+	cm.SetCoverImage("https://photos.example/eagle.jpg")
+	updateTestCard(t, cm)
+
+	covers := server.Covers()
+	if len(covers) != 1 || covers[0] != "https://photos.example/eagle.jpg" {
+		t.Errorf("cover uploads were %v", covers)
+	}
+	card, _ := server.Card("card1")
+	if imageL := coverImageL(card); !strings.HasPrefix(imageL, server.URL+"/covers/") {
+		t.Errorf("cover is %q, want the uploaded cover", imageL)
+	}
+}
+
+func TestUploadsAreReportedAsProgress(t *testing.T) {
+	server := newFakeAPI(t)
+	server.AddCard(yoto.Card{CardID: "card1", Title: "Bird Song Explorer"})
+
+	var steps []string
+	cm := server.Client().NewContentManager()
+	cm.SetProgressReporter(func(step string, message string) {
+		steps = append(steps, step)
+	})
+	updateTestCard(t, cm)
+
+	if len(steps) != 1 || steps[0] != yoto.ProgressUploaded {
+		t.Errorf("reported steps %v, want [%s]", steps, yoto.ProgressUploaded)
+	}
+}
+
+// coverImageL returns the card's metadata.cover.imageL
+func coverImageL(card yoto.Card) string {
+	cover, _ := card.Metadata["cover"].(map[string]interface{})
+	imageL, _ := cover["imageL"].(string)
+	return imageL
+}
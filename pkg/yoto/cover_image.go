@@ -0,0 +1,87 @@
+package yoto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// CoverImageUploadResponse is Yoto's reply to a cover image upload
+type CoverImageUploadResponse struct {
+	CoverImage struct {
+		MediaID  string `json:"mediaId"`
+		MediaURL string `json:"mediaUrl"`
+	} `json:"coverImage"`
+}
+
+// SetCoverImage makes card updates replace the card's cover with the image at imageURL. When
+// the upload fails the card keeps its existing cover.
+func (cm *ContentManager) SetCoverImage(imageURL string) {
+	cm.coverImageURL = imageURL
+}
+
+// UploadCoverImageFromURL has Yoto fetch an image and convert it to a card cover, returning the
+// cover's URL for metadata.cover.imageL
+func (iu *IconUploader) UploadCoverImageFromURL(imageURL string) (string, error) {
+	if err := iu.client.ensureAuthenticated(); err != nil {
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	uploadURL := fmt.Sprintf("%s/media/coverImage/user/me/upload?autoconvert=true&coverType=default&imageUrl=%s",
+		iu.client.baseURL, url.QueryEscape(imageURL))
+
+	req, err := http.NewRequestWithContext(iu.ctx, "POST", uploadURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+iu.client.bearerToken())
+
+	resp, err := iu.client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload cover image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", apiErrorWithBody("cover upload failed", resp, body)
+	}
+
+	var uploadResp CoverImageUploadResponse
+	if err := json.Unmarshal(body, &uploadResp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w - body: %s", err, string(body))
+	}
+	if uploadResp.CoverImage.MediaURL == "" {
+		return "", fmt.Errorf("no cover URL in response: %s", string(body))
+	}
+
+	slog.InfoContext(iu.ctx, "[ICON_UPLOADER] Uploaded cover image", "source", imageURL, "media_id", uploadResp.CoverImage.MediaID)
+	return uploadResp.CoverImage.MediaURL, nil
+}
+
+// uploadCoverImage uploads the configured cover image, returning "" when there is none or the
+// upload failed
+func (cm *ContentManager) uploadCoverImage() string {
+	if cm.coverImageURL == "" {
+		return ""
+	}
+	if coverURL, ok := cm.checkpoint(StepCoverImage); ok {
+		return coverURL
+	}
+
+	coverURL, err := cm.iconUploader.UploadCoverImageFromURL(cm.coverImageURL)
+	if err != nil {
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Failed to upload cover image, keeping existing cover", "image", cm.coverImageURL, "error", err)
+		return ""
+	}
+
+	cm.saveCheckpoint(StepCoverImage, coverURL)
+	return coverURL
+}
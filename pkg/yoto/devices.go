@@ -0,0 +1,54 @@
+package yoto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Device is a player on the account, as listed by the Yoto API
+type Device struct {
+	DeviceID     string `json:"deviceId"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	DeviceType   string `json:"deviceType,omitempty"`
+	DeviceFamily string `json:"deviceFamily,omitempty"`
+	DeviceGroup  string `json:"deviceGroup,omitempty"`
+	Online       bool   `json:"online"`
+}
+
+// ListDevices returns every player registered to the account
+func (c *Client) ListDevices(ctx context.Context) ([]Device, error) {
+	if err := c.ensureAuthenticated(); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/device-v2/devices/mine", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.bearerToken())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("failed to list devices", resp)
+	}
+
+	var response struct {
+		Devices []Device `json:"devices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Devices, nil
+}
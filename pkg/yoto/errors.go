@@ -0,0 +1,121 @@
+package yoto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Kinds of Yoto failure, matched with errors.Is. Failed API calls return an *APIError wrapping
+// the kind its response status means, so callers can choose between re-authenticating, retrying
+// later, and giving up.
+var (
+	ErrUnauthorized     = errors.New("not authorized by Yoto")   // Tokens are missing, expired, or revoked
+	ErrCardNotFound     = errors.New("card not found")           // The card ID doesn't exist or isn't the account's
+	ErrTranscodeTimeout = errors.New("transcode timed out")      // Yoto didn't finish transcoding within the wait
+	ErrRateLimited      = errors.New("rate limited by Yoto")     // Retry after APIError.RetryAfter
+	ErrValidation       = errors.New("request rejected by Yoto") // The request itself is invalid; retrying won't help
+)
+
+// maxErrorBody keeps response bodies quoted in errors to a readable length
+const maxErrorBody = 512
+
+// APIError is a Yoto API call that didn't succeed, with the response body
+type APIError struct {
+	Op         string // What failed, e.g. "failed to get card"
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From a rate-limited response's Retry-After header
+	kind       error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %d - %s", e.Op, e.StatusCode, e.Body)
+}
+
+// Unwrap returns the kind of failure, or nil when the status doesn't map to one
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// Temporary reports whether the same request may succeed later: rate limits and server errors
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newAPIError reads a failed response into an APIError. A 404 has no kind, since it only means a
+// missing card on the card endpoints; those use newCardError.
+func newAPIError(op string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return apiErrorWithBody(op, resp, body)
+}
+
+// apiErrorWithBody is newAPIError for callers that have already read the response body
+func apiErrorWithBody(op string, resp *http.Response, body []byte) *APIError {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	apiErr := &APIError{Op: op, StatusCode: resp.StatusCode, Body: string(body)}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		apiErr.kind = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		apiErr.kind = ErrRateLimited
+		apiErr.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
+	case http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		apiErr.kind = ErrValidation
+	}
+	return apiErr
+}
+
+// newCardError is newAPIError for requests addressing a card, where a 404 means the card is missing
+func newCardError(op string, resp *http.Response) *APIError {
+	return newAPIError(op, resp).forCard()
+}
+
+// forCard marks a 404 as the card missing
+func (e *APIError) forCard() *APIError {
+	if e.StatusCode == http.StatusNotFound {
+		e.kind = ErrCardNotFound
+	}
+	return e
+}
+
+// newAuthError is newAPIError for the OAuth endpoints, where any client error means the
+// credentials were refused
+func newAuthError(op string, resp *http.Response) *APIError {
+	apiErr := newAPIError(op, resp)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		apiErr.kind = ErrUnauthorized
+	}
+	return apiErr
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// RetryAfter returns how long a rate-limited call asked to be left, or 0 when err doesn't say
+func RetryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,4 @@
+package yoto
+
+// CardVerifyDelay lets the fake API tests skip the settle time before a card is read back
+var CardVerifyDelay = &cardVerifyDelay
@@ -0,0 +1,169 @@
+package yoto
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+)
+
+// How long a species' yotoicons result is trusted before it's scraped again. Misses are
+// re-checked sooner, since new icons are uploaded to the site all the time.
+const (
+	iconMappingMaxAge = 30 * 24 * time.Hour
+	iconMissingMaxAge = 7 * 24 * time.Hour
+	iconRefreshBatch  = 20
+)
+
+// IconMapping records which yotoicons.com icon a species uses and the Yoto media ID it was
+// uploaded as
+type IconMapping struct {
+	Species     string    `json:"species"`
+	Query       string    `json:"query,omitempty"`        // Search term that found the icon
+	YotoiconsID string    `json:"yotoicons_id,omitempty"` // "" when yotoicons had no icon for the species
+	MediaID     string    `json:"media_id,omitempty"`     // "" until the icon is uploaded
+	Title       string    `json:"title,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// Found reports whether yotoicons had an icon for the species
+func (m IconMapping) Found() bool {
+	return m.YotoiconsID != ""
+}
+
+// stale reports whether the mapping should be scraped again
+func (m IconMapping) stale() bool {
+	if m.Found() {
+		return time.Since(m.CheckedAt) > iconMappingMaxAge
+	}
+	return time.Since(m.CheckedAt) > iconMissingMaxAge
+}
+
+// IconMappingStore persists species to icon mappings as JSON (data/icon_mappings.json by
+// default), so icons are scraped and uploaded once rather than daily
+type IconMappingStore struct {
+	mu       sync.Mutex
+	path     string
+	mappings map[string]IconMapping // normalized species name -> mapping
+}
+
+var (
+	sharedIconMappingsOnce sync.Once
+	sharedIconMappings     *IconMappingStore
+)
+
+// ConfigureIconMappings sets where the process-wide icon mapping store is kept. Call it at
+// startup, before any card is updated; later calls have no effect.
+func ConfigureIconMappings(path string) {
+	sharedIconMappingsOnce.Do(func() {
+		sharedIconMappings = NewIconMappingStore(path)
+	})
+}
+
+// SharedIconMappings returns the process-wide icon mapping store; content managers are created
+// per update, so they share one store
+func SharedIconMappings() *IconMappingStore {
+	ConfigureIconMappings("")
+	return sharedIconMappings
+}
+
+// NewIconMappingStore loads the mappings stored at path
+func NewIconMappingStore(path string) *IconMappingStore {
+	if path == "" {
+		path = "data/icon_mappings.json"
+	}
+
+	store := &IconMappingStore{
+		path:     path,
+		mappings: make(map[string]IconMapping),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[ICON_MAPPINGS] Failed to read %s: %v", path, err)
+		}
+		return store
+	}
+
+	var mappings []IconMapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		log.Printf("[ICON_MAPPINGS] Failed to parse %s: %v", path, err)
+		return store
+	}
+	for _, mapping := range mappings {
+		store.mappings[iconMappingKey(mapping.Species)] = mapping
+	}
+	return store
+}
+
+// Get returns the mapping for a species
+func (s *IconMappingStore) Get(species string) (IconMapping, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mapping, ok := s.mappings[iconMappingKey(species)]
+	return mapping, ok
+}
+
+// Put records a mapping and saves the store
+func (s *IconMappingStore) Put(mapping IconMapping) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mappings[iconMappingKey(mapping.Species)] = mapping
+	return s.save()
+}
+
+// Stale returns up to limit mappings due to be scraped again, oldest first
+func (s *IconMappingStore) Stale(limit int) []IconMapping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stale []IconMapping
+	for _, mapping := range s.mappings {
+		if mapping.stale() {
+			stale = append(stale, mapping)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].CheckedAt.Before(stale[j].CheckedAt)
+	})
+	if len(stale) > limit {
+		stale = stale[:limit]
+	}
+	return stale
+}
+
+// All returns every mapping
+func (s *IconMappingStore) All() []IconMapping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := make([]IconMapping, 0, len(s.mappings))
+	for _, mapping := range s.mappings {
+		all = append(all, mapping)
+	}
+	return all
+}
+
+// save writes the mappings atomically; the caller holds s.mu
+func (s *IconMappingStore) save() error {
+	all := make([]IconMapping, 0, len(s.mappings))
+	for _, mapping := range s.mappings {
+		all = append(all, mapping)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return iconMappingKey(all[i].Species) < iconMappingKey(all[j].Species)
+	})
+
+	return filex.AtomicWriteJSON(s.path, all)
+}
+
+func iconMappingKey(species string) string {
+	return strings.ToLower(strings.TrimSpace(species))
+}
@@ -2,23 +2,27 @@ package yoto
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
 )
 
 // IconSearcher handles searching for icons from various sources
 type IconSearcher struct {
 	client      *Client
-	cache       map[string]*IconSearchResult
-	cacheMu     sync.RWMutex
+	mappings    *IconMappingStore
 	rateLimiter *RateLimiter
+	ctx         context.Context // Carries the request ID and cancels searches and uploads
 }
 
 // IconSearchResult represents an icon found through search
@@ -47,96 +51,125 @@ type RateLimiter struct {
 	mu          sync.Mutex
 }
 
+// sharedYotoiconsRateLimiter spaces requests to yotoicons.com across every searcher in the process
+var sharedYotoiconsRateLimiter = &RateLimiter{minInterval: 1 * time.Second}
+
 func NewIconSearcher(client *Client) *IconSearcher {
 	return &IconSearcher{
-		client: client,
-		cache:  make(map[string]*IconSearchResult),
-		rateLimiter: &RateLimiter{
-			minInterval: 1 * time.Second,
-		},
+		client:      client,
+		mappings:    SharedIconMappings(),
+		rateLimiter: sharedYotoiconsRateLimiter,
+		ctx:         context.Background(),
 	}
 }
 
-// SearchBirdIcon searches for an icon matching the bird name
+// SearchBirdIcon returns the Yoto media ID of a yotoicons.com icon for the bird, or "" when the
+// site has none. Results are kept in the icon mapping store, so each species is scraped and
+// uploaded once and only re-checked when its mapping goes stale.
 func (is *IconSearcher) SearchBirdIcon(birdName string) (string, error) {
+	mapping, known := is.mappings.Get(birdName)
+	if !known || mapping.stale() {
+		found, err := is.lookupYotoicons(birdName)
+		if err != nil {
+			// Keep using what we knew rather than recording a miss the site didn't report
+			slog.WarnContext(is.ctx, "[ICON_SEARCH] yotoicons.com lookup failed", "bird", birdName, "error", err)
+			if !known {
+				return "", err
+			}
+		} else {
+			if known && found.YotoiconsID == mapping.YotoiconsID {
+				found.MediaID = mapping.MediaID
+			}
+			mapping = found
+			is.saveMapping(mapping)
+		}
+	}
 
-	// Check cache first
-	is.cacheMu.RLock()
-	if cached, exists := is.cache[birdName]; exists && time.Since(cached.CachedAt) < 24*time.Hour {
-		is.cacheMu.RUnlock()
-		return FormatIconID(cached.MediaID), nil
-	}
-	is.cacheMu.RUnlock()
-
-	// Generate variations first (more generic terms)
-	variations := is.generateBirdNameVariations(birdName)
-
-	// Try variations first (they're more likely to have icons)
-	for _, variation := range variations {
-		fmt.Printf("Searching yotoicons.com for variation: %s...\n", variation)
-		yotoiconsResult, err := is.searchYotoicons(variation)
-		if err == nil && yotoiconsResult != nil {
-			fmt.Printf("Found icon for %s on yotoicons.com\n", variation)
-			// Upload the icon from yotoicons.com to Yoto
-			mediaID, err := is.uploadYotoiconsIcon(yotoiconsResult)
-			if err != nil {
-				fmt.Printf("Failed to upload icon for %s: %v\n", variation, err)
-			} else if mediaID == "" {
-				fmt.Printf("Upload returned empty media ID for %s\n", variation)
-			} else {
-				result := &IconSearchResult{
-					MediaID:  mediaID,
-					Title:    yotoiconsResult.Title,
-					Source:   "yotoicons",
-					Author:   yotoiconsResult.Author,
-					CachedAt: time.Now(),
-				}
-
-				// Cache with original name
-				is.cacheMu.Lock()
-				is.cache[birdName] = result
-				is.cacheMu.Unlock()
+	if !mapping.Found() {
+		slog.InfoContext(is.ctx, "[ICON_SEARCH] No specific icon on yotoicons.com", "bird", birdName)
+		return "", nil
+	}
 
-				fmt.Printf("Successfully uploaded icon from yotoicons for %s (variation: %s): %s\n", birdName, variation, mediaID)
-				return FormatIconID(mediaID), nil
-			}
+	if mapping.MediaID == "" {
+		mediaID, err := is.uploadYotoiconsIcon(mapping)
+		if err != nil {
+			slog.WarnContext(is.ctx, "[ICON_SEARCH] Failed to upload icon", "bird", birdName, "yotoicons_id", mapping.YotoiconsID, "error", err)
+			return "", err
 		}
+		mapping.MediaID = mediaID
+		is.saveMapping(mapping)
+		slog.InfoContext(is.ctx, "[ICON_SEARCH] Uploaded icon from yotoicons.com", "bird", birdName, "query", mapping.Query, "media_id", mediaID)
 	}
 
-	// If variations didn't work, try the full bird name as a last resort
-	fmt.Printf("Searching yotoicons.com for full name: %s\n", birdName)
-	yotoiconsResult, err := is.searchYotoicons(birdName)
-	if err == nil && yotoiconsResult != nil {
-		fmt.Printf("Found icon for %s on yotoicons.com\n", birdName)
-		// Upload the icon from yotoicons.com to Yoto
-		mediaID, err := is.uploadYotoiconsIcon(yotoiconsResult)
+	return FormatIconID(mapping.MediaID), nil
+}
+
+// lookupYotoicons scrapes yotoicons.com for the bird, trying generic variations ("Jay" for
+// "Blue Jay") before the full name. A mapping without an icon ID means the site has no icon; an
+// error means the site couldn't be read and nothing should be recorded.
+func (is *IconSearcher) lookupYotoicons(birdName string) (IconMapping, error) {
+	// Variations first, they're more likely to have icons; the full name is the last resort
+	queries := append(is.generateBirdNameVariations(birdName), birdName)
+
+	var lastErr error
+	for _, query := range queries {
+		slog.InfoContext(is.ctx, "[ICON_SEARCH] Searching yotoicons.com", "query", query)
+		icon, err := is.searchYotoicons(query)
 		if err != nil {
-			fmt.Printf("Failed to upload icon for %s: %v\n", birdName, err)
-		} else if mediaID == "" {
-			fmt.Printf("Upload returned empty media ID for %s\n", birdName)
-		} else {
-			result := &IconSearchResult{
-				MediaID:  mediaID,
-				Title:    yotoiconsResult.Title,
-				Source:   "yotoicons",
-				Author:   yotoiconsResult.Author,
-				CachedAt: time.Now(),
+			if !errors.Is(err, errNoYotoicons) {
+				lastErr = err
 			}
+			continue
+		}
 
-			// Cache the result
-			is.cacheMu.Lock()
-			is.cache[birdName] = result
-			is.cacheMu.Unlock()
+		slog.InfoContext(is.ctx, "[ICON_SEARCH] Found icon on yotoicons.com", "bird", birdName, "query", query, "yotoicons_id", icon.ID)
+		return IconMapping{
+			Species:     birdName,
+			Query:       query,
+			YotoiconsID: icon.ID,
+			Title:       fmt.Sprintf("%s icon", query),
+			Author:      icon.Author,
+			CheckedAt:   time.Now(),
+		}, nil
+	}
+
+	if lastErr != nil {
+		return IconMapping{}, lastErr
+	}
+	return IconMapping{Species: birdName, CheckedAt: time.Now()}, nil
+}
 
-			fmt.Printf("Successfully uploaded icon for %s: %s\n", birdName, mediaID)
-			return FormatIconID(mediaID), nil
+func (is *IconSearcher) saveMapping(mapping IconMapping) {
+	if err := is.mappings.Put(mapping); err != nil {
+		slog.WarnContext(is.ctx, "[ICON_SEARCH] Failed to save icon mapping", "bird", mapping.Species, "error", err)
+	}
+}
+
+// RefreshStaleMappings re-scrapes a batch of mappings whose result has gone stale. When a
+// species' icon changes on the site, its upload is dropped so the new icon is uploaded on next use.
+func (is *IconSearcher) RefreshStaleMappings() {
+	for _, mapping := range is.mappings.Stale(iconRefreshBatch) {
+		found, err := is.lookupYotoicons(mapping.Species)
+		if err != nil {
+			slog.WarnContext(is.ctx, "[ICON_SEARCH] Refresh failed, keeping mapping", "bird", mapping.Species, "error", err)
+			continue
+		}
+		if found.YotoiconsID == mapping.YotoiconsID {
+			found.MediaID = mapping.MediaID
 		}
+		is.saveMapping(found)
 	}
+}
 
-	// We no longer search Yoto public icons to avoid generic "bird" matches
-	// Only use specific matches from yotoicons.com
-	fmt.Printf("No specific icon found for %s on yotoicons.com, will use meadowlark default\n", birdName)
-	return "", nil
+// StartRefresh refreshes stale icon mappings in the background every interval
+func (is *IconSearcher) StartRefresh(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			is.RefreshStaleMappings()
+		}
+	}()
 }
 
 // searchYotoPublicIcons searches Yoto's public icon library
@@ -149,12 +182,12 @@ func (is *IconSearcher) searchYotoPublicIcons(query string) (*IconSearchResult,
 	// Get public icons from Yoto
 	url := fmt.Sprintf("%s/media/displayIcons/user/yoto", is.client.baseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(is.ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+is.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+is.client.bearerToken())
 
 	resp, err := is.client.httpClient.Do(req)
 	if err != nil {
@@ -163,7 +196,7 @@ func (is *IconSearcher) searchYotoPublicIcons(query string) (*IconSearchResult,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get public icons: %d", resp.StatusCode)
+		return nil, newAPIError("failed to get public icons", resp)
 	}
 
 	var icons []YotoPublicIcon
@@ -217,14 +250,15 @@ func (is *IconSearcher) searchYotoPublicIcons(query string) (*IconSearchResult,
 	return nil, fmt.Errorf("no matching icon found")
 }
 
-// searchYotoicons searches yotoicons.com for matching icons
-func (is *IconSearcher) searchYotoicons(query string) (*IconSearchResult, error) {
+// searchYotoicons searches yotoicons.com and returns the first icon for the query.
+// errNoYotoicons means the site has no suitable icon.
+func (is *IconSearcher) searchYotoicons(query string) (*yotoiconsIcon, error) {
 	// Rate limiting
 	is.rateLimiter.Wait()
 
-	searchURL := fmt.Sprintf("https://www.yotoicons.com/icons?tag=%s", url.QueryEscape(query))
+	searchURL := fmt.Sprintf("%s/icons?tag=%s", is.client.yotoiconsURL, url.QueryEscape(query))
 
-	resp, err := http.Get(searchURL)
+	resp, err := httpx.Get(is.ctx, httpx.Default, searchURL)
 	if err != nil {
 		return nil, err
 	}
@@ -234,106 +268,77 @@ func (is *IconSearcher) searchYotoicons(query string) (*IconSearchResult, error)
 		return nil, fmt.Errorf("yotoicons search failed: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
 	if err != nil {
 		return nil, err
 	}
 
-	html := string(body)
-	lowerHTML := strings.ToLower(html)
-
-	// Parse HTML to find icon images
-	iconRegex := regexp.MustCompile(`<img[^>]+src=["']/static/uploads/(\d+)\.png["'][^>]*>`)
-	matches := iconRegex.FindAllStringSubmatch(html, 10)
-
-	// Check if we're on a "no results" page
-	if strings.Contains(html, "No icons found") || strings.Contains(html, "no results") {
-		fmt.Printf("No results found on yotoicons.com for: %s\n", query)
-		return nil, fmt.Errorf("no icons found on yotoicons")
+	page, err := parseYotoiconsPage(body)
+	if err != nil {
+		return nil, err
+	}
+	if page.noResults {
+		slog.InfoContext(is.ctx, "[ICON_SEARCH] No results on yotoicons.com", "query", query)
+		return nil, errNoYotoicons
 	}
 
-	if len(matches) > 0 {
-		// Check if we found the search term on the page
-		lowerQuery := strings.ToLower(query)
-		hasSearchTerm := strings.Contains(lowerHTML, lowerQuery)
-
-		// Accept the result if we found the search term
-		// We're being less strict now - if searching for "duck" finds a duck icon, that's good enough
-		if hasSearchTerm {
-			fmt.Printf("Found icon for %s on yotoicons.com\n", query)
-		} else {
-			// Log if we're getting results but not for our search term
-			fmt.Printf("Warning: Search for %s returned results but search term not found on page\n", query)
-		}
-
-		// Avoid truly generic results only when we have no bird association
-		if strings.Contains(lowerHTML, "generic") && !hasSearchTerm {
-			fmt.Printf("Found only generic icon for %s, skipping\n", query)
-			return nil, fmt.Errorf("only generic icon found")
+	icons := page.icons
+	if len(icons) == 0 {
+		// The search page changed shape; a plain scan of the raw page may still find icon images
+		icons = legacyYotoiconsIcons(body)
+		if len(icons) == 0 {
+			return nil, fmt.Errorf("%w: no icons or no-results notice for %q", errYotoiconsMarkup, query)
 		}
+		slog.WarnContext(is.ctx, "[ICON_SEARCH] yotoicons.com markup changed, using fallback parser", "query", query)
+	}
 
-		// Use the first match
-		iconID := matches[0][1]
-		iconURL := fmt.Sprintf("https://www.yotoicons.com/static/uploads/%s.png", iconID)
-
-		// Try to extract author
-		authorRegex := regexp.MustCompile(`@([a-zA-Z0-9_-]+)`)
-		authorMatch := authorRegex.FindStringSubmatch(html)
-		author := "unknown"
-		if len(authorMatch) > 1 {
-			author = authorMatch[1]
+	// We're being lenient - if searching for "duck" finds a duck icon, that's good enough. Truly
+	// generic results are only skipped when the search term appears nowhere on the page.
+	hasSearchTerm := strings.Contains(page.text, strings.ToLower(query))
+	if !hasSearchTerm {
+		if strings.Contains(page.text, "generic") {
+			slog.InfoContext(is.ctx, "[ICON_SEARCH] Found only a generic icon, skipping", "query", query)
+			return nil, errNoYotoicons
 		}
-
-		return &IconSearchResult{
-			MediaID:  iconID, // Temporary, will be replaced after upload
-			Title:    fmt.Sprintf("%s icon", query),
-			URL:      iconURL,
-			Source:   "yotoicons",
-			Author:   author,
-			CachedAt: time.Now(),
-		}, nil
+		slog.WarnContext(is.ctx, "[ICON_SEARCH] Search returned results but the search term is not on the page", "query", query)
 	}
 
-	return nil, fmt.Errorf("no icons found on yotoicons")
+	return &icons[0], nil
 }
 
-// uploadYotoiconsIcon downloads and uploads an icon from yotoicons.com
-func (is *IconSearcher) uploadYotoiconsIcon(icon *IconSearchResult) (string, error) {
+// uploadYotoiconsIcon downloads a mapping's icon from yotoicons.com and uploads it to Yoto
+func (is *IconSearcher) uploadYotoiconsIcon(mapping IconMapping) (string, error) {
+	iconURL := yotoiconsIconURL(is.client.yotoiconsURL, mapping.YotoiconsID)
+
 	// Download the icon
-	fmt.Printf("[ICON_SEARCH] Downloading icon from: %s\n", icon.URL)
-	resp, err := http.Get(icon.URL)
+	slog.InfoContext(is.ctx, "[ICON_SEARCH] Downloading icon", "url", iconURL)
+	resp, err := httpx.Get(is.ctx, httpx.Default, iconURL)
 	if err != nil {
-		fmt.Printf("[ICON_SEARCH] Failed to download icon: %v\n", err)
 		return "", fmt.Errorf("failed to download icon: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("[ICON_SEARCH] Download failed with status: %d\n", resp.StatusCode)
 		return "", fmt.Errorf("download failed with status: %d", resp.StatusCode)
 	}
 
 	iconData, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("[ICON_SEARCH] Failed to read icon data: %v\n", err)
 		return "", fmt.Errorf("failed to read icon data: %w", err)
 	}
 
-	fmt.Printf("[ICON_SEARCH] Downloaded icon data: %d bytes\n", len(iconData))
+	slog.InfoContext(is.ctx, "[ICON_SEARCH] Downloaded icon", "bytes", len(iconData))
 
 	// Upload directly to Yoto without saving to file
-	mediaID, err := is.uploadIconData(iconData, fmt.Sprintf("bird_%s", icon.Title))
+	mediaID, err := is.uploadIconData(iconData, fmt.Sprintf("bird_%s", mapping.Title))
 	if err != nil {
-		fmt.Printf("[ICON_SEARCH] Failed to upload icon to Yoto: %v\n", err)
 		return "", fmt.Errorf("failed to upload icon: %w", err)
 	}
 
 	if mediaID == "" {
-		fmt.Printf("[ICON_SEARCH] Upload succeeded but returned empty media ID\n")
 		return "", fmt.Errorf("upload returned empty media ID")
 	}
 
-	fmt.Printf("[ICON_SEARCH] Successfully uploaded icon, media ID: %s\n", mediaID)
 	return mediaID, nil
 }
 
@@ -442,15 +447,15 @@ func (is *IconSearcher) uploadIconData(iconData []byte, filename string) (string
 	url := fmt.Sprintf("%s/media/displayIcons/user/me/upload?autoConvert=true&filename=%s",
 		is.client.baseURL, url.QueryEscape(filename))
 
-	fmt.Printf("[ICON_SEARCH] Uploading to: %s\n", url)
+	slog.DebugContext(is.ctx, "[ICON_SEARCH] Uploading icon", "url", url)
 
 	// Create request with raw image data (as done in yoto-myo-magic)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(iconData))
+	req, err := http.NewRequestWithContext(is.ctx, "POST", url, bytes.NewReader(iconData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+is.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+is.client.bearerToken())
 	req.Header.Set("Content-Type", "image/png")
 
 	// Send request
@@ -465,11 +470,10 @@ func (is *IconSearcher) uploadIconData(iconData []byte, filename string) (string
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	fmt.Printf("[ICON_SEARCH] Upload response status: %d\n", resp.StatusCode)
-	fmt.Printf("[ICON_SEARCH] Upload response body: %s\n", string(body))
+	slog.DebugContext(is.ctx, "[ICON_SEARCH] Upload response", "status", resp.StatusCode, "body", string(body))
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("upload failed: %d - %s", resp.StatusCode, string(body))
+		return "", apiErrorWithBody("upload failed", resp, body)
 	}
 
 	// Parse response
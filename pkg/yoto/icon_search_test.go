@@ -0,0 +1,28 @@
+package yoto_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+	"github.com/callen/bird-song-explorer/pkg/yoto/yototest"
+)
+
+func TestBirdIconsAreFoundOnYotoicons(t *testing.T) {
+	server := newFakeAPI(t)
+	png := []byte("\x89PNG\r\n\x1a\nfake")
+	server.AddYotoicon(yototest.Yotoicon{ID: "1234", Tag: "eagle", Author: "tester", PNG: png})
+
+	icon, err := yoto.NewIconSearcher(server.Client()).SearchBirdIcon("Bald Eagle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	icons := server.Icons()
+	if len(icons) != 1 || !bytes.Equal(icons[0].Data, png) {
+		t.Fatalf("expected the yotoicons image to be uploaded once, got %d uploads", len(icons))
+	}
+	if want := "yoto:#" + icons[0].MediaID; icon != want {
+		t.Errorf("icon is %q, want %q", icon, want)
+	}
+}
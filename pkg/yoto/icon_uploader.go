@@ -2,9 +2,11 @@ package yoto
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
@@ -16,6 +18,7 @@ type IconUploader struct {
 	client    *Client
 	iconCache map[string]string
 	cacheMu   sync.RWMutex
+	ctx       context.Context // Carries the request ID and cancels uploads
 }
 
 type IconUploadResponse struct {
@@ -42,6 +45,7 @@ func NewIconUploader(client *Client) *IconUploader {
 	return &IconUploader{
 		client:    client,
 		iconCache: make(map[string]string),
+		ctx:       context.Background(),
 	}
 }
 
@@ -81,12 +85,12 @@ func (iu *IconUploader) UploadIcon(filePath string, filename string) (string, er
 		iu.client.baseURL, filename)
 
 	// Create request with raw image data (as shown in Yoto docs)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(iconData))
+	req, err := http.NewRequestWithContext(iu.ctx, "POST", url, bytes.NewReader(iconData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+iu.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+iu.client.bearerToken())
 	req.Header.Set("Content-Type", contentType)
 
 	// Send request
@@ -102,7 +106,7 @@ func (iu *IconUploader) UploadIcon(filePath string, filename string) (string, er
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("upload failed: %d - %s", resp.StatusCode, string(body))
+		return "", apiErrorWithBody("upload failed", resp, body)
 	}
 
 	// Parse response
@@ -155,12 +159,12 @@ func (iu *IconUploader) UploadIconNoCache(filePath string, filename string) (str
 		iu.client.baseURL, filenameWithTimestamp)
 
 	// Create request with raw image data (as shown in Yoto docs)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(iconData))
+	req, err := http.NewRequestWithContext(iu.ctx, "POST", url, bytes.NewReader(iconData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+iu.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+iu.client.bearerToken())
 	req.Header.Set("Content-Type", contentType)
 
 	// Send request
@@ -176,7 +180,7 @@ func (iu *IconUploader) UploadIconNoCache(filePath string, filename string) (str
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("upload failed: %d - %s", resp.StatusCode, string(body))
+		return "", apiErrorWithBody("upload failed", resp, body)
 	}
 
 	// Parse response
@@ -190,7 +194,7 @@ func (iu *IconUploader) UploadIconNoCache(filePath string, filename string) (str
 	}
 
 	// DO NOT cache - return the media ID directly
-	fmt.Printf("[ICON_UPLOADER] Uploaded icon (no cache) %s: %s\n", filenameWithTimestamp, uploadResp.DisplayIcon.MediaID)
+	slog.InfoContext(iu.ctx, "[ICON_UPLOADER] Uploaded icon (no cache)", "file", filenameWithTimestamp, "media_id", uploadResp.DisplayIcon.MediaID)
 	return uploadResp.DisplayIcon.MediaID, nil
 }
 
@@ -382,13 +386,13 @@ func (iu *IconUploader) UploadAnimatedGIF(filePath string, filename string) (str
 		iu.client.baseURL, filename)
 
 	// Create request with GIF data
-	req, err := http.NewRequest("POST", url, bytes.NewReader(gifData))
+	req, err := http.NewRequestWithContext(iu.ctx, "POST", url, bytes.NewReader(gifData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", iu.client.accessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", iu.client.bearerToken()))
 	req.Header.Set("Content-Type", "image/gif")
 
 	// Make request
@@ -405,7 +409,7 @@ func (iu *IconUploader) UploadAnimatedGIF(filePath string, filename string) (str
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+		return "", apiErrorWithBody("upload failed", resp, body)
 	}
 
 	// Parse response
@@ -421,6 +425,6 @@ func (iu *IconUploader) UploadAnimatedGIF(filePath string, filename string) (str
 	iu.iconCache[filePath] = uploadResp.MediaID
 	iu.cacheMu.Unlock()
 
-	fmt.Printf("[ICON_UPLOADER] Successfully uploaded animated GIF %s: %s\n", filename, uploadResp.MediaID)
+	slog.InfoContext(iu.ctx, "[ICON_UPLOADER] Uploaded animated GIF", "file", filename, "media_id", uploadResp.MediaID)
 	return uploadResp.MediaID, nil
 }
@@ -0,0 +1,77 @@
+package yoto
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ListenerOptions are a device's content preferences, passed to the streaming endpoints as query
+// parameters. Zero values leave the server defaults in place.
+type ListenerOptions struct {
+	DeviceID      string // Player the card was updated for ("device"), so plays are keyed on it rather than the IP
+	VoiceID       string // Narrator voice ("voice")
+	FactGenerator string // "basic" or "enhanced" guide ("facts")
+	NatureIntros  *bool  // Nature-mixed intro ("nature")
+	DynamicIntro  *bool  // Intro opened with a greeting for the listener's time and town ("greeting")
+}
+
+// IsZero reports whether no preferences are set
+func (lo ListenerOptions) IsZero() bool {
+	return lo.DeviceID == "" && lo.VoiceID == "" && lo.FactGenerator == "" && lo.NatureIntros == nil && lo.DynamicIntro == nil
+}
+
+// Query encodes the preferences as query parameters
+func (lo ListenerOptions) Query() url.Values {
+	query := url.Values{}
+	if lo.DeviceID != "" {
+		query.Set("device", lo.DeviceID)
+	}
+	if lo.VoiceID != "" {
+		query.Set("voice", lo.VoiceID)
+	}
+	if lo.FactGenerator != "" {
+		query.Set("facts", lo.FactGenerator)
+	}
+	if lo.NatureIntros != nil {
+		query.Set("nature", strconv.FormatBool(*lo.NatureIntros))
+	}
+	if lo.DynamicIntro != nil {
+		query.Set("greeting", strconv.FormatBool(*lo.DynamicIntro))
+	}
+	return query
+}
+
+// SetListenerOptions sets the device preferences carried by the streaming track URLs
+func (cm *ContentManager) SetListenerOptions(options ListenerOptions) {
+	cm.listenerOptions = options
+}
+
+// SetDynamicStreams points tracks at the card-scoped streaming endpoints, which choose the bird
+// per request, instead of the session endpoints tied to the bird published with the card
+func (cm *ContentManager) SetDynamicStreams(enabled bool) {
+	cm.dynamicStreams = enabled
+}
+
+// SetNightMode makes the tracks request the night variant (quieter intro and goodnight outro)
+func (cm *ContentManager) SetNightMode(enabled bool) {
+	cm.nightMode = enabled
+}
+
+// streamURL builds the URL of a streaming endpoint for the card, carrying the listener options.
+// Session URLs also carry the card, so a play without a device is keyed on the card, not its IP.
+func (cm *ContentManager) streamURL(baseURL string, cardID string, track string, sessionID string) string {
+	query := cm.listenerOptions.Query()
+	if cm.nightMode {
+		query.Set("mode", "night")
+	}
+	if cm.dynamicStreams {
+		if len(query) == 0 {
+			return fmt.Sprintf("%s/api/v1/stream/%s/%s", baseURL, url.PathEscape(cardID), track)
+		}
+		return fmt.Sprintf("%s/api/v1/stream/%s/%s?%s", baseURL, url.PathEscape(cardID), track, query.Encode())
+	}
+	query.Set("session", sessionID)
+	query.Set("card", cardID)
+	return fmt.Sprintf("%s/api/v1/stream/%s?%s", baseURL, track, query.Encode())
+}
@@ -0,0 +1,167 @@
+package yoto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	loginAudience = "https://api.yotoplay.com"
+	loginScope    = "profile offline_access"
+
+	deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// DeviceAuthorization is a device code login waiting for the user to approve it in a browser
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// oauthError is the error body the Yoto login server returns
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// loginURL is the Yoto login server, derived from the token endpoint
+func (c *Client) loginURL() string {
+	return strings.TrimSuffix(c.authURL, "/oauth/token")
+}
+
+// AuthorizeURL returns the browser login URL for the authorization code flow with PKCE. The
+// login server redirects to redirectURI with the code and state.
+func (c *Client) AuthorizeURL(redirectURI string, state string, codeChallenge string) string {
+	query := url.Values{}
+	query.Set("audience", loginAudience)
+	query.Set("scope", loginScope)
+	query.Set("response_type", "code")
+	query.Set("client_id", c.clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	return c.loginURL() + "/authorize?" + query.Encode()
+}
+
+// ExchangeCode trades a browser login's authorization code for tokens, which the client then uses
+// and persists to its token store
+func (c *Client) ExchangeCode(code string, redirectURI string, codeVerifier string) (*TokenResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("client_id", c.clientID)
+	data.Set("code", code)
+	data.Set("redirect_uri", redirectURI)
+	data.Set("code_verifier", codeVerifier)
+
+	tokens, err := c.requestTokens(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	return tokens, c.useTokens(tokens)
+}
+
+// StartDeviceLogin begins a device code login; show the user the verification URI and code, then
+// call AwaitDeviceLogin
+func (c *Client) StartDeviceLogin() (*DeviceAuthorization, error) {
+	data := url.Values{}
+	data.Set("client_id", c.clientID)
+	data.Set("scope", loginScope)
+	data.Set("audience", loginAudience)
+
+	resp, err := c.httpClient.PostForm(c.loginURL()+"/oauth/device/code", data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAuthError("device login failed", resp)
+	}
+
+	var authorization DeviceAuthorization
+	if err := json.NewDecoder(resp.Body).Decode(&authorization); err != nil {
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// AwaitDeviceLogin polls until the user approves the device login, then the client uses and
+// persists the tokens. It fails if the user denies the login or the code expires.
+func (c *Client) AwaitDeviceLogin(authorization *DeviceAuthorization) (*TokenResponse, error) {
+	interval := time.Duration(max(authorization.Interval, 1)) * time.Second
+	deadline := time.Now().Add(time.Duration(authorization.ExpiresIn) * time.Second)
+
+	data := url.Values{}
+	data.Set("grant_type", deviceCodeGrant)
+	data.Set("client_id", c.clientID)
+	data.Set("device_code", authorization.DeviceCode)
+
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		tokens, err := c.requestTokens(data)
+		if err == nil {
+			return tokens, c.useTokens(tokens)
+		}
+
+		oauthErr, ok := err.(*oauthError)
+		switch {
+		case ok && oauthErr.Code == "authorization_pending":
+		case ok && oauthErr.Code == "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("device login failed: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("device login expired before it was approved")
+}
+
+// requestTokens posts a grant to the token endpoint. Rejections are returned as *oauthError.
+func (c *Client) requestTokens(data url.Values) (*TokenResponse, error) {
+	resp, err := c.httpClient.PostForm(c.authURL, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var rejection oauthError
+		if json.Unmarshal(body, &rejection) == nil && rejection.Code != "" {
+			return nil, &rejection
+		}
+		return nil, apiErrorWithBody("token request failed", resp, body)
+	}
+
+	var tokens TokenResponse
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+// useTokens switches the client to freshly issued tokens and saves them to the token store
+func (c *Client) useTokens(tokens *TokenResponse) error {
+	c.SetTokens(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn)
+	return c.PersistTokens()
+}
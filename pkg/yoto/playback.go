@@ -0,0 +1,76 @@
+package yoto
+
+// Auto-advance modes for the content-level "autoadvance" setting
+const (
+	AutoAdvanceNext   = "next"   // Play the next chapter when one finishes (player default)
+	AutoAdvanceNone   = "none"   // Stop at the end of each chapter
+	AutoAdvanceRepeat = "repeat" // Loop the current chapter
+)
+
+// Track end commands for TrackEvents.OnEnd
+const (
+	TrackEndStop   = "stop"
+	TrackEndRepeat = "repeat"
+	TrackEndGoto   = "goto" // Params: chapterKey and trackKey
+)
+
+// ContentConfig holds card-level playback behavior sent as content.config
+type ContentConfig struct {
+	AutoAdvance   string `json:"autoadvance,omitempty"`   // One of the AutoAdvance* constants
+	ResumeTimeout int    `json:"resumeTimeout,omitempty"` // Seconds before the player forgets the resume position (0 = player default)
+	OnlineOnly    bool   `json:"onlineOnly,omitempty"`    // Require a connection (streaming cards)
+}
+
+// Ambient controls what the player's ambient light and display show while a chapter or track plays
+type Ambient struct {
+	DefaultTrackDisplay string `json:"defaultTrackDisplay,omitempty"`
+	DefaultTrackAmbient string `json:"defaultTrackAmbient,omitempty"`
+}
+
+// TrackEventCommand is a player command fired on a track event
+type TrackEventCommand struct {
+	Cmd    string            `json:"cmd"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// TrackEvents describes what the player does at track boundaries
+type TrackEvents struct {
+	OnEnd *TrackEventCommand `json:"onEnd,omitempty"`
+}
+
+// PlaybackOptions lets callers control playback instead of relying on player defaults.
+// Zero values leave the corresponding player default in place.
+type PlaybackOptions struct {
+	Config         ContentConfig
+	ChapterAmbient map[string]*Ambient     // Keyed by chapter key ("01", "02", ...)
+	TrackEvents    map[string]*TrackEvents // Keyed by chapter key; applied to every track in the chapter
+}
+
+// IsZero reports whether no playback options are set
+func (po *PlaybackOptions) IsZero() bool {
+	return po == nil ||
+		(po.Config == ContentConfig{} && len(po.ChapterAmbient) == 0 && len(po.TrackEvents) == 0)
+}
+
+// ApplyToStreamingChapters sets ambient and event options on matching chapters and their tracks
+func (po *PlaybackOptions) ApplyToStreamingChapters(chapters []StreamingChapter) {
+	if po.IsZero() {
+		return
+	}
+
+	for i := range chapters {
+		if ambient, ok := po.ChapterAmbient[chapters[i].Key]; ok {
+			chapters[i].Ambient = ambient
+		}
+		if events, ok := po.TrackEvents[chapters[i].Key]; ok {
+			for j := range chapters[i].Tracks {
+				chapters[i].Tracks[j].Events = events
+			}
+		}
+	}
+}
+
+// StopAfterChapter returns events that stop the player when a track finishes
+func StopAfterChapter() *TrackEvents {
+	return &TrackEvents{OnEnd: &TrackEventCommand{Cmd: TrackEndStop}}
+}
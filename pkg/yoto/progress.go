@@ -0,0 +1,22 @@
+package yoto
+
+// Update steps reported to a ProgressReporter
+const (
+	ProgressAudioReady = "audio_ready" // The card's audio was rendered, e.g. a single track stitched from its narration
+	ProgressUploaded   = "uploaded"    // The card's audio and icons were uploaded to Yoto
+)
+
+// ProgressReporter is told when a card update finishes one of its steps, with a short description
+type ProgressReporter func(step string, message string)
+
+// SetProgressReporter reports the update's rendering and upload steps as they finish
+func (cm *ContentManager) SetProgressReporter(reporter ProgressReporter) {
+	cm.progress = reporter
+}
+
+// reportProgress tells the progress reporter, if any, that a step finished
+func (cm *ContentManager) reportProgress(step string, message string) {
+	if cm.progress != nil {
+		cm.progress(step, message)
+	}
+}
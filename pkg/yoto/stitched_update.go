@@ -0,0 +1,121 @@
+package yoto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// stitchedTrackTitle names the single track of a stitched card, and its transcode checkpoint
+const stitchedTrackTitle = "Bird Song Explorer"
+
+// TrackStitcher joins the audio behind a card's stream URLs, in order, into one MP3
+type TrackStitcher func(ctx context.Context, trackURLs []string) ([]byte, error)
+
+// SetTrackStitcher sets how stitched templates turn their segments into a single track; without
+// one, stitched templates publish as separate streaming chapters
+func (cm *ContentManager) SetTrackStitcher(stitcher TrackStitcher) {
+	cm.stitcher = stitcher
+}
+
+// SetSingleTrack makes the card play as one continuous track: the template's segments are
+// stitched together and uploaded as a single chapter instead of streaming one chapter each
+func (cm *ContentManager) SetSingleTrack(enabled bool) {
+	cm.singleTrack = enabled
+}
+
+// updateCardWithStitchedTrack publishes the template as one uploaded track in one chapter. The
+// segments are fetched from the same stream URLs their chapters would play, so the stitched
+// track carries whatever the card's streams would serve today.
+func (cm *ContentManager) updateCardWithStitchedTrack(cardID string, birdName string, baseURL string, sessionID string, template CardTemplate, existingCard *Card) error {
+	trackURLs := make([]string, 0, len(template.Segments))
+	for _, segment := range template.Segments {
+		if _, known := cm.assembler.builders[segment]; !known {
+			return fmt.Errorf("unknown card segment %q", segment)
+		}
+		trackURLs = append(trackURLs, cm.streamURL(baseURL, cardID, segment, sessionID))
+	}
+
+	sha, transcodeInfo, err := cm.uploadStitchedTrack(trackURLs)
+	if err != nil {
+		return err
+	}
+
+	icon := cm.uploadBirdIcon(birdName)
+	cm.reportProgress(ProgressUploaded, "Uploaded the stitched track and bird icon")
+	title := "Today's Bird: " + birdName
+	chapters := []StreamingChapter{
+		{
+			Title: title,
+			Tracks: []StreamingTrack{
+				{
+					Title:    title,
+					TrackURL: fmt.Sprintf("yoto:#%s", sha),
+					Type:     "audio",
+					Format:   transcodeInfo.Transcode.TranscodedInfo.Format,
+					Duration: transcodeInfo.GetDuration(),
+					Display: Display{
+						Icon16x16: icon,
+					},
+				},
+			},
+			Display: Display{
+				Icon16x16: icon,
+			},
+		},
+	}
+
+	SequenceChapters(chapters)
+	cm.titleFormatter.FormatStreamingChapters(chapters)
+	cm.playbackOptions.ApplyToStreamingChapters(chapters)
+
+	content := map[string]interface{}{
+		"title":    cm.cardTitle,
+		"chapters": chapters,
+		"metadata": cm.coverMetadata(existingCard),
+	}
+	if !cm.playbackOptions.IsZero() && cm.playbackOptions.Config != (ContentConfig{}) {
+		content["config"] = cm.playbackOptions.Config
+	}
+
+	if err := cm.publishVerified(cardID, content, chapters, existingCard); err != nil {
+		return err
+	}
+	cm.saveCheckpoint(StepContentPosted, time.Now().UTC().Format(time.RFC3339))
+
+	slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Card updated with a single track", "card_id", cardID, "bird", birdName,
+		"segments", len(template.Segments), "duration", transcodeInfo.GetDuration(), "session", sessionID)
+	return nil
+}
+
+// uploadStitchedTrack stitches and uploads the card's track. A transcode an earlier async attempt
+// left pending is checked on rather than stitched and uploaded again.
+func (cm *ContentManager) uploadStitchedTrack(trackURLs []string) (string, *TranscodeResponse, error) {
+	step := StepTranscodePrefix + stitchedTrackTitle
+	if uploadID, pending := cm.checkpoint(step); pending {
+		transcodeInfo, err := cm.uploader.CheckTranscode(uploadID)
+		if err != nil {
+			return "", nil, fmt.Errorf("transcoding failed: %w", err)
+		}
+		return transcodeInfo.Transcode.TranscodedSha256, transcodeInfo, nil
+	}
+
+	audio, err := cm.stitcher(cm.ctx, trackURLs)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to stitch tracks: %w", err)
+	}
+	cm.reportProgress(ProgressAudioReady, fmt.Sprintf("Stitched %d segments into one track", len(trackURLs)))
+
+	sha, transcodeInfo, err := cm.uploader.UploadAudioData(audio, stitchedTrackTitle)
+	var pending *TranscodePendingError
+	if errors.As(err, &pending) {
+		slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Transcode still running, resuming later", "title", stitchedTrackTitle, "upload_id", pending.UploadID)
+		cm.saveCheckpoint(step, pending.UploadID)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return sha, transcodeInfo, nil
+}
@@ -0,0 +1,49 @@
+package yoto
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SetStreamingCard makes the card a streaming card: its tracks point at the card-scoped streaming
+// endpoints, which generate each track's audio for the day on every play, and its layout doesn't
+// name or picture the bird. Updates only post content when the layout changed (a new theme icon,
+// cover, or chapter list), so the card doesn't need a content post every day.
+func (cm *ContentManager) SetStreamingCard(enabled bool) {
+	cm.streamingCard = enabled
+	if enabled {
+		cm.dynamicStreams = true
+	}
+}
+
+// streamingContentCurrent reports whether the card already holds the content: the same title,
+// chapters, playback config, and cover. Fields Yoto adds to stored content are ignored.
+func streamingContentCurrent(existing *Card, content map[string]interface{}, chapters []StreamingChapter) bool {
+	if existing == nil || existing.Content == nil || existing.Title != content["title"] {
+		return false
+	}
+
+	var stored struct {
+		Chapters []StreamingChapter `json:"chapters"`
+		Config   ContentConfig      `json:"config"`
+	}
+	raw, err := json.Marshal(existing.Content)
+	if err != nil || json.Unmarshal(raw, &stored) != nil {
+		return false
+	}
+
+	config, _ := content["config"].(ContentConfig)
+	if stored.Config != config || !sameJSON(stored.Chapters, chapters) {
+		return false
+	}
+
+	metadata, _ := content["metadata"].(map[string]interface{})
+	return sameJSON(existing.Metadata["cover"], metadata["cover"])
+}
+
+// sameJSON reports whether two values encode to the same JSON
+func sameJSON(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
@@ -1,14 +1,14 @@
 package yoto
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 type StreamingChapter struct {
@@ -17,17 +17,20 @@ type StreamingChapter struct {
 	OverlayLabel string           `json:"overlayLabel,omitempty"`
 	Tracks       []StreamingTrack `json:"tracks"`
 	Display      Display          `json:"display,omitempty"`
+	Ambient      *Ambient         `json:"ambient,omitempty"`
 }
 
 type StreamingTrack struct {
-	Key          string  `json:"key"`
-	Title        string  `json:"title,omitempty"`
-	TrackURL     string  `json:"trackUrl"`
-	Type         string  `json:"type"`
-	Format       string  `json:"format"`
-	Duration     int     `json:"duration"`
-	OverlayLabel string  `json:"overlayLabel,omitempty"`
-	Display      Display `json:"display,omitempty"`
+	Key          string       `json:"key"`
+	Title        string       `json:"title,omitempty"`
+	TrackURL     string       `json:"trackUrl"`
+	Type         string       `json:"type"`
+	Format       string       `json:"format"`
+	Duration     int          `json:"duration"`
+	OverlayLabel string       `json:"overlayLabel,omitempty"`
+	Display      Display      `json:"display,omitempty"`
+	Ambient      *Ambient     `json:"ambient,omitempty"`
+	Events       *TrackEvents `json:"events,omitempty"`
 }
 
 type StreamingContent struct {
@@ -37,14 +40,17 @@ type StreamingContent struct {
 var defaultIconID = "yoto:#RSsi4eQvVffIDMHbq3cuKn0ebSg0X-3Y-ZxrAorxycY"
 
 func (cm *ContentManager) uploadTrackIcon(iconPath string, iconName string) string {
+	if mediaID, ok := cm.checkpoint(StepIconPrefix + iconName); ok {
+		return mediaID
+	}
 	if cm.iconUploader == nil {
-		fmt.Printf("[STREAMING_UPDATE] Icon uploader not initialized, using default icon\n")
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Icon uploader not initialized, using default icon")
 		return defaultIconID
 	}
 
 	mediaID, err := cm.iconUploader.UploadIcon(iconPath, iconName)
 	if err != nil {
-		fmt.Printf("[STREAMING_UPDATE] Failed to upload icon %s: %v, using default\n", iconName, err)
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Failed to upload icon, using default", "icon", iconName, "error", err)
 		return defaultIconID
 	}
 
@@ -52,18 +58,22 @@ func (cm *ContentManager) uploadTrackIcon(iconPath string, iconName string) stri
 		mediaID = fmt.Sprintf("yoto:#%s", mediaID)
 	}
 
+	cm.saveCheckpoint(StepIconPrefix+iconName, mediaID)
 	return mediaID
 }
 
 func (cm *ContentManager) uploadBirdIconNoCache(iconPath string, iconName string) string {
+	if mediaID, ok := cm.checkpoint(StepIconPrefix + iconName); ok {
+		return mediaID
+	}
 	if cm.iconUploader == nil {
-		fmt.Printf("[STREAMING_UPDATE] Icon uploader not initialized, using default icon\n")
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Icon uploader not initialized, using default icon")
 		return defaultIconID
 	}
 
 	mediaID, err := cm.iconUploader.UploadIconNoCache(iconPath, iconName)
 	if err != nil {
-		fmt.Printf("[STREAMING_UPDATE] Failed to upload bird icon %s: %v, using default\n", iconName, err)
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Failed to upload bird icon, using default", "icon", iconName, "error", err)
 		return defaultIconID
 	}
 
@@ -71,184 +81,213 @@ func (cm *ContentManager) uploadBirdIconNoCache(iconPath string, iconName string
 		mediaID = fmt.Sprintf("yoto:#%s", mediaID)
 	}
 
+	cm.saveCheckpoint(StepIconPrefix+iconName, mediaID)
 	return mediaID
 }
 
-func (cm *ContentManager) UpdateCardWithStreamingTracks(cardID string, birdName string, baseURL string, sessionID string) error {
-	if err := cm.client.ensureAuthenticated(); err != nil {
-		return fmt.Errorf("authentication failed: %w", err)
+// iconUploadConcurrency caps how many icons a card update uploads at once
+const iconUploadConcurrency = 4
+
+// uploadStreamingIcons uploads every icon the card needs in parallel, then resolves the
+// fallbacks: the welcome track uses the theme icon when there is one, and the guide track uses
+// the song visualizer when one could be generated. Icon uploads never fail the update; a failed
+// upload falls back to the default icon.
+func (cm *ContentManager) uploadStreamingIcons(birdName string, template CardTemplate) TrackIcons {
+	var icons TrackIcons
+	var themeIcon, visualizerIcon string
+
+	var g errgroup.Group
+	g.SetLimit(iconUploadConcurrency)
+
+	g.Go(func() error {
+		icons.Welcome = cm.uploadTrackIcon("./assets/icons/binoculars_16x16.png", "binoculars")
+		return nil
+	})
+	g.Go(func() error {
+		icons.Music = cm.uploadTrackIcon("./assets/icons/music_16x16.png", "music")
+		return nil
+	})
+	g.Go(func() error {
+		icons.HikingBoot = cm.uploadTrackIcon("./assets/icons/hiking_boot_16x16.png", "hiking_boot")
+		return nil
+	})
+	g.Go(func() error {
+		icons.Bird = cm.uploadBirdIcon(birdName)
+		return nil
+	})
+	if template.Has(SegmentQuiz) {
+		g.Go(func() error {
+			icons.Question = cm.uploadTrackIcon("./assets/icons/question_16x16.png", "question")
+			return nil
+		})
+	}
+	if template.Has(SegmentBirdHero) {
+		g.Go(func() error {
+			icons.BirdHero = cm.uploadTrackIcon("./assets/icons/bird_hero_16x16.png", "bird_hero")
+			return nil
+		})
 	}
 
-	existingCard, err := cm.client.GetCard(cardID)
-	if err != nil {
-		fmt.Printf("[STREAMING_UPDATE] Warning: Could not get existing card %s: %v\n", cardID, err)
+	// Seasonal themes replace the welcome track's binoculars with their own icon
+	if cm.themeIconPath != "" {
+		if _, err := os.Stat(cm.themeIconPath); err == nil {
+			themeName := strings.TrimSuffix(filepath.Base(cm.themeIconPath), filepath.Ext(cm.themeIconPath))
+			g.Go(func() error {
+				themeIcon = cm.uploadTrackIcon(cm.themeIconPath, themeName)
+				return nil
+			})
+		} else {
+			slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Theme icon not found, using binoculars", "path", cm.themeIconPath)
+		}
 	}
 
-	if sessionID == "" {
-		sessionID = fmt.Sprintf("%s_%d", cardID, time.Now().Unix())
+	// Track 3 plays the song visualizer when one can be generated, otherwise the static bird icon
+	if cm.guideIconProvider != nil && birdName != "" {
+		visualizerName := strings.ToLower(strings.ReplaceAll(birdName, " ", "_")) + "_song"
+		if mediaID, ok := cm.checkpoint(StepIconPrefix + visualizerName); ok {
+			visualizerIcon = mediaID
+		} else {
+			g.Go(func() error {
+				if gifPath := cm.guideIconProvider(birdName); gifPath != "" {
+					visualizerIcon = cm.uploadBirdIconNoCache(gifPath, visualizerName)
+				}
+				return nil
+			})
+		}
 	}
 
-	fmt.Printf("[STREAMING_UPDATE] Updating card with session %s for bird: %s\n", sessionID, birdName)
+	g.Wait()
 
-	binocularsIcon := cm.uploadTrackIcon("./assets/icons/binoculars_16x16.png", "binoculars")
-	musicIcon := cm.uploadTrackIcon("./assets/icons/music_16x16.png", "music")
+	if themeIcon != "" && themeIcon != defaultIconID {
+		icons.Welcome = themeIcon
+	}
+	icons.Guide = icons.Bird
+	if visualizerIcon != "" && visualizerIcon != defaultIconID {
+		icons.Guide = visualizerIcon
+	}
+	return icons
+}
 
-	var birdIcon string
-	if birdName != "" {
-		birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
-		birdSpecificIconPath := fmt.Sprintf("./assets/icons/%s.png", birdDir)
+// uploadBirdIcon uploads the bird's own icon asset, else a yotoicons.com match, else a generated
+// icon, else the generic bird icon
+func (cm *ContentManager) uploadBirdIcon(birdName string) string {
+	if birdName == "" {
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] No bird name provided, using generic bird icon")
+		return cm.uploadTrackIcon("./assets/icons/bird_16x16.png", "bird")
+	}
 
-		// Try bird-specific icon first
-		if _, err := os.Stat(birdSpecificIconPath); err == nil {
-			fmt.Printf("[STREAMING_UPDATE] ✅ Uploading bird icon for '%s': %s\n", birdName, birdSpecificIconPath)
-			birdIcon = cm.uploadBirdIconNoCache(birdSpecificIconPath, birdDir)
-			fmt.Printf("[STREAMING_UPDATE] ✅ Bird icon uploaded - Bird: %s, Icon ID: %s\n", birdName, birdIcon)
-		} else {
-			// Fallback to generic bird icon
-			fmt.Printf("[STREAMING_UPDATE] ⚠️  Bird-specific icon not found at %s for '%s', using generic bird icon\n", birdSpecificIconPath, birdName)
-			birdIcon = cm.uploadTrackIcon("./assets/icons/bird_16x16.png", "bird")
+	birdDir := strings.ToLower(strings.ReplaceAll(birdName, " ", "_"))
+	birdSpecificIconPath := fmt.Sprintf("./assets/icons/%s.png", birdDir)
+
+	// Try bird-specific icon first
+	if _, err := os.Stat(birdSpecificIconPath); err != nil {
+		// Then a matching icon from yotoicons.com
+		if searchedIcon, err := cm.iconSearcher.SearchBirdIcon(birdName); err == nil && searchedIcon != "" {
+			slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Using yotoicons.com bird icon", "bird", birdName, "icon", searchedIcon)
+			return searchedIcon
+		}
+
+		// Then a pixel icon generated from a photo of the species
+		if cm.birdIconProvider != nil {
+			if generatedPath := cm.birdIconProvider(cm.ctx, birdName); generatedPath != "" {
+				if birdIcon := cm.uploadTrackIcon(generatedPath, birdDir); birdIcon != defaultIconID {
+					slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Using generated bird icon", "bird", birdName, "icon", birdIcon)
+					return birdIcon
+				}
+			}
 		}
-	} else {
-		fmt.Printf("[STREAMING_UPDATE] ⚠️  No bird name provided, using generic bird icon\n")
-		birdIcon = cm.uploadTrackIcon("./assets/icons/bird_16x16.png", "bird")
-	}
-
-	hikingBootIcon := cm.uploadTrackIcon("./assets/icons/hiking_boot_16x16.png", "hiking_boot")
-
-	chapters := []StreamingChapter{
-		{
-			Key:          "01",
-			Title:        "Welcome, Explorers!",
-			OverlayLabel: "1",
-			Tracks: []StreamingTrack{
-				{
-					Key:          "01",
-					Title:        "Welcome, Explorers!",
-					TrackURL:     fmt.Sprintf("%s/api/v1/stream/intro?session=%s", baseURL, sessionID),
-					Type:         "stream",
-					Format:       "mp3",
-					Duration:     30,
-					OverlayLabel: "1",
-					Display: Display{
-						Icon16x16: binocularsIcon,
-					},
-				},
-			},
-			Display: Display{
-				Icon16x16: binocularsIcon,
-			},
-		},
-		{
-			Key:          "02",
-			Title:        "Who's Singing Today?",
-			OverlayLabel: "2",
-			Tracks: []StreamingTrack{
-				{
-					Key:          "01",
-					Title:        "Who's Singing Today?",
-					TrackURL:     fmt.Sprintf("%s/api/v1/stream/announcement?session=%s", baseURL, sessionID),
-					Type:         "stream",
-					Format:       "mp3",
-					Duration:     10,
-					OverlayLabel: "2",
-					Display: Display{
-						Icon16x16: musicIcon,
-					},
-				},
-			},
-			Display: Display{
-				Icon16x16: musicIcon,
-			},
-		},
-		{
-			Key:          "03",
-			Title:        "Bird Explorer's Guide",
-			OverlayLabel: "3",
-			Tracks: []StreamingTrack{
-				{
-					Key:          "01",
-					Title:        "Bird Explorer's Guide",
-					TrackURL:     fmt.Sprintf("%s/api/v1/stream/description?session=%s", baseURL, sessionID),
-					Type:         "stream",
-					Format:       "mp3",
-					Duration:     60,
-					OverlayLabel: "3",
-					Display: Display{
-						Icon16x16: birdIcon,
-					},
-				},
-			},
-			Display: Display{
-				Icon16x16: birdIcon,
-			},
-		},
-		{
-			Key:          "04",
-			Title:        "Happy Exploring!",
-			OverlayLabel: "4",
-			Tracks: []StreamingTrack{
-				{
-					Key:          "01",
-					Title:        "Happy Exploring!",
-					TrackURL:     fmt.Sprintf("%s/api/v1/stream/outro?session=%s", baseURL, sessionID),
-					Type:         "stream",
-					Format:       "mp3",
-					Duration:     20,
-					OverlayLabel: "4",
-					Display: Display{
-						Icon16x16: hikingBootIcon,
-					},
-				},
-			},
-			Display: Display{
-				Icon16x16: hikingBootIcon,
-			},
-		},
+
+		// Fallback to generic bird icon
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Bird-specific icon not found, using generic bird icon", "bird", birdName, "path", birdSpecificIconPath)
+		return cm.uploadTrackIcon("./assets/icons/bird_16x16.png", "bird")
 	}
 
-	metadataMap := make(map[string]interface{})
+	slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Uploading bird icon", "bird", birdName, "path", birdSpecificIconPath)
+	birdIcon := cm.uploadBirdIconNoCache(birdSpecificIconPath, birdDir)
+	slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Bird icon uploaded", "bird", birdName, "icon", birdIcon)
+	return birdIcon
+}
 
-	if existingCard != nil && existingCard.Metadata != nil {
-		if cover, hasCover := existingCard.Metadata["cover"]; hasCover {
-			metadataMap["cover"] = cover
-		}
+func (cm *ContentManager) UpdateCardWithStreamingTracks(cardID string, birdName string, baseURL string, sessionID string) error {
+	// A retry of an update whose content already went out has nothing left to do
+	if _, posted := cm.checkpoint(StepContentPosted); posted {
+		slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Content already posted by an earlier attempt", "card_id", cardID, "bird", birdName)
+		return nil
 	}
 
-	contentReq := map[string]interface{}{
-		"cardId": cardID,
-		"content": map[string]interface{}{
-			"title":    "Bird Song Explorer",
-			"chapters": chapters,
-			"metadata": metadataMap,
-		},
+	if err := cm.client.ensureAuthenticated(); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
 	}
 
-	jsonData, err := json.Marshal(contentReq)
+	existingCard, err := cm.client.GetCard(cm.ctx, cardID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal update request: %w", err)
+		slog.WarnContext(cm.ctx, "[STREAMING_UPDATE] Could not get existing card", "card_id", cardID, "error", err)
 	}
 
-	url := fmt.Sprintf("%s/content", cm.client.baseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if sessionID == "" {
+		sessionID = fmt.Sprintf("%s_%d", cardID, time.Now().Unix())
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cm.client.accessToken))
+	slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Updating card", "card_id", cardID, "session", sessionID, "bird", birdName)
+
+	template := cm.template()
+	if template.Stitched && cm.stitcher != nil && !cm.streamingCard {
+		return cm.updateCardWithStitchedTrack(cardID, birdName, baseURL, sessionID, template, existingCard)
+	}
+	iconBird := birdName
+	if cm.streamingCard {
+		// The endpoints pick the bird on every play, so the card shows the generic bird icon
+		iconBird = ""
+	}
+	icons := cm.uploadStreamingIcons(iconBird, template)
+	cm.reportProgress(ProgressUploaded, "Uploaded track icons")
 
-	resp, err := cm.client.httpClient.Do(req)
+	chapters, err := cm.assembler.Assemble(template, icons, func(segment string) string {
+		return cm.streamURL(baseURL, cardID, segment, sessionID)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update card: %w", err)
+		return fmt.Errorf("failed to assemble chapters: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	cm.titleFormatter.FormatStreamingChapters(chapters)
+	cm.playbackOptions.ApplyToStreamingChapters(chapters)
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to update card content (status %d): %s", resp.StatusCode, string(body))
+	content := map[string]interface{}{
+		"title":    cm.cardTitle,
+		"chapters": chapters,
+		"metadata": cm.coverMetadata(existingCard),
+	}
+	if !cm.playbackOptions.IsZero() && cm.playbackOptions.Config != (ContentConfig{}) {
+		content["config"] = cm.playbackOptions.Config
 	}
 
-	fmt.Printf("[STREAMING_UPDATE] ✅ Card %s updated - Bird: '%s', Icon: %s, Session: %s\n", cardID, birdName, birdIcon, sessionID)
+	if cm.streamingCard && streamingContentCurrent(existingCard, content, chapters) {
+		slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Card already has its streaming layout, skipping the content post", "card_id", cardID)
+		cm.recordPublished(content, chapters)
+		cm.saveCheckpoint(StepContentPosted, time.Now().UTC().Format(time.RFC3339))
+		return nil
+	}
+
+	if err := cm.publishVerified(cardID, content, chapters, existingCard); err != nil {
+		return err
+	}
+	cm.saveCheckpoint(StepContentPosted, time.Now().UTC().Format(time.RFC3339))
+
+	slog.InfoContext(cm.ctx, "[STREAMING_UPDATE] Card updated", "card_id", cardID, "bird", birdName, "icon", icons.Bird, "session", sessionID)
 	return nil
 }
+
+// coverMetadata returns the card metadata with the uploaded bird cover, or the existing card's
+// cover when there is none
+func (cm *ContentManager) coverMetadata(existingCard *Card) map[string]interface{} {
+	metadataMap := make(map[string]interface{})
+
+	if coverURL := cm.uploadCoverImage(); coverURL != "" {
+		metadataMap["cover"] = map[string]interface{}{"imageL": coverURL}
+	} else if existingCard != nil && existingCard.Metadata != nil {
+		if cover, hasCover := existingCard.Metadata["cover"]; hasCover {
+			metadataMap["cover"] = cover
+		}
+	}
+	return metadataMap
+}
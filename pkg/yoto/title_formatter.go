@@ -0,0 +1,185 @@
+package yoto
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxTitleLength is the longest title the Yoto app and player display without clipping
+const MaxTitleLength = 60
+
+// English variants supported by TitleFormatter
+const (
+	EnglishVariantNone = ""   // Keep spellings as provided
+	EnglishVariantUS   = "us" // Gray, colored
+	EnglishVariantUK   = "uk" // Grey, coloured
+)
+
+// annotationPattern matches bracketed annotations such as "(domestic type)" or "[introduced]"
+var annotationPattern = regexp.MustCompile(`\s*[\(\[][^\)\]]*[\)\]]`)
+
+// minorWords stay lowercase inside a title
+var minorWords = map[string]bool{
+	"a": true, "an": true, "and": true, "at": true, "by": true, "for": true,
+	"in": true, "of": true, "on": true, "or": true, "the": true, "to": true,
+}
+
+// variantSpellings maps lowercase spellings to their US and UK forms
+var variantSpellings = map[string][2]string{
+	"gray":     {"gray", "grey"},
+	"grey":     {"gray", "grey"},
+	"colored":  {"colored", "coloured"},
+	"coloured": {"colored", "coloured"},
+	"color":    {"color", "colour"},
+	"colour":   {"color", "colour"},
+}
+
+// TitleFormatter normalizes bird names and chapter/track titles before they reach the card.
+// API common names arrive with mixed casing ("black-Capped chickadee"), annotations
+// ("Rock Pigeon (Feral Pigeon)") and occasionally run past what the player can show.
+type TitleFormatter struct {
+	Variant   string // One of the EnglishVariant* constants
+	MaxLength int    // Truncate beyond this many characters (0 disables truncation)
+}
+
+// NewTitleFormatter creates a formatter for the given English variant with the Yoto display limit
+func NewTitleFormatter(variant string) *TitleFormatter {
+	variant = strings.ToLower(strings.TrimSpace(variant))
+	if variant != EnglishVariantUS && variant != EnglishVariantUK {
+		variant = EnglishVariantNone
+	}
+
+	return &TitleFormatter{
+		Variant:   variant,
+		MaxLength: MaxTitleLength,
+	}
+}
+
+// Format cleans up, title-cases, and truncates a title
+func (tf *TitleFormatter) Format(title string) string {
+	if tf == nil {
+		return title
+	}
+
+	title = annotationPattern.ReplaceAllString(title, "")
+	title = strings.ReplaceAll(title, "_", " ")
+	title = strings.Join(strings.Fields(title), " ")
+
+	words := strings.Split(title, " ")
+	for i, word := range words {
+		words[i] = tf.formatWord(word, i == 0)
+	}
+	title = strings.Join(words, " ")
+
+	return tf.truncate(title)
+}
+
+// FormatStreamingChapters formats every chapter and track title in place
+func (tf *TitleFormatter) FormatStreamingChapters(chapters []StreamingChapter) {
+	if tf == nil {
+		return
+	}
+
+	for i := range chapters {
+		chapters[i].Title = tf.Format(chapters[i].Title)
+		for j := range chapters[i].Tracks {
+			chapters[i].Tracks[j].Title = tf.Format(chapters[i].Tracks[j].Title)
+		}
+	}
+}
+
+// FormatChapters formats every chapter and track title of an uploaded playlist in place
+func (tf *TitleFormatter) FormatChapters(chapters []Chapter) {
+	if tf == nil {
+		return
+	}
+
+	for i := range chapters {
+		chapters[i].Title = tf.Format(chapters[i].Title)
+		for j := range chapters[i].Tracks {
+			chapters[i].Tracks[j].Title = tf.Format(chapters[i].Tracks[j].Title)
+		}
+	}
+}
+
+// formatWord title-cases one space-separated word. The part after a hyphen stays
+// lowercase ("Black-capped"), following the English bird-naming convention.
+func (tf *TitleFormatter) formatWord(word string, first bool) string {
+	if word == "" || word == "-" {
+		return word
+	}
+
+	parts := strings.Split(word, "-")
+	for i, part := range parts {
+		part = tf.applyVariant(part)
+		switch {
+		case i > 0:
+			parts[i] = strings.ToLower(part)
+		case !first && minorWords[strings.ToLower(part)]:
+			parts[i] = strings.ToLower(part)
+		default:
+			parts[i] = capitalize(part)
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// applyVariant swaps US/UK spellings, preserving the leading capital
+func (tf *TitleFormatter) applyVariant(word string) string {
+	if tf.Variant == EnglishVariantNone {
+		return word
+	}
+
+	spellings, ok := variantSpellings[strings.ToLower(word)]
+	if !ok {
+		return word
+	}
+
+	replacement := spellings[0]
+	if tf.Variant == EnglishVariantUK {
+		replacement = spellings[1]
+	}
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		replacement = capitalize(replacement)
+	}
+	return replacement
+}
+
+// capitalize uppercases the first letter of all-lowercase or all-uppercase words.
+// Mixed-case words like "McCown's" and short acronyms like "UK" are left alone.
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+
+	lower := strings.ToLower(word)
+	upper := strings.ToUpper(word)
+	if word != lower && word != upper {
+		return word
+	}
+	if word == upper && utf8.RuneCountInString(word) <= 2 {
+		return word
+	}
+
+	r, size := utf8.DecodeRuneInString(lower)
+	return string(unicode.ToUpper(r)) + lower[size:]
+}
+
+// truncate shortens a title to MaxLength at a word boundary, adding an ellipsis
+func (tf *TitleFormatter) truncate(title string) string {
+	if tf.MaxLength <= 0 || utf8.RuneCountInString(title) <= tf.MaxLength {
+		return title
+	}
+
+	runes := []rune(title)
+	cut := string(runes[:tf.MaxLength-1])
+	if idx := strings.LastIndex(cut, " "); idx > tf.MaxLength/2 {
+		cut = cut[:idx]
+	}
+	cut = strings.TrimRight(cut, " -:,")
+
+	return cut + "…"
+}
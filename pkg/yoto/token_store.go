@@ -0,0 +1,164 @@
+package yoto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/filex"
+	"github.com/callen/bird-song-explorer/pkg/gcp"
+)
+
+// Secret Manager secret names for the Yoto tokens
+const (
+	accessTokenSecret  = "yoto-access-token"
+	refreshTokenSecret = "yoto-refresh-token"
+)
+
+// StoredTokens is the token pair a TokenStore persists
+type StoredTokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// TokenStore persists Yoto tokens so rotated refresh tokens survive restarts and are shared
+// between instances. Load returns nil without an error when nothing has been stored yet.
+type TokenStore interface {
+	Load() (*StoredTokens, error)
+	Save(tokens StoredTokens) error
+}
+
+// NewTokenStore creates a store by kind: "file" (at path), "secret-manager" (in the GCP
+// project), or "memory". An empty kind returns nil, leaving the client on its configured tokens.
+func NewTokenStore(kind, path, projectID string) (TokenStore, error) {
+	switch kind {
+	case "":
+		return nil, nil
+	case "memory":
+		return &MemoryTokenStore{}, nil
+	case "file":
+		return NewFileTokenStore(path), nil
+	case "secret-manager":
+		return NewSecretManagerTokenStore(projectID)
+	default:
+		return nil, fmt.Errorf("unknown token store %q", kind)
+	}
+}
+
+// MemoryTokenStore keeps tokens for the life of the process (useful for tools and tests)
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens *StoredTokens
+}
+
+// Load returns the last saved tokens
+func (ms *MemoryTokenStore) Load() (*StoredTokens, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ms.tokens == nil {
+		return nil, nil
+	}
+	snapshot := *ms.tokens
+	return &snapshot, nil
+}
+
+// Save replaces the stored tokens
+func (ms *MemoryTokenStore) Save(tokens StoredTokens) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.tokens = &tokens
+	return nil
+}
+
+// FileTokenStore keeps tokens in a JSON file readable only by the owner
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileTokenStore creates a file store at path, defaulting to data/yoto_tokens.json
+func NewFileTokenStore(path string) *FileTokenStore {
+	if path == "" {
+		path = "data/yoto_tokens.json"
+	}
+	return &FileTokenStore{path: path}
+}
+
+// Load reads the token file
+func (fs *FileTokenStore) Load() (*StoredTokens, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := os.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var tokens StoredTokens
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token file: %w", err)
+	}
+	return &tokens, nil
+}
+
+// Save writes the token file atomically
+func (fs *FileTokenStore) Save(tokens StoredTokens) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+	return filex.AtomicWriteFile(fs.path, data, 0600)
+}
+
+// SecretManagerTokenStore keeps tokens in the yoto-access-token and yoto-refresh-token secrets
+type SecretManagerTokenStore struct {
+	projectID string
+}
+
+// NewSecretManagerTokenStore creates a store for the given GCP project
+func NewSecretManagerTokenStore(projectID string) (*SecretManagerTokenStore, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("no GCP project set for Secret Manager (GCP_PROJECT)")
+	}
+	return &SecretManagerTokenStore{projectID: projectID}, nil
+}
+
+// Load reads the latest secret versions; the expiry comes from the access token's JWT claims
+func (ss *SecretManagerTokenStore) Load() (*StoredTokens, error) {
+	accessToken, err := gcp.AccessSecret(ss.projectID, accessTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := gcp.AccessSecret(ss.projectID, refreshTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &StoredTokens{AccessToken: accessToken, RefreshToken: refreshToken}
+	if exp := extractTokenExpiry(accessToken); exp > 0 {
+		tokens.Expiry = time.Unix(exp, 0)
+	}
+	return tokens, nil
+}
+
+// Save adds new secret versions for both tokens
+func (ss *SecretManagerTokenStore) Save(tokens StoredTokens) error {
+	if err := gcp.AddSecretVersion(ss.projectID, accessTokenSecret, tokens.AccessToken); err != nil {
+		return err
+	}
+	if tokens.RefreshToken != "" {
+		return gcp.AddSecretVersion(ss.projectID, refreshTokenSecret, tokens.RefreshToken)
+	}
+	return nil
+}
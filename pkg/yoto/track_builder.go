@@ -0,0 +1,173 @@
+package yoto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TrackIcons are the uploaded icon media IDs a streaming card's chapters can use
+type TrackIcons struct {
+	Welcome    string // Binoculars, or the seasonal theme's icon
+	Music      string
+	Bird       string // The day's bird
+	Guide      string // The song visualizer when one was generated, otherwise the bird
+	HikingBoot string
+	Question   string // Only uploaded when the card has a quiz
+	BirdHero   string // Only uploaded when the card has a bird hero chapter
+}
+
+// TrackSpec is a chapter's single streaming track
+type TrackSpec struct {
+	Title       string
+	TrackURL    string
+	Duration    int // Estimated seconds, shown before the stream has been fetched
+	TrackIcon   string
+	ChapterIcon string
+}
+
+// Chapter builds the streaming chapter for the track, unnumbered until SequenceChapters numbers the
+// card's chapters
+func (ts TrackSpec) Chapter() StreamingChapter {
+	return StreamingChapter{
+		Title: ts.Title,
+		Tracks: []StreamingTrack{
+			{
+				Title:    ts.Title,
+				TrackURL: ts.TrackURL,
+				Type:     "stream",
+				Format:   "mp3",
+				Duration: ts.Duration,
+				Display: Display{
+					Icon16x16: ts.TrackIcon,
+				},
+			},
+		},
+		Display: Display{
+			Icon16x16: ts.ChapterIcon,
+		},
+	}
+}
+
+// ChapterBuilder creates the track for one card segment
+type ChapterBuilder interface {
+	BuildTrack(icons TrackIcons, trackURL string) TrackSpec
+}
+
+// ChapterBuilderFunc adapts a function to a ChapterBuilder
+type ChapterBuilderFunc func(icons TrackIcons, trackURL string) TrackSpec
+
+// BuildTrack calls f
+func (f ChapterBuilderFunc) BuildTrack(icons TrackIcons, trackURL string) TrackSpec {
+	return f(icons, trackURL)
+}
+
+// fixedChapter is a segment with a fixed title and duration, and icons picked from the card's
+type fixedChapter struct {
+	title       string
+	duration    int
+	trackIcon   func(icons TrackIcons) string
+	chapterIcon func(icons TrackIcons) string
+}
+
+func (fc fixedChapter) BuildTrack(icons TrackIcons, trackURL string) TrackSpec {
+	return TrackSpec{
+		Title:       fc.title,
+		TrackURL:    trackURL,
+		Duration:    fc.duration,
+		TrackIcon:   fc.trackIcon(icons),
+		ChapterIcon: fc.chapterIcon(icons),
+	}
+}
+
+func welcomeIcon(icons TrackIcons) string    { return icons.Welcome }
+func musicIcon(icons TrackIcons) string      { return icons.Music }
+func birdIcon(icons TrackIcons) string       { return icons.Bird }
+func guideIcon(icons TrackIcons) string      { return icons.Guide }
+func hikingBootIcon(icons TrackIcons) string { return icons.HikingBoot }
+func questionIcon(icons TrackIcons) string   { return icons.Question }
+func birdHeroIcon(icons TrackIcons) string   { return icons.BirdHero }
+
+// standardChapters build the segments every card can use
+var standardChapters = map[string]ChapterBuilder{
+	SegmentIntro:        fixedChapter{"Welcome, Explorers!", 30, welcomeIcon, welcomeIcon},
+	SegmentAnnouncement: fixedChapter{"Who's Singing Today?", 10, musicIcon, musicIcon},
+	// The server decides per device whether the primer plays or is skipped with a silent clip
+	SegmentPrimer: fixedChapter{"Bird Sound Secrets", 10, musicIcon, musicIcon},
+	// The guide track shows the song visualizer when there is one, the chapter the bird
+	SegmentDescription: fixedChapter{"Bird Explorer's Guide", 60, guideIcon, birdIcon},
+	SegmentQuiz:        fixedChapter{"Can You Guess the Bird?", 30, questionIcon, questionIcon},
+	// The server plays the silent skip clip when no nearby park is found
+	SegmentHotspots: fixedChapter{"Where Can You See It?", 20, hikingBootIcon, hikingBootIcon},
+	// Only threatened birds have a story; the server plays the silent skip clip for the rest
+	SegmentBirdHero: fixedChapter{"Be a Bird Hero!", 30, birdHeroIcon, birdHeroIcon},
+	// Bird-of-the-week cards retitle this chapter with each day's theme
+	SegmentWeeklyFact: fixedChapter{"Bird of the Week", 45, guideIcon, birdIcon},
+	// The answer is read at the start of the outro
+	SegmentListenCount: fixedChapter{"Listen and Count!", 30, musicIcon, musicIcon},
+	SegmentOutro:       fixedChapter{"Happy Exploring!", 20, hikingBootIcon, hikingBootIcon},
+}
+
+// ContentAssembler turns a card template into numbered chapters, building each segment with the
+// ChapterBuilder registered for it
+type ContentAssembler struct {
+	builders map[string]ChapterBuilder
+}
+
+// NewContentAssembler creates an assembler with the standard segments registered
+func NewContentAssembler() *ContentAssembler {
+	builders := make(map[string]ChapterBuilder, len(standardChapters))
+	for segment, builder := range standardChapters {
+		builders[segment] = builder
+	}
+	return &ContentAssembler{builders: builders}
+}
+
+// Register adds a segment or replaces how an existing one is built
+func (ca *ContentAssembler) Register(segment string, builder ChapterBuilder) {
+	ca.builders[segment] = builder
+}
+
+// Segments lists the registered segments, sorted
+func (ca *ContentAssembler) Segments() []string {
+	segments := make([]string, 0, len(ca.builders))
+	for segment := range ca.builders {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+	return segments
+}
+
+// Validate checks the template has at least one segment and no unregistered or repeated ones
+func (ca *ContentAssembler) Validate(template CardTemplate) error {
+	if len(template.Segments) == 0 {
+		return fmt.Errorf("card template has no segments")
+	}
+	seen := make(map[string]bool)
+	for _, segment := range template.Segments {
+		if _, known := ca.builders[segment]; !known {
+			return fmt.Errorf("unknown card segment %q (want one of %s)", segment, strings.Join(ca.Segments(), ", "))
+		}
+		if seen[segment] {
+			return fmt.Errorf("card segment %q appears more than once", segment)
+		}
+		seen[segment] = true
+	}
+	return nil
+}
+
+// Assemble builds one chapter per template segment, in order, each streaming from trackURL(segment),
+// and sequences them
+func (ca *ContentAssembler) Assemble(template CardTemplate, icons TrackIcons, trackURL func(segment string) string) ([]StreamingChapter, error) {
+	if err := ca.Validate(template); err != nil {
+		return nil, err
+	}
+
+	chapters := make([]StreamingChapter, 0, len(template.Segments))
+	for _, segment := range template.Segments {
+		track := ca.builders[segment].BuildTrack(icons, trackURL(segment))
+		chapters = append(chapters, track.Chapter())
+	}
+	SequenceChapters(chapters)
+	return chapters, nil
+}
@@ -0,0 +1,136 @@
+package yoto
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testIcons = TrackIcons{
+	Welcome:    "welcome",
+	Music:      "music",
+	Bird:       "bird",
+	Guide:      "visualizer",
+	HikingBoot: "boot",
+	Question:   "question",
+	BirdHero:   "hero",
+}
+
+func testTrackURL(segment string) string {
+	return "https://example.com/stream/" + segment
+}
+
+func TestAssembleDefaultTemplate(t *testing.T) {
+	chapters, err := NewContentAssembler().Assemble(DefaultCardTemplate(true, true, false), testIcons, testTrackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		segment, title, trackIcon, chapterIcon string
+		duration                               int
+	}{
+		{SegmentIntro, "Welcome, Explorers!", "welcome", "welcome", 30},
+		{SegmentAnnouncement, "Who's Singing Today?", "music", "music", 10},
+		{SegmentPrimer, "Bird Sound Secrets", "music", "music", 10},
+		{SegmentDescription, "Bird Explorer's Guide", "visualizer", "bird", 60},
+		{SegmentQuiz, "Can You Guess the Bird?", "question", "question", 30},
+		{SegmentOutro, "Happy Exploring!", "boot", "boot", 20},
+	}
+	if len(chapters) != len(want) {
+		t.Fatalf("assembled %d chapters, want %d", len(chapters), len(want))
+	}
+	for i, w := range want {
+		chapter := chapters[i]
+		if chapter.Title != w.title || chapter.Display.Icon16x16 != w.chapterIcon {
+			t.Errorf("chapter %d = %q with icon %q, want %q with icon %q", i, chapter.Title, chapter.Display.Icon16x16, w.title, w.chapterIcon)
+		}
+		if len(chapter.Tracks) != 1 {
+			t.Fatalf("chapter %d has %d tracks, want 1", i, len(chapter.Tracks))
+		}
+		track := chapter.Tracks[0]
+		if track.TrackURL != testTrackURL(w.segment) {
+			t.Errorf("chapter %d streams %q, want %q", i, track.TrackURL, testTrackURL(w.segment))
+		}
+		if track.Title != w.title || track.Duration != w.duration || track.Display.Icon16x16 != w.trackIcon {
+			t.Errorf("chapter %d track = %+v", i, track)
+		}
+		if track.Type != "stream" || track.Format != "mp3" {
+			t.Errorf("chapter %d track is %s/%s, want stream/mp3", i, track.Type, track.Format)
+		}
+	}
+}
+
+func TestAssembleSequencesChapters(t *testing.T) {
+	template := CardTemplate{Segments: []string{SegmentOutro, SegmentIntro, SegmentBirdHero}}
+	chapters, err := NewContentAssembler().Assemble(template, testIcons, testTrackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, chapter := range chapters {
+		wantKey, wantLabel := chapterKey(i), strconv.Itoa(i+1)
+		if chapter.Key != wantKey || chapter.OverlayLabel != wantLabel {
+			t.Errorf("chapter %d keyed %q/%q, want %q/%q", i, chapter.Key, chapter.OverlayLabel, wantKey, wantLabel)
+		}
+		if track := chapter.Tracks[0]; track.Key != "01" || track.OverlayLabel != wantLabel {
+			t.Errorf("chapter %d track keyed %q/%q, want 01/%q", i, track.Key, track.OverlayLabel, wantLabel)
+		}
+	}
+	if chapters[0].Title != "Happy Exploring!" || chapters[2].Title != "Be a Bird Hero!" {
+		t.Errorf("chapters aren't in template order: %q, %q", chapters[0].Title, chapters[2].Title)
+	}
+}
+
+func TestValidateRejectsBadTemplates(t *testing.T) {
+	ca := NewContentAssembler()
+	tests := []struct {
+		name     string
+		segments []string
+		wantErr  string
+	}{
+		{"empty", nil, "no segments"},
+		{"unknown", []string{SegmentIntro, "karaoke"}, `unknown card segment "karaoke"`},
+		{"repeated", []string{SegmentIntro, SegmentOutro, SegmentIntro}, `"intro" appears more than once`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ca.Validate(CardTemplate{Segments: tt.segments})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if _, err := ca.Assemble(CardTemplate{Segments: tt.segments}, testIcons, testTrackURL); err == nil {
+				t.Error("Assemble accepted the template")
+			}
+		})
+	}
+}
+
+func TestRegisterAddsAndReplacesSegments(t *testing.T) {
+	ca := NewContentAssembler()
+	ca.Register("karaoke", ChapterBuilderFunc(func(icons TrackIcons, trackURL string) TrackSpec {
+		return TrackSpec{Title: "Sing Along!", TrackURL: trackURL, Duration: 40, TrackIcon: icons.Music, ChapterIcon: icons.Bird}
+	}))
+	ca.Register(SegmentIntro, ChapterBuilderFunc(func(icons TrackIcons, trackURL string) TrackSpec {
+		return TrackSpec{Title: "Happy Holidays!", TrackURL: trackURL, Duration: 25, TrackIcon: icons.Welcome, ChapterIcon: icons.Welcome}
+	}))
+
+	chapters, err := ca.Assemble(CardTemplate{Segments: []string{SegmentIntro, "karaoke"}}, testIcons, testTrackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chapters[0].Title != "Happy Holidays!" || chapters[0].Tracks[0].Duration != 25 {
+		t.Errorf("intro wasn't replaced: %+v", chapters[0])
+	}
+	karaoke := chapters[1]
+	if karaoke.Title != "Sing Along!" || karaoke.Display.Icon16x16 != "bird" || karaoke.Tracks[0].TrackURL != testTrackURL("karaoke") {
+		t.Errorf("karaoke chapter = %+v", karaoke)
+	}
+
+	if segments := NewContentAssembler().Segments(); len(segments) != len(standardChapters) {
+		t.Errorf("a new assembler has %d segments, want %d: registering leaked into the standard set", len(segments), len(standardChapters))
+	}
+	if segments := ca.Segments(); segments[0] != SegmentAnnouncement || len(segments) != len(standardChapters)+1 {
+		t.Errorf("Segments() = %v, want the standard segments plus karaoke, sorted", segments)
+	}
+}
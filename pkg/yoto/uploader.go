@@ -2,19 +2,69 @@ package yoto
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/httpx"
+	"github.com/callen/bird-song-explorer/pkg/metrics"
+)
+
+var (
+	uploadDuration = metrics.NewHistogramVec("bird_explorer_yoto_upload_duration_seconds",
+		"Time to upload audio to Yoto's transcoder, by result", nil, "result")
+	transcodeWait = metrics.NewHistogramVec("bird_explorer_yoto_transcode_wait_seconds",
+		"Time spent polling Yoto until uploaded audio is transcoded, by result", nil, "result")
+)
+
+// observeResult records the time since start under "ok" or "error"
+func observeResult(histogram *metrics.HistogramVec, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	histogram.ObserveSince(start, result)
+}
+
+// Default transcode polling: Yoto usually finishes short clips within a few seconds
+const (
+	defaultTranscodePollInterval = 500 * time.Millisecond
+	defaultTranscodeMaxWait      = 15 * time.Second
 )
 
+// ErrTranscodePending is matched by the TranscodePendingError returned when audio has been
+// uploaded but Yoto hasn't finished transcoding it
+var ErrTranscodePending = errors.New("transcode still in progress")
+
+// TranscodePendingError carries the upload ID of audio still being transcoded, so the caller can
+// check on it later with CheckTranscode instead of uploading again
+type TranscodePendingError struct {
+	UploadID string
+}
+
+func (e *TranscodePendingError) Error() string {
+	return fmt.Sprintf("upload %s: %v", e.UploadID, ErrTranscodePending)
+}
+
+func (e *TranscodePendingError) Is(target error) bool {
+	return target == ErrTranscodePending
+}
+
 type AudioUploader struct {
-	client      *Client
-	maxAttempts int
+	client       *Client
+	pollInterval time.Duration
+	maxWait      time.Duration
+	async        bool                                   // Return a TranscodePendingError rather than waiting
+	normalizer   func(audioData []byte) ([]byte, error) // Optional loudness normalization before upload
+	ctx          context.Context                        // Carries the request ID and cancels uploads and transcode waits
 }
 
 type UploadURLResponse struct {
@@ -112,7 +162,8 @@ type PlaylistContent struct {
 }
 
 type Content struct {
-	Chapters []Chapter `json:"chapters"`
+	Chapters []Chapter      `json:"chapters"`
+	Config   *ContentConfig `json:"config,omitempty"`
 }
 
 type Chapter struct {
@@ -121,19 +172,22 @@ type Chapter struct {
 	OverlayLabel string          `json:"overlayLabel"`
 	Tracks       []PlaylistTrack `json:"tracks"`
 	Display      Display         `json:"display"`
+	Ambient      *Ambient        `json:"ambient,omitempty"`
 }
 
 type PlaylistTrack struct {
-	Key          string  `json:"key"`
-	Title        string  `json:"title"`
-	TrackURL     string  `json:"trackUrl"`
-	Duration     int     `json:"duration"`
-	FileSize     int64   `json:"fileSize"`
-	Channels     string  `json:"channels"` // "stereo" or "mono"
-	Format       string  `json:"format"`
-	Type         string  `json:"type"`
-	OverlayLabel string  `json:"overlayLabel"`
-	Display      Display `json:"display"`
+	Key          string       `json:"key"`
+	Title        string       `json:"title"`
+	TrackURL     string       `json:"trackUrl"`
+	Duration     int          `json:"duration"`
+	FileSize     int64        `json:"fileSize"`
+	Channels     string       `json:"channels"` // "stereo" or "mono"
+	Format       string       `json:"format"`
+	Type         string       `json:"type"`
+	OverlayLabel string       `json:"overlayLabel"`
+	Display      Display      `json:"display"`
+	Ambient      *Ambient     `json:"ambient,omitempty"`
+	Events       *TrackEvents `json:"events,omitempty"`
 }
 
 type Display struct {
@@ -153,11 +207,35 @@ type MediaInfo struct {
 
 func NewAudioUploader(client *Client) *AudioUploader {
 	return &AudioUploader{
-		client:      client,
-		maxAttempts: 30,
+		client:       client,
+		pollInterval: defaultTranscodePollInterval,
+		maxWait:      defaultTranscodeMaxWait,
+		ctx:          context.Background(),
 	}
 }
 
+// SetTranscodeWait sets how often Yoto is polled for a finished transcode and how long to wait in
+// total before giving up; zero keeps the default
+func (au *AudioUploader) SetTranscodeWait(pollInterval, maxWait time.Duration) {
+	if pollInterval > 0 {
+		au.pollInterval = pollInterval
+	}
+	if maxWait > 0 {
+		au.maxWait = maxWait
+	}
+}
+
+// SetAsyncTranscode makes uploads check the transcode once and return a TranscodePendingError
+// when it isn't done, instead of blocking until it finishes
+func (au *AudioUploader) SetAsyncTranscode(async bool) {
+	au.async = async
+}
+
+// SetNormalizer sets a function applied to audio before UploadAudioData; on error the original audio is uploaded
+func (au *AudioUploader) SetNormalizer(normalizer func(audioData []byte) ([]byte, error)) {
+	au.normalizer = normalizer
+}
+
 // UploadAudioFile uploads a local audio file to Yoto
 func (au *AudioUploader) UploadAudioFile(filePath string) (string, error) {
 	if err := au.client.ensureAuthenticated(); err != nil {
@@ -171,23 +249,26 @@ func (au *AudioUploader) UploadAudioFile(filePath string) (string, error) {
 	}
 
 	// Step 2: Upload the file
-	if err := au.uploadFile(uploadURL, filePath); err != nil {
+	uploadStart := time.Now()
+	err = au.uploadFile(uploadURL, filePath)
+	observeResult(uploadDuration, uploadStart, err)
+	if err != nil {
 		return "", fmt.Errorf("failed to upload file: %w", err)
 	}
 
 	// Step 3: Wait for transcoding
-	transcodedSha, err := au.waitForTranscoding(uploadID)
+	transcodeInfo, err := au.awaitTranscode(uploadID)
 	if err != nil {
 		return "", fmt.Errorf("transcoding failed: %w", err)
 	}
 
-	return transcodedSha, nil
+	return transcodeInfo.Transcode.TranscodedSha256, nil
 }
 
 // UploadAudioFromURL downloads and uploads audio from a URL
 func (au *AudioUploader) UploadAudioFromURL(audioURL string, title string) (string, *TranscodeResponse, error) {
 	// Download the audio file
-	resp, err := http.Get(audioURL)
+	resp, err := httpx.Get(au.ctx, httpx.Default, audioURL)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to download audio: %w", err)
 	}
@@ -208,30 +289,43 @@ func (au *AudioUploader) UploadAudioData(audioData []byte, title string) (string
 		return "", nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
+	if au.normalizer != nil {
+		if normalized, err := au.normalizer(audioData); err != nil {
+			slog.WarnContext(au.ctx, "[UPLOADER] Skipping loudness normalization", "title", title, "error", err)
+		} else {
+			audioData = normalized
+		}
+	}
+
 	uploadURL, uploadID, err := au.getUploadURL()
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get upload URL: %w", err)
 	}
 
 	// Upload the audio data
-	req, err := http.NewRequest("PUT", uploadURL, bytes.NewReader(audioData))
+	req, err := http.NewRequestWithContext(au.ctx, "PUT", uploadURL, bytes.NewReader(audioData))
 	if err != nil {
 		return "", nil, err
 	}
 	req.Header.Set("Content-Type", "audio/mpeg")
 
+	uploadStart := time.Now()
 	uploadResp, err := au.client.httpClient.Do(req)
 	if err != nil {
+		observeResult(uploadDuration, uploadStart, err)
 		return "", nil, fmt.Errorf("upload failed: %w", err)
 	}
 	defer uploadResp.Body.Close()
 
 	if uploadResp.StatusCode != http.StatusOK && uploadResp.StatusCode != http.StatusCreated {
-		return "", nil, fmt.Errorf("upload failed with status: %d", uploadResp.StatusCode)
+		err = fmt.Errorf("upload failed with status: %d", uploadResp.StatusCode)
+		observeResult(uploadDuration, uploadStart, err)
+		return "", nil, err
 	}
+	observeResult(uploadDuration, uploadStart, nil)
 
 	// Wait for transcoding
-	transcodeInfo, err := au.waitForTranscodingWithInfo(uploadID)
+	transcodeInfo, err := au.awaitTranscode(uploadID)
 	if err != nil {
 		return "", nil, fmt.Errorf("transcoding failed: %w", err)
 	}
@@ -242,12 +336,12 @@ func (au *AudioUploader) UploadAudioData(audioData []byte, title string) (string
 func (au *AudioUploader) getUploadURL() (string, string, error) {
 	url := fmt.Sprintf("%s/media/transcode/audio/uploadUrl", au.client.baseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(au.ctx, "GET", url, nil)
 	if err != nil {
 		return "", "", err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+au.client.accessToken)
+	req.Header.Set("Authorization", "Bearer "+au.client.bearerToken())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := au.client.httpClient.Do(req)
@@ -257,8 +351,7 @@ func (au *AudioUploader) getUploadURL() (string, string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("failed to get upload URL: %d - %s", resp.StatusCode, string(body))
+		return "", "", newAPIError("failed to get upload URL", resp)
 	}
 
 	var uploadResp UploadURLResponse
@@ -281,7 +374,7 @@ func (au *AudioUploader) uploadFile(uploadURL, filePath string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("PUT", uploadURL, file)
+	req, err := http.NewRequestWithContext(au.ctx, "PUT", uploadURL, file)
 	if err != nil {
 		return err
 	}
@@ -304,72 +397,71 @@ func (au *AudioUploader) uploadFile(uploadURL, filePath string) error {
 	return nil
 }
 
-func (au *AudioUploader) waitForTranscoding(uploadID string) (string, error) {
-	for attempts := 0; attempts < au.maxAttempts; attempts++ {
-		url := fmt.Sprintf("%s/media/upload/%s/transcoded?loudnorm=false", au.client.baseURL, uploadID)
+// awaitTranscode waits for the upload's transcode, or in async mode checks it once
+func (au *AudioUploader) awaitTranscode(uploadID string) (*TranscodeResponse, error) {
+	if au.async {
+		return au.CheckTranscode(uploadID)
+	}
 
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return "", err
-		}
+	transcodeStart := time.Now()
+	transcodeInfo, err := au.waitForTranscoding(uploadID)
+	observeResult(transcodeWait, transcodeStart, err)
+	return transcodeInfo, err
+}
 
-		req.Header.Set("Authorization", "Bearer "+au.client.accessToken)
-		req.Header.Set("Accept", "application/json")
+// CheckTranscode polls an upload's transcode once, returning a TranscodePendingError while Yoto
+// is still working on it
+func (au *AudioUploader) CheckTranscode(uploadID string) (*TranscodeResponse, error) {
+	url := fmt.Sprintf("%s/media/upload/%s/transcoded?loudnorm=false", au.client.baseURL, uploadID)
 
-		resp, err := au.client.httpClient.Do(req)
-		if err != nil {
-			return "", err
-		}
-		defer resp.Body.Close()
+	req, err := http.NewRequestWithContext(au.ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode == http.StatusOK {
-			var transcodeResp TranscodeResponse
-			if err := json.NewDecoder(resp.Body).Decode(&transcodeResp); err != nil {
-				return "", err
-			}
+	req.Header.Set("Authorization", "Bearer "+au.client.bearerToken())
+	req.Header.Set("Accept", "application/json")
 
-			if transcodeResp.Transcode.TranscodedSha256 != "" {
-				return transcodeResp.Transcode.TranscodedSha256, nil
-			}
-		}
+	resp, err := au.client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-		time.Sleep(500 * time.Millisecond)
+	// Yoto answers 404 until the transcode record exists
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, &TranscodePendingError{UploadID: uploadID}
 	}
 
-	return "", fmt.Errorf("transcoding timed out after %d attempts", au.maxAttempts)
+	var transcodeResp TranscodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&transcodeResp); err != nil {
+		return nil, err
+	}
+	if transcodeResp.Transcode.TranscodedSha256 == "" {
+		return nil, &TranscodePendingError{UploadID: uploadID}
+	}
+	return &transcodeResp, nil
 }
 
-func (au *AudioUploader) waitForTranscodingWithInfo(uploadID string) (*TranscodeResponse, error) {
-	for attempts := 0; attempts < au.maxAttempts; attempts++ {
-		url := fmt.Sprintf("%s/media/upload/%s/transcoded?loudnorm=false", au.client.baseURL, uploadID)
-
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
+// waitForTranscoding polls until the upload is transcoded, the maximum wait passes, or the
+// uploader's context is cancelled
+func (au *AudioUploader) waitForTranscoding(uploadID string) (*TranscodeResponse, error) {
+	deadline := time.Now().Add(au.maxWait)
+	for {
+		transcodeInfo, err := au.CheckTranscode(uploadID)
+		if !errors.Is(err, ErrTranscodePending) {
+			return transcodeInfo, err
 		}
 
-		req.Header.Set("Authorization", "Bearer "+au.client.accessToken)
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := au.client.httpClient.Do(req)
-		if err != nil {
-			return nil, err
+		if time.Now().Add(au.pollInterval).After(deadline) {
+			return nil, fmt.Errorf("%w: upload %s didn't finish within %v", ErrTranscodeTimeout, uploadID, au.maxWait)
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			var transcodeResp TranscodeResponse
-			if err := json.NewDecoder(resp.Body).Decode(&transcodeResp); err != nil {
-				return nil, err
-			}
 
-			if transcodeResp.Transcode.TranscodedSha256 != "" {
-				return &transcodeResp, nil
-			}
+		select {
+		case <-au.ctx.Done():
+			return nil, au.ctx.Err()
+		case <-time.After(au.pollInterval):
 		}
-
-		time.Sleep(500 * time.Millisecond)
 	}
-
-	return nil, fmt.Errorf("transcoding timed out after %d attempts", au.maxAttempts)
 }
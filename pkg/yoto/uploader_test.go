@@ -0,0 +1,80 @@
+package yoto_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+)
+
+func TestAudioUploadsAreTranscoded(t *testing.T) {
+	server := newFakeAPI(t)
+	server.TranscodePolls = 2
+	audio := bytes.Repeat([]byte("tweet"), 1024)
+
+	uploader := yoto.NewAudioUploader(server.Client())
+	uploader.SetTranscodeWait(10*time.Millisecond, 0)
+	sha, transcoded, err := uploader.UploadAudioData(audio, "Bald Eagle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(audio)
+	if want := hex.EncodeToString(sum[:]); sha != want {
+		t.Errorf("transcoded sha is %q, want %q", sha, want)
+	}
+	if duration := transcoded.GetDuration(); duration != server.TrackDuration {
+		t.Errorf("duration is %d, want %d", duration, server.TrackDuration)
+	}
+}
+
+func TestSlowTranscodesGiveUpAfterTheMaxWait(t *testing.T) {
+	server := newFakeAPI(t)
+	server.TranscodePolls = 1000
+
+	uploader := yoto.NewAudioUploader(server.Client())
+	uploader.SetTranscodeWait(10*time.Millisecond, 50*time.Millisecond)
+	start := time.Now()
+	_, _, err := uploader.UploadAudioData([]byte("tweet"), "Bald Eagle")
+	if err == nil || errors.Is(err, yoto.ErrTranscodePending) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("waited %v for a 50ms max wait", waited)
+	}
+}
+
+func TestAsyncUploadsHandBackPendingTranscodes(t *testing.T) {
+	server := newFakeAPI(t)
+	server.TranscodePolls = 3
+
+	uploader := yoto.NewAudioUploader(server.Client())
+	uploader.SetAsyncTranscode(true)
+	_, _, err := uploader.UploadAudioData([]byte("tweet"), "Bald Eagle")
+	var pending *yoto.TranscodePendingError
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected a pending transcode, got %v", err)
+	}
+
+	for polls := 0; polls < 5; polls++ {
+		transcoded, err := uploader.CheckTranscode(pending.UploadID)
+		if errors.Is(err, yoto.ErrTranscodePending) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transcoded.Transcode.TranscodedSha256 == "" {
+			t.Error("finished transcode has no sha")
+		}
+		if uploads := len(server.Uploads()); uploads != 1 {
+			t.Errorf("expected 1 upload, got %d", uploads)
+		}
+		return
+	}
+	t.Fatal("transcode never finished")
+}
@@ -0,0 +1,150 @@
+package yoto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// errNoYotoicons means yotoicons.com has no suitable icon for a search
+	errNoYotoicons = errors.New("no icons found on yotoicons")
+	// errYotoiconsMarkup means a search page had neither icons nor a no-results notice, which
+	// usually means the site's markup changed
+	errYotoiconsMarkup = errors.New("unrecognized yotoicons page")
+)
+
+// yotoiconsUploadPattern matches an icon image path and captures its ID
+var yotoiconsUploadPattern = regexp.MustCompile(`/static/uploads/(\d+)\.png`)
+
+// yotoiconsAuthorPattern matches the "@author" credit shown with each icon
+var yotoiconsAuthorPattern = regexp.MustCompile(`@([a-zA-Z0-9_-]+)`)
+
+// yotoiconsIcon is one icon listed on a yotoicons.com search page
+type yotoiconsIcon struct {
+	ID     string
+	Author string
+}
+
+// yotoiconsPage is what a yotoicons.com search page shows
+type yotoiconsPage struct {
+	icons     []yotoiconsIcon
+	noResults bool
+	text      string // Lowercased visible text and image labels, for relevance checks
+}
+
+func yotoiconsIconURL(baseURL string, id string) string {
+	return fmt.Sprintf("%s/static/uploads/%s.png", baseURL, id)
+}
+
+// parseYotoiconsPage reads the icons listed on a search page. Each icon is found from its
+// image, wherever it sits in the layout, and credited to the first "@author" in its enclosing
+// card, so cosmetic markup changes don't break the search.
+func parseYotoiconsPage(body []byte) (*yotoiconsPage, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse yotoicons page: %w", err)
+	}
+
+	page := &yotoiconsPage{}
+	var text strings.Builder
+	seen := make(map[string]bool)
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			text.WriteString(n.Data)
+			text.WriteString(" ")
+		case html.ElementNode:
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+			if n.Data == "img" {
+				text.WriteString(htmlAttr(n, "alt") + " " + htmlAttr(n, "title") + " ")
+				if match := yotoiconsUploadPattern.FindStringSubmatch(htmlAttr(n, "src")); match != nil && !seen[match[1]] {
+					seen[match[1]] = true
+					page.icons = append(page.icons, yotoiconsIcon{ID: match[1], Author: iconAuthor(n)})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	page.text = strings.ToLower(text.String())
+	page.noResults = len(page.icons) == 0 &&
+		(strings.Contains(page.text, "no icons found") || strings.Contains(page.text, "no results"))
+	return page, nil
+}
+
+// iconAuthor returns the "@author" credit from the few elements enclosing an icon image, stopping
+// before an element that holds other icons too
+func iconAuthor(img *html.Node) string {
+	n := img.Parent
+	for depth := 0; n != nil && depth < 3 && countIconImages(n) == 1; depth++ {
+		if match := yotoiconsAuthorPattern.FindStringSubmatch(nodeText(n)); match != nil {
+			return match[1]
+		}
+		n = n.Parent
+	}
+	return "unknown"
+}
+
+// legacyYotoiconsIcons finds icon image paths anywhere in the raw page, for when the page no
+// longer parses into recognizable icon images
+func legacyYotoiconsIcons(body []byte) []yotoiconsIcon {
+	var icons []yotoiconsIcon
+	seen := make(map[string]bool)
+	for _, match := range yotoiconsUploadPattern.FindAllSubmatch(body, 10) {
+		id := string(match[1])
+		if !seen[id] {
+			seen[id] = true
+			icons = append(icons, yotoiconsIcon{ID: id, Author: "unknown"})
+		}
+	}
+	return icons
+}
+
+// countIconImages counts the icon images under n
+func countIconImages(n *html.Node) int {
+	count := 0
+	if n.Type == html.ElementNode && n.Data == "img" && yotoiconsUploadPattern.MatchString(htmlAttr(n, "src")) {
+		count++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		count += countIconImages(c)
+	}
+	return count
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func nodeText(n *html.Node) string {
+	var text strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			text.WriteString(n.Data)
+			text.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return text.String()
+}
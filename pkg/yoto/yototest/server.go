@@ -0,0 +1,586 @@
+// Package yototest provides a fake Yoto API, so ContentManager, the uploaders, and icon search can
+// be exercised end to end without live credentials. The fake enforces the request shapes the real
+// API expects (bearer tokens, cardId rather than contentId, whole-content replacement) and records
+// every request for inspection.
+package yototest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callen/bird-song-explorer/pkg/yoto"
+)
+
+// Request is a request the fake received
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// Upload is an audio file uploaded through a transcode upload URL
+type Upload struct {
+	ID          string
+	Data        []byte
+	ContentType string
+	Polls       int // Transcode status checks so far
+}
+
+// Yotoicon is an icon listed on the fake yotoicons.com
+type Yotoicon struct {
+	ID     string // Numeric, as on the real site
+	Tag    string // Search term it's listed under, e.g. "robin"
+	Author string
+	PNG    []byte
+}
+
+// Icon is an uploaded display icon
+type Icon struct {
+	MediaID     string
+	Filename    string
+	AutoConvert bool
+	Data        []byte
+}
+
+type injectedFailure struct {
+	status int
+	body   string
+}
+
+// Server is a fake Yoto API backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	// TranscodePolls is how many status checks report an upload as still transcoding
+	TranscodePolls int
+	// TrackDuration is the duration in seconds reported for transcoded audio
+	TrackDuration int
+
+	mu           sync.Mutex
+	clientID     string
+	accessToken  string
+	refreshToken string
+	tokenCount   int
+	nextID       int
+	cards        map[string]*yoto.Card
+	devices      map[string]yoto.DeviceConfig
+	uploads      map[string]*Upload
+	icons        []Icon
+	covers       []string
+	publicIcons  []yoto.YotoPublicIcon
+	yotoicons    []Yotoicon
+	requests     []Request
+	failures     map[string][]injectedFailure
+}
+
+// NewServer starts a fake Yoto API. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		TrackDuration: 30,
+		clientID:      "yototest-client",
+		cards:         make(map[string]*yoto.Card),
+		devices:       make(map[string]yoto.DeviceConfig),
+		uploads:       make(map[string]*Upload),
+		failures:      make(map[string][]injectedFailure),
+	}
+	s.accessToken, s.refreshToken = s.issueTokens()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/token", s.handleToken)
+	mux.HandleFunc("GET /content/{cardID}", s.authorized(s.handleGetContent))
+	mux.HandleFunc("PUT /content/{cardID}", s.authorized(s.handlePutContent))
+	mux.HandleFunc("POST /content", s.authorized(s.handlePostContent))
+	mux.HandleFunc("GET /media/transcode/audio/uploadUrl", s.authorized(s.handleUploadURL))
+	mux.HandleFunc("PUT /upload/{uploadID}", s.handleUpload)
+	mux.HandleFunc("GET /media/upload/{uploadID}/transcoded", s.authorized(s.handleTranscoded))
+	mux.HandleFunc("POST /media/displayIcons/user/me/upload", s.authorized(s.handleIconUpload))
+	mux.HandleFunc("GET /media/displayIcons/user/yoto", s.authorized(s.handlePublicIcons))
+	mux.HandleFunc("POST /media/coverImage/user/me/upload", s.authorized(s.handleCoverUpload))
+	mux.HandleFunc("GET /device-v2/{deviceID}/config", s.authorized(s.handleDeviceConfig))
+	mux.HandleFunc("GET /yotoicons/icons", s.handleYotoiconsSearch)
+	mux.HandleFunc("GET /yotoicons/static/uploads/{file}", s.handleYotoiconsImage)
+
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// Client returns a yoto.Client pointed at the fake, already holding valid tokens. Refreshed tokens
+// are kept in memory rather than Secret Manager.
+func (s *Server) Client() *yoto.Client {
+	s.mu.Lock()
+	accessToken, refreshToken := s.accessToken, s.refreshToken
+	s.mu.Unlock()
+
+	client := yoto.NewClient(s.clientID, "", s.URL)
+	client.SetAuthURL(s.URL + "/oauth/token")
+	client.SetYotoiconsURL(s.URL + "/yotoicons")
+	client.SetTokenStore(&yoto.MemoryTokenStore{})
+	client.SetTokens(accessToken, refreshToken, 3600)
+	return client
+}
+
+// Tokens returns the access and refresh tokens the fake currently accepts
+func (s *Server) Tokens() (string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken, s.refreshToken
+}
+
+// ExpireAccessToken revokes the current access token, so the next API call is rejected until the
+// client refreshes it
+func (s *Server) ExpireAccessToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = ""
+}
+
+// AddCard stores a card the fake serves and updates
+func (s *Server) AddCard(card yoto.Card) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards[card.CardID] = &card
+}
+
+// Card returns a copy of a stored card
+func (s *Server) Card(cardID string) (yoto.Card, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	card, ok := s.cards[cardID]
+	if !ok {
+		return yoto.Card{}, false
+	}
+	return *card, true
+}
+
+// AddDevice stores a device's config for GetDeviceConfig
+func (s *Server) AddDevice(config yoto.DeviceConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[config.Device.DeviceID] = config
+}
+
+// AddPublicIcon adds an icon to Yoto's public icon library
+func (s *Server) AddPublicIcon(icon yoto.YotoPublicIcon) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publicIcons = append(s.publicIcons, icon)
+}
+
+// AddYotoicon lists an icon on the fake yotoicons.com under its tag
+func (s *Server) AddYotoicon(icon Yotoicon) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.yotoicons = append(s.yotoicons, icon)
+}
+
+// Uploads returns the uploaded audio files
+func (s *Server) Uploads() []Upload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uploads := make([]Upload, 0, len(s.uploads))
+	for _, upload := range s.uploads {
+		uploads = append(uploads, *upload)
+	}
+	return uploads
+}
+
+// Icons returns the uploaded display icons, in upload order
+func (s *Server) Icons() []Icon {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Icon(nil), s.icons...)
+}
+
+// Covers returns the image URLs cover uploads were made from, in upload order
+func (s *Server) Covers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.covers...)
+}
+
+// Requests returns every request received, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received for a method and path, e.g. ("POST", "/content")
+func (s *Server) RequestsTo(method string, path string) []Request {
+	var matching []Request
+	for _, request := range s.Requests() {
+		if request.Method == method && request.Path == path {
+			matching = append(matching, request)
+		}
+	}
+	return matching
+}
+
+// FailNext makes the next request for a method and path fail with status and body. Repeated calls
+// queue further failures.
+func (s *Server) FailNext(method string, path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	s.failures[key] = append(s.failures[key], injectedFailure{status: status, body: body})
+}
+
+// record logs each request and serves any injected failure before routing it
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		key := r.Method + " " + r.URL.Path
+		var failure *injectedFailure
+		if queued := s.failures[key]; len(queued) > 0 {
+			failure = &queued[0]
+			s.failures[key] = queued[1:]
+		}
+		s.mu.Unlock()
+
+		if failure != nil {
+			http.Error(w, failure.body, failure.status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized rejects requests without the current access token, as the real API does
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		valid := s.accessToken != "" && r.Header.Get("Authorization") == "Bearer "+s.accessToken
+		s.mu.Unlock()
+		if !valid {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or expired token"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// issueTokens returns a new access and refresh token pair; call with s.mu held or before serving
+func (s *Server) issueTokens() (string, string) {
+	s.tokenCount++
+	return fmt.Sprintf("access-%d", s.tokenCount), fmt.Sprintf("refresh-%d", s.tokenCount)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("client_id") != s.clientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+		return
+	}
+	// Refresh tokens rotate, so a stale one is rejected
+	if r.PostForm.Get("refresh_token") != s.refreshToken {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	s.accessToken, s.refreshToken = s.issueTokens()
+	writeJSON(w, http.StatusOK, yoto.TokenResponse{
+		AccessToken:  s.accessToken,
+		RefreshToken: s.refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    3600,
+	})
+}
+
+func (s *Server) handleGetContent(w http.ResponseWriter, r *http.Request) {
+	card, ok := s.Card(r.PathValue("cardID"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "card not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"card": card})
+}
+
+// handlePostContent creates a card, or replaces an existing card's content when the body names it
+// by cardId. The whole content is replaced: metadata left out of the update is lost.
+func (s *Server) handlePostContent(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	if _, ok := body["contentId"]; ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown field contentId; cards are updated by cardId"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cardID, _ := body["cardId"].(string)
+	if cardID == "" {
+		// A new playlist: the body is the content itself
+		s.nextID++
+		card := &yoto.Card{CardID: fmt.Sprintf("card%d", s.nextID), CreatedAt: now()}
+		applyContent(card, body)
+		s.cards[card.CardID] = card
+		writeJSON(w, http.StatusCreated, yoto.CreateContentResponse{CardID: card.CardID, Status: "created"})
+		return
+	}
+
+	card, ok := s.cards[cardID]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "card not found"})
+		return
+	}
+	content, ok := body["content"].(map[string]interface{})
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "content must be an object"})
+		return
+	}
+	applyContent(card, content)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"card": card})
+}
+
+// applyContent replaces a card's title, chapters, and metadata with the posted content
+func applyContent(card *yoto.Card, content map[string]interface{}) {
+	card.Content = make(map[string]interface{})
+	card.Metadata = nil
+	for key, value := range content {
+		switch key {
+		case "title":
+			card.Title, _ = value.(string)
+		case "metadata":
+			card.Metadata, _ = value.(map[string]interface{})
+		default:
+			card.Content[key] = value
+		}
+	}
+	card.UpdatedAt = now()
+}
+
+func (s *Server) handlePutContent(w http.ResponseWriter, r *http.Request) {
+	var update yoto.UpdateCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.cards[r.PathValue("cardID")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "card not found"})
+		return
+	}
+	if update.Title != "" {
+		card.Title = update.Title
+	}
+	if update.Description != "" {
+		card.Description = update.Description
+	}
+	card.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, card)
+}
+
+func (s *Server) handleUploadURL(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.nextID++
+	uploadID := fmt.Sprintf("upload%d", s.nextID)
+	s.uploads[uploadID] = &Upload{ID: uploadID}
+	s.mu.Unlock()
+
+	var response yoto.UploadURLResponse
+	response.Upload.UploadID = uploadID
+	response.Upload.UploadURL = s.URL + "/upload/" + uploadID
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleUpload stands in for the pre-signed storage URL, which takes no bearer token
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.uploads[r.PathValue("uploadID")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	upload.Data = data
+	upload.ContentType = r.Header.Get("Content-Type")
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleTranscoded reports an upload as transcoded once TranscodePolls checks have passed
+func (s *Server) handleTranscoded(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.uploads[r.PathValue("uploadID")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "upload not found"})
+		return
+	}
+	upload.Polls++
+
+	var response yoto.TranscodeResponse
+	if upload.Data != nil && upload.Polls > s.TranscodePolls {
+		sum := sha256.Sum256(upload.Data)
+		response.Transcode.TranscodedSha256 = hex.EncodeToString(sum[:])
+		response.Transcode.TranscodedInfo.Duration = s.TrackDuration
+		response.Transcode.TranscodedInfo.FileSize = len(upload.Data)
+		response.Transcode.TranscodedInfo.Channels = "mono"
+		response.Transcode.TranscodedInfo.Format = "mp3"
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleIconUpload(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil || len(data) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "empty icon"})
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	icon := Icon{
+		MediaID:     fmt.Sprintf("icon%d", s.nextID),
+		Filename:    r.URL.Query().Get("filename"),
+		AutoConvert: r.URL.Query().Get("autoConvert") == "true",
+		Data:        data,
+	}
+	s.icons = append(s.icons, icon)
+	s.mu.Unlock()
+
+	var response struct {
+		yoto.IconUploadResponse
+		MediaID string `json:"mediaId,omitempty"` // Animated uploads read the media ID from the top level
+	}
+	response.DisplayIcon.MediaID = icon.MediaID
+	response.DisplayIcon.UserID = "me"
+	response.DisplayIcon.DisplayIconID = icon.MediaID
+	response.DisplayIcon.URL = s.URL + "/icons/" + icon.MediaID
+	response.DisplayIcon.New = true
+	if !icon.AutoConvert {
+		response.MediaID = icon.MediaID
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handlePublicIcons(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	icons := append([]yoto.YotoPublicIcon{}, s.publicIcons...)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, icons)
+}
+
+func (s *Server) handleCoverUpload(w http.ResponseWriter, r *http.Request) {
+	imageURL := r.URL.Query().Get("imageUrl")
+	if imageURL == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "imageUrl is required"})
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	mediaID := fmt.Sprintf("cover%d", s.nextID)
+	s.covers = append(s.covers, imageURL)
+	s.mu.Unlock()
+
+	var response yoto.CoverImageUploadResponse
+	response.CoverImage.MediaID = mediaID
+	response.CoverImage.MediaURL = s.URL + "/covers/" + mediaID
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleDeviceConfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	config, ok := s.devices[r.PathValue("deviceID")]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "device not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+// handleYotoiconsSearch serves a search page in the shape of yotoicons.com's: one card per icon
+// with its image and "@author" credit, or a no-results notice
+func (s *Server) handleYotoiconsSearch(w http.ResponseWriter, r *http.Request) {
+	tag := strings.ToLower(r.URL.Query().Get("tag"))
+
+	var page strings.Builder
+	page.WriteString("<html><body><div class=\"icons\">")
+	found := 0
+	s.mu.Lock()
+	for _, icon := range s.yotoicons {
+		if strings.ToLower(icon.Tag) != tag {
+			continue
+		}
+		found++
+		fmt.Fprintf(&page, `<div class="icon"><img src="/static/uploads/%s.png" alt="%s"><span>@%s</span></div>`,
+			html.EscapeString(icon.ID), html.EscapeString(icon.Tag), html.EscapeString(icon.Author))
+	}
+	s.mu.Unlock()
+	if found == 0 {
+		page.WriteString("<p>No icons found</p>")
+	}
+	page.WriteString("</div></body></html>")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page.String())
+}
+
+func (s *Server) handleYotoiconsImage(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(r.PathValue("file"), ".png")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, icon := range s.yotoicons {
+		if icon.ID == id {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(icon.PNG)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}